@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Axontik/comin-leave-management-service/internal/contract"
+	"github.com/Axontik/comin-leave-management-service/pkg/auth"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+)
+
+// clientCalls make an interaction's request through the real client,
+// returning what the client decoded
+var clientCalls = map[string]contract.Call{
+	"auth.ValidateToken": func(baseURL string, vars map[string]string) (interface{}, error) {
+		return auth.NewAuthClient(baseURL).ValidateToken(vars["token"])
+	},
+	"organization.GetOrganization": func(baseURL string, vars map[string]string) (interface{}, error) {
+		return organization.NewOrganizationClient(baseURL).GetOrganization(vars["authorization"], vars["organization_id"])
+	},
+	"organization.GetEmployee": func(baseURL string, vars map[string]string) (interface{}, error) {
+		return organization.NewOrganizationClient(baseURL).GetEmployee(vars["authorization"], vars["organization_id"], vars["employee_id"])
+	},
+	"organization.ListDepartmentEmployees": func(baseURL string, vars map[string]string) (interface{}, error) {
+		return organization.NewOrganizationClient(baseURL).ListDepartmentEmployees(vars["authorization"], vars["organization_id"], vars["department_id"])
+	},
+	"organization.ListEmployees": func(baseURL string, vars map[string]string) (interface{}, error) {
+		return organization.NewOrganizationClient(baseURL).ListEmployees(vars["authorization"], vars["organization_id"])
+	},
+	"organization.ListDepartments": func(baseURL string, vars map[string]string) (interface{}, error) {
+		return organization.NewOrganizationClient(baseURL).ListDepartments(vars["authorization"], vars["organization_id"])
+	},
+}
+
+// verifyConsumer runs every interaction of the contract against its client
+// and returns the number that failed
+func verifyConsumer(c *contract.Contract) int {
+	var failures int
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		call, ok := clientCalls[interaction.Call]
+		if !ok {
+			failures += report(c, interaction, fmt.Errorf("unknown call %q", interaction.Call))
+			continue
+		}
+		failures += report(c, interaction, contract.Replay(c, interaction, call))
+	}
+	return failures
+}
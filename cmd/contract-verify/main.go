@@ -0,0 +1,90 @@
+// Command contract-verify checks the contracts in contracts/ between this
+// service and the services it calls.
+//
+// In consumer mode (the default) it replays each interaction from an
+// in-process server and runs the real client against it, failing when the
+// client sends a different request or misreads the response:
+//
+//	go run ./cmd/contract-verify
+//
+// In provider mode it sends each interaction's request to a running
+// provider and checks the response status and that every field the
+// consumer relies on is present with the same JSON type. Provider teams
+// pass the IDs and tokens valid in their environment as variables:
+//
+//	go run ./cmd/contract-verify -provider organization-service \
+//		-url https://org.staging.example.com \
+//		-var authorization="Bearer ..." -var organization_id=... -var employee_id=...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/contract"
+)
+
+// varFlags collects repeated -var name=value flags
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v varFlags) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+	v[name] = val
+	return nil
+}
+
+func main() {
+	dirFlag := flag.String("dir", "contracts", "directory holding the contract files")
+	providerFlag := flag.String("provider", "", "verify this provider instead of the clients")
+	urlFlag := flag.String("url", "", "base URL of the provider to verify")
+	vars := varFlags{}
+	flag.Var(vars, "var", "name=value for a request placeholder, repeatable")
+	flag.Parse()
+
+	contracts, err := contract.LoadDir(*dirFlag)
+	if err != nil {
+		log.Fatalf("Failed to load contracts: %v", err)
+	}
+
+	var failures int
+	if *providerFlag != "" {
+		if *urlFlag == "" {
+			log.Fatal("-url is required with -provider")
+		}
+		c := contract.Find(contracts, *providerFlag)
+		if c == nil {
+			log.Fatalf("No contract with provider %q in %s", *providerFlag, *dirFlag)
+		}
+		failures = verifyProvider(c, *urlFlag, vars)
+	} else {
+		for i := range contracts {
+			failures += verifyConsumer(&contracts[i])
+		}
+	}
+
+	if failures > 0 {
+		fmt.Printf("\n%d interaction(s) failed\n", failures)
+		os.Exit(1)
+	}
+	fmt.Println("\nAll interactions passed")
+}
+
+// report prints the outcome of one interaction and returns 1 on failure
+func report(c *contract.Contract, interaction *contract.Interaction, err error) int {
+	if err != nil {
+		fmt.Printf("FAIL %s: %s\n     %v\n", c.Provider, interaction.Description, err)
+		return 1
+	}
+	fmt.Printf("ok   %s: %s\n", c.Provider, interaction.Description)
+	return 0
+}
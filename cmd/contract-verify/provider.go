@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/contract"
+)
+
+// verifyProvider sends every interaction of the contract that a provider
+// can be asked for to the running provider and returns the number that
+// failed
+func verifyProvider(c *contract.Contract, baseURL string, overrides map[string]string) int {
+	client := &http.Client{Timeout: 10 * time.Second}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	var failures int
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		if interaction.ConsumerOnly {
+			continue
+		}
+		err := verifyInteraction(client, baseURL, interaction, c.VarsFor(interaction, overrides))
+		failures += report(c, interaction, err)
+	}
+	return failures
+}
+
+func verifyInteraction(client *http.Client, baseURL string, interaction *contract.Interaction, vars map[string]string) error {
+	req, err := http.NewRequest(interaction.Request.Method, baseURL+contract.Expand(interaction.Request.Path, vars), nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range interaction.Request.Headers {
+		req.Header.Set(name, contract.Expand(value, vars))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != interaction.Response.Status {
+		return fmt.Errorf("expected status %d, provider returned %d: %s", interaction.Response.Status, resp.StatusCode, body)
+	}
+	if len(interaction.Response.Body) == 0 {
+		return nil
+	}
+
+	var actual, expected interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return fmt.Errorf("provider returned invalid JSON: %w", err)
+	}
+	if err := json.Unmarshal(interaction.Response.Body, &expected); err != nil {
+		return fmt.Errorf("invalid response body in contract: %w", err)
+	}
+	return matchShape("$", actual, expected)
+}
+
+// matchShape checks that actual has every field of expected with the same
+// JSON type. Values may differ and extra fields are allowed; each item of
+// an array must have the shape of the expected array's first item.
+func matchShape(path string, actual, expected interface{}) error {
+	switch e := expected.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %s", path, jsonType(actual))
+		}
+		for key, want := range e {
+			got, present := a[key]
+			if !present {
+				return fmt.Errorf("%s.%s: missing", path, key)
+			}
+			if err := matchShape(path+"."+key, got, want); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %s", path, jsonType(actual))
+		}
+		if len(e) == 0 {
+			return nil
+		}
+		for i := range a {
+			if err := matchShape(fmt.Sprintf("%s[%d]", path, i), a[i], e[0]); err != nil {
+				return err
+			}
+		}
+	default:
+		if jsonType(actual) != jsonType(expected) {
+			return fmt.Errorf("%s: expected %s, got %s", path, jsonType(expected), jsonType(actual))
+		}
+	}
+	return nil
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
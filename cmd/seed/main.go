@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+)
+
+// SeedFile describes the data created for an organization. Settings take
+// the fields of the settings API, e.g. free_cancellation_days. Requests
+// that are not left pending are decided by ApproverID acting as an admin.
+type SeedFile struct {
+	Settings   map[string]interface{} `yaml:"settings"`
+	LeaveTypes []SeedLeaveType        `yaml:"leave_types"`
+	Holidays   []SeedHoliday          `yaml:"holidays"`
+	Employees  []SeedEmployee         `yaml:"employees"`
+	ApproverID uuid.UUID              `yaml:"approver_id"`
+	Requests   []SeedLeaveRequest     `yaml:"requests"`
+}
+
+type SeedLeaveType struct {
+	Name              string `yaml:"name"`
+	Description       string `yaml:"description"`
+	Color             string `yaml:"color"`
+	DefaultDays       int    `yaml:"default_days"`
+	IsPaid            bool   `yaml:"is_paid"`
+	RequiresApproval  *bool  `yaml:"requires_approval"`
+	MinDaysNotice     int    `yaml:"min_days_notice"`
+	MaxDaysPerRequest int    `yaml:"max_days_per_request"`
+	Rounding          string `yaml:"rounding"`
+	TracksBalance     *bool  `yaml:"tracks_balance"`
+	MinTenureDays     int    `yaml:"min_tenure_days"`
+}
+
+// SeedHoliday dates are month-day pairs (e.g. "12-25") placed in the current year
+type SeedHoliday struct {
+	Name string `yaml:"name"`
+	Date string `yaml:"date"`
+	Type string `yaml:"type"`
+}
+
+// SeedEmployee gets a balance for every seeded leave type that tracks one,
+// using the leave type default unless overridden by name in Balances
+type SeedEmployee struct {
+	ID       uuid.UUID          `yaml:"id"`
+	Balances map[string]float64 `yaml:"balances"`
+}
+
+// SeedLeaveRequest is submitted and then moved to Status, pending when
+// empty, through the same transitions as the API: approved and rejected
+// by the approver, cancelled by the employee. Comments go with the
+// decision; rejections need them.
+type SeedLeaveRequest struct {
+	EmployeeID uuid.UUID `yaml:"employee_id"`
+	LeaveType  string    `yaml:"leave_type"`
+	StartDate  string    `yaml:"start_date"`
+	EndDate    string    `yaml:"end_date"`
+	Reason     string    `yaml:"reason"`
+	Comment    string    `yaml:"comment"`
+	Status     string    `yaml:"status"`
+	Comments   string    `yaml:"comments"`
+}
+
+func main() {
+	orgFlag := flag.String("org", "", "organization ID to seed")
+	fileFlag := flag.String("file", "seed.yaml", "path to the YAML seed file")
+	wipeFlag := flag.Bool("wipe", false, "delete the organization's leave data before seeding")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	orgID, err := uuid.Parse(*orgFlag)
+	if err != nil {
+		log.Fatal("A valid -org is required:", err)
+	}
+
+	seed, err := loadSeedFile(*fileFlag)
+	if err != nil {
+		log.Fatal("Failed to load seed file:", err)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	db, err := gorm.Open(postgres.Open(dbURL), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	leaveService := service.NewLeaveService(repository.NewLeaveRepository(db), nil, false, nil)
+
+	if err := run(leaveService, orgID, seed, *wipeFlag); err != nil {
+		log.Fatal("Seeding failed:", err)
+	}
+
+	log.Printf("Seeded organization %s", orgID)
+}
+
+func loadSeedFile(path string) (*SeedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var seed SeedFile
+	if err := yaml.Unmarshal(data, &seed); err != nil {
+		return nil, err
+	}
+	return &seed, nil
+}
+
+// run creates everything through the service layer so balances and
+// requests stay consistent with what the API would produce. With wipe the
+// organization's existing leave data is purged first.
+func run(leaveService service.LeaveService, orgID uuid.UUID, seed *SeedFile, wipe bool) error {
+	if err := validateRequestStatuses(seed); err != nil {
+		return err
+	}
+
+	if wipe {
+		if err := leaveService.PurgeOrganization(orgID); err != nil {
+			return fmt.Errorf("wipe: %w", err)
+		}
+		log.Printf("Wiped organization %s", orgID)
+	}
+
+	year := time.Now().Year()
+
+	leaveTypes := make(map[string]*domain.LeaveType, len(seed.LeaveTypes))
+	for _, lt := range seed.LeaveTypes {
+		leaveType := &domain.LeaveType{
+			OrganizationID:    orgID,
+			Name:              lt.Name,
+			Description:       lt.Description,
+			Color:             lt.Color,
+			DefaultDays:       lt.DefaultDays,
+			IsPaid:            lt.IsPaid,
+			RequiresApproval:  lt.RequiresApproval == nil || *lt.RequiresApproval,
+			MinDaysNotice:     lt.MinDaysNotice,
+			MaxDaysPerRequest: lt.MaxDaysPerRequest,
+			Rounding:          lt.Rounding,
+			TracksBalance:     lt.TracksBalance == nil || *lt.TracksBalance,
+			MinTenureDays:     lt.MinTenureDays,
+		}
+		if err := leaveService.CreateLeaveType(leaveType); err != nil {
+			return fmt.Errorf("leave type %q: %w", lt.Name, err)
+		}
+		leaveTypes[lt.Name] = leaveType
+	}
+	log.Printf("Created %d leave types", len(leaveTypes))
+
+	// Settings come before requests, which some of them govern
+	if len(seed.Settings) > 0 {
+		var req domain.UpdateOrganizationSettingsRequest
+		data, err := json.Marshal(seed.Settings)
+		if err == nil {
+			err = json.Unmarshal(data, &req)
+		}
+		if err != nil {
+			return fmt.Errorf("settings: %w", err)
+		}
+		if _, err := leaveService.UpdateOrganizationSettings(orgID, &req); err != nil {
+			return fmt.Errorf("settings: %w", err)
+		}
+		log.Printf("Saved organization settings")
+	}
+
+	for _, h := range seed.Holidays {
+		date, err := time.Parse("2006-01-02", fmt.Sprintf("%d-%s", year, h.Date))
+		if err != nil {
+			return fmt.Errorf("holiday %q: invalid date %q", h.Name, h.Date)
+		}
+
+		holiday := &domain.Holiday{
+			OrganizationID: orgID,
+			Name:           h.Name,
+			Date:           date,
+			Type:           h.Type,
+		}
+		if err := leaveService.CreateHoliday(holiday, nil); err != nil {
+			return fmt.Errorf("holiday %q: %w", h.Name, err)
+		}
+	}
+	log.Printf("Created %d holidays", len(seed.Holidays))
+
+	for _, emp := range seed.Employees {
+		for name, leaveType := range leaveTypes {
+			days, ok := emp.Balances[name]
+			// Unpaid types have no entitlement to hand out; their balances
+			// open with the first request unless listed explicitly
+			if !leaveType.TracksBalance || (!leaveType.IsPaid && !ok) {
+				continue
+			}
+			totalDays := float64(leaveType.DefaultDays)
+			if ok {
+				totalDays = days
+			}
+
+			balance := &domain.LeaveBalance{
+				OrganizationID: orgID,
+				EmployeeID:     emp.ID,
+				LeaveTypeID:    leaveType.ID,
+				Year:           year,
+				TotalDays:      totalDays,
+			}
+			if err := leaveService.CreateLeaveBalance(balance); err != nil {
+				return fmt.Errorf("balance for employee %s, leave type %q: %w", emp.ID, name, err)
+			}
+		}
+	}
+	log.Printf("Created balances for %d employees", len(seed.Employees))
+
+	for i, r := range seed.Requests {
+		leaveType, ok := leaveTypes[r.LeaveType]
+		if !ok {
+			return fmt.Errorf("request %d: unknown leave type %q", i, r.LeaveType)
+		}
+
+		startDate, err := time.Parse("2006-01-02", r.StartDate)
+		if err != nil {
+			return fmt.Errorf("request %d: invalid start date %q", i, r.StartDate)
+		}
+		endDate, err := time.Parse("2006-01-02", r.EndDate)
+		if err != nil {
+			return fmt.Errorf("request %d: invalid end date %q", i, r.EndDate)
+		}
+
+		request, err := leaveService.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+			EmployeeID:  r.EmployeeID,
+			LeaveTypeID: leaveType.ID,
+			StartDate:   startDate,
+			EndDate:     endDate,
+			Status:      domain.LeaveStatusPending,
+			Reason:      r.Reason,
+			Comment:     r.Comment,
+			// Seeded requests may start at any date, notice period or not
+			BypassNotice: true,
+		})
+		if err != nil {
+			return fmt.Errorf("request %d: %w", i, err)
+		}
+		if err := decide(leaveService, orgID, seed.ApproverID, request, r); err != nil {
+			return fmt.Errorf("request %d: %w", i, err)
+		}
+	}
+	log.Printf("Created %d leave requests", len(seed.Requests))
+
+	return nil
+}
+
+// validateRequestStatuses checks every request's status up front, so a bad
+// file fails before anything is written
+func validateRequestStatuses(seed *SeedFile) error {
+	for i, r := range seed.Requests {
+		switch r.Status {
+		case "", domain.LeaveStatusPending, domain.LeaveStatusCancelled:
+		case domain.LeaveStatusApproved, domain.LeaveStatusRejected:
+			if seed.ApproverID == uuid.Nil {
+				return fmt.Errorf("request %d: approver_id is required to seed %s requests", i, r.Status)
+			}
+		default:
+			return fmt.Errorf("request %d: status must be pending, approved, rejected or cancelled, not %q", i, r.Status)
+		}
+	}
+	return nil
+}
+
+// decide moves a submitted request to its seeded status. Requests whose
+// leave type needs no approval are approved on submission already.
+func decide(leaveService service.LeaveService, orgID, approverID uuid.UUID, request *domain.LeaveRequest, r SeedLeaveRequest) error {
+	if r.Status == "" || r.Status == request.Status {
+		return nil
+	}
+
+	var err error
+	switch r.Status {
+	case domain.LeaveStatusApproved:
+		_, err = leaveService.ApproveLeaveRequest(orgID, request.ID, approverID, domain.RoleAdmin, r.Comments)
+	case domain.LeaveStatusRejected:
+		comments := r.Comments
+		if comments == "" {
+			comments = "Rejected while seeding"
+		}
+		_, err = leaveService.RejectLeaveRequest(orgID, request.ID, approverID, domain.RoleAdmin, comments)
+	case domain.LeaveStatusCancelled:
+		_, err = leaveService.CancelLeaveRequest(orgID, request.ID, request.EmployeeID, "", r.Comments)
+	}
+	return err
+}
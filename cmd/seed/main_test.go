@@ -0,0 +1,178 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/google/uuid"
+)
+
+// recordingService records the calls seeding makes, in order; every other
+// method is left unimplemented
+type recordingService struct {
+	service.LeaveService
+	calls     []string
+	settings  *domain.UpdateOrganizationSettingsRequest
+	decisions map[uuid.UUID]decision
+	requests  []*domain.LeaveRequest
+}
+
+// decision is who moved a request and how
+type decision struct {
+	deciderID uuid.UUID
+	role      string
+	comments  string
+}
+
+func (s *recordingService) PurgeOrganization(orgID uuid.UUID) error {
+	s.calls = append(s.calls, "purge")
+	return nil
+}
+
+func (s *recordingService) CreateLeaveType(leaveType *domain.LeaveType) error {
+	s.calls = append(s.calls, "leave_type")
+	leaveType.ID = uuid.New()
+	return nil
+}
+
+func (s *recordingService) UpdateOrganizationSettings(orgID uuid.UUID, req *domain.UpdateOrganizationSettingsRequest) (*domain.OrganizationSettings, error) {
+	s.calls = append(s.calls, "settings")
+	s.settings = req
+	return domain.DefaultOrganizationSettings(orgID), nil
+}
+
+func (s *recordingService) CreateHoliday(holiday *domain.Holiday, change *domain.HolidayChange) error {
+	s.calls = append(s.calls, "holiday")
+	return nil
+}
+
+func (s *recordingService) CreateLeaveBalance(balance *domain.LeaveBalance) error {
+	s.calls = append(s.calls, "balance")
+	return nil
+}
+
+func (s *recordingService) CreateLeaveRequest(orgID uuid.UUID, req *domain.CreateLeaveRequestRequest) (*domain.LeaveRequest, error) {
+	s.calls = append(s.calls, "request")
+	request := &domain.LeaveRequest{
+		Base:       domain.Base{ID: uuid.New()},
+		EmployeeID: req.EmployeeID,
+		Status:     domain.LeaveStatusPending,
+	}
+	s.requests = append(s.requests, request)
+	return request, nil
+}
+
+func (s *recordingService) decide(id, deciderID uuid.UUID, status, role, comments string) (*domain.LeaveRequest, error) {
+	s.calls = append(s.calls, status)
+	if s.decisions == nil {
+		s.decisions = make(map[uuid.UUID]decision)
+	}
+	s.decisions[id] = decision{deciderID: deciderID, role: role, comments: comments}
+	for _, r := range s.requests {
+		if r.ID == id {
+			r.Status = status
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *recordingService) ApproveLeaveRequest(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	return s.decide(id, approverID, domain.LeaveStatusApproved, role, comments)
+}
+
+func (s *recordingService) RejectLeaveRequest(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	return s.decide(id, rejectorID, domain.LeaveStatusRejected, role, comments)
+}
+
+func (s *recordingService) CancelLeaveRequest(orgID, id, cancelledBy uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	return s.decide(id, cancelledBy, domain.LeaveStatusCancelled, role, comments)
+}
+
+func TestRunSeedsExampleFile(t *testing.T) {
+	seed, err := loadSeedFile("seed.example.yaml")
+	if err != nil {
+		t.Fatalf("loading the example: %v", err)
+	}
+
+	svc := &recordingService{}
+	if err := run(svc, uuid.New(), seed, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if svc.calls[0] != "purge" {
+		t.Errorf("wipe ran after %v", svc.calls)
+	}
+	settingsAt, requestAt := -1, -1
+	for i, call := range svc.calls {
+		if call == "settings" && settingsAt < 0 {
+			settingsAt = i
+		}
+		if call == "request" && requestAt < 0 {
+			requestAt = i
+		}
+	}
+	if settingsAt < 0 || settingsAt > requestAt {
+		t.Errorf("settings were not saved before the requests: %v", svc.calls)
+	}
+	if svc.settings.FreeCancellationDays == nil || *svc.settings.FreeCancellationDays != 3 ||
+		svc.settings.Timezone == nil || *svc.settings.Timezone != "Europe/London" {
+		t.Errorf("settings = %+v", svc.settings)
+	}
+
+	want := []string{domain.LeaveStatusApproved, domain.LeaveStatusPending, domain.LeaveStatusRejected, domain.LeaveStatusCancelled}
+	if len(svc.requests) != len(want) {
+		t.Fatalf("created %d requests, want %d", len(svc.requests), len(want))
+	}
+	for i, request := range svc.requests {
+		if request.Status != want[i] {
+			t.Errorf("request %d is %s, want %s", i, request.Status, want[i])
+		}
+	}
+
+	if got := svc.decisions[svc.requests[0].ID]; got.deciderID != seed.ApproverID || got.role != domain.RoleAdmin {
+		t.Errorf("approval made by %s as %q, want the approver as an admin", got.deciderID, got.role)
+	}
+	if got := svc.decisions[svc.requests[2].ID]; got.deciderID != seed.ApproverID || got.comments != "Team is at minimum staffing that week" {
+		t.Errorf("rejection made by %s with %q", got.deciderID, got.comments)
+	}
+	// Employees cancel their own requests
+	if got := svc.decisions[svc.requests[3].ID]; got.deciderID != svc.requests[3].EmployeeID {
+		t.Errorf("cancellation made by %s, want the employee", got.deciderID)
+	}
+}
+
+func TestRunWithoutWipeKeepsData(t *testing.T) {
+	svc := &recordingService{}
+	if err := run(svc, uuid.New(), &SeedFile{}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range svc.calls {
+		if call == "purge" {
+			t.Fatal("purged without -wipe")
+		}
+	}
+}
+
+func TestRunRejectsBadStatusesBeforeWriting(t *testing.T) {
+	employee := uuid.New()
+	tests := []struct {
+		name string
+		seed *SeedFile
+	}{
+		{name: "unknown status", seed: &SeedFile{Requests: []SeedLeaveRequest{{EmployeeID: employee, Status: "expired"}}}},
+		{name: "approval without approver", seed: &SeedFile{Requests: []SeedLeaveRequest{{EmployeeID: employee, Status: "approved"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &recordingService{}
+			if err := run(svc, uuid.New(), tt.seed, true); err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(svc.calls) != 0 {
+				t.Errorf("wrote %v before failing", svc.calls)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -15,21 +16,38 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"github.com/Axontik/comin-leave-management-service/internal/config"
 	"github.com/Axontik/comin-leave-management-service/internal/handler"
 	"github.com/Axontik/comin-leave-management-service/internal/middleware"
 	"github.com/Axontik/comin-leave-management-service/internal/repository"
 	"github.com/Axontik/comin-leave-management-service/internal/service"
 	"github.com/Axontik/comin-leave-management-service/pkg/auth"
+	"github.com/Axontik/comin-leave-management-service/pkg/holidays"
 	"github.com/Axontik/comin-leave-management-service/pkg/organization"
 )
 
 type Application struct {
+	config              *config.Config
 	db                  *gorm.DB
+	authClient          *auth.AuthClient
+	orgClient           *organization.OrganizationClient
+	readOnly            *middleware.ReadOnlyMode
+	leaveService        service.LeaveService
 	leaveTypeHandler    *handler.LeaveTypeHandler
 	leaveRequestHandler *handler.LeaveRequestHandler
 	leaveBalanceHandler *handler.LeaveBalanceHandler
 	holidayHandler      *handler.HolidayHandler
 	reportHandler       *handler.ReportHandler
+	workingDaysHandler  *handler.WorkingDaysHandler
+	approverPoolHandler *handler.ApproverPoolHandler
+	settingsHandler     *handler.SettingsHandler
+	syncHandler         *handler.SyncHandler
+	maintenanceHandler  *handler.MaintenanceHandler
+	adminUIHandler      *handler.AdminUIHandler
+	jobHandler          *handler.JobHandler
+	compOffHandler      *handler.CompOffHandler
+	absenceAlertHandler *handler.AbsenceAlertHandler
+	delegationHandler   *handler.DelegationHandler
 }
 
 func main() {
@@ -37,7 +55,12 @@ func main() {
 		log.Printf("Warning: .env file not found")
 	}
 
-	app := &Application{}
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+
+	app := &Application{config: cfg}
 
 	// Initialize database
 	db, err := initDB()
@@ -53,13 +76,8 @@ func main() {
 	router := setupRouter(app)
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8083"
-	}
-
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	log.Printf("Server starting on port %s", cfg.Port)
+	if err := router.Run(":" + cfg.Port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
@@ -91,58 +109,81 @@ func initDB() (*gorm.DB, error) {
 }
 
 func (app *Application) initializeDependencies() {
+	// Initialize clients
+	app.authClient = auth.NewAuthClient(app.config.AuthServiceURL)
+	app.orgClient = organization.NewOrganizationClient(app.config.OrganizationServiceURL)
+	app.readOnly = middleware.NewReadOnlyMode(app.config.ReadOnly)
+	if app.config.ReadOnly {
+		log.Printf("Starting in read-only mode")
+	}
+
 	// Initialize repositories
 	leaveRepo := repository.NewLeaveRepository(app.db)
 
 	// Initialize services
-	leaveService := service.NewLeaveService(leaveRepo)
+	leaveService := service.NewLeaveService(leaveRepo, app.orgClient, app.config.EmployeeCheckFailOpen,
+		holidays.NewClient(app.config.HolidayProviderURL))
+	app.leaveService = leaveService
+	leaveService.RunJobWorkers(context.Background(), app.config.JobWorkers, app.readOnly.Enabled)
 
 	// Initialize handlers
 	app.leaveTypeHandler = handler.NewLeaveTypeHandler(leaveService)
 	app.leaveRequestHandler = handler.NewLeaveRequestHandler(leaveService)
 	app.leaveBalanceHandler = handler.NewLeaveBalanceHandler(leaveService)
-	app.holidayHandler = handler.NewHolidayHandler(leaveService)
-	app.reportHandler = handler.NewReportHandler(leaveService)
+	app.holidayHandler = handler.NewHolidayHandler(leaveService, app.config.CalendarTokenSecret)
+	app.reportHandler = handler.NewReportHandler(leaveService, app.config.LeaveRegisterMaxRows)
+	app.workingDaysHandler = handler.NewWorkingDaysHandler(leaveService)
+	app.approverPoolHandler = handler.NewApproverPoolHandler(leaveService)
+	app.settingsHandler = handler.NewSettingsHandler(leaveService)
+	app.syncHandler = handler.NewSyncHandler(leaveService)
+	app.maintenanceHandler = handler.NewMaintenanceHandler(app.readOnly)
+	app.jobHandler = handler.NewJobHandler(leaveService)
+	app.compOffHandler = handler.NewCompOffHandler(leaveService)
+	app.absenceAlertHandler = handler.NewAbsenceAlertHandler(leaveService)
+	app.delegationHandler = handler.NewDelegationHandler(leaveService)
+	if app.config.AdminUI {
+		app.adminUIHandler = handler.NewAdminUIHandler(leaveService)
+	}
 }
 
 func (app *Application) healthHandler(c *gin.Context) {
 	// Check DB connection
 	sqlDB, err := app.db.DB()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "unhealthy", "reason": "database connection error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "unhealthy", "reason": "database connection error", "read_only": app.readOnly.Enabled()})
 		return
 	}
 	err = sqlDB.Ping()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "unhealthy", "reason": "database ping failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "unhealthy", "reason": "database ping failed", "read_only": app.readOnly.Enabled()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
-		"time":   time.Now().UTC(),
+		"status":    "healthy",
+		"time":      time.Now().UTC(),
+		"read_only": app.readOnly.Enabled(),
 	})
 }
 
 func (app *Application) metricsHandler(c *gin.Context) {
 	// Implement your metrics logic here
+	jobs, err := app.leaveService.JobMetrics()
+	if err != nil {
+		log.Printf("Warning: cannot read job metrics: %v", err)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"total_requests": 0,
 		"active_users":   0,
 		"response_time":  0,
+		"read_only":      app.readOnly.Enabled(),
+		"jobs":           jobs,
 	})
 }
 
 func setupRouter(app *Application) *gin.Engine {
-	authClient := auth.NewAuthClient(os.Getenv("AUTH_SERVICE_URL"))
-	if authClient == nil {
-		authClient = auth.NewAuthClient("http://localhost:8080/api/v1/auth")
-	}
-
-	orgClient := organization.NewOrganizationClient("http://localhost:8081/api/v1")
-	if orgClient == nil {
-		orgClient = organization.NewOrganizationClient("http://localhost:8081/api/v1")
-	}
+	authClient := app.authClient
+	orgClient := app.orgClient
 
 	router := gin.New()
 
@@ -150,6 +191,7 @@ func setupRouter(app *Application) *gin.Engine {
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.ReadOnly(app.readOnly, "/health", "/metrics", "/internal/read-only"))
 	// router.Use(middleware.RequestID())
 	// router.Use(middleware.Timeout(10 * time.Second))
 	// router.Use(middleware.CORS())
@@ -159,10 +201,41 @@ func setupRouter(app *Application) *gin.Engine {
 	router.GET("/health", app.healthHandler)
 	router.GET("/metrics", app.metricsHandler)
 
+	// Internal admin routes
+	internal := router.Group("/internal")
+	internal.Use(middleware.InternalAdmin(app.config.InternalAdminToken))
+	{
+		internal.GET("/read-only", app.maintenanceHandler.GetReadOnly)
+		internal.PUT("/read-only", app.maintenanceHandler.SetReadOnly)
+		internal.POST("/accruals", app.leaveBalanceHandler.RunScheduledAccruals)
+		internal.POST("/absence-alerts", app.absenceAlertHandler.RunScheduledEvaluation)
+		internal.POST("/delegations/escalate", app.delegationHandler.RunScheduledEscalation)
+	}
+
+	// Operator console
+	if app.adminUIHandler != nil {
+		ui := router.Group("/internal/ui")
+		ui.Use(middleware.InternalAdminUI(app.config.InternalAdminToken))
+		{
+			ui.GET("/", app.adminUIHandler.Index)
+			ui.GET("/org", app.adminUIHandler.OpenOrganization)
+			ui.GET("/org/:organization_id", app.adminUIHandler.Organization)
+			ui.GET("/org/:organization_id/balances", app.adminUIHandler.Balances)
+			ui.GET("/static/admin.css", app.adminUIHandler.Stylesheet)
+		}
+	}
+
 	// API routes
 	api := router.Group("/api/v1")
 	// api.Use(middleware.APIVersionCheck("1.0"))
 	{
+		// Holiday feed calendar apps poll, with a calendar token when they
+		// cannot send the bearer token
+		api.GET("/organizations/:organization_id/holidays/calendar.ics",
+			middleware.CalendarAccess(app.config.CalendarTokenSecret,
+				organization.ValidateOrganizationAccess(authClient, orgClient)),
+			app.holidayHandler.ExportICS)
+
 		// Organization-specific routes
 		orgs := api.Group("/organizations/:organization_id")
 		orgs.Use(organization.ValidateOrganizationAccess(authClient, orgClient))
@@ -183,14 +256,22 @@ func setupRouter(app *Application) *gin.Engine {
 			leaveRequests := orgs.Group("/leave-requests")
 			{
 				leaveRequests.POST("/", app.leaveRequestHandler.Create)
-				// leaveRequests.GET("/", app.leaveRequestHandler.List)
-				// leaveRequests.GET("/:id", app.leaveRequestHandler.GetByID)
-				// leaveRequests.PUT("/:id", app.leaveRequestHandler.Update)
-				// leaveRequests.DELETE("/:id", app.leaveRequestHandler.Delete)
-				// leaveRequests.PUT("/:id/approve", app.leaveRequestHandler.Approve)
-				// leaveRequests.PUT("/:id/reject", app.leaveRequestHandler.Reject)
-				// leaveRequests.PUT("/:id/cancel", app.leaveRequestHandler.Cancel)
+				leaveRequests.GET("/", app.leaveRequestHandler.List)
+				leaveRequests.GET("/:id", app.leaveRequestHandler.GetByID)
+				leaveRequests.PUT("/:id", app.leaveRequestHandler.Update)
+				leaveRequests.DELETE("/:id", app.leaveRequestHandler.Delete)
+				leaveRequests.POST("/:id/restore", app.leaveRequestHandler.Restore)
+				leaveRequests.POST("/:id/audit-bundle", app.leaveRequestHandler.ExportAuditBundle)
+				leaveRequests.POST("/:id/reopen", app.leaveRequestHandler.Reopen)
+				leaveRequests.PUT("/:id/external-references", app.leaveRequestHandler.UpdateExternalReferences)
+				leaveRequests.PUT("/:id/approve", app.leaveRequestHandler.Approve)
+				leaveRequests.PUT("/:id/reject", app.leaveRequestHandler.Reject)
+				leaveRequests.PUT("/:id/cancel", app.leaveRequestHandler.Cancel)
+				leaveRequests.PUT("/:id/cancellation/approve", app.leaveRequestHandler.ApproveCancellation)
+				leaveRequests.PUT("/:id/cancellation/deny", app.leaveRequestHandler.DenyCancellation)
 				leaveRequests.GET("/calendar", app.leaveRequestHandler.GetCalendarView)
+				leaveRequests.GET("/changes", app.leaveRequestHandler.ListChanges)
+				leaveRequests.GET("/inbox", app.leaveRequestHandler.Inbox)
 				// leaveRequests.GET("/stats", app.leaveRequestHandler.GetStats)
 			}
 
@@ -198,10 +279,27 @@ func setupRouter(app *Application) *gin.Engine {
 			leaveBalances := orgs.Group("/leave-balances")
 			{
 				leaveBalances.GET("/", app.leaveBalanceHandler.List)
+				leaveBalances.GET("/changes", app.leaveBalanceHandler.ListAdjustmentChanges)
 				leaveBalances.GET("/:employee_id", app.leaveBalanceHandler.GetByEmployee)
 				leaveBalances.POST("/adjust", app.leaveBalanceHandler.AdjustBalance)
+				leaveBalances.GET("/adjustments", app.leaveBalanceHandler.ListAdjustments)
+				leaveBalances.POST("/adjustments/bulk", app.leaveBalanceHandler.BulkAdjust)
+				leaveBalances.PUT("/adjustments/:id/approve", app.leaveBalanceHandler.ApproveAdjustment)
+				leaveBalances.PUT("/adjustments/:id/reject", app.leaveBalanceHandler.RejectAdjustment)
+				leaveBalances.PUT("/adjustments/:id/revert", app.leaveBalanceHandler.RevertAdjustment)
+				leaveBalances.PUT("/adjustments/:id/cancel", app.leaveBalanceHandler.CancelAdjustment)
+				leaveBalances.POST("/adjustments/apply-due", app.leaveBalanceHandler.ApplyDueAdjustments)
+				leaveBalances.POST("/transfer", app.leaveBalanceHandler.Transfer)
+				leaveBalances.POST("/transfer/bulk", app.leaveBalanceHandler.BulkTransfer)
+				leaveBalances.GET("/transfer-jobs/:id", app.leaveBalanceHandler.GetTransferJob)
 				leaveBalances.GET("/history/:employee_id", app.leaveBalanceHandler.GetBalanceHistory)
+				leaveBalances.GET("/anomaly", app.leaveBalanceHandler.GetAnomaly)
+				leaveBalances.DELETE("/anomaly", app.leaveBalanceHandler.ClearAnomaly)
 				leaveBalances.POST("/yearly-reset", app.leaveBalanceHandler.YearlyReset)
+				leaveBalances.GET("/snapshots", app.leaveBalanceHandler.ListSnapshots)
+				leaveBalances.POST("/expire-carryover", app.leaveBalanceHandler.ExpireCarryOver)
+				leaveBalances.POST("/accrue", app.leaveBalanceHandler.AccrueLeave)
+				leaveBalances.POST("/recalculate", app.leaveBalanceHandler.RecalculateBalances)
 			}
 
 			// Holidays
@@ -209,11 +307,68 @@ func setupRouter(app *Application) *gin.Engine {
 			{
 				holidays.POST("/", app.holidayHandler.Create)
 				holidays.GET("/", app.holidayHandler.List)
+				holidays.POST("/bulk", app.holidayHandler.BulkImport)
+				holidays.POST("/import", app.holidayHandler.ImportPublic)
 				holidays.PUT("/:id", app.holidayHandler.Update)
 				holidays.DELETE("/:id", app.holidayHandler.Delete)
 				holidays.GET("/calendar", app.holidayHandler.GetCalendarView)
+				holidays.GET("/calendar-token", app.holidayHandler.CalendarToken)
+			}
+
+			// Settings
+			orgs.GET("/settings", app.settingsHandler.Get)
+			orgs.PUT("/settings", app.settingsHandler.Update)
+
+			// Approver pools
+			approverPools := orgs.Group("/approver-pools")
+			{
+				approverPools.POST("/", app.approverPoolHandler.Create)
+				approverPools.GET("/", app.approverPoolHandler.List)
+				approverPools.GET("/:id", app.approverPoolHandler.GetByID)
+				approverPools.POST("/:id/members", app.approverPoolHandler.AddMember)
+				approverPools.DELETE("/:id/members/:user_id", app.approverPoolHandler.RemoveMember)
+			}
+
+			// Background jobs
+			orgs.GET("/jobs/:id", app.jobHandler.Get)
+
+			// Comp-off
+			compOff := orgs.Group("/comp-off-requests")
+			{
+				compOff.POST("/", app.compOffHandler.Create)
+				compOff.GET("/", app.compOffHandler.List)
+				compOff.POST("/expire", app.compOffHandler.Expire)
+				compOff.GET("/:id", app.compOffHandler.GetByID)
+				compOff.PUT("/:id/approve", app.compOffHandler.Approve)
+				compOff.PUT("/:id/reject", app.compOffHandler.Reject)
+			}
+
+			// Absence alerts
+			absenceAlerts := orgs.Group("/absence-alerts")
+			{
+				absenceAlerts.GET("/", app.absenceAlertHandler.List)
+				absenceAlerts.POST("/evaluate", app.absenceAlertHandler.Evaluate)
+				absenceAlerts.PUT("/:id/acknowledge", app.absenceAlertHandler.Acknowledge)
+				absenceAlerts.PUT("/:id/resolve", app.absenceAlertHandler.Resolve)
+			}
+
+			// Delegations
+			delegations := orgs.Group("/delegations")
+			{
+				delegations.GET("/", app.delegationHandler.List)
+				delegations.PUT("/:id/confirm", app.delegationHandler.Confirm)
+				delegations.PUT("/:id/delegate", app.delegationHandler.ChangeDelegate)
+				delegations.PUT("/:id/decline", app.delegationHandler.Decline)
 			}
 
+			// Employees
+			orgs.GET("/employees/:employee_id/leave-history-summary", app.leaveBalanceHandler.GetLeaveHistorySummary)
+			orgs.POST("/employees/:employee_id/leave-balances/initialize", app.leaveBalanceHandler.InitializeEmployeeBalances)
+
+			// Working days
+			orgs.GET("/working-days/add", app.workingDaysHandler.Add)
+			orgs.GET("/calendar-definition", app.workingDaysHandler.CalendarDefinition)
+
 			// Reports
 			reports := orgs.Group("/reports")
 			// reports.Use(middleware.CachingMiddleware(10 * time.Minute))
@@ -221,16 +376,32 @@ func setupRouter(app *Application) *gin.Engine {
 				reports.GET("/leave-summary", app.reportHandler.LeaveSummary)
 				reports.GET("/department-analysis", app.reportHandler.DepartmentAnalysis)
 				reports.GET("/monthly-trends", app.reportHandler.MonthlyTrends)
+				reports.GET("/leave-register", app.reportHandler.LeaveRegister)
+				reports.GET("/bradford", app.reportHandler.Bradford)
+				reports.GET("/approver-performance", app.reportHandler.ApproverPerformance)
+				reports.GET("/holiday-impact", app.reportHandler.HolidayImpact)
 			}
 		}
 
 		// Employee-specific routes
 		employees := api.Group("/employees")
-		// employees.Use(auth.ValidateOrganizationAccess(authClient))
+		employees.Use(organization.ValidateOrganizationAccess(authClient, orgClient))
 		{
 			employees.GET("/:employee_id/leave-requests", app.leaveRequestHandler.ListByEmployee)
 			employees.GET("/:employee_id/leave-balance", app.leaveBalanceHandler.GetByEmployee)
 			employees.GET("/:employee_id/calendar", app.leaveRequestHandler.GetEmployeeCalendar)
+			employees.GET("/:employee_id/leave-statement", app.reportHandler.LeaveStatement)
+			employees.GET("/:employee_id/optional-holidays", app.holidayHandler.ListOptionalClaims)
+			employees.POST("/:employee_id/optional-holidays", app.holidayHandler.ClaimOptional)
+			employees.DELETE("/:employee_id/optional-holidays/:holiday_id", app.holidayHandler.UnclaimOptional)
+		}
+
+		// Current user routes
+		me := api.Group("/me")
+		me.Use(organization.ValidateOrganizationAccess(authClient, orgClient))
+		{
+			me.GET("/sync", app.syncHandler.Sync)
+			me.DELETE("/leave-requests/:id", app.leaveRequestHandler.WithdrawOwn)
 		}
 	}
 
@@ -0,0 +1,127 @@
+// internal/config/config.go
+package config
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+const EnvDevelopment = "development"
+
+// Config holds the settings read from the environment at startup
+type Config struct {
+	Env                    string
+	Port                   string
+	AuthServiceURL         string
+	OrganizationServiceURL string
+	// HolidayProviderURL is the Nager.Date compatible API public holidays
+	// are imported from
+	HolidayProviderURL string
+	// EmployeeCheckFailOpen accepts leave requests when the organization
+	// service cannot confirm the employee, instead of failing with 503
+	EmployeeCheckFailOpen bool
+	// ReadOnly starts the service refusing writes, for maintenance windows;
+	// it can be changed at runtime through the internal admin endpoint
+	ReadOnly bool
+	// InternalAdminToken guards the internal admin endpoints, which are
+	// disabled when it is empty
+	InternalAdminToken string
+	// CalendarTokenSecret signs the tokens calendar apps subscribe to the
+	// holiday calendar with; they are not issued nor accepted when empty
+	CalendarTokenSecret string
+	// AdminUI serves the read-only operator console under /internal/ui;
+	// self-hosted deployments can turn it off
+	AdminUI bool
+	// JobWorkers is how many background jobs the process runs at once;
+	// 0 leaves the queue to other replicas
+	JobWorkers int
+	// LeaveRegisterMaxRows is how many requests a leave register export may
+	// list; 0 lifts the limit
+	LeaveRegisterMaxRows int
+}
+
+// Load reads the configuration from the environment and validates the
+// outbound service URLs. Outside development a missing URL is an error;
+// in development it is left empty so requests needing that service fail
+// with 503 instead of the process refusing to start.
+func Load() (*Config, error) {
+	cfg := &Config{
+		Env:                    getEnv("APP_ENV", EnvDevelopment),
+		Port:                   getEnv("PORT", "8083"),
+		AuthServiceURL:         os.Getenv("AUTH_SERVICE_URL"),
+		OrganizationServiceURL: os.Getenv("ORGANIZATION_SERVICE_URL"),
+		HolidayProviderURL:     getEnv("HOLIDAY_PROVIDER_URL", "https://date.nager.at"),
+		EmployeeCheckFailOpen:  os.Getenv("EMPLOYEE_CHECK_FAIL_OPEN") == "true",
+		ReadOnly:               os.Getenv("READ_ONLY") == "true",
+		InternalAdminToken:     os.Getenv("INTERNAL_ADMIN_TOKEN"),
+		CalendarTokenSecret:    os.Getenv("CALENDAR_TOKEN_SECRET"),
+		AdminUI:                os.Getenv("ADMIN_UI_ENABLED") != "false",
+	}
+
+	jobWorkers, err := strconv.Atoi(getEnv("JOB_WORKERS", "2"))
+	if err != nil || jobWorkers < 0 {
+		return nil, fmt.Errorf("JOB_WORKERS must be a non-negative number, got %q", os.Getenv("JOB_WORKERS"))
+	}
+	cfg.JobWorkers = jobWorkers
+
+	leaveRegisterMaxRows, err := strconv.Atoi(getEnv("LEAVE_REGISTER_MAX_ROWS", "50000"))
+	if err != nil || leaveRegisterMaxRows < 0 {
+		return nil, fmt.Errorf("LEAVE_REGISTER_MAX_ROWS must be a non-negative number, got %q", os.Getenv("LEAVE_REGISTER_MAX_ROWS"))
+	}
+	cfg.LeaveRegisterMaxRows = leaveRegisterMaxRows
+
+	services := []struct {
+		name  string
+		value string
+	}{
+		{"AUTH_SERVICE_URL", cfg.AuthServiceURL},
+		{"ORGANIZATION_SERVICE_URL", cfg.OrganizationServiceURL},
+	}
+
+	for _, svc := range services {
+		if svc.value == "" {
+			if !cfg.IsDevelopment() {
+				return nil, fmt.Errorf("%s is required when APP_ENV is %q", svc.name, cfg.Env)
+			}
+			log.Printf("Warning: %s is not set, requests depending on it will fail with 503", svc.name)
+			continue
+		}
+		if err := validateServiceURL(svc.value); err != nil {
+			return nil, fmt.Errorf("%s: %w", svc.name, err)
+		}
+	}
+
+	if err := validateServiceURL(cfg.HolidayProviderURL); err != nil {
+		return nil, fmt.Errorf("HOLIDAY_PROVIDER_URL: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+func validateServiceURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL %q must use http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL %q has no host", raw)
+	}
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "development allows missing service URLs",
+			env:  map[string]string{},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.AuthServiceURL != "" || cfg.OrganizationServiceURL != "" {
+					t.Errorf("expected empty service URLs, got %q and %q", cfg.AuthServiceURL, cfg.OrganizationServiceURL)
+				}
+				if cfg.Port != "8083" || cfg.JobWorkers != 2 || cfg.LeaveRegisterMaxRows != 50000 || !cfg.AdminUI {
+					t.Errorf("unexpected defaults: %+v", cfg)
+				}
+			},
+		},
+		{
+			name:    "production requires the auth service",
+			env:     map[string]string{"APP_ENV": "production", "ORGANIZATION_SERVICE_URL": "https://org.example.com"},
+			wantErr: "AUTH_SERVICE_URL is required",
+		},
+		{
+			name:    "production requires the organization service",
+			env:     map[string]string{"APP_ENV": "production", "AUTH_SERVICE_URL": "https://auth.example.com"},
+			wantErr: "ORGANIZATION_SERVICE_URL is required",
+		},
+		{
+			name: "production with both services",
+			env: map[string]string{
+				"APP_ENV":                  "production",
+				"AUTH_SERVICE_URL":         "https://auth.example.com",
+				"ORGANIZATION_SERVICE_URL": "http://org.internal:8080",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.IsDevelopment() {
+					t.Error("production config reports development")
+				}
+			},
+		},
+		{
+			name:    "URL without a scheme",
+			env:     map[string]string{"AUTH_SERVICE_URL": "auth.example.com"},
+			wantErr: "must use http or https",
+		},
+		{
+			name:    "URL with another scheme",
+			env:     map[string]string{"ORGANIZATION_SERVICE_URL": "ftp://org.example.com"},
+			wantErr: "must use http or https",
+		},
+		{
+			name:    "URL without a host",
+			env:     map[string]string{"AUTH_SERVICE_URL": "http://"},
+			wantErr: "has no host",
+		},
+		{
+			name:    "invalid holiday provider",
+			env:     map[string]string{"HOLIDAY_PROVIDER_URL": "date.nager.at"},
+			wantErr: "HOLIDAY_PROVIDER_URL",
+		},
+		{
+			name:    "negative job workers",
+			env:     map[string]string{"JOB_WORKERS": "-1"},
+			wantErr: "JOB_WORKERS",
+		},
+		{
+			name:    "unparseable register limit",
+			env:     map[string]string{"LEAVE_REGISTER_MAX_ROWS": "lots"},
+			wantErr: "LEAVE_REGISTER_MAX_ROWS",
+		},
+		{
+			name: "switches",
+			env: map[string]string{
+				"READ_ONLY":                "true",
+				"EMPLOYEE_CHECK_FAIL_OPEN": "true",
+				"ADMIN_UI_ENABLED":         "false",
+				"JOB_WORKERS":              "0",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.ReadOnly || !cfg.EmployeeCheckFailOpen || cfg.AdminUI || cfg.JobWorkers != 0 {
+					t.Errorf("switches not applied: %+v", cfg)
+				}
+			},
+		},
+	}
+
+	keys := []string{
+		"APP_ENV", "PORT", "AUTH_SERVICE_URL", "ORGANIZATION_SERVICE_URL", "HOLIDAY_PROVIDER_URL",
+		"EMPLOYEE_CHECK_FAIL_OPEN", "READ_ONLY", "INTERNAL_ADMIN_TOKEN", "CALENDAR_TOKEN_SECRET",
+		"ADMIN_UI_ENABLED", "JOB_WORKERS", "LEAVE_REGISTER_MAX_ROWS",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range keys {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
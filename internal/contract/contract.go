@@ -0,0 +1,120 @@
+// Package contract reads the pact-style contracts in contracts/ between this
+// service and the services it calls, and replays them against the clients
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Contract is the set of interactions a consumer expects of a provider
+type Contract struct {
+	Consumer string `json:"consumer"`
+	Provider string `json:"provider"`
+	// Vars are example values for the {name} placeholders of requests
+	Vars         map[string]string `json:"vars"`
+	Interactions []Interaction     `json:"interactions"`
+}
+
+type Interaction struct {
+	Description string `json:"description"`
+	// Call names the client method that makes the request
+	Call string `json:"call"`
+	// Vars override the contract's variables for this interaction
+	Vars map[string]string `json:"vars"`
+	// ConsumerOnly interactions check how the client copes with responses
+	// a provider may send but cannot be asked to, such as unknown fields
+	ConsumerOnly bool     `json:"consumer_only"`
+	Request      Request  `json:"request"`
+	Response     Response `json:"response"`
+}
+
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+}
+
+type Response struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// VarsFor returns the contract's variables with overrides, such as those given
+// on the command line, and then the interaction's own applied over them. An
+// interaction sets its own only where it needs a particular value, such as
+// an invalid token.
+func (c *Contract) VarsFor(i *Interaction, overrides map[string]string) map[string]string {
+	vars := make(map[string]string)
+	for _, layer := range []map[string]string{c.Vars, overrides, i.Vars} {
+		for k, v := range layer {
+			vars[k] = v
+		}
+	}
+	return vars
+}
+
+// Expand replaces {name} placeholders with their variables
+func Expand(s string, vars map[string]string) string {
+	for k, v := range vars {
+		s = strings.ReplaceAll(s, "{"+k+"}", v)
+	}
+	return s
+}
+
+// Load reads one contract file
+func Load(path string) (*Contract, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parse(path, data)
+}
+
+// LoadDir reads every contract file in dir, ordered by name
+func LoadDir(dir string) ([]Contract, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no contract files in %s", dir)
+	}
+	sort.Strings(paths)
+
+	contracts := make([]Contract, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		contract, err := parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, *contract)
+	}
+	return contracts, nil
+}
+
+// Find returns the contract with the provider, or nil
+func Find(contracts []Contract, provider string) *Contract {
+	for i := range contracts {
+		if contracts[i].Provider == provider {
+			return &contracts[i]
+		}
+	}
+	return nil
+}
+
+func parse(path string, data []byte) (*Contract, error) {
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &contract, nil
+}
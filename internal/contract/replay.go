@@ -0,0 +1,129 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+)
+
+// Call makes an interaction's request through a real client pointed at
+// baseURL, returning what the client decoded
+type Call func(baseURL string, vars map[string]string) (interface{}, error)
+
+// Replay serves the interaction's response to the client, checking that
+// the request matches, then compares what the client decoded with the
+// response body. Error responses must make the client fail.
+func Replay(contract *Contract, interaction *Interaction, call Call) error {
+	vars := contract.VarsFor(interaction, nil)
+
+	var mismatch error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mismatch = MatchRequest(&interaction.Request, vars, r)
+		if mismatch != nil {
+			http.Error(w, mismatch.Error(), http.StatusTeapot)
+			return
+		}
+		if len(interaction.Response.Body) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(interaction.Response.Status)
+		w.Write(interaction.Response.Body)
+	}))
+	defer server.Close()
+
+	result, err := call(server.URL, vars)
+	if mismatch != nil {
+		return mismatch
+	}
+
+	if interaction.Response.Status < 200 || interaction.Response.Status > 299 {
+		if err == nil {
+			return fmt.Errorf("client accepted a %d response", interaction.Response.Status)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("client failed: %w", err)
+	}
+	return MatchDecoded(result, interaction.Response.Body)
+}
+
+// MatchRequest checks the client sent the method, path and headers the
+// interaction expects
+func MatchRequest(expected *Request, vars map[string]string, r *http.Request) error {
+	if r.Method != expected.Method {
+		return fmt.Errorf("expected method %s, client sent %s", expected.Method, r.Method)
+	}
+	if path := Expand(expected.Path, vars); r.URL.Path != path {
+		return fmt.Errorf("expected path %s, client sent %s", path, r.URL.Path)
+	}
+	for name, value := range expected.Headers {
+		if want := Expand(value, vars); r.Header.Get(name) != want {
+			return fmt.Errorf("expected header %s %q, client sent %q", name, want, r.Header.Get(name))
+		}
+	}
+	return nil
+}
+
+// MatchDecoded checks the client's result against the response body: every
+// field the client kept must hold the body's value, or its zero value when
+// the body left the field out. Body fields the client has no place for are
+// ignored.
+func MatchDecoded(result interface{}, body json.RawMessage) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var decoded, expected interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return err
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&expected); err != nil {
+		return fmt.Errorf("invalid response body in contract: %w", err)
+	}
+	return matchValue("$", decoded, expected)
+}
+
+func matchValue(path string, decoded, expected interface{}) error {
+	switch d := decoded.(type) {
+	case map[string]interface{}:
+		e, ok := expected.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: client decoded an object from %v", path, expected)
+		}
+		for key, value := range d {
+			want, present := e[key]
+			if !present {
+				if !isZero(value) {
+					return fmt.Errorf("%s.%s: missing from the response but decoded as %v", path, key, value)
+				}
+				continue
+			}
+			if err := matchValue(path+"."+key, value, want); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		e, ok := expected.([]interface{})
+		if !ok || len(e) != len(d) {
+			return fmt.Errorf("%s: client decoded %d items from %v", path, len(d), expected)
+		}
+		for i := range d {
+			if err := matchValue(fmt.Sprintf("%s[%d]", path, i), d[i], e[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		if !reflect.DeepEqual(decoded, expected) {
+			return fmt.Errorf("%s: response had %v, client decoded %v", path, expected, decoded)
+		}
+	}
+	return nil
+}
+
+func isZero(value interface{}) bool {
+	return value == nil || reflect.ValueOf(value).IsZero()
+}
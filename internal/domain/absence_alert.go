@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Absence alert statuses. HR acknowledges an open alert while reviewing it
+// and resolves it once the review is done.
+const (
+	AbsenceAlertStatusOpen         = "open"
+	AbsenceAlertStatusAcknowledged = "acknowledged"
+	AbsenceAlertStatusResolved     = "resolved"
+)
+
+// AbsenceAlert records an employee crossing the organization's rolling
+// absence threshold: Days or Spells of monitored leave in the 12 months
+// [WindowStart, WindowEnd] reached ThresholdDays or ThresholdSpells. It
+// stays uncleared while the employee remains over a threshold, so the
+// nightly evaluation raises no second alert; ClearedAt is set once they
+// fall back under both, and a later crossing raises a new alert.
+type AbsenceAlert struct {
+	Base
+	OrganizationID  uuid.UUID `json:"organization_id" gorm:"type:uuid;not null"`
+	EmployeeID      uuid.UUID `json:"employee_id" gorm:"type:uuid;not null"`
+	Status          string    `json:"status" gorm:"type:varchar(20);not null;default:'open'"`
+	WindowStart     time.Time `json:"window_start" gorm:"type:date;not null"`
+	WindowEnd       time.Time `json:"window_end" gorm:"type:date;not null"`
+	Days            float64   `json:"days" gorm:"type:decimal(6,2);not null"`
+	Spells          int       `json:"spells" gorm:"not null"`
+	ThresholdDays   float64   `json:"threshold_days" gorm:"type:decimal(6,2);not null"`
+	ThresholdSpells int       `json:"threshold_spells" gorm:"not null"`
+	// NotifiedAt is when HR was notified; nil while the notification is
+	// still to be delivered
+	NotifiedAt     *time.Time `json:"notified_at,omitempty"`
+	ClearedAt      *time.Time `json:"cleared_at,omitempty"`
+	AcknowledgedBy *uuid.UUID `json:"acknowledged_by,omitempty" gorm:"type:uuid"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty" gorm:"type:uuid"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	Comments       string     `json:"comments"`
+}
+
+// AbsenceAlertActionRequest carries the optional comment on acknowledging
+// or resolving an absence alert
+type AbsenceAlertActionRequest struct {
+	Comments string `json:"comments" binding:"max=1000"`
+}
+
+// ListAbsenceAlertsParams filters an organization's absence alerts
+type ListAbsenceAlertsParams struct {
+	Page       int
+	PageSize   int
+	Status     string
+	EmployeeID *uuid.UUID
+}
+
+// AbsenceAlertEvaluation is the outcome of evaluating an organization's
+// absence thresholds as of a date
+type AbsenceAlertEvaluation struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	AsOf           time.Time `json:"as_of"`
+	WindowStart    time.Time `json:"window_start"`
+	// Employees counts those with monitored leave in the window
+	Employees int `json:"employees"`
+	Raised    int `json:"raised"`
+	Cleared   int `json:"cleared"`
+	Notified  int `json:"notified"`
+	// NotifyFailed counts alerts whose notification failed; the next
+	// evaluation tries them again
+	NotifyFailed int `json:"notify_failed"`
+}
+
+// AbsenceAlertNotification is what HR is sent for a new absence alert
+type AbsenceAlertNotification struct {
+	Event string        `json:"event"`
+	Alert *AbsenceAlert `json:"alert"`
+}
+
+// AbsenceAlertRaisedEvent names the notification of a new absence alert
+const AbsenceAlertRaisedEvent = "absence_alert.raised"
+
+// AbsenceAlertWindow returns the first day of the rolling 12 months ending
+// on asOf, the day after the same date a year earlier; 29 February counts
+// from 28 February, so its window starts on 1 March
+func AbsenceAlertWindow(asOf time.Time) time.Time {
+	year, month, day := TruncateToDate(asOf).Date()
+	if month == time.February && day == 29 {
+		day = 28
+	}
+	return time.Date(year-1, month, day+1, 0, 0, 0, 0, time.UTC)
+}
+
+// CrossesAbsenceThreshold reports whether the totals reach either of the
+// organization's thresholds; a threshold of 0 is off
+func (s *OrganizationSettings) CrossesAbsenceThreshold(totals AbsenceTotals) bool {
+	return (s.AbsenceAlertDays > 0 && totals.Days >= s.AbsenceAlertDays) ||
+		(s.AbsenceAlertSpells > 0 && totals.Spells >= s.AbsenceAlertSpells)
+}
+
+// AbsenceAlertsEnabled reports whether either threshold is set
+func (s *OrganizationSettings) AbsenceAlertsEnabled() bool {
+	return s.AbsenceAlertDays > 0 || s.AbsenceAlertSpells > 0
+}
+
+// CanAcknowledge reports whether the alert is still open
+func (a *AbsenceAlert) CanAcknowledge() bool {
+	return a.Status == AbsenceAlertStatusOpen
+}
+
+// CanResolve reports whether the alert is not resolved yet
+func (a *AbsenceAlert) CanResolve() bool {
+	return a.Status == AbsenceAlertStatusOpen || a.Status == AbsenceAlertStatusAcknowledged
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAbsenceAlertWindow(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		asOf time.Time
+		want time.Time
+	}{
+		{name: "mid-year", asOf: date(2025, time.June, 30), want: date(2024, time.July, 1)},
+		{name: "year end", asOf: date(2025, time.December, 31), want: date(2025, time.January, 1)},
+		{name: "leap day", asOf: date(2024, time.February, 29), want: date(2023, time.March, 1)},
+		{name: "day before a leap day", asOf: date(2024, time.February, 28), want: date(2023, time.March, 1)},
+		{name: "a year after a leap day", asOf: date(2025, time.February, 28), want: date(2024, time.February, 29)},
+		{name: "time of day dropped", asOf: time.Date(2025, time.June, 30, 23, 30, 0, 0, time.UTC), want: date(2024, time.July, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AbsenceAlertWindow(tt.asOf); !got.Equal(tt.want) {
+				t.Errorf("AbsenceAlertWindow(%s) = %s, want %s", tt.asOf.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestCrossesAbsenceThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		days   float64
+		spells int
+		totals AbsenceTotals
+		want   bool
+	}{
+		{name: "off", totals: AbsenceTotals{Spells: 20, Days: 100}},
+		{name: "days reached", days: 8, totals: AbsenceTotals{Spells: 1, Days: 8}, want: true},
+		{name: "days under", days: 8, spells: 3, totals: AbsenceTotals{Spells: 2, Days: 7.5}},
+		{name: "spells reached", days: 8, spells: 3, totals: AbsenceTotals{Spells: 3, Days: 3}, want: true},
+		{name: "spells off", days: 8, totals: AbsenceTotals{Spells: 9, Days: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &OrganizationSettings{AbsenceAlertDays: tt.days, AbsenceAlertSpells: tt.spells}
+			if got := settings.CrossesAbsenceThreshold(tt.totals); got != tt.want {
+				t.Errorf("CrossesAbsenceThreshold(%+v) = %t, want %t", tt.totals, got, tt.want)
+			}
+		})
+	}
+}
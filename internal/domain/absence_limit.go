@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What happens to a request that takes a department over its concurrent
+// absence limit: block refuses it, warn saves it flagged for the approver
+const (
+	AbsenceLimitModeBlock = "block"
+	AbsenceLimitModeWarn  = "warn"
+)
+
+// AbsenceConflict is a day on which a request would take its employee's
+// department over the concurrent absence limit
+type AbsenceConflict struct {
+	Date time.Time `json:"date"`
+	// EmployeeIDs are the colleagues already off that day
+	EmployeeIDs []uuid.UUID `json:"employee_ids"`
+}
+
+// FindAbsenceConflicts returns the days of breakdown on which limit or more
+// colleagues are already off, going by colleagueDays, whose LeaveRequest
+// must be loaded. Half days count as absences; sandwiched days do not.
+func FindAbsenceConflicts(breakdown, colleagueDays []LeaveRequestDay, limit int) []AbsenceConflict {
+	off := make(map[time.Time]map[uuid.UUID]bool)
+	for _, d := range colleagueDays {
+		if d.Sandwiched || d.LeaveRequest == nil {
+			continue
+		}
+		day := TruncateToDate(d.Date)
+		if off[day] == nil {
+			off[day] = make(map[uuid.UUID]bool)
+		}
+		off[day][d.LeaveRequest.EmployeeID] = true
+	}
+
+	var conflicts []AbsenceConflict
+	for _, d := range breakdown {
+		day := TruncateToDate(d.Date)
+		if d.Sandwiched || len(off[day]) < limit {
+			continue
+		}
+		conflict := AbsenceConflict{Date: day}
+		for id := range off[day] {
+			conflict.EmployeeIDs = append(conflict.EmployeeIDs, id)
+		}
+		sort.Slice(conflict.EmployeeIDs, func(i, j int) bool {
+			return conflict.EmployeeIDs[i].String() < conflict.EmployeeIDs[j].String()
+		})
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
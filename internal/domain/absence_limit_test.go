@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFindAbsenceConflicts(t *testing.T) {
+	// 2026-03-02 is a Monday
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	ana, bo, cy := uuid.MustParse("00000000-0000-0000-0000-00000000000a"),
+		uuid.MustParse("00000000-0000-0000-0000-00000000000b"), uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	off := func(employeeID uuid.UUID, day time.Time, fraction float64, sandwiched bool) LeaveRequestDay {
+		return LeaveRequestDay{Date: day.Add(9 * time.Hour), Fraction: fraction, Sandwiched: sandwiched,
+			LeaveRequest: &LeaveRequest{EmployeeID: employeeID}}
+	}
+	colleagues := []LeaveRequestDay{
+		off(ana, monday, 1, false),
+		off(bo, monday, 0.5, false),
+		// Two requests of the same colleague count once
+		off(ana, monday.AddDate(0, 0, 1), 0.5, false),
+		off(ana, monday.AddDate(0, 0, 1), 0.5, false),
+		off(cy, monday.AddDate(0, 0, 1), 1, false),
+		// Sandwiched days are not absences
+		off(bo, monday.AddDate(0, 0, 2), 1, true),
+		off(cy, monday.AddDate(0, 0, 2), 1, false),
+		{Date: monday.AddDate(0, 0, 3), Fraction: 1},
+	}
+	breakdown := LeaveDayBreakdown(monday, monday.AddDate(0, 0, 3), "", "", nil)
+
+	conflicts := FindAbsenceConflicts(breakdown, colleagues, 2)
+	if len(conflicts) != 2 {
+		t.Fatalf("conflicts = %+v, want Monday and Tuesday", conflicts)
+	}
+	for i, want := range []struct {
+		date      time.Time
+		employees []uuid.UUID
+	}{
+		{date: monday, employees: []uuid.UUID{ana, bo}},
+		{date: monday.AddDate(0, 0, 1), employees: []uuid.UUID{ana, cy}},
+	} {
+		got := conflicts[i]
+		if !got.Date.Equal(want.date) || len(got.EmployeeIDs) != 2 ||
+			got.EmployeeIDs[0] != want.employees[0] || got.EmployeeIDs[1] != want.employees[1] {
+			t.Errorf("conflict %d = %+v, want %v off on %s", i, got, want.employees, want.date.Format("2006-01-02"))
+		}
+	}
+
+	if conflicts := FindAbsenceConflicts(breakdown, colleagues, 3); len(conflicts) != 0 {
+		t.Errorf("conflicts under a limit of 3 = %+v, want none", conflicts)
+	}
+}
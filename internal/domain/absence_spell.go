@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Absence is an approved request's leave as seen from a window: the
+// request's dates and the days of it falling in the window
+type Absence struct {
+	EmployeeID uuid.UUID
+	StartDate  time.Time
+	EndDate    time.Time
+	Days       float64
+}
+
+// AbsenceTotals are an employee's spells and days of absence in a window
+type AbsenceTotals struct {
+	EmployeeID uuid.UUID
+	Spells     int
+	Days       float64
+}
+
+// TotalAbsenceSpells merges each employee's absences into spells and
+// totals them, ordered by employee. An absence continues the current spell
+// when it starts no later than the first working day after the spell ends,
+// so overlapping and abutting absences, and those apart only by weekends
+// and the given holidays, are one spell.
+func TotalAbsenceSpells(absences []Absence, holidays []Holiday) []AbsenceTotals {
+	sorted := make([]Absence, len(absences))
+	copy(sorted, absences)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.EmployeeID != b.EmployeeID {
+			return a.EmployeeID.String() < b.EmployeeID.String()
+		}
+		if !a.StartDate.Equal(b.StartDate) {
+			return a.StartDate.Before(b.StartDate)
+		}
+		return a.EndDate.Before(b.EndDate)
+	})
+
+	closed := holidayDates(holidays)
+	var totals []AbsenceTotals
+	var spellEnd time.Time
+	for _, a := range sorted {
+		start, end := TruncateToDate(a.StartDate), TruncateToDate(a.EndDate)
+		last := len(totals) - 1
+		if last < 0 || totals[last].EmployeeID != a.EmployeeID {
+			totals = append(totals, AbsenceTotals{EmployeeID: a.EmployeeID, Spells: 1, Days: a.Days})
+			spellEnd = end
+			continue
+		}
+
+		totals[last].Days += a.Days
+		if start.After(nextWorkingDay(spellEnd, closed)) {
+			totals[last].Spells++
+			spellEnd = end
+		} else if end.After(spellEnd) {
+			spellEnd = end
+		}
+	}
+	return totals
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTotalAbsenceSpells(t *testing.T) {
+	employee := uuid.New()
+	other := uuid.New()
+	// Monday 2025-06-02
+	day := func(offset int) time.Time { return time.Date(2025, time.June, 2+offset, 0, 0, 0, 0, time.UTC) }
+	absence := func(id uuid.UUID, start, end int, days float64) Absence {
+		return Absence{EmployeeID: id, StartDate: day(start), EndDate: day(end), Days: days}
+	}
+	fridayHoliday := []Holiday{{Name: "Holiday", Date: day(4)}}
+
+	tests := []struct {
+		name     string
+		absences []Absence
+		holidays []Holiday
+		want     []AbsenceTotals
+	}{
+		{
+			name:     "one request",
+			absences: []Absence{absence(employee, 0, 2, 3)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 3}},
+		},
+		{
+			name:     "abutting",
+			absences: []Absence{absence(employee, 0, 1, 2), absence(employee, 2, 2, 1)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 3}},
+		},
+		{
+			name:     "overlapping",
+			absences: []Absence{absence(employee, 0, 3, 4), absence(employee, 1, 2, 2)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 6}},
+		},
+		{
+			name:     "split by a weekend",
+			absences: []Absence{absence(employee, 2, 4, 3), absence(employee, 7, 8, 2)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 5}},
+		},
+		{
+			name:     "split by a weekend and a holiday",
+			absences: []Absence{absence(employee, 2, 3, 2), absence(employee, 7, 8, 2)},
+			holidays: fridayHoliday,
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 4}},
+		},
+		{
+			name:     "a working day between",
+			absences: []Absence{absence(employee, 2, 3, 2), absence(employee, 7, 8, 2)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 2, Days: 4}},
+		},
+		{
+			name:     "starting on the weekend after",
+			absences: []Absence{absence(employee, 0, 4, 5), absence(employee, 6, 6, 0)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 5}},
+		},
+		{
+			name:     "unordered, a later request covering the gap",
+			absences: []Absence{absence(employee, 9, 9, 1), absence(employee, 0, 0, 1), absence(employee, 0, 8, 7)},
+			want:     []AbsenceTotals{{EmployeeID: employee, Spells: 1, Days: 9}},
+		},
+		{
+			name:     "employees kept apart",
+			absences: []Absence{absence(employee, 0, 1, 2), absence(other, 2, 2, 1)},
+			want: []AbsenceTotals{
+				{EmployeeID: employee, Spells: 1, Days: 2},
+				{EmployeeID: other, Spells: 1, Days: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TotalAbsenceSpells(tt.absences, tt.holidays)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			want := make(map[uuid.UUID]AbsenceTotals, len(tt.want))
+			for _, w := range tt.want {
+				want[w.EmployeeID] = w
+			}
+			for _, g := range got {
+				if g != want[g.EmployeeID] {
+					t.Errorf("got %+v, want %+v", g, want[g.EmployeeID])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How often a leave type's allowance is accrued. Types that do not accrue
+// open their balances at the full DefaultDays.
+const (
+	AccrualFrequencyNone      = "none"
+	AccrualFrequencyMonthly   = "monthly"
+	AccrualFrequencyQuarterly = "quarterly"
+)
+
+// AccrualPeriodLayout is the format of accrual periods, a calendar month
+const AccrualPeriodLayout = "2006-01"
+
+// IsValidAccrualFrequency reports whether frequency is supported. An empty
+// frequency is treated as AccrualFrequencyNone.
+func IsValidAccrualFrequency(frequency string) bool {
+	return frequency == "" || frequency == AccrualFrequencyNone ||
+		frequency == AccrualFrequencyMonthly || frequency == AccrualFrequencyQuarterly
+}
+
+// Accrues reports whether the leave type's allowance is granted through
+// accruals rather than up front
+func (t *LeaveType) Accrues() bool {
+	return t.AccrualFrequency == AccrualFrequencyMonthly || t.AccrualFrequency == AccrualFrequencyQuarterly
+}
+
+// AccruesIn reports whether the leave type accrues for the given month.
+// Quarterly types accrue at the end of each quarter.
+func (t *LeaveType) AccruesIn(month time.Month) bool {
+	switch t.AccrualFrequency {
+	case AccrualFrequencyMonthly:
+		return true
+	case AccrualFrequencyQuarterly:
+		return month%3 == 0
+	}
+	return false
+}
+
+// Accrual is what one period grants a balance that has accrued the given
+// amount so far this year: the type's AccrualRate, but no more than what
+// is left of the yearly allowance, which caps the year's accruals
+func (t *LeaveType) Accrual(accrued, hoursPerDay float64) float64 {
+	left := t.Allowance(hoursPerDay) - accrued
+	if left <= 0 {
+		return 0
+	}
+	return math.Min(t.AccrualRate, left)
+}
+
+// CapAccrual splits an accrual of days into what a balance banking banked
+// days may be credited under the type's MaxBalanceDays and what the cap
+// forfeits
+func (t *LeaveType) CapAccrual(banked, days float64) (credited, forfeited float64) {
+	if t.MaxBalanceDays == nil {
+		return days, 0
+	}
+	room := math.Max(*t.MaxBalanceDays-banked, 0)
+	if days <= room {
+		return days, 0
+	}
+	return room, days - room
+}
+
+// AtBalanceCap reports whether a balance banking banked days has reached
+// the type's MaxBalanceDays
+func (t *LeaveType) AtBalanceCap(banked float64) bool {
+	return t.MaxBalanceDays != nil && banked >= *t.MaxBalanceDays
+}
+
+// ForfeitedAccrualPerMonth is how much of the type's accrual a balance
+// banking banked days loses to the cap each month, a third of the
+// quarterly loss for quarterly types
+func (t *LeaveType) ForfeitedAccrualPerMonth(banked float64) float64 {
+	if !t.Accrues() {
+		return 0
+	}
+	_, forfeited := t.CapAccrual(banked, t.AccrualRate)
+	if t.AccrualFrequency == AccrualFrequencyQuarterly {
+		forfeited /= 3
+	}
+	return math.Round(forfeited*100) / 100
+}
+
+// ParseAccrualPeriod parses a YYYY-MM period into the first day of the month
+func ParseAccrualPeriod(period string) (time.Time, error) {
+	month, err := time.Parse(AccrualPeriodLayout, period)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid accrual period %q, expected YYYY-MM", period)
+	}
+	return month, nil
+}
+
+// AccrualRun records that a balance accrued for a period. There is one per
+// employee, leave type and period, which makes rerunning a period a no-op.
+// Days is 0 and AdjustmentID nil when the year's allowance was already
+// accrued or the balance was at its bank cap. Capped runs credited less
+// than the accrual because of the bank cap, ForfeitedDays less.
+type AccrualRun struct {
+	Base
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null"`
+	EmployeeID     uuid.UUID  `json:"employee_id" gorm:"type:uuid;not null"`
+	LeaveTypeID    uuid.UUID  `json:"leave_type_id" gorm:"type:uuid;not null"`
+	LeaveBalanceID uuid.UUID  `json:"leave_balance_id" gorm:"type:uuid;not null"`
+	Period         string     `json:"period" gorm:"type:varchar(7);not null"`
+	Days           float64    `json:"days" gorm:"type:decimal(5,2);not null"`
+	AdjustmentID   *uuid.UUID `json:"adjustment_id,omitempty" gorm:"type:uuid"`
+	Capped         bool       `json:"capped" gorm:"not null;default:false"`
+	ForfeitedDays  float64    `json:"forfeited_days" gorm:"type:decimal(5,2);not null;default:0"`
+}
+
+// AccrueLeaveRequest runs the accruals of a period, by default the current
+// month in the organization's time zone
+type AccrueLeaveRequest struct {
+	Period string `json:"period" binding:"omitempty,len=7"`
+}
+
+// AccrualSummary is the outcome of accruing a period. Accrued counts the
+// balances granted days, Capped those that had reached the year's
+// allowance and AlreadyAccrued those a previous run of the period handled.
+// BankCapped counts the balances the bank cap credited less than their
+// accrual, forfeiting ForfeitedDays in total. Failed balances are logged
+// and picked up by the next run.
+type AccrualSummary struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Period         string    `json:"period"`
+	LeaveTypes     int       `json:"leave_types"`
+	Accrued        int       `json:"accrued"`
+	AccruedDays    float64   `json:"accrued_days"`
+	Capped         int       `json:"capped"`
+	AlreadyAccrued int       `json:"already_accrued"`
+	BankCapped     int       `json:"bank_capped"`
+	ForfeitedDays  float64   `json:"forfeited_days"`
+	Failed         int       `json:"failed"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "testing"
+
+func TestLeaveTypeCapAccrual(t *testing.T) {
+	capDays := 30.0
+	tests := []struct {
+		name          string
+		cap           *float64
+		banked        float64
+		days          float64
+		wantCredited  float64
+		wantForfeited float64
+	}{
+		{name: "uncapped", banked: 100, days: 2, wantCredited: 2},
+		{name: "below the cap", cap: &capDays, banked: 20, days: 2, wantCredited: 2},
+		{name: "reaching the cap", cap: &capDays, banked: 28, days: 2, wantCredited: 2},
+		{name: "crossing the cap", cap: &capDays, banked: 29.5, days: 2, wantCredited: 0.5, wantForfeited: 1.5},
+		{name: "at the cap", cap: &capDays, banked: 30, days: 2, wantForfeited: 2},
+		{name: "over the cap by an override", cap: &capDays, banked: 33, days: 2, wantForfeited: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaveType := &LeaveType{MaxBalanceDays: tt.cap}
+			credited, forfeited := leaveType.CapAccrual(tt.banked, tt.days)
+			if credited != tt.wantCredited || forfeited != tt.wantForfeited {
+				t.Errorf("got %v credited, %v forfeited, want %v and %v", credited, forfeited, tt.wantCredited, tt.wantForfeited)
+			}
+		})
+	}
+}
+
+func TestLeaveTypeForfeitedAccrualPerMonth(t *testing.T) {
+	capDays := 30.0
+	tests := []struct {
+		name      string
+		frequency string
+		rate      float64
+		cap       *float64
+		banked    float64
+		wantAtCap bool
+		want      float64
+	}{
+		{name: "monthly at the cap", frequency: AccrualFrequencyMonthly, rate: 2, cap: &capDays, banked: 30, wantAtCap: true, want: 2},
+		{name: "monthly near the cap", frequency: AccrualFrequencyMonthly, rate: 2, cap: &capDays, banked: 29, want: 1},
+		{name: "quarterly at the cap", frequency: AccrualFrequencyQuarterly, rate: 5, cap: &capDays, banked: 31, wantAtCap: true, want: 1.67},
+		{name: "uncapped", frequency: AccrualFrequencyMonthly, rate: 2, banked: 60},
+		{name: "not accruing", frequency: AccrualFrequencyNone, cap: &capDays, banked: 30, wantAtCap: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaveType := &LeaveType{AccrualFrequency: tt.frequency, AccrualRate: tt.rate, MaxBalanceDays: tt.cap}
+			if got := leaveType.AtBalanceCap(tt.banked); got != tt.wantAtCap {
+				t.Errorf("at cap = %v, want %v", got, tt.wantAtCap)
+			}
+			if got := leaveType.ForfeitedAccrualPerMonth(tt.banked); got != tt.want {
+				t.Errorf("forfeited per month = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
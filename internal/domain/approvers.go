@@ -0,0 +1,44 @@
+package domain
+
+import "github.com/google/uuid"
+
+// Approval strategies selectable per leave type
+const (
+	ApprovalStrategyDirectManager = "direct_manager"
+	ApprovalStrategyRoleBased     = "role_based"
+	ApprovalStrategyRoundRobin    = "round_robin"
+	ApprovalStrategyQuorum        = "quorum"
+)
+
+// ApproverPool is a group of users that take turns approving requests.
+// LastAssignedUserID points at the member who received the previous request.
+type ApproverPool struct {
+	Base
+	OrganizationID     uuid.UUID            `json:"organization_id" gorm:"type:uuid;not null"`
+	Name               string               `json:"name" gorm:"not null"`
+	LastAssignedUserID *uuid.UUID           `json:"last_assigned_user_id,omitempty" gorm:"type:uuid"`
+	Members            []ApproverPoolMember `json:"members,omitempty" gorm:"foreignKey:PoolID"`
+}
+
+// ApproverPoolMember is a user taking part in an approver pool's rotation
+type ApproverPoolMember struct {
+	Base
+	PoolID uuid.UUID `json:"pool_id" gorm:"type:uuid;not null"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+}
+
+type CreateApproverPoolRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=100"`
+}
+
+type AddApproverPoolMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// LeaveRequestApproval is one candidate's sign-off on a pending request of
+// a leave type using the quorum strategy
+type LeaveRequestApproval struct {
+	Base
+	LeaveRequestID uuid.UUID `json:"leave_request_id" gorm:"type:uuid;not null"`
+	ApproverID     uuid.UUID `json:"approver_id" gorm:"type:uuid;not null"`
+}
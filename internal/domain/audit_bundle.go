@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveActionAuditExport records that a request's audit bundle was
+// generated; it keeps the request's status
+const LeaveActionAuditExport = "audit_export"
+
+// LeaveRequestAuditBundle is everything this service records about one
+// leave request, gathered into a single document for disputes. Request
+// includes deleted requests with their day breakdown, approvals and
+// external references; History runs oldest first and ends with the export
+// that produced the bundle. Edits are recorded as update entries, not as
+// snapshots, so earlier versions of the dates cannot be rebuilt from it.
+type LeaveRequestAuditBundle struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	GeneratedBy uuid.UUID             `json:"generated_by"`
+	Request     *LeaveRequest         `json:"request"`
+	History     []LeaveRequestHistory `json:"history"`
+	// NotRecorded names the records legal may expect that this service
+	// does not keep, such as attachments and notifications sent, so their
+	// absence is explicit rather than mistaken for an empty list
+	NotRecorded []string `json:"not_recorded"`
+}
+
+// AuditBundleNotRecorded lists what LeaveRequestAuditBundle.NotRecorded reports
+var AuditBundleNotRecorded = []string{"attachments", "notifications", "webhook_deliveries", "hash_chain_verification"}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Balance anomaly monitor defaults for organizations without saved settings
+const (
+	DefaultAnomalyMaxMutationDays    = 60
+	DefaultAnomalyMaxWritesPerMinute = 500
+)
+
+// Kinds of balance anomaly
+const (
+	// BalanceAnomalyLargeMutation is a single balance change larger than
+	// the organization's threshold
+	BalanceAnomalyLargeMutation = "large_mutation"
+	// BalanceAnomalyWriteRate is more balance writes in a minute than the
+	// organization's threshold
+	BalanceAnomalyWriteRate = "write_rate"
+)
+
+// Paths that change balances, as reported to the anomaly monitor
+const (
+	BalanceMutationAdjustment   = "adjustment"
+	BalanceMutationLeaveRequest = "leave_request"
+	BalanceMutationTransfer     = "transfer"
+)
+
+// BalanceAnomaly is the alert raised when an organization's balance changes
+// look like a runaway bug. It stays active until an admin clears it; while
+// it is active and AdjustmentsHeld is set, balance adjustments cannot be
+// approved on creation.
+type BalanceAnomaly struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Kind           string    `json:"kind"`
+	// Source is the mutation path that tripped the threshold
+	Source string `json:"source"`
+	// Delta is the size of the mutation that raised a large_mutation alert
+	Delta float64 `json:"delta,omitempty"`
+	// Writes is the balance writes counted in the minute that raised a
+	// write_rate alert
+	Writes          int       `json:"writes,omitempty"`
+	Threshold       float64   `json:"threshold"`
+	AdjustmentsHeld bool      `json:"adjustments_held"`
+	DetectedAt      time.Time `json:"detected_at"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of balance ledger entries
+const (
+	LedgerEntryAdjustment   = "adjustment"
+	LedgerEntryTransfer     = "transfer"
+	LedgerEntryLeave        = "leave"
+	LedgerEntryCancellation = "cancellation"
+)
+
+// BalanceLedgerEntry is one change to a balance: an approved adjustment,
+// days transferred to or from another leave type, leave taken when its
+// request was approved, or leave given back when an approved request was
+// cancelled. RunningBalance is the balance after it, counting used days
+// but not pending ones. A transfer is one entry on each side's ledger,
+// linked by TransferID; CounterpartLeaveTypeID is the other side's type.
+type BalanceLedgerEntry struct {
+	Type           string     `json:"type"`
+	OccurredAt     time.Time  `json:"occurred_at"`
+	Amount         float64    `json:"amount"`
+	RunningBalance float64    `json:"running_balance"`
+	LeaveRequestID *uuid.UUID `json:"leave_request_id,omitempty"`
+	AdjustmentID   *uuid.UUID `json:"adjustment_id,omitempty"`
+	Reason         string     `json:"reason"`
+	PerformedBy    *uuid.UUID `json:"performed_by,omitempty"`
+	TransferID     *uuid.UUID `json:"transfer_id,omitempty"`
+	// CounterpartLeaveTypeID is the leave type the days of a transfer came
+	// from or went to
+	CounterpartLeaveTypeID *uuid.UUID `json:"counterpart_leave_type_id,omitempty"`
+	// BalanceBefore and BalanceAfter are the total days an adjustment
+	// found and left on the balance, when it recorded them
+	BalanceBefore *float64 `json:"balance_before,omitempty"`
+	BalanceAfter  *float64 `json:"balance_after,omitempty"`
+}
+
+// BalanceLedger is a page of a balance's entries, oldest first.
+// OpeningBalance is the entitlement before any adjustment. ClosingBalance
+// is where all entries, not only this page's, lead; it should equal the
+// stored total less used days, and Reconciled is false when it does not.
+type BalanceLedger struct {
+	LeaveBalanceID uuid.UUID            `json:"leave_balance_id"`
+	EmployeeID     uuid.UUID            `json:"employee_id"`
+	LeaveTypeID    uuid.UUID            `json:"leave_type_id"`
+	Year           int                  `json:"year"`
+	OpeningBalance float64              `json:"opening_balance"`
+	ClosingBalance float64              `json:"closing_balance"`
+	StoredBalance  float64              `json:"stored_balance"`
+	Reconciled     bool                 `json:"reconciled"`
+	Entries        []BalanceLedgerEntry `json:"entries"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+)
+
+// BalanceSnapshot records a balance as the yearly reset left it: the
+// opening position of the year, including any carry-over and the requests
+// already booked against it. RunID is the id of the yearly reset job that
+// wrote it. Snapshots are only ever inserted, so they remain the
+// authoritative opening balance however the balance changes afterwards.
+type BalanceSnapshot struct {
+	Base
+	OrganizationID  uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null"`
+	RunID           uuid.UUID  `json:"run_id" gorm:"type:uuid;not null"`
+	EmployeeID      uuid.UUID  `json:"employee_id" gorm:"type:uuid;not null"`
+	LeaveTypeID     uuid.UUID  `json:"leave_type_id" gorm:"type:uuid;not null"`
+	LeaveBalanceID  uuid.UUID  `json:"leave_balance_id" gorm:"type:uuid;not null"`
+	Year            int        `json:"year" gorm:"not null"`
+	TotalDays       float64    `json:"total_days" gorm:"type:decimal(5,2);not null"`
+	UsedDays        float64    `json:"used_days" gorm:"type:decimal(5,2);not null"`
+	PendingDays     float64    `json:"pending_days" gorm:"type:decimal(5,2);not null"`
+	RemainingDays   float64    `json:"remaining_days" gorm:"type:decimal(5,2);not null"`
+	CarriedOverDays float64    `json:"carried_over_days" gorm:"type:decimal(5,2);not null"`
+	LeaveType       *LeaveType `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+}
+
+// NewBalanceSnapshot captures the balance's current figures for the run
+func NewBalanceSnapshot(runID uuid.UUID, balance *LeaveBalance) *BalanceSnapshot {
+	return &BalanceSnapshot{
+		OrganizationID:  balance.OrganizationID,
+		RunID:           runID,
+		EmployeeID:      balance.EmployeeID,
+		LeaveTypeID:     balance.LeaveTypeID,
+		LeaveBalanceID:  balance.ID,
+		Year:            balance.Year,
+		TotalDays:       balance.TotalDays,
+		UsedDays:        balance.UsedDays,
+		PendingDays:     balance.PendingDays,
+		RemainingDays:   balance.RemainingDays,
+		CarriedOverDays: balance.CarriedOverDays,
+	}
+}
+
+// ListBalanceSnapshotsParams filters an organization's snapshots; RunID
+// and EmployeeID are optional
+type ListBalanceSnapshotsParams struct {
+	Page       int
+	PageSize   int
+	RunID      *uuid.UUID
+	EmployeeID *uuid.UUID
+}
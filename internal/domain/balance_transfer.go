@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Balance transfer job statuses
+const (
+	TransferJobStatusQueued    = "queued"
+	TransferJobStatusRunning   = "running"
+	TransferJobStatusCompleted = "completed"
+	TransferJobStatusFailed    = "failed"
+)
+
+// BalanceTransferRequest moves an employee's unused days from one leave
+// type's balance to another's for the same year. Either Days or
+// AllRemaining is set.
+type BalanceTransferRequest struct {
+	EmployeeID      uuid.UUID `json:"employee_id" binding:"required"`
+	FromLeaveTypeID uuid.UUID `json:"from_leave_type_id" binding:"required"`
+	ToLeaveTypeID   uuid.UUID `json:"to_leave_type_id" binding:"required"`
+	// Year defaults to the current year
+	Year         int       `json:"year"`
+	Days         float64   `json:"days" binding:"min=0"`
+	AllRemaining bool      `json:"all_remaining"`
+	Reason       string    `json:"reason" binding:"required,min=5,max=500"`
+	PerformedBy  uuid.UUID `json:"-"`
+}
+
+// BalanceTransfer is the pair of adjustments recording one transfer. Both
+// carry the same TransferID and point at each other through CounterpartID.
+type BalanceTransfer struct {
+	TransferID uuid.UUID               `json:"transfer_id"`
+	Debit      *LeaveBalanceAdjustment `json:"debit"`
+	Credit     *LeaveBalanceAdjustment `json:"credit"`
+}
+
+// BulkBalanceTransferRequest runs the same transfer for several employees,
+// or with All for every employee holding a balance of the source type
+type BulkBalanceTransferRequest struct {
+	EmployeeIDs     []uuid.UUID `json:"employee_ids"`
+	All             bool        `json:"all"`
+	FromLeaveTypeID uuid.UUID   `json:"from_leave_type_id" binding:"required"`
+	ToLeaveTypeID   uuid.UUID   `json:"to_leave_type_id" binding:"required"`
+	Year            int         `json:"year"`
+	Days            float64     `json:"days" binding:"min=0"`
+	AllRemaining    bool        `json:"all_remaining"`
+	Reason          string      `json:"reason" binding:"required,min=5,max=500"`
+	PerformedBy     uuid.UUID   `json:"-"`
+}
+
+// BalanceTransferJob tracks a bulk transfer running in the background.
+// Employees with nothing left to transfer under all_remaining are counted
+// as skipped rather than failed.
+type BalanceTransferJob struct {
+	Base
+	OrganizationID  uuid.UUID                   `json:"organization_id" gorm:"type:uuid;not null"`
+	FromLeaveTypeID uuid.UUID                   `json:"from_leave_type_id" gorm:"type:uuid;not null"`
+	ToLeaveTypeID   uuid.UUID                   `json:"to_leave_type_id" gorm:"type:uuid;not null"`
+	Year            int                         `json:"year" gorm:"not null"`
+	Days            float64                     `json:"days" gorm:"type:decimal(5,2);not null"`
+	AllRemaining    bool                        `json:"all_remaining" gorm:"not null"`
+	Reason          string                      `json:"reason" gorm:"not null"`
+	RequestedBy     uuid.UUID                   `json:"requested_by" gorm:"type:uuid;not null"`
+	Status          string                      `json:"status" gorm:"type:varchar(20);not null"`
+	Total           int                         `json:"total" gorm:"not null"`
+	Succeeded       int                         `json:"succeeded" gorm:"not null"`
+	Skipped         int                         `json:"skipped" gorm:"not null"`
+	Failed          int                         `json:"failed" gorm:"not null"`
+	StartedAt       *time.Time                  `json:"started_at,omitempty"`
+	FinishedAt      *time.Time                  `json:"finished_at,omitempty"`
+	Failures        []BalanceTransferJobFailure `json:"failures,omitempty" gorm:"foreignKey:JobID"`
+	// EmployeeIDs are the employees the job runs over; they are not stored
+	EmployeeIDs []uuid.UUID `json:"-" gorm:"-"`
+}
+
+// BalanceTransferJobFailure records why one employee's transfer failed
+type BalanceTransferJobFailure struct {
+	Base
+	JobID      uuid.UUID `json:"job_id" gorm:"type:uuid;not null"`
+	EmployeeID uuid.UUID `json:"employee_id" gorm:"type:uuid;not null"`
+	Error      string    `json:"error" gorm:"not null"`
+}
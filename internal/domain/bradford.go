@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BradfordWindowWeeks is the trailing window the Bradford factor is
+// computed over, ending on the as-of date
+const BradfordWindowWeeks = 52
+
+// MaxBradfordBands caps how many bands an organization may define
+const MaxBradfordBands = 10
+
+// BradfordBand classifies Bradford scores of at least MinScore, up to the
+// next band's
+type BradfordBand struct {
+	Name     string  `json:"name"`
+	MinScore float64 `json:"min_score"`
+}
+
+// BradfordBands are an organization's bands, lowest first; the first
+// starts at 0 so every score falls in one
+type BradfordBands []BradfordBand
+
+// DefaultBradfordBands are the thresholds HR teams commonly act on
+func DefaultBradfordBands() BradfordBands {
+	return BradfordBands{
+		{Name: "none", MinScore: 0},
+		{Name: "low", MinScore: 51},
+		{Name: "medium", MinScore: 125},
+		{Name: "high", MinScore: 400},
+		{Name: "critical", MinScore: 650},
+	}
+}
+
+// Validate checks the bands start at 0, have distinct non-empty names and
+// strictly rising thresholds
+func (b BradfordBands) Validate() error {
+	if len(b) == 0 {
+		return errors.New("at least one band is required")
+	}
+	if len(b) > MaxBradfordBands {
+		return fmt.Errorf("at most %d bands are allowed", MaxBradfordBands)
+	}
+	if b[0].MinScore != 0 {
+		return errors.New("the first band must start at min_score 0")
+	}
+	names := make(map[string]bool, len(b))
+	for i, band := range b {
+		name := strings.TrimSpace(band.Name)
+		if name == "" || len(name) > 50 {
+			return fmt.Errorf("band %d needs a name of at most 50 characters", i+1)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("band name %q is used twice", name)
+		}
+		names[strings.ToLower(name)] = true
+		if i > 0 && band.MinScore <= b[i-1].MinScore {
+			return fmt.Errorf("band %q must start above %q", name, b[i-1].Name)
+		}
+	}
+	return nil
+}
+
+// Classify returns the name of the highest band the score reaches
+func (b BradfordBands) Classify(score float64) string {
+	name := ""
+	for _, band := range b {
+		if score >= band.MinScore {
+			name = band.Name
+		}
+	}
+	return name
+}
+
+func (b BradfordBands) Value() (driver.Value, error) {
+	if b == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(b)
+	return string(data), err
+}
+
+func (b *BradfordBands) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*b = BradfordBands{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	}
+	return fmt.Errorf("cannot scan %T into BradfordBands", src)
+}
+
+// BradfordParams selects a page of a Bradford report: approved leave of
+// one leave type in the 52 weeks ending on AsOf
+type BradfordParams struct {
+	AsOf        time.Time
+	LeaveTypeID uuid.UUID
+	Page        int
+	PageSize    int
+}
+
+// BradfordScore is an employee's Bradford factor: Spells squared times
+// Days. Requests that overlap, abut or are apart only by weekends and
+// holidays are one spell, see TotalAbsenceSpells; Days counts only those
+// within the window.
+type BradfordScore struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	Spells     int       `json:"spells"`
+	Days       float64   `json:"days"`
+	Score      float64   `json:"score"`
+	Band       string    `json:"band"`
+}
+
+// BradfordReport ranks employees by Bradford score, highest first, over
+// [StartDate, EndDate]. Employees without leave in the window are left
+// out; Total counts those listed across all pages.
+type BradfordReport struct {
+	OrganizationID uuid.UUID       `json:"organization_id"`
+	AsOf           time.Time       `json:"as_of"`
+	StartDate      time.Time       `json:"start_date"`
+	EndDate        time.Time       `json:"end_date"`
+	LeaveTypeID    uuid.UUID       `json:"leave_type_id"`
+	LeaveType      string          `json:"leave_type"`
+	Bands          BradfordBands   `json:"bands"`
+	Total          int64           `json:"total"`
+	Scores         []BradfordScore `json:"scores"`
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxBulkAdjustmentRows caps the rows of one bulk adjustment upload
+const MaxBulkAdjustmentRows = 5000
+
+// Outcomes of a bulk adjustment row
+const (
+	BulkAdjustmentRowCreated = "created"
+	BulkAdjustmentRowFailed  = "failed"
+)
+
+// BulkAdjustmentRequest applies the adjustments of an uploaded CSV to the
+// balances of Year, the current year unless set
+type BulkAdjustmentRequest struct {
+	Year        int
+	AutoApprove bool
+	OverrideCap bool
+	Rows        []BulkAdjustmentRow
+	PerformedBy uuid.UUID
+	CallerRole  string
+	// AuthToken is used to look employees up by email
+	AuthToken string
+}
+
+// BulkAdjustmentRow is one CSV row as uploaded. The employee is given by
+// EmployeeID or Email and the leave type by name or id.
+type BulkAdjustmentRow struct {
+	Line       int
+	EmployeeID string
+	Email      string
+	LeaveType  string
+	Adjustment string
+	Reason     string
+}
+
+// ParseBulkAdjustmentCSV reads the rows of a bulk adjustment upload. The
+// header names the columns, in any order: employee_id or email, leave_type,
+// adjustment and reason; either employee column may be left empty on a row
+// that fills the other.
+func ParseBulkAdjustmentCSV(r io.Reader) ([]BulkAdjustmentRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	_, hasID := columns["employee_id"]
+	_, hasEmail := columns["email"]
+	if !hasID && !hasEmail {
+		return nil, errors.New("the header needs an employee_id or email column")
+	}
+	for _, required := range []string{"leave_type", "adjustment", "reason"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the header needs a %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []BulkAdjustmentRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(rows) == MaxBulkAdjustmentRows {
+			return nil, fmt.Errorf("the file has more than %d rows", MaxBulkAdjustmentRows)
+		}
+		rows = append(rows, BulkAdjustmentRow{
+			Line:       line,
+			EmployeeID: field(record, "employee_id"),
+			Email:      field(record, "email"),
+			LeaveType:  field(record, "leave_type"),
+			Adjustment: field(record, "adjustment"),
+			Reason:     field(record, "reason"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("the file has no rows")
+	}
+	return rows, nil
+}
+
+// BulkAdjustmentRowResult is the outcome of one row, by its line in the file
+type BulkAdjustmentRowResult struct {
+	Line         int        `json:"line"`
+	Status       string     `json:"status"`
+	EmployeeID   *uuid.UUID `json:"employee_id,omitempty"`
+	LeaveTypeID  *uuid.UUID `json:"leave_type_id,omitempty"`
+	AdjustmentID *uuid.UUID `json:"adjustment_id,omitempty"`
+	// AdjustmentStatus is pending or approved for created rows
+	AdjustmentStatus string `json:"adjustment_status,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// BulkAdjustmentReport is the outcome of a bulk adjustment upload. Rows are
+// applied one by one, so failed rows leave the others in place.
+type BulkAdjustmentReport struct {
+	Year      int                       `json:"year"`
+	Rows      int                       `json:"rows"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Results   []BulkAdjustmentRowResult `json:"results"`
+}
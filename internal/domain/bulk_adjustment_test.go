@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseBulkAdjustmentCSV(t *testing.T) {
+	// Columns come in any order, behind a byte order mark, and blank lines
+	// keep the line numbers of the rows after them
+	file := "\ufeffReason, Leave_Type,email,employee_id,adjustment\n" +
+		"Long service award,Annual,,6f1c2a9e-0b7d-4c1e-9a53-2d8f4e6b7c10,2\n" +
+		"\n" +
+		"\"Overtime, March\",casual,ana@example.com,, -0.5 \n" +
+		"Short row,Annual\n"
+
+	rows, err := ParseBulkAdjustmentCSV(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []BulkAdjustmentRow{
+		{Line: 2, EmployeeID: "6f1c2a9e-0b7d-4c1e-9a53-2d8f4e6b7c10", LeaveType: "Annual", Adjustment: "2", Reason: "Long service award"},
+		{Line: 4, Email: "ana@example.com", LeaveType: "casual", Adjustment: "-0.5", Reason: "Overtime, March"},
+		{Line: 5, LeaveType: "Annual", Reason: "Short row"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestParseBulkAdjustmentCSVRejects(t *testing.T) {
+	tooMany := "email,leave_type,adjustment,reason\n" + strings.Repeat("a@example.com,Annual,1,Bonus day\n", MaxBulkAdjustmentRows+1)
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{name: "empty", file: "", wantErr: "the file is empty"},
+		{name: "header only", file: "email,leave_type,adjustment,reason\n\n", wantErr: "the file has no rows"},
+		{name: "no employee column", file: "name,leave_type,adjustment,reason\n", wantErr: "employee_id or email column"},
+		{name: "no reason column", file: "email,leave_type,adjustment\n", wantErr: "needs a reason column"},
+		{name: "too many rows", file: tooMany, wantErr: fmt.Sprintf("more than %d rows", MaxBulkAdjustmentRows)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBulkAdjustmentCSV(strings.NewReader(tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
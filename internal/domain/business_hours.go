@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// Business hours are 09:00-17:00 UTC on working days
+const (
+	BusinessDayStartHour = 9
+	BusinessDayEndHour   = 17
+)
+
+// BusinessHoursBetween returns the business hours elapsed from start to end,
+// skipping weekends and the given holidays. It returns 0 when end is not
+// after start.
+func BusinessHoursBetween(start, end time.Time, holidays []Holiday) float64 {
+	start, end = start.UTC(), end.UTC()
+	if !end.After(start) {
+		return 0
+	}
+
+	closed := holidayDates(holidays)
+	var total time.Duration
+	for day := TruncateToDate(start); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !isWorkingDay(day, closed) {
+			continue
+		}
+
+		open := day.Add(BusinessDayStartHour * time.Hour)
+		shut := day.Add(BusinessDayEndHour * time.Hour)
+		if start.After(open) {
+			open = start
+		}
+		if end.Before(shut) {
+			shut = end
+		}
+		if shut.After(open) {
+			total += shut.Sub(open)
+		}
+	}
+	return total.Hours()
+}
+
+// AddBusinessHours returns the time hours business hours after start,
+// skipping weekends and the given holidays. Time outside business hours
+// does not count, so a start in the evening begins counting the next
+// business morning.
+func AddBusinessHours(start time.Time, hours float64, holidays []Holiday) time.Time {
+	start = start.UTC()
+	closed := holidayDates(holidays)
+	remaining := time.Duration(hours * float64(time.Hour))
+
+	for day := TruncateToDate(start); ; day = day.AddDate(0, 0, 1) {
+		if !isWorkingDay(day, closed) {
+			continue
+		}
+
+		open := day.Add(BusinessDayStartHour * time.Hour)
+		shut := day.Add(BusinessDayEndHour * time.Hour)
+		if start.After(open) {
+			open = start
+		}
+		if !shut.After(open) {
+			continue
+		}
+		if remaining <= shut.Sub(open) {
+			return open.Add(remaining)
+		}
+		remaining -= shut.Sub(open)
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBusinessHoursBetween(t *testing.T) {
+	// 2026-03-02 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	mondayHoliday := []Holiday{{Name: "Founders day", Date: at(9, 0, 0), Type: "company"}}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		holidays   []Holiday
+		want       float64
+	}{
+		{name: "within one day", start: at(2, 10, 0), end: at(2, 12, 30), want: 2.5},
+		{name: "outside hours clamp to the working day", start: at(2, 7, 0), end: at(2, 19, 0), want: 8},
+		{name: "overnight", start: at(2, 16, 0), end: at(3, 10, 0), want: 2},
+		{name: "over a weekend", start: at(6, 16, 0), end: at(9, 10, 0), want: 2},
+		{name: "over a weekend and a holiday", start: at(6, 16, 0), end: at(10, 10, 0), holidays: mondayHoliday, want: 2},
+		{name: "entirely on a weekend", start: at(7, 9, 0), end: at(8, 17, 0), want: 0},
+		{name: "after hours to before hours", start: at(2, 18, 0), end: at(3, 8, 0), want: 0},
+		{name: "end before start", start: at(3, 12, 0), end: at(2, 12, 0), want: 0},
+		{name: "equal", start: at(2, 12, 0), end: at(2, 12, 0), want: 0},
+		{name: "other zones are read as UTC", start: at(2, 10, 0).In(time.FixedZone("IST", 5*3600+1800)), end: at(2, 11, 0), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BusinessHoursBetween(tt.start, tt.end, tt.holidays); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v hours, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddBusinessHours(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	mondayHoliday := []Holiday{{Name: "Founders day", Date: at(9, 0, 0), Type: "company"}}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		hours    float64
+		holidays []Holiday
+		want     time.Time
+	}{
+		{name: "within one day", start: at(2, 10, 0), hours: 2.5, want: at(2, 12, 30)},
+		{name: "to the end of the day", start: at(2, 9, 0), hours: 8, want: at(2, 17, 0)},
+		{name: "overnight", start: at(2, 16, 0), hours: 2, want: at(3, 10, 0)},
+		{name: "before hours starts in the morning", start: at(2, 6, 0), hours: 1, want: at(2, 10, 0)},
+		{name: "evening starts the next morning", start: at(2, 20, 0), hours: 1, want: at(3, 10, 0)},
+		{name: "over a weekend", start: at(6, 15, 0), hours: 4, want: at(9, 11, 0)},
+		{name: "over a weekend and a holiday", start: at(6, 15, 0), hours: 4, holidays: mondayHoliday, want: at(10, 11, 0)},
+		{name: "zero", start: at(2, 12, 0), hours: 0, want: at(2, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AddBusinessHours(tt.start, tt.hours, tt.holidays)
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if back := BusinessHoursBetween(tt.start, got, tt.holidays); math.Abs(back-tt.hours) > 1e-9 {
+				t.Errorf("round trip gave %v hours, want %v", back, tt.hours)
+			}
+		})
+	}
+}
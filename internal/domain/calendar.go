@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarEntry is one employee's leave on a calendar day. A claimed
+// optional holiday shows as approved leave named after the holiday, with
+// OptionalHolidayID set instead of the leave request and type.
+type CalendarEntry struct {
+	LeaveRequestID    uuid.UUID  `json:"leave_request_id"`
+	OptionalHolidayID *uuid.UUID `json:"optional_holiday_id,omitempty"`
+	EmployeeID        uuid.UUID  `json:"employee_id"`
+	LeaveTypeID       uuid.UUID  `json:"leave_type_id"`
+	LeaveType         string     `json:"leave_type"`
+	Color             string     `json:"color"`
+	Status            string     `json:"status"`
+	// Half is am or pm when only that half of the day is taken off
+	Half string `json:"half,omitempty"`
+	// Hours is set for hourly leave, with its time window when the request has one
+	Hours     float64 `json:"hours,omitempty"`
+	StartTime string  `json:"start_time,omitempty"`
+	EndTime   string  `json:"end_time,omitempty"`
+}
+
+type CalendarDay struct {
+	Date    time.Time       `json:"date"`
+	Entries []CalendarEntry `json:"entries"`
+}
+
+// LeaveCalendar is the team calendar for a date range, one day per date
+type LeaveCalendar struct {
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	Days     []CalendarDay `json:"days"`
+	Holidays []Holiday     `json:"holidays"`
+}
+
+// BuildLeaveCalendar places each covered leave day within [from, to] on its
+// date, and each claimed optional holiday. days must have their
+// LeaveRequest loaded and claims their Holiday; weekends and holidays carry
+// no leave entries, including sandwiched days charged on them.
+func BuildLeaveCalendar(from, to time.Time, days []LeaveRequestDay, holidays []Holiday, claims []OptionalHolidayClaim) *LeaveCalendar {
+	from, to = TruncateToDate(from), TruncateToDate(to)
+
+	calendar := &LeaveCalendar{From: from, To: to, Holidays: holidays}
+	index := make(map[time.Time]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		index[day] = len(calendar.Days)
+		calendar.Days = append(calendar.Days, CalendarDay{Date: day, Entries: []CalendarEntry{}})
+	}
+
+	for _, d := range days {
+		i, ok := index[TruncateToDate(d.Date)]
+		if !ok || d.LeaveRequest == nil || d.Sandwiched {
+			continue
+		}
+		r := d.LeaveRequest
+		entry := CalendarEntry{
+			LeaveRequestID: r.ID,
+			EmployeeID:     r.EmployeeID,
+			LeaveTypeID:    r.LeaveTypeID,
+			Status:         r.Status,
+			Half:           r.HalfOff(d.Date),
+			Hours:          r.Hours,
+			StartTime:      r.StartTime,
+		}
+		if r.StartTime != "" {
+			entry.EndTime, _ = HourlyEndTime(r.StartTime, r.Hours)
+		}
+		if r.LeaveType != nil {
+			entry.LeaveType = r.LeaveType.Name
+			entry.Color = r.LeaveType.Color
+		}
+		calendar.Days[i].Entries = append(calendar.Days[i].Entries, entry)
+	}
+
+	for _, claim := range claims {
+		if claim.Holiday == nil {
+			continue
+		}
+		i, ok := index[TruncateToDate(claim.Holiday.Date)]
+		if !ok {
+			continue
+		}
+		holidayID := claim.HolidayID
+		calendar.Days[i].Entries = append(calendar.Days[i].Entries, CalendarEntry{
+			OptionalHolidayID: &holidayID,
+			EmployeeID:        claim.EmployeeID,
+			LeaveType:         claim.Holiday.Name,
+			Status:            LeaveStatusApproved,
+		})
+	}
+
+	return calendar
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarDefinition describes an organization's working time for one leave
+// year, for scheduling systems that need to know when people can work.
+// Holidays are those observed at Location, the organization-wide ones only
+// when it is empty. Version changes whenever any other field does.
+type CalendarDefinition struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Year           int       `json:"year"`
+	Location       string    `json:"location,omitempty"`
+	Version        string    `json:"version"`
+	// WorkingWeekdays has bit n set when time.Weekday n is a working day,
+	// so Monday to Friday is 62
+	WorkingWeekdays int                         `json:"working_weekdays"`
+	LeaveYear       CalendarDefinitionPeriod    `json:"leave_year"`
+	Holidays        []CalendarDefinitionHoliday `json:"holidays"`
+}
+
+// CalendarDefinitionPeriod is an inclusive range of dates
+type CalendarDefinitionPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CalendarDefinitionHoliday is a holiday in a calendar definition. Holidays
+// always close the whole day.
+type CalendarDefinitionHoliday struct {
+	Date         time.Time `json:"date"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	DurationDays int       `json:"duration_days"`
+}
+
+// NewCalendarDefinition builds the definition of the given calendar year,
+// which is also the leave year balances are kept for
+func NewCalendarDefinition(orgID uuid.UUID, year int, location string, holidays []Holiday) *CalendarDefinition {
+	def := &CalendarDefinition{
+		OrganizationID: orgID,
+		Year:           year,
+		Location:       location,
+		LeaveYear: CalendarDefinitionPeriod{
+			Start: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		Holidays: make([]CalendarDefinitionHoliday, 0, len(holidays)),
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day != time.Saturday && day != time.Sunday {
+			def.WorkingWeekdays |= 1 << day
+		}
+	}
+	for _, h := range holidays {
+		def.Holidays = append(def.Holidays, CalendarDefinitionHoliday{
+			Date:         TruncateToDate(h.Date),
+			Name:         h.Name,
+			Type:         h.Type,
+			DurationDays: 1,
+		})
+	}
+
+	// Hash the content only, so the version is stable across requests
+	content, _ := json.Marshal(def)
+	sum := sha256.Sum256(content)
+	def.Version = hex.EncodeToString(sum[:8])
+	return def
+}
+
+// ICal renders the definition as an iCalendar VFREEBUSY component marking
+// every non-working day of the leave year as busy-unavailable. Runs of
+// consecutive non-working days are merged into one period.
+func (d *CalendarDefinition) ICal() string {
+	closed := make(map[time.Time]bool, len(d.Holidays))
+	for _, h := range d.Holidays {
+		closed[h.Date] = true
+	}
+	working := func(day time.Time) bool {
+		return d.WorkingWeekdays&(1<<day.Weekday()) != 0 && !closed[day]
+	}
+
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//comin//leave-management-service//EN")
+	line("BEGIN:VFREEBUSY")
+	line("UID:%s-%d-%s", d.OrganizationID, d.Year, d.Version)
+	line("DTSTAMP:%s", time.Now().UTC().Format("20060102T150405Z"))
+	line("DTSTART:%s", d.LeaveYear.Start.Format("20060102T150405Z"))
+	line("DTEND:%s", d.LeaveYear.End.AddDate(0, 0, 1).Format("20060102T150405Z"))
+	line("X-CALENDAR-VERSION:%s", d.Version)
+
+	for day := d.LeaveYear.Start; !day.After(d.LeaveYear.End); day = day.AddDate(0, 0, 1) {
+		if working(day) {
+			continue
+		}
+		length := 1
+		for next := day.AddDate(0, 0, 1); !next.After(d.LeaveYear.End) && !working(next); next = next.AddDate(0, 0, 1) {
+			length++
+		}
+		line("FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:%s/P%dD", day.Format("20060102T150405Z"), length)
+		day = day.AddDate(0, 0, length-1)
+	}
+
+	line("END:VFREEBUSY")
+	line("END:VCALENDAR")
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewCalendarDefinition(t *testing.T) {
+	orgID := uuid.New()
+	holidays := []Holiday{{Name: "New Year", Type: "public", Date: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}}
+
+	def := NewCalendarDefinition(orgID, 2026, "", holidays)
+	if def.WorkingWeekdays != 62 {
+		t.Errorf("working_weekdays = %d, want 62 for Monday to Friday", def.WorkingWeekdays)
+	}
+	if !def.LeaveYear.Start.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		!def.LeaveYear.End.Equal(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("leave_year = %+v, want the 2026 calendar year", def.LeaveYear)
+	}
+	if len(def.Holidays) != 1 || !def.Holidays[0].Date.Equal(def.LeaveYear.Start) || def.Holidays[0].DurationDays != 1 {
+		t.Errorf("holidays = %+v, want New Year as one whole day", def.Holidays)
+	}
+
+	if again := NewCalendarDefinition(orgID, 2026, "", holidays); again.Version != def.Version {
+		t.Errorf("version changed between identical builds: %s then %s", def.Version, again.Version)
+	}
+
+	changed := append(holidays, Holiday{Name: "Founders day", Type: "company", Date: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)})
+	for name, other := range map[string]*CalendarDefinition{
+		"another holiday":  NewCalendarDefinition(orgID, 2026, "", changed),
+		"another year":     NewCalendarDefinition(orgID, 2027, "", holidays),
+		"another location": NewCalendarDefinition(orgID, 2026, "LK", holidays),
+	} {
+		if other.Version == def.Version {
+			t.Errorf("%s: version did not change", name)
+		}
+	}
+}
+
+func TestCalendarDefinitionICal(t *testing.T) {
+	// 2026-01-01 is a Thursday; a holiday on Friday the 2nd joins the weekend
+	holidays := []Holiday{{Name: "Bridge day", Type: "company", Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}}
+	ical := NewCalendarDefinition(uuid.New(), 2026, "", holidays).ICal()
+
+	for _, want := range []string{
+		"BEGIN:VFREEBUSY\r\n",
+		"DTSTART:20260101T000000Z\r\n",
+		"DTEND:20270101T000000Z\r\n",
+		"FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:20260102T000000Z/P3D\r\n",
+		"FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:20260110T000000Z/P2D\r\n",
+		"FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:20261226T000000Z/P2D\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ical, want) {
+			t.Errorf("iCalendar is missing %q", strings.TrimSpace(want))
+		}
+	}
+	if strings.Contains(ical, "20260103T000000Z") {
+		t.Error("the weekend after the bridge day was not merged into its period")
+	}
+	// 52 weekends, one of them merged with the bridge day
+	if got := strings.Count(ical, "FREEBUSY;"); got != 52 {
+		t.Errorf("got %d busy periods, want 52", got)
+	}
+}
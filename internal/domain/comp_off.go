@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comp-off request statuses. Approved comp-off whose expiry passed is
+// expired, with the unused part of its credit taken back off the balance.
+const (
+	CompOffStatusPending  = "pending"
+	CompOffStatusApproved = "approved"
+	CompOffStatusRejected = "rejected"
+	CompOffStatusExpired  = "expired"
+)
+
+// CompOffRequest claims time off in lieu for a weekend or holiday worked.
+// Approving it credits the claim to the employee's balance of the
+// organization's comp-off leave type, through the approved adjustment
+// AdjustmentID, in that type's unit.
+type CompOffRequest struct {
+	Base
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null"`
+	EmployeeID     uuid.UUID `json:"employee_id" gorm:"type:uuid;not null"`
+	DateWorked     time.Time `json:"date_worked" gorm:"type:date;not null"`
+	// Either Days or Hours is claimed
+	Days        float64    `json:"days" gorm:"type:decimal(5,2);not null;default:0"`
+	Hours       float64    `json:"hours" gorm:"type:decimal(5,2);not null;default:0"`
+	Reason      string     `json:"reason" gorm:"not null"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	RequestedBy uuid.UUID  `json:"requested_by" gorm:"type:uuid;not null"`
+	DecidedBy   *uuid.UUID `json:"decided_by,omitempty" gorm:"type:uuid"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	Comments    string     `json:"comments"`
+	// LeaveBalanceID and AdjustmentID are the balance credited on approval
+	// and the adjustment crediting it with Credited
+	LeaveBalanceID *uuid.UUID `json:"leave_balance_id,omitempty" gorm:"type:uuid"`
+	AdjustmentID   *uuid.UUID `json:"adjustment_id,omitempty" gorm:"type:uuid"`
+	Credited       *float64   `json:"credited,omitempty" gorm:"type:decimal(5,2)"`
+	// ExpiresOn is the last day the credit can be used, when the
+	// organization lets comp-off lapse
+	ExpiresOn *time.Time `json:"expires_on,omitempty" gorm:"type:date"`
+	ExpiredAt *time.Time `json:"expired_at,omitempty"`
+}
+
+// CreateCompOffRequest claims comp-off for a non-working day worked
+type CreateCompOffRequest struct {
+	EmployeeID  uuid.UUID `json:"employee_id" binding:"required"`
+	DateWorked  time.Time `json:"date_worked" binding:"required"`
+	Days        float64   `json:"days" binding:"omitempty,gt=0,max=1"`
+	Hours       float64   `json:"hours" binding:"omitempty,gt=0,max=24"`
+	Reason      string    `json:"reason" binding:"required,min=5,max=500"`
+	RequestedBy uuid.UUID `json:"-"`
+	// AuthToken is used to look up the employee's location
+	AuthToken string `json:"-"`
+}
+
+// CompOffActionRequest carries the optional comment on approving or
+// rejecting a comp-off request
+type CompOffActionRequest struct {
+	Comments string `json:"comments" binding:"max=1000"`
+}
+
+// ListCompOffRequestsParams filters an organization's comp-off requests
+type ListCompOffRequestsParams struct {
+	Page       int
+	PageSize   int
+	Status     string
+	EmployeeID *uuid.UUID
+}
+
+// CompOffExpirySummary is the outcome of lapsing expired comp-off
+type CompOffExpirySummary struct {
+	Expired       int     `json:"expired"`
+	Forfeited     int     `json:"forfeited"`
+	ForfeitedDays float64 `json:"forfeited_days"`
+	Failed        int     `json:"failed"`
+}
+
+func (r *CompOffRequest) CanDecide() bool {
+	return r.Status == CompOffStatusPending
+}
+
+// CompOffCredit converts a claim into the unit of the comp-off leave type:
+// hours for hourly types, days otherwise
+func CompOffCredit(leaveType *LeaveType, days, hours, hoursPerDay float64) float64 {
+	if leaveType.IsHourly() {
+		if hours > 0 {
+			return hours
+		}
+		return days * hoursPerDay
+	}
+	if days > 0 {
+		return days
+	}
+	return hours / hoursPerDay
+}
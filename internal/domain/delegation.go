@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delegation statuses. A delegation is proposed when an approver's own
+// leave is approved and escalated when they do not confirm it in time;
+// either way it waits for a delegate to be confirmed, or for the approver
+// to decline having one. Cancelling the leave withdraws it.
+const (
+	DelegationStatusProposed  = "proposed"
+	DelegationStatusEscalated = "escalated"
+	DelegationStatusConfirmed = "confirmed"
+	DelegationStatusDeclined  = "declined"
+	DelegationStatusWithdrawn = "withdrawn"
+)
+
+// DefaultDelegationConfirmDays is how long approvers of organizations that
+// have not set their own have to confirm a proposed delegation
+const DefaultDelegationConfirmDays = 2
+
+// Delegation hands the approvals of DelegatorID to DelegateID while the
+// delegator is on the leave of LeaveRequestID, from StartDate to EndDate.
+// DelegateID is the suggested delegate while the delegation is proposed,
+// nil when there was none to suggest.
+type Delegation struct {
+	Base
+	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null"`
+	LeaveRequestID uuid.UUID  `json:"leave_request_id" gorm:"type:uuid;not null"`
+	DelegatorID    uuid.UUID  `json:"delegator_id" gorm:"type:uuid;not null"`
+	DelegateID     *uuid.UUID `json:"delegate_id,omitempty" gorm:"type:uuid"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:'proposed'"`
+	StartDate      time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate        time.Time  `json:"end_date" gorm:"type:date;not null"`
+	// ConfirmBy is when a proposal not confirmed yet is escalated; nil
+	// when the organization does not escalate
+	ConfirmBy   *time.Time `json:"confirm_by,omitempty"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"`
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+	DecidedBy   *uuid.UUID `json:"decided_by,omitempty" gorm:"type:uuid"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+}
+
+// ConfirmDelegationRequest confirms a proposed delegation, to the suggested
+// delegate unless another is given
+type ConfirmDelegationRequest struct {
+	DelegateID *uuid.UUID `json:"delegate_id"`
+	AuthToken  string     `json:"-"`
+}
+
+// ChangeDelegateRequest hands a delegation to another delegate
+type ChangeDelegateRequest struct {
+	DelegateID uuid.UUID `json:"delegate_id" binding:"required"`
+	AuthToken  string    `json:"-"`
+}
+
+// ListDelegationsParams filters an organization's delegations
+type ListDelegationsParams struct {
+	Page        int
+	PageSize    int
+	Status      string
+	DelegatorID *uuid.UUID
+	DelegateID  *uuid.UUID
+}
+
+// DelegationEscalation is the outcome of escalating the proposals that
+// were not confirmed in time
+type DelegationEscalation struct {
+	Escalated int `json:"escalated"`
+	Notified  int `json:"notified"`
+	// NotifyFailed counts escalations whose notification failed
+	NotifyFailed int `json:"notify_failed"`
+}
+
+// DelegationNotification is what an organization's delegation webhook is
+// sent
+type DelegationNotification struct {
+	Event      string      `json:"event"`
+	Delegation *Delegation `json:"delegation"`
+}
+
+// Events of the delegation webhook. Proposals are for the delegator to
+// confirm; escalations are for their manager, whom the receiver resolves.
+const (
+	DelegationProposedEvent  = "delegation.proposed"
+	DelegationEscalatedEvent = "delegation.escalated"
+)
+
+// IsOpen reports whether the delegation still waits for a delegate to be
+// confirmed
+func (d *Delegation) IsOpen() bool {
+	return d.Status == DelegationStatusProposed || d.Status == DelegationStatusEscalated
+}
+
+// IsActive reports whether the delegation is open or confirmed, so it
+// follows its leave's dates and is withdrawn with it
+func (d *Delegation) IsActive() bool {
+	return d.IsOpen() || d.Status == DelegationStatusConfirmed
+}
+
+// IsWithdrawn reports whether the delegation's leave was cancelled
+func (d *Delegation) IsWithdrawn() bool {
+	return d.Status == DelegationStatusWithdrawn
+}
+
+// IsOverdue reports whether a proposal was not confirmed by its deadline
+func (d *Delegation) IsOverdue(now time.Time) bool {
+	return d.Status == DelegationStatusProposed && d.ConfirmBy != nil && now.After(*d.ConfirmBy)
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Employee attributes from the organization service that eligibility rules
+// can test
+const (
+	EligibilityAttributeGender         = "gender"
+	EligibilityAttributeEmploymentType = "employment_type"
+	EligibilityAttributeLocation       = "location"
+	EligibilityAttributeGrade          = "grade"
+)
+
+// EligibilityCondition is met when the employee's attribute equals one of
+// Values, ignoring case
+type EligibilityCondition struct {
+	Attribute string   `json:"attribute" binding:"required,oneof=gender employment_type location grade"`
+	Values    []string `json:"values" binding:"required,min=1,dive,required"`
+}
+
+// EligibilityRule restricts a leave type to employees meeting every one of
+// its conditions. It is stored as JSONB; a leave type without a rule is
+// open to everyone.
+type EligibilityRule struct {
+	Conditions []EligibilityCondition `json:"conditions" binding:"required,min=1,dive"`
+}
+
+func (r EligibilityRule) Value() (driver.Value, error) {
+	data, err := json.Marshal(r)
+	return string(data), err
+}
+
+func (r *EligibilityRule) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	}
+	return fmt.Errorf("cannot scan %T into EligibilityRule", src)
+}
+
+// Validate checks that every condition names a known attribute and at
+// least one value
+func (r *EligibilityRule) Validate() error {
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("eligibility rule needs at least one condition")
+	}
+	for _, cond := range r.Conditions {
+		switch cond.Attribute {
+		case EligibilityAttributeGender, EligibilityAttributeEmploymentType,
+			EligibilityAttributeLocation, EligibilityAttributeGrade:
+		default:
+			return fmt.Errorf("unknown eligibility attribute %q", cond.Attribute)
+		}
+		if len(cond.Values) == 0 {
+			return fmt.Errorf("eligibility condition on %s needs at least one value", cond.Attribute)
+		}
+	}
+	return nil
+}
+
+// FailedCondition returns the first condition the employee with the given
+// attributes does not meet, or nil when they are eligible. A missing
+// attribute meets no condition on it.
+func (r *EligibilityRule) FailedCondition(attributes map[string]string) *EligibilityCondition {
+	if r == nil {
+		return nil
+	}
+	for i, cond := range r.Conditions {
+		if !cond.Matches(attributes[cond.Attribute]) {
+			return &r.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Matches reports whether value is one of the condition's values
+func (c EligibilityCondition) Matches(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+	for _, v := range c.Values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c EligibilityCondition) String() string {
+	return fmt.Sprintf("%s in (%s)", c.Attribute, strings.Join(c.Values, ", "))
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxExternalReferences caps the references one leave request can carry
+const MaxExternalReferences = 5
+
+var ErrTooManyExternalReferences = fmt.Errorf("a leave request can have at most %d external references", MaxExternalReferences)
+
+// ExternalReference links a leave request to a case in another system, such
+// as an HR case number
+type ExternalReference struct {
+	System    string `json:"system" binding:"required,max=50"`
+	Reference string `json:"reference" binding:"required,max=100"`
+}
+
+// ExternalReferences is stored as a JSONB array
+type ExternalReferences []ExternalReference
+
+func (r ExternalReferences) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(r)
+	return string(data), err
+}
+
+func (r *ExternalReferences) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*r = ExternalReferences{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	}
+	return fmt.Errorf("cannot scan %T into ExternalReferences", src)
+}
+
+type UpdateExternalReferencesRequest struct {
+	ExternalReferences []ExternalReference `json:"external_references" binding:"max=5,dive"`
+}
+
+// NormalizeExternalReferences trims the references, drops repeats of the
+// same system and reference and checks the count
+func NormalizeExternalReferences(refs []ExternalReference) (ExternalReferences, error) {
+	normalized := make(ExternalReferences, 0, len(refs))
+	seen := make(map[ExternalReference]bool, len(refs))
+	for _, ref := range refs {
+		ref.System = strings.TrimSpace(ref.System)
+		ref.Reference = strings.TrimSpace(ref.Reference)
+		if ref.System == "" || ref.Reference == "" {
+			return nil, errors.New("external references need both a system and a reference")
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		normalized = append(normalized, ref)
+	}
+	if len(normalized) > MaxExternalReferences {
+		return nil, ErrTooManyExternalReferences
+	}
+	return normalized, nil
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Half-day markers. A request's StartHalf is HalfDayPM when its leave begins
+// at midday on the start date; its EndHalf is HalfDayAM when the leave ends
+// at midday on the end date. A single-day request sets one of them: EndHalf
+// am takes the morning off, StartHalf pm the afternoon.
+const (
+	HalfDayAM = "am"
+	HalfDayPM = "pm"
+)
+
+var (
+	ErrInvalidStartHalf = errors.New("start_half can only be pm, to start the leave at midday")
+	ErrInvalidEndHalf   = errors.New("end_half can only be am, to end the leave at midday")
+	ErrEmptyHalfDay     = errors.New("a single-day request cannot both start and end at midday")
+)
+
+// ValidateHalfDays checks the half-day markers of a request over [start, end]
+func ValidateHalfDays(start, end time.Time, startHalf, endHalf string) error {
+	if startHalf != "" && startHalf != HalfDayPM {
+		return ErrInvalidStartHalf
+	}
+	if endHalf != "" && endHalf != HalfDayAM {
+		return ErrInvalidEndHalf
+	}
+	if startHalf != "" && endHalf != "" && TruncateToDate(start).Equal(TruncateToDate(end)) {
+		return ErrEmptyHalfDay
+	}
+	return nil
+}
+
+// CalculateLeaveDays is CalculateWorkingDays less half a day for each end of
+// the request that falls on a working day and is marked as a half day
+func CalculateLeaveDays(start, end time.Time, startHalf, endHalf string, holidays []Holiday) float64 {
+	var days float64
+	for _, day := range LeaveDayBreakdown(start, end, startHalf, endHalf, holidays) {
+		days += day.Fraction
+	}
+	return days
+}
+
+// halvesOff reports which halves of day a leave over [start, end] with the
+// given half-day markers takes off
+func halvesOff(day, start, end time.Time, startHalf, endHalf string) (am, pm bool) {
+	day, start, end = TruncateToDate(day), TruncateToDate(start), TruncateToDate(end)
+	if day.Before(start) || day.After(end) {
+		return false, false
+	}
+	am = !(day.Equal(start) && startHalf == HalfDayPM)
+	pm = !(day.Equal(end) && endHalf == HalfDayAM)
+	return am, pm
+}
+
+func (l *LeaveRequest) halvesOff(day time.Time) (am, pm bool) {
+	return halvesOff(day, l.StartDate, l.EndDate, l.StartHalf, l.EndHalf)
+}
+
+// HalfOff returns HalfDayAM or HalfDayPM when the request takes only that
+// half of day off, and "" for a full day or a day outside the request
+func (l *LeaveRequest) HalfOff(day time.Time) string {
+	am, pm := l.halvesOff(day)
+	switch {
+	case am && !pm:
+		return HalfDayAM
+	case pm && !am:
+		return HalfDayPM
+	}
+	return ""
+}
+
+// Overlaps reports whether the two requests take off the same half of any
+// day. A morning off in one and an afternoon off in the other do not
+// overlap.
+func (l *LeaveRequest) Overlaps(other *LeaveRequest) bool {
+	from := TruncateToDate(l.StartDate)
+	if s := TruncateToDate(other.StartDate); s.After(from) {
+		from = s
+	}
+	to := TruncateToDate(l.EndDate)
+	if e := TruncateToDate(other.EndDate); e.Before(to) {
+		to = e
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		am, pm := l.halvesOff(day)
+		otherAM, otherPM := other.halvesOff(day)
+		if (am && otherAM) || (pm && otherPM) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HolidayCalendarEntry is a holiday in the calendar view. OnWeekend marks
+// holidays that give no day off since they fall on a weekend anyway.
+type HolidayCalendarEntry struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Date      time.Time `json:"date"`
+	Weekday   string    `json:"weekday"`
+	Type      string    `json:"type"`
+	Location  *string   `json:"location,omitempty"`
+	OnWeekend bool      `json:"on_weekend"`
+}
+
+// HolidayCalendarMonth groups the holidays of one month
+type HolidayCalendarMonth struct {
+	Month    int                    `json:"month"`
+	Name     string                 `json:"name"`
+	Count    int                    `json:"count"`
+	Holidays []HolidayCalendarEntry `json:"holidays"`
+}
+
+// HolidayCalendar is a year of holidays for a month grid. Months always
+// holds the twelve months in order, empty ones included, and Holidays the
+// same holidays as a flat list by date.
+type HolidayCalendar struct {
+	Year      int                    `json:"year"`
+	Location  string                 `json:"location,omitempty"`
+	Total     int                    `json:"total"`
+	OnWeekend int                    `json:"on_weekend"`
+	Months    []HolidayCalendarMonth `json:"months"`
+	Holidays  []HolidayCalendarEntry `json:"holidays"`
+}
+
+// BuildHolidayCalendar lays out the holidays of year, sorted by date, by
+// month
+func BuildHolidayCalendar(year int, location string, holidays []Holiday) *HolidayCalendar {
+	calendar := &HolidayCalendar{
+		Year:     year,
+		Location: location,
+		Months:   make([]HolidayCalendarMonth, 12),
+		Holidays: make([]HolidayCalendarEntry, 0, len(holidays)),
+	}
+	for i := range calendar.Months {
+		calendar.Months[i] = HolidayCalendarMonth{
+			Month:    i + 1,
+			Name:     time.Month(i + 1).String(),
+			Holidays: []HolidayCalendarEntry{},
+		}
+	}
+
+	for _, h := range holidays {
+		date := TruncateToDate(h.Date)
+		if date.Year() != year {
+			continue
+		}
+		entry := HolidayCalendarEntry{
+			ID:        h.ID,
+			Name:      h.Name,
+			Date:      date,
+			Weekday:   date.Weekday().String(),
+			Type:      h.Type,
+			Location:  h.Location,
+			OnWeekend: date.Weekday() == time.Saturday || date.Weekday() == time.Sunday,
+		}
+		month := &calendar.Months[date.Month()-1]
+		month.Holidays = append(month.Holidays, entry)
+		month.Count++
+		calendar.Holidays = append(calendar.Holidays, entry)
+		calendar.Total++
+		if entry.OnWeekend {
+			calendar.OnWeekend++
+		}
+	}
+	return calendar
+}
+
+// HolidayCalendarToken is an organization's calendar token and the path
+// calendar apps subscribe to with it
+type HolidayCalendarToken struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
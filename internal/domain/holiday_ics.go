@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// HolidayICSProductID identifies the service in exported calendars
+const HolidayICSProductID = "-//Comin//Leave Management//EN"
+
+// HolidayICS renders holidays as an iCalendar (RFC 5545) document of
+// all-day events. Each event's UID is its holiday's ID, so calendar apps
+// re-importing the calendar update the events rather than add them again.
+func HolidayICS(name string, holidays []Holiday) []byte {
+	var b strings.Builder
+	line := func(content string) {
+		b.WriteString(foldICSLine(content))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + HolidayICSProductID)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICSText(name))
+	for _, h := range holidays {
+		date := TruncateToDate(h.Date)
+		stamp := h.UpdatedAt
+		if stamp.IsZero() {
+			stamp = h.CreatedAt
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + h.ID.String() + "@comin-leave-management")
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeICSText(fmt.Sprintf("%s (%s holiday)", h.Name, h.Type)))
+		line("CATEGORIES:" + escapeICSText(h.Type))
+		if h.Location != nil {
+			line("DESCRIPTION:" + escapeICSText("Observed in "+*h.Location))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// escapeICSText escapes a TEXT value
+func escapeICSText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// foldICSLine splits a content line into lines of at most 75 octets, each
+// continuation starting with a space, without splitting a character
+func foldICSLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+
+	var b strings.Builder
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts toward the next line's octets
+		width = limit - 1
+	}
+	b.WriteString(s)
+	return b.String()
+}
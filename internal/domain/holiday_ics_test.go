@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHolidayICS(t *testing.T) {
+	location := "LK"
+	holidays := []Holiday{
+		{
+			// The stamp is the last update, in UTC
+			Base:     Base{ID: uuid.MustParse("6f1c2a9e-0b7d-4c1e-9a53-2d8f4e6b7c10"), UpdatedAt: time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("IST", 19800))},
+			Name:     `Poya; Vesak, "Full Moon" \ lanterns`,
+			Type:     HolidayTypePublic,
+			Date:     time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+			Location: &location,
+		},
+		{
+			// Never updated, so stamped with its creation; the event ends
+			// on the next year's first day
+			Base: Base{ID: uuid.MustParse("0a9b8c7d-6e5f-4a3b-8c2d-1e0f9a8b7c6d"), CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+			Name: "Fiesta de la Independencia y de la Constitución de la República — " +
+				"celebración nacional en todas las provincias y municipios del país",
+			Type: HolidayTypeOptional,
+			Date: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Comin//Leave Management//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"METHOD:PUBLISH\r\n" +
+		"X-WR-CALNAME:Acme\\, Inc\\; Holidays\\nLK\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:6f1c2a9e-0b7d-4c1e-9a53-2d8f4e6b7c10@comin-leave-management\r\n" +
+		"DTSTAMP:20260303T233607Z\r\n" +
+		"DTSTART;VALUE=DATE:20260501\r\n" +
+		"DTEND;VALUE=DATE:20260502\r\n" +
+		"SUMMARY:Poya\\; Vesak\\, \"Full Moon\" \\\\ lanterns (public holiday)\r\n" +
+		"CATEGORIES:public\r\n" +
+		"DESCRIPTION:Observed in LK\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:0a9b8c7d-6e5f-4a3b-8c2d-1e0f9a8b7c6d@comin-leave-management\r\n" +
+		"DTSTAMP:20260102T030405Z\r\n" +
+		"DTSTART;VALUE=DATE:20261231\r\n" +
+		"DTEND;VALUE=DATE:20270101\r\n" +
+		// The 75th octet falls inside the dash, which moves to the next line
+		"SUMMARY:Fiesta de la Independencia y de la Constitución de la República \r\n" +
+		" — celebración nacional en todas las provincias y municipios del país (\r\n" +
+		" optional holiday)\r\n" +
+		"CATEGORIES:optional\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	got := string(HolidayICS("Acme, Inc; Holidays\nLK", holidays))
+	if got != want {
+		t.Errorf("calendar differs from the golden copy\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if strings.ContainsAny(line, "\r\n") {
+			t.Errorf("bare line break in %q", line)
+		}
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "short", in: strings.Repeat("a", 75), want: strings.Repeat("a", 75)},
+		{name: "one over", in: strings.Repeat("a", 76), want: strings.Repeat("a", 75) + "\r\n a"},
+		// Continuation lines hold 74 octets after their space
+		{name: "two folds", in: strings.Repeat("a", 75+74+1), want: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a"},
+		{name: "two-octet character at the limit", in: strings.Repeat("a", 74) + "é", want: strings.Repeat("a", 74) + "\r\n é"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foldICSLine(tt.in); got != tt.want {
+				t.Errorf("foldICSLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxHolidayImportRows caps the holidays of one import
+const MaxHolidayImportRows = 500
+
+// Outcomes of a holiday import row. Dry runs report the holidays that
+// would be created as valid.
+const (
+	HolidayImportCreated = "created"
+	HolidayImportValid   = "valid"
+	HolidayImportFailed  = "failed"
+)
+
+// HolidayImportRow is one holiday as uploaded, by its line in a CSV file or
+// its position in a JSON array
+type HolidayImportRow struct {
+	Line     int    `json:"-"`
+	Name     string `json:"name"`
+	Date     string `json:"date"`
+	Type     string `json:"type"`
+	Location string `json:"location"`
+}
+
+// ParseDate reads the row's date as YYYY-MM-DD or RFC 3339
+func (r *HolidayImportRow) ParseDate() (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", r.Date); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, r.Date)
+	if err != nil {
+		return time.Time{}, errors.New("date must be in YYYY-MM-DD format")
+	}
+	return TruncateToDate(date), nil
+}
+
+// ParseHolidayCSV reads the rows of a holiday import. The header names the
+// name, date and type columns, in any order, and optionally location.
+func ParseHolidayCSV(r io.Reader) ([]HolidayImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "date", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the header needs a %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []HolidayImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows) == MaxHolidayImportRows {
+			return nil, fmt.Errorf("the file has more than %d holidays", MaxHolidayImportRows)
+		}
+		rows = append(rows, HolidayImportRow{
+			Line:     line,
+			Name:     field(record, "name"),
+			Date:     field(record, "date"),
+			Type:     strings.ToLower(field(record, "type")),
+			Location: field(record, "location"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("the file has no holidays")
+	}
+	return rows, nil
+}
+
+// ParseHolidayJSON reads the rows of a holiday import sent as a JSON array
+// of holidays shaped like single creates, numbering them from 1
+func ParseHolidayJSON(r io.Reader) ([]HolidayImportRow, error) {
+	var rows []HolidayImportRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, errors.New("the body must be a JSON array of holidays")
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no holidays to import")
+	}
+	if len(rows) > MaxHolidayImportRows {
+		return nil, fmt.Errorf("more than %d holidays", MaxHolidayImportRows)
+	}
+	for i := range rows {
+		rows[i].Line = i + 1
+	}
+	return rows, nil
+}
+
+// HolidayImportResult is the outcome of one row
+type HolidayImportResult struct {
+	Line      int        `json:"line"`
+	Name      string     `json:"name"`
+	Date      *time.Time `json:"date,omitempty"`
+	Location  string     `json:"location,omitempty"`
+	Status    string     `json:"status"`
+	HolidayID *uuid.UUID `json:"holiday_id,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// HolidayImportReport is the outcome of a holiday import. Rows are created
+// one by one, so failed rows leave the others in place; a dry run creates
+// nothing.
+type HolidayImportReport struct {
+	DryRun  bool                  `json:"dry_run"`
+	Rows    int                   `json:"rows"`
+	Created int                   `json:"created"`
+	Valid   int                   `json:"valid"`
+	Failed  int                   `json:"failed"`
+	Results []HolidayImportResult `json:"results"`
+}
+
+// SkippedHoliday is a provider holiday an import left out, with why
+type SkippedHoliday struct {
+	Name   string    `json:"name"`
+	Date   time.Time `json:"date"`
+	Reason string    `json:"reason"`
+}
+
+// PublicHolidayImport is the outcome of importing a country's public
+// holidays from the holiday provider, for one location when set. In a
+// preview Created lists the holidays that would be created, without ids.
+type PublicHolidayImport struct {
+	Country  string           `json:"country"`
+	Year     int              `json:"year"`
+	Location string           `json:"location,omitempty"`
+	Preview  bool             `json:"preview"`
+	Created  []Holiday        `json:"created"`
+	Skipped  []SkippedHoliday `json:"skipped"`
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHolidayCSV(t *testing.T) {
+	// Columns in any order, a BOM before the header and a short row
+	csv := "\ufeffDate, Type ,NAME,location\n" +
+		"2026-01-14, Public, Thai Pongal, LK-N\n" +
+		"2026-05-01,company,May Day\n"
+
+	rows, err := ParseHolidayCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []HolidayImportRow{
+		{Line: 2, Name: "Thai Pongal", Date: "2026-01-14", Type: "public", Location: "LK-N"},
+		{Line: 3, Name: "May Day", Date: "2026-05-01", Type: "company"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestParseHolidayCSVRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr string
+	}{
+		{name: "empty", csv: "", wantErr: "the file is empty"},
+		{name: "no type column", csv: "name,date\nMay Day,2026-05-01\n", wantErr: "the header needs a type column"},
+		{name: "header only", csv: "name,date,type\n", wantErr: "the file has no holidays"},
+		{name: "too many rows", csv: "name,date,type\n" + strings.Repeat("Poya,2026-05-01,public\n", MaxHolidayImportRows+1),
+			wantErr: "the file has more than 500 holidays"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseHolidayCSV(strings.NewReader(tt.csv)); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHolidayJSON(t *testing.T) {
+	rows, err := ParseHolidayJSON(strings.NewReader(`[{"name":"Poya","date":"2026-05-01","type":"public"},{"name":"Founders' Day","date":"2026-06-01T00:00:00Z","type":"company"}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Line != 1 || rows[1].Line != 2 || rows[1].Name != "Founders' Day" {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	for body, wantErr := range map[string]string{
+		`{"name":"Poya"}`: "the body must be a JSON array of holidays",
+		`[]`:              "no holidays to import",
+	} {
+		if _, err := ParseHolidayJSON(strings.NewReader(body)); err == nil || err.Error() != wantErr {
+			t.Errorf("%s: error = %v, want %q", body, err, wantErr)
+		}
+	}
+}
+
+func TestHolidayImportRowParseDate(t *testing.T) {
+	want := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{"2026-05-01", "2026-05-01T18:30:00Z"} {
+		row := HolidayImportRow{Date: value}
+		if got, err := row.ParseDate(); err != nil || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "01/05/2026", "2026-02-30"} {
+		row := HolidayImportRow{Date: value}
+		if _, err := row.ParseDate(); err == nil {
+			t.Errorf("ParseDate(%q) accepted", value)
+		}
+	}
+}
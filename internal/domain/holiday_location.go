@@ -0,0 +1,44 @@
+package domain
+
+import "strings"
+
+// HolidayLocation returns the location to store for a holiday, nil for an
+// organization-wide one
+func HolidayLocation(location string) *string {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return nil
+	}
+	return &location
+}
+
+// LocationName returns the holiday's location, "" when organization-wide
+func (h *Holiday) LocationName() string {
+	if h.Location == nil {
+		return ""
+	}
+	return *h.Location
+}
+
+// AppliesTo reports whether employees at location observe the holiday.
+// Organization-wide holidays apply everywhere; employees without a
+// location observe only those.
+func (h *Holiday) AppliesTo(location string) bool {
+	return h.Location == nil || strings.EqualFold(*h.Location, strings.TrimSpace(location))
+}
+
+// Overlaps reports whether some employees observe both holidays
+func (h *Holiday) Overlaps(other *Holiday) bool {
+	return h.Location == nil || other.Location == nil || strings.EqualFold(*h.Location, *other.Location)
+}
+
+// HolidaysAt keeps the holidays employees at location observe
+func HolidaysAt(holidays []Holiday, location string) []Holiday {
+	observed := make([]Holiday, 0, len(holidays))
+	for _, h := range holidays {
+		if h.AppliesTo(location) {
+			observed = append(observed, h)
+		}
+	}
+	return observed
+}
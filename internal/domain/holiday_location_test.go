@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHolidayLocations(t *testing.T) {
+	orgWide := Holiday{Name: "Vesak"}
+	north := Holiday{Name: "Thai Pongal", Location: HolidayLocation(" LK-N ")}
+	south := Holiday{Name: "Kataragama", Location: HolidayLocation("LK-S")}
+
+	if orgWide.Location != nil || HolidayLocation("  ") != nil {
+		t.Error("a blank location is stored")
+	}
+	if north.LocationName() != "LK-N" {
+		t.Errorf("location = %q, want it trimmed", north.LocationName())
+	}
+
+	tests := []struct {
+		holiday  Holiday
+		location string
+		want     bool
+	}{
+		{holiday: orgWide, location: "", want: true},
+		{holiday: orgWide, location: "LK-N", want: true},
+		{holiday: north, location: " lk-n", want: true},
+		{holiday: north, location: "LK-S"},
+		// Employees without a location observe organization-wide holidays only
+		{holiday: north, location: ""},
+	}
+	for _, tt := range tests {
+		if got := tt.holiday.AppliesTo(tt.location); got != tt.want {
+			t.Errorf("%s applies to %q = %t, want %t", tt.holiday.Name, tt.location, got, tt.want)
+		}
+	}
+
+	for _, pair := range []struct {
+		a, b Holiday
+		want bool
+	}{
+		{a: orgWide, b: north, want: true},
+		{a: north, b: orgWide, want: true},
+		{a: north, b: Holiday{Location: HolidayLocation("lk-n")}, want: true},
+		{a: north, b: south},
+	} {
+		if got := pair.a.Overlaps(&pair.b); got != pair.want {
+			t.Errorf("%q overlaps %q = %t, want %t", pair.a.LocationName(), pair.b.LocationName(), got, pair.want)
+		}
+	}
+
+	if got := HolidaysAt([]Holiday{orgWide, north, south}, "LK-S"); len(got) != 2 || got[0].Name != "Vesak" || got[1].Name != "Kataragama" {
+		t.Errorf("holidays at LK-S = %+v", got)
+	}
+}
+
+func TestBuildHolidayImpact(t *testing.T) {
+	// 2026-06-01 is a Monday; the fortnight has 10 weekdays
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	last := from.AddDate(0, 0, 13)
+	holidays := []Holiday{
+		{Name: "Poson", Date: from.AddDate(0, 0, 2)},
+		{Name: "Office day", Date: from.AddDate(0, 0, 4), Location: HolidayLocation("Kandy")},
+		{Name: "Town fair", Date: from.AddDate(0, 0, 8), Location: HolidayLocation("colombo")},
+		// A weekend holiday costs no working day
+		{Name: "Fair weekend", Date: from.AddDate(0, 0, 5), Location: HolidayLocation("Colombo")},
+	}
+
+	want := []HolidayImpact{
+		{Location: "", Holidays: 1, WorkingDaysLost: 1, WorkingDays: 9},
+		{Location: "colombo", Holidays: 3, WorkingDaysLost: 2, WorkingDays: 8},
+		{Location: "Kandy", Holidays: 2, WorkingDaysLost: 2, WorkingDays: 8},
+	}
+	got := BuildHolidayImpact(from, last, holidays)
+	if len(got) != len(want) {
+		t.Fatalf("impact = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("impact %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveActionRecalculate records a request whose days were counted again
+// after a holiday it covers was added, moved or removed
+const LeaveActionRecalculate = "recalculate"
+
+// HolidayRecalculationComment is the history comment of a recalculation
+const HolidayRecalculationComment = "days recalculated due to holiday change"
+
+// HolidayChange says who adds, changes or removes a holiday, so the leave
+// requests it affects are recalculated on their behalf. With PreviewImpact
+// the holiday is left as it is and the requests are only reported.
+type HolidayChange struct {
+	PerformedBy   uuid.UUID
+	AuthToken     string
+	PreviewImpact bool
+}
+
+// RecalculatedLeaveRequest is a leave request whose days a holiday change
+// counts differently. Delta is what its balance is charged on top, negative
+// when days are given back.
+type RecalculatedLeaveRequest struct {
+	LeaveRequestID uuid.UUID `json:"leave_request_id"`
+	EmployeeID     uuid.UUID `json:"employee_id"`
+	LeaveTypeID    uuid.UUID `json:"leave_type_id"`
+	Status         string    `json:"status"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	OldDays        float64   `json:"old_days"`
+	NewDays        float64   `json:"new_days"`
+	Delta          float64   `json:"delta"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// HolidayRecalculation lists the pending and approved leave requests, not
+// yet ended, whose days a holiday change counts differently. In a preview
+// nothing was changed; otherwise Failed requests kept their old days.
+type HolidayRecalculation struct {
+	Preview       bool                       `json:"preview"`
+	Requests      int                        `json:"requests"`
+	Failed        int                        `json:"failed"`
+	LeaveRequests []RecalculatedLeaveRequest `json:"leave_requests"`
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Units a leave type's balances and requests are counted in
+const (
+	LeaveUnitDays  = "days"
+	LeaveUnitHours = "hours"
+)
+
+// DefaultHoursPerDay converts hourly leave to day equivalents for
+// organizations that have not set their own working day length
+const DefaultHoursPerDay = 8
+
+var (
+	ErrHoursRequired      = errors.New("hours is required for leave types tracked in hours")
+	ErrHoursNotAllowed    = errors.New("hours can only be set on leave types tracked in hours")
+	ErrHourlySingleDate   = errors.New("hourly leave must start and end on the same date")
+	ErrHourlyHalfDay      = errors.New("start_half and end_half do not apply to leave tracked in hours")
+	ErrInvalidStartTime   = errors.New("start_time must be a time of day in HH:MM format")
+	ErrHourlyPastMidnight = errors.New("hourly leave cannot run past midnight")
+)
+
+// IsValidLeaveUnit reports whether unit is a supported leave unit. An empty
+// unit is treated as LeaveUnitDays.
+func IsValidLeaveUnit(unit string) bool {
+	return unit == "" || unit == LeaveUnitDays || unit == LeaveUnitHours
+}
+
+// IsHourly reports whether the leave type is tracked in hours
+func (t *LeaveType) IsHourly() bool {
+	return t.Unit == LeaveUnitHours
+}
+
+// Allowance is the yearly allowance of the leave type in its own unit.
+// DefaultDays are converted at hoursPerDay for hourly types.
+func (t *LeaveType) Allowance(hoursPerDay float64) float64 {
+	if t.IsHourly() {
+		return float64(t.DefaultDays) * hoursPerDay
+	}
+	return float64(t.DefaultDays)
+}
+
+// Entitlement is what a balance of the leave type opens with: the yearly
+// allowance, or nothing for types whose allowance accrues
+func (t *LeaveType) Entitlement(hoursPerDay float64) float64 {
+	if t.Accrues() {
+		return 0
+	}
+	return t.Allowance(hoursPerDay)
+}
+
+// ValidateHourlyLeave checks a request for hourly leave: a positive number
+// of hours on a single date, no half-day markers and, when given, a start
+// time that leaves room for the hours before midnight
+func ValidateHourlyLeave(start, end time.Time, startHalf, endHalf string, hours float64, startTime string) error {
+	if hours <= 0 {
+		return ErrHoursRequired
+	}
+	if !end.IsZero() && !TruncateToDate(start).Equal(TruncateToDate(end)) {
+		return ErrHourlySingleDate
+	}
+	if startHalf != "" || endHalf != "" {
+		return ErrHourlyHalfDay
+	}
+	if startTime == "" {
+		return nil
+	}
+	_, err := HourlyEndTime(startTime, hours)
+	return err
+}
+
+// HourlyEndTime returns the HH:MM time hours after startTime
+func HourlyEndTime(startTime string, hours float64) (string, error) {
+	t, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return "", ErrInvalidStartTime
+	}
+	minutes := t.Hour()*60 + t.Minute() + int(hours*60+0.5)
+	if minutes > 24*60 {
+		return "", ErrHourlyPastMidnight
+	}
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), nil
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Background job statuses
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Background job types
+const (
+	JobTypeYearlyReset          = "yearly_reset"
+	JobTypeBalanceRecalculation = "balance_recalculation"
+)
+
+const (
+	// JobLeaseDuration is how long a worker holds a claimed job without
+	// reporting progress before another worker may take it over
+	JobLeaseDuration = 5 * time.Minute
+	// JobMaxAttempts caps how often a job is claimed; one whose lease
+	// expires on its last attempt is failed
+	JobMaxAttempts = 3
+)
+
+// IsSingletonJobType reports whether an organization may only have one job
+// of the type queued or running at a time
+func IsSingletonJobType(jobType string) bool {
+	switch jobType {
+	case JobTypeYearlyReset, JobTypeBalanceRecalculation:
+		return true
+	}
+	return false
+}
+
+// Job is an admin operation queued to run in the background. Payload holds
+// its type's input and Result its outcome once completed; Processed out of
+// Total reports progress while it runs. A job whose worker stops is claimed
+// again once its lease expires, so job types must be safe to rerun.
+type Job struct {
+	Base
+	OrganizationID uuid.UUID       `json:"organization_id" gorm:"type:uuid;not null"`
+	Type           string          `json:"type" gorm:"type:varchar(50);not null"`
+	Singleton      bool            `json:"-" gorm:"not null"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb;not null"`
+	Status         string          `json:"status" gorm:"type:varchar(20);not null"`
+	Processed      int             `json:"processed" gorm:"not null"`
+	Total          int             `json:"total" gorm:"not null"`
+	Result         json.RawMessage `json:"result,omitempty" gorm:"type:jsonb"`
+	Error          string          `json:"error,omitempty" gorm:"not null;default:''"`
+	Attempts       int             `json:"attempts" gorm:"not null"`
+	RequestedBy    uuid.UUID       `json:"requested_by" gorm:"type:uuid;not null"`
+	LeaseExpiresAt *time.Time      `json:"-"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+}
+
+// YearlyResetJobPayload is the input of a yearly reset job. The active
+// employees are listed when the job is queued, as listing them needs the
+// caller's credentials.
+type YearlyResetJobPayload struct {
+	Year        int         `json:"year"`
+	EmployeeIDs []uuid.UUID `json:"employee_ids"`
+}
+
+// JobMetrics describe the job queue: how many jobs wait or run, and per
+// type how many finished since the process started and how long they took
+type JobMetrics struct {
+	Queued  int64                     `json:"queued"`
+	Running int64                     `json:"running"`
+	Types   map[string]JobTypeMetrics `json:"types"`
+}
+
+type JobTypeMetrics struct {
+	Completed    int     `json:"completed"`
+	Failed       int     `json:"failed"`
+	TotalSeconds float64 `json:"total_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
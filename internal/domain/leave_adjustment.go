@@ -17,13 +17,43 @@ type LeaveBalanceAdjustment struct {
 	Comments       string        `json:"comments"`
 	Status         string        `json:"status" gorm:"default:'pending'"`
 	LeaveBalance   *LeaveBalance `json:"leave_balance,omitempty" gorm:"foreignKey:LeaveBalanceID"`
+	// TransferID is shared by the debit and credit sides of a balance
+	// transfer, and CounterpartID is the other side's adjustment
+	TransferID    *uuid.UUID `json:"transfer_id,omitempty" gorm:"type:uuid"`
+	CounterpartID *uuid.UUID `json:"counterpart_id,omitempty" gorm:"type:uuid"`
+	// HeldForApproval is set in create responses when auto-approval was
+	// asked for but a balance anomaly alert kept the adjustment pending
+	HeldForApproval bool `json:"held_for_approval,omitempty" gorm:"-"`
+	// EffectiveDate is the day a future-dated adjustment takes effect.
+	// Approved before then it stays scheduled, and AppliedAt is when its
+	// change reached the balance.
+	EffectiveDate *time.Time `json:"effective_date,omitempty" gorm:"type:date"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty"`
+	// BalanceBefore and BalanceAfter are the balance's total days right
+	// before and after the adjustment reached it, captured under the
+	// balance's lock; unset until it does, and on older adjustments
+	BalanceBefore *float64 `json:"balance_before,omitempty" gorm:"type:decimal(5,2)"`
+	BalanceAfter  *float64 `json:"balance_after,omitempty" gorm:"type:decimal(5,2)"`
+	// CapOverridden is set when an admin let the adjustment take the
+	// balance past its leave type's bank cap
+	CapOverridden bool `json:"cap_overridden,omitempty" gorm:"not null;default:false"`
 }
 
+// CreateBalanceAdjustmentRequest adds to or, when negative, deducts from a
+// balance's total. Adjustments start pending unless an admin sets
+// AutoApprove; with a future EffectiveDate, within the balance's year, an
+// approved adjustment is scheduled and applied on that day.
 type CreateBalanceAdjustmentRequest struct {
-	LeaveBalanceID uuid.UUID `json:"leave_balance_id" binding:"required"`
-	Adjustment     float64   `json:"adjustment" binding:"required,ne=0"`
-	Reason         string    `json:"reason" binding:"required,min=5,max=500"`
-	Comments       string    `json:"comments" binding:"max=1000"`
+	LeaveBalanceID uuid.UUID  `json:"leave_balance_id" binding:"required"`
+	Adjustment     float64    `json:"adjustment" binding:"required,ne=0"`
+	Reason         string     `json:"reason" binding:"required,min=5,max=500"`
+	Comments       string     `json:"comments" binding:"max=1000"`
+	AutoApprove    bool       `json:"auto_approve"`
+	EffectiveDate  *time.Time `json:"effective_date"`
+	// OverrideCap lets an admin bank more than the leave type's cap
+	OverrideCap bool      `json:"override_cap"`
+	PerformedBy uuid.UUID `json:"-"`
+	CallerRole  string    `json:"-"`
 }
 
 type UpdateBalanceAdjustmentRequest struct {
@@ -32,11 +62,43 @@ type UpdateBalanceAdjustmentRequest struct {
 	ApprovedBy uuid.UUID `json:"approved_by" binding:"required_if=Status approved"`
 }
 
+// ListBalanceAdjustmentsParams filters an organization's adjustments.
+// Status defaults to pending, "all" lists every status; From and To bound
+// the day the adjustment was made.
+type ListBalanceAdjustmentsParams struct {
+	Page       int
+	PageSize   int
+	Status     string
+	EmployeeID *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// BalanceAdjustmentActionRequest carries the optional comment on approving
+// or rejecting an adjustment
+type BalanceAdjustmentActionRequest struct {
+	Comments string `json:"comments" binding:"max=1000"`
+}
+
 // Constants for balance adjustment
 const (
 	AdjustmentStatusPending  = "pending"
 	AdjustmentStatusApproved = "approved"
 	AdjustmentStatusRejected = "rejected"
+	// AdjustmentStatusReverted is an approved adjustment whose change to
+	// the total was taken back; a correction records the reversal
+	AdjustmentStatusReverted = "reverted"
+	// AdjustmentStatusCorrection documents a fix made by a balance
+	// recalculation. Adjustment records the change to the total, but unlike
+	// approved adjustments it does not count towards it.
+	AdjustmentStatusCorrection = "correction"
+	// AdjustmentStatusScheduled is an approved adjustment waiting for its
+	// effective date; it counts towards the total once applied, when it
+	// becomes approved
+	AdjustmentStatusScheduled = "scheduled"
+	// AdjustmentStatusCancelled is a scheduled adjustment called off
+	// before it applied
+	AdjustmentStatusCancelled = "cancelled"
 )
 
 // Methods for LeaveBalanceAdjustment
@@ -48,10 +110,60 @@ func (a *LeaveBalanceAdjustment) IsNegative() bool {
 	return a.Adjustment < 0
 }
 
+// CanTransitionTo reports whether the adjustment may move to status:
+// pending ones are approved, scheduled or rejected, scheduled ones applied
+// or cancelled, approved ones reverted. Rejected, reverted, cancelled and
+// correction adjustments are final.
+func (a *LeaveBalanceAdjustment) CanTransitionTo(status string) bool {
+	switch a.Status {
+	case AdjustmentStatusPending:
+		return status == AdjustmentStatusApproved || status == AdjustmentStatusScheduled ||
+			status == AdjustmentStatusRejected
+	case AdjustmentStatusScheduled:
+		return status == AdjustmentStatusApproved || status == AdjustmentStatusCancelled
+	case AdjustmentStatusApproved:
+		return status == AdjustmentStatusReverted
+	}
+	return false
+}
+
 func (a *LeaveBalanceAdjustment) CanApprove() bool {
 	return a.Status == AdjustmentStatusPending
 }
 
 func (a *LeaveBalanceAdjustment) CanReject() bool {
-	return a.Status == AdjustmentStatusPending
+	return a.CanTransitionTo(AdjustmentStatusRejected)
+}
+
+func (a *LeaveBalanceAdjustment) CanRevert() bool {
+	return a.CanTransitionTo(AdjustmentStatusReverted)
+}
+
+func (a *LeaveBalanceAdjustment) CanCancel() bool {
+	return a.CanTransitionTo(AdjustmentStatusCancelled)
+}
+
+// RecordBalance snapshots the balance's total days before the adjustment
+// and after it is added
+func (a *LeaveBalanceAdjustment) RecordBalance(before float64) {
+	after := before + a.Adjustment
+	a.BalanceBefore = &before
+	a.BalanceAfter = &after
+}
+
+// EffectiveAfter reports whether the adjustment takes effect after today,
+// so approving it schedules it instead of applying it
+func (a *LeaveBalanceAdjustment) EffectiveAfter(today time.Time) bool {
+	return a.EffectiveDate != nil && TruncateToDate(*a.EffectiveDate).After(TruncateToDate(today))
+}
+
+// ScheduledAdjustmentsApplication is the outcome of applying the
+// scheduled adjustments that fell due. Failed ones, e.g. deductions the
+// balance no longer covers, stay scheduled for the next run.
+type ScheduledAdjustmentsApplication struct {
+	OrganizationID uuid.UUID   `json:"organization_id"`
+	Date           time.Time   `json:"date"`
+	Due            int         `json:"due"`
+	Applied        []uuid.UUID `json:"applied"`
+	Failed         []uuid.UUID `json:"failed"`
 }
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaveBalanceAdjustmentCanTransitionTo(t *testing.T) {
+	statuses := []string{
+		AdjustmentStatusPending, AdjustmentStatusScheduled, AdjustmentStatusApproved, AdjustmentStatusRejected,
+		AdjustmentStatusReverted, AdjustmentStatusCancelled, AdjustmentStatusCorrection,
+	}
+	allowed := map[string][]string{
+		AdjustmentStatusPending:   {AdjustmentStatusApproved, AdjustmentStatusScheduled, AdjustmentStatusRejected},
+		AdjustmentStatusScheduled: {AdjustmentStatusApproved, AdjustmentStatusCancelled},
+		AdjustmentStatusApproved:  {AdjustmentStatusReverted},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, status := range allowed[from] {
+				want = want || status == to
+			}
+			adjustment := &LeaveBalanceAdjustment{Status: from}
+			if got := adjustment.CanTransitionTo(to); got != want {
+				t.Errorf("%s to %s: got %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if !(&LeaveBalanceAdjustment{Status: AdjustmentStatusApproved}).CanRevert() {
+		t.Error("an approved adjustment cannot be reverted")
+	}
+	if (&LeaveBalanceAdjustment{Status: AdjustmentStatusCorrection}).CanRevert() {
+		t.Error("a correction can be reverted")
+	}
+}
+
+func TestLeaveBalanceAdjustmentRecordBalance(t *testing.T) {
+	adjustment := &LeaveBalanceAdjustment{Adjustment: -2.5}
+	adjustment.RecordBalance(20)
+	if *adjustment.BalanceBefore != 20 || *adjustment.BalanceAfter != 17.5 {
+		t.Errorf("got %v to %v, want 20 to 17.5", *adjustment.BalanceBefore, *adjustment.BalanceAfter)
+	}
+}
+
+func TestLeaveBalanceAdjustmentEffectiveAfter(t *testing.T) {
+	today := time.Date(2026, 6, 10, 18, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		date := time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	tests := []struct {
+		name      string
+		effective *time.Time
+		want      bool
+	}{
+		{name: "no effective date"},
+		{name: "yesterday", effective: day(9)},
+		{name: "today", effective: day(10)},
+		{name: "tomorrow", effective: day(11), want: true},
+	}
+	for _, tt := range tests {
+		adjustment := &LeaveBalanceAdjustment{EffectiveDate: tt.effective}
+		if got := adjustment.EffectiveAfter(today); got != tt.want {
+			t.Errorf("%s: EffectiveAfter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
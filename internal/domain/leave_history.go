@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// LeaveHistoryYears is how many leave years the history summary covers,
+// counting the current one
+const LeaveHistoryYears = 5
+
+// LeaveHistorySummary is an employee's use of their entitlement over the
+// last LeaveHistoryYears years, newest first
+type LeaveHistorySummary struct {
+	EmployeeID uuid.UUID          `json:"employee_id"`
+	Years      []LeaveHistoryYear `json:"years"`
+}
+
+// LeaveHistoryYear totals a year's leave of the paid types counted in days;
+// LeaveTypes breaks it down per type, each in its own unit.
+type LeaveHistoryYear struct {
+	Year int `json:"year"`
+	LeaveHistoryFigures
+	// Reconstructed is set when any type's figures for the year were
+	// reconstructed rather than read from a balance
+	Reconstructed bool                   `json:"reconstructed"`
+	LeaveTypes    []LeaveHistoryTypeYear `json:"leave_types"`
+}
+
+type LeaveHistoryTypeYear struct {
+	LeaveTypeID uuid.UUID `json:"leave_type_id"`
+	LeaveType   string    `json:"leave_type"`
+	Unit        string    `json:"unit"`
+	LeaveHistoryFigures
+	// Reconstructed figures come from the year's approved requests and the
+	// type's current default days, as the employee had no balance that
+	// year; nothing carried over or forfeited is known for them.
+	Reconstructed bool `json:"reconstructed"`
+}
+
+// LeaveHistoryFigures describe one year of a balance. Entitled is what was
+// granted for the year, including adjustments but not the CarriedOver days
+// brought in from the year before; Forfeited is carried-over days that
+// expired unused. Utilization is Taken as a percentage of Entitled plus
+// CarriedOver, 0 when there was nothing to take.
+type LeaveHistoryFigures struct {
+	Entitled    float64 `json:"entitled"`
+	Taken       float64 `json:"taken"`
+	CarriedOver float64 `json:"carried_over"`
+	Forfeited   float64 `json:"forfeited"`
+	Utilization float64 `json:"utilization"`
+}
+
+// Add sums other into f, leaving Utilization to SetUtilization
+func (f *LeaveHistoryFigures) Add(other LeaveHistoryFigures) {
+	f.Entitled += other.Entitled
+	f.Taken += other.Taken
+	f.CarriedOver += other.CarriedOver
+	f.Forfeited += other.Forfeited
+}
+
+// SetUtilization computes Utilization from the other figures, rounded to
+// one decimal
+func (f *LeaveHistoryFigures) SetUtilization() {
+	f.Utilization = 0
+	if available := f.Entitled + f.CarriedOver; available > 0 {
+		f.Utilization = math.Round(f.Taken/available*1000) / 10
+	}
+}
+
+// BalanceHistoryFigures reads a year's figures from its balance. The
+// forfeited days were taken off TotalDays, so they are added back along
+// with the carried-over days to find what was granted.
+func BalanceHistoryFigures(balance *LeaveBalance, forfeited float64) LeaveHistoryFigures {
+	figures := LeaveHistoryFigures{
+		Entitled:    balance.TotalDays - balance.CarriedOverDays + forfeited,
+		Taken:       balance.UsedDays,
+		CarriedOver: balance.CarriedOverDays,
+		Forfeited:   forfeited,
+	}
+	figures.SetUtilization()
+	return figures
+}
+
+// ReconstructedHistoryFigures estimates a year's figures without a balance
+// from the leave taken and the type's default entitlement
+func ReconstructedHistoryFigures(leaveType *LeaveType, taken float64) LeaveHistoryFigures {
+	figures := LeaveHistoryFigures{
+		Entitled: float64(leaveType.DefaultDays),
+		Taken:    taken,
+	}
+	figures.SetUtilization()
+	return figures
+}
+
+// LeaveTakenByYear is the approved leave of one type starting in a year
+type LeaveTakenByYear struct {
+	Year        int
+	LeaveTypeID uuid.UUID
+	Days        float64
+}
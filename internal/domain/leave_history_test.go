@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestBalanceHistoryFigures(t *testing.T) {
+	balance := &LeaveBalance{TotalDays: 23, CarriedOverDays: 5, UsedDays: 10}
+
+	got := BalanceHistoryFigures(balance, 2)
+	want := LeaveHistoryFigures{Entitled: 20, Taken: 10, CarriedOver: 5, Forfeited: 2, Utilization: 40}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestReconstructedHistoryFigures(t *testing.T) {
+	got := ReconstructedHistoryFigures(&LeaveType{DefaultDays: 20}, 7)
+	want := LeaveHistoryFigures{Entitled: 20, Taken: 7, Utilization: 35}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLeaveHistoryFiguresSetUtilization(t *testing.T) {
+	tests := []struct {
+		name    string
+		figures LeaveHistoryFigures
+		want    float64
+	}{
+		{name: "nothing to take", figures: LeaveHistoryFigures{Taken: 3}, want: 0},
+		{name: "rounded to one decimal", figures: LeaveHistoryFigures{Entitled: 3, Taken: 1}, want: 33.3},
+		{name: "carry-over counts as available", figures: LeaveHistoryFigures{Entitled: 15, CarriedOver: 5, Taken: 5}, want: 25},
+		{name: "over-used", figures: LeaveHistoryFigures{Entitled: 10, Taken: 12}, want: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.figures.Utilization = -1
+			tt.figures.SetUtilization()
+			if tt.figures.Utilization != tt.want {
+				t.Errorf("got %v, want %v", tt.figures.Utilization, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveRegisterPageSize is how many requests the leave register reads at a
+// time while writing a sheet
+const LeaveRegisterPageSize = 500
+
+// LeaveRegisterParams selects the requests of a leave register: those
+// starting in Year, optionally of one leave type. MaxRows refuses
+// registers listing more requests; 0 means no limit.
+type LeaveRegisterParams struct {
+	Year        int
+	LeaveTypeID *uuid.UUID
+	MaxRows     int
+}
+
+// LeaveRegisterType totals one leave type's requests in a register.
+// Approved includes leave awaiting cancellation, and ApprovedDays are in
+// the type's unit.
+type LeaveRegisterType struct {
+	LeaveTypeID  uuid.UUID `json:"leave_type_id"`
+	LeaveType    string    `json:"leave_type"`
+	Unit         string    `json:"unit"`
+	Requests     int64     `json:"requests"`
+	Approved     int64     `json:"approved"`
+	Pending      int64     `json:"pending"`
+	Rejected     int64     `json:"rejected"`
+	Cancelled    int64     `json:"cancelled"`
+	ApprovedDays float64   `json:"approved_days"`
+}
+
+// LeaveRegister is the organization's requests starting within a year,
+// one sheet per leave type with requests, for auditors
+type LeaveRegister struct {
+	OrganizationID uuid.UUID           `json:"organization_id"`
+	Year           int                 `json:"year"`
+	StartDate      time.Time           `json:"start_date"`
+	EndDate        time.Time           `json:"end_date"`
+	Requests       int64               `json:"requests"`
+	Types          []LeaveRegisterType `json:"types"`
+}
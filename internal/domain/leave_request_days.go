@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveRequestDay is one working day a leave request covers. Fraction is 1
+// for a full day and 0.5 for a half day, before the leave type's rounding.
+// The rows are written with the request and replaced when its dates change;
+// whether they count is decided by the request's status.
+type LeaveRequestDay struct {
+	Base
+	LeaveRequestID uuid.UUID     `json:"leave_request_id" gorm:"type:uuid;not null"`
+	Date           time.Time     `json:"date" gorm:"type:date;not null"`
+	Fraction       float64       `json:"fraction" gorm:"type:decimal(3,2);not null"`
+	LeaveRequest   *LeaveRequest `json:"-" gorm:"foreignKey:LeaveRequestID"`
+	// Sandwiched marks a weekend or holiday charged because it lies between
+	// two leave days of the request
+	Sandwiched bool `json:"sandwiched,omitempty" gorm:"not null;default:false"`
+}
+
+// LeaveDayBreakdown lists the working days of a leave over [start, end],
+// skipping weekends and the given holidays
+func LeaveDayBreakdown(start, end time.Time, startHalf, endHalf string, holidays []Holiday) []LeaveRequestDay {
+	closed := holidayDates(holidays)
+	var days []LeaveRequestDay
+	for day := TruncateToDate(start); !day.After(TruncateToDate(end)); day = day.AddDate(0, 0, 1) {
+		if !isWorkingDay(day, closed) {
+			continue
+		}
+		am, pm := halvesOff(day, start, end, startHalf, endHalf)
+		fraction := 0.0
+		if am {
+			fraction += 0.5
+		}
+		if pm {
+			fraction += 0.5
+		}
+		days = append(days, LeaveRequestDay{Date: day, Fraction: fraction})
+	}
+	return days
+}
+
+// SandwichDays adds every weekend and holiday lying between the first and
+// last day of a working day breakdown as a full sandwiched day. Non-working
+// days before the first or after the last leave day are never added, so
+// leave ending on a Friday or starting on a Monday charges no weekend.
+func SandwichDays(breakdown []LeaveRequestDay) []LeaveRequestDay {
+	if len(breakdown) < 2 {
+		return breakdown
+	}
+	days := make([]LeaveRequestDay, 0, len(breakdown))
+	next := TruncateToDate(breakdown[0].Date)
+	for _, d := range breakdown {
+		for day := TruncateToDate(d.Date); next.Before(day); next = next.AddDate(0, 0, 1) {
+			days = append(days, LeaveRequestDay{Date: next, Fraction: 1, Sandwiched: true})
+		}
+		days = append(days, d)
+		next = next.AddDate(0, 0, 1)
+	}
+	return days
+}
+
+// SetBreakdown stores the days of a request and splits its raw days into
+// working and sandwiched days
+func (l *LeaveRequest) SetBreakdown(breakdown []LeaveRequestDay) {
+	l.Breakdown = breakdown
+	l.WorkingDays, l.SandwichedDays = 0, 0
+	for _, d := range breakdown {
+		if d.Sandwiched {
+			l.SandwichedDays += d.Fraction
+		} else {
+			l.WorkingDays += d.Fraction
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSandwichDays(t *testing.T) {
+	// 2026-03-06 is a Friday
+	friday := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	saturday, sunday, monday := friday.AddDate(0, 0, 1), friday.AddDate(0, 0, 2), friday.AddDate(0, 0, 3)
+	holiday := func(day time.Time) []Holiday { return []Holiday{{Name: "Poya", Date: day.Add(9 * time.Hour)}} }
+
+	tests := []struct {
+		name               string
+		start, end         time.Time
+		startHalf, endHalf string
+		holidays           []Holiday
+		wantSandwiched     []time.Time
+		wantWorking        float64
+	}{
+		{name: "Friday to Monday", start: friday, end: monday,
+			wantSandwiched: []time.Time{saturday, sunday}, wantWorking: 2},
+		{name: "Friday afternoon to Monday morning", start: friday, end: monday, startHalf: HalfDayPM, endHalf: HalfDayAM,
+			wantSandwiched: []time.Time{saturday, sunday}, wantWorking: 1},
+		{name: "over a weekend and a holiday", start: friday.AddDate(0, 0, -1), end: monday.AddDate(0, 0, 1), holidays: holiday(monday),
+			wantSandwiched: []time.Time{saturday, sunday, monday}, wantWorking: 3},
+		{name: "over a midweek holiday", start: monday, end: monday.AddDate(0, 0, 4), holidays: holiday(monday.AddDate(0, 0, 2)),
+			wantSandwiched: []time.Time{monday.AddDate(0, 0, 2)}, wantWorking: 4},
+		{name: "two weekends", start: friday, end: monday.AddDate(0, 0, 7),
+			wantSandwiched: []time.Time{saturday, sunday, saturday.AddDate(0, 0, 7), sunday.AddDate(0, 0, 7)}, wantWorking: 7},
+		// Non-working days at either edge do not bridge anything
+		{name: "Monday to Friday", start: monday, end: monday.AddDate(0, 0, 4), wantWorking: 5},
+		{name: "ending on a Friday", start: friday.AddDate(0, 0, -1), end: friday, wantWorking: 2},
+		{name: "starting on a Monday", start: monday, end: monday.AddDate(0, 0, 1), wantWorking: 2},
+		{name: "Friday through the weekend", start: friday, end: sunday, wantWorking: 1},
+		{name: "weekend into Monday", start: saturday, end: monday, wantWorking: 1},
+		{name: "ending before a holiday", start: monday, end: monday.AddDate(0, 0, 4), holidays: holiday(monday.AddDate(0, 0, 4)), wantWorking: 4},
+		{name: "single day", start: friday, end: friday, wantWorking: 1},
+		{name: "weekend only", start: saturday, end: sunday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := SandwichDays(LeaveDayBreakdown(tt.start, tt.end, tt.startHalf, tt.endHalf, tt.holidays))
+
+			var sandwiched []time.Time
+			working := 0.0
+			for i, d := range days {
+				// Every day from the first leave day to the last is listed
+				if i > 0 && !d.Date.Equal(days[i-1].Date.AddDate(0, 0, 1)) {
+					t.Errorf("gap before %s left unfilled", d.Date.Format("2006-01-02"))
+				}
+				if d.Sandwiched {
+					if d.Fraction != 1 {
+						t.Errorf("%s is sandwiched as %v of a day, want a full day", d.Date.Format("2006-01-02"), d.Fraction)
+					}
+					sandwiched = append(sandwiched, d.Date)
+				} else {
+					working += d.Fraction
+				}
+			}
+
+			if len(sandwiched) != len(tt.wantSandwiched) {
+				t.Fatalf("sandwiched %v, want %v", sandwiched, tt.wantSandwiched)
+			}
+			for i := range sandwiched {
+				if !sandwiched[i].Equal(tt.wantSandwiched[i]) {
+					t.Errorf("sandwiched %v, want %v", sandwiched, tt.wantSandwiched)
+					break
+				}
+			}
+			if working != tt.wantWorking {
+				t.Errorf("working days = %v, want %v", working, tt.wantWorking)
+			}
+
+			var request LeaveRequest
+			request.SetBreakdown(days)
+			if request.WorkingDays != tt.wantWorking || request.SandwichedDays != float64(len(tt.wantSandwiched)) {
+				t.Errorf("SetBreakdown split %v working and %v sandwiched, want %v and %d",
+					request.WorkingDays, request.SandwichedDays, tt.wantWorking, len(tt.wantSandwiched))
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveStatementBalance is an employee's balance of one leave type at the
+// time of the statement, in the type's unit. Opening is the balance as the
+// yearly reset left it, nil when the year was not opened by a reset.
+type LeaveStatementBalance struct {
+	LeaveTypeID   uuid.UUID              `json:"leave_type_id"`
+	LeaveType     string                 `json:"leave_type"`
+	Unit          string                 `json:"unit"`
+	Opening       *LeaveStatementOpening `json:"opening,omitempty"`
+	TotalDays     float64                `json:"total_days"`
+	UsedDays      float64                `json:"used_days"`
+	PendingDays   float64                `json:"pending_days"`
+	RemainingDays float64                `json:"remaining_days"`
+}
+
+// LeaveStatementOpening is the opening balance of the year, taken from the
+// latest snapshot the yearly reset wrote of it
+type LeaveStatementOpening struct {
+	RunID           uuid.UUID `json:"run_id"`
+	SnapshotAt      time.Time `json:"snapshot_at"`
+	TotalDays       float64   `json:"total_days"`
+	UsedDays        float64   `json:"used_days"`
+	PendingDays     float64   `json:"pending_days"`
+	RemainingDays   float64   `json:"remaining_days"`
+	CarriedOverDays float64   `json:"carried_over_days"`
+}
+
+// LeaveStatementRequest is a request starting in the statement's year
+type LeaveStatementRequest struct {
+	ID        uuid.UUID `json:"id"`
+	LeaveType string    `json:"leave_type"`
+	Unit      string    `json:"unit"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Days      float64   `json:"days"`
+	Status    string    `json:"status"`
+}
+
+// LeaveStatementAdjustment is an adjustment made to one of the year's
+// balances, oldest first
+type LeaveStatementAdjustment struct {
+	ID         uuid.UUID `json:"id"`
+	LeaveType  string    `json:"leave_type"`
+	Unit       string    `json:"unit"`
+	Date       time.Time `json:"date"`
+	Adjustment float64   `json:"adjustment"`
+	Reason     string    `json:"reason"`
+	Status     string    `json:"status"`
+}
+
+// LeaveStatement is an employee's leave position for a year: their
+// balances, the requests starting in the year and the adjustments to the
+// balances. OrganizationName falls back to the organization's id when the
+// organization service cannot be reached.
+type LeaveStatement struct {
+	OrganizationID   uuid.UUID                  `json:"organization_id"`
+	OrganizationName string                     `json:"organization_name"`
+	EmployeeID       uuid.UUID                  `json:"employee_id"`
+	EmployeeEmail    string                     `json:"employee_email,omitempty"`
+	Year             int                        `json:"year"`
+	GeneratedAt      time.Time                  `json:"generated_at"`
+	Balances         []LeaveStatementBalance    `json:"balances"`
+	Requests         []LeaveStatementRequest    `json:"requests"`
+	Adjustments      []LeaveStatementAdjustment `json:"adjustments"`
+}
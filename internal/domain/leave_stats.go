@@ -2,23 +2,33 @@
 package domain
 
 import (
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// LeaveStats represents overall leave statistics
+// LeaveStats represents overall leave statistics. PaidDaysTaken and
+// UnpaidDaysTaken add up to TotalDaysTaken. The breakdowns are queried
+// separately, so the totals scan skips them.
 type LeaveStats struct {
-	TotalRequests  int64           `json:"total_requests"`
-	TotalDaysTaken float64         `json:"total_days_taken"`
-	LeaveByType    []LeaveByType   `json:"leave_by_type"`
-	LeaveByStatus  []LeaveByStatus `json:"leave_by_status"`
-	MonthlyStats   []MonthlyStats  `json:"monthly_stats"`
+	TotalRequests   int64           `json:"total_requests"`
+	TotalDaysTaken  float64         `json:"total_days_taken"`
+	PaidDaysTaken   float64         `json:"paid_days_taken"`
+	UnpaidDaysTaken float64         `json:"unpaid_days_taken"`
+	LeaveByType     []LeaveByType   `json:"leave_by_type" gorm:"-"`
+	LeaveByStatus   []LeaveByStatus `json:"leave_by_status" gorm:"-"`
+	MonthlyStats    []MonthlyStats  `json:"monthly_stats" gorm:"-"`
 }
 
-// LeaveByType represents leave statistics grouped by leave type
+// LeaveByType represents leave statistics grouped by leave type. TotalDays
+// is in the type's unit.
 type LeaveByType struct {
 	LeaveType string  `json:"leave_type"`
+	IsPaid    bool    `json:"is_paid"`
+	Unit      string  `json:"unit"`
 	Count     int64   `json:"count"`
 	TotalDays float64 `json:"total_days"`
 }
@@ -37,15 +47,37 @@ type MonthlyStats struct {
 	TotalDays float64   `json:"total_days"`
 }
 
-// DepartmentLeaveStats represents leave statistics for a department
+// UnassignedDepartment names the group of employees the organization
+// service does not place in a department
+const UnassignedDepartment = "unassigned"
+
+// DepartmentLeaveStats represents leave statistics for a department.
+// DepartmentID is nil for the unassigned group.
 type DepartmentLeaveStats struct {
-	DepartmentID   uuid.UUID     `json:"department_id"`
+	DepartmentID   *uuid.UUID    `json:"department_id"`
 	DepartmentName string        `json:"department_name"`
 	TotalRequests  int64         `json:"total_requests"`
 	TotalDaysTaken float64       `json:"total_days_taken"`
 	LeaveByType    []LeaveByType `json:"leave_by_type"`
 }
 
+// EmployeeLeaveByType is one employee's leave of one type
+type EmployeeLeaveByType struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	LeaveByType
+}
+
+// DepartmentAnalysisReport breaks the leave of a period down per
+// department, most days taken first. Warning explains why employees may be
+// missing from their department when the organization service could not be
+// fully read.
+type DepartmentAnalysisReport struct {
+	StartDate   time.Time              `json:"start_date"`
+	EndDate     time.Time              `json:"end_date"`
+	Departments []DepartmentLeaveStats `json:"departments"`
+	Warning     string                 `json:"warning,omitempty"`
+}
+
 // EmployeeLeaveStats represents leave statistics for an employee
 type EmployeeLeaveStats struct {
 	EmployeeID     uuid.UUID           `json:"employee_id"`
@@ -92,6 +124,66 @@ type TrendData struct {
 	Trend  string    `json:"trend"` // increasing, decreasing, stable
 }
 
+// Directions of a TrendData point against the one before
+const (
+	TrendIncreasing = "increasing"
+	TrendDecreasing = "decreasing"
+	TrendStable     = "stable"
+)
+
+// A month is stable against the one before when it differs by at most
+// TrendTolerance of it, or TrendMinChange days for small months
+const (
+	TrendTolerance = 0.05
+	TrendMinChange = 0.5
+)
+
+// MaxTrendMonths bounds the months of a trends report
+const MaxTrendMonths = 36
+
+// MonthlyTrendsReport is approved leave per calendar month, oldest first,
+// with the direction of each month against the one before. Days of
+// requests spanning months count toward the month they fall in.
+type MonthlyTrendsReport struct {
+	Months       int            `json:"months"`
+	LeaveTypeID  *uuid.UUID     `json:"leave_type_id,omitempty"`
+	MonthlyStats []MonthlyStats `json:"monthly_stats"`
+	Trend        []TrendData    `json:"trend"`
+}
+
+// BuildMonthlyTrends lays out the months from first on, filling those
+// missing from stats with zeros, and the trend of their days
+func BuildMonthlyTrends(first time.Time, months int, stats []MonthlyStats) ([]MonthlyStats, []TrendData) {
+	byMonth := make(map[time.Time]MonthlyStats, len(stats))
+	for _, m := range stats {
+		key := time.Date(m.Month.Year(), m.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		byMonth[key] = m
+	}
+
+	monthly := make([]MonthlyStats, 0, months)
+	trend := make([]TrendData, 0, months)
+	for i := 0; i < months; i++ {
+		month := time.Date(first.Year(), first.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		m := byMonth[month]
+		m.Month = month
+		monthly = append(monthly, m)
+
+		direction := TrendStable
+		if i > 0 {
+			prior := monthly[i-1].TotalDays
+			tolerance := math.Max(prior*TrendTolerance, TrendMinChange)
+			switch {
+			case m.TotalDays > prior+tolerance:
+				direction = TrendIncreasing
+			case m.TotalDays < prior-tolerance:
+				direction = TrendDecreasing
+			}
+		}
+		trend = append(trend, TrendData{Period: month, Value: m.TotalDays, Trend: direction})
+	}
+	return monthly, trend
+}
+
 // Methods for LeaveStats
 func (s *LeaveStats) GetAverageLeaveLength() float64 {
 	if s.TotalRequests == 0 {
@@ -125,3 +217,87 @@ func (s *LeaveStats) GetMonthlyAverage() float64 {
 	}
 	return total / float64(len(s.MonthlyStats))
 }
+
+// ApproverDecision is one approve or reject decision with the timestamps
+// needed to measure how long it took
+type ApproverDecision struct {
+	ApproverID  uuid.UUID `json:"approver_id"`
+	Action      string    `json:"action"`
+	RequestedAt time.Time `json:"requested_at"`
+	DecidedAt   time.Time `json:"decided_at"`
+	StartDate   time.Time `json:"start_date"`
+}
+
+// ApproverPerformance summarizes one approver's decisions over a period.
+// Decision times are in business hours.
+type ApproverPerformance struct {
+	ApproverID          uuid.UUID `json:"approver_id"`
+	Decided             int       `json:"decided"`
+	Approved            int       `json:"approved"`
+	Rejected            int       `json:"rejected"`
+	ApprovalRate        float64   `json:"approval_rate"`
+	MedianDecisionHours float64   `json:"median_decision_hours"`
+	P90DecisionHours    float64   `json:"p90_decision_hours"`
+	// Overdue counts decisions made after the leave had started plus
+	// assigned requests still pending past their start date
+	Overdue int `json:"overdue"`
+}
+
+// ApproverPerformanceReport is the per-approver breakdown for a period
+type ApproverPerformanceReport struct {
+	StartDate time.Time             `json:"start_date"`
+	EndDate   time.Time             `json:"end_date"`
+	Approvers []ApproverPerformance `json:"approvers"`
+}
+
+// HolidayImpact is what holidays take out of a period for the employees of
+// one location, who observe the organization-wide holidays and the
+// location's own. An empty Location stands for employees without one.
+type HolidayImpact struct {
+	Location string `json:"location"`
+	Holidays int    `json:"holidays"`
+	// WorkingDaysLost counts the holidays falling on weekdays and
+	// WorkingDays the weekdays left to work
+	WorkingDaysLost int `json:"working_days_lost"`
+	WorkingDays     int `json:"working_days"`
+}
+
+// HolidayImpactReport breaks a period's holidays down per location
+type HolidayImpactReport struct {
+	StartDate time.Time       `json:"start_date"`
+	EndDate   time.Time       `json:"end_date"`
+	Locations []HolidayImpact `json:"locations"`
+}
+
+// BuildHolidayImpact computes the impact of the holidays within
+// [from, last] for employees without a location and for every location
+// that has holidays of its own, in that order
+func BuildHolidayImpact(from, last time.Time, holidays []Holiday) []HolidayImpact {
+	locations := []string{""}
+	seen := map[string]bool{"": true}
+	for _, h := range holidays {
+		key := strings.ToLower(h.LocationName())
+		if !seen[key] {
+			seen[key] = true
+			locations = append(locations, h.LocationName())
+		}
+	}
+	own := locations[1:]
+	sort.Slice(own, func(i, j int) bool {
+		return strings.ToLower(own[i]) < strings.ToLower(own[j])
+	})
+
+	weekdays := int(CalculateWorkingDays(from, last, nil))
+	impacts := make([]HolidayImpact, 0, len(locations))
+	for _, location := range locations {
+		observed := HolidaysAt(holidays, location)
+		working := int(CalculateWorkingDays(from, last, observed))
+		impacts = append(impacts, HolidayImpact{
+			Location:        location,
+			Holidays:        len(observed),
+			WorkingDaysLost: weekdays - working,
+			WorkingDays:     working,
+		})
+	}
+	return impacts
+}
@@ -2,13 +2,16 @@ package domain
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// LeaveType represents different types of leave (vacation, sick, etc.)
+// LeaveType represents different types of leave (vacation, sick, etc.).
+// Unpaid types carry no entitlement: their balances are opened by the first
+// request of the year, with DefaultDays as the cap on days taken.
 type LeaveType struct {
 	Base
 	ID                uuid.UUID `json:"id"`
@@ -18,9 +21,100 @@ type LeaveType struct {
 	Color             string    `json:"color" gorm:"type:varchar(7)" binding:"required,hexcolor"`
 	DefaultDays       int       `json:"default_days" binding:"required,min=0,max=365"`
 	IsPaid            bool      `json:"is_paid" gorm:"default:true"`
-	RequiresApproval  bool      `json:"requires_approval" gorm:"default:true"`
+	RequiresApproval  bool      `json:"requires_approval"`
 	MinDaysNotice     int       `json:"min_days_notice" gorm:"default:0" binding:"min=0"`
 	MaxDaysPerRequest int       `json:"max_days_per_request" binding:"required,min=1,max=365"`
+	Rounding          string    `json:"rounding" gorm:"type:varchar(10);default:'none'" binding:"omitempty,oneof=none half_up full_up"`
+	// AllowNegativeBalance lets HR accept requests beyond the remaining balance
+	AllowNegativeBalance bool `json:"allow_negative_balance" gorm:"default:false"`
+	// MaxNegativeDays caps how far below zero a balance of a type that
+	// allows a negative balance may go; nil leaves it uncapped
+	MaxNegativeDays *float64 `json:"max_negative_days,omitempty" gorm:"type:decimal(5,2)"`
+	// ApprovalStrategy selects who approves requests of this type; ApproverRole
+	// and ApproverPoolID configure the role_based and round_robin strategies
+	ApprovalStrategy string     `json:"approval_strategy" gorm:"type:varchar(20);default:'direct_manager'"`
+	ApproverRole     string     `json:"approver_role,omitempty"`
+	ApproverPoolID   *uuid.UUID `json:"approver_pool_id,omitempty" gorm:"type:uuid"`
+	// AllowBackdated lets employees file requests that start in the past,
+	// e.g. sick leave reported after the fact
+	AllowBackdated bool `json:"allow_backdated"`
+	// Unit is days or hours. Balances and request amounts of hourly types
+	// are kept in hours.
+	Unit string `json:"unit" gorm:"type:varchar(5);default:'days'" binding:"omitempty,oneof=days hours"`
+	// TracksBalance is false for types such as work from home whose
+	// requests are never charged to a balance
+	TracksBalance bool `json:"tracks_balance" gorm:"not null"`
+	// MinTenureDays is how many days after their start date employees
+	// must wait before their leave of this type may begin, e.g. during
+	// probation
+	MinTenureDays int `json:"min_tenure_days" gorm:"not null;default:0" binding:"min=0"`
+	// Eligibility limits the type to employees with matching attributes,
+	// e.g. a location for regional leave; nil means everyone is eligible
+	Eligibility *EligibilityRule `json:"eligibility,omitempty" gorm:"type:jsonb"`
+	// QuorumSize is how many candidates must approve under the quorum
+	// strategy, whose candidates are the members of ApproverPoolID and the
+	// holders of ApproverRole
+	QuorumSize int `json:"quorum_size" gorm:"not null;default:1"`
+	// MaxCarryOverDays caps the unused balance carried into the next year
+	// by the yearly reset; 0 carries nothing over
+	MaxCarryOverDays float64 `json:"max_carry_over_days" gorm:"type:decimal(5,2);not null;default:0" binding:"min=0"`
+	// CarryOverExpiryMonths is how many months into the new year carried
+	// days stay usable, e.g. 3 for the end of March; 0 never expires them
+	CarryOverExpiryMonths int `json:"carry_over_expiry_months" gorm:"not null;default:0" binding:"min=0,max=12"`
+	// AccrualFrequency grants the allowance monthly or quarterly instead of
+	// up front: balances open empty and AccrualRate, in the type's unit, is
+	// added each period until the year's accruals reach DefaultDays
+	AccrualFrequency string  `json:"accrual_frequency" gorm:"type:varchar(10);not null;default:'none'" binding:"omitempty,oneof=none monthly quarterly"`
+	AccrualRate      float64 `json:"accrual_rate" gorm:"type:decimal(5,2);not null;default:0" binding:"min=0"`
+	// MaxBalanceDays caps what a balance may bank, its total less the
+	// days used, in the type's unit; accruals beyond it are forfeited and
+	// manual adjustments beyond it need an admin override. nil leaves the
+	// bank uncapped.
+	MaxBalanceDays *float64 `json:"max_balance_days,omitempty" gorm:"type:decimal(5,2)"`
+	// AbsenceMonitored counts the type's leave, e.g. sickness, towards the
+	// organization's rolling absence alert thresholds
+	AbsenceMonitored bool `json:"absence_monitored" gorm:"not null;default:false"`
+	// BlockedDuringProbation refuses the type's leave, e.g. vacation, on
+	// any day of the employee's probation
+	BlockedDuringProbation bool `json:"blocked_during_probation" gorm:"not null;default:false"`
+}
+
+// CarryOver is how much of a remaining balance is carried into the next
+// year
+func (t *LeaveType) CarryOver(remaining float64) float64 {
+	if remaining <= 0 {
+		return 0
+	}
+	return math.Min(remaining, t.MaxCarryOverDays)
+}
+
+// CarryOverExpiry is the last day days carried into year can be used, nil
+// when they do not expire
+func (t *LeaveType) CarryOverExpiry(year int) *time.Time {
+	if t.CarryOverExpiryMonths == 0 {
+		return nil
+	}
+	expiry := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, t.CarryOverExpiryMonths, -1)
+	return &expiry
+}
+
+// EligibleFrom is the first date employees who started on startDate may
+// take leave of this type
+func (t *LeaveType) EligibleFrom(startDate time.Time) time.Time {
+	return TruncateToDate(startDate).AddDate(0, 0, t.MinTenureDays)
+}
+
+// BalanceFloor is the lowest remaining balance requests of the leave type
+// may be booked down to. ok is false when the balance may go below zero
+// without limit.
+func (t *LeaveType) BalanceFloor() (floor float64, ok bool) {
+	if !t.AllowNegativeBalance {
+		return 0, true
+	}
+	if t.MaxNegativeDays == nil {
+		return 0, false
+	}
+	return -*t.MaxNegativeDays, true
 }
 
 // LeaveBalance tracks employee's leave balance
@@ -33,25 +127,111 @@ type LeaveBalance struct {
 	TotalDays      float64    `json:"total_days" gorm:"type:decimal(5,2);not null"`
 	UsedDays       float64    `json:"used_days" gorm:"type:decimal(5,2);default:0"`
 	PendingDays    float64    `json:"pending_days" gorm:"type:decimal(5,2);default:0"`
-	RemainingDays  float64    `json:"remaining_days" gorm:"type:decimal(5,2)"`
+	RemainingDays  float64    `json:"remaining_days" gorm:"type:decimal(5,2);->"`
 	LeaveType      *LeaveType `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+	// CarriedOverDays is the part of TotalDays carried over from the year
+	// before. ClosedAt is set once the yearly reset has carried this
+	// balance into the next year.
+	CarriedOverDays float64    `json:"carried_over_days" gorm:"type:decimal(5,2);not null;default:0"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	// CarriedOverExpiresOn is the last day the carried-over days can be
+	// used. CarriedOverExpiredAt is set once the unused ones have been
+	// forfeited.
+	CarriedOverExpiresOn *time.Time `json:"carried_over_expires_on,omitempty" gorm:"type:date"`
+	CarriedOverExpiredAt *time.Time `json:"carried_over_expired_at,omitempty"`
+	// Provisional balances were opened by a request filed before the
+	// yearly reset opened their year; the reset adopts them and recomputes
+	// their total
+	Provisional bool `json:"provisional" gorm:"not null"`
+	// OpeningDays is the entitlement the balance opened with, before
+	// carried-over days and adjustments; nil for balances opened before it
+	// was recorded
+	OpeningDays *float64 `json:"opening_days,omitempty" gorm:"type:decimal(5,2)"`
+}
+
+// Remaining is what is left of the balance after used and pending days.
+// The remaining_days column is generated from the same formula and never
+// written; RemainingDays is kept in step with it after every read and
+// save.
+func (b *LeaveBalance) Remaining() float64 {
+	return b.TotalDays - b.UsedDays - b.PendingDays
+}
+
+func (b *LeaveBalance) AfterFind(tx *gorm.DB) error {
+	b.RemainingDays = b.Remaining()
+	return nil
+}
+
+func (b *LeaveBalance) AfterSave(tx *gorm.DB) error {
+	b.RemainingDays = b.Remaining()
+	return nil
+}
+
+// CarriedOverRemaining is the part of the carried-over days still unused.
+// Leave taken is charged to carried-over days first, and nothing is left
+// of them once they have expired.
+func (b *LeaveBalance) CarriedOverRemaining() float64 {
+	if b.CarriedOverExpiredAt != nil {
+		return 0
+	}
+	return math.Max(0, b.CarriedOverDays-b.UsedDays)
 }
 
 // LeaveRequest represents a leave application
 type LeaveRequest struct {
 	Base
-	OrganizationID uuid.UUID  `json:"organization_id" gorm:"type:uuid;not null" binding:"required"`
-	EmployeeID     uuid.UUID  `json:"employee_id" gorm:"type:uuid;not null" binding:"required"`
-	LeaveTypeID    uuid.UUID  `json:"leave_type_id" gorm:"type:uuid" binding:"required"`
-	StartDate      time.Time  `json:"start_date" gorm:"not null" binding:"required"`
-	EndDate        time.Time  `json:"end_date" gorm:"not null" binding:"required,gtefield=StartDate"`
-	Days           float64    `json:"days" gorm:"type:decimal(5,2);not null"`
-	Status         string     `json:"status" gorm:"default:'pending'" binding:"required,oneof=pending approved rejected cancelled"`
-	Reason         string     `json:"reason" binding:"required,min=5,max=500"`
-	Comments       string     `json:"comments" binding:"max=1000"`
-	ApprovedBy     *uuid.UUID `json:"approved_by,omitempty" gorm:"type:uuid"`
-	ApprovedAt     *time.Time `json:"approved_at,omitempty"`
-	LeaveType      *LeaveType `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+	OrganizationID     uuid.UUID      `json:"organization_id" gorm:"type:uuid;not null" binding:"required"`
+	EmployeeID         uuid.UUID      `json:"employee_id" gorm:"type:uuid;not null" binding:"required"`
+	LeaveTypeID        uuid.UUID      `json:"leave_type_id" gorm:"type:uuid" binding:"required"`
+	StartDate          time.Time      `json:"start_date" gorm:"not null" binding:"required"`
+	EndDate            time.Time      `json:"end_date" gorm:"not null" binding:"required,gtefield=StartDate"`
+	StartHalf          string         `json:"start_half,omitempty" gorm:"not null;default:''"`
+	EndHalf            string         `json:"end_half,omitempty" gorm:"not null;default:''"`
+	Days               float64        `json:"days" gorm:"type:decimal(5,2);not null"`
+	RawDays            float64        `json:"raw_days" gorm:"type:decimal(5,2);not null"`
+	Status             string         `json:"status" gorm:"default:'pending'" binding:"required,oneof=pending approved rejected cancelled cancellation_requested"`
+	Reason             string         `json:"reason" binding:"required,min=5,max=500"`
+	Comments           string         `json:"comments" binding:"max=1000"`
+	AssignedApproverID *uuid.UUID     `json:"assigned_approver_id,omitempty" gorm:"type:uuid"`
+	Backdated          bool           `json:"backdated"`
+	ApprovedBy         *uuid.UUID     `json:"approved_by,omitempty" gorm:"type:uuid"`
+	ApprovedAt         *time.Time     `json:"approved_at,omitempty"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	LeaveType          *LeaveType     `json:"leave_type,omitempty" gorm:"foreignKey:LeaveTypeID"`
+	// ProbationOverridden records that HR or an admin let the request
+	// through although its leave type is blocked during probation
+	ProbationOverridden bool `json:"probation_overridden" gorm:"not null;default:false"`
+	// Hours and the optional HH:MM StartTime describe leave of hourly
+	// types, whose Days and RawDays are also counted in hours
+	Hours     float64 `json:"hours,omitempty" gorm:"type:decimal(5,2);not null;default:0"`
+	StartTime string  `json:"start_time,omitempty" gorm:"type:varchar(5);not null;default:''"`
+	// SandwichedDays are the weekends and holidays charged under the
+	// organization's sandwich policy; RawDays includes them
+	SandwichedDays float64 `json:"sandwiched_days" gorm:"type:decimal(5,2);not null;default:0"`
+	// WorkingDays is RawDays less SandwichedDays
+	WorkingDays float64 `json:"working_days" gorm:"-"`
+	// Breakdown lists the days the request covers, oldest first: its
+	// working days and any sandwiched days
+	Breakdown []LeaveRequestDay `json:"breakdown,omitempty" gorm:"foreignKey:LeaveRequestID"`
+	// Balance is the employee balance after creation, set only in create responses
+	Balance *LeaveBalance `json:"balance,omitempty" gorm:"-"`
+	// EstimatedDecisionBy is when a pending request is likely to be decided,
+	// set only in create and detail responses
+	EstimatedDecisionBy *time.Time `json:"estimated_decision_by,omitempty" gorm:"-"`
+	// HasConflicts is set on requests saved over their department's
+	// concurrent absence limit, for the approver to weigh
+	HasConflicts bool `json:"has_conflicts"`
+	// AbsenceConflicts lists the days over the limit, set only in create responses
+	AbsenceConflicts []AbsenceConflict `json:"absence_conflicts,omitempty" gorm:"-"`
+	// ExternalReferences link the request to cases in other systems
+	ExternalReferences ExternalReferences `json:"external_references" gorm:"type:jsonb;not null;default:'[]'"`
+	// Warnings are non-blocking notes on the last write, set only in write responses
+	Warnings []string `json:"warnings,omitempty" gorm:"-"`
+	// SkippedValidations are the checks the last write went through without
+	// because the organization service was down, set only in write responses
+	SkippedValidations []SkippedValidation `json:"skipped_validations,omitempty" gorm:"-"`
+	// Approvals are the sign-offs collected so far under the quorum strategy
+	Approvals []LeaveRequestApproval `json:"approvals,omitempty" gorm:"foreignKey:LeaveRequestID"`
 }
 
 // LeaveRequestHistory tracks leave request status changes
@@ -64,25 +244,65 @@ type LeaveRequestHistory struct {
 	PerformedBy    uuid.UUID `json:"performed_by" gorm:"type:uuid;not null"`
 }
 
-// Holiday represents company holidays
+func (LeaveRequestHistory) TableName() string {
+	return "leave_request_history"
+}
+
+// LeaveRequestDetail is a leave request with its status history, newest first
+type LeaveRequestDetail struct {
+	*LeaveRequest
+	History []LeaveRequestHistory `json:"history"`
+}
+
+// Holiday represents company holidays. Location limits it to the
+// employees of one office or region, matched against the location the
+// organization service reports for them; without it the holiday applies
+// to the whole organization.
 type Holiday struct {
 	Base
 	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null"`
 	Name           string    `json:"name" gorm:"not null"`
 	Date           time.Time `json:"date" gorm:"not null"`
 	Type           string    `json:"type" gorm:"not null"` // public, company, optional
+	Location       *string   `json:"location,omitempty" gorm:"size:100"`
+	// Recalculation lists the leave requests recounted when the holiday was
+	// added or changed
+	Recalculation *HolidayRecalculation `json:"recalculation,omitempty" gorm:"-"`
 }
 
 // Request/Response types
 type CreateLeaveTypeRequest struct {
-	Name              string `json:"name" binding:"required"`
-	Description       string `json:"description"`
-	Color             string `json:"color" binding:"required"`
-	DefaultDays       int    `json:"default_days" binding:"required"`
-	IsPaid            bool   `json:"is_paid"`
-	RequiresApproval  bool   `json:"requires_approval"`
-	MinDaysNotice     int    `json:"min_days_notice"`
-	MaxDaysPerRequest int    `json:"max_days_per_request"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Color       string `json:"color" binding:"required"`
+	DefaultDays int    `json:"default_days" binding:"required"`
+	IsPaid      bool   `json:"is_paid"`
+	// RequiresApproval defaults to true when omitted
+	RequiresApproval     *bool      `json:"requires_approval"`
+	MinDaysNotice        int        `json:"min_days_notice"`
+	MaxDaysPerRequest    int        `json:"max_days_per_request"`
+	Rounding             string     `json:"rounding" binding:"omitempty,oneof=none half_up full_up"`
+	AllowNegativeBalance bool       `json:"allow_negative_balance"`
+	ApprovalStrategy     string     `json:"approval_strategy" binding:"omitempty,oneof=direct_manager role_based round_robin quorum"`
+	ApproverRole         string     `json:"approver_role"`
+	ApproverPoolID       *uuid.UUID `json:"approver_pool_id"`
+	AllowBackdated       bool       `json:"allow_backdated"`
+	Unit                 string     `json:"unit" binding:"omitempty,oneof=days hours"`
+	// TracksBalance defaults to true when omitted
+	TracksBalance   *bool            `json:"tracks_balance"`
+	MaxNegativeDays *float64         `json:"max_negative_days"`
+	MinTenureDays   int              `json:"min_tenure_days" binding:"min=0"`
+	Eligibility     *EligibilityRule `json:"eligibility"`
+	// QuorumSize defaults to 1
+	QuorumSize            int      `json:"quorum_size" binding:"min=0"`
+	MaxCarryOverDays      float64  `json:"max_carry_over_days" binding:"min=0"`
+	CarryOverExpiryMonths int      `json:"carry_over_expiry_months" binding:"min=0,max=12"`
+	AccrualFrequency      string   `json:"accrual_frequency" binding:"omitempty,oneof=none monthly quarterly"`
+	AccrualRate           float64  `json:"accrual_rate" binding:"min=0"`
+	MaxBalanceDays        *float64 `json:"max_balance_days"`
+	AbsenceMonitored      bool     `json:"absence_monitored"`
+	// BlockedDuringProbation refuses the type's leave during probation
+	BlockedDuringProbation bool `json:"blocked_during_probation"`
 }
 
 type ListLeaveTypesParams struct {
@@ -91,36 +311,168 @@ type ListLeaveTypesParams struct {
 	Name             string
 	IsPaid           *bool
 	RequiresApproval *bool
+	// EmployeeID keeps only the types the employee is eligible for;
+	// AuthToken is used to look the employee up
+	EmployeeID *uuid.UUID
+	AuthToken  string
+}
+
+// ListLeaveBalancesParams filters an organization's balances for one year
+type ListLeaveBalancesParams struct {
+	Page        int
+	PageSize    int
+	Year        int
+	LeaveTypeID *uuid.UUID
+	EmployeeID  *uuid.UUID
+}
+
+type ListLeaveRequestsParams struct {
+	Page           int
+	PageSize       int
+	Status         string
+	EmployeeID     *uuid.UUID
+	LeaveTypeID    *uuid.UUID
+	From           *time.Time
+	To             *time.Time
+	IncludeDeleted bool
+	// Backdated filters on the backdated flag when set
+	Backdated *bool
+	// ExternalRef matches requests carrying the reference, narrowed to
+	// ExternalSystem when that is set
+	ExternalRef    string
+	ExternalSystem string
+}
+
+// DefaultChangesLimit and MaxChangesLimit bound a page of a change feed
+const (
+	DefaultChangesLimit = 100
+	MaxChangesLimit     = 1000
+)
+
+// ChangesParams is a watermark for incremental reads. A row is returned
+// when its updated_at is strictly greater than Since, or equal to Since
+// with an id greater than SinceID.
+type ChangesParams struct {
+	Since   time.Time
+	SinceID uuid.UUID
+	Limit   int
+	// EmployeeID narrows the feed to one employee when set
+	EmployeeID *uuid.UUID
 }
 
 type CreateLeaveRequestRequest struct {
-	EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
+	EmployeeID  uuid.UUID `json:"employee_id" binding:"required"`
 	LeaveTypeID uuid.UUID `json:"leave_type_id" binding:"required"`
 	StartDate   time.Time `json:"start_date" binding:"required"`
-	EndDate     time.Time `json:"end_date" binding:"required"`
-	TotalDays   float64   `json:"total_days" binding:"required"`
+	EndDate     time.Time `json:"end_date"`
+	StartHalf   string    `json:"start_half" binding:"omitempty,oneof=pm"`
+	EndHalf     string    `json:"end_half" binding:"omitempty,oneof=am"`
+	TotalDays   float64   `json:"total_days" binding:"omitempty,gt=0"`
 	Status      string    `json:"status" binding:"required,oneof=pending approved rejected cancelled"`
 	Reason      string    `json:"reason" binding:"required"`
 	Comment     string    `json:"comment"`
+	// Hours and StartTime apply to hourly leave types, which take a single
+	// date; EndDate may be left out for them
+	Hours     float64 `json:"hours" binding:"omitempty,gt=0,max=24"`
+	StartTime string  `json:"start_time"`
+	// BypassNotice skips the leave type's notice period; only managers and admins may set it
+	BypassNotice bool `json:"bypass_notice"`
+	// OverrideProbation lets leave of a type blocked during probation
+	// through; only HR and admins may set it
+	OverrideProbation bool `json:"override_probation"`
+	// ExternalReferences link the request to cases in other systems
+	ExternalReferences []ExternalReference `json:"external_references" binding:"omitempty,max=5,dive"`
+	// AuthToken is the caller's credential, forwarded to the organization service
+	AuthToken string `json:"-"`
+	// CallerRole is the authenticated caller's role
+	CallerRole string `json:"-"`
+}
+
+type EditLeaveRequestRequest struct {
+	LeaveTypeID uuid.UUID `json:"leave_type_id" binding:"required"`
+	StartDate   time.Time `json:"start_date" binding:"required"`
+	EndDate     time.Time `json:"end_date"`
+	StartHalf   string    `json:"start_half" binding:"omitempty,oneof=pm"`
+	EndHalf     string    `json:"end_half" binding:"omitempty,oneof=am"`
+	Hours       float64   `json:"hours" binding:"omitempty,gt=0,max=24"`
+	StartTime   string    `json:"start_time"`
+	Reason      string    `json:"reason" binding:"required,min=5,max=500"`
+	// BypassNotice skips the leave type's notice period; only managers and admins may set it
+	BypassNotice bool `json:"bypass_notice"`
+	// OverrideProbation lets leave of a type blocked during probation
+	// through; only HR and admins may set it
+	OverrideProbation bool `json:"override_probation"`
+	// AuthToken is the caller's credential, forwarded to the organization service
+	AuthToken string `json:"-"`
+	// CallerRole is the authenticated caller's role
+	CallerRole string `json:"-"`
+}
+
+type LeaveRequestActionRequest struct {
+	Comments string `json:"comments" binding:"max=1000"`
 }
 
 type UpdateLeaveRequestRequest struct {
-	Status   string `json:"status" binding:"required,oneof=approved rejected cancelled"`
+	Status   string `json:"status" binding:"omitempty,oneof=approved rejected cancelled"`
 	Comments string `json:"comments"`
 }
 
 type CreateHolidayRequest struct {
-	Name string    `json:"name" binding:"required"`
-	Date time.Time `json:"date" binding:"required"`
-	Type string    `json:"type" binding:"required,oneof=public company optional"`
+	Name     string    `json:"name" binding:"required,max=100"`
+	Date     time.Time `json:"date" binding:"required"`
+	Type     string    `json:"type" binding:"required,oneof=public company optional"`
+	Location string    `json:"location" binding:"max=100"`
+}
+
+// UpdateHolidayRequest changes the fields that are set. An empty Location
+// makes the holiday organization-wide.
+type UpdateHolidayRequest struct {
+	Name     *string    `json:"name" binding:"omitempty,min=1,max=100"`
+	Date     *time.Time `json:"date"`
+	Type     *string    `json:"type" binding:"omitempty,oneof=public company optional"`
+	Location *string    `json:"location" binding:"omitempty,max=100"`
+}
+
+// ListHolidaysParams bounds listed holidays by date, inclusive; Year
+// lists a whole calendar year. Location keeps the holidays observed
+// there, organization-wide ones included.
+type ListHolidaysParams struct {
+	From     *time.Time
+	To       *time.Time
+	Year     int
+	Location string
 }
 
+// LeaveBalanceResponse reports a balance as stored. RemainingDays is
+// negative when leave was borrowed against a type that allows it. History
+// holds the latest adjustments, newest first, when requested.
 type LeaveBalanceResponse struct {
-	LeaveType     string  `json:"leave_type"`
-	TotalDays     float64 `json:"total_days"`
-	UsedDays      float64 `json:"used_days"`
-	PendingDays   float64 `json:"pending_days"`
-	RemainingDays float64 `json:"remaining_days"`
+	ID            uuid.UUID `json:"id"`
+	LeaveTypeID   uuid.UUID `json:"leave_type_id"`
+	LeaveType     string    `json:"leave_type"`
+	Unit          string    `json:"unit"`
+	Year          int       `json:"year"`
+	TotalDays     float64   `json:"total_days"`
+	UsedDays      float64   `json:"used_days"`
+	PendingDays   float64   `json:"pending_days"`
+	RemainingDays float64   `json:"remaining_days"`
+	// ScheduledDays adds up the approved adjustments still waiting for
+	// their effective date and ProjectedRemainingDays is what remains once
+	// they apply
+	ScheduledDays          float64                  `json:"scheduled_days"`
+	ProjectedRemainingDays float64                  `json:"projected_remaining_days"`
+	History                []LeaveBalanceAdjustment `json:"history,omitempty"`
+	// CarriedOverDays is what is left of the days carried over from the
+	// year before, usable until CarriedOverExpiresOn
+	CarriedOverDays      float64    `json:"carried_over_days"`
+	CarriedOverExpiresOn *time.Time `json:"carried_over_expires_on,omitempty"`
+	Provisional          bool       `json:"provisional"`
+	// MaxBalanceDays is the leave type's bank cap. AtCap is set once the
+	// balance banks that much, and ForfeitedAccrualPerMonth is how much
+	// accrual a month the cap then takes away.
+	MaxBalanceDays           *float64 `json:"max_balance_days,omitempty"`
+	AtCap                    bool     `json:"at_cap"`
+	ForfeitedAccrualPerMonth float64  `json:"forfeited_accrual_per_month"`
 }
 
 // Constants
@@ -129,20 +481,71 @@ const (
 	LeaveStatusApproved  = "approved"
 	LeaveStatusRejected  = "rejected"
 	LeaveStatusCancelled = "cancelled"
+	// LeaveStatusCancellationRequested is approved leave whose late
+	// cancellation awaits sign-off; its days stay used until then
+	LeaveStatusCancellationRequested = "cancellation_requested"
+
+	LeaveActionUpdate  = "update"
+	LeaveActionApprove = "approve"
+	LeaveActionReject  = "reject"
+	LeaveActionCancel  = "cancel"
+	LeaveActionDelete  = "delete"
+	LeaveActionRestore = "restore"
+	LeaveActionReopen  = "reopen"
+	// LeaveActionAutoApprove records requests approved on creation because
+	// their leave type does not require approval
+	LeaveActionAutoApprove = "auto-approved"
+	// LeaveActionPartialApprove records an approval that did not yet meet
+	// the leave type's quorum; the request stays pending
+	LeaveActionPartialApprove = "partial_approve"
+
+	LeaveActionRequestCancellation = "request_cancellation"
+	LeaveActionApproveCancellation = "approve_cancellation"
+	LeaveActionDenyCancellation    = "deny_cancellation"
+
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleHR      = "hr"
 
 	HolidayTypePublic   = "public"
 	HolidayTypeCompany  = "company"
 	HolidayTypeOptional = "optional"
 )
 
+// SystemUserID stands in for a user on actions the service takes itself,
+// such as auto-approval
+var SystemUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// ReopenWindowDays is how long after being rejected or cancelled a request
+// can still be reopened
+const ReopenWindowDays = 30
+
+// ActiveLeaveStatuses are the statuses whose days count against a balance
+var ActiveLeaveStatuses = []string{LeaveStatusPending, LeaveStatusApproved, LeaveStatusCancellationRequested}
+
 // GORM Hooks
 func (l *LeaveRequest) BeforeCreate(tx *gorm.DB) error {
 	if l.StartDate.After(l.EndDate) {
 		return errors.New("start date must be before end date")
 	}
 
-	// Calculate days excluding weekends
-	l.Days = calculateWorkingDays(l.StartDate, l.EndDate)
+	// Calculate days excluding weekends unless the service already did,
+	// in which case Days also excludes holidays and carries the leave
+	// type's rounding
+	if l.Breakdown == nil {
+		l.Breakdown = LeaveDayBreakdown(l.StartDate, l.EndDate, l.StartHalf, l.EndHalf, nil)
+	}
+	if l.RawDays == 0 {
+		l.RawDays = CalculateLeaveDays(l.StartDate, l.EndDate, l.StartHalf, l.EndHalf, nil)
+	}
+	if l.Days == 0 {
+		l.Days = l.RawDays
+	}
+	return nil
+}
+
+func (l *LeaveRequest) AfterFind(tx *gorm.DB) error {
+	l.WorkingDays = l.RawDays - l.SandwichedDays
 	return nil
 }
 
@@ -154,22 +557,64 @@ func (l *LeaveRequest) BeforeUpdate(tx *gorm.DB) error {
 }
 
 // Business Logic Methods
-func (l *LeaveRequest) CanCancel() bool {
+
+// CanCancel reports whether the request is pending, or approved leave that
+// starts after today, the date in the organization's time zone
+func (l *LeaveRequest) CanCancel(today time.Time) bool {
 	return l.Status == LeaveStatusPending ||
-		(l.Status == LeaveStatusApproved && l.StartDate.After(time.Now()))
+		(l.Status == LeaveStatusApproved && TruncateToDate(l.StartDate).After(TruncateToDate(today)))
+}
+
+// CanDecideCancellation reports whether a late cancellation awaits sign-off
+func (l *LeaveRequest) CanDecideCancellation() bool {
+	return l.Status == LeaveStatusCancellationRequested
+}
+
+// InsideCancellationWindow reports whether approved leave starts within
+// freeDays calendar days of now, so cancelling it needs sign-off
+func (l *LeaveRequest) InsideCancellationWindow(now time.Time, freeDays int) bool {
+	return TruncateToDate(l.StartDate).Before(TruncateToDate(now).AddDate(0, 0, freeDays))
 }
 
 func (l *LeaveRequest) CanApprove() bool {
 	return l.Status == LeaveStatusPending
 }
 
+// EarliestStartDate is the first date a request submitted at now may start
+// on while respecting the leave type's notice period
+func (t *LeaveType) EarliestStartDate(now time.Time) time.Time {
+	return TruncateToDate(now).AddDate(0, 0, t.MinDaysNotice)
+}
+
+func (l *LeaveRequest) CanEdit() bool {
+	return l.Status == LeaveStatusPending
+}
+
+// CanReopen reports whether the request ended in a status it can be
+// brought back to pending from
+func (l *LeaveRequest) CanReopen() bool {
+	return l.Status == LeaveStatusRejected || l.Status == LeaveStatusCancelled
+}
+
+// CanDelete reports whether the request no longer affects any balance
+func (l *LeaveRequest) CanDelete() bool {
+	return l.Status == LeaveStatusRejected || l.Status == LeaveStatusCancelled
+}
+
 // Helper functions
-func calculateWorkingDays(start, end time.Time) float64 {
+
+// CalculateWorkingDays counts the days between start and end inclusive that
+// are neither weekends nor one of the given holidays. Only the calendar date
+// of start and end matters. This is the number charged to the balance and
+// checked against a leave type's MaxDaysPerRequest.
+func CalculateWorkingDays(start, end time.Time, holidays []Holiday) float64 {
+	closed := holidayDates(holidays)
 	var days float64
-	current := start
+	current := TruncateToDate(start)
+	last := TruncateToDate(end)
 
-	for current.Before(end) || current.Equal(end) {
-		if current.Weekday() != time.Saturday && current.Weekday() != time.Sunday {
+	for !current.After(last) {
+		if isWorkingDay(current, closed) {
 			days++
 		}
 		current = current.AddDate(0, 0, 1)
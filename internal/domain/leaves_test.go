@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaveRequestCanCancel(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC) }
+	// today as resolved for an organization ahead of UTC, late in its day
+	today := time.Date(2026, 3, 10, 23, 0, 0, 0, time.FixedZone("+0530", 5*3600+1800))
+
+	tests := []struct {
+		name   string
+		status string
+		start  time.Time
+		want   bool
+	}{
+		{name: "pending in the past", status: LeaveStatusPending, start: date(1), want: true},
+		{name: "approved tomorrow", status: LeaveStatusApproved, start: date(11), want: true},
+		{name: "approved today", status: LeaveStatusApproved, start: date(10), want: false},
+		{name: "approved yesterday", status: LeaveStatusApproved, start: date(9), want: false},
+		{name: "rejected tomorrow", status: LeaveStatusRejected, start: date(11), want: false},
+		{name: "cancelled", status: LeaveStatusCancelled, start: date(20), want: false},
+		{name: "cancellation requested", status: LeaveStatusCancellationRequested, start: date(20), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &LeaveRequest{Status: tt.status, StartDate: tt.start}
+			if got := request.CanCancel(today); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaveRequestInsideCancellationWindow(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		freeDays int
+		want     bool
+	}{
+		{name: "no free days", start: today.AddDate(0, 0, 1), freeDays: 0, want: false},
+		{name: "starts inside the window", start: today.AddDate(0, 0, 2), freeDays: 3, want: true},
+		{name: "starts the day after the window", start: today.AddDate(0, 0, 3), freeDays: 3, want: false},
+		{name: "starts after the window", start: today.AddDate(0, 0, 10), freeDays: 3, want: false},
+		{name: "time of day is ignored", start: today.AddDate(0, 0, 2).Add(23 * time.Hour), freeDays: 3, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &LeaveRequest{Status: LeaveStatusApproved, StartDate: tt.start}
+			if got := request.InsideCancellationWindow(today, tt.freeDays); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaveBalanceRemaining(t *testing.T) {
+	balance := &LeaveBalance{TotalDays: 20, UsedDays: 7.5, PendingDays: 3, RemainingDays: 20}
+	if got := balance.Remaining(); got != 9.5 {
+		t.Errorf("Remaining() = %v, want 9.5", got)
+	}
+
+	for name, hook := range map[string]func(*LeaveBalance) error{
+		"after find": func(b *LeaveBalance) error { return b.AfterFind(nil) },
+		"after save": func(b *LeaveBalance) error { return b.AfterSave(nil) },
+	} {
+		b := *balance
+		if err := hook(&b); err != nil || b.RemainingDays != 9.5 {
+			t.Errorf("%s: remaining_days = %v (%v), want 9.5", name, b.RemainingDays, err)
+		}
+	}
+}
+
+func TestLeaveTypeCarryOverExpiry(t *testing.T) {
+	tests := []struct {
+		months int
+		want   string
+	}{
+		{months: 0},
+		{months: 1, want: "2027-01-31"},
+		{months: 2, want: "2027-02-28"},
+		{months: 3, want: "2027-03-31"},
+		{months: 12, want: "2027-12-31"},
+	}
+	for _, tt := range tests {
+		leaveType := &LeaveType{CarryOverExpiryMonths: tt.months}
+		got := leaveType.CarryOverExpiry(2027)
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("%d months: expires on %v, want never", tt.months, got)
+			}
+			continue
+		}
+		if got == nil || got.Format("2006-01-02") != tt.want {
+			t.Errorf("%d months: expires on %v, want %s", tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestLeaveBalanceCarriedOverRemaining(t *testing.T) {
+	expired := time.Now()
+	tests := []struct {
+		name    string
+		balance LeaveBalance
+		want    float64
+	}{
+		{name: "unused", balance: LeaveBalance{CarriedOverDays: 5}, want: 5},
+		// Leave taken is charged to the carried-over days first
+		{name: "partly used", balance: LeaveBalance{CarriedOverDays: 5, UsedDays: 1.5, PendingDays: 2}, want: 3.5},
+		{name: "used up", balance: LeaveBalance{CarriedOverDays: 5, UsedDays: 8}},
+		{name: "expired", balance: LeaveBalance{CarriedOverDays: 5, CarriedOverExpiredAt: &expired}},
+		{name: "nothing carried", balance: LeaveBalance{UsedDays: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.balance.CarriedOverRemaining(); got != tt.want {
+				t.Errorf("CarriedOverRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
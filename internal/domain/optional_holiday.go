@@ -0,0 +1,50 @@
+package domain
+
+import "github.com/google/uuid"
+
+// DefaultOptionalHolidayQuota is how many optional holidays an employee
+// may take a year until the organization sets its own quota
+const DefaultOptionalHolidayQuota = 2
+
+// OptionalHolidayClaim is an employee's election of an optional holiday.
+// Employees only get the optional holidays they claimed off, like a day of
+// approved leave that is not charged to any balance; the others are
+// working days for them.
+type OptionalHolidayClaim struct {
+	Base
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;not null"`
+	EmployeeID     uuid.UUID `json:"employee_id" gorm:"type:uuid;not null"`
+	HolidayID      uuid.UUID `json:"holiday_id" gorm:"type:uuid;not null"`
+	ClaimedBy      uuid.UUID `json:"claimed_by" gorm:"type:uuid;not null"`
+	Holiday        *Holiday  `json:"holiday,omitempty" gorm:"foreignKey:HolidayID"`
+}
+
+type ClaimOptionalHolidayRequest struct {
+	HolidayID uuid.UUID `json:"holiday_id" binding:"required"`
+}
+
+// OptionalHolidayClaims is an employee's use of the optional holiday quota
+// in a year. Quota 0 means no limit, leaving Remaining unset.
+type OptionalHolidayClaims struct {
+	EmployeeID uuid.UUID              `json:"employee_id"`
+	Year       int                    `json:"year"`
+	Quota      int                    `json:"quota"`
+	Claimed    int                    `json:"claimed"`
+	Remaining  *int                   `json:"remaining,omitempty"`
+	Claims     []OptionalHolidayClaim `json:"claims"`
+	// Available lists the year's optional holidays at the employee's
+	// location they have not claimed
+	Available []Holiday `json:"available"`
+}
+
+// ClaimedOptionalHolidays keeps the holidays the employee observes: all
+// but the optional ones they did not claim
+func ClaimedOptionalHolidays(holidays []Holiday, claimed map[uuid.UUID]bool) []Holiday {
+	observed := make([]Holiday, 0, len(holidays))
+	for _, h := range holidays {
+		if h.Type != HolidayTypeOptional || claimed[h.ID] {
+			observed = append(observed, h)
+		}
+	}
+	return observed
+}
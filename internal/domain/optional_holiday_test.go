@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestClaimedOptionalHolidays(t *testing.T) {
+	claimed, unclaimed := uuid.New(), uuid.New()
+	holidays := []Holiday{
+		{Base: Base{ID: uuid.New()}, Name: "Vesak", Type: HolidayTypePublic},
+		{Base: Base{ID: claimed}, Name: "Deepavali", Type: HolidayTypeOptional},
+		{Base: Base{ID: unclaimed}, Name: "Good Friday", Type: HolidayTypeOptional},
+		{Base: Base{ID: uuid.New()}, Name: "Founders' Day", Type: HolidayTypeCompany},
+	}
+
+	observed := ClaimedOptionalHolidays(holidays, map[uuid.UUID]bool{claimed: true})
+	var names []string
+	for _, h := range observed {
+		names = append(names, h.Name)
+	}
+	if len(observed) != 3 || observed[0].Name != "Vesak" || observed[1].Name != "Deepavali" || observed[2].Name != "Founders' Day" {
+		t.Errorf("observed %v, want all but the unclaimed optional holiday", names)
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// DefaultProbationMonths is the probation of organizations that have not
+// set their own
+const DefaultProbationMonths = 3
+
+// ProbationEnd is the first day after the probation of an employee who
+// started on startDate, or the zero time when the organization has no
+// probation
+func (s *OrganizationSettings) ProbationEnd(startDate time.Time) time.Time {
+	if s.ProbationMonths <= 0 {
+		return time.Time{}
+	}
+	return TruncateToDate(startDate).AddDate(0, s.ProbationMonths, 0)
+}
+
+// ProbationConflict details a request refused for falling within
+// probation with the earliest date leave of its type may start on
+type ProbationConflict struct {
+	EarliestStartDate time.Time `json:"earliest_start_date"`
+}
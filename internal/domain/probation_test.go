@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbationEnd(t *testing.T) {
+	started := time.Date(2026, 1, 31, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		months int
+		want   time.Time
+	}{
+		{name: "default", months: DefaultProbationMonths, want: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		// Normalized past the end of a short month, like AddDate
+		{name: "one month", months: 1, want: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "none", months: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &OrganizationSettings{ProbationMonths: tt.months}
+			if got := settings.ProbationEnd(started); !got.Equal(tt.want) {
+				t.Errorf("ProbationEnd() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
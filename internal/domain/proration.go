@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What a new hire's first-year entitlement is pro-rated by: the months
+// left in the leave year, counting the month they join, or the days left,
+// counting their first day
+const (
+	ProrationBasisMonths = "months"
+	ProrationBasisDays   = "days"
+)
+
+// How pro-rated entitlements are rounded: to the nearest half day, or up
+// to a whole day
+const (
+	ProrationRoundingNearestHalf = "nearest_half"
+	ProrationRoundingUp          = "up"
+)
+
+// InitializeBalancesRequest opens a new hire's balances. HireDate defaults
+// to the start date the organization service reports for the employee.
+type InitializeBalancesRequest struct {
+	HireDate *time.Time `json:"hire_date"`
+	// AuthToken is used to look the employee up
+	AuthToken string `json:"-"`
+}
+
+// Proration is the share of a leave year left after a hire date
+type Proration struct {
+	Basis     string  `json:"basis"`
+	Remaining int     `json:"remaining"`
+	Total     int     `json:"total"`
+	Fraction  float64 `json:"fraction"`
+}
+
+// ProrateYear returns the share of year left from hireDate on. Hires
+// before the year get all of it.
+func ProrateYear(hireDate time.Time, year int, basis string) Proration {
+	hireDate = TruncateToDate(hireDate)
+	p := Proration{Basis: basis, Total: 12}
+	if basis == ProrationBasisDays {
+		first := time.Date(year, time.January, 1, 0, 0, 0, 0, hireDate.Location())
+		next := first.AddDate(1, 0, 0)
+		p.Total = int(next.Sub(first).Hours() / 24)
+		p.Remaining = p.Total
+		if hireDate.After(first) {
+			p.Remaining = int(math.Round(next.Sub(hireDate).Hours() / 24))
+		}
+	} else {
+		p.Remaining = p.Total
+		if hireDate.Year() == year {
+			p.Remaining = 12 - int(hireDate.Month()) + 1
+		}
+	}
+	p.Fraction = float64(p.Remaining) / float64(p.Total)
+	return p
+}
+
+// RoundProratedDays applies an organization's proration rounding
+func RoundProratedDays(days float64, rounding string) float64 {
+	if rounding == ProrationRoundingUp {
+		return math.Ceil(days)
+	}
+	return math.Round(days*2) / 2
+}
+
+// ProratedBalance is a new hire's balance of one leave type with the math
+// behind its opening total. Created is false when the balance already
+// existed and was returned unchanged.
+type ProratedBalance struct {
+	LeaveTypeID uuid.UUID `json:"leave_type_id"`
+	LeaveType   string    `json:"leave_type"`
+	Unit        string    `json:"unit"`
+	Created     bool      `json:"created"`
+	// DefaultDays is the full-year allowance, ProratedDays its share for
+	// the rest of the year and RoundedDays that share after rounding.
+	// Accruing types open empty and accrue from the hire month on.
+	DefaultDays  float64       `json:"default_days"`
+	ProratedDays float64       `json:"prorated_days"`
+	RoundedDays  float64       `json:"rounded_days"`
+	Calculation  string        `json:"calculation"`
+	Balance      *LeaveBalance `json:"balance"`
+}
+
+// Describe fills in Calculation from the other figures
+func (b *ProratedBalance) Describe(p Proration, rounding string) {
+	b.Calculation = fmt.Sprintf("%.2f days x %d/%d %s = %.2f, rounded %s to %.2f",
+		b.DefaultDays, p.Remaining, p.Total, p.Basis, b.ProratedDays, rounding, b.RoundedDays)
+}
+
+// BalanceInitialization is the outcome of opening a new hire's balances
+type BalanceInitialization struct {
+	EmployeeID uuid.UUID         `json:"employee_id"`
+	HireDate   time.Time         `json:"hire_date"`
+	Year       int               `json:"year"`
+	Rounding   string            `json:"rounding"`
+	Proration  Proration         `json:"proration"`
+	Balances   []ProratedBalance `json:"balances"`
+}
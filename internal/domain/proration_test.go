@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProrateYear(t *testing.T) {
+	tests := []struct {
+		name          string
+		hired         time.Time
+		year          int
+		basis         string
+		wantRemaining int
+		wantTotal     int
+	}{
+		// The month they join counts in full
+		{name: "months from mid-April", hired: time.Date(2027, 4, 10, 15, 0, 0, 0, time.UTC), year: 2027, basis: ProrationBasisMonths, wantRemaining: 9, wantTotal: 12},
+		{name: "months from December", hired: time.Date(2027, 12, 31, 0, 0, 0, 0, time.UTC), year: 2027, basis: ProrationBasisMonths, wantRemaining: 1, wantTotal: 12},
+		{name: "months for a hire the year before", hired: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), year: 2027, basis: ProrationBasisMonths, wantRemaining: 12, wantTotal: 12},
+		// And so does their first day
+		{name: "days from July", hired: time.Date(2027, 7, 2, 0, 0, 0, 0, time.UTC), year: 2027, basis: ProrationBasisDays, wantRemaining: 183, wantTotal: 365},
+		{name: "days from the last day", hired: time.Date(2027, 12, 31, 0, 0, 0, 0, time.UTC), year: 2027, basis: ProrationBasisDays, wantRemaining: 1, wantTotal: 365},
+		{name: "days in a leap year", hired: time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC), year: 2028, basis: ProrationBasisDays, wantRemaining: 366, wantTotal: 366},
+		{name: "days for a hire the year before", hired: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), year: 2027, basis: ProrationBasisDays, wantRemaining: 365, wantTotal: 365},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ProrateYear(tt.hired, tt.year, tt.basis)
+			if p.Basis != tt.basis || p.Remaining != tt.wantRemaining || p.Total != tt.wantTotal {
+				t.Fatalf("prorated %d/%d %s, want %d/%d %s", p.Remaining, p.Total, p.Basis, tt.wantRemaining, tt.wantTotal, tt.basis)
+			}
+			if want := float64(tt.wantRemaining) / float64(tt.wantTotal); p.Fraction != want {
+				t.Errorf("fraction = %v, want %v", p.Fraction, want)
+			}
+		})
+	}
+}
+
+func TestRoundProratedDays(t *testing.T) {
+	tests := []struct {
+		days     float64
+		rounding string
+		want     float64
+	}{
+		{days: 13.2, rounding: ProrationRoundingNearestHalf, want: 13},
+		{days: 13.3, rounding: ProrationRoundingNearestHalf, want: 13.5},
+		{days: 13.75, rounding: ProrationRoundingNearestHalf, want: 14},
+		{days: 13.1, rounding: ProrationRoundingUp, want: 14},
+		{days: 13, rounding: ProrationRoundingUp, want: 13},
+	}
+	for _, tt := range tests {
+		if got := RoundProratedDays(tt.days, tt.rounding); got != tt.want {
+			t.Errorf("RoundProratedDays(%v, %s) = %v, want %v", tt.days, tt.rounding, got, tt.want)
+		}
+	}
+}
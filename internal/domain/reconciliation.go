@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RecalculateBalancesParams scopes a balance recalculation to a year, the
+// current one unless set, and optionally one employee. Without Apply the
+// discrepancies are only reported.
+type RecalculateBalancesParams struct {
+	EmployeeID *uuid.UUID `json:"employee_id,omitempty"`
+	Year       int        `json:"year"`
+	Apply      bool       `json:"apply"`
+}
+
+// BalanceFigures are the three stored buckets of a balance
+type BalanceFigures struct {
+	TotalDays   float64 `json:"total_days"`
+	UsedDays    float64 `json:"used_days"`
+	PendingDays float64 `json:"pending_days"`
+}
+
+// StoredFigures returns the balance's buckets as stored
+func (b *LeaveBalance) StoredFigures() BalanceFigures {
+	return BalanceFigures{TotalDays: b.TotalDays, UsedDays: b.UsedDays, PendingDays: b.PendingDays}
+}
+
+// Matches reports whether the figures agree to the cent
+func (f BalanceFigures) Matches(other BalanceFigures) bool {
+	return math.Abs(f.TotalDays-other.TotalDays) < 0.005 &&
+		math.Abs(f.UsedDays-other.UsedDays) < 0.005 &&
+		math.Abs(f.PendingDays-other.PendingDays) < 0.005
+}
+
+// Describe lists the buckets that differ from expected, e.g.
+// "used 3.00 -> 5.00"
+func (f BalanceFigures) Describe(expected BalanceFigures) string {
+	var changes []string
+	for _, c := range []struct {
+		name             string
+		stored, expected float64
+	}{
+		{"total", f.TotalDays, expected.TotalDays},
+		{"used", f.UsedDays, expected.UsedDays},
+		{"pending", f.PendingDays, expected.PendingDays},
+	} {
+		if math.Abs(c.stored-c.expected) >= 0.005 {
+			changes = append(changes, fmt.Sprintf("%s %.2f -> %.2f", c.name, c.stored, c.expected))
+		}
+	}
+	return strings.Join(changes, ", ")
+}
+
+// BalanceDiscrepancy is a balance whose stored buckets differ from what
+// its source records add up to: used and pending days from its approved
+// and pending requests, the total from its opening entitlement, carried
+// over days and approved adjustments, accruals included. When the fix
+// was applied, AdjustmentID is the correction documenting it.
+type BalanceDiscrepancy struct {
+	LeaveBalanceID uuid.UUID      `json:"leave_balance_id"`
+	EmployeeID     uuid.UUID      `json:"employee_id"`
+	LeaveTypeID    uuid.UUID      `json:"leave_type_id"`
+	Year           int            `json:"year"`
+	Stored         BalanceFigures `json:"stored"`
+	Expected       BalanceFigures `json:"expected"`
+	Changes        string         `json:"changes"`
+	Applied        bool           `json:"applied"`
+	AdjustmentID   *uuid.UUID     `json:"adjustment_id,omitempty"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// BalanceRecalculation is the outcome of recalculating balances. Without
+// Applied, nothing was changed.
+type BalanceRecalculation struct {
+	Year          int                  `json:"year"`
+	Applied       bool                 `json:"applied"`
+	Checked       int                  `json:"checked"`
+	Discrepancies []BalanceDiscrepancy `json:"discrepancies"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "math"
+
+// Rounding modes applied to the working-day count of a leave request
+const (
+	RoundingNone   = "none"
+	RoundingHalfUp = "half_up"
+	RoundingFullUp = "full_up"
+)
+
+// IsValidRounding reports whether mode is a supported rounding mode.
+// An empty mode is treated as RoundingNone.
+func IsValidRounding(mode string) bool {
+	switch mode {
+	case "", RoundingNone, RoundingHalfUp, RoundingFullUp:
+		return true
+	}
+	return false
+}
+
+// RoundDays applies a leave type's rounding mode to a raw day count.
+// It must always be given the raw value so edits never compound rounding.
+func RoundDays(rawDays float64, mode string) float64 {
+	switch mode {
+	case RoundingHalfUp:
+		return math.Ceil(rawDays*2) / 2
+	case RoundingFullUp:
+		return math.Ceil(rawDays)
+	}
+	return rawDays
+}
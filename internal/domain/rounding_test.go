@@ -0,0 +1,54 @@
+package domain
+
+import "testing"
+
+func TestRoundDays(t *testing.T) {
+	tests := []struct {
+		raw  float64
+		mode string
+		want float64
+	}{
+		{raw: 2.5, mode: "", want: 2.5},
+		{raw: 2.5, mode: RoundingNone, want: 2.5},
+		{raw: 2.25, mode: RoundingNone, want: 2.25},
+		{raw: 2.25, mode: RoundingHalfUp, want: 2.5},
+		{raw: 2.5, mode: RoundingHalfUp, want: 2.5},
+		{raw: 2.51, mode: RoundingHalfUp, want: 3},
+		{raw: 3, mode: RoundingHalfUp, want: 3},
+		{raw: 0.1, mode: RoundingHalfUp, want: 0.5},
+		{raw: 2.5, mode: RoundingFullUp, want: 3},
+		{raw: 2.01, mode: RoundingFullUp, want: 3},
+		{raw: 3, mode: RoundingFullUp, want: 3},
+		{raw: 0, mode: RoundingFullUp, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := RoundDays(tt.raw, tt.mode); got != tt.want {
+			t.Errorf("RoundDays(%v, %q) = %v, want %v", tt.raw, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestRoundDaysNeverCompounds(t *testing.T) {
+	for _, mode := range []string{RoundingNone, RoundingHalfUp, RoundingFullUp} {
+		once := RoundDays(2.25, mode)
+		if twice := RoundDays(once, mode); twice != once {
+			t.Errorf("%s: rounding %v again gave %v", mode, once, twice)
+		}
+	}
+}
+
+func TestIsValidRounding(t *testing.T) {
+	for mode, want := range map[string]bool{
+		"":             true,
+		RoundingNone:   true,
+		RoundingHalfUp: true,
+		RoundingFullUp: true,
+		"down":         false,
+		"HALF_UP":      false,
+	} {
+		if got := IsValidRounding(mode); got != want {
+			t.Errorf("IsValidRounding(%q) = %v, want %v", mode, got, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationSettings holds leave policy options for an organization.
+// Organizations without a row use the zero-value defaults.
+type OrganizationSettings struct {
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;primaryKey"`
+	// FreeCancellationDays is how many calendar days before the start an
+	// employee can still cancel approved leave without manager sign-off
+	FreeCancellationDays int `json:"free_cancellation_days" gorm:"not null;default:0"`
+	// BackdateWindowDays is how far in the past managers and admins may
+	// start a request on a leave type that does not allow backdating
+	BackdateWindowDays int `json:"backdate_window_days" gorm:"not null;default:90"`
+	// HoursPerDay converts hourly leave to day equivalents
+	HoursPerDay float64 `json:"hours_per_day" gorm:"type:decimal(4,2);not null;default:8"`
+	// SandwichPolicy charges weekends and holidays that fall between two
+	// leave days of the same request
+	SandwichPolicy bool `json:"sandwich_policy" gorm:"not null;default:false"`
+	// MaxConcurrentAbsences is how many employees of one department may be
+	// off on the same day, 0 for no limit. ConcurrentAbsenceMode decides
+	// whether requests beyond it are blocked or only flagged.
+	MaxConcurrentAbsences int       `json:"max_concurrent_absences" gorm:"not null;default:0"`
+	ConcurrentAbsenceMode string    `json:"concurrent_absence_mode" gorm:"type:varchar(10);not null;default:'block'"`
+	CreatedAt             time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt             time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+	// Timezone is the IANA zone "today" is resolved in, e.g. for notice
+	// periods and default report ranges
+	Timezone string `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	// AnomalyMaxMutationDays and AnomalyMaxWritesPerMinute are the balance
+	// anomaly thresholds: the largest single balance change, and the most
+	// balance writes in a minute. AnomalyHoldAdjustments keeps adjustments
+	// pending while an anomaly alert is active.
+	AnomalyMaxMutationDays    float64 `json:"anomaly_max_mutation_days" gorm:"type:decimal(6,2);not null;default:60"`
+	AnomalyMaxWritesPerMinute int     `json:"anomaly_max_writes_per_minute" gorm:"not null;default:500"`
+	AnomalyHoldAdjustments    bool    `json:"anomaly_hold_adjustments" gorm:"not null"`
+	// MaxAdvanceBookingMonths is how far ahead of today leave may start.
+	// Requests in a year whose balances are not open yet book against a
+	// provisional balance that the yearly reset adopts.
+	MaxAdvanceBookingMonths int `json:"max_advance_booking_months" gorm:"not null;default:15"`
+	// ProrationBasis and ProrationRounding decide how a mid-year hire's
+	// first-year entitlement is pro-rated and rounded
+	ProrationBasis    string `json:"proration_basis" gorm:"type:varchar(10);not null;default:'months'"`
+	ProrationRounding string `json:"proration_rounding" gorm:"type:varchar(15);not null;default:'nearest_half'"`
+	// UndoWindowMinutes is how long after submitting employees can undo a
+	// request nobody has acted on, deleting it without a trace; 0 turns
+	// undo off
+	UndoWindowMinutes int `json:"undo_window_minutes" gorm:"not null;default:15"`
+	// CompOffLeaveTypeID is the leave type approved comp-off is credited
+	// to; comp-off cannot be requested until it is set. Credits lapse
+	// CompOffExpiryDays after approval, 0 for never.
+	CompOffLeaveTypeID *uuid.UUID `json:"comp_off_leave_type_id,omitempty" gorm:"type:uuid"`
+	CompOffExpiryDays  int        `json:"comp_off_expiry_days" gorm:"not null;default:0"`
+	// OptionalHolidayQuota is how many optional holidays an employee may
+	// claim a calendar year, 0 for no limit
+	OptionalHolidayQuota int `json:"optional_holiday_quota" gorm:"not null;default:2"`
+	// BradfordBands classify employees by their Bradford score in the
+	// Bradford report
+	BradfordBands BradfordBands `json:"bradford_bands" gorm:"type:jsonb;not null"`
+	// AbsenceAlertDays and AbsenceAlertSpells raise an absence alert for
+	// employees with that many days or spells of absence-monitored leave
+	// in a rolling 12 months; 0 turns a threshold off. HR is notified of
+	// new alerts at AbsenceAlertWebhookURL, when set.
+	AbsenceAlertDays       float64 `json:"absence_alert_days" gorm:"type:decimal(6,2);not null;default:0"`
+	AbsenceAlertSpells     int     `json:"absence_alert_spells" gorm:"not null;default:0"`
+	AbsenceAlertWebhookURL string  `json:"absence_alert_webhook_url" gorm:"not null;default:''"`
+	// ProbationMonths is how long new employees are on probation, during
+	// which leave types blocked during probation cannot be taken; 0 for
+	// no probation
+	ProbationMonths int `json:"probation_months" gorm:"not null;default:3"`
+	// DelegationConfirmDays is how long approvers have to confirm the
+	// delegation proposed for their own approved leave before it is
+	// escalated; 0 never escalates. Proposals and escalations are sent to
+	// DelegationWebhookURL, when set.
+	DelegationConfirmDays int    `json:"delegation_confirm_days" gorm:"not null;default:2"`
+	DelegationWebhookURL  string `json:"delegation_webhook_url" gorm:"not null;default:''"`
+}
+
+type UpdateOrganizationSettingsRequest struct {
+	FreeCancellationDays      *int          `json:"free_cancellation_days" binding:"omitempty,min=0,max=365"`
+	BackdateWindowDays        *int          `json:"backdate_window_days" binding:"omitempty,min=0,max=365"`
+	HoursPerDay               *float64      `json:"hours_per_day" binding:"omitempty,gt=0,max=24"`
+	SandwichPolicy            *bool         `json:"sandwich_policy"`
+	MaxConcurrentAbsences     *int          `json:"max_concurrent_absences" binding:"omitempty,min=0"`
+	ConcurrentAbsenceMode     *string       `json:"concurrent_absence_mode" binding:"omitempty,oneof=block warn"`
+	Timezone                  *string       `json:"timezone" binding:"omitempty,max=64"`
+	AnomalyMaxMutationDays    *float64      `json:"anomaly_max_mutation_days" binding:"omitempty,gt=0"`
+	AnomalyMaxWritesPerMinute *int          `json:"anomaly_max_writes_per_minute" binding:"omitempty,min=1"`
+	AnomalyHoldAdjustments    *bool         `json:"anomaly_hold_adjustments"`
+	MaxAdvanceBookingMonths   *int          `json:"max_advance_booking_months" binding:"omitempty,min=1,max=60"`
+	ProrationBasis            *string       `json:"proration_basis" binding:"omitempty,oneof=months days"`
+	ProrationRounding         *string       `json:"proration_rounding" binding:"omitempty,oneof=nearest_half up"`
+	UndoWindowMinutes         *int          `json:"undo_window_minutes" binding:"omitempty,min=0,max=1440"`
+	CompOffLeaveTypeID        *uuid.UUID    `json:"comp_off_leave_type_id"`
+	CompOffExpiryDays         *int          `json:"comp_off_expiry_days" binding:"omitempty,min=0,max=3650"`
+	OptionalHolidayQuota      *int          `json:"optional_holiday_quota" binding:"omitempty,min=0,max=366"`
+	BradfordBands             BradfordBands `json:"bradford_bands"`
+	AbsenceAlertDays          *float64      `json:"absence_alert_days" binding:"omitempty,min=0,max=366"`
+	AbsenceAlertSpells        *int          `json:"absence_alert_spells" binding:"omitempty,min=0,max=366"`
+	AbsenceAlertWebhookURL    *string       `json:"absence_alert_webhook_url" binding:"omitempty,max=500"`
+	ProbationMonths           *int          `json:"probation_months" binding:"omitempty,min=0,max=24"`
+	DelegationConfirmDays     *int          `json:"delegation_confirm_days" binding:"omitempty,min=0,max=30"`
+	DelegationWebhookURL      *string       `json:"delegation_webhook_url" binding:"omitempty,max=500"`
+}
+
+// DefaultBackdateWindowDays applies to organizations without saved settings
+const DefaultBackdateWindowDays = 90
+
+// DefaultUndoWindowMinutes is long enough to spot a typo in a request
+// just submitted
+const DefaultUndoWindowMinutes = 15
+
+// DefaultMaxAdvanceBookingMonths lets employees book next summer's leave
+// well before the yearly reset
+const DefaultMaxAdvanceBookingMonths = 15
+
+// DefaultOrganizationSettings returns the settings used until an
+// organization saves its own
+func DefaultOrganizationSettings(orgID uuid.UUID) *OrganizationSettings {
+	return &OrganizationSettings{
+		OrganizationID:            orgID,
+		BackdateWindowDays:        DefaultBackdateWindowDays,
+		HoursPerDay:               DefaultHoursPerDay,
+		ConcurrentAbsenceMode:     AbsenceLimitModeBlock,
+		Timezone:                  "UTC",
+		AnomalyMaxMutationDays:    DefaultAnomalyMaxMutationDays,
+		AnomalyMaxWritesPerMinute: DefaultAnomalyMaxWritesPerMinute,
+		AnomalyHoldAdjustments:    true,
+		MaxAdvanceBookingMonths:   DefaultMaxAdvanceBookingMonths,
+		ProrationBasis:            ProrationBasisMonths,
+		ProrationRounding:         ProrationRoundingNearestHalf,
+		UndoWindowMinutes:         DefaultUndoWindowMinutes,
+		OptionalHolidayQuota:      DefaultOptionalHolidayQuota,
+		BradfordBands:             DefaultBradfordBands(),
+		ProbationMonths:           DefaultProbationMonths,
+		DelegationConfirmDays:     DefaultDelegationConfirmDays,
+	}
+}
+
+// Location returns the organization's time zone, UTC when it is unset or
+// unknown
+func (s *OrganizationSettings) Location() *time.Location {
+	return LoadLocation(s.Timezone)
+}
+
+// Today is the current date in the organization's time zone
+func (s *OrganizationSettings) Today() time.Time {
+	return s.DateAt(time.Now())
+}
+
+// DateAt is the date in the organization's time zone at the instant t
+func (s *OrganizationSettings) DateAt(t time.Time) time.Time {
+	return TruncateToDate(t.In(s.Location()))
+}
+
+// LoadLocation returns the named IANA time zone, or UTC when the name is
+// empty or unknown
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrganizationSettingsDateAt(t *testing.T) {
+	// 2026-03-01 22:30 UTC is already 2 March east of UTC and still
+	// 1 March west of it
+	instant := time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		timezone string
+		want     time.Time
+	}{
+		{timezone: "", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{timezone: "UTC", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{timezone: "Asia/Colombo", want: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{timezone: "Pacific/Auckland", want: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{timezone: "America/Los_Angeles", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{timezone: "Not/AZone", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			settings := &OrganizationSettings{Timezone: tt.timezone}
+			if got := settings.DateAt(instant); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
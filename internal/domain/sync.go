@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// syncTokenVersion is bumped whenever the token layout changes so old
+	// tokens fall back to a full resync
+	syncTokenVersion = 1
+
+	// SyncTokenMaxAge is how long a sync token stays usable; older tokens
+	// get a full resync since tombstones may have been missed
+	SyncTokenMaxAge = 30 * 24 * time.Hour
+
+	// SyncPageSize caps the rows returned per entity in one sync call
+	SyncPageSize = 500
+
+	SyncEntityLeaveRequest = "leave_request"
+)
+
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// SyncWatermark is the (updated_at, id) position reached in one entity's
+// change feed
+type SyncWatermark struct {
+	Since   time.Time `json:"s"`
+	SinceID uuid.UUID `json:"i"`
+}
+
+// SyncToken holds a watermark per synced entity. Clients treat its encoded
+// form as opaque.
+type SyncToken struct {
+	Version       int           `json:"v"`
+	IssuedAt      time.Time     `json:"t"`
+	LeaveRequests SyncWatermark `json:"r"`
+	Balances      SyncWatermark `json:"b"`
+	Holidays      SyncWatermark `json:"h"`
+}
+
+// NewSyncToken starts a token issued at now with empty watermarks
+func NewSyncToken(now time.Time) *SyncToken {
+	return &SyncToken{Version: syncTokenVersion, IssuedAt: now}
+}
+
+// Encode returns the opaque string form of the token
+func (t *SyncToken) Encode() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeSyncToken parses a token, rejecting malformed, outdated and expired ones
+func DecodeSyncToken(s string, now time.Time) (*SyncToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidSyncToken
+	}
+
+	var token SyncToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, ErrInvalidSyncToken
+	}
+	if token.Version != syncTokenVersion || now.Sub(token.IssuedAt) > SyncTokenMaxAge {
+		return nil, ErrInvalidSyncToken
+	}
+	return &token, nil
+}
+
+// SyncLeaveRequest is the compact leave request shape sent to mobile clients
+type SyncLeaveRequest struct {
+	ID          uuid.UUID `json:"id"`
+	EmployeeID  uuid.UUID `json:"employee_id"`
+	LeaveTypeID uuid.UUID `json:"leave_type_id"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Days        float64   `json:"days"`
+	Status      string    `json:"status"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewSyncLeaveRequest(l *LeaveRequest) SyncLeaveRequest {
+	return SyncLeaveRequest{
+		ID:          l.ID,
+		EmployeeID:  l.EmployeeID,
+		LeaveTypeID: l.LeaveTypeID,
+		StartDate:   l.StartDate,
+		EndDate:     l.EndDate,
+		Days:        l.Days,
+		Status:      l.Status,
+		UpdatedAt:   l.UpdatedAt,
+	}
+}
+
+// SyncTombstone marks a record the client should drop
+type SyncTombstone struct {
+	Entity    string    `json:"entity"`
+	ID        uuid.UUID `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncResponse carries everything that changed for the caller since their
+// token. PendingApprovals is always the full current inbox rather than a delta.
+type SyncResponse struct {
+	Token            string             `json:"token"`
+	FullResync       bool               `json:"full_resync"`
+	HasMore          bool               `json:"has_more"`
+	LeaveRequests    []SyncLeaveRequest `json:"leave_requests"`
+	Balances         []LeaveBalance     `json:"balances"`
+	Holidays         []Holiday          `json:"holidays"`
+	PendingApprovals []SyncLeaveRequest `json:"pending_approvals,omitempty"`
+	Tombstones       []SyncTombstone    `json:"tombstones"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSyncTokenRoundTrip(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	token := NewSyncToken(now)
+	token.LeaveRequests = SyncWatermark{Since: now.Add(-time.Hour), SinceID: uuid.New()}
+	token.Holidays = SyncWatermark{Since: now.Add(-48 * time.Hour), SinceID: uuid.New()}
+
+	got, err := DecodeSyncToken(token.Encode(), now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.LeaveRequests.Since.Equal(token.LeaveRequests.Since) || got.LeaveRequests.SinceID != token.LeaveRequests.SinceID {
+		t.Errorf("leave request watermark: got %+v, want %+v", got.LeaveRequests, token.LeaveRequests)
+	}
+	if !got.Holidays.Since.Equal(token.Holidays.Since) || got.Holidays.SinceID != token.Holidays.SinceID {
+		t.Errorf("holiday watermark: got %+v, want %+v", got.Holidays, token.Holidays)
+	}
+	if got.Balances.SinceID != uuid.Nil {
+		t.Errorf("balance watermark: got %+v, want empty", got.Balances)
+	}
+}
+
+func TestDecodeSyncTokenRejects(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	outdated := NewSyncToken(now)
+	outdated.Version = syncTokenVersion + 1
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not base64", token: "!!!"},
+		{name: "not json", token: "bm90LWpzb24"},
+		{name: "other version", token: outdated.Encode()},
+		{name: "expired", token: NewSyncToken(now.Add(-SyncTokenMaxAge - time.Second)).Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeSyncToken(tt.token, now); !errors.Is(err, ErrInvalidSyncToken) {
+				t.Errorf("got %v, want ErrInvalidSyncToken", err)
+			}
+		})
+	}
+
+	if _, err := DecodeSyncToken(NewSyncToken(now.Add(-SyncTokenMaxAge)).Encode(), now); err != nil {
+		t.Errorf("token at the max age: %v", err)
+	}
+}
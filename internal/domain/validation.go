@@ -0,0 +1,39 @@
+package domain
+
+import "fmt"
+
+// Checks of a leave request that depend on the organization service
+const (
+	// ValidationCheckEmployee confirms the employee belongs to the organization
+	ValidationCheckEmployee = "employee"
+	// The eligibility, tenure and probation checks read the employee the
+	// employee check looked up, so they cannot run without it
+	ValidationCheckEligibility = "eligibility"
+	ValidationCheckTenure      = "tenure"
+	ValidationCheckProbation   = "probation"
+	// ValidationCheckAbsenceLimit lists the employee's department
+	ValidationCheckAbsenceLimit = "absence_limit"
+)
+
+// What a check does when the organization service cannot be reached
+const (
+	// ValidationSeverityBlock refuses the request
+	ValidationSeverityBlock = "block"
+	// ValidationSeverityDegrade saves the request without the check and
+	// records that it was skipped
+	ValidationSeverityDegrade = "degrade"
+)
+
+// LeaveActionValidationSkipped records a check a request was saved without
+// because the organization service could not be reached
+const LeaveActionValidationSkipped = "validation_skipped"
+
+// SkippedValidation is a check a write went through without and why
+type SkippedValidation struct {
+	Check  string `json:"check"`
+	Reason string `json:"reason"`
+}
+
+func (v SkippedValidation) String() string {
+	return fmt.Sprintf("%s check skipped: %s", v.Check, v.Reason)
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxLeaveSpanDays bounds how far a day calculation may walk in calendar days
+const MaxLeaveSpanDays = 400
+
+var (
+	ErrInvalidDayFraction = errors.New("days must be a positive multiple of 0.5")
+	ErrLeaveSpanTooLong   = errors.New("leave would span more than 400 calendar days")
+)
+
+// AddWorkingDaysParams asks where days working days from Start end. With
+// an EmployeeID the walk skips the optional holidays they claimed, and
+// their location's holidays when Location is empty.
+type AddWorkingDaysParams struct {
+	Start      time.Time
+	Days       float64
+	EmployeeID *uuid.UUID
+	Location   string
+	AuthToken  string
+}
+
+// WorkingDaysResult describes where a run of working days starting on
+// StartDate ends and when the employee is back at work. ChargedDays is
+// what a request over the run is charged before rounding, which includes
+// weekends and holidays sandwiched in it under the sandwich policy.
+type WorkingDaysResult struct {
+	StartDate     time.Time `json:"start_date"`
+	Days          float64   `json:"days"`
+	EndDate       time.Time `json:"end_date"`
+	EndsHalfDay   bool      `json:"ends_half_day"`
+	ReturnDate    time.Time `json:"return_date"`
+	ReturnHalfDay bool      `json:"return_half_day"`
+	ChargedDays   float64   `json:"charged_days"`
+}
+
+// AddWorkingDays walks forward from start over weekends and the given
+// holidays until days working days are covered. A trailing half day ends
+// the leave at midday, so the employee returns that same afternoon.
+func AddWorkingDays(start time.Time, days float64, holidays []Holiday) (*WorkingDaysResult, error) {
+	if days <= 0 || math.Mod(days*2, 1) != 0 {
+		return nil, ErrInvalidDayFraction
+	}
+
+	start = TruncateToDate(start)
+	closed := holidayDates(holidays)
+	limit := start.AddDate(0, 0, MaxLeaveSpanDays)
+
+	result := &WorkingDaysResult{StartDate: start, Days: days}
+	remaining := days
+	for current := start; ; current = current.AddDate(0, 0, 1) {
+		if current.After(limit) {
+			return nil, ErrLeaveSpanTooLong
+		}
+		if !isWorkingDay(current, closed) {
+			continue
+		}
+
+		if remaining == 0.5 {
+			result.EndDate = current
+			result.EndsHalfDay = true
+			result.ReturnDate = current
+			result.ReturnHalfDay = true
+			return result, nil
+		}
+
+		remaining--
+		if remaining == 0 {
+			result.EndDate = current
+			result.ReturnDate = nextWorkingDay(current, closed)
+			return result, nil
+		}
+	}
+}
+
+// IsNonWorkingDay reports whether date is a weekend day or one of the
+// given holidays
+func IsNonWorkingDay(date time.Time, holidays []Holiday) bool {
+	return !isWorkingDay(date, holidayDates(holidays))
+}
+
+func isWorkingDay(date time.Time, holidays map[time.Time]bool) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return !holidays[TruncateToDate(date)]
+}
+
+func nextWorkingDay(date time.Time, holidays map[time.Time]bool) time.Time {
+	next := date.AddDate(0, 0, 1)
+	for !isWorkingDay(next, holidays) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func holidayDates(holidays []Holiday) map[time.Time]bool {
+	dates := make(map[time.Time]bool, len(holidays))
+	for _, h := range holidays {
+		dates[TruncateToDate(h.Date)] = true
+	}
+	return dates
+}
+
+// TruncateToDate drops the time of day, keeping the calendar date at UTC midnight
+func TruncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
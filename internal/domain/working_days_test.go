@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateWorkingDays(t *testing.T) {
+	// 2026-03-06 is a Friday
+	friday := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	monday := friday.AddDate(0, 0, 3)
+	tuesday := friday.AddDate(0, 0, 4)
+	holiday := []Holiday{{Name: "Founders day", Date: monday.Add(15 * time.Hour)}}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		holidays   []Holiday
+		want       float64
+	}{
+		{name: "single day", start: friday, end: friday, want: 1},
+		{name: "over a weekend", start: friday, end: monday, want: 2},
+		{name: "weekend only", start: friday.AddDate(0, 0, 1), end: friday.AddDate(0, 0, 2), want: 0},
+		{name: "full week", start: monday, end: monday.AddDate(0, 0, 4), want: 5},
+		{name: "over a holiday", start: friday, end: tuesday, holidays: holiday, want: 2},
+		{name: "start later in the day than the end", start: friday.Add(17 * time.Hour), end: monday.Add(9 * time.Hour), want: 2},
+		{name: "end before start", start: monday, end: friday, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateWorkingDays(tt.start, tt.end, tt.holidays); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddWorkingDays(t *testing.T) {
+	friday := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	monday := friday.AddDate(0, 0, 3)
+	holiday := []Holiday{{Name: "Founders day", Date: monday}}
+
+	tests := []struct {
+		name           string
+		days           float64
+		holidays       []Holiday
+		wantEnd        time.Time
+		wantReturn     time.Time
+		wantReturnHalf bool
+	}{
+		{name: "one day", days: 1, wantEnd: friday, wantReturn: monday},
+		{name: "over a weekend", days: 2, wantEnd: monday, wantReturn: monday.AddDate(0, 0, 1)},
+		{name: "over a holiday", days: 2, holidays: holiday, wantEnd: monday.AddDate(0, 0, 1), wantReturn: monday.AddDate(0, 0, 2)},
+		{name: "trailing half day", days: 1.5, wantEnd: monday, wantReturn: monday, wantReturnHalf: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AddWorkingDays(friday, tt.days, tt.holidays)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.EndDate.Equal(tt.wantEnd) || !got.ReturnDate.Equal(tt.wantReturn) || got.ReturnHalfDay != tt.wantReturnHalf {
+				t.Errorf("got end %s return %s half %v, want %s %s %v",
+					got.EndDate.Format("2006-01-02"), got.ReturnDate.Format("2006-01-02"), got.ReturnHalfDay,
+					tt.wantEnd.Format("2006-01-02"), tt.wantReturn.Format("2006-01-02"), tt.wantReturnHalf)
+			}
+		})
+	}
+
+	for _, days := range []float64{0, -1, 0.25} {
+		if _, err := AddWorkingDays(friday, days, nil); err != ErrInvalidDayFraction {
+			t.Errorf("%v days: got %v, want ErrInvalidDayFraction", days, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "github.com/google/uuid"
+
+// YearlyResetRequest opens the balances of Year for every employee,
+// carrying over what was left of the year before
+type YearlyResetRequest struct {
+	Year int `json:"year" binding:"required,min=2000,max=2100"`
+	// AuthToken is used to list the organization's employees
+	AuthToken   string    `json:"-"`
+	RequestedBy uuid.UUID `json:"-"`
+}
+
+// YearlyResetCounts tallies the balances a reset touched. Created balances
+// are new rows and Adopted ones provisional rows opened by early requests;
+// CarriedOver counts balances, new or existing, that received days from
+// the year before; Skipped balances already existed with nothing left to
+// carry.
+type YearlyResetCounts struct {
+	Created     int `json:"created"`
+	Adopted     int `json:"adopted"`
+	CarriedOver int `json:"carried_over"`
+	Skipped     int `json:"skipped"`
+}
+
+func (c *YearlyResetCounts) Add(other YearlyResetCounts) {
+	c.Created += other.Created
+	c.Adopted += other.Adopted
+	c.CarriedOver += other.CarriedOver
+	c.Skipped += other.Skipped
+}
+
+// YearlyResetSummary is the outcome of a yearly reset. Each employee's
+// balances are opened in their own transaction, so a failure only affects
+// that employee and a rerun picks up where it failed.
+type YearlyResetSummary struct {
+	Year       int `json:"year"`
+	Employees  int `json:"employees"`
+	LeaveTypes int `json:"leave_types"`
+	YearlyResetCounts
+	Errors []YearlyResetError `json:"errors"`
+}
+
+// YearlyResetError records why an employee's balances could not be opened
+type YearlyResetError struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	Error      string    `json:"error"`
+}
+
+// CarryOverExpirySummary is the outcome of expiring carried-over days.
+// Expired counts the balances whose carry-over expired, Forfeited those
+// that still had unused carried-over days, which were written off.
+type CarryOverExpirySummary struct {
+	Expired       int     `json:"expired"`
+	Forfeited     int     `json:"forfeited"`
+	ForfeitedDays float64 `json:"forfeited_days"`
+	Failed        int     `json:"failed"`
+}
@@ -7,22 +7,26 @@ type ErrorCode string
 
 const (
 	// Client Errors (4xx)
-	ErrBadRequest   ErrorCode = "BAD_REQUEST"
-	ErrUnauthorized ErrorCode = "UNAUTHORIZED"
-	ErrForbidden    ErrorCode = "FORBIDDEN"
-	ErrNotFound     ErrorCode = "NOT_FOUND"
-	ErrConflict     ErrorCode = "CONFLICT"
-	ErrValidation   ErrorCode = "VALIDATION_ERROR"
+	ErrBadRequest    ErrorCode = "BAD_REQUEST"
+	ErrUnauthorized  ErrorCode = "UNAUTHORIZED"
+	ErrForbidden     ErrorCode = "FORBIDDEN"
+	ErrNotFound      ErrorCode = "NOT_FOUND"
+	ErrConflict      ErrorCode = "CONFLICT"
+	ErrValidation    ErrorCode = "VALIDATION_ERROR"
+	ErrUnprocessable ErrorCode = "UNPROCESSABLE_ENTITY"
 
 	// Server Errors (5xx)
 	ErrInternalServer    ErrorCode = "INTERNAL_SERVER_ERROR"
 	ErrDatabaseOperation ErrorCode = "DATABASE_ERROR"
 	ErrExternalService   ErrorCode = "EXTERNAL_SERVICE_ERROR"
+	ErrReadOnly          ErrorCode = "READ_ONLY_MODE"
 
 	// Business Logic Errors
 	ErrOrganizationInactive ErrorCode = "ORGANIZATION_INACTIVE"
 	ErrInvalidStatus        ErrorCode = "INVALID_STATUS"
 	ErrLimitExceeded        ErrorCode = "LIMIT_EXCEEDED"
+	ErrInsufficientBalance  ErrorCode = "INSUFFICIENT_BALANCE"
+	ErrNegativeBalanceCap   ErrorCode = "NEGATIVE_BALANCE_CAP_EXCEEDED"
 )
 
 type AppError struct {
@@ -54,6 +58,22 @@ func NewValidationError(details interface{}) *AppError {
 	}
 }
 
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Code:       ErrUnauthorized,
+		Message:    message,
+		HTTPStatus: 401,
+	}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:       ErrForbidden,
+		Message:    message,
+		HTTPStatus: 403,
+	}
+}
+
 func NewNotFoundError(message string) *AppError {
 	return &AppError{
 		Code:       ErrNotFound,
@@ -62,6 +82,70 @@ func NewNotFoundError(message string) *AppError {
 	}
 }
 
+func NewConflictError(message string) *AppError {
+	return &AppError{
+		Code:       ErrConflict,
+		Message:    message,
+		HTTPStatus: 409,
+	}
+}
+
+func NewInvalidStatusError(message string) *AppError {
+	return &AppError{
+		Code:       ErrInvalidStatus,
+		Message:    message,
+		HTTPStatus: 409,
+	}
+}
+
+func NewInsufficientBalanceError(message string) *AppError {
+	return &AppError{
+		Code:       ErrInsufficientBalance,
+		Message:    message,
+		HTTPStatus: 422,
+	}
+}
+
+func NewNegativeBalanceCapError(message string) *AppError {
+	return &AppError{
+		Code:       ErrNegativeBalanceCap,
+		Message:    message,
+		HTTPStatus: 422,
+	}
+}
+
+func NewUnprocessableError(message string) *AppError {
+	return &AppError{
+		Code:       ErrUnprocessable,
+		Message:    message,
+		HTTPStatus: 422,
+	}
+}
+
+func NewServiceUnavailableError(message string) *AppError {
+	return &AppError{
+		Code:       ErrExternalService,
+		Message:    message,
+		HTTPStatus: 503,
+	}
+}
+
+func NewBadGatewayError(message string) *AppError {
+	return &AppError{
+		Code:       ErrExternalService,
+		Message:    message,
+		HTTPStatus: 502,
+	}
+}
+
+func NewReadOnlyError(message string) *AppError {
+	return &AppError{
+		Code:       ErrReadOnly,
+		Message:    message,
+		HTTPStatus: 503,
+	}
+}
+
 func NewInternalServerError(message string) *AppError {
 	return &AppError{
 		Code:       ErrInternalServer,
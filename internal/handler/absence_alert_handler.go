@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AbsenceAlertHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewAbsenceAlertHandler(leaveService service.LeaveService) *AbsenceAlertHandler {
+	return &AbsenceAlertHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary List absence alerts
+// @Description Lists the organization's rolling 12-month absence alerts, oldest first (HR and admins)
+// @Tags absence-alerts
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param status query string false "open, acknowledged or resolved"
+// @Param employee_id query string false "Employee ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} ListResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/absence-alerts [get]
+func (h *AbsenceAlertHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only HR and admins can list absence alerts"})
+		return
+	}
+
+	params := &domain.ListAbsenceAlertsParams{
+		Page:     1,
+		PageSize: 10,
+		Status:   c.Query("status"),
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+	}
+
+	alerts, total, err := h.leaveService.ListAbsenceAlerts(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: alerts,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Acknowledge an absence alert
+// @Description Marks an open alert as under review (HR and admins)
+// @Tags absence-alerts
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Absence alert ID"
+// @Param body body domain.AbsenceAlertActionRequest false "Comments"
+// @Success 200 {object} domain.AbsenceAlert
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/absence-alerts/{id}/acknowledge [put]
+func (h *AbsenceAlertHandler) Acknowledge(c *gin.Context) {
+	h.act(c, h.leaveService.AcknowledgeAbsenceAlert)
+}
+
+// @Summary Resolve an absence alert
+// @Description Closes the review of an open or acknowledged alert (HR and admins). The employee is not alerted again until they fall back under the thresholds and cross them anew.
+// @Tags absence-alerts
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Absence alert ID"
+// @Param body body domain.AbsenceAlertActionRequest false "Comments"
+// @Success 200 {object} domain.AbsenceAlert
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/absence-alerts/{id}/resolve [put]
+func (h *AbsenceAlertHandler) Resolve(c *gin.Context) {
+	h.act(c, h.leaveService.ResolveAbsenceAlert)
+}
+
+func (h *AbsenceAlertHandler) act(c *gin.Context, action func(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error)) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid absence alert id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only HR and admins can act on absence alerts"})
+		return
+	}
+
+	var req domain.AbsenceAlertActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	alert, err := action(orgID, id, userID, req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// @Summary Evaluate absence alerts
+// @Description Evaluates the organization's rolling 12-month absence thresholds now, as the nightly job does (admin only). Reruns raise no duplicate alerts.
+// @Tags absence-alerts
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.AbsenceAlertEvaluation
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/absence-alerts/evaluate [post]
+func (h *AbsenceAlertHandler) Evaluate(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can evaluate absence alerts"})
+		return
+	}
+
+	evaluation, err := h.leaveService.EvaluateAbsenceAlerts(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, evaluation)
+}
+
+// @Summary Run the nightly absence alert evaluation
+// @Description Evaluates the rolling 12-month absence thresholds of every organization that set one or has an alert to clear, and notifies HR of new alerts. Meant to be called nightly by a scheduler; reruns raise no duplicate alerts.
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Admin-Token header string true "Internal admin token"
+// @Success 200 {array} domain.AbsenceAlertEvaluation
+// @Router /internal/absence-alerts [post]
+func (h *AbsenceAlertHandler) RunScheduledEvaluation(c *gin.Context) {
+	evaluations, err := h.leaveService.EvaluateAllAbsenceAlerts()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, evaluations)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/Axontik/comin-leave-management-service/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// absenceAlertService records the absence alert calls it gets; every other
+// method is left unimplemented
+type absenceAlertService struct {
+	service.LeaveService
+	params   *domain.ListAbsenceAlertsParams
+	userID   uuid.UUID
+	comments string
+}
+
+func (s *absenceAlertService) ListAbsenceAlerts(orgID uuid.UUID, params *domain.ListAbsenceAlertsParams) ([]domain.AbsenceAlert, int64, error) {
+	s.params = params
+	return []domain.AbsenceAlert{{OrganizationID: orgID, Status: params.Status}}, 1, nil
+}
+
+func (s *absenceAlertService) AcknowledgeAbsenceAlert(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error) {
+	s.userID, s.comments = userID, comments
+	return &domain.AbsenceAlert{Base: domain.Base{ID: id}, Status: domain.AbsenceAlertStatusAcknowledged}, nil
+}
+
+func (s *absenceAlertService) ResolveAbsenceAlert(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error) {
+	s.userID, s.comments = userID, comments
+	return &domain.AbsenceAlert{Base: domain.Base{ID: id}, Status: domain.AbsenceAlertStatusResolved}, nil
+}
+
+func absenceAlertRouter(svc *absenceAlertService, userID uuid.UUID, role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		auth.SetCurrentUser(c, &auth.UserResponse{ID: userID.String()})
+		c.Set("role", role)
+	})
+	h := NewAbsenceAlertHandler(svc)
+	router.GET("/organizations/:organization_id/absence-alerts", h.List)
+	router.PUT("/organizations/:organization_id/absence-alerts/:id/acknowledge", h.Acknowledge)
+	router.PUT("/organizations/:organization_id/absence-alerts/:id/resolve", h.Resolve)
+	return router
+}
+
+func TestAbsenceAlertHandlerList(t *testing.T) {
+	tests := []struct {
+		role string
+		want int
+	}{
+		{role: "employee", want: http.StatusForbidden},
+		{role: domain.RoleManager, want: http.StatusForbidden},
+		{role: domain.RoleHR, want: http.StatusOK},
+		{role: domain.RoleAdmin, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			svc := &absenceAlertService{}
+			w := httptest.NewRecorder()
+			absenceAlertRouter(svc, uuid.New(), tt.role).ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+				"/organizations/"+uuid.New().String()+"/absence-alerts?status=open&page_size=20", nil))
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			if svc.params.Status != domain.AbsenceAlertStatusOpen || svc.params.PageSize != 20 {
+				t.Errorf("params = %+v, want open alerts, 20 a page", svc.params)
+			}
+			var body struct {
+				Data []domain.AbsenceAlert `json:"data"`
+				Meta MetaResponse          `json:"meta"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Data) != 1 || body.Meta.Total != 1 {
+				t.Errorf("body = %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAbsenceAlertHandlerActions(t *testing.T) {
+	for _, action := range []string{"acknowledge", "resolve"} {
+		t.Run(action, func(t *testing.T) {
+			path := "/organizations/" + uuid.New().String() + "/absence-alerts/" + uuid.New().String() + "/" + action
+
+			svc := &absenceAlertService{}
+			w := httptest.NewRecorder()
+			absenceAlertRouter(svc, uuid.New(), domain.RoleManager).ServeHTTP(w, httptest.NewRequest(http.MethodPut, path, nil))
+			if w.Code != http.StatusForbidden {
+				t.Errorf("manager: got %d, want %d", w.Code, http.StatusForbidden)
+			}
+
+			hr := uuid.New()
+			w = httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, path, strings.NewReader(`{"comments":"occupational health referral"}`))
+			req.Header.Set("Content-Type", "application/json")
+			absenceAlertRouter(svc, hr, domain.RoleHR).ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("hr: got %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			if svc.userID != hr || svc.comments != "occupational health referral" {
+				t.Errorf("service called by %s with %q", svc.userID, svc.comments)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+//go:embed ui
+var adminUIFiles embed.FS
+
+// recentRequestsShown is how many of an organization's latest requests the
+// console lists
+const recentRequestsShown = 25
+
+// AdminUIHandler serves the read-only operator console. It reads through
+// the service layer like the API does.
+type AdminUIHandler struct {
+	leaveService service.LeaveService
+	templates    *template.Template
+}
+
+func NewAdminUIHandler(leaveService service.LeaveService) *AdminUIHandler {
+	return &AdminUIHandler{
+		leaveService: leaveService,
+		templates:    template.Must(template.ParseFS(adminUIFiles, "ui/*.tmpl")),
+	}
+}
+
+// Stylesheet serves the console's embedded stylesheet
+func (h *AdminUIHandler) Stylesheet(c *gin.Context) {
+	c.FileFromFS("ui/admin.css", http.FS(adminUIFiles))
+}
+
+type adminUIPage struct {
+	Title      string
+	Error      string
+	OrgID      *uuid.UUID
+	EmployeeID *uuid.UUID
+	Year       int
+	Settings   *domain.OrganizationSettings
+	LeaveTypes []domain.LeaveType
+	Requests   []domain.LeaveRequest
+	Balances   []domain.LeaveBalanceResponse
+}
+
+func (h *AdminUIHandler) Index(c *gin.Context) {
+	h.render(c, http.StatusOK, "index", &adminUIPage{Title: "Organizations"})
+}
+
+// OpenOrganization redirects the organization selector to its page
+func (h *AdminUIHandler) OpenOrganization(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Query("organization_id"))
+	if err != nil {
+		h.render(c, http.StatusBadRequest, "index", &adminUIPage{Title: "Organizations", Error: "invalid organization id"})
+		return
+	}
+	c.Redirect(http.StatusFound, "/internal/ui/org/"+orgID.String())
+}
+
+// Organization shows an organization's settings, leave types and latest requests
+func (h *AdminUIHandler) Organization(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		h.render(c, http.StatusBadRequest, "index", &adminUIPage{Title: "Organizations", Error: "invalid organization id"})
+		return
+	}
+	page := &adminUIPage{Title: "Organization " + orgID.String(), OrgID: &orgID}
+
+	if page.Settings, err = h.leaveService.GetOrganizationSettings(orgID); err != nil {
+		h.renderError(c, page, err)
+		return
+	}
+	if page.LeaveTypes, _, err = h.leaveService.ListLeaveTypes(orgID, &domain.ListLeaveTypesParams{Page: 1, PageSize: 100}); err != nil {
+		h.renderError(c, page, err)
+		return
+	}
+	if page.Requests, _, err = h.leaveService.ListLeaveRequests(orgID, &domain.ListLeaveRequestsParams{Page: 1, PageSize: recentRequestsShown}); err != nil {
+		h.renderError(c, page, err)
+		return
+	}
+
+	h.render(c, http.StatusOK, "organization", page)
+}
+
+// Balances looks up an employee's balances for a year
+func (h *AdminUIHandler) Balances(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		h.render(c, http.StatusBadRequest, "index", &adminUIPage{Title: "Organizations", Error: "invalid organization id"})
+		return
+	}
+	page := &adminUIPage{Title: "Balance lookup", OrgID: &orgID}
+
+	if v := c.Query("year"); v != "" {
+		if page.Year, err = strconv.Atoi(v); err != nil {
+			page.Error = "invalid year"
+			h.render(c, http.StatusBadRequest, "balances", page)
+			return
+		}
+	}
+
+	if v := c.Query("employee_id"); v != "" {
+		employeeID, err := uuid.Parse(v)
+		if err != nil {
+			page.Error = "invalid employee id"
+			h.render(c, http.StatusBadRequest, "balances", page)
+			return
+		}
+		page.EmployeeID = &employeeID
+		if page.Balances, err = h.leaveService.ListEmployeeBalances(orgID, employeeID, page.Year, false); err != nil {
+			h.renderError(c, page, err)
+			return
+		}
+	}
+
+	h.render(c, http.StatusOK, "balances", page)
+}
+
+func (h *AdminUIHandler) renderError(c *gin.Context, page *adminUIPage, err error) {
+	page.Error = err.Error()
+	h.render(c, http.StatusInternalServerError, "index", page)
+}
+
+// render writes the page and logs the view, since the console is reached
+// with the shared operator token rather than a user's
+func (h *AdminUIHandler) render(c *gin.Context, status int, name string, page *adminUIPage) {
+	log.Printf("Admin UI: %s viewed %s (%d)", c.ClientIP(), c.Request.URL.RequestURI(), status)
+
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := h.templates.ExecuteTemplate(c.Writer, name, page); err != nil {
+		log.Printf("Error: rendering admin UI page %s: %v", name, err)
+	}
+}
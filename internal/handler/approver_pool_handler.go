@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ApproverPoolHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewApproverPoolHandler(leaveService service.LeaveService) *ApproverPoolHandler {
+	return &ApproverPoolHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary Create approver pool
+// @Description Create a round-robin approver pool for an organization
+// @Tags approver-pools
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param pool body domain.CreateApproverPoolRequest true "Pool details"
+// @Success 201 {object} domain.ApproverPool
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/approver-pools [post]
+func (h *ApproverPoolHandler) Create(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage approver pools"})
+		return
+	}
+
+	var req domain.CreateApproverPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pool := &domain.ApproverPool{
+		OrganizationID: orgID,
+		Name:           req.Name,
+	}
+	if err := h.leaveService.CreateApproverPool(pool); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pool)
+}
+
+// @Summary List approver pools
+// @Tags approver-pools
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {array} domain.ApproverPool
+// @Router /organizations/{organization_id}/approver-pools [get]
+func (h *ApproverPoolHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	pools, err := h.leaveService.ListApproverPools(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pools)
+}
+
+// @Summary Get approver pool
+// @Tags approver-pools
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Approver Pool ID"
+// @Success 200 {object} domain.ApproverPool
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/approver-pools/{id} [get]
+func (h *ApproverPoolHandler) GetByID(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid approver pool id"})
+		return
+	}
+
+	pool, err := h.leaveService.GetApproverPool(orgID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pool)
+}
+
+// @Summary Add approver pool member
+// @Description Add a user to the end of the pool's rotation
+// @Tags approver-pools
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Approver Pool ID"
+// @Param member body domain.AddApproverPoolMemberRequest true "Member"
+// @Success 201 {object} domain.ApproverPoolMember
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/approver-pools/{id}/members [post]
+func (h *ApproverPoolHandler) AddMember(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid approver pool id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage approver pools"})
+		return
+	}
+
+	var req domain.AddApproverPoolMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.leaveService.AddApproverPoolMember(orgID, id, req.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// @Summary Remove approver pool member
+// @Tags approver-pools
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Approver Pool ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/approver-pools/{id}/members/{user_id} [delete]
+func (h *ApproverPoolHandler) RemoveMember(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid approver pool id"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage approver pools"})
+		return
+	}
+
+	if err := h.leaveService.RemoveApproverPoolMember(orgID, id, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
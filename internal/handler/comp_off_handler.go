@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CompOffHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewCompOffHandler(leaveService service.LeaveService) *CompOffHandler {
+	return &CompOffHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary Request comp-off
+// @Description Claims comp-off, in days (0.5 or 1) or hours, for a weekend or holiday worked. Employees claim for themselves; managers and admins for anyone. The organization must have a comp-off leave type configured, and a date can be claimed once unless the claim was rejected.
+// @Tags comp-off
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param request body domain.CreateCompOffRequest true "Comp-off claim"
+// @Success 201 {object} domain.CompOffRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/comp-off-requests [post]
+func (h *CompOffHandler) Create(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req domain.CreateCompOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.EmployeeID != userID && !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can request comp-off for someone else"})
+		return
+	}
+	req.RequestedBy = userID
+	req.AuthToken = c.GetHeader("Authorization")
+
+	request, err := h.leaveService.CreateCompOffRequest(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// @Summary List comp-off requests
+// @Description Lists the organization's comp-off requests, oldest first (admins and managers)
+// @Tags comp-off
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param status query string false "pending, approved, rejected or expired"
+// @Param employee_id query string false "Employee ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} ListResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/comp-off-requests [get]
+func (h *CompOffHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can list comp-off requests"})
+		return
+	}
+
+	params := &domain.ListCompOffRequestsParams{
+		Page:     1,
+		PageSize: 10,
+		Status:   c.Query("status"),
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+	}
+
+	requests, total, err := h.leaveService.ListCompOffRequests(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: requests,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Get a comp-off request
+// @Tags comp-off
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Comp-off request ID"
+// @Success 200 {object} domain.CompOffRequest
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/comp-off-requests/{id} [get]
+func (h *CompOffHandler) GetByID(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comp-off request id"})
+		return
+	}
+
+	request, err := h.leaveService.GetCompOffRequest(orgID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// @Summary Approve a comp-off request
+// @Description Approves a pending claim and credits it to the employee's balance of the comp-off leave type for the current year, through an approved adjustment. The credit lapses after the organization's comp_off_expiry_days, if set. Managers cannot approve their own claims.
+// @Tags comp-off
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Comp-off request ID"
+// @Param body body domain.CompOffActionRequest false "Comments"
+// @Success 200 {object} domain.CompOffRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/comp-off-requests/{id}/approve [put]
+func (h *CompOffHandler) Approve(c *gin.Context) {
+	h.decide(c, h.leaveService.ApproveCompOffRequest)
+}
+
+// @Summary Reject a comp-off request
+// @Description Rejects a pending claim; the date can be claimed again. Managers cannot reject their own claims.
+// @Tags comp-off
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Comp-off request ID"
+// @Param body body domain.CompOffActionRequest false "Comments"
+// @Success 200 {object} domain.CompOffRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/comp-off-requests/{id}/reject [put]
+func (h *CompOffHandler) Reject(c *gin.Context) {
+	h.decide(c, h.leaveService.RejectCompOffRequest)
+}
+
+func (h *CompOffHandler) decide(c *gin.Context, decide func(orgID, id, deciderID uuid.UUID, role, comments string) (*domain.CompOffRequest, error)) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comp-off request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can decide comp-off requests"})
+		return
+	}
+
+	var req domain.CompOffActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	request, err := decide(orgID, id, userID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// @Summary Expire comp-off
+// @Description Lapses approved comp-off past its last usable day, forfeiting what is left of each credit with a system adjustment (admin only). Reruns are safe.
+// @Tags comp-off
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.CompOffExpirySummary
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/comp-off-requests/expire [post]
+func (h *CompOffHandler) Expire(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can expire comp-off"})
+		return
+	}
+
+	summary, err := h.leaveService.ExpireCompOff(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
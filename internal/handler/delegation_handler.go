@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DelegationHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewDelegationHandler(leaveService service.LeaveService) *DelegationHandler {
+	return &DelegationHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary List delegations
+// @Description Lists the delegations of approvers' approvals while they are on leave, by start date. HR and admins see all of them; anyone else sees those they delegate, or those delegated to them with delegate_id set to themselves.
+// @Tags delegations
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param status query string false "proposed, escalated, confirmed, declined or withdrawn"
+// @Param delegator_id query string false "Delegator ID"
+// @Param delegate_id query string false "Delegate ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} ListResponse
+// @Router /organizations/{organization_id}/delegations [get]
+func (h *DelegationHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := &domain.ListDelegationsParams{
+		Page:     1,
+		PageSize: 10,
+		Status:   c.Query("status"),
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if delegatorID := c.Query("delegator_id"); delegatorID != "" {
+		id, err := uuid.Parse(delegatorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delegator id"})
+			return
+		}
+		params.DelegatorID = &id
+	}
+
+	if delegateID := c.Query("delegate_id"); delegateID != "" {
+		id, err := uuid.Parse(delegateID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delegate id"})
+			return
+		}
+		params.DelegateID = &id
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleHR) && (params.DelegateID == nil || *params.DelegateID != userID) {
+		if params.DelegatorID != nil && *params.DelegatorID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only HR and admins can list the delegations of others"})
+			return
+		}
+		params.DelegatorID = &userID
+	}
+
+	delegations, total, err := h.leaveService.ListDelegations(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: delegations,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Confirm a delegation
+// @Description Confirms a proposed or escalated delegation, to the suggested delegate unless another is given (the delegator, HR and admins). The delegate may then act on the delegator's approvals during the leave.
+// @Tags delegations
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Delegation ID"
+// @Param body body domain.ConfirmDelegationRequest false "Delegate"
+// @Success 200 {object} domain.Delegation
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/delegations/{id}/confirm [put]
+func (h *DelegationHandler) Confirm(c *gin.Context) {
+	orgID, id, userID, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ConfirmDelegationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	req.AuthToken = c.GetHeader("Authorization")
+
+	delegation, err := h.leaveService.ConfirmDelegation(orgID, id, userID, c.GetString("role"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delegation)
+}
+
+// @Summary Change a delegation's delegate
+// @Description Hands a proposed, escalated or confirmed delegation to another delegate (the delegator, HR and admins). A proposal still has to be confirmed.
+// @Tags delegations
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Delegation ID"
+// @Param body body domain.ChangeDelegateRequest true "Delegate"
+// @Success 200 {object} domain.Delegation
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/delegations/{id}/delegate [put]
+func (h *DelegationHandler) ChangeDelegate(c *gin.Context) {
+	orgID, id, userID, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ChangeDelegateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.AuthToken = c.GetHeader("Authorization")
+
+	delegation, err := h.leaveService.ChangeDelegate(orgID, id, userID, c.GetString("role"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delegation)
+}
+
+// @Summary Decline a delegation
+// @Description Declines a proposed or escalated delegation, so the delegator's approvals wait for their return (the delegator, HR and admins)
+// @Tags delegations
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Delegation ID"
+// @Success 200 {object} domain.Delegation
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/delegations/{id}/decline [put]
+func (h *DelegationHandler) Decline(c *gin.Context) {
+	orgID, id, userID, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	delegation, err := h.leaveService.DeclineDelegation(orgID, id, userID, c.GetString("role"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delegation)
+}
+
+// @Summary Escalate unconfirmed delegations
+// @Description Escalates the delegations of every organization not confirmed by their deadline and notifies each organization's delegation webhook, whose receiver resolves the delegator's manager. Meant to be called on a schedule; reruns escalate nothing twice.
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Admin-Token header string true "Internal admin token"
+// @Success 200 {object} domain.DelegationEscalation
+// @Router /internal/delegations/escalate [post]
+func (h *DelegationHandler) RunScheduledEscalation(c *gin.Context) {
+	escalation, err := h.leaveService.EscalateDelegations()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, escalation)
+}
+
+// params parses the organization and delegation of the path and the caller
+func (h *DelegationHandler) params(c *gin.Context) (orgID, id, userID uuid.UUID, ok bool) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delegation id"})
+		return
+	}
+
+	userID, err = currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	return orgID, id, userID, true
+}
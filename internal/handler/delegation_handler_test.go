@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/Axontik/comin-leave-management-service/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// delegationService records the filter delegations are listed with; every
+// other method is left unimplemented
+type delegationService struct {
+	service.LeaveService
+	params *domain.ListDelegationsParams
+}
+
+func (s *delegationService) ListDelegations(orgID uuid.UUID, params *domain.ListDelegationsParams) ([]domain.Delegation, int64, error) {
+	s.params = params
+	return nil, 0, nil
+}
+
+func TestDelegationHandlerListScopesCaller(t *testing.T) {
+	userID, other := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name          string
+		role          string
+		query         string
+		want          int
+		wantDelegator *uuid.UUID
+	}{
+		{name: "hr sees all", role: domain.RoleHR, want: http.StatusOK},
+		{name: "approver sees their own", role: domain.RoleManager, want: http.StatusOK, wantDelegator: &userID},
+		{name: "delegate sees those delegated to them", role: domain.RoleManager, query: "?delegate_id=" + userID.String(), want: http.StatusOK},
+		{name: "approver cannot list another's", role: domain.RoleManager, query: "?delegator_id=" + other.String(), want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				auth.SetCurrentUser(c, &auth.UserResponse{ID: userID.String()})
+				c.Set("role", tt.role)
+			})
+			svc := &delegationService{}
+			router.GET("/organizations/:organization_id/delegations", NewDelegationHandler(svc).List)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+				"/organizations/"+uuid.New().String()+"/delegations"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			got := svc.params.DelegatorID
+			if (got == nil) != (tt.wantDelegator == nil) || (got != nil && *got != *tt.wantDelegator) {
+				t.Errorf("listed delegator %v, want %v", got, tt.wantDelegator)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestParseChangesParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.MustParse("7d1c6a9e-3f55-4c55-9b3e-2f0f4a2f8b11")
+
+	tests := []struct {
+		name      string
+		query     string
+		wantErr   bool
+		wantSince time.Time
+		wantID    uuid.UUID
+		wantLimit int
+	}{
+		{name: "empty", query: ""},
+		{
+			name:      "full watermark",
+			query:     "since=2026-03-01T10:00:00.123456Z&since_id=" + id.String() + "&limit=50",
+			wantSince: time.Date(2026, 3, 1, 10, 0, 0, 123456000, time.UTC),
+			wantID:    id,
+			wantLimit: 50,
+		},
+		{name: "limit above the maximum is left to the service", query: "limit=5000", wantLimit: 5000},
+		{name: "unparseable limit", query: "limit=ten", wantErr: true},
+		{name: "zero limit", query: "limit=0", wantErr: true},
+		{name: "negative limit", query: "limit=-1", wantErr: true},
+		{name: "invalid since", query: "since=yesterday", wantErr: true},
+		{name: "invalid since_id", query: "since_id=42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/changes?"+tt.query, nil)
+
+			params, err := parseChangesParams(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", params)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !params.Since.Equal(tt.wantSince) || params.SinceID != tt.wantID || params.Limit != tt.wantLimit {
+				t.Errorf("got since=%v since_id=%v limit=%d, want since=%v since_id=%v limit=%d",
+					params.Since, params.SinceID, params.Limit, tt.wantSince, tt.wantID, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -1,36 +1,646 @@
 package handler
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/middleware"
 	"github.com/Axontik/comin-leave-management-service/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type HolidayHandler struct {
 	leaveService service.LeaveService
+	// calendarTokenSecret signs the calendar tokens handed out for the ICS
+	// export; none are issued when it is empty
+	calendarTokenSecret string
 }
 
-func NewHolidayHandler(leaveService service.LeaveService) *HolidayHandler {
+func NewHolidayHandler(leaveService service.LeaveService, calendarTokenSecret string) *HolidayHandler {
 	return &HolidayHandler{
-		leaveService: leaveService,
+		leaveService:        leaveService,
+		calendarTokenSecret: calendarTokenSecret,
 	}
 }
 
+// @Summary Create a holiday
+// @Description Adds a holiday to the organization calendar (admin only). With a location it applies only to employees there. Employees observe one holiday per date, so a holiday clashing with an organization-wide one or another of its location returns 409. Pending and approved leave that has not ended and includes the date is recalculated, its balances charged the difference; with preview_impact=true nothing is saved and the requests that would change are returned.
+// @Tags holidays
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param preview_impact query bool false "Only list the leave requests the holiday would recalculate"
+// @Param holiday body domain.CreateHolidayRequest true "Holiday details"
+// @Success 201 {object} domain.Holiday
+// @Success 200 {object} domain.HolidayRecalculation
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays [post]
 func (h *HolidayHandler) Create(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage holidays"})
+		return
+	}
+
+	change, ok := holidayChange(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holiday := &domain.Holiday{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Date:           req.Date,
+		Type:           req.Type,
+		Location:       domain.HolidayLocation(req.Location),
+	}
+	if err := h.leaveService.CreateHoliday(holiday, change); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if change.PreviewImpact {
+		c.JSON(http.StatusOK, holiday.Recalculation)
+		return
+	}
+	c.JSON(http.StatusCreated, holiday)
 }
 
+// @Summary List holidays
+// @Description Lists the organization's holidays by date, optionally within a year or between from and to, inclusive, and only those observed at a location
+// @Tags holidays
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param year query int false "Calendar year"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Param location query string false "Only holidays observed at this location, organization-wide ones included"
+// @Success 200 {array} domain.Holiday
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays [get]
 func (h *HolidayHandler) List(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	params := &domain.ListHolidaysParams{Location: c.Query("location")}
+	if v := c.Query("year"); v != "" {
+		if params.Year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		params.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+		params.To = &t
+	}
+
+	holidays, err := h.leaveService.ListHolidays(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, holidays)
 }
 
+// @Summary Update a holiday
+// @Description Changes the fields that are set (admin only); an empty location makes the holiday organization-wide. A new date, location or type recalculates the pending and approved leave that has not ended and includes the old or new date, its balances charged the difference; with preview_impact=true nothing is saved and the requests that would change are returned.
+// @Tags holidays
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Holiday ID"
+// @Param preview_impact query bool false "Only list the leave requests the change would recalculate"
+// @Param holiday body domain.UpdateHolidayRequest true "Fields to change"
+// @Success 200 {object} domain.Holiday
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/{id} [put]
 func (h *HolidayHandler) Update(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid holiday id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage holidays"})
+		return
+	}
+
+	change, ok := holidayChange(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holiday, err := h.leaveService.UpdateHoliday(orgID, id, &req, change)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if change.PreviewImpact {
+		c.JSON(http.StatusOK, holiday.Recalculation)
+		return
+	}
+	c.JSON(http.StatusOK, holiday)
 }
 
+// @Summary Delete a holiday
+// @Description Removes a holiday (admin only). Pending and approved leave that has not ended and includes its date is recalculated, its balances charged the difference; with preview_impact=true the holiday is kept and the requests that would change are returned.
+// @Tags holidays
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Holiday ID"
+// @Param preview_impact query bool false "Only list the leave requests the removal would recalculate"
+// @Success 204
+// @Success 200 {object} domain.HolidayRecalculation
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/{id} [delete]
 func (h *HolidayHandler) Delete(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid holiday id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage holidays"})
+		return
+	}
+
+	change, ok := holidayChange(c)
+	if !ok {
+		return
+	}
+
+	recalculation, err := h.leaveService.DeleteHoliday(orgID, id, change)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if change.PreviewImpact {
+		c.JSON(http.StatusOK, recalculation)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Import holidays
+// @Description Adds many holidays at once (admin only), sent as a JSON array of holidays shaped like single creates or as a multipart CSV file whose header names the name, date (YYYY-MM-DD) and type columns, and optionally location. Every row is validated like a single create and created on its own, so bad rows fail on their own; a second row on the same date, or a date that already has a holiday, fails. With dry_run=true nothing is created and valid rows are reported as such. The report gives the outcome of every row by its line in the file or position in the array.
+// @Tags holidays
+// @Accept json,multipart/form-data
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param dry_run query bool false "Only validate the rows"
+// @Param holidays body []domain.CreateHolidayRequest false "Holidays, when sent as JSON"
+// @Param file formData file false "CSV file, when sent as multipart"
+// @Success 200 {object} domain.HolidayImportReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/bulk [post]
+func (h *HolidayHandler) BulkImport(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage holidays"})
+		return
+	}
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return
+		}
+	}
+
+	var rows []domain.HolidayImportRow
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a CSV file is required"})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		defer file.Close()
+
+		if rows, err = domain.ParseHolidayCSV(file); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid CSV: " + err.Error()})
+			return
+		}
+	} else if rows, err = domain.ParseHolidayJSON(c.Request.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.leaveService.ImportHolidays(orgID, rows, dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Import public holidays
+// @Description Fetches a country's public holidays of a year from the holiday provider and adds them as public holidays (admin only), organization-wide or for one location. Dates that already have a holiday and regional holidays are skipped and listed with the reason. With preview=true nothing is saved and the holidays that would be created are returned. Provider failures return 502 with the provider's error.
+// @Tags holidays
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param country query string true "ISO 3166-1 alpha-2 country code, e.g. LK"
+// @Param year query int false "Calendar year, the current one unless set"
+// @Param location query string false "Add the holidays for this location only"
+// @Param preview query bool false "Only return what would be created"
+// @Success 200 {object} domain.PublicHolidayImport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/import [post]
+func (h *HolidayHandler) ImportPublic(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage holidays"})
+		return
+	}
+
+	country := c.Query("country")
+	if country == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "country is required"})
+		return
+	}
+
+	var year int
+	if v := c.Query("year"); v != "" {
+		if year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	} else {
+		today, err := h.leaveService.Today(orgID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		year = today.Year()
+	}
+
+	preview := false
+	if v := c.Query("preview"); v != "" {
+		if preview, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid preview"})
+			return
+		}
+	}
+
+	result, err := h.leaveService.ImportPublicHolidays(orgID, country, year, c.Query("location"), preview)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Claim an optional holiday
+// @Description Elects an optional holiday for the employee, who then gets it off like a day of approved leave without it being charged to any balance. Employees claim for themselves; managers and admins for anyone. Claims past the organization's yearly quota return 422, and only admins can claim holidays that have passed.
+// @Tags holidays
+// @Accept json
+// @Produce json
+// @Param employee_id path string true "Employee ID"
+// @Param claim body domain.ClaimOptionalHolidayRequest true "Optional holiday to claim"
+// @Success 201 {object} domain.OptionalHolidayClaim
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /employees/{employee_id}/optional-holidays [post]
+func (h *HolidayHandler) ClaimOptional(c *gin.Context) {
+	orgID, employeeID, userID, ok := optionalHolidayCaller(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ClaimOptionalHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claim, err := h.leaveService.ClaimOptionalHoliday(orgID, employeeID, req.HolidayID, userID,
+		c.GetString("role"), c.GetHeader("Authorization"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, claim)
+}
+
+// @Summary Unclaim an optional holiday
+// @Description Withdraws the employee's claim of an optional holiday, before its date unless the caller is an admin
+// @Tags holidays
+// @Param employee_id path string true "Employee ID"
+// @Param holiday_id path string true "Holiday ID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /employees/{employee_id}/optional-holidays/{holiday_id} [delete]
+func (h *HolidayHandler) UnclaimOptional(c *gin.Context) {
+	orgID, employeeID, _, ok := optionalHolidayCaller(c)
+	if !ok {
+		return
+	}
+
+	holidayID, err := uuid.Parse(c.Param("holiday_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid holiday id"})
+		return
+	}
+
+	if err := h.leaveService.UnclaimOptionalHoliday(orgID, employeeID, holidayID, c.GetString("role")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List an employee's optional holidays
+// @Description The employee's optional holiday claims of a year against the quota, and the optional holidays still open to them
+// @Tags holidays
+// @Produce json
+// @Param employee_id path string true "Employee ID"
+// @Param year query int false "Calendar year, the current one unless set"
+// @Success 200 {object} domain.OptionalHolidayClaims
+// @Failure 403 {object} ErrorResponse
+// @Router /employees/{employee_id}/optional-holidays [get]
+func (h *HolidayHandler) ListOptionalClaims(c *gin.Context) {
+	orgID, employeeID, _, ok := optionalHolidayCaller(c)
+	if !ok {
+		return
+	}
+
+	var year int
+	if v := c.Query("year"); v != "" {
+		var err error
+		if year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	claims, err := h.leaveService.ListOptionalHolidayClaims(orgID, employeeID, year, c.GetHeader("Authorization"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
 }
 
+// holidayChange reads who changes a holiday and whether they only preview
+// its impact on leave, responding and returning false when either is
+// invalid
+func holidayChange(c *gin.Context) (*domain.HolidayChange, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	change := &domain.HolidayChange{
+		PerformedBy: userID,
+		AuthToken:   c.GetHeader("Authorization"),
+	}
+	if v := c.Query("preview_impact"); v != "" {
+		if change.PreviewImpact, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid preview_impact"})
+			return nil, false
+		}
+	}
+	return change, true
+}
+
+// optionalHolidayCaller reads the organization, employee and caller of an
+// optional holiday route, responding and returning false when the caller
+// is neither the employee nor a manager or admin
+func optionalHolidayCaller(c *gin.Context) (orgID, employeeID, userID uuid.UUID, ok bool) {
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	orgID, err = uuid.Parse(c.GetString("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authenticated organization not found"})
+		return
+	}
+
+	userID, err = currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID != employeeID && !hasRole(c, domain.RoleManager, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to manage this employee's optional holidays"})
+		return
+	}
+	return orgID, employeeID, userID, true
+}
+
+// @Summary Holiday calendar
+// @Description The organization's holidays of a year grouped by month, all twelve months included, with counts, plus the same holidays as a flat list. Each holiday says whether it falls on a weekend. An empty calendar returns empty months.
+// @Tags holidays
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param year query int false "Calendar year, the current one unless set"
+// @Param location query string false "Only holidays observed at this location, organization-wide ones included"
+// @Success 200 {object} domain.HolidayCalendar
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/calendar [get]
 func (h *HolidayHandler) GetCalendarView(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	year, ok := h.calendarYear(c, orgID)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.leaveService.GetHolidayCalendar(orgID, year, c.Query("location"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// @Summary Holiday calendar as iCalendar
+// @Description The organization's holidays of a year as an iCalendar feed of all-day events for Outlook, Google Calendar and the like. Each event keeps its holiday's UID, so re-imports update rather than duplicate. Besides the bearer token, the organization's calendar token is accepted as the token query parameter so calendar apps can poll without signing in.
+// @Tags holidays
+// @Produce text/calendar
+// @Param organization_id path string true "Organization ID"
+// @Param year query int false "Calendar year, the current one unless set"
+// @Param location query string false "Only holidays observed at this location, organization-wide ones included"
+// @Param token query string false "Calendar token instead of the Authorization header"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/calendar.ics [get]
+func (h *HolidayHandler) ExportICS(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	year, ok := h.calendarYear(c, orgID)
+	if !ok {
+		return
+	}
+
+	location := c.Query("location")
+	holidays, err := h.leaveService.ListHolidays(orgID, &domain.ListHolidaysParams{Year: year, Location: location})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := fmt.Sprintf("Holidays %d", year)
+	if location != "" {
+		name += " - " + location
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="holidays-%d.ics"`, year))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", domain.HolidayICS(name, holidays))
+}
+
+// @Summary Holiday calendar token
+// @Description The organization's calendar token and the ICS feed path to subscribe to with it (admin only). The token does not expire; rotating the service's calendar secret revokes it.
+// @Tags holidays
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.HolidayCalendarToken
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /organizations/{organization_id}/holidays/calendar-token [get]
+func (h *HolidayHandler) CalendarToken(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can issue calendar tokens"})
+		return
+	}
+
+	if h.calendarTokenSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "calendar tokens are not configured"})
+		return
+	}
+
+	token := middleware.SignCalendarToken(h.calendarTokenSecret, orgID)
+	c.JSON(http.StatusOK, domain.HolidayCalendarToken{
+		Token: token,
+		URL: fmt.Sprintf("/api/v1/organizations/%s/holidays/calendar.ics?%s=%s",
+			orgID, middleware.CalendarTokenQuery, token),
+	})
+}
+
+// calendarYear reads the year query of a holiday calendar, the current
+// year of the organization unless set, responding and returning false when
+// it is invalid
+func (h *HolidayHandler) calendarYear(c *gin.Context, orgID uuid.UUID) (int, bool) {
+	if v := c.Query("year"); v != "" {
+		year, err := strconv.Atoi(v)
+		if err != nil || year <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return 0, false
+		}
+		return year, true
+	}
+
+	today, err := h.leaveService.Today(orgID)
+	if err != nil {
+		c.Error(err)
+		return 0, false
+	}
+	return today.Year(), true
 }
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type JobHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewJobHandler(leaveService service.LeaveService) *JobHandler {
+	return &JobHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary Get background job
+// @Description Status and progress of a background job such as a yearly reset (admin only). Once completed, result holds the job's outcome; once failed, error says why.
+// @Tags jobs
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.Job
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/jobs/{id} [get]
+func (h *JobHandler) Get(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can view jobs"})
+		return
+	}
+
+	job, err := h.leaveService.GetJob(orgID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
 	"github.com/Axontik/comin-leave-management-service/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type LeaveBalanceHandler struct {
@@ -15,22 +21,1294 @@ func NewLeaveBalanceHandler(leaveService service.LeaveService) *LeaveBalanceHand
 	}
 }
 
+// @Summary List leave balances
+// @Description Balances of every employee in the organization for a year, ordered by employee
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param year query integer false "Balance year (default current year)"
+// @Param leave_type_id query string false "Filter by leave type"
+// @Param employee_id query string false "Filter by employee"
+// @Param page query integer false "Page number"
+// @Param page_size query integer false "Page size"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances [get]
 func (h *LeaveBalanceHandler) List(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can list organization balances"})
+		return
+	}
+
+	params := &domain.ListLeaveBalancesParams{
+		Page:     1,
+		PageSize: 10,
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if year := c.Query("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+		params.Year = y
+	}
+
+	if leaveTypeID := c.Query("leave_type_id"); leaveTypeID != "" {
+		id, err := uuid.Parse(leaveTypeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave type id"})
+			return
+		}
+		params.LeaveTypeID = &id
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+	}
+
+	balances, total, err := h.leaveService.ListOrgLeaveBalances(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: balances,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
 }
 
+// @Summary Get an employee's leave balances
+// @Description Balances of the employee for a year, with remaining days as total less used and pending. Employees without balances get an empty list.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param employee_id path string true "Employee ID"
+// @Param year query integer false "Balance year (default current year)"
+// @Param include_history query boolean false "Embed each balance's latest adjustments"
+// @Success 200 {array} domain.LeaveBalanceResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/{employee_id} [get]
+// @Router /employees/{employee_id}/leave-balance [get]
 func (h *LeaveBalanceHandler) GetByEmployee(c *gin.Context) {
-	// Implementation
+	orgID, err := requestOrganizationID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID != employeeID && !hasRole(c, domain.RoleManager, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to view this employee's leave balances"})
+		return
+	}
+
+	var year int
+	if y := c.Query("year"); y != "" {
+		year, err = strconv.Atoi(y)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	balances, err := h.leaveService.ListEmployeeBalances(orgID, employeeID, year, c.Query("include_history") == "true")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
 }
 
+// @Summary Leave history summary
+// @Description Entitled, taken, carried-over and forfeited days and utilization per leave year, newest first, for up to five years. Years without a balance are reconstructed from approved requests and flagged.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param employee_id path string true "Employee ID"
+// @Success 200 {object} domain.LeaveHistorySummary
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/employees/{employee_id}/leave-history-summary [get]
+func (h *LeaveBalanceHandler) GetLeaveHistorySummary(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID != employeeID && !hasRole(c, domain.RoleManager, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to view this employee's leave history"})
+		return
+	}
+
+	summary, err := h.leaveService.GetLeaveHistorySummary(orgID, employeeID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// @Summary Adjust a leave balance
+// @Description Records a manual change to a balance's total, pending approval unless an admin sets auto_approve. With an effective_date after today, in the balance's year, the approved adjustment is scheduled and applied on that day. Deductions may not take the remaining days below zero unless the leave type allows a negative balance, and additions may not bank past the leave type's max_balance_days unless an admin sets override_cap.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param adjustment body domain.CreateBalanceAdjustmentRequest true "Adjustment details"
+// @Success 201 {object} domain.LeaveBalanceAdjustment
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjust [post]
 func (h *LeaveBalanceHandler) AdjustBalance(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can adjust leave balances"})
+		return
+	}
+
+	var req domain.CreateBalanceAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.PerformedBy = userID
+	req.CallerRole = c.GetString("role")
+
+	adjustment, err := h.leaveService.CreateBalanceAdjustment(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, adjustment)
+}
+
+// @Summary List balance adjustments
+// @Description Adjustments across the organization's balances, oldest first, each with its balance and leave type. Lists the pending ones unless status is set, so it doubles as the review queue. performed_by and approved_by are user ids.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param status query string false "pending (default), approved, rejected, reverted, correction or all"
+// @Param employee_id query string false "Filter by employee"
+// @Param from query string false "Made on or after date (YYYY-MM-DD)"
+// @Param to query string false "Made on or before date (YYYY-MM-DD)"
+// @Param page query integer false "Page number"
+// @Param page_size query integer false "Page size"
+// @Success 200 {object} ListResponse{data=[]domain.LeaveBalanceAdjustment}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments [get]
+func (h *LeaveBalanceHandler) ListAdjustments(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can list balance adjustments"})
+		return
+	}
+
+	params := &domain.ListBalanceAdjustmentsParams{
+		Page:     1,
+		PageSize: 10,
+		Status:   c.Query("status"),
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		params.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+		params.To = &t
+	}
+
+	adjustments, total, err := h.leaveService.ListOrgBalanceAdjustments(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: adjustments,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Approve a balance adjustment
+// @Description Approves a pending adjustment and applies it to the balance's total. Managers cannot approve their own adjustments. Returns 409 once the adjustment is no longer pending, so a retried approval is applied once.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Adjustment ID"
+// @Param body body domain.BalanceAdjustmentActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveBalanceAdjustment
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments/{id}/approve [put]
+func (h *LeaveBalanceHandler) ApproveAdjustment(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adjustment id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can approve balance adjustments"})
+		return
+	}
+
+	var req domain.BalanceAdjustmentActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adjustment, err := h.leaveService.ApproveBalanceAdjustment(orgID, id, userID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// @Summary Reject a balance adjustment
+// @Description Rejects a pending adjustment, leaving the balance unchanged. Managers cannot reject their own adjustments.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Adjustment ID"
+// @Param body body domain.BalanceAdjustmentActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveBalanceAdjustment
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments/{id}/reject [put]
+func (h *LeaveBalanceHandler) RejectAdjustment(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adjustment id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can reject balance adjustments"})
+		return
+	}
+
+	var req domain.BalanceAdjustmentActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adjustment, err := h.leaveService.RejectBalanceAdjustment(orgID, id, userID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// @Summary Revert a balance adjustment
+// @Description Takes an approved adjustment's change back off the balance and marks it reverted (admin only). The reversal is recorded as a correction adjustment, which is returned. Taking back added days fails like a deduction when too few are left. Returns 409 unless the adjustment is approved; rejected and reverted adjustments are final.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Adjustment ID"
+// @Param body body domain.BalanceAdjustmentActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveBalanceAdjustment
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments/{id}/revert [put]
+func (h *LeaveBalanceHandler) RevertAdjustment(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adjustment id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can revert balance adjustments"})
+		return
+	}
+
+	var req domain.BalanceAdjustmentActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	correction, err := h.leaveService.RevertBalanceAdjustment(orgID, id, userID, req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, correction)
 }
 
+// @Summary Balance history
+// @Description Ledger of one balance, oldest first: approved adjustments, leave taken and leave given back by cancellations, each with the running balance. reconciled is false when the ledger does not add up to the stored balance.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param employee_id path string true "Employee ID"
+// @Param leave_type_id query string true "Leave type of the balance"
+// @Param year query integer false "Balance year (default current year)"
+// @Param page query integer false "Page number"
+// @Param page_size query integer false "Page size (default 50, max 100)"
+// @Success 200 {object} ListResponse{data=domain.BalanceLedger}
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/history/{employee_id} [get]
 func (h *LeaveBalanceHandler) GetBalanceHistory(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID != employeeID && !hasRole(c, domain.RoleManager, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to view this employee's leave balances"})
+		return
+	}
+
+	leaveTypeID, err := uuid.Parse(c.Query("leave_type_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leave_type_id is required"})
+		return
+	}
+
+	var year int
+	if y := c.Query("year"); y != "" {
+		if year, err = strconv.Atoi(y); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	page, pageSize := 1, 50
+	if v := c.Query("page"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			page = n
+		}
+	}
+	if v := c.Query("page_size"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			pageSize = n
+		}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	ledger, total, err := h.leaveService.GetBalanceHistory(orgID, employeeID, leaveTypeID, year, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: ledger,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       page,
+			PageSize:   pageSize,
+			TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
 }
 
+// @Summary Initialize a new hire's balances
+// @Description Opens the employee's balances of the current leave year for every paid leave type that tracks a balance, pro-rating the default days by what is left of the year from the hire date. The hire date defaults to the start date known to the organization service. Balances that already exist are returned unchanged.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param employee_id path string true "Employee ID"
+// @Param body body domain.InitializeBalancesRequest false "Hire date"
+// @Success 200 {object} domain.BalanceInitialization
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/employees/{employee_id}/leave-balances/initialize [post]
+func (h *LeaveBalanceHandler) InitializeEmployeeBalances(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can initialize balances"})
+		return
+	}
+
+	var req domain.InitializeBalancesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	req.AuthToken = c.GetHeader("Authorization")
+
+	result, err := h.leaveService.InitializeEmployeeBalances(orgID, employeeID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Open next year's balances
+// @Description Queues a job opening the year's balances for every active employee and paid leave type that tracks a balance, at the type's entitlement plus the unused days it lets carry over. Provisional balances opened by requests filed ahead of the reset are adopted and their totals recomputed. The previous year's balances are closed. Poll the returned job for progress; its result is the reset summary. Safe to rerun: existing balances are skipped and closed balances are not carried again. Each balance is snapshotted once per run, see the snapshots listing.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param body body domain.YearlyResetRequest true "Year to open"
+// @Success 202 {object} domain.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/yearly-reset [post]
 func (h *LeaveBalanceHandler) YearlyReset(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can run the yearly reset"})
+		return
+	}
+
+	var req domain.YearlyResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.AuthToken = c.GetHeader("Authorization")
+	req.RequestedBy = userID
+
+	job, err := h.leaveService.StartYearlyReset(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// @Summary List balance snapshots
+// @Description Lists the balances as yearly reset runs left them, newest run first. Snapshots are written by the reset and never change; filter by run_id, the reset job's id, and by employee_id.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param run_id query string false "Yearly reset job ID"
+// @Param employee_id query string false "Employee ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/snapshots [get]
+func (h *LeaveBalanceHandler) ListSnapshots(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only HR and admins can list balance snapshots"})
+		return
+	}
+
+	params := &domain.ListBalanceSnapshotsParams{
+		Page:     1,
+		PageSize: 10,
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if runID := c.Query("run_id"); runID != "" {
+		id, err := uuid.Parse(runID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run id"})
+			return
+		}
+		params.RunID = &id
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+	}
+
+	snapshots, total, err := h.leaveService.ListBalanceSnapshots(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: snapshots,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Expire carried-over days
+// @Description Forfeits the unused carried-over days of balances whose carry-over expired before today in the organization's time zone, recording a system adjustment for each. Safe to rerun.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.CarryOverExpirySummary
+// @Router /organizations/{organization_id}/leave-balances/expire-carryover [post]
+func (h *LeaveBalanceHandler) ExpireCarryOver(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can expire carried-over days"})
+		return
+	}
+
+	summary, err := h.leaveService.ExpireCarryOver(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// @Summary Accrue leave
+// @Description Grants a period's accruals to the balances of leave types that accrue monthly or quarterly, recording a system adjustment for each. The period defaults to the current month in the organization's time zone. Safe to rerun: a period is accrued once per employee and leave type.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param body body domain.AccrueLeaveRequest false "Period to accrue, YYYY-MM"
+// @Success 200 {object} domain.AccrualSummary
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/accrue [post]
+func (h *LeaveBalanceHandler) AccrueLeave(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can run accruals"})
+		return
+	}
+
+	var req domain.AccrueLeaveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	summary, err := h.leaveService.AccrueLeave(orgID, req.Period)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// @Summary Recalculate leave balances
+// @Description Recalculates the organization's balances of a year from their source records: used days from approved requests, pending days from pending ones and the total from the opening entitlement, carried-over days and approved adjustments, accruals included. Reports every balance that differs. With apply=true a job is queued instead that fixes each one, a correction adjustment by the system user documenting the change; poll it for the report.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param employee_id query string false "Only this employee's balances"
+// @Param year query int false "Leave year, defaults to the current one"
+// @Param apply query bool false "Fix the discrepancies found"
+// @Success 200 {object} domain.BalanceRecalculation
+// @Success 202 {object} domain.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/recalculate [post]
+func (h *LeaveBalanceHandler) RecalculateBalances(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can recalculate balances"})
+		return
+	}
+
+	var params domain.RecalculateBalancesParams
+	if v := c.Query("employee_id"); v != "" {
+		employeeID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &employeeID
+	}
+	if v := c.Query("year"); v != "" {
+		if params.Year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+	if v := c.Query("apply"); v != "" {
+		if params.Apply, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid apply flag"})
+			return
+		}
+	}
+
+	if params.Apply {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		job, err := h.leaveService.StartBalanceRecalculation(orgID, userID, params)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	result, err := h.leaveService.RecalculateBalances(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Run scheduled accruals
+// @Description Runs the current month's accruals of every organization with a leave type that accrues. Meant to be called by a scheduler, e.g. daily; reruns within a month change nothing.
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Admin-Token header string true "Internal admin token"
+// @Success 200 {array} domain.AccrualSummary
+// @Router /internal/accruals [post]
+func (h *LeaveBalanceHandler) RunScheduledAccruals(c *gin.Context) {
+	summaries, err := h.leaveService.AccrueAllOrganizations()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// @Summary List balance adjustment changes
+// @Description Adjustments created or updated after the (since, since_id) watermark, ordered by updated_at then id
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param since query string false "RFC3339 watermark timestamp"
+// @Param since_id query string false "Watermark tie-breaker ID"
+// @Param limit query integer false "Maximum rows (default 100, capped at 1000)"
+// @Success 200 {object} ChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/changes [get]
+func (h *LeaveBalanceHandler) ListAdjustmentChanges(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	params, err := parseChangesParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adjustments, err := h.leaveService.ListBalanceAdjustmentChanges(orgID, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := ChangesResponse{
+		Data:        adjustments,
+		NextSince:   params.Since,
+		NextSinceID: params.SinceID,
+		HasMore:     len(adjustments) == params.Limit,
+	}
+	if len(adjustments) > 0 {
+		last := adjustments[len(adjustments)-1]
+		resp.NextSince = last.UpdatedAt
+		resp.NextSinceID = last.ID
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Transfer balance between leave types
+// @Description Moves days, or all days neither used nor pending, from an employee's balance of one leave type to another as two linked adjustments
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param transfer body domain.BalanceTransferRequest true "Transfer details"
+// @Success 201 {object} domain.BalanceTransfer
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/transfer [post]
+func (h *LeaveBalanceHandler) Transfer(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can transfer leave balances"})
+		return
+	}
+
+	var req domain.BalanceTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.PerformedBy = userID
+
+	transfer, err := h.leaveService.TransferLeaveBalance(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// @Summary Transfer balances for many employees
+// @Description Runs the same transfer for the listed employees, or every employee with a balance of the source type, as a background job
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param transfer body domain.BulkBalanceTransferRequest true "Transfer details"
+// @Success 202 {object} domain.BalanceTransferJob
+// @Router /organizations/{organization_id}/leave-balances/transfer/bulk [post]
+func (h *LeaveBalanceHandler) BulkTransfer(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can transfer leave balances"})
+		return
+	}
+
+	var req domain.BulkBalanceTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.PerformedBy = userID
+
+	job, err := h.leaveService.StartBulkBalanceTransfer(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// @Summary Get a bulk balance transfer job
+// @Description Progress counts of a bulk transfer and the employees whose transfer failed
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Job ID"
+// @Success 200 {object} domain.BalanceTransferJob
+// @Router /organizations/{organization_id}/leave-balances/transfer-jobs/{id} [get]
+func (h *LeaveBalanceHandler) GetTransferJob(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can view balance transfer jobs"})
+		return
+	}
+
+	job, err := h.leaveService.GetBalanceTransferJob(orgID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// @Summary Get the balance anomaly alert
+// @Description The organization's active alert, raised when a single balance change or the rate of balance writes exceeds the thresholds in the settings
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.BalanceAnomaly
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/anomaly [get]
+func (h *LeaveBalanceHandler) GetAnomaly(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can view balance anomaly alerts"})
+		return
+	}
+
+	anomaly, err := h.leaveService.GetBalanceAnomaly(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// @Summary Clear the balance anomaly alert
+// @Description Dismisses the active alert once the cause is understood, letting adjustments be approved on creation and transfers run again
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.BalanceAnomaly
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/anomaly [delete]
+func (h *LeaveBalanceHandler) ClearAnomaly(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can clear balance anomaly alerts"})
+		return
+	}
+
+	anomaly, err := h.leaveService.ClearBalanceAnomaly(orgID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// @Summary Cancel a scheduled balance adjustment
+// @Description Calls off an approved adjustment waiting for its effective date, leaving the balance as it is. Returns 409 unless the adjustment is scheduled.
+// @Tags leave-balances
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Adjustment ID"
+// @Param body body domain.BalanceAdjustmentActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveBalanceAdjustment
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments/{id}/cancel [put]
+func (h *LeaveBalanceHandler) CancelAdjustment(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adjustment id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can cancel balance adjustments"})
+		return
+	}
+
+	var req domain.BalanceAdjustmentActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	adjustment, err := h.leaveService.CancelBalanceAdjustment(orgID, id, userID, req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// @Summary Apply due scheduled balance adjustments
+// @Description Applies the organization's scheduled adjustments effective on or before today (admin only). Each applies once, however often this runs; failures stay scheduled for the next run.
+// @Tags leave-balances
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.ScheduledAdjustmentsApplication
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments/apply-due [post]
+func (h *LeaveBalanceHandler) ApplyDueAdjustments(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can apply scheduled balance adjustments"})
+		return
+	}
+
+	result, err := h.leaveService.ApplyDueBalanceAdjustments(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Bulk-adjust leave balances from a CSV file
+// @Description Records one balance adjustment per row of an uploaded CSV, attributed to the uploader. The header names the columns: employee_id or email, leave_type (name or id), adjustment and reason. Rows are validated and applied one by one against the balances of the year, the current one unless set, so bad rows fail on their own; the report gives the outcome of every row by its line in the file. Adjustments stay pending unless an admin sets auto_approve, and rows banking past the leave type's cap fail unless an admin sets override_cap. Looking employees up by email needs the organization service.
+// @Tags leave-balances
+// @Accept multipart/form-data
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param file formData file true "CSV file"
+// @Param year formData int false "Balance year"
+// @Param auto_approve formData bool false "Approve the adjustments at once (admin only)"
+// @Param override_cap formData bool false "Let the adjustments bank past the leave type's cap (admin only)"
+// @Success 200 {object} domain.BulkAdjustmentReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-balances/adjustments/bulk [post]
+func (h *LeaveBalanceHandler) BulkAdjust(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can adjust leave balances"})
+		return
+	}
+
+	req := domain.BulkAdjustmentRequest{
+		PerformedBy: userID,
+		CallerRole:  c.GetString("role"),
+		AuthToken:   c.GetHeader("Authorization"),
+	}
+	if v := c.PostForm("year"); v != "" {
+		if req.Year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+	if v := c.PostForm("auto_approve"); v != "" {
+		if req.AutoApprove, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid auto_approve"})
+			return
+		}
+	}
+	if v := c.PostForm("override_cap"); v != "" {
+		if req.OverrideCap, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid override_cap"})
+			return
+		}
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a CSV file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	if req.Rows, err = domain.ParseBulkAdjustmentCSV(file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid CSV: " + err.Error()})
+		return
+	}
+
+	report, err := h.leaveService.BulkAdjustBalances(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
 }
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/Axontik/comin-leave-management-service/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// historySummaryService returns empty leave history summaries; every other
+// method is left unimplemented
+type historySummaryService struct {
+	service.LeaveService
+}
+
+func (s *historySummaryService) GetLeaveHistorySummary(orgID, employeeID uuid.UUID) (*domain.LeaveHistorySummary, error) {
+	return &domain.LeaveHistorySummary{EmployeeID: employeeID, Years: []domain.LeaveHistoryYear{}}, nil
+}
+
+func TestLeaveBalanceHandlerHistorySummaryAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	employeeID := uuid.New()
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		role   string
+		want   int
+	}{
+		{name: "own summary", userID: employeeID, role: "employee", want: http.StatusOK},
+		{name: "another employee's", userID: uuid.New(), role: "employee", want: http.StatusForbidden},
+		{name: "hr", userID: uuid.New(), role: domain.RoleHR, want: http.StatusForbidden},
+		{name: "manager", userID: uuid.New(), role: domain.RoleManager, want: http.StatusOK},
+		{name: "admin", userID: uuid.New(), role: domain.RoleAdmin, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				auth.SetCurrentUser(c, &auth.UserResponse{ID: tt.userID.String()})
+				c.Set("role", tt.role)
+			})
+			router.GET("/organizations/:organization_id/employees/:employee_id/leave-history-summary",
+				NewLeaveBalanceHandler(&historySummaryService{}).GetLeaveHistorySummary)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+				"/organizations/"+uuid.New().String()+"/employees/"+employeeID.String()+"/leave-history-summary", nil))
+			if w.Code != tt.want {
+				t.Errorf("got %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// snapshotService records the filter balance snapshots are listed with
+type snapshotService struct {
+	service.LeaveService
+	params *domain.ListBalanceSnapshotsParams
+}
+
+func (s *snapshotService) ListBalanceSnapshots(orgID uuid.UUID, params *domain.ListBalanceSnapshotsParams) ([]domain.BalanceSnapshot, int64, error) {
+	s.params = params
+	return []domain.BalanceSnapshot{}, 0, nil
+}
+
+func TestLeaveBalanceHandlerListSnapshots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runID := uuid.New()
+	employeeID := uuid.New()
+
+	tests := []struct {
+		name         string
+		role         string
+		query        string
+		want         int
+		wantRun      *uuid.UUID
+		wantEmployee *uuid.UUID
+	}{
+		{name: "by run and employee", role: domain.RoleHR, query: "?run_id=" + runID.String() + "&employee_id=" + employeeID.String(),
+			want: http.StatusOK, wantRun: &runID, wantEmployee: &employeeID},
+		{name: "unfiltered", role: domain.RoleAdmin, want: http.StatusOK},
+		{name: "invalid run", role: domain.RoleAdmin, query: "?run_id=latest", want: http.StatusBadRequest},
+		{name: "manager", role: domain.RoleManager, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &snapshotService{}
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				auth.SetCurrentUser(c, &auth.UserResponse{ID: uuid.NewString()})
+				c.Set("role", tt.role)
+			})
+			router.GET("/organizations/:organization_id/leave-balances/snapshots", NewLeaveBalanceHandler(svc).ListSnapshots)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+				"/organizations/"+uuid.New().String()+"/leave-balances/snapshots"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				if svc.params != nil {
+					t.Error("snapshots were listed for a rejected request")
+				}
+				return
+			}
+			if (svc.params.RunID == nil) != (tt.wantRun == nil) || (tt.wantRun != nil && *svc.params.RunID != *tt.wantRun) {
+				t.Errorf("run filter = %v, want %v", svc.params.RunID, tt.wantRun)
+			}
+			if (svc.params.EmployeeID == nil) != (tt.wantEmployee == nil) || (tt.wantEmployee != nil && *svc.params.EmployeeID != *tt.wantEmployee) {
+				t.Errorf("employee filter = %v, want %v", svc.params.EmployeeID, tt.wantEmployee)
+			}
+		})
+	}
+}
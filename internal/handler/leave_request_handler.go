@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Axontik/comin-leave-management-service/internal/domain"
 	"github.com/Axontik/comin-leave-management-service/internal/service"
@@ -37,25 +40,924 @@ func (h *LeaveRequestHandler) Create(c *gin.Context) {
 		return
 	}
 
+	req.AuthToken = c.GetHeader("Authorization")
+	req.CallerRole = c.GetString("role")
+
+	if req.BypassNotice && !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can bypass the notice period"})
+		return
+	}
+	if req.OverrideProbation && !hasRole(c, domain.RoleAdmin, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only HR and admins can override probation"})
+		return
+	}
+
 	leaveRequest, err := h.leaveService.CreateLeaveRequest(orgID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, leaveRequest)
 }
 
-// Add other leave request methods: List, GetByID, Update, Delete, Approve, Reject, Cancel
+// @Summary Approve leave request
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param body body domain.LeaveRequestActionRequest false "Approval comments"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/approve [put]
+func (h *LeaveRequestHandler) Approve(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	approverID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req domain.LeaveRequestActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	leaveRequest, err := h.leaveService.ApproveLeaveRequest(orgID, id, approverID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Reject leave request
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param body body domain.UpdateLeaveRequestRequest true "Rejection comments"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/reject [put]
+func (h *LeaveRequestHandler) Reject(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	rejectorID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req domain.UpdateLeaveRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Status != "" && req.Status != domain.LeaveStatusRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be rejected"})
+		return
+	}
+
+	leaveRequest, err := h.leaveService.RejectLeaveRequest(orgID, id, rejectorID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Cancel leave request
+// @Description Cancel a pending request or an approved request that has not started. Employees can cancel their own requests; otherwise only whoever may decide the request, admins included.
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param body body domain.LeaveRequestActionRequest false "Cancellation reason"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/cancel [put]
+func (h *LeaveRequestHandler) Cancel(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req domain.LeaveRequestActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	leaveRequest, err := h.leaveService.CancelLeaveRequest(orgID, id, userID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Withdraw own leave request
+// @Description With undo=true, a pending request nobody has acted on is deleted outright within the organization's undo window, releasing its pending days without leaving history (204). Otherwise, or once the window has passed, the request is cancelled as usual (200).
+// @Tags leave-requests
+// @Produce json
+// @Param id path string true "Leave Request ID"
+// @Param undo query bool false "Delete the request if it can still be undone"
+// @Success 200 {object} domain.LeaveRequest
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /me/leave-requests/{id} [delete]
+func (h *LeaveRequestHandler) WithdrawOwn(c *gin.Context) {
+	orgID, err := uuid.Parse(c.GetString("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authenticated organization not found"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaveRequest, err := h.leaveService.WithdrawLeaveRequest(orgID, id, userID, c.GetString("role"), c.Query("undo") == "true")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if leaveRequest == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Approve a requested cancellation
+// @Description Finalizes a late cancellation and returns the leave's days to the balance
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param body body domain.LeaveRequestActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/cancellation/approve [put]
+func (h *LeaveRequestHandler) ApproveCancellation(c *gin.Context) {
+	h.decideCancellation(c, h.leaveService.ApproveCancellation)
+}
+
+// @Summary Deny a requested cancellation
+// @Description Keeps the leave approved
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param body body domain.LeaveRequestActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/cancellation/deny [put]
+func (h *LeaveRequestHandler) DenyCancellation(c *gin.Context) {
+	h.decideCancellation(c, h.leaveService.DenyCancellation)
+}
+
+func (h *LeaveRequestHandler) decideCancellation(c *gin.Context,
+	decide func(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error)) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
 
+	approverID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req domain.LeaveRequestActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	leaveRequest, err := decide(orgID, id, approverID, c.GetString("role"), req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary List leave requests
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param status query string false "Filter by status"
+// @Param employee_id query string false "Filter by employee"
+// @Param leave_type_id query string false "Filter by leave type"
+// @Param from query string false "Overlapping on or after date (YYYY-MM-DD)"
+// @Param to query string false "Overlapping on or before date (YYYY-MM-DD)"
+// @Param page query integer false "Page number"
+// @Param page_size query integer false "Page size"
+// @Param external_ref query string false "Filter by external reference"
+// @Param external_system query string false "System of the external reference"
+// @Param include_deleted query boolean false "Include soft-deleted requests (admin only)"
+// @Success 200 {object} ListResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests [get]
+func (h *LeaveRequestHandler) List(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	params, err := parseListLeaveRequestsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+	}
+
+	if c.Query("include_deleted") == "true" {
+		if !hasRole(c, domain.RoleAdmin) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only admins can view deleted leave requests"})
+			return
+		}
+		params.IncludeDeleted = true
+	}
+
+	requests, total, err := h.leaveService.ListLeaveRequests(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: requests,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Get leave request by ID
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param include_deleted query boolean false "Include soft-deleted requests (admin only)"
+// @Success 200 {object} domain.LeaveRequestDetail
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id} [get]
+func (h *LeaveRequestHandler) GetByID(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	includeDeleted := c.Query("include_deleted") == "true"
+	if includeDeleted && !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can view deleted leave requests"})
+		return
+	}
+
+	leaveRequest, err := h.leaveService.GetLeaveRequest(orgID, id, includeDeleted)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Update pending leave request
+// @Description Change the dates, leave type or reason of a pending request. Employees can edit their own requests; whoever may decide a request, admins included, can edit it too. The edit is validated like a new request.
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param leave_request body domain.EditLeaveRequestRequest true "Leave Request Details"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id} [put]
+func (h *LeaveRequestHandler) Update(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req domain.EditLeaveRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.AuthToken = c.GetHeader("Authorization")
+	req.CallerRole = c.GetString("role")
+
+	if req.BypassNotice && !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers and admins can bypass the notice period"})
+		return
+	}
+	if req.OverrideProbation && !hasRole(c, domain.RoleAdmin, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only HR and admins can override probation"})
+		return
+	}
+
+	leaveRequest, err := h.leaveService.EditLeaveRequest(orgID, id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Delete leave request
+// @Description Soft-delete a rejected or cancelled request (admin only)
+// @Tags leave-requests
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Success 204 "No Content"
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id} [delete]
+func (h *LeaveRequestHandler) Delete(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can delete leave requests"})
+		return
+	}
+
+	if err := h.leaveService.DeleteLeaveRequest(orgID, id, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Restore leave request
+// @Description Restore a soft-deleted request (admin only)
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 409 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/restore [post]
+func (h *LeaveRequestHandler) Restore(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can restore leave requests"})
+		return
+	}
+
+	leaveRequest, err := h.leaveService.RestoreLeaveRequest(orgID, id, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Export leave request audit bundle
+// @Description Everything recorded about one request, deleted or not, in a single document for disputes (admin only): the request with its day breakdown, approvals and external references, and its full history oldest first. Each export is itself recorded in the history, so it is a POST and refused with 503 in read-only mode.
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Success 200 {object} domain.LeaveRequestAuditBundle
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/audit-bundle [post]
+func (h *LeaveRequestHandler) ExportAuditBundle(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can export audit bundles"})
+		return
+	}
+
+	bundle, err := h.leaveService.ExportLeaveRequestAuditBundle(orgID, id, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// @Summary Reopen leave request
+// @Description Move a request rejected or cancelled in the last 30 days back to pending (admin or manager). Balance and overlaps are checked again.
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param body body domain.LeaveRequestActionRequest false "Comments"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/reopen [post]
+func (h *LeaveRequestHandler) Reopen(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins and managers can reopen leave requests"})
+		return
+	}
+
+	var req domain.LeaveRequestActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	leaveRequest, err := h.leaveService.ReopenLeaveRequest(orgID, id, userID, req.Comments)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary Set external references
+// @Description Replaces the references linking a leave request to cases in other systems. References already used by other requests are reported as warnings.
+// @Tags leave-requests
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param id path string true "Leave Request ID"
+// @Param references body domain.UpdateExternalReferencesRequest true "External references"
+// @Success 200 {object} domain.LeaveRequest
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/{id}/external-references [put]
+func (h *LeaveRequestHandler) UpdateExternalReferences(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave request id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers, HR and admins can set external references"})
+		return
+	}
+
+	var req domain.UpdateExternalReferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaveRequest, err := h.leaveService.UpdateExternalReferences(orgID, id, req.ExternalReferences)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leaveRequest)
+}
+
+// @Summary List pending approvals
+// @Description Pending requests the current user may approve under each leave type's approval strategy
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {array} domain.LeaveRequest
+// @Router /organizations/{organization_id}/leave-requests/inbox [get]
+func (h *LeaveRequestHandler) Inbox(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	requests, err := h.leaveService.ListPendingApprovals(orgID, userID, c.GetString("role"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// @Summary List leave request changes
+// @Description Requests created or updated after the (since, since_id) watermark, ordered by updated_at then id
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param since query string false "RFC3339 watermark timestamp"
+// @Param since_id query string false "Watermark tie-breaker ID"
+// @Param limit query integer false "Maximum rows (default 100, capped at 1000)"
+// @Success 200 {object} ChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/changes [get]
+func (h *LeaveRequestHandler) ListChanges(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	params, err := parseChangesParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requests, err := h.leaveService.ListLeaveRequestChanges(orgID, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := ChangesResponse{
+		Data:        requests,
+		NextSince:   params.Since,
+		NextSinceID: params.SinceID,
+		HasMore:     len(requests) == params.Limit,
+	}
+	if len(requests) > 0 {
+		last := requests[len(requests)-1]
+		resp.NextSince = last.UpdatedAt
+		resp.NextSinceID = last.ID
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Team leave calendar
+// @Description Employees on approved or pending leave for each day of the range, plus holidays: those observed at location when set, every location's otherwise
+// @Tags leave-requests
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to the first of this month in the organization's time zone"
+// @Param to query string false "End date (YYYY-MM-DD), defaults to the end of the from month"
+// @Param location query string false "Only holidays observed at this location, organization-wide ones included"
+// @Success 200 {object} domain.LeaveCalendar
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/leave-requests/calendar [get]
 func (h *LeaveRequestHandler) GetCalendarView(c *gin.Context) {
-	// Implementation for calendar view
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	today, err := h.leaveService.Today(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+	}
+
+	to := from.AddDate(0, 1, -from.Day())
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+	}
+
+	calendar, err := h.leaveService.GetLeaveCalendar(orgID, from, to, c.Query("location"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
 }
 
 func (h *LeaveRequestHandler) GetEmployeeCalendar(c *gin.Context) {
 	// Implementation for employee calendar
 }
 
+// @Summary List an employee's leave requests
+// @Description Employees can list their own requests; managers and admins can list anyone's
+// @Tags leave-requests
+// @Produce json
+// @Param employee_id path string true "Employee ID"
+// @Param status query string false "Filter by status"
+// @Param year query integer false "Requests overlapping this year"
+// @Param page query integer false "Page number"
+// @Param page_size query integer false "Page size"
+// @Success 200 {object} ListResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /employees/{employee_id}/leave-requests [get]
 func (h *LeaveRequestHandler) ListByEmployee(c *gin.Context) {
-	// Implementation for listing by employee
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	orgID, err := uuid.Parse(c.GetString("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authenticated organization not found"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID != employeeID && !hasRole(c, domain.RoleManager, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to view this employee's leave requests"})
+		return
+	}
+
+	params, err := parseListLeaveRequestsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if year := c.Query("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+		from := time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC)
+		params.From = &from
+		params.To = &to
+	}
+
+	requests, total, err := h.leaveService.ListEmployeeLeaveRequests(orgID, employeeID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: requests,
+		Meta: MetaResponse{
+			Total:      total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// parseListLeaveRequestsParams reads the filter and pagination query params
+// shared by the leave request listings
+func parseListLeaveRequestsParams(c *gin.Context) (*domain.ListLeaveRequestsParams, error) {
+	params := &domain.ListLeaveRequestsParams{
+		Page:     1,
+		PageSize: 10,
+		Status:   c.Query("status"),
+	}
+
+	if page := c.Query("page"); page != "" {
+		if pageNum, err := strconv.Atoi(page); err == nil {
+			params.Page = pageNum
+		}
+	}
+
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if size, err := strconv.Atoi(pageSize); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	if leaveTypeID := c.Query("leave_type_id"); leaveTypeID != "" {
+		id, err := uuid.Parse(leaveTypeID)
+		if err != nil {
+			return nil, errors.New("invalid leave type id")
+		}
+		params.LeaveTypeID = &id
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return nil, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		params.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return nil, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		params.To = &t
+	}
+
+	params.ExternalRef = c.Query("external_ref")
+	params.ExternalSystem = c.Query("external_system")
+	if params.ExternalSystem != "" && params.ExternalRef == "" {
+		return nil, errors.New("external_system requires external_ref")
+	}
+
+	if backdated := c.Query("backdated"); backdated != "" {
+		b, err := strconv.ParseBool(backdated)
+		if err != nil {
+			return nil, errors.New("backdated must be true or false")
+		}
+		params.Backdated = &b
+	}
+
+	return params, nil
 }
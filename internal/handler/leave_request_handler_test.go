@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// changesFeedService serves ListLeaveRequestChanges from memory with the
+// repository's watermark semantics; every other method is left unimplemented
+type changesFeedService struct {
+	service.LeaveService
+	requests []domain.LeaveRequest
+	limits   []int
+}
+
+func (s *changesFeedService) ListLeaveRequestChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveRequest, error) {
+	if params.Limit < 1 {
+		params.Limit = domain.DefaultChangesLimit
+	}
+	s.limits = append(s.limits, params.Limit)
+
+	var page []domain.LeaveRequest
+	for _, r := range s.requests {
+		after := r.UpdatedAt.After(params.Since) ||
+			(r.UpdatedAt.Equal(params.Since) && r.ID.String() > params.SinceID.String())
+		if after && len(page) < params.Limit {
+			page = append(page, r)
+		}
+	}
+	return page, nil
+}
+
+// newChangesFeedService returns n requests, several sharing an updated_at,
+// in (updated_at, id) order
+func newChangesFeedService(n int) *changesFeedService {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := &changesFeedService{}
+	for i := 0; i < n; i++ {
+		r := domain.LeaveRequest{}
+		r.ID = uuid.New()
+		r.UpdatedAt = base.Add(time.Duration(i/3) * time.Second)
+		s.requests = append(s.requests, r)
+	}
+	sort.Slice(s.requests, func(i, j int) bool {
+		a, b := s.requests[i], s.requests[j]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return s
+}
+
+type changesPage struct {
+	Data        []domain.LeaveRequest `json:"data"`
+	NextSince   time.Time             `json:"next_since"`
+	NextSinceID uuid.UUID             `json:"next_since_id"`
+	HasMore     bool                  `json:"has_more"`
+}
+
+func getChangesPage(t *testing.T, router *gin.Engine, query url.Values) (int, *changesPage) {
+	t.Helper()
+	path := fmt.Sprintf("/organizations/%s/leave-requests/changes?%s", uuid.New(), query.Encode())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+	var page changesPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decoding page: %v", err)
+	}
+	return w.Code, &page
+}
+
+func TestListChangesWatermarkPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		rows      int
+		limit     int
+		wantPages int
+	}{
+		{name: "empty feed", rows: 0, limit: 5, wantPages: 1},
+		{name: "single partial page", rows: 4, limit: 5, wantPages: 1},
+		{name: "exact pages end with an empty page", rows: 10, limit: 5, wantPages: 3},
+		{name: "page boundary inside a shared timestamp", rows: 11, limit: 4, wantPages: 3},
+		{name: "one row per page", rows: 6, limit: 1, wantPages: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newChangesFeedService(tt.rows)
+			router := gin.New()
+			router.GET("/organizations/:organization_id/leave-requests/changes", NewLeaveRequestHandler(svc).ListChanges)
+
+			seen := make(map[uuid.UUID]int)
+			var order []uuid.UUID
+			query := url.Values{"limit": {fmt.Sprint(tt.limit)}}
+			pages := 0
+			for {
+				code, page := getChangesPage(t, router, query)
+				if code != http.StatusOK {
+					t.Fatalf("page %d: status %d", pages+1, code)
+				}
+				pages++
+				for _, r := range page.Data {
+					seen[r.ID]++
+					order = append(order, r.ID)
+				}
+				if !page.HasMore {
+					break
+				}
+				if pages > tt.rows+1 {
+					t.Fatal("feed did not terminate")
+				}
+				query.Set("since", page.NextSince.Format(time.RFC3339Nano))
+				query.Set("since_id", page.NextSinceID.String())
+			}
+
+			if pages != tt.wantPages {
+				t.Errorf("got %d pages, want %d", pages, tt.wantPages)
+			}
+			if len(order) != tt.rows {
+				t.Fatalf("got %d rows, want %d", len(order), tt.rows)
+			}
+			for i, r := range svc.requests {
+				if seen[r.ID] != 1 {
+					t.Errorf("row %s returned %d times", r.ID, seen[r.ID])
+				}
+				if order[i] != r.ID {
+					t.Errorf("row %d out of (updated_at, id) order", i)
+				}
+			}
+		})
+	}
+}
+
+func TestListChangesKeepsWatermarkOnEmptyPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newChangesFeedService(0)
+	router := gin.New()
+	router.GET("/organizations/:organization_id/leave-requests/changes", NewLeaveRequestHandler(svc).ListChanges)
+
+	since := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	sinceID := uuid.New()
+	_, page := getChangesPage(t, router, url.Values{
+		"since":    {since.Format(time.RFC3339Nano)},
+		"since_id": {sinceID.String()},
+	})
+	if !page.NextSince.Equal(since) || page.NextSinceID != sinceID || page.HasMore {
+		t.Errorf("got next_since=%v next_since_id=%v has_more=%v, want the request's watermark and no more",
+			page.NextSince, page.NextSinceID, page.HasMore)
+	}
+}
+
+func TestListChangesRejectsInvalidLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newChangesFeedService(3)
+	router := gin.New()
+	router.GET("/organizations/:organization_id/leave-requests/changes", NewLeaveRequestHandler(svc).ListChanges)
+
+	for _, limit := range []string{"abc", "0", "-5", "1.5"} {
+		code, _ := getChangesPage(t, router, url.Values{"limit": {limit}})
+		if code != http.StatusBadRequest {
+			t.Errorf("limit=%s: got status %d, want 400", limit, code)
+		}
+	}
+	if len(svc.limits) != 0 {
+		t.Errorf("service called %d times for invalid limits", len(svc.limits))
+	}
+}
+
+// externalReferencesService records the references it is asked to set;
+// every other method is left unimplemented
+type externalReferencesService struct {
+	service.LeaveService
+	refs []domain.ExternalReference
+}
+
+func (s *externalReferencesService) UpdateExternalReferences(orgID, id uuid.UUID, refs []domain.ExternalReference) (*domain.LeaveRequest, error) {
+	s.refs = refs
+	request := &domain.LeaveRequest{ExternalReferences: refs}
+	request.ID = id
+	return request, nil
+}
+
+func TestUpdateExternalReferencesRoles(t *testing.T) {
+	tests := []struct {
+		role string
+		want int
+	}{
+		{role: "employee", want: http.StatusForbidden},
+		{role: domain.RoleManager, want: http.StatusOK},
+		{role: domain.RoleHR, want: http.StatusOK},
+		{role: domain.RoleAdmin, want: http.StatusOK},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			svc := &externalReferencesService{}
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set("role", tt.role) })
+			router.PUT("/organizations/:organization_id/leave-requests/:id/external-references",
+				NewLeaveRequestHandler(svc).UpdateExternalReferences)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut,
+				fmt.Sprintf("/organizations/%s/leave-requests/%s/external-references", uuid.New(), uuid.New()),
+				strings.NewReader(`{"external_references":[{"system":"workday","reference":"HR-1042"}]}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if set := len(svc.refs) == 1; set != (tt.want == http.StatusOK) {
+				t.Errorf("references set = %t", set)
+			}
+		})
+	}
+}
@@ -44,15 +44,35 @@ func (h *LeaveTypeHandler) Create(c *gin.Context) {
 	}
 
 	leaveType := &domain.LeaveType{
-		OrganizationID:    orgID,
-		Name:              req.Name,
-		Description:       req.Description,
-		Color:             req.Color,
-		DefaultDays:       req.DefaultDays,
-		IsPaid:            req.IsPaid,
-		RequiresApproval:  req.RequiresApproval,
-		MinDaysNotice:     req.MinDaysNotice,
-		MaxDaysPerRequest: req.MaxDaysPerRequest,
+		OrganizationID:        orgID,
+		Name:                  req.Name,
+		Description:           req.Description,
+		Color:                 req.Color,
+		DefaultDays:           req.DefaultDays,
+		IsPaid:                req.IsPaid,
+		RequiresApproval:      req.RequiresApproval == nil || *req.RequiresApproval,
+		MinDaysNotice:         req.MinDaysNotice,
+		MaxDaysPerRequest:     req.MaxDaysPerRequest,
+		Rounding:              req.Rounding,
+		AllowNegativeBalance:  req.AllowNegativeBalance,
+		ApprovalStrategy:      req.ApprovalStrategy,
+		ApproverRole:          req.ApproverRole,
+		ApproverPoolID:        req.ApproverPoolID,
+		AllowBackdated:        req.AllowBackdated,
+		Unit:                  req.Unit,
+		TracksBalance:         req.TracksBalance == nil || *req.TracksBalance,
+		MaxNegativeDays:       req.MaxNegativeDays,
+		MinTenureDays:         req.MinTenureDays,
+		Eligibility:           req.Eligibility,
+		QuorumSize:            req.QuorumSize,
+		MaxCarryOverDays:      req.MaxCarryOverDays,
+		CarryOverExpiryMonths: req.CarryOverExpiryMonths,
+		AccrualFrequency:      req.AccrualFrequency,
+		AccrualRate:           req.AccrualRate,
+		MaxBalanceDays:        req.MaxBalanceDays,
+		AbsenceMonitored:      req.AbsenceMonitored,
+
+		BlockedDuringProbation: req.BlockedDuringProbation,
 	}
 
 	if err := h.leaveService.CreateLeaveType(leaveType); err != nil {
@@ -72,6 +92,7 @@ func (h *LeaveTypeHandler) Create(c *gin.Context) {
 // @Param page_size query integer false "Page size"
 // @Param name query string false "Filter by name"
 // @Param is_paid query boolean false "Filter by paid status"
+// @Param employee_id query string false "Only types this employee is eligible for"
 // @Success 200 {array} domain.LeaveType
 // @Router /organizations/{organization_id}/leave-types [get]
 func (h *LeaveTypeHandler) List(c *gin.Context) {
@@ -108,9 +129,19 @@ func (h *LeaveTypeHandler) List(c *gin.Context) {
 		}
 	}
 
+	if employeeID := c.Query("employee_id"); employeeID != "" {
+		id, err := uuid.Parse(employeeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &id
+		params.AuthToken = c.GetHeader("Authorization")
+	}
+
 	leaveTypes, total, err := h.leaveService.ListLeaveTypes(orgID, params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -183,16 +214,36 @@ func (h *LeaveTypeHandler) Update(c *gin.Context) {
 	}
 
 	leaveType := &domain.LeaveType{
-		ID:                id,
-		OrganizationID:    orgID,
-		Name:              req.Name,
-		Description:       req.Description,
-		Color:             req.Color,
-		DefaultDays:       req.DefaultDays,
-		IsPaid:            req.IsPaid,
-		RequiresApproval:  req.RequiresApproval,
-		MinDaysNotice:     req.MinDaysNotice,
-		MaxDaysPerRequest: req.MaxDaysPerRequest,
+		ID:                    id,
+		OrganizationID:        orgID,
+		Name:                  req.Name,
+		Description:           req.Description,
+		Color:                 req.Color,
+		DefaultDays:           req.DefaultDays,
+		IsPaid:                req.IsPaid,
+		RequiresApproval:      req.RequiresApproval == nil || *req.RequiresApproval,
+		MinDaysNotice:         req.MinDaysNotice,
+		MaxDaysPerRequest:     req.MaxDaysPerRequest,
+		Rounding:              req.Rounding,
+		AllowNegativeBalance:  req.AllowNegativeBalance,
+		ApprovalStrategy:      req.ApprovalStrategy,
+		ApproverRole:          req.ApproverRole,
+		ApproverPoolID:        req.ApproverPoolID,
+		AllowBackdated:        req.AllowBackdated,
+		Unit:                  req.Unit,
+		TracksBalance:         req.TracksBalance == nil || *req.TracksBalance,
+		MaxNegativeDays:       req.MaxNegativeDays,
+		MinTenureDays:         req.MinTenureDays,
+		Eligibility:           req.Eligibility,
+		QuorumSize:            req.QuorumSize,
+		MaxCarryOverDays:      req.MaxCarryOverDays,
+		CarryOverExpiryMonths: req.CarryOverExpiryMonths,
+		AccrualFrequency:      req.AccrualFrequency,
+		AccrualRate:           req.AccrualRate,
+		MaxBalanceDays:        req.MaxBalanceDays,
+		AbsenceMonitored:      req.AbsenceMonitored,
+
+		BlockedDuringProbation: req.BlockedDuringProbation,
 	}
 
 	if err := h.leaveService.UpdateLeaveType(leaveType); err != nil {
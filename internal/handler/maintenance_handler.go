@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Axontik/comin-leave-management-service/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceHandler struct {
+	readOnly *middleware.ReadOnlyMode
+}
+
+func NewMaintenanceHandler(readOnly *middleware.ReadOnlyMode) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		readOnly: readOnly,
+	}
+}
+
+// ReadOnlyRequest turns read-only mode on or off
+type ReadOnlyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// @Summary Get read-only mode
+// @Tags internal
+// @Produce json
+// @Param X-Internal-Admin-Token header string true "Internal admin token"
+// @Success 200 {object} map[string]bool
+// @Router /internal/read-only [get]
+func (h *MaintenanceHandler) GetReadOnly(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"read_only": h.readOnly.Enabled()})
+}
+
+// @Summary Set read-only mode
+// @Description Refuse or accept writes without a restart
+// @Tags internal
+// @Accept json
+// @Produce json
+// @Param X-Internal-Admin-Token header string true "Internal admin token"
+// @Param body body ReadOnlyRequest true "Mode"
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} ErrorResponse
+// @Router /internal/read-only [put]
+func (h *MaintenanceHandler) SetReadOnly(c *gin.Context) {
+	var req ReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if previous := h.readOnly.Set(*req.Enabled); previous != *req.Enabled {
+		log.Printf("Read-only mode changed from %t to %t", previous, *req.Enabled)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"read_only": *req.Enabled})
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenanceHandlerSetReadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		body        string
+		want        int
+		wantEnabled bool
+	}{
+		{name: "turn on", body: `{"enabled":true}`, want: http.StatusOK, wantEnabled: true},
+		{name: "turn off", body: `{"enabled":false}`, want: http.StatusOK},
+		{name: "missing enabled", body: `{}`, want: http.StatusBadRequest},
+		{name: "malformed", body: `{"enabled":`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := middleware.NewReadOnlyMode(false)
+			router := gin.New()
+			router.PUT("/internal/read-only", NewMaintenanceHandler(mode).SetReadOnly)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/internal/read-only", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("got %d (%s), want %d", w.Code, w.Body.String(), tt.want)
+			}
+			if mode.Enabled() != tt.wantEnabled {
+				t.Errorf("read-only mode is %v, want %v", mode.Enabled(), tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestMaintenanceHandlerGetReadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/internal/read-only", NewMaintenanceHandler(middleware.NewReadOnlyMode(true)).GetReadOnly)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/internal/read-only", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"read_only":true}` {
+		t.Errorf("got %d %s, want 200 with read_only true", w.Code, w.Body.String())
+	}
+}
@@ -1,28 +1,532 @@
 package handler
 
 import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/report"
 	"github.com/Axontik/comin-leave-management-service/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ReportHandler struct {
-	leaveService service.LeaveService
+	leaveService         service.LeaveService
+	leaveRegisterMaxRows int
 }
 
-func NewReportHandler(leaveService service.LeaveService) *ReportHandler {
+func NewReportHandler(leaveService service.LeaveService, leaveRegisterMaxRows int) *ReportHandler {
 	return &ReportHandler{
-		leaveService: leaveService,
+		leaveService:         leaveService,
+		leaveRegisterMaxRows: leaveRegisterMaxRows,
 	}
 }
 
+// @Summary Leave summary
+// @Description Requests starting in the period and their approved days, split into paid and unpaid leave. Day totals cover leave types counted in days.
+// @Tags reports
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param start_date query string false "First day, YYYY-MM-DD (default 30 days before end_date)"
+// @Param end_date query string false "Last day inclusive, YYYY-MM-DD (default today in the organization's time zone)"
+// @Success 200 {object} domain.LeaveStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/leave-summary [get]
 func (h *ReportHandler) LeaveSummary(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins and managers can view the leave summary"})
+		return
+	}
+
+	end, err := h.leaveService.Today(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if v := c.Query("end_date"); v != "" {
+		if end, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	start := end.AddDate(0, 0, -30)
+	if v := c.Query("start_date"); v != "" {
+		if start, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	summary, err := h.leaveService.LeaveSummary(orgID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
 }
 
+// @Summary Department analysis report
+// @Description Per department, most days taken first: requests starting in the period, their approved days and a breakdown by leave type. Day totals cover leave types counted in days. Departments come from the organization service; employees it does not place in one are grouped as unassigned, and when it cannot be reached the report is still returned with a warning.
+// @Tags reports
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param start_date query string false "First day, YYYY-MM-DD (default 30 days before end_date)"
+// @Param end_date query string false "Last day inclusive, YYYY-MM-DD (default today in the organization's time zone)"
+// @Success 200 {object} domain.DepartmentAnalysisReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/department-analysis [get]
 func (h *ReportHandler) DepartmentAnalysis(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins and managers can view the department analysis"})
+		return
+	}
+
+	end, err := h.leaveService.Today(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if v := c.Query("end_date"); v != "" {
+		if end, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	start := end.AddDate(0, 0, -30)
+	if v := c.Query("start_date"); v != "" {
+		if start, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	report, err := h.leaveService.DepartmentAnalysisReport(orgID, start, end.AddDate(0, 0, 1), c.GetHeader("Authorization"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
 }
 
+// @Summary Monthly trends report
+// @Description Approved leave per calendar month over the last months, the current one included, with zeros for months without leave. Days of requests spanning months count toward the month they fall in; day totals cover leave types counted in days. Each month's trend compares its days with the month before, within a 5% tolerance.
+// @Tags reports
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param months query int false "Number of months (default 12, at most 36)"
+// @Param leave_type_id query string false "Only leave of this type"
+// @Success 200 {object} domain.MonthlyTrendsReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/monthly-trends [get]
 func (h *ReportHandler) MonthlyTrends(c *gin.Context) {
-	// Implementation
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins and managers can view monthly trends"})
+		return
+	}
+
+	months := 12
+	if v := c.Query("months"); v != "" {
+		if months, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid months"})
+			return
+		}
+	}
+
+	var leaveTypeID *uuid.UUID
+	if v := c.Query("leave_type_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave_type_id"})
+			return
+		}
+		leaveTypeID = &id
+	}
+
+	report, err := h.leaveService.MonthlyTrendsReport(orgID, months, leaveTypeID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Leave register export
+// @Description Every leave request starting in the year as an XLSX workbook, for auditors (admin only): a summary sheet of per-type totals, then one sheet per leave type listing each request's dates, days or hours, status, approver and approval date. Exports listing more requests than the service allows are refused; export one leave type at a time instead.
+// @Tags reports
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param organization_id path string true "Organization ID"
+// @Param year query int false "Year (default the current one)"
+// @Param format query string false "Export format, only xlsx (default xlsx)"
+// @Param leave_type_id query string false "Only requests of this type"
+// @Success 200 {file} file "XLSX workbook"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/leave-register [get]
+func (h *ReportHandler) LeaveRegister(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can export the leave register"})
+		return
+	}
+
+	if format := c.DefaultQuery("format", "xlsx"); format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be xlsx"})
+		return
+	}
+
+	params := &domain.LeaveRegisterParams{MaxRows: h.leaveRegisterMaxRows}
+	if v := c.Query("year"); v != "" {
+		if params.Year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	} else {
+		today, err := h.leaveService.Today(orgID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		params.Year = today.Year()
+	}
+
+	if v := c.Query("leave_type_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave_type_id"})
+			return
+		}
+		params.LeaveTypeID = &id
+	}
+
+	register, err := h.leaveService.LeaveRegister(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The workbook is streamed, so once writing starts a failure can only
+	// cut the download short
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="leave-register-%d.xlsx"`, register.Year))
+	c.Status(http.StatusOK)
+	if err := h.leaveService.WriteLeaveRegister(c.Writer, register); err != nil {
+		log.Printf("Error: writing the %d leave register of organization %s: %v", register.Year, orgID, err)
+		c.Abort()
+	}
+}
+
+// @Summary Employee leave statement
+// @Description The employee's leave position for the year as a document to hand out, headed by the organization's name: balances per leave type (total, used, pending, remaining), the requests starting in the year with their statuses, and the adjustments to the year's balances with their reasons. Employees can download their own; HR and admins anyone's.
+// @Tags reports
+// @Produce application/pdf
+// @Param employee_id path string true "Employee ID"
+// @Param year query int false "Year (default the current one)"
+// @Param format query string false "Document format, only pdf (default pdf)"
+// @Success 200 {file} file "Leave statement"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /employees/{employee_id}/leave-statement [get]
+func (h *ReportHandler) LeaveStatement(c *gin.Context) {
+	employeeID, err := uuid.Parse(c.Param("employee_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
+
+	orgID, err := uuid.Parse(c.GetString("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authenticated organization not found"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID != employeeID && !hasRole(c, domain.RoleHR, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to view this employee's leave statement"})
+		return
+	}
+
+	renderer, err := report.ForFormat(c.DefaultQuery("format", report.FormatPDF))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var year int
+	if v := c.Query("year"); v != "" {
+		if year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	} else {
+		today, err := h.leaveService.Today(orgID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		year = today.Year()
+	}
+
+	statement, err := h.leaveService.LeaveStatement(orgID, employeeID, year, c.GetHeader("Authorization"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var document bytes.Buffer
+	if err := renderer.RenderLeaveStatement(&document, statement); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="leave-statement-%s-%d.%s"`,
+		employeeID, year, renderer.Extension()))
+	c.Data(http.StatusOK, renderer.ContentType(), document.Bytes())
+}
+
+// @Summary Bradford factor report
+// @Description Ranks employees by Bradford factor, S squared times D, for approved leave of the type in the 52 weeks ending on as_of: S is the number of separate spells, with requests that overlap, start the day after another ends or are apart only by weekends and organization-wide holidays counted as one, and D the days in the window. Scores are classified into the organization's bradford_bands settings. Employees without such leave are left out. JSON is paginated; format=csv returns every row.
+// @Tags reports
+// @Produce json
+// @Produce text/csv
+// @Param organization_id path string true "Organization ID"
+// @Param leave_type_id query string true "Leave type, e.g. sick leave"
+// @Param as_of query string false "Last day of the window, YYYY-MM-DD (default today in the organization's time zone)"
+// @Param format query string false "json or csv (default json)"
+// @Param page query integer false "Page number"
+// @Param page_size query integer false "Page size"
+// @Success 200 {object} ListResponse{data=domain.BradfordReport}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/bradford [get]
+func (h *ReportHandler) Bradford(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleHR) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins and HR can view Bradford scores"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	params := &domain.BradfordParams{Page: 1, PageSize: 10}
+	if params.LeaveTypeID, err = uuid.Parse(c.Query("leave_type_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leave_type_id is required and must be a valid id"})
+		return
+	}
+	if v := c.Query("as_of"); v != "" {
+		if params.AsOf, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "as_of must be a date in YYYY-MM-DD format"})
+			return
+		}
+	} else {
+		if params.AsOf, err = h.leaveService.Today(orgID); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if v := c.Query("page"); v != "" {
+		if page, err := strconv.Atoi(v); err == nil {
+			params.Page = page
+		}
+	}
+	if v := c.Query("page_size"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			params.PageSize = size
+		}
+	}
+
+	report, err := h.leaveService.BradfordReport(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="bradford-%s.csv"`, report.AsOf.Format("2006-01-02")))
+		c.Status(http.StatusOK)
+		if err := h.leaveService.WriteBradfordCSV(c.Writer, report); err != nil {
+			log.Printf("Error: writing the Bradford report of organization %s: %v", orgID, err)
+			c.Abort()
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Data: report,
+		Meta: MetaResponse{
+			Total:      report.Total,
+			Page:       params.Page,
+			PageSize:   params.PageSize,
+			TotalPages: (report.Total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		},
+	})
+}
+
+// @Summary Approver performance report
+// @Description Per approver: decisions, approval rate, median and p90 decision time in business hours, and overdue count. Auto-approved requests are excluded.
+// @Tags reports
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param start_date query string false "First day, YYYY-MM-DD (default 30 days before end_date)"
+// @Param end_date query string false "Last day inclusive, YYYY-MM-DD (default today in the organization's time zone)"
+// @Param exclude_backdated query bool false "Leave out decisions on backdated requests"
+// @Success 200 {object} domain.ApproverPerformanceReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/approver-performance [get]
+func (h *ReportHandler) ApproverPerformance(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can view approver performance"})
+		return
+	}
+
+	end, err := h.leaveService.Today(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if v := c.Query("end_date"); v != "" {
+		if end, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	start := end.AddDate(0, 0, -30)
+	if v := c.Query("start_date"); v != "" {
+		if start, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	excludeBackdated := false
+	if v := c.Query("exclude_backdated"); v != "" {
+		if excludeBackdated, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid exclude_backdated, expected true or false"})
+			return
+		}
+	}
+
+	report, err := h.leaveService.ApproverPerformanceReport(orgID, start, end.AddDate(0, 0, 1), excludeBackdated)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Holiday impact per location
+// @Description Per location: the holidays its employees observe in the period, organization-wide ones included, and the working days they take out of it. The first entry, with an empty location, covers employees without one.
+// @Tags reports
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param start_date query string false "First day, YYYY-MM-DD (default January 1 of end_date's year)"
+// @Param end_date query string false "Last day inclusive, YYYY-MM-DD (default December 31 of the current year in the organization's time zone)"
+// @Success 200 {object} domain.HolidayImpactReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/reports/holiday-impact [get]
+func (h *ReportHandler) HolidayImpact(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin, domain.RoleManager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins and managers can view the holiday impact"})
+		return
+	}
+
+	today, err := h.leaveService.Today(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	end := time.Date(today.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+	if v := c.Query("end_date"); v != "" {
+		if end, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	start := time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	if v := c.Query("start_date"); v != "" {
+		if start, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	report, err := h.leaveService.HolidayImpactReport(orgID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
 }
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SettingsHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewSettingsHandler(leaveService service.LeaveService) *SettingsHandler {
+	return &SettingsHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary Get organization leave settings
+// @Tags settings
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} domain.OrganizationSettings
+// @Router /organizations/{organization_id}/settings [get]
+func (h *SettingsHandler) Get(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	settings, err := h.leaveService.GetOrganizationSettings(orgID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary Update organization leave settings
+// @Description Only the fields present in the body are changed
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param settings body domain.UpdateOrganizationSettingsRequest true "Settings"
+// @Success 200 {object} domain.OrganizationSettings
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /organizations/{organization_id}/settings [put]
+func (h *SettingsHandler) Update(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	if !hasRole(c, domain.RoleAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can update organization settings"})
+		return
+	}
+
+	var req domain.UpdateOrganizationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.leaveService.UpdateOrganizationSettings(orgID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
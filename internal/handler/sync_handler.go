@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SyncHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewSyncHandler(leaveService service.LeaveService) *SyncHandler {
+	return &SyncHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary Sync the current employee's leave data
+// @Description Changes since the sync token for the caller's requests, balances and holidays, with tombstones for deleted requests. An empty, invalid or expired token returns a full resync.
+// @Tags sync
+// @Produce json
+// @Param token query string false "Sync token from the previous response"
+// @Success 200 {object} domain.SyncResponse
+// @Router /me/sync [get]
+func (h *SyncHandler) Sync(c *gin.Context) {
+	orgID, err := uuid.Parse(c.GetString("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authenticated organization not found"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.leaveService.SyncEmployee(orgID, userID, c.GetString("role"), c.Query("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WorkingDaysHandler struct {
+	leaveService service.LeaveService
+}
+
+func NewWorkingDaysHandler(leaveService service.LeaveService) *WorkingDaysHandler {
+	return &WorkingDaysHandler{
+		leaveService: leaveService,
+	}
+}
+
+// @Summary Suggest leave end date
+// @Description Walk forward over weekends and holidays to find the end and return-to-work dates, and the days a request over them would be charged
+// @Tags working-days
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param start query string true "Start date (YYYY-MM-DD)"
+// @Param days query number true "Working days, in steps of 0.5"
+// @Param location query string false "Skip the holidays observed at this location too, not only organization-wide ones; defaults to the employee's location"
+// @Param employee_id query string false "Count as the employee's request would be, skipping the optional holidays they claimed"
+// @Success 200 {object} domain.WorkingDaysResult
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/working-days/add [get]
+func (h *WorkingDaysHandler) Add(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", c.Query("start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	days, err := strconv.ParseFloat(c.Query("days"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number"})
+		return
+	}
+
+	params := &domain.AddWorkingDaysParams{
+		Start:     start,
+		Days:      days,
+		Location:  c.Query("location"),
+		AuthToken: c.GetHeader("Authorization"),
+	}
+	if v := c.Query("employee_id"); v != "" {
+		employeeID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+			return
+		}
+		params.EmployeeID = &employeeID
+	}
+
+	result, err := h.leaveService.AddWorkingDays(orgID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Organization calendar definition
+// @Description Working weekdays, holidays and leave-year boundaries for one year, as JSON or, with Accept: text/calendar, as an iCalendar VFREEBUSY. The ETag is the definition's version.
+// @Tags working-days
+// @Produce json
+// @Produce text/calendar
+// @Param organization_id path string true "Organization ID"
+// @Param year query integer false "Leave year, defaults to the current year"
+// @Param location query string false "Include the holidays observed at this location, not only organization-wide ones"
+// @Success 200 {object} domain.CalendarDefinition
+// @Success 304 "Not Modified"
+// @Failure 400 {object} ErrorResponse
+// @Router /organizations/{organization_id}/calendar-definition [get]
+func (h *WorkingDaysHandler) CalendarDefinition(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	year := time.Now().Year()
+	if v := c.Query("year"); v != "" {
+		if year, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	def, err := h.leaveService.GetCalendarDefinition(orgID, year, c.Query("location"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	etag := `"` + def.Version + `"`
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, "text/calendar") {
+	case "text/calendar":
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(def.ICal()))
+	default:
+		c.JSON(http.StatusOK, def)
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// calendarDefinitionService builds calendar definitions without holidays;
+// every other method is left unimplemented
+type calendarDefinitionService struct {
+	service.LeaveService
+	years []int
+}
+
+func (s *calendarDefinitionService) GetCalendarDefinition(orgID uuid.UUID, year int, location string) (*domain.CalendarDefinition, error) {
+	s.years = append(s.years, year)
+	return domain.NewCalendarDefinition(orgID, year, location, nil), nil
+}
+
+func TestWorkingDaysHandlerCalendarDefinition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uuid.New()
+	version := domain.NewCalendarDefinition(orgID, 2026, "", nil).Version
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		ifNoneMatch string
+		want        int
+		wantType    string
+		wantBody    string
+	}{
+		{name: "json", query: "?year=2026", want: http.StatusOK, wantType: "application/json", wantBody: `"working_weekdays":62`},
+		{name: "icalendar", query: "?year=2026", accept: "text/calendar", want: http.StatusOK, wantType: "text/calendar", wantBody: "BEGIN:VFREEBUSY"},
+		{name: "unchanged", query: "?year=2026", ifNoneMatch: `"` + version + `"`, want: http.StatusNotModified},
+		{name: "stale etag", query: "?year=2026", ifNoneMatch: `"0000"`, want: http.StatusOK, wantType: "application/json"},
+		{name: "invalid year", query: "?year=next", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/organizations/:organization_id/calendar-definition",
+				NewWorkingDaysHandler(&calendarDefinitionService{}).CalendarDefinition)
+
+			req := httptest.NewRequest(http.MethodGet, "/organizations/"+orgID.String()+"/calendar-definition"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusBadRequest {
+				return
+			}
+			if etag := w.Header().Get("ETag"); etag != `"`+version+`"` {
+				t.Errorf("ETag = %s, want %q", etag, version)
+			}
+			if !strings.HasPrefix(w.Header().Get("Content-Type"), tt.wantType) && tt.wantType != "" {
+				t.Errorf("Content-Type = %s, want %s", w.Header().Get("Content-Type"), tt.wantType)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %s", tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWorkingDaysHandlerCalendarDefinitionDefaultYear(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &calendarDefinitionService{}
+	router := gin.New()
+	router.GET("/organizations/:organization_id/calendar-definition", NewWorkingDaysHandler(svc).CalendarDefinition)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/organizations/"+uuid.New().String()+"/calendar-definition", nil))
+	if w.Code != http.StatusOK || len(svc.years) != 1 || svc.years[0] != time.Now().Year() {
+		t.Errorf("got %d for years %v, want the current year", w.Code, svc.years)
+	}
+}
+
+// addWorkingDaysService records the params it is asked with; every other
+// method is left unimplemented
+type addWorkingDaysService struct {
+	service.LeaveService
+	params *domain.AddWorkingDaysParams
+}
+
+func (s *addWorkingDaysService) AddWorkingDays(orgID uuid.UUID, params *domain.AddWorkingDaysParams) (*domain.WorkingDaysResult, error) {
+	s.params = params
+	return &domain.WorkingDaysResult{StartDate: params.Start, Days: params.Days, EndDate: params.Start}, nil
+}
+
+func TestWorkingDaysHandlerAdd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	employeeID := uuid.New()
+
+	tests := []struct {
+		name         string
+		query        string
+		want         int
+		wantEmployee *uuid.UUID
+		wantLocation string
+	}{
+		{name: "organization calendar", query: "start=2026-07-01&days=3", want: http.StatusOK},
+		{name: "at a location", query: "start=2026-07-01&days=3&location=LK", want: http.StatusOK, wantLocation: "LK"},
+		{name: "for an employee", query: "start=2026-07-01&days=2.5&employee_id=" + employeeID.String(), want: http.StatusOK, wantEmployee: &employeeID},
+		{name: "invalid employee", query: "start=2026-07-01&days=3&employee_id=me", want: http.StatusBadRequest},
+		{name: "invalid start", query: "start=01/07/2026&days=3", want: http.StatusBadRequest},
+		{name: "invalid days", query: "start=2026-07-01&days=three", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &addWorkingDaysService{}
+			router := gin.New()
+			router.GET("/organizations/:organization_id/working-days/add", NewWorkingDaysHandler(svc).Add)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+				"/organizations/"+uuid.New().String()+"/working-days/add?"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				if svc.params != nil {
+					t.Error("the service was called for an invalid query")
+				}
+				return
+			}
+			if svc.params.Location != tt.wantLocation {
+				t.Errorf("location = %q, want %q", svc.params.Location, tt.wantLocation)
+			}
+			if (svc.params.EmployeeID == nil) != (tt.wantEmployee == nil) ||
+				(tt.wantEmployee != nil && *svc.params.EmployeeID != *tt.wantEmployee) {
+				t.Errorf("employee = %v, want %v", svc.params.EmployeeID, tt.wantEmployee)
+			}
+		})
+	}
+}
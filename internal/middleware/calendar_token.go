@@ -0,0 +1,51 @@
+// internal/middleware/calendar_token.go
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CalendarTokenQuery is the query parameter calendar apps pass their
+// calendar token in
+const CalendarTokenQuery = "token"
+
+// SignCalendarToken returns the organization's calendar token. It does not
+// expire, so calendar apps can keep polling with it; rotating the secret
+// revokes every token.
+func SignCalendarToken(secret string, orgID uuid.UUID) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("holiday-calendar:" + orgID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CalendarAccess lets a request in with the calendar token of the
+// organization in its path, and otherwise hands it to authenticate. With no
+// secret configured calendar tokens are refused.
+func CalendarAccess(secret string, authenticate gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.Query(CalendarTokenQuery)
+		if provided == "" {
+			authenticate(c)
+			return
+		}
+
+		if secret == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "calendar tokens are disabled"})
+			return
+		}
+		orgID, err := uuid.Parse(c.Param("organization_id"))
+		if err != nil || !hmac.Equal([]byte(provided), []byte(SignCalendarToken(secret, orgID))) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid calendar token"})
+			return
+		}
+
+		c.Set("organization_id", orgID.String())
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+// internal/middleware/internal_admin.go
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalAdminTokenHeader carries the shared secret for internal endpoints
+const InternalAdminTokenHeader = "X-Internal-Admin-Token"
+
+// InternalAdmin guards operator-only endpoints with a shared token. With no
+// token configured the endpoints are disabled.
+func InternalAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "internal admin endpoints are disabled"})
+			return
+		}
+
+		provided := c.GetHeader(InternalAdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid internal admin token"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// InternalAdminUI guards the operator console like InternalAdmin, but also
+// accepts the token as the HTTP basic auth password so a browser can
+// prompt for it
+func InternalAdminUI(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		provided := c.GetHeader(InternalAdminTokenHeader)
+		if _, password, ok := c.Request.BasicAuth(); ok {
+			provided = password
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="leave management admin"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,67 @@
+// internal/middleware/read_only.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// readOnlyRetryAfter is the Retry-After hint, in seconds, sent with writes
+// refused during read-only mode
+const readOnlyRetryAfter = 60
+
+// ReadOnlyMode is a process-wide switch for maintenance windows. It is safe
+// to flip while requests are being served.
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	m := &ReadOnlyMode{}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// Enabled reports whether writes are currently refused
+func (m *ReadOnlyMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Set turns read-only mode on or off and reports the previous state
+func (m *ReadOnlyMode) Set(enabled bool) bool {
+	return m.enabled.Swap(enabled)
+}
+
+// ReadOnly refuses every request other than GET, HEAD and OPTIONS with 503
+// while the mode is enabled. Paths under exemptPrefixes, such as health
+// checks and the internal endpoint that turns the mode off, always pass.
+func ReadOnly(mode *ReadOnlyMode, exemptPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !mode.Enabled() {
+			c.Next()
+			return
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		for _, prefix := range exemptPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		c.Header("Retry-After", strconv.Itoa(readOnlyRetryAfter))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+			errors.NewReadOnlyError("the service is in read-only mode for maintenance, please retry later"))
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// newReadOnlyRouter answers every method on every path with 200 behind the
+// read-only middleware
+func newReadOnlyRouter(mode *ReadOnlyMode) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ReadOnly(mode, "/health", "/internal/"))
+	router.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		method  string
+		path    string
+		want    int
+	}{
+		{name: "off: write", method: http.MethodPost, path: "/api/v1/leave-requests", want: http.StatusOK},
+		{name: "on: read", enabled: true, method: http.MethodGet, path: "/api/v1/leave-requests", want: http.StatusOK},
+		{name: "on: head", enabled: true, method: http.MethodHead, path: "/api/v1/leave-requests", want: http.StatusOK},
+		{name: "on: options", enabled: true, method: http.MethodOptions, path: "/api/v1/leave-requests", want: http.StatusOK},
+		{name: "on: post", enabled: true, method: http.MethodPost, path: "/api/v1/leave-requests", want: http.StatusServiceUnavailable},
+		{name: "on: put", enabled: true, method: http.MethodPut, path: "/api/v1/leave-types/1", want: http.StatusServiceUnavailable},
+		{name: "on: delete", enabled: true, method: http.MethodDelete, path: "/api/v1/holidays/1", want: http.StatusServiceUnavailable},
+		{name: "on: exempt internal", enabled: true, method: http.MethodPut, path: "/internal/read-only", want: http.StatusOK},
+		{name: "on: exempt health", enabled: true, method: http.MethodPost, path: "/health", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newReadOnlyRouter(NewReadOnlyMode(tt.enabled)).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want != http.StatusServiceUnavailable {
+				return
+			}
+			if w.Header().Get("Retry-After") == "" {
+				t.Error("refused write has no Retry-After header")
+			}
+			var body errors.AppError
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != errors.ErrReadOnly {
+				t.Errorf("got body %s, want a %s error", w.Body.String(), errors.ErrReadOnly)
+			}
+		})
+	}
+}
+
+func TestReadOnlyModeSet(t *testing.T) {
+	mode := NewReadOnlyMode(false)
+	router := newReadOnlyRouter(mode)
+
+	if previous := mode.Set(true); previous {
+		t.Error("Set reported the mode was already on")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/leave-requests", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("after turning on: got %d, want 503", w.Code)
+	}
+
+	if previous := mode.Set(false); !previous {
+		t.Error("Set reported the mode was off")
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/leave-requests", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("after turning off: got %d, want 200", w.Code)
+	}
+}
+
+func TestReadOnlyModeConcurrentToggle(t *testing.T) {
+	mode := NewReadOnlyMode(false)
+	router := newReadOnlyRouter(mode)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(on bool) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				mode.Set(on)
+			}
+		}(i%2 == 0)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/leave-requests", nil))
+				if w.Code != http.StatusOK && w.Code != http.StatusServiceUnavailable {
+					t.Errorf("got %d mid-toggle", w.Code)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestInternalAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		provided   string
+		want       int
+	}{
+		{name: "disabled", configured: "", provided: "", want: http.StatusNotFound},
+		{name: "disabled ignores a token", configured: "", provided: "secret", want: http.StatusNotFound},
+		{name: "missing token", configured: "secret", provided: "", want: http.StatusUnauthorized},
+		{name: "wrong token", configured: "secret", provided: "guess", want: http.StatusUnauthorized},
+		{name: "matching token", configured: "secret", provided: "secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/internal/read-only", InternalAdmin(tt.configured), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/read-only", nil)
+			if tt.provided != "" {
+				req.Header.Set(InternalAdminTokenHeader, tt.provided)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("got %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/pkg/pdf"
+)
+
+// Page layout in points
+const (
+	pdfMargin     = 48.0
+	pdfBodySize   = 9.0
+	pdfRowHeight  = 14.0
+	pdfFooterSize = 8.0
+)
+
+// PDFRenderer renders documents as A4 PDFs
+type PDFRenderer struct{}
+
+func (PDFRenderer) ContentType() string { return "application/pdf" }
+
+func (PDFRenderer) Extension() string { return "pdf" }
+
+// RenderLeaveStatement lays the statement out as a header naming the
+// organization and employee, tables of the opening balances snapshotted by
+// the yearly reset, the current balances, requests and adjustments, and a
+// line for HR to sign
+func (PDFRenderer) RenderLeaveStatement(w io.Writer, statement *domain.LeaveStatement) error {
+	title := fmt.Sprintf("Leave statement %d", statement.Year)
+	p := newPDFLayout(pdf.New(title + " - " + statement.OrganizationName))
+
+	p.text(16, true, statement.OrganizationName)
+	p.y += 6
+	p.text(12, true, title)
+	employee := statement.EmployeeID.String()
+	if statement.EmployeeEmail != "" {
+		employee = statement.EmployeeEmail + " (" + employee + ")"
+	}
+	p.text(pdfBodySize, false, "Employee: "+employee)
+	p.text(pdfBodySize, false, "Generated: "+statement.GeneratedAt.Format("2006-01-02 15:04 MST"))
+
+	p.section("Opening balances")
+	openings := [][]string{}
+	for _, b := range statement.Balances {
+		if b.Opening == nil {
+			continue
+		}
+		o := b.Opening
+		openings = append(openings, []string{b.LeaveType, o.SnapshotAt.Format("2006-01-02"), amount(o.TotalDays),
+			amount(o.CarriedOverDays), amount(o.UsedDays), amount(o.PendingDays), amount(o.RemainingDays)})
+	}
+	p.table([]pdfColumn{{"Leave type", 130}, {"As of", 70}, {"Total", 58}, {"Carried", 58}, {"Used", 58}, {"Pending", 58}, {"Remaining", 58}},
+		openings, "The year was not opened by a yearly reset.")
+
+	p.section("Balances")
+	balances := [][]string{}
+	for _, b := range statement.Balances {
+		balances = append(balances, []string{b.LeaveType, b.Unit,
+			amount(b.TotalDays), amount(b.UsedDays), amount(b.PendingDays), amount(b.RemainingDays)})
+	}
+	p.table([]pdfColumn{{"Leave type", 170}, {"Unit", 60}, {"Total", 65}, {"Used", 65}, {"Pending", 65}, {"Remaining", 65}},
+		balances, "No balances for the year.")
+
+	p.section("Requests")
+	requests := [][]string{}
+	for _, r := range statement.Requests {
+		requests = append(requests, []string{r.LeaveType, r.StartDate.Format("2006-01-02"), r.EndDate.Format("2006-01-02"),
+			amount(r.Days) + " " + r.Unit, statusLabel(r.Status)})
+	}
+	p.table([]pdfColumn{{"Leave type", 150}, {"Start", 75}, {"End", 75}, {"Amount", 80}, {"Status", 110}},
+		requests, "No requests starting in the year.")
+
+	p.section("Adjustments")
+	adjustments := [][]string{}
+	for _, a := range statement.Adjustments {
+		adjustments = append(adjustments, []string{a.Date.Format("2006-01-02"), a.LeaveType,
+			fmt.Sprintf("%+.2f %s", a.Adjustment, a.Unit), statusLabel(a.Status), a.Reason})
+	}
+	p.table([]pdfColumn{{"Date", 70}, {"Leave type", 110}, {"Adjustment", 80}, {"Status", 70}, {"Reason", 160}},
+		adjustments, "No adjustments to the year's balances.")
+
+	p.need(4 * pdfRowHeight)
+	p.y += 3 * pdfRowHeight
+	p.doc.Line(pdfMargin, p.y, pdfMargin+200, p.y)
+	p.doc.Line(pdf.PageWidth-pdfMargin-150, p.y, pdf.PageWidth-pdfMargin, p.y)
+	p.y += pdfRowHeight - 2
+	p.doc.Text(pdfMargin, p.y, pdfFooterSize, false, "Signature, HR on behalf of "+pdf.Fit(statement.OrganizationName, pdfFooterSize, 100))
+	p.doc.Text(pdf.PageWidth-pdfMargin-150, p.y, pdfFooterSize, false, "Date")
+
+	_, err := p.doc.WriteTo(w)
+	return err
+}
+
+// pdfColumn is a table column: its title and width in points
+type pdfColumn struct {
+	title string
+	width float64
+}
+
+// pdfLayout writes lines top to bottom, starting new pages as they fill
+type pdfLayout struct {
+	doc *pdf.Document
+	y   float64
+}
+
+func newPDFLayout(doc *pdf.Document) *pdfLayout {
+	p := &pdfLayout{doc: doc}
+	p.newPage()
+	return p
+}
+
+// newPage starts a page, numbered in its footer
+func (p *pdfLayout) newPage() {
+	p.doc.AddPage()
+	p.doc.Text(pdfMargin, pdf.PageHeight-pdfMargin/2, pdfFooterSize, false, fmt.Sprintf("Page %d", p.doc.PageCount()))
+	p.y = pdfMargin
+}
+
+// need starts a new page unless height points fit above the bottom margin
+func (p *pdfLayout) need(height float64) {
+	if p.y+height > pdf.PageHeight-pdfMargin {
+		p.newPage()
+	}
+}
+
+// text writes a line of text
+func (p *pdfLayout) text(size float64, bold bool, s string) {
+	p.need(size + 4)
+	p.y += size + 4
+	p.doc.Text(pdfMargin, p.y, size, bold, pdf.Fit(s, size, pdf.PageWidth-2*pdfMargin))
+}
+
+// section writes a section heading, kept on the page of its first rows
+func (p *pdfLayout) section(title string) {
+	p.need(20 + 3*pdfRowHeight)
+	p.y += 20
+	p.doc.Text(pdfMargin, p.y, 11, true, title)
+}
+
+// table writes the rows under a header, repeating the header on each page
+// the table continues on, or the empty message when there are no rows
+func (p *pdfLayout) table(columns []pdfColumn, rows [][]string, empty string) {
+	header := func() {
+		p.y += pdfRowHeight
+		x := pdfMargin
+		for _, c := range columns {
+			p.doc.Text(x, p.y, pdfBodySize, true, c.title)
+			x += c.width
+		}
+		p.doc.Line(pdfMargin, p.y+4, x, p.y+4)
+	}
+	header()
+
+	if len(rows) == 0 {
+		p.y += pdfRowHeight
+		p.doc.Text(pdfMargin, p.y, pdfBodySize, false, empty)
+		return
+	}
+	for _, row := range rows {
+		if p.y+pdfRowHeight > pdf.PageHeight-pdfMargin {
+			p.newPage()
+			header()
+		}
+		p.y += pdfRowHeight
+		x := pdfMargin
+		for i, c := range columns {
+			p.doc.Text(x, p.y, pdfBodySize, false, pdf.Fit(row[i], pdfBodySize, c.width-6))
+			x += c.width
+		}
+	}
+}
+
+func amount(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// statusLabel spells out a status, e.g. "cancellation requested"
+func statusLabel(status string) string {
+	return strings.ReplaceAll(status, "_", " ")
+}
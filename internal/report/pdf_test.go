@@ -0,0 +1,106 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func renderStatement(t *testing.T, statement *domain.LeaveStatement) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := (PDFRenderer{}).RenderLeaveStatement(&buf, statement); err != nil {
+		t.Fatalf("rendering: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") ||
+		!strings.Contains(out, "trailer\n<< /Size ") || !strings.Contains(out, "startxref\n") {
+		t.Fatalf("not a complete PDF:\n%s", out)
+	}
+	return out
+}
+
+func TestRenderLeaveStatement(t *testing.T) {
+	snapshotAt := time.Date(2026, time.January, 1, 0, 5, 0, 0, time.UTC)
+	statement := &domain.LeaveStatement{
+		OrganizationName: `Acme (Europe) \ Ltd`,
+		EmployeeID:       uuid.New(),
+		EmployeeEmail:    "ana@example.com",
+		Year:             2026,
+		GeneratedAt:      time.Date(2026, time.March, 4, 9, 30, 0, 0, time.UTC),
+		Balances: []domain.LeaveStatementBalance{{
+			LeaveType: "Annual", Unit: domain.LeaveUnitDays, TotalDays: 25, UsedDays: 3, PendingDays: 2, RemainingDays: 20,
+			Opening: &domain.LeaveStatementOpening{SnapshotAt: snapshotAt, TotalDays: 25, CarriedOverDays: 5, RemainingDays: 25},
+		}},
+		Requests: []domain.LeaveStatementRequest{{
+			LeaveType: "Annual", Unit: domain.LeaveUnitDays, Days: 3, Status: domain.LeaveStatusCancellationRequested,
+			StartDate: time.Date(2026, time.February, 2, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2026, time.February, 4, 0, 0, 0, 0, time.UTC),
+		}},
+		Adjustments: []domain.LeaveStatementAdjustment{{
+			LeaveType: "Annual", Unit: domain.LeaveUnitDays, Adjustment: -1.5, Status: "approved",
+			Date: time.Date(2026, time.February, 10, 0, 0, 0, 0, time.UTC), Reason: "Correction (payroll)",
+		}},
+	}
+
+	out := renderStatement(t, statement)
+	for _, want := range []string{
+		`/Title (Leave statement 2026 - Acme \(Europe\) \\ Ltd)`,
+		`(Acme \(Europe\) \\ Ltd) Tj`,
+		`(Employee: ana@example.com \(` + statement.EmployeeID.String() + `\)) Tj`,
+		"(Generated: 2026-03-04 09:30 UTC) Tj",
+		"(Opening balances) Tj",
+		"(2026-01-01) Tj",
+		"(cancellation requested) Tj",
+		"(-1.50 days) Tj",
+		`(Correction \(payroll\)) Tj`,
+		"(Page 1) Tj",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("statement lacks %s", want)
+		}
+	}
+	if strings.Contains(out, "No balances for the year.") {
+		t.Error("the empty message was written for a table with rows")
+	}
+}
+
+func TestRenderLeaveStatementEmptyAndLong(t *testing.T) {
+	empty := renderStatement(t, &domain.LeaveStatement{OrganizationName: "Acme", Year: 2026})
+	for _, want := range []string{"The year was not opened by a yearly reset.", "No balances for the year.",
+		"No requests starting in the year.", "No adjustments to the year's balances."} {
+		if !strings.Contains(empty, want) {
+			t.Errorf("empty statement lacks %q", want)
+		}
+	}
+
+	long := &domain.LeaveStatement{OrganizationName: "Acme", Year: 2026}
+	for i := 0; i < 120; i++ {
+		long.Requests = append(long.Requests, domain.LeaveStatementRequest{
+			LeaveType: fmt.Sprintf("Type %d", i), Status: domain.LeaveStatusApproved,
+		})
+	}
+	out := renderStatement(t, long)
+	if !strings.Contains(out, "/Count 3 >>") || !strings.Contains(out, "(Page 3) Tj") {
+		t.Error("120 requests did not run over three numbered pages")
+	}
+	// The table header is repeated on each page it continues on
+	if n := strings.Count(out, "(Amount) Tj"); n != 3 {
+		t.Errorf("requests header written %d times, want once per page", n)
+	}
+}
+
+func TestForFormat(t *testing.T) {
+	renderer, err := ForFormat("PDF")
+	if err != nil || renderer.ContentType() != "application/pdf" || renderer.Extension() != "pdf" {
+		t.Errorf("ForFormat(PDF) = %v, %v", renderer, err)
+	}
+	if _, err := ForFormat("docx"); err == nil {
+		t.Error("an unsupported format was accepted")
+	}
+}
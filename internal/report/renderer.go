@@ -0,0 +1,36 @@
+// Package report renders documents handed to people rather than API
+// clients, such as an employee's leave statement, in the formats they
+// can be downloaded in
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+)
+
+// Formats documents can be rendered in
+const (
+	FormatPDF = "pdf"
+)
+
+// Renderer renders documents in one format
+type Renderer interface {
+	// ContentType is the media type of the rendered documents
+	ContentType() string
+	// Extension is the file extension of the rendered documents, without
+	// the dot
+	Extension() string
+	RenderLeaveStatement(w io.Writer, statement *domain.LeaveStatement) error
+}
+
+// ForFormat returns the renderer of the format
+func ForFormat(format string) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case FormatPDF:
+		return PDFRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, expected %s", format, FormatPDF)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestCreateAbsenceAlertSkipsUnclearedAlert(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		inserted bool
+	}{
+		{name: "new alert", inserted: true},
+		{name: "employee already alerted"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			if tt.inserted {
+				rec.respond(`INSERT INTO "absence_alerts"`, []string{"id"}, []driver.Value{uuid.NewString()})
+			}
+
+			created, err := repo.CreateAbsenceAlert(&domain.AbsenceAlert{
+				OrganizationID: uuid.New(),
+				EmployeeID:     uuid.New(),
+				Status:         domain.AbsenceAlertStatusOpen,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if created != tt.inserted {
+				t.Errorf("created = %t, want %t", created, tt.inserted)
+			}
+
+			query := rec.last(t)
+			if !strings.Contains(query.SQL, `ON CONFLICT ("organization_id","employee_id")`) ||
+				!strings.Contains(query.SQL, "WHERE cleared_at IS NULL DO NOTHING") {
+				t.Errorf("insert does not yield to the employee's uncleared alert: %s", query.SQL)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeAbsenceAlertLocksStatus(t *testing.T) {
+	for _, tt := range []struct {
+		status  string
+		wantErr error
+	}{
+		{status: domain.AbsenceAlertStatusOpen},
+		{status: domain.AbsenceAlertStatusAcknowledged, wantErr: ErrStatusChanged},
+		{status: domain.AbsenceAlertStatusResolved, wantErr: ErrStatusChanged},
+	} {
+		t.Run(tt.status, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			id := uuid.New()
+			rec.respond(`FOR UPDATE`, []string{"id", "status"}, []driver.Value{id.String(), tt.status})
+
+			err := repo.AcknowledgeAbsenceAlert(&domain.AbsenceAlert{Base: domain.Base{ID: id}, Status: domain.AbsenceAlertStatusAcknowledged})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			updated := len(rec.find(`UPDATE "absence_alerts"`)) > 0
+			if updated != (tt.wantErr == nil) {
+				t.Errorf("alert updated = %t with the locked row %s", updated, tt.status)
+			}
+		})
+	}
+}
+
+func TestListAbsenceAlertOrganizationIDsIncludesUnclearedAlerts(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	if _, err := repo.ListAbsenceAlertOrganizationIDs(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := rec.last(t)
+	if !strings.Contains(query.SQL, "absence_alert_days > 0 OR absence_alert_spells > 0") ||
+		!strings.Contains(query.SQL, "FROM absence_alerts WHERE cleared_at IS NULL") {
+		t.Errorf("organizations whose thresholds were turned off keep their alerts: %s", query.SQL)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestListAbsencesCountsApprovedDaysInWindow(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	start := time.Date(2024, time.July, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	if _, err := repo.ListAbsences(uuid.New(), []uuid.UUID{uuid.New()}, start, end); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := rec.last(t)
+	if !strings.Contains(query.SQL, "d.date BETWEEN") || !hasArg(query, start) || !hasArg(query, end) {
+		t.Errorf("breakdown days are not bounded by the window: %s %+v", query.SQL, query.Args)
+	}
+	if !hasArg(query, domain.LeaveStatusApproved) || !hasArg(query, domain.LeaveStatusCancellationRequested) {
+		t.Errorf("only approved leave should count: %+v", query.Args)
+	}
+	if !strings.Contains(query.SQL, "deleted_at IS NULL") {
+		t.Errorf("deleted requests are counted: %s", query.SQL)
+	}
+}
+
+func TestListAbsencesWithoutLeaveTypes(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	absences, err := repo.ListAbsences(uuid.New(), nil, time.Now(), time.Now())
+	if err != nil || len(absences) != 0 {
+		t.Fatalf("got %v, %v, want no absences", absences, err)
+	}
+	if len(rec.find("leave_requests")) != 0 {
+		t.Error("queried absences of no leave type")
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestAccrueBalanceBankCap(t *testing.T) {
+	tests := []struct {
+		name           string
+		total          float64
+		wantDays       float64
+		wantForfeited  float64
+		wantAdjustment bool
+	}{
+		{name: "below the cap", total: 20, wantDays: 2, wantAdjustment: true},
+		{name: "crossing the cap", total: 29, wantDays: 1, wantForfeited: 1, wantAdjustment: true},
+		{name: "at the cap", total: 30, wantForfeited: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			balanceID := uuid.New()
+			rec.respond(`FROM "leave_balances"`, []string{"id", "organization_id", "employee_id", "total_days", "used_days"},
+				[]driver.Value{balanceID.String(), uuid.NewString(), uuid.NewString(), tt.total + 4, 4.0})
+			rec.respond(`INSERT INTO "leave_balance_adjustments"`, []string{"id"}, []driver.Value{uuid.NewString()})
+			rec.respond(`INSERT INTO "accrual_runs"`, []string{"id"}, []driver.Value{uuid.NewString()})
+
+			capDays := 30.0
+			leaveType := &domain.LeaveType{
+				ID:               uuid.New(),
+				DefaultDays:      24,
+				AccrualFrequency: domain.AccrualFrequencyMonthly,
+				AccrualRate:      2,
+				MaxBalanceDays:   &capDays,
+			}
+			run, err := repo.AccrueBalance(balanceID, leaveType, "2027-03", 8)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if run.Days != tt.wantDays || run.ForfeitedDays != tt.wantForfeited || run.Capped != (tt.wantForfeited > 0) {
+				t.Errorf("run credited %v and forfeited %v (capped %v), want %v and %v",
+					run.Days, run.ForfeitedDays, run.Capped, tt.wantDays, tt.wantForfeited)
+			}
+
+			adjustments := rec.find(`INSERT INTO "leave_balance_adjustments"`)
+			if !tt.wantAdjustment {
+				if len(adjustments) != 0 || len(rec.find(`UPDATE "leave_balances"`)) != 0 {
+					t.Error("a balance at its cap was credited")
+				}
+			} else if len(adjustments) != 1 || !hasArg(adjustments[0], tt.wantDays) {
+				t.Errorf("adjustment does not credit %v: %+v", tt.wantDays, adjustments)
+			} else if capped := hasArg(adjustments[0], "monthly accrual for 2027-03, capped at 30 banked"); capped != (tt.wantForfeited > 0) {
+				t.Errorf("adjustment reason mentions the cap = %v, want %v: %+v", capped, tt.wantForfeited > 0, adjustments[0].Args)
+			}
+
+			runs := rec.find(`INSERT INTO "accrual_runs"`)
+			if len(runs) != 1 || !strings.Contains(runs[0].SQL, `"capped"`) || !hasArg(runs[0], tt.wantForfeited > 0) {
+				t.Errorf("accrual run does not record capped = %v: %+v", tt.wantForfeited > 0, runs)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestRevertBalanceAdjustment(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           string
+		delta            float64
+		usedDays         float64
+		wantErr          error
+		wantInsufficient bool
+		wantAfter        float64
+	}{
+		{name: "added days", status: domain.AdjustmentStatusApproved, delta: 3, wantAfter: 20},
+		{name: "deducted days", status: domain.AdjustmentStatusApproved, delta: -2, wantAfter: 25},
+		{name: "added days already used", status: domain.AdjustmentStatusApproved, delta: 3, usedDays: 21, wantInsufficient: true},
+		{name: "already reverted", status: domain.AdjustmentStatusReverted, delta: 3, wantErr: ErrStatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			adjustmentID := uuid.New()
+			balanceID := uuid.New()
+			rec.respond(`SELECT * FROM "leave_balance_adjustments"`, []string{"id", "leave_balance_id", "adjustment", "status"},
+				[]driver.Value{adjustmentID.String(), balanceID.String(), tt.delta, tt.status})
+			rec.respond(`SELECT * FROM "leave_balances"`, []string{"id", "leave_type_id", "total_days", "used_days", "pending_days"},
+				[]driver.Value{balanceID.String(), uuid.New().String(), 23.0, tt.usedDays, 0.0})
+			rec.respond(`SELECT * FROM "leave_types"`, []string{"id", "allow_negative_balance"},
+				[]driver.Value{uuid.New().String(), false})
+			rec.respond(`INSERT INTO "leave_balance_adjustments"`, []string{"id"}, []driver.Value{uuid.New().String()})
+
+			revertedBy := uuid.New()
+			correction, err := repo.RevertBalanceAdjustment(adjustmentID, revertedBy, "entered twice")
+			if tt.wantErr != nil || tt.wantInsufficient {
+				var insufficient *InsufficientBalanceError
+				if tt.wantInsufficient && !errors.As(err, &insufficient) {
+					t.Fatalf("got %v, want an InsufficientBalanceError", err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				if len(rec.find(`UPDATE "leave_balances"`)) != 0 || len(rec.find(`INSERT INTO "leave_balance_adjustments"`)) != 0 {
+					t.Error("a refused revert changed the ledger")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if correction.Adjustment != -tt.delta || correction.Status != domain.AdjustmentStatusCorrection ||
+				correction.PerformedBy != domain.SystemUserID || correction.ApprovedBy == nil || *correction.ApprovedBy != revertedBy {
+				t.Errorf("correction = %+v", correction)
+			}
+			if *correction.BalanceBefore != 23 || *correction.BalanceAfter != tt.wantAfter {
+				t.Errorf("correction moved the balance from %v to %v, want 23 to %v",
+					*correction.BalanceBefore, *correction.BalanceAfter, tt.wantAfter)
+			}
+			if !strings.Contains(correction.Reason, adjustmentID.String()) || !strings.HasSuffix(correction.Reason, ": entered twice") {
+				t.Errorf("reason = %q", correction.Reason)
+			}
+
+			total := rec.find(`UPDATE "leave_balances" SET "total_days"=total_days + $1`)
+			if len(total) != 1 || !hasArg(total[0], -tt.delta) {
+				t.Errorf("balance total was not moved by %v: %+v", -tt.delta, total)
+			}
+			reverted := rec.find(`UPDATE "leave_balance_adjustments" SET "status"=$1`)
+			if len(reverted) != 1 || !hasArg(reverted[0], domain.AdjustmentStatusReverted) {
+				t.Errorf("the adjustment was not marked reverted: %+v", reverted)
+			}
+		})
+	}
+}
+
+func TestUpdateBalanceAdjustmentAppliesScheduledOnce(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		to        string
+		wantErr   error
+		wantDelta bool
+	}{
+		{name: "applied when due", status: domain.AdjustmentStatusScheduled, to: domain.AdjustmentStatusApproved, wantDelta: true},
+		{name: "already applied", status: domain.AdjustmentStatusApproved, to: domain.AdjustmentStatusApproved, wantErr: ErrStatusChanged},
+		{name: "cancelled", status: domain.AdjustmentStatusScheduled, to: domain.AdjustmentStatusCancelled},
+		{name: "applied after a cancellation", status: domain.AdjustmentStatusCancelled, to: domain.AdjustmentStatusApproved, wantErr: ErrStatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			adjustmentID, balanceID := uuid.New(), uuid.New()
+			rec.respond(`SELECT * FROM "leave_balance_adjustments"`, []string{"id", "leave_balance_id", "adjustment", "status"},
+				[]driver.Value{adjustmentID.String(), balanceID.String(), 2.0, tt.status})
+			rec.respond(`SELECT * FROM "leave_balances"`, []string{"id", "total_days", "used_days", "pending_days"},
+				[]driver.Value{balanceID.String(), 20.0, 0.0, 0.0})
+
+			adjustment := &domain.LeaveBalanceAdjustment{Base: domain.Base{ID: adjustmentID}, Status: tt.to}
+			err := repo.UpdateBalanceAdjustment(adjustment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			locks := rec.find(`SELECT * FROM "leave_balance_adjustments"`)
+			if len(locks) != 1 || !strings.HasSuffix(locks[0].SQL, "FOR UPDATE") {
+				t.Errorf("the adjustment was not locked: %+v", locks)
+			}
+			total := rec.find(`UPDATE "leave_balances" SET "total_days"=total_days + $1`)
+			if tt.wantDelta != (len(total) == 1 && hasArg(total[0], 2.0)) {
+				t.Errorf("balance updates = %+v, want the delta applied %v", total, tt.wantDelta)
+			}
+			if tt.wantErr != nil {
+				if len(rec.find(`UPDATE "leave_balance_adjustments"`)) != 0 {
+					t.Error("a refused transition was saved")
+				}
+				return
+			}
+			if tt.wantDelta && (adjustment.AppliedAt == nil || *adjustment.BalanceBefore != 20 || *adjustment.BalanceAfter != 22) {
+				t.Errorf("applied adjustment = %+v, want it dated and moving the balance from 20 to 22", adjustment)
+			}
+			if !tt.wantDelta && adjustment.AppliedAt != nil {
+				t.Error("a cancelled adjustment was dated as applied")
+			}
+		})
+	}
+}
+
+func TestTransferLeaveBalance(t *testing.T) {
+	tests := []struct {
+		name             string
+		days             float64
+		allRemaining     bool
+		usedDays         float64
+		withoutSource    bool
+		wantErr          error
+		wantInsufficient bool
+		wantDays         float64
+	}{
+		// 20 days less 5 used and 2 pending leaves 13 to move
+		{name: "fixed amount", days: 5, wantDays: 5},
+		{name: "all remaining", allRemaining: true, wantDays: 13},
+		{name: "more than is left", days: 14, wantInsufficient: true},
+		{name: "nothing left", allRemaining: true, usedDays: 18, wantErr: ErrNothingToTransfer},
+		{name: "no source balance", days: 1, withoutSource: true, wantErr: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			employeeID, fromTypeID, toTypeID := uuid.New(), uuid.New(), uuid.New()
+			fromID, toID := uuid.New(), uuid.New()
+			used := tt.usedDays
+			if used == 0 {
+				used = 5
+			}
+			rows := [][]driver.Value{{toID.String(), toTypeID.String(), 7.0, 0.0, 0.0}}
+			if !tt.withoutSource {
+				rows = append(rows, []driver.Value{fromID.String(), fromTypeID.String(), 20.0, used, 2.0})
+			}
+			rec.respond(`SELECT * FROM "leave_balances"`, []string{"id", "leave_type_id", "total_days", "used_days", "pending_days"}, rows...)
+
+			transfer, err := repo.TransferLeaveBalance(uuid.New(), &domain.BalanceTransferRequest{
+				EmployeeID:      employeeID,
+				FromLeaveTypeID: fromTypeID,
+				ToLeaveTypeID:   toTypeID,
+				Year:            2026,
+				Days:            tt.days,
+				AllRemaining:    tt.allRemaining,
+				Reason:          "Converting unused annual leave",
+				PerformedBy:     uuid.New(),
+			}, 7)
+
+			// The target balance is opened at its entitlement when missing
+			seeds := rec.find(`INSERT INTO "leave_balances"`)
+			if len(seeds) != 1 || !strings.Contains(seeds[0].SQL, "DO NOTHING") || !hasArg(seeds[0], 7.0) {
+				t.Errorf("target balance seed = %+v", seeds)
+			}
+			// Both balances are locked in id order so opposite transfers
+			// cannot deadlock
+			locks := rec.find(`SELECT * FROM "leave_balances"`)
+			if len(locks) != 1 || !strings.HasSuffix(locks[0].SQL, `ORDER BY id FOR UPDATE`) {
+				t.Errorf("balances were not locked in id order: %+v", locks)
+			}
+
+			if tt.wantErr != nil || tt.wantInsufficient {
+				var insufficient *InsufficientBalanceError
+				if tt.wantInsufficient && !errors.As(err, &insufficient) {
+					t.Fatalf("got %v, want an InsufficientBalanceError", err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				if len(rec.find(`INSERT INTO "leave_balance_adjustments"`)) != 0 || len(rec.find(`UPDATE "leave_balances"`)) != 0 {
+					t.Error("a refused transfer moved days")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			debit, credit := transfer.Debit, transfer.Credit
+			if debit.Adjustment != -tt.wantDays || debit.LeaveBalanceID != fromID || credit.Adjustment != tt.wantDays || credit.LeaveBalanceID != toID {
+				t.Fatalf("moved %v from %s and %v to %s, want %v from %s to %s",
+					debit.Adjustment, debit.LeaveBalanceID, credit.Adjustment, credit.LeaveBalanceID, tt.wantDays, fromID, toID)
+			}
+			if *debit.TransferID != transfer.TransferID || *credit.TransferID != transfer.TransferID ||
+				*debit.CounterpartID != credit.ID || *credit.CounterpartID != debit.ID {
+				t.Errorf("the sides are not linked: debit %+v, credit %+v", debit, credit)
+			}
+			if *debit.BalanceAfter != 20-tt.wantDays || *credit.BalanceAfter != 7+tt.wantDays {
+				t.Errorf("balances after = %v and %v", *debit.BalanceAfter, *credit.BalanceAfter)
+			}
+			if inserts := rec.find(`INSERT INTO "leave_balance_adjustments"`); len(inserts) != 2 {
+				t.Errorf("recorded %d adjustments, want both sides", len(inserts))
+			}
+			totals := rec.find(`UPDATE "leave_balances" SET "total_days"=total_days + $1`)
+			if len(totals) != 2 || !hasArg(totals[0], -tt.wantDays) || !hasArg(totals[1], tt.wantDays) {
+				t.Errorf("balance totals were not moved by %v: %+v", tt.wantDays, totals)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestExpireCompOffForfeitsUnusedDays(t *testing.T) {
+	today := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		status        string
+		expiresOn     time.Time
+		usedDays      float64
+		wantForfeited float64
+		wantExpired   bool
+	}{
+		{name: "unused", status: domain.CompOffStatusApproved, expiresOn: today.AddDate(0, 0, -1), wantForfeited: 1, wantExpired: true},
+		{name: "partly used", status: domain.CompOffStatusApproved, expiresOn: today.AddDate(0, 0, -1), usedDays: 2.5, wantForfeited: 0.5, wantExpired: true},
+		{name: "used up", status: domain.CompOffStatusApproved, expiresOn: today.AddDate(0, 0, -1), usedDays: 3, wantExpired: true},
+		{name: "last usable day", status: domain.CompOffStatusApproved, expiresOn: today},
+		{name: "expired by another run", status: domain.CompOffStatusExpired, expiresOn: today.AddDate(0, 0, -1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			requestID, balanceID := uuid.New(), uuid.New()
+			// The balance holds the day credited on top of two days of allowance
+			rec.respond(`SELECT * FROM "comp_off_requests"`,
+				[]string{"id", "status", "date_worked", "expires_on", "leave_balance_id", "credited"},
+				[]driver.Value{requestID.String(), tt.status, today.AddDate(0, -2, 0), tt.expiresOn, balanceID.String(), 1.0})
+			rec.respond(`SELECT * FROM "leave_balances"`, []string{"id", "total_days", "used_days", "pending_days"},
+				[]driver.Value{balanceID.String(), 3.0, tt.usedDays, 0.0})
+			rec.respond(`INSERT INTO "leave_balance_adjustments"`, []string{"id"}, []driver.Value{uuid.New().String()})
+
+			adjustment, err := repo.ExpireCompOff(requestID, today)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			locks := rec.find(`FROM "comp_off_requests" WHERE id = $1`)
+			if len(locks) != 1 || !strings.HasSuffix(locks[0].SQL, "FOR UPDATE") {
+				t.Errorf("the request was not locked: %+v", locks)
+			}
+
+			if tt.wantForfeited == 0 {
+				if adjustment != nil {
+					t.Errorf("forfeited %v, want nothing", adjustment.Adjustment)
+				}
+				if len(rec.find(`INSERT INTO "leave_balance_adjustments"`)) != 0 || len(rec.find(`UPDATE "leave_balances"`)) != 0 {
+					t.Error("the balance was changed")
+				}
+			} else {
+				if adjustment == nil || adjustment.Adjustment != -tt.wantForfeited ||
+					adjustment.PerformedBy != domain.SystemUserID || adjustment.Status != domain.AdjustmentStatusApproved {
+					t.Fatalf("adjustment = %+v, want %v forfeited by the system", adjustment, -tt.wantForfeited)
+				}
+				total := rec.find(`UPDATE "leave_balances" SET "total_days"=total_days + $1`)
+				if len(total) != 1 || !hasArg(total[0], -tt.wantForfeited) {
+					t.Errorf("balance total was not moved by %v: %+v", -tt.wantForfeited, total)
+				}
+			}
+
+			expired := rec.find(`UPDATE "comp_off_requests"`)
+			if tt.wantExpired != (len(expired) == 1 && hasArg(expired[0], domain.CompOffStatusExpired)) {
+				t.Errorf("expired = %+v, want expired %v", expired, tt.wantExpired)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestRecalculateLeaveRequestDays(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        string
+		savedStatus   string
+		savedDays     float64
+		tracksBalance bool
+		wantBucket    string
+		wantErr       error
+	}{
+		{name: "pending", status: domain.LeaveStatusPending, savedStatus: domain.LeaveStatusPending, savedDays: 5,
+			tracksBalance: true, wantBucket: "pending_days"},
+		{name: "approved", status: domain.LeaveStatusApproved, savedStatus: domain.LeaveStatusApproved, savedDays: 5,
+			tracksBalance: true, wantBucket: "used_days"},
+		{name: "untracked", status: domain.LeaveStatusApproved, savedStatus: domain.LeaveStatusApproved, savedDays: 5},
+		{name: "decided meanwhile", status: domain.LeaveStatusPending, savedStatus: domain.LeaveStatusApproved, savedDays: 5,
+			tracksBalance: true, wantErr: ErrStatusChanged},
+		{name: "recounted meanwhile", status: domain.LeaveStatusPending, savedStatus: domain.LeaveStatusPending, savedDays: 4,
+			tracksBalance: true, wantErr: ErrStatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			requestID, leaveTypeID := uuid.New(), uuid.New()
+			start := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+			rec.respond(`SELECT * FROM "leave_requests"`, []string{"id", "status", "days", "leave_type_id", "start_date"},
+				[]driver.Value{requestID.String(), tt.savedStatus, tt.savedDays, leaveTypeID.String(), start})
+			rec.respond(`SELECT "tracks_balance" FROM "leave_types"`, []string{"tracks_balance"}, []driver.Value{tt.tracksBalance})
+			rec.respond(`INSERT INTO "leave_request_history"`, []string{"id"}, []driver.Value{uuid.New().String()})
+
+			request := &domain.LeaveRequest{Status: tt.status, Days: 4, RawDays: 4,
+				Breakdown: []domain.LeaveRequestDay{{Date: start, Fraction: 1}}}
+			request.ID = requestID
+			if tt.status == domain.LeaveStatusApproved {
+				approver := uuid.New()
+				request.ApprovedBy = &approver
+			}
+			history := &domain.LeaveRequestHistory{LeaveRequestID: requestID, Action: domain.LeaveActionRecalculate}
+			err := repo.RecalculateLeaveRequestDays(request, 5, history)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			locks := rec.find(`SELECT * FROM "leave_requests"`)
+			if len(locks) != 1 || !strings.HasSuffix(locks[0].SQL, "FOR UPDATE") {
+				t.Errorf("the request was not locked: %+v", locks)
+			}
+			if tt.wantErr != nil {
+				if len(rec.find(`UPDATE "leave_balances"`)) != 0 || len(rec.find(`UPDATE "leave_requests"`)) != 0 {
+					t.Error("a request that changed was saved")
+				}
+				return
+			}
+
+			// The balance is charged the day given back
+			charged := rec.find(`UPDATE "leave_balances"`)
+			if tt.wantBucket == "" {
+				if len(charged) != 0 {
+					t.Errorf("an untracked leave type's balance was charged: %+v", charged)
+				}
+			} else if len(charged) != 1 || !strings.Contains(charged[0].SQL, `"`+tt.wantBucket+`"=`+tt.wantBucket+` + $1`) ||
+				!hasArg(charged[0], -1.0) || !hasArg(charged[0], 2026) {
+				t.Errorf("balance update = %+v, want %s charged -1", charged, tt.wantBucket)
+			}
+
+			if len(rec.find(`DELETE FROM "leave_request_days"`)) != 1 || len(rec.find(`INSERT INTO "leave_request_days"`)) != 1 {
+				t.Error("the day breakdown was not replaced")
+			}
+			saved := rec.find(`UPDATE "leave_requests"`)
+			if len(saved) != 1 || !strings.Contains(saved[0].SQL, `"days"=$`) || !hasArg(saved[0], 4.0) {
+				t.Errorf("request update = %+v", saved)
+			}
+			if len(rec.find(`INSERT INTO "leave_request_history"`)) != 1 {
+				t.Error("no history was recorded")
+			}
+		})
+	}
+}
+
+func TestListLeaveRequestsCovering(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	orgID := uuid.New()
+	wednesday := time.Date(2026, time.June, 3, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2026, time.May, 20, 0, 0, 0, 0, time.UTC)
+
+	if _, err := repo.ListLeaveRequestsCovering(orgID, []time.Time{wednesday, wednesday.AddDate(0, 0, 7)}, today); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listed := rec.find(`SELECT * FROM "leave_requests"`)
+	if len(listed) != 1 || !strings.Contains(listed[0].SQL, "end_date >= $5) AND ((start_date <= $6 AND end_date >= $7) OR (start_date <= $8 AND end_date >= $9))") ||
+		!hasArg(listed[0], "2026-05-20") || !hasArg(listed[0], "2026-06-10") {
+		t.Errorf("query = %+v, want requests ending from today covering either date", listed)
+	}
+
+	// Without dates there is nothing to look up
+	repo, rec = newRecordingRepository(t)
+	if requests, err := repo.ListLeaveRequestsCovering(orgID, nil, today); err != nil || len(requests) != 0 {
+		t.Errorf("got %v (%v), want no requests", requests, err)
+	}
+	if len(rec.find("leave_requests")) != 0 {
+		t.Error("a query was sent without dates")
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestClaimJob(t *testing.T) {
+	firstStarted := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		status       string
+		attempts     int
+		startedAt    interface{}
+		wantAttempts int
+	}{
+		{name: "queued", status: domain.JobStatusQueued, startedAt: nil, wantAttempts: 1},
+		{name: "lease expired", status: domain.JobStatusRunning, attempts: 1, startedAt: firstStarted, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			jobID := uuid.New()
+			rec.respond(`SELECT * FROM "jobs"`, []string{"id", "status", "attempts", "started_at"},
+				[]driver.Value{jobID.String(), tt.status, int64(tt.attempts), tt.startedAt})
+
+			before := time.Now()
+			job, err := repo.ClaimJob(time.Minute)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if job == nil || job.ID != jobID {
+				t.Fatalf("claimed %+v, want job %s", job, jobID)
+			}
+
+			if job.Status != domain.JobStatusRunning || job.Attempts != tt.wantAttempts {
+				t.Errorf("claimed job is %s on attempt %d, want running on attempt %d", job.Status, job.Attempts, tt.wantAttempts)
+			}
+			if job.LeaseExpiresAt == nil || job.LeaseExpiresAt.Before(before.Add(time.Minute)) {
+				t.Errorf("lease expires at %v, want a minute from now", job.LeaseExpiresAt)
+			}
+			// A job taken over keeps the time it first started
+			if started, ok := tt.startedAt.(time.Time); ok && !job.StartedAt.Equal(started) {
+				t.Errorf("started at %v, want %v", job.StartedAt, started)
+			} else if !ok && (job.StartedAt == nil || job.StartedAt.Before(before)) {
+				t.Errorf("started at %v, want now", job.StartedAt)
+			}
+
+			// Workers skip jobs another worker is claiming instead of
+			// waiting to claim the same one
+			claims := rec.find(`SELECT * FROM "jobs"`)
+			if len(claims) != 1 || !strings.HasSuffix(claims[0].SQL, "FOR UPDATE SKIP LOCKED") ||
+				!strings.Contains(claims[0].SQL, "status = $1 OR (status = $2 AND lease_expires_at < $3)") {
+				t.Errorf("claim query = %+v", claims)
+			}
+			saved := rec.find(`"attempts"=$`)
+			if len(saved) != 1 || !hasArg(saved[0], domain.JobStatusRunning) || !hasArg(saved[0], tt.wantAttempts) {
+				t.Errorf("claim was not saved: %+v", saved)
+			}
+		})
+	}
+}
+
+func TestClaimJobFailsExhaustedJobsFirst(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+
+	job, err := repo.ClaimJob(time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job != nil {
+		t.Errorf("claimed %+v from an empty queue", job)
+	}
+
+	rec.mu.Lock()
+	queries := rec.queries
+	rec.mu.Unlock()
+	if len(queries) < 2 || !strings.HasPrefix(queries[0].SQL, `UPDATE "jobs"`) ||
+		!strings.HasPrefix(queries[1].SQL, `SELECT * FROM "jobs"`) {
+		t.Fatalf("queries = %+v, want the exhausted jobs failed before claiming", queries)
+	}
+	failed := queries[0]
+	if !strings.Contains(failed.SQL, "attempts >= $") || !hasArg(failed, domain.JobMaxAttempts) ||
+		!hasArg(failed, domain.JobStatusFailed) {
+		t.Errorf("exhausted jobs update = %+v", failed)
+	}
+	if len(rec.find(`"attempts"=`)) != 0 {
+		t.Error("a job was claimed from an empty queue")
+	}
+}
+
+func TestSaveClaimedJobChecksTheAttempt(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		save func(repo *leaveRepository, job *domain.Job) error
+	}{
+		{name: "progress", save: func(repo *leaveRepository, job *domain.Job) error { return repo.UpdateJobProgress(job, time.Minute) }},
+		{name: "finish", save: func(repo *leaveRepository, job *domain.Job) error { return repo.FinishJob(job) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			job := &domain.Job{Base: domain.Base{ID: uuid.New()}, Status: domain.JobStatusCompleted, Attempts: 2}
+			if err := tt.save(repo, job); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Only the attempt still holding the job may save it, so a
+			// worker whose lease expired cannot overwrite its successor
+			query := rec.last(t)
+			if !strings.Contains(query.SQL, "WHERE (status = $") || !strings.Contains(query.SQL, "AND attempts = $") ||
+				!hasArg(query, domain.JobStatusRunning) || !hasArg(query, 2) {
+				t.Errorf("save is not guarded by the claim: %+v", query)
+			}
+		})
+	}
+}
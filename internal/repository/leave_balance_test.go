@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestLeaveBalanceRemainingDaysIsNeverWritten(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	rec.respond(`INSERT INTO "leave_balances"`, []string{"id"}, []driver.Value{uuid.New().String()})
+	rec.respond(`SELECT * FROM "leave_balances"`, []string{"id", "total_days"}, []driver.Value{uuid.New().String(), 20.0})
+	balance := &domain.LeaveBalance{
+		Base:          domain.Base{ID: uuid.New()},
+		EmployeeID:    uuid.New(),
+		LeaveTypeID:   uuid.New(),
+		Year:          2026,
+		TotalDays:     20,
+		UsedDays:      4,
+		PendingDays:   1.5,
+		RemainingDays: 99,
+	}
+
+	if err := repo.UpdateLeaveBalance(balance); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := repo.CreateMissingLeaveBalances([]*domain.LeaveBalance{{
+		EmployeeID:  uuid.New(),
+		LeaveTypeID: uuid.New(),
+		Year:        2026,
+		TotalDays:   20,
+	}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	writes := append(rec.find(`UPDATE "leave_balances"`), rec.find(`INSERT INTO "leave_balances"`)...)
+	if len(writes) != 2 {
+		t.Fatalf("got %d balance writes, want an update and an insert", len(writes))
+	}
+	for _, write := range writes {
+		if strings.Contains(write.SQL, "remaining_days") {
+			t.Errorf("the generated remaining_days column is written: %s", write.SQL)
+		}
+	}
+	if balance.RemainingDays != 14.5 {
+		t.Errorf("remaining days after save = %v, want 14.5", balance.RemainingDays)
+	}
+}
+
+func TestLeaveBalanceRemainingDaysAfterFind(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	rec.respond(`FROM "leave_balances"`, []string{"id", "total_days", "used_days", "pending_days", "remaining_days"},
+		[]driver.Value{uuid.New().String(), 25.0, 10.0, 2.0, 0.0})
+
+	balance, err := repo.GetLeaveBalance(uuid.New(), uuid.New(), 2026)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if balance.RemainingDays != 13 {
+		t.Errorf("remaining days = %v, want 13 from the buckets", balance.RemainingDays)
+	}
+}
+
+func TestCorrectLeaveBalance(t *testing.T) {
+	opening := 20.0
+	tests := []struct {
+		name        string
+		openingDays interface{}
+		stored      [3]float64
+		wantTotal   float64
+		wantChanges string
+	}{
+		{name: "drifted", openingDays: opening, stored: [3]float64{22, 3, 0}, wantTotal: 23.5,
+			wantChanges: "total 22.00 -> 23.50, used 3.00 -> 5.00, pending 0.00 -> 1.00"},
+		// Older balances fall back to the leave type's entitlement
+		{name: "opened before opening days were recorded", stored: [3]float64{23.5, 5, 1}, wantTotal: 21.5,
+			wantChanges: "total 23.50 -> 21.50"},
+		{name: "already right", openingDays: opening, stored: [3]float64{23.5, 5, 1}, wantTotal: 23.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			balanceID := uuid.New()
+			rec.respond(`SELECT * FROM "leave_balances"`,
+				[]string{"id", "year", "total_days", "used_days", "pending_days", "carried_over_days", "opening_days"},
+				[]driver.Value{balanceID.String(), int64(2026), tt.stored[0], tt.stored[1], tt.stored[2], 2.0, tt.openingDays})
+			// Five days taken, one awaiting approval and 1.5 days granted
+			rec.respond(`AS adjustments`, []string{"used", "pending", "adjustments"}, []driver.Value{5.0, 1.0, 1.5})
+			rec.respond(`INSERT INTO "leave_balance_adjustments"`, []string{"id"}, []driver.Value{uuid.New().String()})
+
+			discrepancy, err := repo.CorrectLeaveBalance(balanceID, 18)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			locks := rec.find(`SELECT * FROM "leave_balances"`)
+			if len(locks) != 1 || !strings.HasSuffix(locks[0].SQL, "FOR UPDATE") {
+				t.Errorf("the balance was not locked: %+v", locks)
+			}
+
+			if tt.wantChanges == "" {
+				if discrepancy != nil {
+					t.Errorf("corrected %s, want nothing", discrepancy.Changes)
+				}
+				if len(rec.find(`INSERT INTO "leave_balance_adjustments"`)) != 0 || len(rec.find(`UPDATE "leave_balances"`)) != 0 {
+					t.Error("a balance that adds up was changed")
+				}
+				return
+			}
+
+			if discrepancy == nil || !discrepancy.Applied || discrepancy.AdjustmentID == nil || discrepancy.Changes != tt.wantChanges {
+				t.Fatalf("discrepancy = %+v, want %q applied", discrepancy, tt.wantChanges)
+			}
+			expected := domain.BalanceFigures{TotalDays: tt.wantTotal, UsedDays: 5, PendingDays: 1}
+			if discrepancy.Expected != expected {
+				t.Errorf("expected figures = %+v, want %+v", discrepancy.Expected, expected)
+			}
+
+			// The correction documents the change without counting towards
+			// the total
+			corrections := rec.find(`INSERT INTO "leave_balance_adjustments"`)
+			if len(corrections) != 1 || !hasArg(corrections[0], domain.AdjustmentStatusCorrection) ||
+				!hasArg(corrections[0], tt.wantTotal-tt.stored[0]) || !hasArg(corrections[0], domain.SystemUserID) {
+				t.Errorf("correction = %+v", corrections)
+			}
+			updates := rec.find(`UPDATE "leave_balances"`)
+			if len(updates) != 1 || !hasArg(updates[0], tt.wantTotal) || !hasArg(updates[0], 5.0) || !hasArg(updates[0], 1.0) {
+				t.Errorf("balance was not overwritten with %+v: %+v", expected, updates)
+			}
+		})
+	}
+}
@@ -2,14 +2,82 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/Axontik/comin-leave-management-service/internal/domain"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrStatusChanged is returned when a leave request or balance adjustment
+// no longer has the status a transition was validated against
+var ErrStatusChanged = errors.New("leave request status changed concurrently")
+
+// ErrEmptyApproverPool is returned when a round-robin pool has no members to assign
+var ErrEmptyApproverPool = errors.New("approver pool has no members")
+
+// ErrAlreadyApproved is returned when an approver signs off on a quorum
+// request a second time
+var ErrAlreadyApproved = errors.New("approver already approved this leave request")
+
+// ErrNothingToTransfer is returned when a transfer of all remaining days
+// finds none left on the source balance
+var ErrNothingToTransfer = errors.New("no remaining days to transfer")
+
+// ErrDuplicateCompOff is returned when the employee already claimed
+// comp-off for the date worked
+var ErrDuplicateCompOff = errors.New("comp-off already requested for this date")
+
+// ErrDuplicateOptionalHolidayClaim is returned when the employee already
+// claimed the optional holiday
+var ErrDuplicateOptionalHolidayClaim = errors.New("optional holiday already claimed")
+
+// ErrOptionalHolidayQuotaReached is returned when a claim would take the
+// employee past the yearly optional holiday quota
+var ErrOptionalHolidayQuotaReached = errors.New("optional holiday quota reached")
+
+// InsufficientBalanceError is returned when a request needs more days than
+// the balance has left
+type InsufficientBalanceError struct {
+	Available float64
+	Requested float64
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("insufficient leave balance: %.2f days available, %.2f requested", e.Available, e.Requested)
+}
+
+// NegativeBalanceCapError is returned when booking a request would take a
+// balance further below zero than its leave type's max_negative_days
+type NegativeBalanceCapError struct {
+	// Remaining is the balance left once the request is booked
+	Remaining float64
+	Cap       float64
+}
+
+func (e *NegativeBalanceCapError) Error() string {
+	return fmt.Sprintf("negative balance cap exceeded: %.2f days remaining, cap is %.2f", e.Remaining, e.Cap)
+}
+
+// ActiveJobError is returned when a singleton job is queued while the
+// organization already has one of its type queued or running
+type ActiveJobError struct {
+	Job *domain.Job
+}
+
+func (e *ActiveJobError) Error() string {
+	return fmt.Sprintf("%s job %s is already %s", e.Job.Type, e.Job.ID, e.Job.Status)
+}
+
+// ErrJobLeaseLost is returned when a worker saves a job it no longer holds,
+// because its lease expired and the job was claimed again
+var ErrJobLeaseLost = errors.New("job lease lost")
+
 type LeaveRepository interface {
 	// LeaveType methods
 	CreateLeaveType(leaveType *domain.LeaveType) error
@@ -19,27 +87,196 @@ type LeaveRepository interface {
 	ListLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error)
 
 	// LeaveRequest methods
-	CreateLeaveRequest(request *domain.LeaveRequest) error
+	CreateLeaveRequest(request *domain.LeaveRequest, leaveType *domain.LeaveType, entitlement float64, provisional bool, history *domain.LeaveRequestHistory) error
 	GetLeaveRequest(id uuid.UUID) (*domain.LeaveRequest, error)
+	GetLeaveRequestIncludingDeleted(id uuid.UUID) (*domain.LeaveRequest, error)
 	UpdateLeaveRequest(request *domain.LeaveRequest) error
 	ListLeaveRequests(orgID, employeeID uuid.UUID, status string) ([]domain.LeaveRequest, error)
+	ListLeaveRequestsWithOptions(orgID uuid.UUID, params *domain.ListLeaveRequestsParams) ([]domain.LeaveRequest, int64, error)
 	GetOverlappingRequests(employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error)
+	ListLeaveRequestDays(orgID uuid.UUID, from, to time.Time, statuses []string) ([]domain.LeaveRequestDay, error)
+	ListEmployeeLeaveRequestDays(orgID uuid.UUID, employeeIDs []uuid.UUID, from, to time.Time, statuses []string) ([]domain.LeaveRequestDay, error)
+	ListLeaveRequestChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveRequest, error)
+	TransitionLeaveRequest(request *domain.LeaveRequest, fromStatus string, history *domain.LeaveRequestHistory) error
+	RecordQuorumApproval(request *domain.LeaveRequest, approval *domain.LeaveRequestApproval, quorum int, history *domain.LeaveRequestHistory) (int, error)
+	ReopenLeaveRequest(request *domain.LeaveRequest, fromStatus string, leaveType *domain.LeaveType, history *domain.LeaveRequestHistory) error
+	UpdatePendingLeaveRequest(request *domain.LeaveRequest, entitlement float64, provisional bool, history *domain.LeaveRequestHistory) error
+	UpdateExternalReferences(request *domain.LeaveRequest) error
+	FindExternalReferenceDuplicates(orgID, excludeID uuid.UUID, refs domain.ExternalReferences) (map[domain.ExternalReference][]uuid.UUID, error)
+	SoftDeleteLeaveRequest(request *domain.LeaveRequest, history *domain.LeaveRequestHistory) error
+	PurgeLeaveRequest(request *domain.LeaveRequest) error
+	PurgeOrganization(orgID uuid.UUID) error
+	RestoreLeaveRequest(request *domain.LeaveRequest, history *domain.LeaveRequestHistory) error
+	CreateLeaveRequestHistory(history *domain.LeaveRequestHistory) error
+	ListLeaveRequestHistory(leaveRequestID uuid.UUID) ([]domain.LeaveRequestHistory, error)
+	ListPendingLeaveRequests(orgID uuid.UUID) ([]domain.LeaveRequest, error)
 
 	// LeaveBalance methods
+	CreateLeaveBalance(balance *domain.LeaveBalance) error
 	GetLeaveBalance(employeeID, leaveTypeID uuid.UUID, year int) (*domain.LeaveBalance, error)
+	GetLeaveBalanceByID(id uuid.UUID) (*domain.LeaveBalance, error)
 	UpdateLeaveBalance(balance *domain.LeaveBalance) error
-	ListLeaveBalances(employeeID uuid.UUID) ([]domain.LeaveBalance, error)
+	ListLeaveBalances(orgID, employeeID uuid.UUID, year int) ([]domain.LeaveBalance, error)
+	ListOrgLeaveBalances(orgID uuid.UUID, params *domain.ListLeaveBalancesParams) ([]domain.LeaveBalance, int64, error)
+	ListEntitlementLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error)
+	InitializeYearlyBalance(orgID, employeeID, runID uuid.UUID, leaveTypes []domain.LeaveType, year int, hoursPerDay float64) (domain.YearlyResetCounts, error)
+	ListBalanceSnapshots(orgID uuid.UUID, params *domain.ListBalanceSnapshotsParams) ([]domain.BalanceSnapshot, int64, error)
+	ListOpeningBalanceSnapshots(orgID, employeeID uuid.UUID, year int) ([]domain.BalanceSnapshot, error)
+	ListExpiredCarryOverBalanceIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error)
+	ListEmployeeBalanceYears(orgID, employeeID uuid.UUID, fromYear, toYear int) ([]domain.LeaveBalance, error)
+	SumForfeitedCarryOver(balanceIDs []uuid.UUID) (map[uuid.UUID]float64, error)
+	SumLeaveTakenByYear(orgID, employeeID uuid.UUID, fromYear, toYear int) ([]domain.LeaveTakenByYear, error)
+	ExpireCarryOver(balanceID uuid.UUID, today time.Time) (*domain.LeaveBalanceAdjustment, error)
+	CreateMissingLeaveBalances(balances []*domain.LeaveBalance) ([]bool, error)
+	ListRecalculationBalances(orgID uuid.UUID, employeeID *uuid.UUID, year int) ([]domain.LeaveBalance, error)
+	ExpectedBalanceFigures(balance *domain.LeaveBalance, fallbackOpening float64) (domain.BalanceFigures, error)
+	CorrectLeaveBalance(balanceID uuid.UUID, fallbackOpening float64) (*domain.BalanceDiscrepancy, error)
+	ListAccrualLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error)
+	ListAccrualOrganizationIDs() ([]uuid.UUID, error)
+	ListLeaveTypeBalanceIDs(leaveTypeID uuid.UUID, year int) ([]uuid.UUID, error)
+	AccrueBalance(balanceID uuid.UUID, leaveType *domain.LeaveType, period string, hoursPerDay float64) (*domain.AccrualRun, error)
 
 	// Balance Adjustment methods
 	CreateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error
 	GetBalanceAdjustment(id uuid.UUID) (*domain.LeaveBalanceAdjustment, error)
 	UpdateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error
+	RevertBalanceAdjustment(id, revertedBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error)
+	ListDueBalanceAdjustmentIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error)
+	ListScheduledAdjustmentOrganizationIDs() ([]uuid.UUID, error)
+	SumScheduledAdjustments(balanceIDs []uuid.UUID) (map[uuid.UUID]float64, error)
 	ListBalanceAdjustments(balanceID uuid.UUID) ([]domain.LeaveBalanceAdjustment, error)
+	ListOrgBalanceAdjustments(orgID uuid.UUID, params *domain.ListBalanceAdjustmentsParams) ([]domain.LeaveBalanceAdjustment, int64, error)
+	ListRecentBalanceAdjustments(balanceIDs []uuid.UUID, limit int) ([]domain.LeaveBalanceAdjustment, error)
+	ListBalancesAdjustments(balanceIDs []uuid.UUID) ([]domain.LeaveBalanceAdjustment, error)
+	SumBalanceLedger(balance *domain.LeaveBalance) (count int64, adjustments, leave float64, err error)
+	ListBalanceLedgerEntries(balance *domain.LeaveBalance, opening float64, offset, limit int) ([]domain.BalanceLedgerEntry, error)
+	ListBalanceAdjustmentChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalanceAdjustment, error)
+	ListLeaveBalanceChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalance, error)
+
+	// Balance transfer methods
+	TransferLeaveBalance(orgID uuid.UUID, req *domain.BalanceTransferRequest, toEntitlement float64) (*domain.BalanceTransfer, error)
+	ListBalanceEmployeeIDs(leaveTypeID uuid.UUID, year int) ([]uuid.UUID, error)
+	CreateBalanceTransferJob(job *domain.BalanceTransferJob) error
+	UpdateBalanceTransferJob(job *domain.BalanceTransferJob) error
+	AddBalanceTransferJobFailure(failure *domain.BalanceTransferJobFailure) error
+	GetBalanceTransferJob(id uuid.UUID) (*domain.BalanceTransferJob, error)
+
+	// Background job methods
+	CreateJob(job *domain.Job) error
+	GetJob(id uuid.UUID) (*domain.Job, error)
+	ClaimJob(lease time.Duration) (*domain.Job, error)
+	UpdateJobProgress(job *domain.Job, lease time.Duration) error
+	FinishJob(job *domain.Job) error
+	CountJobsByStatus() (map[string]int64, error)
+
+	// Holiday methods
+	CreateHoliday(holiday *domain.Holiday) error
+	GetHoliday(id uuid.UUID) (*domain.Holiday, error)
+	ListHolidaysOnDate(orgID uuid.UUID, date time.Time) ([]domain.Holiday, error)
+	UpdateHoliday(holiday *domain.Holiday) error
+	DeleteHoliday(id uuid.UUID) error
+	ListHolidays(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.Holiday, error)
+	ListLeaveRequestsCovering(orgID uuid.UUID, dates []time.Time, endingFrom time.Time) ([]domain.LeaveRequest, error)
+	RecalculateLeaveRequestDays(request *domain.LeaveRequest, previousDays float64, history *domain.LeaveRequestHistory) error
+	ListHolidayChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.Holiday, error)
+
+	// Report methods
+	GetLeaveStats(orgID uuid.UUID, startDate, endDate time.Time) (*domain.LeaveStats, error)
+	ListEmployeeLeaveByType(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.EmployeeLeaveByType, error)
+	ListMonthlyLeave(orgID uuid.UUID, leaveTypeID *uuid.UUID, startDate, endDate time.Time) ([]domain.MonthlyStats, error)
+	SummarizeLeaveRegister(orgID uuid.UUID, leaveTypeID *uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRegisterType, error)
+	ListLeaveRegisterPage(orgID, leaveTypeID uuid.UUID, startDate, endDate time.Time, after *domain.LeaveRequest, limit int) ([]domain.LeaveRequest, error)
+	ListEmployeeLeaveRequestsStarting(orgID, employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error)
+	ListAbsences(orgID uuid.UUID, leaveTypeIDs []uuid.UUID, startDate, endDate time.Time) ([]domain.Absence, error)
+	ListApproverDecisions(orgID uuid.UUID, from, to time.Time, excludeBackdated bool) ([]domain.ApproverDecision, error)
+	CountOverduePendingByApprover(orgID uuid.UUID, now time.Time) (map[uuid.UUID]int, error)
+
+	// Organization settings methods
+	GetOrganizationSettings(orgID uuid.UUID) (*domain.OrganizationSettings, error)
+	SaveOrganizationSettings(settings *domain.OrganizationSettings) error
+
+	// Approver pool methods
+	CreateApproverPool(pool *domain.ApproverPool) error
+	GetApproverPool(id uuid.UUID) (*domain.ApproverPool, error)
+	ListApproverPools(orgID uuid.UUID) ([]domain.ApproverPool, error)
+	AddApproverPoolMember(member *domain.ApproverPoolMember) error
+	RemoveApproverPoolMember(poolID, userID uuid.UUID) error
+	NextPoolApprover(poolID uuid.UUID) (uuid.UUID, error)
+	IsApproverPoolMember(poolID, userID uuid.UUID) (bool, error)
+
+	// Comp-off methods
+	CreateCompOffRequest(request *domain.CompOffRequest) error
+	GetCompOffRequest(id uuid.UUID) (*domain.CompOffRequest, error)
+	ListCompOffRequests(orgID uuid.UUID, params *domain.ListCompOffRequestsParams) ([]domain.CompOffRequest, int64, error)
+	RejectCompOffRequest(request *domain.CompOffRequest) error
+	ApproveCompOffRequest(request *domain.CompOffRequest, leaveTypeID uuid.UUID, year int, opening, credit float64) error
+	ListExpiredCompOffIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error)
+	ExpireCompOff(id uuid.UUID, today time.Time) (*domain.LeaveBalanceAdjustment, error)
+
+	// Absence alert methods
+	ListAbsenceAlertOrganizationIDs() ([]uuid.UUID, error)
+	ListAbsenceMonitoredLeaveTypeIDs(orgID uuid.UUID) ([]uuid.UUID, error)
+	ListUnclearedAbsenceAlerts(orgID uuid.UUID) ([]domain.AbsenceAlert, error)
+	CreateAbsenceAlert(alert *domain.AbsenceAlert) (bool, error)
+	ClearAbsenceAlert(id uuid.UUID, at time.Time) error
+	MarkAbsenceAlertNotified(id uuid.UUID, at time.Time) error
+	GetAbsenceAlert(id uuid.UUID) (*domain.AbsenceAlert, error)
+	ListAbsenceAlerts(orgID uuid.UUID, params *domain.ListAbsenceAlertsParams) ([]domain.AbsenceAlert, int64, error)
+	AcknowledgeAbsenceAlert(alert *domain.AbsenceAlert) error
+	ResolveAbsenceAlert(alert *domain.AbsenceAlert) error
+
+	// Delegation methods
+	CreateDelegation(delegation *domain.Delegation) (bool, error)
+	GetDelegation(id uuid.UUID) (*domain.Delegation, error)
+	GetLeaveRequestDelegation(leaveRequestID uuid.UUID) (*domain.Delegation, error)
+	ListDelegations(orgID uuid.UUID, params *domain.ListDelegationsParams) ([]domain.Delegation, int64, error)
+	UpdateDelegation(delegation *domain.Delegation, allowed func(*domain.Delegation) bool) error
+	MarkDelegationNotified(id uuid.UUID, at time.Time) error
+	ListOverdueDelegations(now time.Time) ([]domain.Delegation, error)
+	ListDelegators(orgID, delegateID uuid.UUID, day time.Time) ([]uuid.UUID, error)
+
+	// Optional holiday methods
+	CreateOptionalHolidayClaim(claim *domain.OptionalHolidayClaim, year, quota int) error
+	GetOptionalHolidayClaim(employeeID, holidayID uuid.UUID) (*domain.OptionalHolidayClaim, error)
+	DeleteOptionalHolidayClaim(id uuid.UUID) error
+	ListOptionalHolidayClaims(orgID uuid.UUID, employeeID *uuid.UUID, from, to time.Time) ([]domain.OptionalHolidayClaim, error)
 
 	HasActiveLeaveRequests(leaveTypeID uuid.UUID) (bool, error)
 	ListLeaveTypesWithOptions(orgID uuid.UUID, params *domain.ListLeaveTypesParams) ([]domain.LeaveType, int64, error)
 }
 
+// Change feeds page through rows by (updated_at, id), but a transaction
+// that stamps updated_at before another and commits after it would land
+// behind a watermark a consumer already passed. Feeds therefore stop at a
+// horizon: the start of the oldest transaction still writing to the
+// database, or now when there is none, less changesSettleDelay as a margin
+// for clock skew between the service, which stamps updated_at, and the
+// database. A long write, such as a yearly reset, holds feeds back until it
+// commits rather than letting them skip its rows.
+//
+// pg_stat_activity only shows other roles' transactions to members of
+// pg_read_all_stats, so every writer must use the service's role or the
+// role must be granted pg_read_all_stats.
+const changesSettleDelay = 5 * time.Second
+
+const changesHorizonSQL = `
+SELECT LEAST(now(), COALESCE(MIN(xact_start), now())) - make_interval(secs => @margin)
+FROM pg_stat_activity
+WHERE backend_xid IS NOT NULL
+  AND pid <> pg_backend_pid()
+  AND datname = current_database()`
+
+// changesHorizon returns the latest updated_at a change feed may return
+func (r *leaveRepository) changesHorizon() (time.Time, error) {
+	var horizon time.Time
+	err := r.db.Raw(changesHorizonSQL, map[string]interface{}{"margin": changesSettleDelay.Seconds()}).
+		Row().Scan(&horizon)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the change feed horizon: %w", err)
+	}
+	return horizon, nil
+}
+
 type leaveRepository struct {
 	db *gorm.DB
 }
@@ -148,29 +385,109 @@ func (r *leaveRepository) ListLeaveTypesWithOptions(orgID uuid.UUID, params *dom
 }
 
 // LeaveRequest implementation
-func (r *leaveRepository) CreateLeaveRequest(request *domain.LeaveRequest) error {
+// CreateLeaveRequest locks the employee's balance for the request year so the
+// sufficiency check and the balance increment cannot race another submission.
+// A missing balance is first created with the given entitlement, in the
+// leave type's unit, and marked provisional when its year has not been
+// opened by the yearly reset yet.
+// Requests created already approved book their days as used rather than
+// pending. history is optional and is linked to the new request. The
+// resulting balance is attached to request.Balance. Leave types that do not
+// track a balance skip all of this.
+func (r *leaveRepository) CreateLeaveRequest(request *domain.LeaveRequest, leaveType *domain.LeaveType, entitlement float64, provisional bool, history *domain.LeaveRequestHistory) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
+		if !leaveType.TracksBalance {
+			if err := tx.Create(request).Error; err != nil {
+				return err
+			}
+			return createHistory(tx, request, history)
+		}
+
+		balance, err := lockRequestBalance(tx, request, entitlement, provisional)
+		if err != nil {
+			return err
+		}
+
+		available := balance.TotalDays - balance.UsedDays - balance.PendingDays
+		if err := checkBalance(leaveType, available, request.Days); err != nil {
+			return err
+		}
+
 		if err := tx.Create(request).Error; err != nil {
 			return err
 		}
 
 		// Update leave balance
-		balance := &domain.LeaveBalance{}
-		err := tx.Where("employee_id = ? AND leave_type_id = ? AND year = ?",
-			request.EmployeeID, request.LeaveTypeID, request.StartDate.Year()).
-			First(balance).Error
-		if err != nil {
+		if request.Status == domain.LeaveStatusApproved {
+			if err := tx.Model(balance).
+				Update("used_days", gorm.Expr("used_days + ?", request.Days)).Error; err != nil {
+				return err
+			}
+		} else if err := shiftPendingDays(tx, request, request.Days); err != nil {
 			return err
 		}
 
-		balance.PendingDays += request.Days
-		return tx.Save(balance).Error
+		if err := tx.First(balance, "id = ?", balance.ID).Error; err != nil {
+			return err
+		}
+		request.Balance = balance
+
+		return createHistory(tx, request, history)
 	})
 }
 
+// lockRequestBalance locks the balance a request is booked against, first
+// creating it with the given entitlement when the employee has none for
+// the leave type and year
+func lockRequestBalance(tx *gorm.DB, request *domain.LeaveRequest, entitlement float64, provisional bool) (*domain.LeaveBalance, error) {
+	// Concurrent first requests both attempt the insert; the unique
+	// (employee_id, leave_type_id, year) key keeps a single row
+	seed := &domain.LeaveBalance{
+		OrganizationID: request.OrganizationID,
+		EmployeeID:     request.EmployeeID,
+		LeaveTypeID:    request.LeaveTypeID,
+		Year:           request.StartDate.Year(),
+		TotalDays:      entitlement,
+		Provisional:    provisional,
+		OpeningDays:    &entitlement,
+	}
+	if err := tx.Omit("LeaveType").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "employee_id"}, {Name: "leave_type_id"}, {Name: "year"}},
+			DoNothing: true,
+		}).
+		Create(seed).Error; err != nil {
+		return nil, err
+	}
+
+	var balance domain.LeaveBalance
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+			request.EmployeeID, request.LeaveTypeID, request.StartDate.Year()).
+		First(&balance).Error; err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// createHistory links an optional history entry to a new request and saves it
+func createHistory(tx *gorm.DB, request *domain.LeaveRequest, history *domain.LeaveRequestHistory) error {
+	if history == nil {
+		return nil
+	}
+	history.LeaveRequestID = request.ID
+	return tx.Create(history).Error
+}
+
 func (r *leaveRepository) GetLeaveRequest(id uuid.UUID) (*domain.LeaveRequest, error) {
 	var request domain.LeaveRequest
-	err := r.db.Preload("LeaveType").First(&request, "id = ?", id).Error
+	err := r.db.Preload("LeaveType").Preload("Breakdown", orderByDate).Preload("Approvals").First(&request, "id = ?", id).Error
+	return &request, err
+}
+
+func (r *leaveRepository) GetLeaveRequestIncludingDeleted(id uuid.UUID) (*domain.LeaveRequest, error) {
+	var request domain.LeaveRequest
+	err := r.db.Unscoped().Preload("LeaveType").Preload("Breakdown", orderByDate).Preload("Approvals").First(&request, "id = ?", id).Error
 	return &request, err
 }
 
@@ -208,230 +525,1797 @@ func (r *leaveRepository) UpdateLeaveRequest(request *domain.LeaveRequest) error
 	})
 }
 
-func (r *leaveRepository) ListLeaveRequests(orgID, employeeID uuid.UUID, status string) ([]domain.LeaveRequest, error) {
-	var requests []domain.LeaveRequest
-	query := r.db.Preload("LeaveType").Where("organization_id = ?", orgID)
+// TransitionLeaveRequest moves a request out of fromStatus into request.Status,
+// shifting its days between the balance buckets and recording the history
+// entry in the same transaction. The row is locked first so two concurrent
+// transitions cannot both apply their balance changes.
+func (r *leaveRepository) TransitionLeaveRequest(request *domain.LeaveRequest, fromStatus string, history *domain.LeaveRequestHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return transitionLeaveRequest(tx, request, fromStatus, history)
+	})
+}
 
-	if employeeID != uuid.Nil {
-		query = query.Where("employee_id = ?", employeeID)
+func transitionLeaveRequest(tx *gorm.DB, request *domain.LeaveRequest, fromStatus string, history *domain.LeaveRequestHistory) error {
+	current := &domain.LeaveRequest{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(current, "id = ?", request.ID).Error; err != nil {
+		return err
 	}
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if current.Status != fromStatus {
+		return ErrStatusChanged
 	}
 
-	err := query.Order("created_at DESC").Find(&requests).Error
-	return requests, err
-}
+	tracked, err := tracksBalance(tx, current.LeaveTypeID)
+	if err != nil {
+		return err
+	}
 
-func (r *leaveRepository) GetOverlappingRequests(employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error) {
-	var requests []domain.LeaveRequest
-	err := r.db.Where("employee_id = ? AND status IN (?) AND "+
-		"((start_date BETWEEN ? AND ?) OR (end_date BETWEEN ? AND ?) OR (start_date <= ? AND end_date >= ?))",
-		employeeID, []string{"pending", "approved"},
-		startDate, endDate, startDate, endDate, startDate, endDate).
-		Find(&requests).Error
-	return requests, err
-}
+	// The cap may have been lowered, or the balance cut, since the
+	// request was booked
+	if tracked && request.Status == domain.LeaveStatusApproved {
+		if err := checkNegativeBalanceCap(tx, current); err != nil {
+			return err
+		}
+	}
 
-// LeaveBalance methods
-func (r *leaveRepository) GetLeaveBalance(employeeID, leaveTypeID uuid.UUID, year int) (*domain.LeaveBalance, error) {
-	var balance domain.LeaveBalance
-	err := r.db.Preload("LeaveType").
-		Where("employee_id = ? AND leave_type_id = ? AND year = ?",
-			employeeID, leaveTypeID, year).
-		First(&balance).Error
-	return &balance, err
-}
+	if updates := balanceUpdates(fromStatus, request.Status, current.Days); tracked && updates != nil {
+		result := tx.Model(&domain.LeaveBalance{}).
+			Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+				current.EmployeeID, current.LeaveTypeID, current.StartDate.Year()).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
 
-func (r *leaveRepository) UpdateLeaveBalance(balance *domain.LeaveBalance) error {
-	return r.db.Save(balance).Error
-}
+	if err := tx.Model(request).
+		Select("Status", "ApprovedBy", "ApprovedAt").
+		Updates(request).Error; err != nil {
+		return err
+	}
 
-func (r *leaveRepository) ListLeaveBalances(employeeID uuid.UUID) ([]domain.LeaveBalance, error) {
-	var balances []domain.LeaveBalance
-	err := r.db.Preload("LeaveType").
-		Where("employee_id = ? AND year = ?", employeeID, time.Now().Year()).
-		Find(&balances).Error
-	return balances, err
+	return tx.Create(history).Error
 }
 
-// Holiday methods
-func (r *leaveRepository) CreateHoliday(holiday *domain.Holiday) error {
-	return r.db.Create(holiday).Error
-}
+// RecordQuorumApproval adds the approver's sign-off to a pending request of
+// a quorum leave type. The request row stays locked while approvals are
+// counted, so exactly quorum approvals are recorded: the one meeting the
+// quorum approves the request in the same transaction, setting its status
+// and decision fields, and later ones find it no longer pending. Approvals
+// short of the quorum are recorded in the history as partial. It returns
+// the number of approvals after this one.
+func (r *leaveRepository) RecordQuorumApproval(request *domain.LeaveRequest, approval *domain.LeaveRequestApproval, quorum int, history *domain.LeaveRequestHistory) (int, error) {
+	var count int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		current := &domain.LeaveRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != domain.LeaveStatusPending {
+			return ErrStatusChanged
+		}
 
-func (r *leaveRepository) GetHoliday(id uuid.UUID) (*domain.Holiday, error) {
-	var holiday domain.Holiday
-	err := r.db.First(&holiday, "id = ?", id).Error
-	return &holiday, err
-}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(approval)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyApproved
+		}
 
-func (r *leaveRepository) UpdateHoliday(holiday *domain.Holiday) error {
-	return r.db.Save(holiday).Error
-}
+		if err := tx.Model(&domain.LeaveRequestApproval{}).
+			Where("leave_request_id = ?", request.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
 
-func (r *leaveRepository) DeleteHoliday(id uuid.UUID) error {
-	return r.db.Delete(&domain.Holiday{}, "id = ?", id).Error
+		if int(count) < quorum {
+			history.Action = domain.LeaveActionPartialApprove
+			history.Status = domain.LeaveStatusPending
+			return tx.Create(history).Error
+		}
+
+		now := time.Now()
+		request.Status = domain.LeaveStatusApproved
+		request.ApprovedBy = &approval.ApproverID
+		request.ApprovedAt = &now
+		return transitionLeaveRequest(tx, request, domain.LeaveStatusPending, history)
+	})
+	return int(count), err
 }
 
-func (r *leaveRepository) ListHolidays(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.Holiday, error) {
-	var holidays []domain.Holiday
-	query := r.db.Where("organization_id = ?", orgID)
+// ReopenLeaveRequest moves a rejected or cancelled request back to pending,
+// booking its days as pending again after checking the balance still covers
+// them. The decision fields are cleared; the history keeps the old decision.
+func (r *leaveRepository) ReopenLeaveRequest(request *domain.LeaveRequest, fromStatus string, leaveType *domain.LeaveType, history *domain.LeaveRequestHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		current := &domain.LeaveRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != fromStatus {
+			return ErrStatusChanged
+		}
 
-	if !startDate.IsZero() && !endDate.IsZero() {
-		query = query.Where("date BETWEEN ? AND ?", startDate, endDate)
-	}
+		if leaveType.TracksBalance {
+			var balance domain.LeaveBalance
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+					current.EmployeeID, current.LeaveTypeID, current.StartDate.Year()).
+				First(&balance).Error; err != nil {
+				return err
+			}
 
-	err := query.Order("date ASC").Find(&holidays).Error
-	return holidays, err
-}
+			available := balance.TotalDays - balance.UsedDays - balance.PendingDays
+			if err := checkBalance(leaveType, available, current.Days); err != nil {
+				return err
+			}
 
-// Leave Request History methods
-func (r *leaveRepository) CreateLeaveRequestHistory(history *domain.LeaveRequestHistory) error {
-	return r.db.Create(history).Error
+			if err := shiftPendingDays(tx, current, current.Days); err != nil {
+				return err
+			}
+		}
+
+		// Approvals toward a quorum were given on the earlier round
+		if err := deleteQuorumApprovals(tx, request); err != nil {
+			return err
+		}
+
+		if err := tx.Model(request).
+			Select("Status", "ApprovedBy", "ApprovedAt", "AssignedApproverID").
+			Updates(request).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(history).Error
+	})
 }
 
-func (r *leaveRepository) ListLeaveRequestHistory(leaveRequestID uuid.UUID) ([]domain.LeaveRequestHistory, error) {
-	var history []domain.LeaveRequestHistory
-	err := r.db.Where("leave_request_id = ?", leaveRequestID).
-		Order("created_at DESC").
-		Find(&history).Error
-	return history, err
+func deleteQuorumApprovals(tx *gorm.DB, request *domain.LeaveRequest) error {
+	request.Approvals = nil
+	return tx.Where("leave_request_id = ?", request.ID).Delete(&domain.LeaveRequestApproval{}).Error
 }
 
-// Leave Balance operations
-func (r *leaveRepository) InitializeYearlyBalance(orgID uuid.UUID, year int) error {
+// UpdatePendingLeaveRequest saves new dates, leave type and reason on a
+// pending request. Its pending days are released from the balance they were
+// booked against and booked against the balance for the new type and year,
+// which is locked and checked like on create and, when missing, created
+// with the given entitlement.
+func (r *leaveRepository) UpdatePendingLeaveRequest(request *domain.LeaveRequest, entitlement float64, provisional bool, history *domain.LeaveRequestHistory) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Get all active employees and leave types
-		var leaveTypes []domain.LeaveType
-		if err := tx.Where("organization_id = ?", orgID).Find(&leaveTypes).Error; err != nil {
-			return err
-		}
-
-		// Query to get all active employees from the employee service
-		// This would typically be done through a service client
-		var employeeIDs []uuid.UUID
-		// TODO: Get employee IDs from employee service
-
-		// Create balances for each employee and leave type
-		for _, empID := range employeeIDs {
-			for _, leaveType := range leaveTypes {
-				balance := &domain.LeaveBalance{
-					OrganizationID: orgID,
-					EmployeeID:     empID,
-					LeaveTypeID:    leaveType.ID,
-					Year:           year,
-					TotalDays:      float64(leaveType.DefaultDays),
-					UsedDays:       0,
-					PendingDays:    0,
-				}
-				if err := tx.Create(balance).Error; err != nil {
-					return err
-				}
+		current := &domain.LeaveRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != domain.LeaveStatusPending {
+			return ErrStatusChanged
+		}
+
+		if tracked, err := tracksBalance(tx, current.LeaveTypeID); err != nil {
+			return err
+		} else if tracked {
+			if err := shiftPendingDays(tx, current, -current.Days); err != nil {
+				return err
+			}
+		}
+		var leaveType domain.LeaveType
+		if err := tx.First(&leaveType, "id = ?", request.LeaveTypeID).Error; err != nil {
+			return err
+		}
+		if leaveType.TracksBalance {
+			// Checked after the old days are released, so an edit within
+			// the same balance is only charged its difference
+			balance, err := lockRequestBalance(tx, request, entitlement, provisional)
+			if err != nil {
+				return err
+			}
+			available := balance.TotalDays - balance.UsedDays - balance.PendingDays
+			if err := checkBalance(&leaveType, available, request.Days); err != nil {
+				return err
+			}
+			if err := shiftPendingDays(tx, request, request.Days); err != nil {
+				return err
 			}
 		}
-		return nil
-	})
-}
 
-func (r *leaveRepository) AdjustLeaveBalance(balance *domain.LeaveBalance, adjustment float64, reason string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		balance.TotalDays += adjustment
+		if err := replaceLeaveRequestDays(tx, request); err != nil {
+			return err
+		}
 
-		// Create balance adjustment history
-		history := &domain.LeaveBalanceAdjustment{
-			LeaveBalanceID: balance.ID,
-			Adjustment:     adjustment,
-			Reason:         reason,
-			PerformedBy:    uuid.UUID{}, // TODO: Get from context
+		// Approvals toward a quorum were given on the old dates
+		if err := deleteQuorumApprovals(tx, request); err != nil {
+			return err
 		}
 
-		if err := tx.Create(history).Error; err != nil {
+		if err := tx.Model(request).
+			Select("LeaveTypeID", "AssignedApproverID", "StartDate", "EndDate", "StartHalf", "EndHalf",
+				"Hours", "StartTime", "Days", "RawDays", "SandwichedDays", "Reason", "Backdated", "HasConflicts",
+				"ProbationOverridden").
+			Updates(request).Error; err != nil {
 			return err
 		}
 
-		return tx.Save(balance).Error
+		return tx.Create(history).Error
 	})
 }
 
-// Reporting methods
-func (r *leaveRepository) GetLeaveStats(orgID uuid.UUID, startDate, endDate time.Time) (*domain.LeaveStats, error) {
-	var stats domain.LeaveStats
+// UpdateExternalReferences saves the external references of a request
+func (r *leaveRepository) UpdateExternalReferences(request *domain.LeaveRequest) error {
+	request.UpdatedAt = time.Now()
+	return r.db.Model(request).
+		Select("ExternalReferences", "UpdatedAt").
+		Updates(request).Error
+}
 
-	// Total leave requests
-	err := r.db.Model(&domain.LeaveRequest{}).
-		Where("organization_id = ? AND start_date BETWEEN ? AND ?",
-			orgID, startDate, endDate).
-		Select("COUNT(*) as total_requests, " +
-			"SUM(CASE WHEN status = 'approved' THEN days ELSE 0 END) as total_days_taken").
-		Scan(&stats).Error
+// FindExternalReferenceDuplicates returns, per reference, the other requests
+// of the organization already carrying the same system and reference
+func (r *leaveRepository) FindExternalReferenceDuplicates(orgID, excludeID uuid.UUID, refs domain.ExternalReferences) (map[domain.ExternalReference][]uuid.UUID, error) {
+	duplicates := make(map[domain.ExternalReference][]uuid.UUID)
+	for _, ref := range refs {
+		var ids []uuid.UUID
+		err := r.db.Model(&domain.LeaveRequest{}).
+			Where("organization_id = ? AND id <> ? AND external_references @> ?::jsonb",
+				orgID, excludeID, externalRefFilter(domain.ExternalReferences{ref})).
+			Order("created_at").
+			Pluck("id", &ids).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			duplicates[ref] = ids
+		}
+	}
+	return duplicates, nil
+}
 
-	if err != nil {
-		return nil, err
+// externalRefFilter renders references as a JSONB containment pattern,
+// leaving out an empty system so it matches any
+func externalRefFilter(refs domain.ExternalReferences) string {
+	pattern := make([]map[string]string, 0, len(refs))
+	for _, ref := range refs {
+		p := map[string]string{"reference": ref.Reference}
+		if ref.System != "" {
+			p["system"] = ref.System
+		}
+		pattern = append(pattern, p)
 	}
+	data, _ := json.Marshal(pattern)
+	return string(data)
+}
 
-	// Leave by type
-	err = r.db.Model(&domain.LeaveRequest{}).
-		Joins("JOIN leave_types ON leave_requests.leave_type_id = leave_types.id").
-		Where("leave_requests.organization_id = ? AND leave_requests.start_date BETWEEN ? AND ?",
-			orgID, startDate, endDate).
-		Group("leave_types.name").
-		Select("leave_types.name, COUNT(*) as count, SUM(days) as total_days").
-		Scan(&stats.LeaveByType).Error
+// SoftDeleteLeaveRequest hides a rejected or cancelled request. updated_at is
+// bumped with deleted_at so change feeds pick up the deletion.
+func (r *leaveRepository) SoftDeleteLeaveRequest(request *domain.LeaveRequest, history *domain.LeaveRequestHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		current := &domain.LeaveRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+		if !current.CanDelete() {
+			return ErrStatusChanged
+		}
 
-	return &stats, err
+		now := time.Now()
+		if err := tx.Model(&domain.LeaveRequest{}).
+			Where("id = ?", request.ID).
+			Updates(map[string]interface{}{"deleted_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		request.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		request.UpdatedAt = now
+
+		return tx.Create(history).Error
+	})
 }
 
-func (r *leaveRepository) CreateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error {
+// PurgeLeaveRequest deletes a pending request nobody has voted on,
+// together with its days and history, and releases its pending days. It
+// returns ErrStatusChanged when the request was decided or voted on
+// concurrently.
+func (r *leaveRepository) PurgeLeaveRequest(request *domain.LeaveRequest) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(adjustment).Error; err != nil {
+		current := &domain.LeaveRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != domain.LeaveStatusPending {
+			return ErrStatusChanged
+		}
+		var votes int64
+		if err := tx.Model(&domain.LeaveRequestApproval{}).
+			Where("leave_request_id = ?", request.ID).
+			Count(&votes).Error; err != nil {
 			return err
 		}
+		if votes > 0 {
+			return ErrStatusChanged
+		}
 
-		if adjustment.Status == domain.AdjustmentStatusApproved {
-			balance := &domain.LeaveBalance{}
-			if err := tx.First(balance, adjustment.LeaveBalanceID).Error; err != nil {
+		tracked, err := tracksBalance(tx, current.LeaveTypeID)
+		if err != nil {
+			return err
+		}
+		if tracked {
+			if err := shiftPendingDays(tx, current, -current.Days); err != nil {
 				return err
 			}
-
-			balance.TotalDays += adjustment.Adjustment
-			return tx.Save(balance).Error
 		}
 
-		return nil
+		return purgeLeaveRequests(tx, []uuid.UUID{request.ID})
 	})
 }
 
-func (r *leaveRepository) GetBalanceAdjustment(id uuid.UUID) (*domain.LeaveBalanceAdjustment, error) {
-	var adjustment domain.LeaveBalanceAdjustment
-	err := r.db.Preload("LeaveBalance").First(&adjustment, "id = ?", id).Error
-	return &adjustment, err
+// purgeLeaveRequests deletes the requests, ids or a subquery selecting
+// them, soft-deleted ones included, with their history. Their days and
+// approvals are removed by the cascade; balances are left to the caller.
+func purgeLeaveRequests(tx *gorm.DB, ids interface{}) error {
+	if err := tx.Where("leave_request_id IN (?)", ids).
+		Delete(&domain.LeaveRequestHistory{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&domain.LeaveRequest{}, "id IN (?)", ids).Error
 }
 
-func (r *leaveRepository) UpdateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error {
+// purgeOrganizationSQL deletes what purgeLeaveRequests leaves of an
+// organization, children before the rows they reference
+var purgeOrganizationSQL = []string{
+	`DELETE FROM comp_off_requests WHERE organization_id = @org`,
+	`DELETE FROM accrual_runs WHERE organization_id = @org`,
+	`DELETE FROM balance_snapshots WHERE organization_id = @org`,
+	`DELETE FROM leave_balance_adjustments WHERE leave_balance_id IN
+		(SELECT id FROM leave_balances WHERE organization_id = @org)`,
+	`DELETE FROM leave_balances WHERE organization_id = @org`,
+	`DELETE FROM balance_transfer_jobs WHERE organization_id = @org`,
+	`DELETE FROM optional_holiday_claims WHERE organization_id = @org`,
+	`DELETE FROM holidays WHERE organization_id = @org`,
+	`DELETE FROM organization_settings WHERE organization_id = @org`,
+	`DELETE FROM leave_types WHERE organization_id = @org`,
+	`DELETE FROM approver_pools WHERE organization_id = @org`,
+	`DELETE FROM absence_alerts WHERE organization_id = @org`,
+	`DELETE FROM jobs WHERE organization_id = @org`,
+}
+
+// PurgeOrganization deletes all of an organization's leave data, leaving
+// no history, in one transaction: its requests through the same path as
+// PurgeLeaveRequest, then balances, holidays, settings, leave types and
+// the rest
+func (r *leaveRepository) PurgeOrganization(orgID uuid.UUID) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		oldAdjustment := &domain.LeaveBalanceAdjustment{}
-		if err := tx.First(oldAdjustment, adjustment.ID).Error; err != nil {
+		requests := tx.Unscoped().Model(&domain.LeaveRequest{}).
+			Select("id").
+			Where("organization_id = ?", orgID)
+		if err := purgeLeaveRequests(tx, requests); err != nil {
 			return err
 		}
-
-		if oldAdjustment.Status != adjustment.Status && adjustment.Status == domain.AdjustmentStatusApproved {
-			balance := &domain.LeaveBalance{}
-			if err := tx.First(balance, adjustment.LeaveBalanceID).Error; err != nil {
+		for _, statement := range purgeOrganizationSQL {
+			if err := tx.Exec(statement, map[string]interface{}{"org": orgID}).Error; err != nil {
 				return err
 			}
+		}
+		return nil
+	})
+}
 
-			balance.TotalDays += adjustment.Adjustment
-			if err := tx.Save(balance).Error; err != nil {
-				return err
-			}
+// RestoreLeaveRequest brings back a soft-deleted request
+func (r *leaveRepository) RestoreLeaveRequest(request *domain.LeaveRequest, history *domain.LeaveRequestHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Unscoped().Model(&domain.LeaveRequest{}).
+			Where("id = ? AND deleted_at IS NOT NULL", request.ID).
+			Updates(map[string]interface{}{"deleted_at": nil, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
 		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		request.DeletedAt = gorm.DeletedAt{}
+		request.UpdatedAt = now
 
-		return tx.Save(adjustment).Error
+		return tx.Create(history).Error
 	})
 }
 
-func (r *leaveRepository) ListBalanceAdjustments(balanceID uuid.UUID) ([]domain.LeaveBalanceAdjustment, error) {
+// replaceLeaveRequestDays swaps the stored day breakdown of a request for request.Breakdown
+func replaceLeaveRequestDays(tx *gorm.DB, request *domain.LeaveRequest) error {
+	if err := tx.Where("leave_request_id = ?", request.ID).Delete(&domain.LeaveRequestDay{}).Error; err != nil {
+		return err
+	}
+	if len(request.Breakdown) == 0 {
+		return nil
+	}
+	for i := range request.Breakdown {
+		request.Breakdown[i].ID = uuid.Nil
+		request.Breakdown[i].LeaveRequestID = request.ID
+	}
+	return tx.Create(&request.Breakdown).Error
+}
+
+func orderByDate(db *gorm.DB) *gorm.DB {
+	return db.Order("date ASC")
+}
+
+// tracksBalance reports whether requests of the leave type are charged to a balance
+func tracksBalance(tx *gorm.DB, leaveTypeID uuid.UUID) (bool, error) {
+	var leaveType domain.LeaveType
+	if err := tx.Select("tracks_balance").First(&leaveType, "id = ?", leaveTypeID).Error; err != nil {
+		return false, err
+	}
+	return leaveType.TracksBalance, nil
+}
+
+// checkBalance returns an error when booking requested days against a
+// balance with available days left would take it below the leave type's floor
+func checkBalance(leaveType *domain.LeaveType, available, requested float64) error {
+	floor, ok := leaveType.BalanceFloor()
+	if !ok || available-requested >= floor {
+		return nil
+	}
+	if leaveType.AllowNegativeBalance {
+		return &NegativeBalanceCapError{Remaining: available - requested, Cap: -floor}
+	}
+	return &InsufficientBalanceError{Available: available, Requested: requested}
+}
+
+// checkNegativeBalanceCap returns a NegativeBalanceCapError when the balance
+// a booked request is charged to is below its leave type's negative cap
+func checkNegativeBalanceCap(tx *gorm.DB, request *domain.LeaveRequest) error {
+	var leaveType domain.LeaveType
+	if err := tx.Select("allow_negative_balance", "max_negative_days").
+		First(&leaveType, "id = ?", request.LeaveTypeID).Error; err != nil {
+		return err
+	}
+	floor, ok := leaveType.BalanceFloor()
+	if !ok || !leaveType.AllowNegativeBalance {
+		return nil
+	}
+
+	var balance domain.LeaveBalance
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+			request.EmployeeID, request.LeaveTypeID, request.StartDate.Year()).
+		First(&balance).Error; err != nil {
+		return err
+	}
+	if remaining := balance.TotalDays - balance.UsedDays - balance.PendingDays; remaining < floor {
+		return &NegativeBalanceCapError{Remaining: remaining, Cap: -floor}
+	}
+	return nil
+}
+
+// shiftPendingDays adds days to the pending bucket of the balance a request is booked against
+func shiftPendingDays(tx *gorm.DB, request *domain.LeaveRequest, days float64) error {
+	result := tx.Model(&domain.LeaveBalance{}).
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+			request.EmployeeID, request.LeaveTypeID, request.StartDate.Year()).
+		Update("pending_days", gorm.Expr("pending_days + ?", days))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// balanceUpdates returns the column changes a status transition makes to the
+// leave balance, or nil when the transition does not touch it
+func balanceUpdates(fromStatus, toStatus string, days float64) map[string]interface{} {
+	switch {
+	case fromStatus == domain.LeaveStatusPending && toStatus == domain.LeaveStatusApproved:
+		return map[string]interface{}{
+			"pending_days": gorm.Expr("pending_days - ?", days),
+			"used_days":    gorm.Expr("used_days + ?", days),
+		}
+	case fromStatus == domain.LeaveStatusPending:
+		return map[string]interface{}{
+			"pending_days": gorm.Expr("pending_days - ?", days),
+		}
+	case (fromStatus == domain.LeaveStatusApproved || fromStatus == domain.LeaveStatusCancellationRequested) &&
+		toStatus == domain.LeaveStatusCancelled:
+		return map[string]interface{}{
+			"used_days": gorm.Expr("used_days - ?", days),
+		}
+	}
+	return nil
+}
+
+func (r *leaveRepository) ListLeaveRequests(orgID, employeeID uuid.UUID, status string) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	query := r.db.Preload("LeaveType").Where("organization_id = ?", orgID)
+
+	if employeeID != uuid.Nil {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// ListLeaveRequestsWithOptions lists requests with filtering and pagination.
+// From and To select requests overlapping the range.
+func (r *leaveRepository) ListLeaveRequestsWithOptions(orgID uuid.UUID, params *domain.ListLeaveRequestsParams) ([]domain.LeaveRequest, int64, error) {
+	var requests []domain.LeaveRequest
+	var total int64
+
+	// Base query
+	query := r.db.Model(&domain.LeaveRequest{}).
+		Where("organization_id = ?", orgID)
+
+	// Apply filters if provided
+	if params != nil {
+		if params.IncludeDeleted {
+			query = query.Unscoped()
+		}
+		if params.Status != "" {
+			query = query.Where("status = ?", params.Status)
+		}
+		if params.EmployeeID != nil {
+			query = query.Where("employee_id = ?", *params.EmployeeID)
+		}
+		if params.LeaveTypeID != nil {
+			query = query.Where("leave_type_id = ?", *params.LeaveTypeID)
+		}
+		if params.From != nil {
+			query = query.Where("end_date >= ?", *params.From)
+		}
+		if params.To != nil {
+			query = query.Where("start_date <= ?", *params.To)
+		}
+		if params.Backdated != nil {
+			query = query.Where("backdated = ?", *params.Backdated)
+		}
+		if params.ExternalRef != "" {
+			ref := domain.ExternalReferences{{System: params.ExternalSystem, Reference: params.ExternalRef}}
+			query = query.Where("external_references @> ?::jsonb", externalRefFilter(ref))
+		}
+	}
+
+	// Get total count before pagination
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count leave requests: %w", err)
+	}
+
+	// Apply pagination
+	if params != nil && params.Page > 0 && params.PageSize > 0 {
+		offset := (params.Page - 1) * params.PageSize
+		query = query.Offset(offset).Limit(params.PageSize)
+	}
+
+	err = query.
+		Preload("LeaveType").
+		Order("start_date DESC, created_at DESC").
+		Find(&requests).
+		Error
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list leave requests: %w", err)
+	}
+
+	return requests, total, nil
+}
+
+func (r *leaveRepository) GetOverlappingRequests(employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	err := r.db.Where("employee_id = ? AND status IN (?) AND "+
+		"((start_date BETWEEN ? AND ?) OR (end_date BETWEEN ? AND ?) OR (start_date <= ? AND end_date >= ?))",
+		employeeID, domain.ActiveLeaveStatuses,
+		startDate, endDate, startDate, endDate, startDate, endDate).
+		Find(&requests).Error
+	return requests, err
+}
+
+// ListLeaveRequestChanges returns requests created or updated after the
+// watermark in (updated_at, id) order, whatever their status and including
+// soft-deleted ones
+func (r *leaveRepository) ListLeaveRequestChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	horizon, err := r.changesHorizon()
+	if err != nil {
+		return nil, err
+	}
+	query := r.db.Unscoped().Where("organization_id = ?", orgID)
+	if params.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *params.EmployeeID)
+	}
+	err = query.
+		Where("(updated_at > ? OR (updated_at = ? AND id > ?)) AND updated_at < ?",
+			params.Since, params.Since, params.SinceID, horizon).
+		Order("updated_at ASC, id ASC").
+		Limit(params.Limit).
+		Find(&requests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leave request changes: %w", err)
+	}
+	return requests, nil
+}
+
+// ListLeaveBalanceChanges returns balances changed after the watermark in
+// (updated_at, id) order, leaving out leave types that track no balance
+func (r *leaveRepository) ListLeaveBalanceChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	horizon, err := r.changesHorizon()
+	if err != nil {
+		return nil, err
+	}
+	query := r.db.Joins("JOIN leave_types lt ON lt.id = leave_balances.leave_type_id AND lt.tracks_balance").
+		Where("leave_balances.organization_id = ?", orgID)
+	if params.EmployeeID != nil {
+		query = query.Where("leave_balances.employee_id = ?", *params.EmployeeID)
+	}
+	err = query.
+		Where("(leave_balances.updated_at > ? OR (leave_balances.updated_at = ? AND leave_balances.id > ?)) AND leave_balances.updated_at < ?",
+			params.Since, params.Since, params.SinceID, horizon).
+		Order("leave_balances.updated_at ASC, leave_balances.id ASC").
+		Limit(params.Limit).
+		Find(&balances).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leave balance changes: %w", err)
+	}
+	return balances, nil
+}
+
+// ListHolidayChanges returns holidays changed after the watermark in
+// (updated_at, id) order
+func (r *leaveRepository) ListHolidayChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.Holiday, error) {
+	var holidays []domain.Holiday
+	horizon, err := r.changesHorizon()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Where("organization_id = ?", orgID).
+		Where("(updated_at > ? OR (updated_at = ? AND id > ?)) AND updated_at < ?",
+			params.Since, params.Since, params.SinceID, horizon).
+		Order("updated_at ASC, id ASC").
+		Limit(params.Limit).
+		Find(&holidays).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holiday changes: %w", err)
+	}
+	return holidays, nil
+}
+
+// ListLeaveRequestDays returns the covered days in [from, to] of the
+// organization's requests in the given statuses, with each day's request
+// and leave type loaded
+func (r *leaveRepository) ListLeaveRequestDays(orgID uuid.UUID, from, to time.Time, statuses []string) ([]domain.LeaveRequestDay, error) {
+	var days []domain.LeaveRequestDay
+	err := r.db.Preload("LeaveRequest.LeaveType").
+		Joins("JOIN leave_requests r ON r.id = leave_request_days.leave_request_id AND r.deleted_at IS NULL").
+		Where("r.organization_id = ? AND r.status IN ? AND leave_request_days.date BETWEEN ? AND ?",
+			orgID, statuses, from, to).
+		Order("leave_request_days.date ASC, r.start_date ASC").
+		Find(&days).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leave request days: %w", err)
+	}
+	return days, nil
+}
+
+// ListEmployeeLeaveRequestDays returns the covered days in [from, to] of the
+// given employees' requests in the given statuses, with each day's request loaded
+func (r *leaveRepository) ListEmployeeLeaveRequestDays(orgID uuid.UUID, employeeIDs []uuid.UUID, from, to time.Time, statuses []string) ([]domain.LeaveRequestDay, error) {
+	var days []domain.LeaveRequestDay
+	if len(employeeIDs) == 0 {
+		return days, nil
+	}
+	err := r.db.Preload("LeaveRequest").
+		Joins("JOIN leave_requests r ON r.id = leave_request_days.leave_request_id AND r.deleted_at IS NULL").
+		Where("r.organization_id = ? AND r.employee_id IN ? AND r.status IN ? AND leave_request_days.date BETWEEN ? AND ?",
+			orgID, employeeIDs, statuses, from, to).
+		Order("leave_request_days.date ASC").
+		Find(&days).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list employee leave request days: %w", err)
+	}
+	return days, nil
+}
+
+// LeaveBalance methods
+func (r *leaveRepository) CreateLeaveBalance(balance *domain.LeaveBalance) error {
+	return r.db.Create(balance).Error
+}
+
+func (r *leaveRepository) GetLeaveBalance(employeeID, leaveTypeID uuid.UUID, year int) (*domain.LeaveBalance, error) {
+	var balance domain.LeaveBalance
+	err := r.db.Preload("LeaveType").
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+			employeeID, leaveTypeID, year).
+		First(&balance).Error
+	return &balance, err
+}
+
+func (r *leaveRepository) GetLeaveBalanceByID(id uuid.UUID) (*domain.LeaveBalance, error) {
+	var balance domain.LeaveBalance
+	err := r.db.Preload("LeaveType").First(&balance, "id = ?", id).Error
+	return &balance, err
+}
+
+func (r *leaveRepository) UpdateLeaveBalance(balance *domain.LeaveBalance) error {
+	return r.db.Save(balance).Error
+}
+
+// ListLeaveBalances returns the employee's balances for a year, ordered by
+// leave type name. Only types that track a balance are listed.
+func (r *leaveRepository) ListLeaveBalances(orgID, employeeID uuid.UUID, year int) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	err := r.db.Preload("LeaveType").
+		Joins("JOIN leave_types lt ON lt.id = leave_balances.leave_type_id AND lt.tracks_balance").
+		Where("leave_balances.organization_id = ? AND leave_balances.employee_id = ? AND leave_balances.year = ?",
+			orgID, employeeID, year).
+		Order("lt.name").
+		Find(&balances).Error
+	return balances, err
+}
+
+// ListOrgLeaveBalances pages through the organization's balances for a
+// year, ordered by employee then leave type. Only types that track a
+// balance are listed.
+func (r *leaveRepository) ListOrgLeaveBalances(orgID uuid.UUID, params *domain.ListLeaveBalancesParams) ([]domain.LeaveBalance, int64, error) {
+	var balances []domain.LeaveBalance
+	var total int64
+
+	query := r.db.Model(&domain.LeaveBalance{}).
+		Joins("JOIN leave_types lt ON lt.id = leave_balances.leave_type_id AND lt.tracks_balance").
+		Where("leave_balances.organization_id = ? AND leave_balances.year = ?", orgID, params.Year)
+	if params.LeaveTypeID != nil {
+		query = query.Where("leave_balances.leave_type_id = ?", *params.LeaveTypeID)
+	}
+	if params.EmployeeID != nil {
+		query = query.Where("leave_balances.employee_id = ?", *params.EmployeeID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count leave balances: %w", err)
+	}
+
+	if params.Page > 0 && params.PageSize > 0 {
+		query = query.Offset((params.Page - 1) * params.PageSize).Limit(params.PageSize)
+	}
+
+	err := query.
+		Preload("LeaveType").
+		Order("leave_balances.employee_id, lt.name, leave_balances.id").
+		Find(&balances).
+		Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list leave balances: %w", err)
+	}
+
+	return balances, total, nil
+}
+
+// Holiday methods
+func (r *leaveRepository) CreateHoliday(holiday *domain.Holiday) error {
+	return r.db.Create(holiday).Error
+}
+
+func (r *leaveRepository) GetHoliday(id uuid.UUID) (*domain.Holiday, error) {
+	var holiday domain.Holiday
+	err := r.db.First(&holiday, "id = ?", id).Error
+	return &holiday, err
+}
+
+// ListHolidaysOnDate returns the organization's holidays on date, at most
+// one per location
+func (r *leaveRepository) ListHolidaysOnDate(orgID uuid.UUID, date time.Time) ([]domain.Holiday, error) {
+	var holidays []domain.Holiday
+	err := r.db.Where("organization_id = ? AND date = ?", orgID, date.Format("2006-01-02")).
+		Order("location ASC NULLS FIRST").Find(&holidays).Error
+	return holidays, err
+}
+
+func (r *leaveRepository) UpdateHoliday(holiday *domain.Holiday) error {
+	return r.db.Save(holiday).Error
+}
+
+func (r *leaveRepository) DeleteHoliday(id uuid.UUID) error {
+	return r.db.Delete(&domain.Holiday{}, "id = ?", id).Error
+}
+
+// ListHolidays returns the organization's holidays from startDate to
+// endDate inclusive, either bound left open when zero
+func (r *leaveRepository) ListHolidays(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.Holiday, error) {
+	var holidays []domain.Holiday
+	query := r.db.Where("organization_id = ?", orgID)
+
+	if !startDate.IsZero() {
+		query = query.Where("date >= ?", startDate)
+	}
+	if !endDate.IsZero() {
+		query = query.Where("date <= ?", endDate)
+	}
+
+	err := query.Order("date ASC").Find(&holidays).Error
+	return holidays, err
+}
+
+// ListLeaveRequestsCovering lists the organization's active requests
+// ending on endingFrom or later whose dates include any of dates
+func (r *leaveRepository) ListLeaveRequestsCovering(orgID uuid.UUID, dates []time.Time, endingFrom time.Time) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	if len(dates) == 0 {
+		return requests, nil
+	}
+
+	covering := r.db.Where("start_date <= ? AND end_date >= ?", dates[0].Format("2006-01-02"), dates[0].Format("2006-01-02"))
+	for _, date := range dates[1:] {
+		covering = covering.Or("start_date <= ? AND end_date >= ?", date.Format("2006-01-02"), date.Format("2006-01-02"))
+	}
+	err := r.db.Preload("LeaveType").
+		Where("organization_id = ? AND status IN ? AND end_date >= ?",
+			orgID, domain.ActiveLeaveStatuses, endingFrom.Format("2006-01-02")).
+		Where(covering).
+		Order("start_date ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// RecalculateLeaveRequestDays saves the new day count and breakdown of an
+// active request, charging the difference from previousDays to the bucket
+// of its balance its status books them in. The request must still have
+// its status and previousDays, or ErrStatusChanged is returned.
+func (r *leaveRepository) RecalculateLeaveRequestDays(request *domain.LeaveRequest, previousDays float64, history *domain.LeaveRequestHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		current := &domain.LeaveRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", request.ID).Error; err != nil {
+			return err
+		}
+		if current.Status != request.Status || math.Abs(current.Days-previousDays) >= 0.005 {
+			return ErrStatusChanged
+		}
+
+		if tracked, err := tracksBalance(tx, current.LeaveTypeID); err != nil {
+			return err
+		} else if tracked {
+			bucket := "used_days"
+			if current.Status == domain.LeaveStatusPending {
+				bucket = "pending_days"
+			}
+			result := tx.Model(&domain.LeaveBalance{}).
+				Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+					current.EmployeeID, current.LeaveTypeID, current.StartDate.Year()).
+				Update(bucket, gorm.Expr(bucket+" + ?", request.Days-previousDays))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+
+		if err := replaceLeaveRequestDays(tx, request); err != nil {
+			return err
+		}
+		if err := tx.Model(request).
+			Select("Days", "RawDays", "SandwichedDays").
+			Updates(request).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(history).Error
+	})
+}
+
+// Leave Request History methods
+func (r *leaveRepository) CreateLeaveRequestHistory(history *domain.LeaveRequestHistory) error {
+	return r.db.Create(history).Error
+}
+
+func (r *leaveRepository) ListLeaveRequestHistory(leaveRequestID uuid.UUID) ([]domain.LeaveRequestHistory, error) {
+	var history []domain.LeaveRequestHistory
+	err := r.db.Where("leave_request_id = ?", leaveRequestID).
+		Order("created_at DESC").
+		Find(&history).Error
+	return history, err
+}
+
+// ListEntitlementLeaveTypes returns the organization's paid leave types
+// that track a balance, which the yearly reset opens balances for
+func (r *leaveRepository) ListEntitlementLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error) {
+	var leaveTypes []domain.LeaveType
+	err := r.db.Where("organization_id = ? AND is_paid AND tracks_balance", orgID).
+		Order("name").
+		Find(&leaveTypes).Error
+	return leaveTypes, err
+}
+
+// InitializeYearlyBalance opens the employee's balances of year for the
+// given leave types in one transaction. Each new balance starts at the
+// type's entitlement, converted at hoursPerDay for hourly types, plus what
+// the type lets carry over from the year before, whose balance is then
+// closed. A provisional balance opened by a request filed ahead of the
+// reset is adopted: its total is recomputed the same way, keeping the
+// approved adjustments made since. Any other existing balance receives
+// the carry-over instead. Closed balances are not carried again, so reruns
+// change nothing. Each balance is then snapshotted for runID, once per run.
+func (r *leaveRepository) InitializeYearlyBalance(orgID, employeeID, runID uuid.UUID, leaveTypes []domain.LeaveType, year int, hoursPerDay float64) (domain.YearlyResetCounts, error) {
+	var counts domain.YearlyResetCounts
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		counts = domain.YearlyResetCounts{}
+		for i := range leaveTypes {
+			leaveType := &leaveTypes[i]
+
+			carry := 0.0
+			previous := &domain.LeaveBalance{}
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("employee_id = ? AND leave_type_id = ? AND year = ?", employeeID, leaveType.ID, year-1).
+				Take(previous).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+			case err != nil:
+				return err
+			case previous.ClosedAt == nil:
+				carry = leaveType.CarryOver(previous.RemainingDays)
+				if err := tx.Model(previous).Update("closed_at", time.Now()).Error; err != nil {
+					return err
+				}
+			}
+
+			opening := leaveType.Entitlement(hoursPerDay)
+			balance := &domain.LeaveBalance{
+				OrganizationID:  orgID,
+				EmployeeID:      employeeID,
+				LeaveTypeID:     leaveType.ID,
+				Year:            year,
+				TotalDays:       opening + carry,
+				CarriedOverDays: carry,
+				OpeningDays:     &opening,
+			}
+			if carry > 0 {
+				balance.CarriedOverExpiresOn = leaveType.CarryOverExpiry(year)
+			}
+			result := tx.Omit("LeaveType").
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "employee_id"}, {Name: "leave_type_id"}, {Name: "year"}},
+					DoNothing: true,
+				}).
+				Create(balance)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 1 {
+				counts.Created++
+			} else if adopted, err := adoptProvisionalBalance(tx, balance); err != nil {
+				return err
+			} else if adopted {
+				counts.Adopted++
+			} else if carry == 0 {
+				counts.Skipped++
+			} else if err := tx.Model(&domain.LeaveBalance{}).
+				Where("employee_id = ? AND leave_type_id = ? AND year = ?", employeeID, leaveType.ID, year).
+				Updates(map[string]interface{}{
+					"total_days":              gorm.Expr("total_days + ?", carry),
+					"carried_over_days":       gorm.Expr("carried_over_days + ?", carry),
+					"carried_over_expires_on": balance.CarriedOverExpiresOn,
+				}).Error; err != nil {
+				return err
+			}
+			if carry > 0 {
+				counts.CarriedOver++
+			}
+
+			current := &domain.LeaveBalance{}
+			if err := tx.Where("employee_id = ? AND leave_type_id = ? AND year = ?", employeeID, leaveType.ID, year).
+				Take(current).Error; err != nil {
+				return err
+			}
+			if err := tx.Omit("LeaveType").
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "run_id"}, {Name: "employee_id"}, {Name: "leave_type_id"}},
+					DoNothing: true,
+				}).
+				Create(domain.NewBalanceSnapshot(runID, current)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// ListBalanceSnapshots returns a page of the organization's snapshots,
+// newest run first and by employee within a run
+func (r *leaveRepository) ListBalanceSnapshots(orgID uuid.UUID, params *domain.ListBalanceSnapshotsParams) ([]domain.BalanceSnapshot, int64, error) {
+	var snapshots []domain.BalanceSnapshot
+	var total int64
+
+	query := r.db.Model(&domain.BalanceSnapshot{}).Where("organization_id = ?", orgID)
+	if params.RunID != nil {
+		query = query.Where("run_id = ?", *params.RunID)
+	}
+	if params.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *params.EmployeeID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count balance snapshots: %w", err)
+	}
+
+	if params.Page > 0 && params.PageSize > 0 {
+		query = query.Offset((params.Page - 1) * params.PageSize).Limit(params.PageSize)
+	}
+
+	err := query.
+		Preload("LeaveType").
+		Order("created_at DESC, employee_id, leave_type_id").
+		Find(&snapshots).
+		Error
+	return snapshots, total, err
+}
+
+// ListOpeningBalanceSnapshots returns the latest snapshot of each of the
+// employee's balances of year
+func (r *leaveRepository) ListOpeningBalanceSnapshots(orgID, employeeID uuid.UUID, year int) ([]domain.BalanceSnapshot, error) {
+	var snapshots []domain.BalanceSnapshot
+	err := r.db.
+		Raw(`SELECT DISTINCT ON (leave_type_id) * FROM balance_snapshots
+			WHERE organization_id = ? AND employee_id = ? AND year = ?
+			ORDER BY leave_type_id, created_at DESC`, orgID, employeeID, year).
+		Scan(&snapshots).Error
+	return snapshots, err
+}
+
+// adoptProvisionalBalance turns the existing provisional balance of
+// opening's employee, leave type and year into a regular one with
+// opening's total and carry-over, plus the approved adjustments already
+// made to it. It reports false when the balance is not provisional.
+func adoptProvisionalBalance(tx *gorm.DB, opening *domain.LeaveBalance) (bool, error) {
+	existing := &domain.LeaveBalance{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("employee_id = ? AND leave_type_id = ? AND year = ?", opening.EmployeeID, opening.LeaveTypeID, opening.Year).
+		Take(existing).Error; err != nil {
+		return false, err
+	}
+	if !existing.Provisional {
+		return false, nil
+	}
+
+	var adjusted float64
+	if err := tx.Model(&domain.LeaveBalanceAdjustment{}).
+		Where("leave_balance_id = ? AND status = ?", existing.ID, domain.AdjustmentStatusApproved).
+		Select("COALESCE(SUM(adjustment), 0)").
+		Scan(&adjusted).Error; err != nil {
+		return false, err
+	}
+
+	return true, tx.Model(existing).Updates(map[string]interface{}{
+		"total_days":              opening.TotalDays + adjusted,
+		"carried_over_days":       opening.CarriedOverDays,
+		"carried_over_expires_on": opening.CarriedOverExpiresOn,
+		"opening_days":            opening.OpeningDays,
+		"provisional":             false,
+	}).Error
+}
+
+// ListExpiredCarryOverBalanceIDs returns the organization's balances whose
+// carried-over days expired before today and have not been forfeited yet
+func (r *leaveRepository) ListExpiredCarryOverBalanceIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveBalance{}).
+		Where("organization_id = ? AND carried_over_days > 0 AND carried_over_expires_on < ? AND carried_over_expired_at IS NULL",
+			orgID, today.Format("2006-01-02")).
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ExpireCarryOver forfeits the unused carried-over days of a balance whose
+// carry-over expired before today. The forfeiture is recorded as an
+// approved adjustment by the system user, which is returned; it is nil when
+// the carried-over days had all been used, or the balance was already
+// expired by a concurrent run.
+func (r *leaveRepository) ExpireCarryOver(balanceID uuid.UUID, today time.Time) (*domain.LeaveBalanceAdjustment, error) {
+	var adjustment *domain.LeaveBalanceAdjustment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		balance := &domain.LeaveBalance{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(balance, "id = ?", balanceID).Error; err != nil {
+			return err
+		}
+		if balance.CarriedOverExpiredAt != nil || balance.CarriedOverExpiresOn == nil ||
+			!balance.CarriedOverExpiresOn.Before(today) {
+			return nil
+		}
+
+		now := time.Now()
+		if forfeited := balance.CarriedOverRemaining(); forfeited > 0 {
+			adjustment = &domain.LeaveBalanceAdjustment{
+				LeaveBalanceID: balance.ID,
+				Adjustment:     -forfeited,
+				Reason: fmt.Sprintf("%.2f carried-over days expired on %s",
+					forfeited, balance.CarriedOverExpiresOn.Format("2006-01-02")),
+				PerformedBy: domain.SystemUserID,
+				ApprovedBy:  &domain.SystemUserID,
+				ApprovedAt:  &now,
+				Status:      domain.AdjustmentStatusApproved,
+			}
+			adjustment.RecordBalance(balance.TotalDays)
+			if err := tx.Omit("LeaveBalance").Create(adjustment).Error; err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{"carried_over_expired_at": now}
+		if adjustment != nil {
+			updates["total_days"] = gorm.Expr("total_days + ?", adjustment.Adjustment)
+		}
+		return tx.Model(balance).Updates(updates).Error
+	})
+	return adjustment, err
+}
+
+// CreateMissingLeaveBalances creates the given balances in one
+// transaction, skipping those whose employee, leave type and year already
+// have one. Each balance is replaced by the stored row, and the result
+// reports which ones were created.
+func (r *leaveRepository) CreateMissingLeaveBalances(balances []*domain.LeaveBalance) ([]bool, error) {
+	created := make([]bool, len(balances))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i, balance := range balances {
+			result := tx.Omit("LeaveType").
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "employee_id"}, {Name: "leave_type_id"}, {Name: "year"}},
+					DoNothing: true,
+				}).
+				Create(balance)
+			if result.Error != nil {
+				return result.Error
+			}
+			created[i] = result.RowsAffected == 1
+
+			if err := tx.Where("employee_id = ? AND leave_type_id = ? AND year = ?",
+				balance.EmployeeID, balance.LeaveTypeID, balance.Year).
+				Take(balance).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return created, err
+}
+
+// ListRecalculationBalances returns the organization's balances of leave
+// types that track one for a year, optionally for one employee, with
+// their leave type
+func (r *leaveRepository) ListRecalculationBalances(orgID uuid.UUID, employeeID *uuid.UUID, year int) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	query := r.db.Preload("LeaveType").
+		Joins("JOIN leave_types lt ON lt.id = leave_balances.leave_type_id AND lt.tracks_balance").
+		Where("leave_balances.organization_id = ? AND leave_balances.year = ?", orgID, year)
+	if employeeID != nil {
+		query = query.Where("leave_balances.employee_id = ?", *employeeID)
+	}
+	err := query.Order("leave_balances.employee_id, lt.name").Find(&balances).Error
+	return balances, err
+}
+
+// expectedBalanceFigures adds up a balance's source records: used days
+// from its approved requests, including those awaiting cancellation,
+// pending days from its pending requests, and the total from its opening
+// entitlement, or fallbackOpening when none was recorded, plus carried-over
+// days and approved adjustments
+func expectedBalanceFigures(tx *gorm.DB, balance *domain.LeaveBalance, fallbackOpening float64) (domain.BalanceFigures, error) {
+	var row struct {
+		Used        float64
+		Pending     float64
+		Adjustments float64
+	}
+	err := tx.Raw(`SELECT
+		(SELECT COALESCE(SUM(days) FILTER (WHERE status IN @used), 0) FROM leave_requests
+			WHERE employee_id = @employee_id AND leave_type_id = @leave_type_id
+			AND EXTRACT(YEAR FROM start_date) = @year AND deleted_at IS NULL) AS used,
+		(SELECT COALESCE(SUM(days) FILTER (WHERE status = @pending), 0) FROM leave_requests
+			WHERE employee_id = @employee_id AND leave_type_id = @leave_type_id
+			AND EXTRACT(YEAR FROM start_date) = @year AND deleted_at IS NULL) AS pending,
+		(SELECT COALESCE(SUM(adjustment), 0) FROM leave_balance_adjustments
+			WHERE leave_balance_id = @balance_id AND status = @approved) AS adjustments`,
+		map[string]interface{}{
+			"used":          []string{domain.LeaveStatusApproved, domain.LeaveStatusCancellationRequested},
+			"pending":       domain.LeaveStatusPending,
+			"approved":      domain.AdjustmentStatusApproved,
+			"balance_id":    balance.ID,
+			"employee_id":   balance.EmployeeID,
+			"leave_type_id": balance.LeaveTypeID,
+			"year":          balance.Year,
+		}).Scan(&row).Error
+	if err != nil {
+		return domain.BalanceFigures{}, err
+	}
+
+	opening := fallbackOpening
+	if balance.OpeningDays != nil {
+		opening = *balance.OpeningDays
+	}
+	return domain.BalanceFigures{
+		TotalDays:   opening + balance.CarriedOverDays + row.Adjustments,
+		UsedDays:    row.Used,
+		PendingDays: row.Pending,
+	}, nil
+}
+
+// ExpectedBalanceFigures returns what a balance's buckets should be going
+// by its source records
+func (r *leaveRepository) ExpectedBalanceFigures(balance *domain.LeaveBalance, fallbackOpening float64) (domain.BalanceFigures, error) {
+	return expectedBalanceFigures(r.db, balance, fallbackOpening)
+}
+
+// CorrectLeaveBalance recalculates a balance from its source records under
+// a lock and, when its buckets differ, overwrites them and records a
+// correction by the system user documenting the change. It returns nil
+// when the balance was already right.
+func (r *leaveRepository) CorrectLeaveBalance(balanceID uuid.UUID, fallbackOpening float64) (*domain.BalanceDiscrepancy, error) {
+	var discrepancy *domain.BalanceDiscrepancy
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		balance := &domain.LeaveBalance{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(balance, "id = ?", balanceID).Error; err != nil {
+			return err
+		}
+
+		expected, err := expectedBalanceFigures(tx, balance, fallbackOpening)
+		if err != nil {
+			return err
+		}
+		stored := balance.StoredFigures()
+		if stored.Matches(expected) {
+			return nil
+		}
+
+		discrepancy = &domain.BalanceDiscrepancy{
+			LeaveBalanceID: balance.ID,
+			EmployeeID:     balance.EmployeeID,
+			LeaveTypeID:    balance.LeaveTypeID,
+			Year:           balance.Year,
+			Stored:         stored,
+			Expected:       expected,
+			Changes:        stored.Describe(expected),
+			Applied:        true,
+		}
+
+		now := time.Now()
+		correction := &domain.LeaveBalanceAdjustment{
+			LeaveBalanceID: balance.ID,
+			Adjustment:     expected.TotalDays - stored.TotalDays,
+			Reason:         "balance recalculated from source records: " + discrepancy.Changes,
+			PerformedBy:    domain.SystemUserID,
+			ApprovedBy:     &domain.SystemUserID,
+			ApprovedAt:     &now,
+			Status:         domain.AdjustmentStatusCorrection,
+		}
+		correction.RecordBalance(stored.TotalDays)
+		if err := tx.Omit("LeaveBalance").Create(correction).Error; err != nil {
+			return err
+		}
+		discrepancy.AdjustmentID = &correction.ID
+
+		return tx.Model(balance).Updates(map[string]interface{}{
+			"total_days":   expected.TotalDays,
+			"used_days":    expected.UsedDays,
+			"pending_days": expected.PendingDays,
+		}).Error
+	})
+	return discrepancy, err
+}
+
+// ListAccrualLeaveTypes returns the organization's leave types that track
+// a balance and accrue
+func (r *leaveRepository) ListAccrualLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error) {
+	var leaveTypes []domain.LeaveType
+	err := r.db.Where("organization_id = ? AND tracks_balance AND accrual_frequency IN ?",
+		orgID, []string{domain.AccrualFrequencyMonthly, domain.AccrualFrequencyQuarterly}).
+		Order("name").
+		Find(&leaveTypes).Error
+	return leaveTypes, err
+}
+
+// ListAccrualOrganizationIDs returns the organizations with at least one
+// leave type that tracks a balance and accrues
+func (r *leaveRepository) ListAccrualOrganizationIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveType{}).
+		Distinct("organization_id").
+		Where("tracks_balance AND accrual_frequency IN ?",
+			[]string{domain.AccrualFrequencyMonthly, domain.AccrualFrequencyQuarterly}).
+		Order("organization_id").
+		Pluck("organization_id", &ids).Error
+	return ids, err
+}
+
+// ListLeaveTypeBalanceIDs returns the balances of a leave type for a year
+func (r *leaveRepository) ListLeaveTypeBalanceIDs(leaveTypeID uuid.UUID, year int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveBalance{}).
+		Where("leave_type_id = ? AND year = ?", leaveTypeID, year).
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// AccrueBalance grants a balance the leave type's accrual for period,
+// recording an approved adjustment by the system user and the accrual run.
+// It returns nil when the balance's employee already accrued the period,
+// and a run without days or adjustment when the year's accruals reached
+// the type's allowance. Under the type's bank cap only what keeps the
+// balance within it is credited, and the run is marked capped with the
+// days forfeited.
+func (r *leaveRepository) AccrueBalance(balanceID uuid.UUID, leaveType *domain.LeaveType, period string, hoursPerDay float64) (*domain.AccrualRun, error) {
+	var run *domain.AccrualRun
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		balance := &domain.LeaveBalance{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(balance, "id = ?", balanceID).Error; err != nil {
+			return err
+		}
+
+		var done int64
+		if err := tx.Model(&domain.AccrualRun{}).
+			Where("employee_id = ? AND leave_type_id = ? AND period = ?", balance.EmployeeID, leaveType.ID, period).
+			Count(&done).Error; err != nil {
+			return err
+		}
+		if done > 0 {
+			return nil
+		}
+
+		var accrued float64
+		if err := tx.Model(&domain.AccrualRun{}).
+			Where("leave_balance_id = ?", balance.ID).
+			Select("COALESCE(SUM(days), 0)").
+			Scan(&accrued).Error; err != nil {
+			return err
+		}
+
+		run = &domain.AccrualRun{
+			OrganizationID: balance.OrganizationID,
+			EmployeeID:     balance.EmployeeID,
+			LeaveTypeID:    leaveType.ID,
+			LeaveBalanceID: balance.ID,
+			Period:         period,
+		}
+		run.Days, run.ForfeitedDays = leaveType.CapAccrual(balance.TotalDays-balance.UsedDays, leaveType.Accrual(accrued, hoursPerDay))
+		run.Capped = run.ForfeitedDays > 0
+		if run.Days > 0 {
+			reason := fmt.Sprintf("%s accrual for %s", leaveType.AccrualFrequency, period)
+			if run.Capped {
+				reason += fmt.Sprintf(", capped at %g banked", *leaveType.MaxBalanceDays)
+			}
+			now := time.Now()
+			adjustment := &domain.LeaveBalanceAdjustment{
+				LeaveBalanceID: balance.ID,
+				Adjustment:     run.Days,
+				Reason:         reason,
+				PerformedBy:    domain.SystemUserID,
+				ApprovedBy:     &domain.SystemUserID,
+				ApprovedAt:     &now,
+				Status:         domain.AdjustmentStatusApproved,
+			}
+			adjustment.RecordBalance(balance.TotalDays)
+			if err := tx.Omit("LeaveBalance").Create(adjustment).Error; err != nil {
+				return err
+			}
+			run.AdjustmentID = &adjustment.ID
+
+			if err := tx.Model(balance).
+				Update("total_days", gorm.Expr("total_days + ?", run.Days)).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(run).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListEmployeeBalanceYears returns the employee's balances of leave types
+// that track one for the years fromYear to toYear
+func (r *leaveRepository) ListEmployeeBalanceYears(orgID, employeeID uuid.UUID, fromYear, toYear int) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	err := r.db.Preload("LeaveType").
+		Joins("JOIN leave_types lt ON lt.id = leave_balances.leave_type_id AND lt.tracks_balance").
+		Where("leave_balances.organization_id = ? AND leave_balances.employee_id = ? AND leave_balances.year BETWEEN ? AND ?",
+			orgID, employeeID, fromYear, toYear).
+		Order("leave_balances.year DESC, lt.name").
+		Find(&balances).Error
+	return balances, err
+}
+
+// SumForfeitedCarryOver returns, per balance, the carried-over days written
+// off when they expired
+func (r *leaveRepository) SumForfeitedCarryOver(balanceIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	forfeited := make(map[uuid.UUID]float64)
+	if len(balanceIDs) == 0 {
+		return forfeited, nil
+	}
+
+	var rows []struct {
+		LeaveBalanceID uuid.UUID
+		Days           float64
+	}
+	err := r.db.Model(&domain.LeaveBalanceAdjustment{}).
+		Select("leave_balance_id, -SUM(adjustment) AS days").
+		Where("leave_balance_id IN ? AND performed_by = ? AND status = ? AND adjustment < 0 AND transfer_id IS NULL",
+			balanceIDs, domain.SystemUserID, domain.AdjustmentStatusApproved).
+		Group("leave_balance_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		forfeited[row.LeaveBalanceID] = row.Days
+	}
+	return forfeited, err
+}
+
+// SumLeaveTakenByYear totals the employee's approved leave of types that
+// track a balance per start year and leave type, for the years fromYear to
+// toYear. Leave awaiting cancellation still counts as taken, as it does on
+// the balance.
+func (r *leaveRepository) SumLeaveTakenByYear(orgID, employeeID uuid.UUID, fromYear, toYear int) ([]domain.LeaveTakenByYear, error) {
+	var taken []domain.LeaveTakenByYear
+	err := r.db.Model(&domain.LeaveRequest{}).
+		Select("EXTRACT(YEAR FROM leave_requests.start_date)::int AS year, leave_requests.leave_type_id, SUM(leave_requests.days) AS days").
+		Joins("JOIN leave_types lt ON lt.id = leave_requests.leave_type_id AND lt.tracks_balance").
+		Where("leave_requests.organization_id = ? AND leave_requests.employee_id = ? AND leave_requests.status IN ?",
+			orgID, employeeID, []string{domain.LeaveStatusApproved, domain.LeaveStatusCancellationRequested}).
+		Where("EXTRACT(YEAR FROM leave_requests.start_date) BETWEEN ? AND ?", fromYear, toYear).
+		Group("1, 2").
+		Scan(&taken).Error
+	return taken, err
+}
+
+// GetLeaveStats counts the organization's requests starting in
+// [startDate, endDate) and totals the approved days among them, split into
+// paid and unpaid. The totals cover leave types counted in days; hourly
+// types only appear in the per-type rows, in hours.
+func (r *leaveRepository) GetLeaveStats(orgID uuid.UUID, startDate, endDate time.Time) (*domain.LeaveStats, error) {
+	var stats domain.LeaveStats
+
+	inPeriod := func() *gorm.DB {
+		return r.db.Model(&domain.LeaveRequest{}).
+			Joins("JOIN leave_types lt ON lt.id = leave_requests.leave_type_id").
+			Where("leave_requests.organization_id = ? AND leave_requests.start_date >= ? AND leave_requests.start_date < ?",
+				orgID, startDate, endDate)
+	}
+
+	err := inPeriod().
+		Select("COUNT(*) AS total_requests, " +
+			"COALESCE(SUM(leave_requests.days) FILTER (WHERE leave_requests.status = 'approved' AND lt.unit = 'days'), 0) AS total_days_taken, " +
+			"COALESCE(SUM(leave_requests.days) FILTER (WHERE leave_requests.status = 'approved' AND lt.unit = 'days' AND lt.is_paid), 0) AS paid_days_taken, " +
+			"COALESCE(SUM(leave_requests.days) FILTER (WHERE leave_requests.status = 'approved' AND lt.unit = 'days' AND NOT lt.is_paid), 0) AS unpaid_days_taken").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave by type
+	err = inPeriod().
+		Group("lt.id, lt.name, lt.is_paid, lt.unit").
+		Order("lt.name").
+		Select("lt.name AS leave_type, lt.is_paid, lt.unit, COUNT(*) AS count, " +
+			"COALESCE(SUM(leave_requests.days) FILTER (WHERE leave_requests.status = 'approved'), 0) AS total_days").
+		Scan(&stats.LeaveByType).Error
+
+	return &stats, err
+}
+
+// ListEmployeeLeaveByType counts per employee and leave type the requests
+// starting in [startDate, endDate) and totals their approved days
+func (r *leaveRepository) ListEmployeeLeaveByType(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.EmployeeLeaveByType, error) {
+	var rows []domain.EmployeeLeaveByType
+	err := r.db.Model(&domain.LeaveRequest{}).
+		Joins("JOIN leave_types lt ON lt.id = leave_requests.leave_type_id").
+		Where("leave_requests.organization_id = ? AND leave_requests.start_date >= ? AND leave_requests.start_date < ?",
+			orgID, startDate, endDate).
+		Group("leave_requests.employee_id, lt.id, lt.name, lt.is_paid, lt.unit").
+		Order("lt.name").
+		Select("leave_requests.employee_id, lt.name AS leave_type, lt.is_paid, lt.unit, COUNT(*) AS count, " +
+			"COALESCE(SUM(leave_requests.days) FILTER (WHERE leave_requests.status = 'approved'), 0) AS total_days").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ListMonthlyLeave totals the approved leave falling in [startDate, endDate)
+// per calendar month from the requests' day breakdowns, so a request
+// spanning months counts toward each. Count is the requests with a day in
+// the month. Days are charged days, spread over a request's days by their
+// fraction, and cover leave types counted in days. Leave awaiting
+// cancellation still counts as taken.
+func (r *leaveRepository) ListMonthlyLeave(orgID uuid.UUID, leaveTypeID *uuid.UUID, startDate, endDate time.Time) ([]domain.MonthlyStats, error) {
+	var stats []domain.MonthlyStats
+	query := r.db.Model(&domain.LeaveRequestDay{}).
+		Joins("JOIN leave_requests r ON r.id = leave_request_days.leave_request_id AND r.deleted_at IS NULL").
+		Joins("JOIN leave_types lt ON lt.id = r.leave_type_id").
+		Where("r.organization_id = ? AND r.status IN ? AND leave_request_days.date >= ? AND leave_request_days.date < ?",
+			orgID, []string{domain.LeaveStatusApproved, domain.LeaveStatusCancellationRequested},
+			startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if leaveTypeID != nil {
+		query = query.Where("r.leave_type_id = ?", *leaveTypeID)
+	}
+	err := query.
+		Select("date_trunc('month', leave_request_days.date)::date AS month, COUNT(DISTINCT r.id) AS count, " +
+			"COALESCE(SUM(leave_request_days.fraction * r.days / NULLIF(r.raw_days, 0)) FILTER (WHERE lt.unit = 'days'), 0) AS total_days").
+		// A lone position would be quoted into a column name
+		Group("date_trunc('month', leave_request_days.date)").
+		Order("1").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// SummarizeLeaveRegister counts per leave type, by name, the requests
+// starting in [startDate, endDate) by status and totals their approved days
+func (r *leaveRepository) SummarizeLeaveRegister(orgID uuid.UUID, leaveTypeID *uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRegisterType, error) {
+	var types []domain.LeaveRegisterType
+	query := r.db.Model(&domain.LeaveRequest{}).
+		Joins("JOIN leave_types lt ON lt.id = leave_requests.leave_type_id").
+		Where("leave_requests.organization_id = ? AND leave_requests.start_date >= ? AND leave_requests.start_date < ?",
+			orgID, startDate, endDate)
+	if leaveTypeID != nil {
+		query = query.Where("leave_requests.leave_type_id = ?", *leaveTypeID)
+	}
+	err := query.
+		Group("lt.id, lt.name, lt.unit").
+		Order("lt.name").
+		Select("lt.id AS leave_type_id, lt.name AS leave_type, lt.unit, COUNT(*) AS requests, " +
+			"COUNT(*) FILTER (WHERE leave_requests.status IN ('approved', 'cancellation_requested')) AS approved, " +
+			"COUNT(*) FILTER (WHERE leave_requests.status = 'pending') AS pending, " +
+			"COUNT(*) FILTER (WHERE leave_requests.status = 'rejected') AS rejected, " +
+			"COUNT(*) FILTER (WHERE leave_requests.status = 'cancelled') AS cancelled, " +
+			"COALESCE(SUM(leave_requests.days) FILTER (WHERE leave_requests.status IN ('approved', 'cancellation_requested')), 0) AS approved_days").
+		Scan(&types).Error
+	return types, err
+}
+
+// ListLeaveRegisterPage lists up to limit requests of the leave type
+// starting in [startDate, endDate), by start date, that come after the
+// given request, or from the first when it is nil
+func (r *leaveRepository) ListLeaveRegisterPage(orgID, leaveTypeID uuid.UUID, startDate, endDate time.Time, after *domain.LeaveRequest, limit int) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	query := r.db.Where("organization_id = ? AND leave_type_id = ? AND start_date >= ? AND start_date < ?",
+		orgID, leaveTypeID, startDate, endDate)
+	if after != nil {
+		query = query.Where("(start_date, id) > (?, ?)", after.StartDate, after.ID)
+	}
+	err := query.Order("start_date ASC, id ASC").Limit(limit).Find(&requests).Error
+	return requests, err
+}
+
+// ListEmployeeLeaveRequestsStarting lists the employee's requests starting
+// in [startDate, endDate) with their leave type, by start date
+func (r *leaveRepository) ListEmployeeLeaveRequestsStarting(orgID, employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	err := r.db.Preload("LeaveType").
+		Where("organization_id = ? AND employee_id = ? AND start_date >= ? AND start_date < ?",
+			orgID, employeeID, startDate, endDate).
+		Order("start_date ASC, created_at ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// CreateBalanceAdjustment records an adjustment, adding it to the balance's
+// total right away when it is created approved. The balance is locked so a
+// deduction is checked against its current remaining days, which it may
+// not take below the leave type's floor.
+func (r *leaveRepository) CreateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var balance domain.LeaveBalance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&balance, "id = ?", adjustment.LeaveBalanceID).Error; err != nil {
+			return err
+		}
+
+		if adjustment.IsNegative() {
+			var leaveType domain.LeaveType
+			if err := tx.First(&leaveType, "id = ?", balance.LeaveTypeID).Error; err != nil {
+				return err
+			}
+			available := balance.TotalDays - balance.UsedDays - balance.PendingDays
+			if err := checkBalance(&leaveType, available, -adjustment.Adjustment); err != nil {
+				return err
+			}
+		}
+
+		if adjustment.Status == domain.AdjustmentStatusApproved {
+			now := time.Now()
+			adjustment.AppliedAt = &now
+			adjustment.RecordBalance(balance.TotalDays)
+		}
+		if err := tx.Omit("LeaveBalance").Create(adjustment).Error; err != nil {
+			return err
+		}
+
+		if adjustment.Status != domain.AdjustmentStatusApproved {
+			return nil
+		}
+		return tx.Model(&domain.LeaveBalance{}).
+			Where("id = ?", balance.ID).
+			Update("total_days", gorm.Expr("total_days + ?", adjustment.Adjustment)).Error
+	})
+}
+
+func (r *leaveRepository) GetBalanceAdjustment(id uuid.UUID) (*domain.LeaveBalanceAdjustment, error) {
+	var adjustment domain.LeaveBalanceAdjustment
+	err := r.db.Preload("LeaveBalance.LeaveType").First(&adjustment, "id = ?", id).Error
+	return &adjustment, err
+}
+
+// UpdateBalanceAdjustment saves a decision on a pending adjustment, or
+// the application or cancellation of a scheduled one. The adjustment is
+// locked and must still allow the move to its new status, or
+// ErrStatusChanged is returned, so an approval retried or raced applies its
+// delta to the balance once. Deductions are checked against the balance as
+// it is now.
+func (r *leaveRepository) UpdateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		oldAdjustment, err := lockAdjustmentForTransition(tx, adjustment.ID, adjustment.Status)
+		if err != nil {
+			return err
+		}
+
+		if adjustment.Status == domain.AdjustmentStatusApproved {
+			before, err := applyAdjustmentDelta(tx, oldAdjustment.LeaveBalanceID, oldAdjustment.Adjustment)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			adjustment.AppliedAt = &now
+			adjustment.Adjustment = oldAdjustment.Adjustment
+			adjustment.RecordBalance(before)
+		}
+
+		return tx.Model(adjustment).
+			Select("status", "comments", "approved_by", "approved_at", "applied_at",
+				"balance_before", "balance_after", "updated_at").
+			Updates(adjustment).Error
+	})
+}
+
+// RevertBalanceAdjustment takes an approved adjustment's delta back off its
+// balance and marks it reverted. The reversal is recorded as a correction
+// by the system user, approved by revertedBy, which is returned; like the
+// reverted adjustment it does not count towards the total, so the approved
+// adjustments keep adding up to it.
+func (r *leaveRepository) RevertBalanceAdjustment(id, revertedBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	var correction *domain.LeaveBalanceAdjustment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		adjustment, err := lockAdjustmentForTransition(tx, id, domain.AdjustmentStatusReverted)
+		if err != nil {
+			return err
+		}
+		before, err := applyAdjustmentDelta(tx, adjustment.LeaveBalanceID, -adjustment.Adjustment)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		reason := fmt.Sprintf("reverts adjustment %s of %+.2f", adjustment.ID, adjustment.Adjustment)
+		if comments != "" {
+			reason += ": " + comments
+		}
+		correction = &domain.LeaveBalanceAdjustment{
+			LeaveBalanceID: adjustment.LeaveBalanceID,
+			Adjustment:     -adjustment.Adjustment,
+			Reason:         reason,
+			PerformedBy:    domain.SystemUserID,
+			ApprovedBy:     &revertedBy,
+			ApprovedAt:     &now,
+			Status:         domain.AdjustmentStatusCorrection,
+		}
+		correction.RecordBalance(before)
+		if err := tx.Omit("LeaveBalance").Create(correction).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(adjustment).Updates(map[string]interface{}{
+			"status":     domain.AdjustmentStatusReverted,
+			"updated_at": now,
+		}).Error
+	})
+	return correction, err
+}
+
+// lockAdjustmentForTransition locks an adjustment and checks it may still
+// move to status, returning ErrStatusChanged when it may not
+func lockAdjustmentForTransition(tx *gorm.DB, id uuid.UUID, status string) (*domain.LeaveBalanceAdjustment, error) {
+	adjustment := &domain.LeaveBalanceAdjustment{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(adjustment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	if !adjustment.CanTransitionTo(status) {
+		return nil, ErrStatusChanged
+	}
+	return adjustment, nil
+}
+
+// applyAdjustmentDelta adds delta to a balance's total under its lock,
+// checking deductions against what is left of it, and returns the total
+// from before
+func applyAdjustmentDelta(tx *gorm.DB, balanceID uuid.UUID, delta float64) (float64, error) {
+	balance := &domain.LeaveBalance{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(balance, "id = ?", balanceID).Error; err != nil {
+		return 0, err
+	}
+
+	if delta < 0 {
+		var leaveType domain.LeaveType
+		if err := tx.First(&leaveType, "id = ?", balance.LeaveTypeID).Error; err != nil {
+			return 0, err
+		}
+		available := balance.TotalDays - balance.UsedDays - balance.PendingDays
+		if err := checkBalance(&leaveType, available, -delta); err != nil {
+			return 0, err
+		}
+	}
+
+	return balance.TotalDays, tx.Model(balance).Update("total_days", gorm.Expr("total_days + ?", delta)).Error
+}
+
+// ListDueBalanceAdjustmentIDs returns the organization's scheduled
+// adjustments effective on or before today, earliest first
+func (r *leaveRepository) ListDueBalanceAdjustmentIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveBalanceAdjustment{}).
+		Joins("JOIN leave_balances lb ON lb.id = leave_balance_adjustments.leave_balance_id").
+		Where("lb.organization_id = ? AND leave_balance_adjustments.status = ? AND leave_balance_adjustments.effective_date <= ?",
+			orgID, domain.AdjustmentStatusScheduled, today.Format("2006-01-02")).
+		Order("leave_balance_adjustments.effective_date, leave_balance_adjustments.created_at").
+		Pluck("leave_balance_adjustments.id", &ids).Error
+	return ids, err
+}
+
+// ListScheduledAdjustmentOrganizationIDs returns the organizations with
+// scheduled adjustments
+func (r *leaveRepository) ListScheduledAdjustmentOrganizationIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveBalanceAdjustment{}).
+		Joins("JOIN leave_balances lb ON lb.id = leave_balance_adjustments.leave_balance_id").
+		Where("leave_balance_adjustments.status = ?", domain.AdjustmentStatusScheduled).
+		Distinct().
+		Pluck("lb.organization_id", &ids).Error
+	return ids, err
+}
+
+// SumScheduledAdjustments adds up the scheduled adjustments of each balance,
+// leaving out balances without any
+func (r *leaveRepository) SumScheduledAdjustments(balanceIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	var rows []struct {
+		LeaveBalanceID uuid.UUID
+		Total          float64
+	}
+	if len(balanceIDs) > 0 {
+		err := r.db.Model(&domain.LeaveBalanceAdjustment{}).
+			Select("leave_balance_id, SUM(adjustment) AS total").
+			Where("leave_balance_id IN ? AND status = ?", balanceIDs, domain.AdjustmentStatusScheduled).
+			Group("leave_balance_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sums := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		sums[row.LeaveBalanceID] = row.Total
+	}
+	return sums, nil
+}
+
+func (r *leaveRepository) ListBalanceAdjustments(balanceID uuid.UUID) ([]domain.LeaveBalanceAdjustment, error) {
 	var adjustments []domain.LeaveBalanceAdjustment
 	err := r.db.Where("leave_balance_id = ?", balanceID).
 		Order("created_at DESC").
@@ -439,13 +2323,475 @@ func (r *leaveRepository) ListBalanceAdjustments(balanceID uuid.UUID) ([]domain.
 	return adjustments, err
 }
 
+// ListOrgBalanceAdjustments pages through the organization's adjustments,
+// oldest first, with their balance and its leave type
+func (r *leaveRepository) ListOrgBalanceAdjustments(orgID uuid.UUID, params *domain.ListBalanceAdjustmentsParams) ([]domain.LeaveBalanceAdjustment, int64, error) {
+	var adjustments []domain.LeaveBalanceAdjustment
+	var total int64
+
+	query := r.db.Model(&domain.LeaveBalanceAdjustment{}).
+		Joins("JOIN leave_balances lb ON lb.id = leave_balance_adjustments.leave_balance_id").
+		Where("lb.organization_id = ?", orgID)
+	if params.Status != "" {
+		query = query.Where("leave_balance_adjustments.status = ?", params.Status)
+	}
+	if params.EmployeeID != nil {
+		query = query.Where("lb.employee_id = ?", *params.EmployeeID)
+	}
+	if params.From != nil {
+		query = query.Where("leave_balance_adjustments.created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("leave_balance_adjustments.created_at < ?", params.To.AddDate(0, 0, 1))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count balance adjustments: %w", err)
+	}
+
+	if params.Page > 0 && params.PageSize > 0 {
+		query = query.Offset((params.Page - 1) * params.PageSize).Limit(params.PageSize)
+	}
+
+	err := query.
+		Preload("LeaveBalance.LeaveType").
+		Order("leave_balance_adjustments.created_at, leave_balance_adjustments.id").
+		Find(&adjustments).
+		Error
+	return adjustments, total, err
+}
+
+// ListRecentBalanceAdjustments returns up to limit of the newest
+// adjustments of each balance, newest first within a balance
+func (r *leaveRepository) ListRecentBalanceAdjustments(balanceIDs []uuid.UUID, limit int) ([]domain.LeaveBalanceAdjustment, error) {
+	var adjustments []domain.LeaveBalanceAdjustment
+	if len(balanceIDs) == 0 {
+		return adjustments, nil
+	}
+	ranked := r.db.Model(&domain.LeaveBalanceAdjustment{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY leave_balance_id ORDER BY created_at DESC, id DESC) AS rn").
+		Where("leave_balance_id IN ?", balanceIDs)
+	err := r.db.Table("(?) AS ranked", ranked).
+		Where("rn <= ?", limit).
+		Order("leave_balance_id, created_at DESC, id DESC").
+		Find(&adjustments).Error
+	return adjustments, err
+}
+
+// ListBalancesAdjustments returns every adjustment of the balances, oldest
+// first
+func (r *leaveRepository) ListBalancesAdjustments(balanceIDs []uuid.UUID) ([]domain.LeaveBalanceAdjustment, error) {
+	var adjustments []domain.LeaveBalanceAdjustment
+	if len(balanceIDs) == 0 {
+		return adjustments, nil
+	}
+	err := r.db.Where("leave_balance_id IN ?", balanceIDs).
+		Order("created_at ASC, id ASC").
+		Find(&adjustments).Error
+	return adjustments, err
+}
+
+// balanceLedgerSQL selects the entries of a balance's ledger: approved
+// adjustments, the side of a transfer that moved days in or out of it,
+// approved requests charged to it and the cancellations that gave their
+// days back. Soft-deleted requests are included since their effect on the
+// balance stands.
+const balanceLedgerSQL = `
+SELECT CASE WHEN a.transfer_id IS NULL THEN 'adjustment' ELSE 'transfer' END AS type,
+	COALESCE(a.applied_at, a.approved_at, a.created_at) AS occurred_at, a.id AS entry_id,
+	a.adjustment AS amount, NULL::uuid AS leave_request_id, a.id AS adjustment_id, a.reason, a.performed_by,
+	a.transfer_id, cb.leave_type_id AS counterpart_leave_type_id, a.balance_before, a.balance_after
+FROM leave_balance_adjustments a
+LEFT JOIN leave_balance_adjustments ca ON ca.id = a.counterpart_id
+LEFT JOIN leave_balances cb ON cb.id = ca.leave_balance_id
+WHERE a.leave_balance_id = @balance_id AND a.status = 'approved'
+UNION ALL
+SELECT 'leave', r.approved_at, r.id, -r.days, r.id, NULL, r.reason, r.approved_by, NULL, NULL, NULL, NULL
+FROM leave_requests r
+WHERE r.employee_id = @employee_id AND r.leave_type_id = @leave_type_id
+	AND EXTRACT(YEAR FROM r.start_date) = @year
+	AND r.approved_at IS NOT NULL AND r.status IN ('approved', 'cancellation_requested', 'cancelled')
+UNION ALL
+SELECT 'cancellation', h.created_at, h.id, r.days, r.id, NULL, h.comments, h.performed_by, NULL, NULL, NULL, NULL
+FROM leave_requests r
+JOIN LATERAL (
+	SELECT id, created_at, comments, performed_by FROM leave_request_history
+	WHERE leave_request_id = r.id AND status = 'cancelled' AND action <> 'audit_export'
+	ORDER BY created_at DESC LIMIT 1
+) h ON true
+WHERE r.employee_id = @employee_id AND r.leave_type_id = @leave_type_id
+	AND EXTRACT(YEAR FROM r.start_date) = @year
+	AND r.approved_at IS NOT NULL AND r.status = 'cancelled'`
+
+func balanceLedgerArgs(balance *domain.LeaveBalance) map[string]interface{} {
+	return map[string]interface{}{
+		"balance_id":    balance.ID,
+		"employee_id":   balance.EmployeeID,
+		"leave_type_id": balance.LeaveTypeID,
+		"year":          balance.Year,
+	}
+}
+
+// SumBalanceLedger counts a balance's ledger entries and totals its
+// adjustments, transfers included, and its leave taken less leave given
+// back
+func (r *leaveRepository) SumBalanceLedger(balance *domain.LeaveBalance) (int64, float64, float64, error) {
+	var row struct {
+		Count       int64
+		Adjustments float64
+		Leave       float64
+	}
+	err := r.db.Raw(`SELECT COUNT(*) AS count,
+		COALESCE(SUM(amount) FILTER (WHERE type IN ('adjustment', 'transfer')), 0) AS adjustments,
+		COALESCE(SUM(amount) FILTER (WHERE type NOT IN ('adjustment', 'transfer')), 0) AS leave
+		FROM (`+balanceLedgerSQL+`) e`, balanceLedgerArgs(balance)).
+		Scan(&row).Error
+	return row.Count, row.Adjustments, row.Leave, err
+}
+
+// ListBalanceLedgerEntries returns a page of a balance's ledger, oldest
+// first, with the running balance from opening computed over every entry
+// before the page
+func (r *leaveRepository) ListBalanceLedgerEntries(balance *domain.LeaveBalance, opening float64, offset, limit int) ([]domain.BalanceLedgerEntry, error) {
+	args := balanceLedgerArgs(balance)
+	args["opening"] = opening
+	args["offset"] = offset
+	args["limit"] = limit
+
+	var entries []domain.BalanceLedgerEntry
+	err := r.db.Raw(`SELECT type, occurred_at, amount, leave_request_id, adjustment_id, reason, performed_by,
+		transfer_id, counterpart_leave_type_id, balance_before, balance_after,
+		@opening + SUM(amount) OVER (ORDER BY occurred_at, entry_id ROWS UNBOUNDED PRECEDING) AS running_balance
+		FROM (`+balanceLedgerSQL+`) e
+		ORDER BY occurred_at, entry_id
+		OFFSET @offset LIMIT @limit`, args).
+		Scan(&entries).Error
+	return entries, err
+}
+
+// ListAbsences returns the approved requests of the leave types with days
+// in [startDate, endDate], each with the days of its breakdown falling in
+// the window, scaled like the request's own count
+func (r *leaveRepository) ListAbsences(orgID uuid.UUID, leaveTypeIDs []uuid.UUID, startDate, endDate time.Time) ([]domain.Absence, error) {
+	var absences []domain.Absence
+	if len(leaveTypeIDs) == 0 {
+		return absences, nil
+	}
+	err := r.db.Raw(`SELECT r.employee_id, r.start_date, r.end_date,
+			COALESCE(SUM(d.fraction * r.days / NULLIF(r.raw_days, 0)), 0) AS days
+		FROM leave_requests r
+		JOIN leave_request_days d ON d.leave_request_id = r.id
+		WHERE r.organization_id = ? AND r.leave_type_id IN ?
+			AND r.deleted_at IS NULL AND r.status IN ?
+			AND d.date BETWEEN ? AND ?
+		GROUP BY r.id, r.employee_id, r.start_date, r.end_date
+		ORDER BY r.employee_id, r.start_date`,
+		orgID, leaveTypeIDs, []string{domain.LeaveStatusApproved, domain.LeaveStatusCancellationRequested}, startDate, endDate).
+		Scan(&absences).Error
+	return absences, err
+}
+
+// ListBalanceAdjustmentChanges returns adjustments on the organization's
+// balances created or updated after the watermark in (updated_at, id) order
+func (r *leaveRepository) ListBalanceAdjustmentChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalanceAdjustment, error) {
+	var adjustments []domain.LeaveBalanceAdjustment
+	horizon, err := r.changesHorizon()
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Joins("JOIN leave_balances ON leave_balances.id = leave_balance_adjustments.leave_balance_id").
+		Where("leave_balances.organization_id = ?", orgID).
+		Where("(leave_balance_adjustments.updated_at > ? OR "+
+			"(leave_balance_adjustments.updated_at = ? AND leave_balance_adjustments.id > ?)) AND "+
+			"leave_balance_adjustments.updated_at < ?",
+			params.Since, params.Since, params.SinceID, horizon).
+		Order("leave_balance_adjustments.updated_at ASC, leave_balance_adjustments.id ASC").
+		Limit(params.Limit).
+		Find(&adjustments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balance adjustment changes: %w", err)
+	}
+	return adjustments, nil
+}
+
+// TransferLeaveBalance debits the employee's source balance and credits the
+// target balance, opening it at toEntitlement when it does not exist yet.
+// Only days that are neither used nor pending can be moved. Both sides are
+// recorded as approved adjustments sharing one transfer id.
+func (r *leaveRepository) TransferLeaveBalance(orgID uuid.UUID, req *domain.BalanceTransferRequest, toEntitlement float64) (*domain.BalanceTransfer, error) {
+	transfer := &domain.BalanceTransfer{TransferID: uuid.New()}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		seed := &domain.LeaveBalance{
+			OrganizationID: orgID,
+			EmployeeID:     req.EmployeeID,
+			LeaveTypeID:    req.ToLeaveTypeID,
+			Year:           req.Year,
+			TotalDays:      toEntitlement,
+		}
+		if err := tx.Omit("LeaveType").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "employee_id"}, {Name: "leave_type_id"}, {Name: "year"}},
+				DoNothing: true,
+			}).
+			Create(seed).Error; err != nil {
+			return err
+		}
+
+		// Lock both balances in id order so opposite transfers cannot deadlock
+		var balances []domain.LeaveBalance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("employee_id = ? AND year = ? AND leave_type_id IN ?",
+				req.EmployeeID, req.Year, []uuid.UUID{req.FromLeaveTypeID, req.ToLeaveTypeID}).
+			Order("id").
+			Find(&balances).Error; err != nil {
+			return err
+		}
+		var from, to *domain.LeaveBalance
+		for i := range balances {
+			switch balances[i].LeaveTypeID {
+			case req.FromLeaveTypeID:
+				from = &balances[i]
+			case req.ToLeaveTypeID:
+				to = &balances[i]
+			}
+		}
+		if from == nil || to == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		available := from.TotalDays - from.UsedDays - from.PendingDays
+		days := req.Days
+		if req.AllRemaining {
+			if available <= 0 {
+				return ErrNothingToTransfer
+			}
+			days = available
+		}
+		if available < days {
+			return &InsufficientBalanceError{Available: available, Requested: days}
+		}
+
+		now := time.Now()
+		debitID, creditID := uuid.New(), uuid.New()
+		transfer.Debit = &domain.LeaveBalanceAdjustment{
+			Base:           domain.Base{ID: debitID},
+			LeaveBalanceID: from.ID,
+			Adjustment:     -days,
+			Reason:         req.Reason,
+			PerformedBy:    req.PerformedBy,
+			ApprovedBy:     &req.PerformedBy,
+			ApprovedAt:     &now,
+			Status:         domain.AdjustmentStatusApproved,
+			TransferID:     &transfer.TransferID,
+			CounterpartID:  &creditID,
+		}
+		transfer.Credit = &domain.LeaveBalanceAdjustment{
+			Base:           domain.Base{ID: creditID},
+			LeaveBalanceID: to.ID,
+			Adjustment:     days,
+			Reason:         req.Reason,
+			PerformedBy:    req.PerformedBy,
+			ApprovedBy:     &req.PerformedBy,
+			ApprovedAt:     &now,
+			Status:         domain.AdjustmentStatusApproved,
+			TransferID:     &transfer.TransferID,
+			CounterpartID:  &debitID,
+		}
+		transfer.Debit.RecordBalance(from.TotalDays)
+		transfer.Credit.RecordBalance(to.TotalDays)
+
+		for _, adjustment := range []*domain.LeaveBalanceAdjustment{transfer.Debit, transfer.Credit} {
+			if err := tx.Omit("LeaveBalance").Create(adjustment).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&domain.LeaveBalance{}).
+				Where("id = ?", adjustment.LeaveBalanceID).
+				Update("total_days", gorm.Expr("total_days + ?", adjustment.Adjustment)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// ListBalanceEmployeeIDs returns the employees holding a balance of the leave type for the year
+func (r *leaveRepository) ListBalanceEmployeeIDs(leaveTypeID uuid.UUID, year int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveBalance{}).
+		Where("leave_type_id = ? AND year = ?", leaveTypeID, year).
+		Order("employee_id").
+		Pluck("employee_id", &ids).Error
+	return ids, err
+}
+
+func (r *leaveRepository) CreateBalanceTransferJob(job *domain.BalanceTransferJob) error {
+	return r.db.Omit("Failures").Create(job).Error
+}
+
+// UpdateBalanceTransferJob saves the status, progress counts and timestamps of a job
+func (r *leaveRepository) UpdateBalanceTransferJob(job *domain.BalanceTransferJob) error {
+	job.UpdatedAt = time.Now()
+	return r.db.Model(job).
+		Select("Status", "Succeeded", "Skipped", "Failed", "StartedAt", "FinishedAt", "UpdatedAt").
+		Updates(job).Error
+}
+
+// CreateJob queues a job. For singleton types the organization's active
+// jobs of the type are checked under an advisory lock on the organization
+// and type, and an *ActiveJobError names the one found.
+func (r *leaveRepository) CreateJob(job *domain.Job) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if job.Singleton {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))",
+				"jobs:"+job.OrganizationID.String()+":"+job.Type).Error; err != nil {
+				return err
+			}
+			var active []domain.Job
+			if err := tx.Where("organization_id = ? AND type = ? AND status IN ?",
+				job.OrganizationID, job.Type, []string{domain.JobStatusQueued, domain.JobStatusRunning}).
+				Limit(1).Find(&active).Error; err != nil {
+				return err
+			}
+			if len(active) > 0 {
+				return &ActiveJobError{Job: &active[0]}
+			}
+		}
+		return tx.Create(job).Error
+	})
+}
+
+func (r *leaveRepository) GetJob(id uuid.UUID) (*domain.Job, error) {
+	var job domain.Job
+	err := r.db.First(&job, "id = ?", id).Error
+	return &job, err
+}
+
+// ClaimJob hands the oldest claimable job to a worker for lease: a queued
+// job, or a running one whose worker let its lease expire. Jobs whose lease
+// expired on their last attempt are failed first. It returns nil when
+// there is nothing to run.
+func (r *leaveRepository) ClaimJob(lease time.Duration) (*domain.Job, error) {
+	var claimed *domain.Job
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&domain.Job{}).
+			Where("status = ? AND lease_expires_at < ? AND attempts >= ?", domain.JobStatusRunning, now, domain.JobMaxAttempts).
+			Updates(map[string]interface{}{
+				"status":           domain.JobStatusFailed,
+				"error":            fmt.Sprintf("worker lease expired on all %d attempts", domain.JobMaxAttempts),
+				"lease_expires_at": nil,
+				"finished_at":      now,
+				"updated_at":       now,
+			}).Error; err != nil {
+			return err
+		}
+
+		var jobs []domain.Job
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND lease_expires_at < ?)", domain.JobStatusQueued, domain.JobStatusRunning, now).
+			Order("created_at").Limit(1).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		job := &jobs[0]
+		expires := now.Add(lease)
+		job.Status = domain.JobStatusRunning
+		job.Attempts++
+		job.LeaseExpiresAt = &expires
+		if job.StartedAt == nil {
+			job.StartedAt = &now
+		}
+		job.UpdatedAt = now
+		if err := tx.Model(job).
+			Select("Status", "Attempts", "LeaseExpiresAt", "StartedAt", "UpdatedAt").
+			Updates(job).Error; err != nil {
+			return err
+		}
+		claimed = job
+		return nil
+	})
+	return claimed, err
+}
+
+// UpdateJobProgress saves a running job's progress and renews its lease
+func (r *leaveRepository) UpdateJobProgress(job *domain.Job, lease time.Duration) error {
+	now := time.Now()
+	expires := now.Add(lease)
+	job.LeaseExpiresAt = &expires
+	job.UpdatedAt = now
+	return r.saveClaimedJob(job, "Processed", "Total", "LeaseExpiresAt", "UpdatedAt")
+}
+
+// FinishJob saves a job's final status, result or error
+func (r *leaveRepository) FinishJob(job *domain.Job) error {
+	now := time.Now()
+	job.LeaseExpiresAt = nil
+	job.FinishedAt = &now
+	job.UpdatedAt = now
+	return r.saveClaimedJob(job, "Status", "Processed", "Total", "Result", "Error", "LeaseExpiresAt", "FinishedAt", "UpdatedAt")
+}
+
+// saveClaimedJob saves the fields of a job as long as the attempt that
+// claimed it still holds it, or returns ErrJobLeaseLost
+func (r *leaveRepository) saveClaimedJob(job *domain.Job, fields ...string) error {
+	result := r.db.Model(job).
+		Where("status = ? AND attempts = ?", domain.JobStatusRunning, job.Attempts).
+		Select(fields).
+		Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
+
+// CountJobsByStatus counts the queued and running jobs
+func (r *leaveRepository) CountJobsByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&domain.Job{}).
+		Select("status, COUNT(*) AS count").
+		Where("status IN ?", []string{domain.JobStatusQueued, domain.JobStatusRunning}).
+		Group("status").
+		Scan(&rows).Error
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, err
+}
+
+func (r *leaveRepository) AddBalanceTransferJobFailure(failure *domain.BalanceTransferJobFailure) error {
+	return r.db.Create(failure).Error
+}
+
+func (r *leaveRepository) GetBalanceTransferJob(id uuid.UUID) (*domain.BalanceTransferJob, error) {
+	var job domain.BalanceTransferJob
+	err := r.db.Preload("Failures", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).First(&job, "id = ?", id).Error
+	return &job, err
+}
+
 // HasActiveLeaveRequests checks if there are any active leave requests for a leave type
 func (r *leaveRepository) HasActiveLeaveRequests(leaveTypeID uuid.UUID) (bool, error) {
 	var count int64
 	err := r.db.Model(&domain.LeaveRequest{}).
 		Where("leave_type_id = ? AND status IN (?)",
 			leaveTypeID,
-			[]string{domain.LeaveStatusPending, domain.LeaveStatusApproved}).
+			domain.ActiveLeaveStatuses).
 		Count(&count).Error
 
 	if err != nil {
@@ -454,3 +2800,674 @@ func (r *leaveRepository) HasActiveLeaveRequests(leaveTypeID uuid.UUID) (bool, e
 
 	return count > 0, nil
 }
+
+// ListPendingLeaveRequests returns every request in the organization awaiting
+// a decision, pending or with a requested cancellation, with its leave type,
+// oldest first
+func (r *leaveRepository) ListPendingLeaveRequests(orgID uuid.UUID) ([]domain.LeaveRequest, error) {
+	var requests []domain.LeaveRequest
+	err := r.db.Preload("LeaveType").Preload("Approvals").
+		Where("organization_id = ? AND status IN (?)", orgID,
+			[]string{domain.LeaveStatusPending, domain.LeaveStatusCancellationRequested}).
+		Order("created_at ASC, id ASC").
+		Find(&requests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending leave requests: %w", err)
+	}
+	return requests, nil
+}
+
+// ListApproverDecisions returns the approve and reject decisions recorded in
+// [from, to) on requests that are not deleted, excluding system actions
+// such as auto-approval
+func (r *leaveRepository) ListApproverDecisions(orgID uuid.UUID, from, to time.Time, excludeBackdated bool) ([]domain.ApproverDecision, error) {
+	var decisions []domain.ApproverDecision
+	query := r.db.Table("leave_request_history AS h").
+		Select("h.performed_by AS approver_id, h.action, r.created_at AS requested_at, "+
+			"h.created_at AS decided_at, r.start_date").
+		Joins("JOIN leave_requests r ON r.id = h.leave_request_id AND r.deleted_at IS NULL").
+		Where("r.organization_id = ? AND h.action IN ? AND h.performed_by <> ? AND h.created_at >= ? AND h.created_at < ?",
+			orgID, []string{domain.LeaveActionApprove, domain.LeaveActionReject}, domain.SystemUserID, from, to)
+	if excludeBackdated {
+		query = query.Where("r.backdated = ?", false)
+	}
+	err := query.Order("h.created_at ASC").Scan(&decisions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approver decisions: %w", err)
+	}
+	return decisions, nil
+}
+
+// CountOverduePendingByApprover counts, per assigned approver, pending
+// requests whose leave has already started
+func (r *leaveRepository) CountOverduePendingByApprover(orgID uuid.UUID, now time.Time) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		AssignedApproverID uuid.UUID
+		Count              int
+	}
+	err := r.db.Model(&domain.LeaveRequest{}).
+		Select("assigned_approver_id, COUNT(*) AS count").
+		Where("organization_id = ? AND status = ? AND assigned_approver_id IS NOT NULL AND start_date < ?",
+			orgID, domain.LeaveStatusPending, now).
+		Group("assigned_approver_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count overdue pending requests: %w", err)
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.AssignedApproverID] = row.Count
+	}
+	return counts, nil
+}
+
+// Organization settings methods
+func (r *leaveRepository) GetOrganizationSettings(orgID uuid.UUID) (*domain.OrganizationSettings, error) {
+	var settings domain.OrganizationSettings
+	err := r.db.First(&settings, "organization_id = ?", orgID).Error
+	return &settings, err
+}
+
+func (r *leaveRepository) SaveOrganizationSettings(settings *domain.OrganizationSettings) error {
+	settings.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"free_cancellation_days", "backdate_window_days", "hours_per_day", "sandwich_policy",
+			"max_concurrent_absences", "concurrent_absence_mode", "timezone", "anomaly_max_mutation_days",
+			"anomaly_max_writes_per_minute", "anomaly_hold_adjustments", "max_advance_booking_months",
+			"proration_basis", "proration_rounding", "undo_window_minutes", "probation_months",
+			"delegation_confirm_days", "delegation_webhook_url", "updated_at",
+		}),
+	}).Create(settings).Error
+}
+
+// Approver pool methods
+func (r *leaveRepository) CreateApproverPool(pool *domain.ApproverPool) error {
+	return r.db.Create(pool).Error
+}
+
+func (r *leaveRepository) GetApproverPool(id uuid.UUID) (*domain.ApproverPool, error) {
+	var pool domain.ApproverPool
+	err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).First(&pool, "id = ?", id).Error
+	return &pool, err
+}
+
+func (r *leaveRepository) ListApproverPools(orgID uuid.UUID) ([]domain.ApproverPool, error) {
+	var pools []domain.ApproverPool
+	err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Where("organization_id = ?", orgID).Order("name ASC").Find(&pools).Error
+	return pools, err
+}
+
+func (r *leaveRepository) AddApproverPoolMember(member *domain.ApproverPoolMember) error {
+	return r.db.Create(member).Error
+}
+
+// IsApproverPoolMember reports whether the user belongs to the pool
+func (r *leaveRepository) IsApproverPoolMember(poolID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ApproverPoolMember{}).
+		Where("pool_id = ? AND user_id = ?", poolID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *leaveRepository) RemoveApproverPoolMember(poolID, userID uuid.UUID) error {
+	result := r.db.Where("pool_id = ? AND user_id = ?", poolID, userID).
+		Delete(&domain.ApproverPoolMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// NextPoolApprover hands out pool members in join order, starting after the
+// last assigned member. The pool row is locked so concurrent submissions
+// advance the pointer one at a time.
+func (r *leaveRepository) NextPoolApprover(poolID uuid.UUID) (uuid.UUID, error) {
+	var next uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var pool domain.ApproverPool
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&pool, "id = ?", poolID).Error; err != nil {
+			return err
+		}
+
+		var members []domain.ApproverPoolMember
+		if err := tx.Where("pool_id = ?", poolID).
+			Order("created_at ASC, id ASC").
+			Find(&members).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return ErrEmptyApproverPool
+		}
+
+		next = members[0].UserID
+		if pool.LastAssignedUserID != nil {
+			for i, m := range members {
+				if m.UserID == *pool.LastAssignedUserID {
+					next = members[(i+1)%len(members)].UserID
+					break
+				}
+			}
+		}
+
+		return tx.Model(&pool).Update("last_assigned_user_id", next).Error
+	})
+	return next, err
+}
+
+// CreateCompOffRequest stores a new comp-off request, refusing with
+// ErrDuplicateCompOff a second claim of the employee for the same date
+// unless the earlier one was rejected
+func (r *leaveRepository) CreateCompOffRequest(request *domain.CompOffRequest) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&domain.CompOffRequest{}).
+			Where("employee_id = ? AND date_worked = ? AND status <> ?",
+				request.EmployeeID, request.DateWorked.Format("2006-01-02"), domain.CompOffStatusRejected).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrDuplicateCompOff
+		}
+
+		err := tx.Create(request).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateCompOff
+		}
+		return err
+	})
+}
+
+func (r *leaveRepository) GetCompOffRequest(id uuid.UUID) (*domain.CompOffRequest, error) {
+	var request domain.CompOffRequest
+	err := r.db.First(&request, "id = ?", id).Error
+	return &request, err
+}
+
+// ListCompOffRequests returns a page of the organization's comp-off
+// requests, oldest first
+func (r *leaveRepository) ListCompOffRequests(orgID uuid.UUID, params *domain.ListCompOffRequestsParams) ([]domain.CompOffRequest, int64, error) {
+	var requests []domain.CompOffRequest
+	var total int64
+
+	query := r.db.Model(&domain.CompOffRequest{}).Where("organization_id = ?", orgID)
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *params.EmployeeID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count comp-off requests: %w", err)
+	}
+
+	if params.Page > 0 && params.PageSize > 0 {
+		query = query.Offset((params.Page - 1) * params.PageSize).Limit(params.PageSize)
+	}
+
+	err := query.Order("created_at, id").Find(&requests).Error
+	return requests, total, err
+}
+
+// lockPendingCompOff locks a comp-off request, returning ErrStatusChanged
+// when it was decided in the meantime
+func lockPendingCompOff(tx *gorm.DB, id uuid.UUID) error {
+	current := &domain.CompOffRequest{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(current, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if !current.CanDecide() {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+// RejectCompOffRequest saves the rejection of a pending request
+func (r *leaveRepository) RejectCompOffRequest(request *domain.CompOffRequest) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPendingCompOff(tx, request.ID); err != nil {
+			return err
+		}
+		return tx.Model(request).
+			Select("status", "decided_by", "decided_at", "comments", "updated_at").
+			Updates(request).Error
+	})
+}
+
+// ApproveCompOffRequest approves a pending request and credits it to the
+// employee's balance of the comp-off leave type for year, opening the
+// balance at opening when there is none. The credit is recorded as an
+// approved adjustment by the approver; the request is updated with it.
+func (r *leaveRepository) ApproveCompOffRequest(request *domain.CompOffRequest, leaveTypeID uuid.UUID, year int, opening, credit float64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPendingCompOff(tx, request.ID); err != nil {
+			return err
+		}
+
+		seed := &domain.LeaveBalance{
+			OrganizationID: request.OrganizationID,
+			EmployeeID:     request.EmployeeID,
+			LeaveTypeID:    leaveTypeID,
+			Year:           year,
+			TotalDays:      opening,
+			OpeningDays:    &opening,
+		}
+		if err := tx.Omit("LeaveType").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "employee_id"}, {Name: "leave_type_id"}, {Name: "year"}},
+				DoNothing: true,
+			}).
+			Create(seed).Error; err != nil {
+			return err
+		}
+
+		balance := &domain.LeaveBalance{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("employee_id = ? AND leave_type_id = ? AND year = ?", request.EmployeeID, leaveTypeID, year).
+			Take(balance).Error; err != nil {
+			return err
+		}
+
+		adjustment := &domain.LeaveBalanceAdjustment{
+			LeaveBalanceID: balance.ID,
+			Adjustment:     credit,
+			Reason:         fmt.Sprintf("comp-off for work on %s: %s", request.DateWorked.Format("2006-01-02"), request.Reason),
+			PerformedBy:    *request.DecidedBy,
+			ApprovedBy:     request.DecidedBy,
+			ApprovedAt:     request.DecidedAt,
+			AppliedAt:      request.DecidedAt,
+			Status:         domain.AdjustmentStatusApproved,
+		}
+		adjustment.RecordBalance(balance.TotalDays)
+		if err := tx.Omit("LeaveBalance").Create(adjustment).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(balance).Update("total_days", gorm.Expr("total_days + ?", credit)).Error; err != nil {
+			return err
+		}
+
+		request.LeaveBalanceID = &balance.ID
+		request.AdjustmentID = &adjustment.ID
+		request.Credited = &credit
+		return tx.Model(request).
+			Select("status", "decided_by", "decided_at", "comments", "leave_balance_id", "adjustment_id",
+				"credited", "expires_on", "updated_at").
+			Updates(request).Error
+	})
+}
+
+// ListExpiredCompOffIDs returns the organization's approved comp-off whose
+// last usable day was before today
+func (r *leaveRepository) ListExpiredCompOffIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.CompOffRequest{}).
+		Where("organization_id = ? AND status = ? AND expires_on < ?",
+			orgID, domain.CompOffStatusApproved, today.Format("2006-01-02")).
+		Order("expires_on, id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ExpireCompOff lapses an approved comp-off credit whose last usable day
+// was before today. What is left of the credit, up to the days still
+// unused on the balance, is forfeited through an approved adjustment by
+// the system user, which is returned; it is nil when nothing was left, or
+// the request was already expired by a concurrent run.
+func (r *leaveRepository) ExpireCompOff(id uuid.UUID, today time.Time) (*domain.LeaveBalanceAdjustment, error) {
+	var adjustment *domain.LeaveBalanceAdjustment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		request := &domain.CompOffRequest{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(request, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if request.Status != domain.CompOffStatusApproved || request.ExpiresOn == nil ||
+			!request.ExpiresOn.Before(today) || request.LeaveBalanceID == nil || request.Credited == nil {
+			return nil
+		}
+
+		balance := &domain.LeaveBalance{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(balance, "id = ?", *request.LeaveBalanceID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if forfeited := math.Min(*request.Credited, balance.Remaining()); forfeited > 0 {
+			adjustment = &domain.LeaveBalanceAdjustment{
+				LeaveBalanceID: balance.ID,
+				Adjustment:     -forfeited,
+				Reason: fmt.Sprintf("%.2f of comp-off for work on %s expired on %s",
+					forfeited, request.DateWorked.Format("2006-01-02"), request.ExpiresOn.Format("2006-01-02")),
+				PerformedBy: domain.SystemUserID,
+				ApprovedBy:  &domain.SystemUserID,
+				ApprovedAt:  &now,
+				AppliedAt:   &now,
+				Status:      domain.AdjustmentStatusApproved,
+			}
+			adjustment.RecordBalance(balance.TotalDays)
+			if err := tx.Omit("LeaveBalance").Create(adjustment).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(balance).Update("total_days", gorm.Expr("total_days + ?", adjustment.Adjustment)).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(request).Updates(map[string]interface{}{
+			"status":     domain.CompOffStatusExpired,
+			"expired_at": now,
+			"updated_at": now,
+		}).Error
+	})
+	return adjustment, err
+}
+
+// CreateOptionalHolidayClaim stores an employee's claim of an optional
+// holiday in year. Under an advisory lock on the employee it refuses with
+// ErrDuplicateOptionalHolidayClaim a holiday claimed before and, when
+// quota is set, with ErrOptionalHolidayQuotaReached a claim past it.
+func (r *leaveRepository) CreateOptionalHolidayClaim(claim *domain.OptionalHolidayClaim, year, quota int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))",
+			"optional-holidays:"+claim.EmployeeID.String()).Error; err != nil {
+			return err
+		}
+
+		var claimed []domain.OptionalHolidayClaim
+		if err := tx.Joins("JOIN holidays ON holidays.id = optional_holiday_claims.holiday_id").
+			Where("optional_holiday_claims.employee_id = ? AND holidays.type = ? AND holidays.date BETWEEN ? AND ?",
+				claim.EmployeeID, domain.HolidayTypeOptional,
+				fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year)).
+			Find(&claimed).Error; err != nil {
+			return err
+		}
+		for _, other := range claimed {
+			if other.HolidayID == claim.HolidayID {
+				return ErrDuplicateOptionalHolidayClaim
+			}
+		}
+		if quota > 0 && len(claimed) >= quota {
+			return ErrOptionalHolidayQuotaReached
+		}
+
+		err := tx.Omit("Holiday").Create(claim).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateOptionalHolidayClaim
+		}
+		return err
+	})
+}
+
+func (r *leaveRepository) GetOptionalHolidayClaim(employeeID, holidayID uuid.UUID) (*domain.OptionalHolidayClaim, error) {
+	var claim domain.OptionalHolidayClaim
+	err := r.db.Preload("Holiday").
+		First(&claim, "employee_id = ? AND holiday_id = ?", employeeID, holidayID).Error
+	return &claim, err
+}
+
+func (r *leaveRepository) DeleteOptionalHolidayClaim(id uuid.UUID) error {
+	return r.db.Delete(&domain.OptionalHolidayClaim{}, "id = ?", id).Error
+}
+
+// ListOptionalHolidayClaims returns the organization's claims of optional
+// holidays dated within [from, to], or one employee's, by date
+func (r *leaveRepository) ListOptionalHolidayClaims(orgID uuid.UUID, employeeID *uuid.UUID, from, to time.Time) ([]domain.OptionalHolidayClaim, error) {
+	query := r.db.Preload("Holiday").
+		Joins("JOIN holidays ON holidays.id = optional_holiday_claims.holiday_id").
+		Where("optional_holiday_claims.organization_id = ? AND holidays.type = ? AND holidays.date BETWEEN ? AND ?",
+			orgID, domain.HolidayTypeOptional, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if employeeID != nil {
+		query = query.Where("optional_holiday_claims.employee_id = ?", *employeeID)
+	}
+
+	var claims []domain.OptionalHolidayClaim
+	err := query.Order("holidays.date ASC, optional_holiday_claims.employee_id ASC").Find(&claims).Error
+	return claims, err
+}
+
+// ListAbsenceAlertOrganizationIDs returns the organizations with an
+// absence threshold set or an alert not cleared yet, which a disabled
+// threshold clears
+func (r *leaveRepository) ListAbsenceAlertOrganizationIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Raw(`SELECT organization_id FROM organization_settings
+			WHERE absence_alert_days > 0 OR absence_alert_spells > 0
+		UNION
+		SELECT organization_id FROM absence_alerts WHERE cleared_at IS NULL
+		ORDER BY organization_id`).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// ListAbsenceMonitoredLeaveTypeIDs returns the organization's leave types
+// counted towards its absence thresholds
+func (r *leaveRepository) ListAbsenceMonitoredLeaveTypeIDs(orgID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.LeaveType{}).
+		Where("organization_id = ? AND absence_monitored", orgID).
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ListUnclearedAbsenceAlerts returns the organization's alerts whose
+// employee has not fallen back under the thresholds yet, at most one each
+func (r *leaveRepository) ListUnclearedAbsenceAlerts(orgID uuid.UUID) ([]domain.AbsenceAlert, error) {
+	var alerts []domain.AbsenceAlert
+	err := r.db.Where("organization_id = ? AND cleared_at IS NULL", orgID).
+		Order("employee_id").
+		Find(&alerts).Error
+	return alerts, err
+}
+
+// CreateAbsenceAlert stores a new alert unless the employee already has one
+// not cleared, reporting whether it was created
+func (r *leaveRepository) CreateAbsenceAlert(alert *domain.AbsenceAlert) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "organization_id"}, {Name: "employee_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "cleared_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(alert)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClearAbsenceAlert marks an alert's employee as back under the thresholds
+func (r *leaveRepository) ClearAbsenceAlert(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.AbsenceAlert{}).
+		Where("id = ? AND cleared_at IS NULL", id).
+		Updates(map[string]interface{}{"cleared_at": at, "updated_at": at}).Error
+}
+
+// MarkAbsenceAlertNotified records that HR was notified of an alert
+func (r *leaveRepository) MarkAbsenceAlertNotified(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.AbsenceAlert{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"notified_at": at, "updated_at": at}).Error
+}
+
+func (r *leaveRepository) GetAbsenceAlert(id uuid.UUID) (*domain.AbsenceAlert, error) {
+	var alert domain.AbsenceAlert
+	err := r.db.First(&alert, "id = ?", id).Error
+	return &alert, err
+}
+
+// ListAbsenceAlerts returns a page of the organization's absence alerts,
+// oldest first
+func (r *leaveRepository) ListAbsenceAlerts(orgID uuid.UUID, params *domain.ListAbsenceAlertsParams) ([]domain.AbsenceAlert, int64, error) {
+	var alerts []domain.AbsenceAlert
+	var total int64
+
+	query := r.db.Model(&domain.AbsenceAlert{}).Where("organization_id = ?", orgID)
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.EmployeeID != nil {
+		query = query.Where("employee_id = ?", *params.EmployeeID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count absence alerts: %w", err)
+	}
+
+	if params.Page > 0 && params.PageSize > 0 {
+		query = query.Offset((params.Page - 1) * params.PageSize).Limit(params.PageSize)
+	}
+
+	err := query.Order("created_at, id").Find(&alerts).Error
+	return alerts, total, err
+}
+
+// lockAbsenceAlert locks an alert, returning ErrStatusChanged when another
+// action moved it out of the statuses allowed
+func lockAbsenceAlert(tx *gorm.DB, id uuid.UUID, allowed func(*domain.AbsenceAlert) bool) error {
+	current := &domain.AbsenceAlert{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(current, "id = ?", id).Error; err != nil {
+		return err
+	}
+	if !allowed(current) {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+// AcknowledgeAbsenceAlert saves the acknowledgement of an open alert
+func (r *leaveRepository) AcknowledgeAbsenceAlert(alert *domain.AbsenceAlert) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockAbsenceAlert(tx, alert.ID, (*domain.AbsenceAlert).CanAcknowledge); err != nil {
+			return err
+		}
+		return tx.Model(alert).
+			Select("status", "acknowledged_by", "acknowledged_at", "comments", "updated_at").
+			Updates(alert).Error
+	})
+}
+
+// ResolveAbsenceAlert saves the resolution of an open or acknowledged alert
+func (r *leaveRepository) ResolveAbsenceAlert(alert *domain.AbsenceAlert) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockAbsenceAlert(tx, alert.ID, (*domain.AbsenceAlert).CanResolve); err != nil {
+			return err
+		}
+		return tx.Model(alert).
+			Select("status", "resolved_by", "resolved_at", "comments", "updated_at").
+			Updates(alert).Error
+	})
+}
+
+// CreateDelegation stores a new delegation unless its leave already has
+// one, reporting whether it did
+func (r *leaveRepository) CreateDelegation(delegation *domain.Delegation) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "leave_request_id"}},
+		DoNothing: true,
+	}).Create(delegation)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *leaveRepository) GetDelegation(id uuid.UUID) (*domain.Delegation, error) {
+	var delegation domain.Delegation
+	err := r.db.First(&delegation, "id = ?", id).Error
+	return &delegation, err
+}
+
+// GetLeaveRequestDelegation returns the delegation proposed for a leave
+// request
+func (r *leaveRepository) GetLeaveRequestDelegation(leaveRequestID uuid.UUID) (*domain.Delegation, error) {
+	var delegation domain.Delegation
+	err := r.db.First(&delegation, "leave_request_id = ?", leaveRequestID).Error
+	return &delegation, err
+}
+
+// ListDelegations returns a page of the organization's delegations, by
+// start date
+func (r *leaveRepository) ListDelegations(orgID uuid.UUID, params *domain.ListDelegationsParams) ([]domain.Delegation, int64, error) {
+	var delegations []domain.Delegation
+	var total int64
+
+	query := r.db.Model(&domain.Delegation{}).Where("organization_id = ?", orgID)
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.DelegatorID != nil {
+		query = query.Where("delegator_id = ?", *params.DelegatorID)
+	}
+	if params.DelegateID != nil {
+		query = query.Where("delegate_id = ?", *params.DelegateID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count delegations: %w", err)
+	}
+
+	if params.Page > 0 && params.PageSize > 0 {
+		query = query.Offset((params.Page - 1) * params.PageSize).Limit(params.PageSize)
+	}
+
+	err := query.Order("start_date, id").Find(&delegations).Error
+	return delegations, total, err
+}
+
+// UpdateDelegation saves a delegation's status, delegate, period and
+// decision, returning ErrStatusChanged when another action moved it out of
+// the statuses allowed
+func (r *leaveRepository) UpdateDelegation(delegation *domain.Delegation, allowed func(*domain.Delegation) bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		current := &domain.Delegation{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(current, "id = ?", delegation.ID).Error; err != nil {
+			return err
+		}
+		if !allowed(current) {
+			return ErrStatusChanged
+		}
+		return tx.Model(delegation).
+			Select("status", "delegate_id", "start_date", "end_date", "confirm_by", "escalated_at",
+				"decided_by", "decided_at", "updated_at").
+			Updates(delegation).Error
+	})
+}
+
+// MarkDelegationNotified records that a delegation's webhook was sent
+func (r *leaveRepository) MarkDelegationNotified(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.Delegation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"notified_at": at, "updated_at": at}).Error
+}
+
+// ListOverdueDelegations returns the proposals of every organization not
+// confirmed by their deadline
+func (r *leaveRepository) ListOverdueDelegations(now time.Time) ([]domain.Delegation, error) {
+	var delegations []domain.Delegation
+	err := r.db.Where("status = ? AND confirm_by < ?", domain.DelegationStatusProposed, now).
+		Order("confirm_by, id").
+		Find(&delegations).Error
+	return delegations, err
+}
+
+// ListDelegators returns the approvers whose confirmed delegations to the
+// delegate cover day
+func (r *leaveRepository) ListDelegators(orgID, delegateID uuid.UUID, day time.Time) ([]uuid.UUID, error) {
+	var delegators []uuid.UUID
+	err := r.db.Model(&domain.Delegation{}).
+		Where("organization_id = ? AND delegate_id = ? AND status = ? AND start_date <= ? AND end_date >= ?",
+			orgID, delegateID, domain.DelegationStatusConfirmed, day, day).
+		Distinct().
+		Pluck("delegator_id", &delegators).Error
+	return delegators, err
+}
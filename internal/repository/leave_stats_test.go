@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestListApproverDecisionsSkipsDeletedRequests(t *testing.T) {
+	tests := []struct {
+		name             string
+		excludeBackdated bool
+		wantBackdated    bool
+	}{
+		{name: "all requests", excludeBackdated: false},
+		{name: "backdated excluded", excludeBackdated: true, wantBackdated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			if _, err := repo.ListApproverDecisions(uuid.New(), from, from.AddDate(0, 1, 0), tt.excludeBackdated); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			query := rec.last(t).SQL
+			if !strings.Contains(query, "JOIN leave_requests r ON r.id = h.leave_request_id AND r.deleted_at IS NULL") {
+				t.Errorf("deleted requests are not excluded: %s", query)
+			}
+			if got := strings.Contains(query, "r.backdated"); got != tt.wantBackdated {
+				t.Errorf("backdated filter present = %v, want %v: %s", got, tt.wantBackdated, query)
+			}
+		})
+	}
+}
+
+func TestListMonthlyLeaveGroupsDaysByMonth(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	leaveTypeID := uuid.New()
+	if _, err := repo.ListMonthlyLeave(uuid.New(), &leaveTypeID, from, from.AddDate(0, 3, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := rec.last(t)
+	for _, want := range []string{
+		// Days are grouped by the month they fall in, not the request's
+		"date_trunc('month', leave_request_days.date)",
+		"leave_request_days.date >= $", "leave_request_days.date < $",
+		"COUNT(DISTINCT r.id)",
+		"r.deleted_at IS NULL",
+		"r.leave_type_id = $",
+		"GROUP BY date_trunc('month', leave_request_days.date)",
+	} {
+		if !strings.Contains(query.SQL, want) {
+			t.Errorf("query lacks %s: %s", want, query.SQL)
+		}
+	}
+	if !hasArg(query, "2026-01-01") || !hasArg(query, "2026-04-01") || !hasArg(query, leaveTypeID) {
+		t.Errorf("query args = %+v", query.Args)
+	}
+}
+
+func TestListEmployeeLeaveByTypeTotalsApprovedDays(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := repo.ListEmployeeLeaveByType(uuid.New(), from, from.AddDate(0, 3, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := rec.last(t)
+	for _, want := range []string{
+		// Requests count whatever their status; only approved days are taken
+		"COUNT(*) AS count",
+		"SUM(leave_requests.days) FILTER (WHERE leave_requests.status = 'approved')",
+		"leave_requests.start_date >= $2 AND leave_requests.start_date < $3",
+		`"leave_requests"."deleted_at" IS NULL`,
+		"GROUP BY leave_requests.employee_id, lt.id",
+	} {
+		if !strings.Contains(query.SQL, want) {
+			t.Errorf("query lacks %s: %s", want, query.SQL)
+		}
+	}
+}
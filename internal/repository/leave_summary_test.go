@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetLeaveStatsSplitsPaidAndUnpaid(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	rec.respond("AS unpaid_days_taken",
+		[]string{"total_requests", "total_days_taken", "paid_days_taken", "unpaid_days_taken"},
+		[]driver.Value{int64(6), 9.5, 7.0, 2.5})
+	rec.respond("AS leave_type, lt.is_paid",
+		[]string{"leave_type", "is_paid", "unit", "count", "total_days"},
+		[]driver.Value{"Annual", true, "days", int64(3), 7.0},
+		[]driver.Value{"Time off in lieu", true, "hours", int64(2), 6.0},
+		[]driver.Value{"Unpaid", false, "days", int64(1), 2.5})
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+	stats, err := repo.GetLeaveStats(uuid.New(), from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.TotalRequests != 6 || stats.TotalDaysTaken != 9.5 || stats.PaidDaysTaken != 7 || stats.UnpaidDaysTaken != 2.5 {
+		t.Errorf("got totals %+v", stats)
+	}
+	if len(stats.LeaveByType) != 3 {
+		t.Fatalf("got %d per-type rows, want 3", len(stats.LeaveByType))
+	}
+	if row := stats.LeaveByType[1]; row.Unit != "hours" || !row.IsPaid || row.TotalDays != 6 {
+		t.Errorf("hourly row = %+v", row)
+	}
+	if row := stats.LeaveByType[2]; row.IsPaid || row.TotalDays != 2.5 {
+		t.Errorf("unpaid row = %+v", row)
+	}
+
+	totals := rec.find("AS unpaid_days_taken")
+	if len(totals) != 1 {
+		t.Fatalf("got %d totals queries, want 1", len(totals))
+	}
+	query := totals[0].SQL
+	for _, want := range []string{
+		"leave_requests.start_date >= $2 AND leave_requests.start_date < $3",
+		"lt.unit = 'days' AND lt.is_paid)",
+		"lt.unit = 'days' AND NOT lt.is_paid)",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("totals query is missing %q: %s", want, query)
+		}
+	}
+	if args := totals[0].Args; len(args) < 3 || args[1].Value != from || args[2].Value != to {
+		t.Errorf("period args = %+v, want [%s, %s)", args, from, to)
+	}
+}
+
+func TestListEntitlementLeaveTypesSkipsUnpaid(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	if _, err := repo.ListEntitlementLeaveTypes(uuid.New()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query := rec.last(t).SQL; !strings.Contains(query, "is_paid AND tracks_balance") {
+		t.Errorf("unpaid or untracked types are not skipped: %s", query)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestCreateOptionalHolidayClaim(t *testing.T) {
+	holidayID := uuid.New()
+	tests := []struct {
+		name    string
+		claimed []uuid.UUID
+		quota   int
+		wantErr error
+	}{
+		{name: "first", quota: 2},
+		{name: "within the quota", claimed: []uuid.UUID{uuid.New()}, quota: 2},
+		{name: "past the quota", claimed: []uuid.UUID{uuid.New(), uuid.New()}, quota: 2, wantErr: ErrOptionalHolidayQuotaReached},
+		{name: "without a quota", claimed: []uuid.UUID{uuid.New(), uuid.New()}},
+		{name: "claimed before", claimed: []uuid.UUID{holidayID}, quota: 2, wantErr: ErrDuplicateOptionalHolidayClaim},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			var rows [][]driver.Value
+			for _, id := range tt.claimed {
+				rows = append(rows, []driver.Value{uuid.New().String(), id.String()})
+			}
+			rec.respond(`SELECT "optional_holiday_claims"."id"`, []string{"id", "holiday_id"}, rows...)
+			rec.respond(`INSERT INTO "optional_holiday_claims"`, []string{"id"}, []driver.Value{uuid.New().String()})
+
+			claim := &domain.OptionalHolidayClaim{EmployeeID: uuid.New(), HolidayID: holidayID}
+			err := repo.CreateOptionalHolidayClaim(claim, 2027, tt.quota)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			// Claims of one employee are counted one at a time
+			locks := rec.find("pg_advisory_xact_lock")
+			if len(locks) != 1 || !hasArg(locks[0], "optional-holidays:"+claim.EmployeeID.String()) {
+				t.Errorf("the employee was not locked: %+v", locks)
+			}
+			counted := rec.find(`FROM "optional_holiday_claims" JOIN holidays`)
+			if len(counted) != 1 || !hasArg(counted[0], "2027-01-01") || !hasArg(counted[0], "2027-12-31") ||
+				!hasArg(counted[0], domain.HolidayTypeOptional) {
+				t.Errorf("claims were not counted over the year: %+v", counted)
+			}
+			if inserted := len(rec.find(`INSERT INTO "optional_holiday_claims"`)) == 1; inserted != (tt.wantErr == nil) {
+				t.Errorf("claim inserted = %t, want %t", inserted, tt.wantErr == nil)
+			}
+		})
+	}
+}
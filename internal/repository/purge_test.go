@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestPurgeLeaveRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		votes   int64
+		wantErr error
+	}{
+		{name: "pending without votes", status: domain.LeaveStatusPending},
+		{name: "decided meanwhile", status: domain.LeaveStatusApproved, wantErr: ErrStatusChanged},
+		{name: "voted on meanwhile", status: domain.LeaveStatusPending, votes: 1, wantErr: ErrStatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			request := &domain.LeaveRequest{Base: domain.Base{ID: uuid.New()}}
+			rec.respond(`SELECT * FROM "leave_requests"`, []string{"id", "status", "days", "leave_type_id"},
+				[]driver.Value{request.ID.String(), tt.status, 3.0, uuid.New().String()})
+			rec.respond(`FROM "leave_request_approvals"`, []string{"count"}, []driver.Value{tt.votes})
+			rec.respond(`SELECT "tracks_balance" FROM "leave_types"`, []string{"tracks_balance"}, []driver.Value{true})
+
+			err := repo.PurgeLeaveRequest(request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+
+			if lock := rec.find(`SELECT * FROM "leave_requests"`); len(lock) != 1 || !strings.Contains(lock[0].SQL, "FOR UPDATE") {
+				t.Errorf("the request was not locked: %+v", lock)
+			}
+			deletes := rec.find(`DELETE FROM "leave_requests"`)
+			if tt.wantErr != nil {
+				if len(deletes) != 0 || len(rec.find(`UPDATE "leave_balances"`)) != 0 {
+					t.Error("a request that can no longer be undone was purged")
+				}
+				return
+			}
+
+			release := rec.find(`UPDATE "leave_balances" SET "pending_days"=pending_days + $1`)
+			if len(release) != 1 || !hasArg(release[0], -3.0) {
+				t.Errorf("pending days were not released: %+v", release)
+			}
+			if len(rec.find(`DELETE FROM "leave_request_history"`)) != 1 {
+				t.Error("the request's history was kept")
+			}
+			// Unscoped, so the row is gone rather than soft-deleted
+			if len(deletes) != 1 || strings.Contains(deletes[0].SQL, "deleted_at") {
+				t.Errorf("the request was not deleted outright: %+v", deletes)
+			}
+		})
+	}
+}
+
+func TestPurgeOrganization(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	orgID := uuid.New()
+
+	if err := repo.PurgeOrganization(orgID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history := rec.find(`DELETE FROM "leave_request_history"`)
+	if len(history) != 1 || !strings.Contains(history[0].SQL, `SELECT "id" FROM "leave_requests" WHERE organization_id = $1`) ||
+		!hasArg(history[0], orgID) {
+		t.Errorf("history not deleted for the organization's requests: %+v", history)
+	}
+	requests := rec.find(`DELETE FROM "leave_requests"`)
+	if len(requests) != 1 || strings.Contains(requests[0].SQL, "deleted_at") {
+		t.Errorf("requests not deleted outright: %+v", requests)
+	}
+
+	// Then the rest, in order, each scoped to the organization
+	var purged []recordedQuery
+	for _, statement := range purgeOrganizationSQL {
+		found := rec.find(strings.ReplaceAll(statement, "@org", "$1"))
+		if len(found) != 1 || !hasArg(found[0], orgID) {
+			t.Fatalf("%q not run for the organization: %+v", statement, found)
+		}
+		purged = append(purged, found[0])
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	position := func(query recordedQuery) int {
+		for i, q := range rec.queries {
+			if q.SQL == query.SQL {
+				return i
+			}
+		}
+		return -1
+	}
+	last := position(requests[0])
+	for _, query := range purged {
+		at := position(query)
+		if at < last {
+			t.Errorf("%q ran out of order", query.SQL)
+		}
+		last = at
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestRecordQuorumApproval(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		duplicate    bool
+		approvals    int64
+		wantErr      error
+		wantAction   string
+		wantApproved bool
+	}{
+		{name: "short of quorum", status: domain.LeaveStatusPending, approvals: 1, wantAction: domain.LeaveActionPartialApprove},
+		{name: "meeting quorum", status: domain.LeaveStatusPending, approvals: 2, wantAction: domain.LeaveActionApprove, wantApproved: true},
+		{name: "same approver again", status: domain.LeaveStatusPending, duplicate: true, wantErr: ErrAlreadyApproved},
+		{name: "quorum already met", status: domain.LeaveStatusApproved, wantErr: ErrStatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			requestID, approverID := uuid.New(), uuid.New()
+			rec.respond(`SELECT * FROM "leave_requests"`,
+				[]string{"id", "status", "employee_id", "leave_type_id", "start_date", "days"},
+				[]driver.Value{requestID.String(), tt.status, uuid.NewString(), uuid.NewString(),
+					time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC), 2.0})
+			if !tt.duplicate {
+				rec.respond(`INSERT INTO "leave_request_approvals"`, []string{"id"}, []driver.Value{uuid.NewString()})
+			}
+			rec.respond(`SELECT count(*) FROM "leave_request_approvals"`, []string{"count"}, []driver.Value{tt.approvals})
+			rec.respond(`FROM "leave_types"`, []string{"tracks_balance", "allow_negative_balance"}, []driver.Value{true, false})
+
+			request := &domain.LeaveRequest{Base: domain.Base{ID: requestID}, Status: domain.LeaveStatusPending}
+			history := &domain.LeaveRequestHistory{LeaveRequestID: requestID, Action: domain.LeaveActionApprove, Status: domain.LeaveStatusApproved}
+			count, err := repo.RecordQuorumApproval(request,
+				&domain.LeaveRequestApproval{LeaveRequestID: requestID, ApproverID: approverID}, 2, history)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			// The request is locked before the approval is recorded
+			rec.mu.Lock()
+			first := rec.queries[0]
+			rec.mu.Unlock()
+			if !strings.Contains(first.SQL, `FROM "leave_requests" WHERE id = $1`) || !strings.HasSuffix(first.SQL, "FOR UPDATE") {
+				t.Errorf("first statement does not lock the request: %s", first.SQL)
+			}
+
+			inserted := rec.find(`INSERT INTO "leave_request_approvals"`)
+			if tt.status != domain.LeaveStatusPending {
+				if len(inserted) != 0 {
+					t.Error("an approval was recorded on a decided request")
+				}
+			} else if len(inserted) != 1 || !strings.Contains(inserted[0].SQL, "ON CONFLICT DO NOTHING") {
+				t.Errorf("approval insert = %+v, want one yielding to the approver's earlier one", inserted)
+			}
+
+			if tt.wantErr != nil {
+				if len(rec.find(`INSERT INTO "leave_request_history"`)) != 0 || len(rec.find(`UPDATE "leave_requests"`)) != 0 {
+					t.Error("a refused approval changed the request")
+				}
+				return
+			}
+			if count != int(tt.approvals) {
+				t.Errorf("count = %d, want %d", count, tt.approvals)
+			}
+
+			histories := rec.find(`INSERT INTO "leave_request_history"`)
+			if len(histories) != 1 || !hasArg(histories[0], tt.wantAction) {
+				t.Errorf("history = %+v, want one %s entry", histories, tt.wantAction)
+			}
+
+			transitions := rec.find(`UPDATE "leave_requests"`)
+			balances := rec.find(`UPDATE "leave_balances"`)
+			if !tt.wantApproved {
+				if len(transitions) != 0 || len(balances) != 0 || request.Status != domain.LeaveStatusPending {
+					t.Errorf("a partial approval moved the request: %+v", transitions)
+				}
+				return
+			}
+			if len(transitions) != 1 || !hasArg(transitions[0], domain.LeaveStatusApproved) || !hasArg(transitions[0], request.ApprovedBy) {
+				t.Errorf("transitions = %+v, want a single one to approved by the approver", transitions)
+			}
+			if len(balances) != 1 || !strings.Contains(balances[0].SQL, `"used_days"=used_days + $`) {
+				t.Errorf("balance updates = %+v, want the days moved from pending to used", balances)
+			}
+			if request.Status != domain.LeaveStatusApproved || request.ApprovedBy == nil || *request.ApprovedBy != approverID {
+				t.Errorf("request = %+v, want approved by the approver", request)
+			}
+		})
+	}
+}
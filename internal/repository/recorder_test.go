@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordedQuery is a statement the repository sent to the database
+type recordedQuery struct {
+	SQL  string
+	Args []driver.NamedValue
+}
+
+// recordedResult is what the recorder answers a query with
+type recordedResult struct {
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+// queryRecorder stands in for Postgres: it records every statement and
+// answers queries containing a registered fragment with canned rows, and
+// everything else with no rows
+type queryRecorder struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+	results map[string]recordedResult
+}
+
+// newRecordingRepository returns a repository backed by a queryRecorder
+func newRecordingRepository(t *testing.T) (*leaveRepository, *queryRecorder) {
+	t.Helper()
+	rec := &queryRecorder{results: make(map[string]recordedResult)}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(rec)}), &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("opening recorder: %v", err)
+	}
+	return &leaveRepository{db: db}, rec
+}
+
+// respond answers queries containing fragment with the columns and rows
+func (q *queryRecorder) respond(fragment string, columns []string, rows ...[]driver.Value) {
+	q.results[fragment] = recordedResult{Columns: columns, Rows: rows}
+}
+
+// find returns the recorded statements containing fragment
+func (q *queryRecorder) find(fragment string) []recordedQuery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var found []recordedQuery
+	for _, query := range q.queries {
+		if strings.Contains(query.SQL, fragment) {
+			found = append(found, query)
+		}
+	}
+	return found
+}
+
+// last returns the last statement recorded, failing the test without one
+func (q *queryRecorder) last(t *testing.T) recordedQuery {
+	t.Helper()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.queries) == 0 {
+		t.Fatal("no statement was sent")
+	}
+	return q.queries[len(q.queries)-1]
+}
+
+func (q *queryRecorder) record(query string, args []driver.NamedValue) recordedResult {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queries = append(q.queries, recordedQuery{SQL: query, Args: args})
+	for fragment, result := range q.results {
+		if strings.Contains(query, fragment) {
+			return result
+		}
+	}
+	return recordedResult{}
+}
+
+func (q *queryRecorder) Connect(context.Context) (driver.Conn, error) { return &recorderConn{q}, nil }
+func (q *queryRecorder) Driver() driver.Driver                        { return recorderDriver{q} }
+
+type recorderDriver struct{ q *queryRecorder }
+
+func (d recorderDriver) Open(string) (driver.Conn, error) { return &recorderConn{d.q}, nil }
+
+type recorderConn struct{ q *queryRecorder }
+
+func (c *recorderConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recorderConn) Close() error                        { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error)           { return recorderTx{}, nil }
+
+func (c *recorderConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return recorderTx{}, nil
+}
+
+func (c *recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.q.record(query, args)
+	return &recorderRows{result: result}, nil
+}
+
+func (c *recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.q.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+// CheckNamedValue accepts every argument as is; the recorder never
+// encodes them
+func (c *recorderConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type recorderTx struct{}
+
+func (recorderTx) Commit() error   { return nil }
+func (recorderTx) Rollback() error { return nil }
+
+type recorderRows struct {
+	result recordedResult
+	next   int
+}
+
+func (r *recorderRows) Columns() []string { return r.result.Columns }
+func (r *recorderRows) Close() error      { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.Rows[r.next])
+	r.next++
+	return nil
+}
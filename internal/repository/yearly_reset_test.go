@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// hasArg reports whether one of the statement's arguments equals value
+func hasArg(query recordedQuery, value interface{}) bool {
+	for _, arg := range query.Args {
+		if arg.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAdoptProvisionalBalance(t *testing.T) {
+	tests := []struct {
+		name        string
+		provisional bool
+		wantAdopted bool
+	}{
+		{name: "provisional", provisional: true, wantAdopted: true},
+		{name: "regular", provisional: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			balanceID := uuid.New()
+			rec.respond(`SELECT * FROM "leave_balances"`, []string{"id", "total_days", "provisional"},
+				[]driver.Value{balanceID.String(), 4.0, tt.provisional})
+			rec.respond(`FROM "leave_balance_adjustments"`, []string{"coalesce"}, []driver.Value{1.5})
+
+			opening := 20.0
+			adopted, err := adoptProvisionalBalance(repo.db, &domain.LeaveBalance{
+				EmployeeID:      uuid.New(),
+				LeaveTypeID:     uuid.New(),
+				Year:            2027,
+				TotalDays:       23,
+				CarriedOverDays: 3,
+				OpeningDays:     &opening,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if adopted != tt.wantAdopted {
+				t.Fatalf("adopted = %v, want %v", adopted, tt.wantAdopted)
+			}
+
+			if lock := rec.find(`SELECT * FROM "leave_balances"`); len(lock) != 1 || !strings.Contains(lock[0].SQL, "FOR UPDATE") {
+				t.Errorf("the existing balance was not locked: %+v", lock)
+			}
+			updates := rec.find(`UPDATE "leave_balances"`)
+			if !tt.wantAdopted {
+				if len(updates) != 0 {
+					t.Errorf("a regular balance was rewritten: %s", updates[0].SQL)
+				}
+				return
+			}
+			if len(updates) != 1 {
+				t.Fatalf("got %d balance updates, want 1", len(updates))
+			}
+			// The recomputed opening keeps the approved adjustments
+			if !hasArg(updates[0], 24.5) || !hasArg(updates[0], false) || !strings.Contains(updates[0].SQL, `"provisional"=`) {
+				t.Errorf("update does not set total 24.5 and clear provisional: %s %+v", updates[0].SQL, updates[0].Args)
+			}
+			if adjustments := rec.find(`FROM "leave_balance_adjustments"`); len(adjustments) != 1 ||
+				!hasArg(adjustments[0], domain.AdjustmentStatusApproved) {
+				t.Errorf("only approved adjustments should be summed: %+v", adjustments)
+			}
+		})
+	}
+}
+
+func TestInitializeYearlyBalanceSnapshotsOpening(t *testing.T) {
+	repo, rec := newRecordingRepository(t)
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	runID := uuid.New()
+	leaveType := domain.LeaveType{ID: uuid.New(), OrganizationID: orgID, DefaultDays: 20}
+	balanceID := uuid.New()
+
+	rec.respond(`INSERT INTO "leave_balances"`, []string{"id"}, []driver.Value{balanceID.String()})
+	// The lock on last year's balance reads the same row; it is closed, so
+	// nothing is carried
+	rec.respond(`SELECT * FROM "leave_balances" WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3 LIMIT`,
+		[]string{"id", "organization_id", "employee_id", "leave_type_id", "year",
+			"total_days", "used_days", "pending_days", "remaining_days", "carried_over_days", "closed_at"},
+		[]driver.Value{balanceID.String(), orgID.String(), employeeID.String(), leaveType.ID.String(), int64(2027),
+			20.0, 0.0, 1.5, 18.5, 0.0, time.Now()})
+	rec.respond(`INSERT INTO "balance_snapshots"`, []string{"id"}, []driver.Value{uuid.NewString()})
+
+	counts, err := repo.InitializeYearlyBalance(orgID, employeeID, runID, []domain.LeaveType{leaveType}, 2027, 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts.Created != 1 {
+		t.Errorf("created %d balances, want 1", counts.Created)
+	}
+
+	inserts := rec.find(`INSERT INTO "balance_snapshots"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d snapshot inserts, want 1", len(inserts))
+	}
+	insert := inserts[0]
+	if !strings.Contains(insert.SQL, "ON CONFLICT") || !strings.Contains(insert.SQL, "DO NOTHING") {
+		t.Errorf("a rerun of the same run would snapshot twice: %s", insert.SQL)
+	}
+	for _, want := range []interface{}{runID, balanceID, 2027, 20.0, 1.5, 18.5} {
+		if !hasArg(insert, want) {
+			t.Errorf("snapshot does not record %v: %+v", want, insert.Args)
+		}
+	}
+}
+
+func TestExpireCarryOverForfeitsUnusedDays(t *testing.T) {
+	today := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+	endOfMarch := time.Date(2027, time.March, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		expiresOn     time.Time
+		usedDays      float64
+		expiredAt     interface{}
+		wantForfeited float64
+		wantExpired   bool
+	}{
+		{name: "unused", expiresOn: endOfMarch, wantForfeited: 5, wantExpired: true},
+		{name: "partly used", expiresOn: endOfMarch, usedDays: 3.5, wantForfeited: 1.5, wantExpired: true},
+		{name: "used up", expiresOn: endOfMarch, usedDays: 7, wantExpired: true},
+		{name: "last usable day", expiresOn: today},
+		{name: "expired by another run", expiresOn: endOfMarch, expiredAt: endOfMarch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, rec := newRecordingRepository(t)
+			balanceID := uuid.New()
+			rec.respond(`SELECT * FROM "leave_balances"`,
+				[]string{"id", "total_days", "used_days", "pending_days", "carried_over_days", "carried_over_expires_on", "carried_over_expired_at"},
+				[]driver.Value{balanceID.String(), 25.0, tt.usedDays, 0.0, 5.0, tt.expiresOn, tt.expiredAt})
+			rec.respond(`INSERT INTO "leave_balance_adjustments"`, []string{"id"}, []driver.Value{uuid.New().String()})
+
+			adjustment, err := repo.ExpireCarryOver(balanceID, today)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			locks := rec.find(`SELECT * FROM "leave_balances"`)
+			if len(locks) != 1 || !strings.HasSuffix(locks[0].SQL, "FOR UPDATE") {
+				t.Errorf("the balance was not locked: %+v", locks)
+			}
+
+			if tt.wantForfeited == 0 {
+				if adjustment != nil {
+					t.Errorf("forfeited %v, want nothing", adjustment.Adjustment)
+				}
+				if len(rec.find(`INSERT INTO "leave_balance_adjustments"`)) != 0 || len(rec.find(`"total_days"=`)) != 0 {
+					t.Error("the balance total was changed")
+				}
+			} else {
+				if adjustment == nil || adjustment.Adjustment != -tt.wantForfeited ||
+					adjustment.PerformedBy != domain.SystemUserID || adjustment.Status != domain.AdjustmentStatusApproved {
+					t.Fatalf("adjustment = %+v, want %v forfeited by the system", adjustment, -tt.wantForfeited)
+				}
+				total := rec.find(`"total_days"=total_days + $`)
+				if len(total) != 1 || !hasArg(total[0], -tt.wantForfeited) {
+					t.Errorf("balance total was not moved by %v: %+v", -tt.wantForfeited, total)
+				}
+			}
+
+			// Expired balances are marked so a rerun forfeits nothing more
+			expired := rec.find(`"carried_over_expired_at"=`)
+			if tt.wantExpired != (len(expired) == 1) {
+				t.Errorf("marked expired %d times, want expired %v", len(expired), tt.wantExpired)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EvaluateAbsenceAlerts totals each employee's spells and days of
+// absence-monitored leave in the 12 months ending today, merging spells as
+// the Bradford report does. Employees newly over a threshold get an alert
+// and HR is notified; an employee whose alert is not cleared yet gets no
+// second one. Alerts of employees back under both thresholds are cleared,
+// so crossing again raises a new alert. Notifications that failed before
+// are retried.
+func (s *leaveService) EvaluateAbsenceAlerts(orgID uuid.UUID) (*domain.AbsenceAlertEvaluation, error) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluateAbsenceAlerts(settings, settings.Today())
+}
+
+// evaluateAbsenceAlerts evaluates the organization's thresholds over the
+// 12 months ending on asOf
+func (s *leaveService) evaluateAbsenceAlerts(settings *domain.OrganizationSettings, asOf time.Time) (*domain.AbsenceAlertEvaluation, error) {
+	orgID := settings.OrganizationID
+	evaluation := &domain.AbsenceAlertEvaluation{
+		OrganizationID: orgID,
+		AsOf:           asOf,
+		WindowStart:    domain.AbsenceAlertWindow(asOf),
+	}
+
+	var crossing []domain.AbsenceTotals
+	if settings.AbsenceAlertsEnabled() {
+		leaveTypeIDs, err := s.leaveRepo.ListAbsenceMonitoredLeaveTypeIDs(orgID)
+		if err != nil {
+			return nil, err
+		}
+		totals, err := s.absenceTotals(orgID, leaveTypeIDs, evaluation.WindowStart, asOf)
+		if err != nil {
+			return nil, err
+		}
+		evaluation.Employees = len(totals)
+		for _, t := range totals {
+			if settings.CrossesAbsenceThreshold(t) {
+				crossing = append(crossing, t)
+			}
+		}
+	}
+	over := make(map[uuid.UUID]bool, len(crossing))
+	for _, t := range crossing {
+		over[t.EmployeeID] = true
+	}
+
+	uncleared, err := s.leaveRepo.ListUnclearedAbsenceAlerts(orgID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	alerted := make(map[uuid.UUID]bool, len(uncleared))
+	var unnotified []domain.AbsenceAlert
+	for _, alert := range uncleared {
+		if !over[alert.EmployeeID] {
+			if err := s.leaveRepo.ClearAbsenceAlert(alert.ID, now); err != nil {
+				return nil, err
+			}
+			evaluation.Cleared++
+			continue
+		}
+		alerted[alert.EmployeeID] = true
+		if alert.NotifiedAt == nil && alert.Status != domain.AbsenceAlertStatusResolved {
+			unnotified = append(unnotified, alert)
+		}
+	}
+
+	for _, t := range crossing {
+		if alerted[t.EmployeeID] {
+			continue
+		}
+		alert := domain.AbsenceAlert{
+			OrganizationID:  orgID,
+			EmployeeID:      t.EmployeeID,
+			Status:          domain.AbsenceAlertStatusOpen,
+			WindowStart:     evaluation.WindowStart,
+			WindowEnd:       asOf,
+			Days:            t.Days,
+			Spells:          t.Spells,
+			ThresholdDays:   settings.AbsenceAlertDays,
+			ThresholdSpells: settings.AbsenceAlertSpells,
+		}
+		created, err := s.leaveRepo.CreateAbsenceAlert(&alert)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			// Raised by an evaluation running alongside this one
+			continue
+		}
+		evaluation.Raised++
+		log.Printf("Alert: absence threshold crossed in organization %s: employee %s has %d spells and %.2f days of absence since %s",
+			orgID, t.EmployeeID, t.Spells, t.Days, evaluation.WindowStart.Format("2006-01-02"))
+		unnotified = append(unnotified, alert)
+	}
+
+	if settings.AbsenceAlertWebhookURL == "" {
+		return evaluation, nil
+	}
+	for i := range unnotified {
+		alert := &unnotified[i]
+		err := s.webhooks.Post(settings.AbsenceAlertWebhookURL, domain.AbsenceAlertNotification{
+			Event: domain.AbsenceAlertRaisedEvent,
+			Alert: alert,
+		})
+		if err != nil {
+			log.Printf("Error: cannot notify HR of absence alert %s in organization %s: %v", alert.ID, orgID, err)
+			evaluation.NotifyFailed++
+			continue
+		}
+		if err := s.leaveRepo.MarkAbsenceAlertNotified(alert.ID, time.Now()); err != nil {
+			return nil, err
+		}
+		evaluation.Notified++
+	}
+	return evaluation, nil
+}
+
+// EvaluateAllAbsenceAlerts evaluates the absence thresholds of every
+// organization that set one or still has an alert to clear, for a nightly
+// scheduler to call. An organization that fails is logged and left for the
+// next run.
+func (s *leaveService) EvaluateAllAbsenceAlerts() ([]domain.AbsenceAlertEvaluation, error) {
+	orgIDs, err := s.leaveRepo.ListAbsenceAlertOrganizationIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	evaluations := make([]domain.AbsenceAlertEvaluation, 0, len(orgIDs))
+	for _, orgID := range orgIDs {
+		evaluation, err := s.EvaluateAbsenceAlerts(orgID)
+		if err != nil {
+			log.Printf("Error: cannot evaluate absence alerts of %s: %v", orgID, err)
+			continue
+		}
+		evaluations = append(evaluations, *evaluation)
+	}
+	return evaluations, nil
+}
+
+// ListAbsenceAlerts lists the organization's absence alerts, oldest first
+func (s *leaveService) ListAbsenceAlerts(orgID uuid.UUID, params *domain.ListAbsenceAlertsParams) ([]domain.AbsenceAlert, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+	switch params.Status {
+	case "", domain.AbsenceAlertStatusOpen, domain.AbsenceAlertStatusAcknowledged, domain.AbsenceAlertStatusResolved:
+	default:
+		return nil, 0, apperrors.NewBadRequestError("invalid status")
+	}
+	return s.leaveRepo.ListAbsenceAlerts(orgID, params)
+}
+
+// AcknowledgeAbsenceAlert marks an open alert as under review by HR
+func (s *leaveService) AcknowledgeAbsenceAlert(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error) {
+	alert, err := s.getAbsenceAlert(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.CanAcknowledge() {
+		return nil, apperrors.NewInvalidStatusError("only open absence alerts can be acknowledged")
+	}
+
+	now := time.Now()
+	alert.Status = domain.AbsenceAlertStatusAcknowledged
+	alert.AcknowledgedBy = &userID
+	alert.AcknowledgedAt = &now
+	if comments != "" {
+		alert.Comments = comments
+	}
+
+	if err := s.leaveRepo.AcknowledgeAbsenceAlert(alert); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only open absence alerts can be acknowledged")
+		}
+		return nil, err
+	}
+	return alert, nil
+}
+
+// ResolveAbsenceAlert closes HR's review of an open or acknowledged alert.
+// A resolved alert still keeps the employee from being alerted again until
+// they fall back under the thresholds.
+func (s *leaveService) ResolveAbsenceAlert(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error) {
+	alert, err := s.getAbsenceAlert(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.CanResolve() {
+		return nil, apperrors.NewInvalidStatusError("only open or acknowledged absence alerts can be resolved")
+	}
+
+	now := time.Now()
+	alert.Status = domain.AbsenceAlertStatusResolved
+	alert.ResolvedBy = &userID
+	alert.ResolvedAt = &now
+	if comments != "" {
+		alert.Comments = comments
+	}
+
+	if err := s.leaveRepo.ResolveAbsenceAlert(alert); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only open or acknowledged absence alerts can be resolved")
+		}
+		return nil, err
+	}
+	return alert, nil
+}
+
+func (s *leaveService) getAbsenceAlert(orgID, id uuid.UUID) (*domain.AbsenceAlert, error) {
+	alert, err := s.leaveRepo.GetAbsenceAlert(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && alert.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("absence alert not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
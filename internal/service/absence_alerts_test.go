@@ -0,0 +1,274 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// fakeWebhook records the payloads posted to it, failing with err when set
+type fakeWebhook struct {
+	urls     []string
+	payloads []interface{}
+	err      error
+}
+
+func (w *fakeWebhook) Post(url string, payload interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.urls = append(w.urls, url)
+	w.payloads = append(w.payloads, payload)
+	return nil
+}
+
+// newAbsenceAlertService returns a service alerting at 3 spells of
+// monitored leave, notifying HR through the returned webhook
+func newAbsenceAlertService(orgID uuid.UUID) (*leaveService, *fakeRepository, *fakeWebhook) {
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	repo.settings.AbsenceAlertSpells = 3
+	repo.settings.AbsenceAlertWebhookURL = "https://hr.example.com/hooks/absence"
+	repo.addLeaveType(orgID, domain.RoundingNone).AbsenceMonitored = true
+
+	hook := &fakeWebhook{}
+	svc := newTestService(repo)
+	svc.webhooks = hook
+	return svc, repo, hook
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEvaluateAbsenceAlertsWindowEdges(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, hook := newAbsenceAlertService(orgID)
+
+	// As of Monday 2025-06-30 the window is 2024-07-01 to 2025-06-30. Each
+	// absence below is its own spell, a working day or more apart.
+	onFirstDay, beforeWindow, onLastDay := uuid.New(), uuid.New(), uuid.New()
+	spell := func(id uuid.UUID, day time.Time) domain.Absence {
+		return domain.Absence{EmployeeID: id, StartDate: day, EndDate: day, Days: 1}
+	}
+	repo.absences = []domain.Absence{
+		spell(onFirstDay, date(2024, time.July, 1)),
+		spell(onFirstDay, date(2024, time.September, 3)),
+		spell(onFirstDay, date(2025, time.March, 4)),
+		spell(beforeWindow, date(2024, time.June, 28)),
+		spell(beforeWindow, date(2024, time.September, 3)),
+		spell(beforeWindow, date(2025, time.March, 4)),
+		spell(onLastDay, date(2024, time.November, 5)),
+		spell(onLastDay, date(2025, time.March, 4)),
+		spell(onLastDay, date(2025, time.June, 30)),
+	}
+
+	evaluation, err := svc.evaluateAbsenceAlerts(repo.settings, date(2025, time.June, 30))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := date(2024, time.July, 1); !evaluation.WindowStart.Equal(want) || !repo.absenceWindow[0].Equal(want) {
+		t.Errorf("window starts %v, listed from %v, want %v", evaluation.WindowStart, repo.absenceWindow[0], want)
+	}
+	if evaluation.Employees != 3 || evaluation.Raised != 2 || evaluation.Notified != 2 {
+		t.Errorf("evaluation = %+v, want 3 employees, 2 raised and notified", evaluation)
+	}
+	alerted := map[uuid.UUID]bool{}
+	for _, alert := range repo.absenceAlerts {
+		alerted[alert.EmployeeID] = true
+		if alert.Status != domain.AbsenceAlertStatusOpen || alert.Spells != 3 || alert.ThresholdSpells != 3 || alert.NotifiedAt == nil {
+			t.Errorf("alert = %+v, want an open, notified alert at 3 spells", alert)
+		}
+	}
+	if !alerted[onFirstDay] || !alerted[onLastDay] || alerted[beforeWindow] {
+		t.Errorf("alerted %v, want the employees with a spell on the window's first and last day only", alerted)
+	}
+	if len(hook.urls) != 2 || hook.urls[0] != repo.settings.AbsenceAlertWebhookURL {
+		t.Errorf("webhook posts to %v, want 2 to the organization's URL", hook.urls)
+	}
+
+	// A day later the spell on 2024-07-01 falls out of the window
+	evaluation, err = svc.evaluateAbsenceAlerts(repo.settings, date(2025, time.July, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evaluation.Raised != 0 || evaluation.Cleared != 1 || evaluation.Notified != 0 {
+		t.Errorf("evaluation = %+v, want onFirstDay's alert cleared and nothing raised", evaluation)
+	}
+	for _, alert := range repo.absenceAlerts {
+		if cleared := alert.ClearedAt != nil; cleared != (alert.EmployeeID == onFirstDay) {
+			t.Errorf("alert of %s cleared = %t", alert.EmployeeID, cleared)
+		}
+	}
+}
+
+func TestEvaluateAbsenceAlertsDoesNotRealertWhileCrossed(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, hook := newAbsenceAlertService(orgID)
+	repo.settings.AbsenceAlertSpells = 0
+	repo.settings.AbsenceAlertDays = 5
+
+	employee := uuid.New()
+	repo.absences = []domain.Absence{
+		{EmployeeID: employee, StartDate: date(2025, time.June, 2), EndDate: date(2025, time.June, 6), Days: 5},
+	}
+	asOf := date(2025, time.June, 30)
+	if _, err := svc.evaluateAbsenceAlerts(repo.settings, asOf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := repo.absenceAlerts[0].ID
+	if _, err := svc.ResolveAbsenceAlert(orgID, first, uuid.New(), "spoke to the employee"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Still over the threshold, even with more leave and the alert
+	// resolved: no second alert
+	repo.absences = append(repo.absences,
+		domain.Absence{EmployeeID: employee, StartDate: date(2025, time.June, 16), EndDate: date(2025, time.June, 16), Days: 1})
+	evaluation, err := svc.evaluateAbsenceAlerts(repo.settings, asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evaluation.Raised != 0 || evaluation.Cleared != 0 || len(repo.absenceAlerts) != 1 || len(hook.payloads) != 1 {
+		t.Fatalf("evaluation = %+v with %d alerts and %d notifications, want the one alert kept", evaluation, len(repo.absenceAlerts), len(hook.payloads))
+	}
+
+	// Back under the threshold clears it; crossing again raises a new one
+	repo.absences = nil
+	if evaluation, _ := svc.evaluateAbsenceAlerts(repo.settings, asOf); evaluation.Cleared != 1 {
+		t.Errorf("evaluation = %+v, want the alert cleared", evaluation)
+	}
+	repo.absences = []domain.Absence{
+		{EmployeeID: employee, StartDate: date(2025, time.June, 23), EndDate: date(2025, time.June, 27), Days: 5},
+	}
+	evaluation, err = svc.evaluateAbsenceAlerts(repo.settings, asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evaluation.Raised != 1 || len(repo.absenceAlerts) != 2 || repo.absenceAlerts[1].ID == first {
+		t.Errorf("evaluation = %+v with %d alerts, want a new alert", evaluation, len(repo.absenceAlerts))
+	}
+	notification := hook.payloads[len(hook.payloads)-1].(domain.AbsenceAlertNotification)
+	if notification.Event != domain.AbsenceAlertRaisedEvent || notification.Alert.EmployeeID != employee || notification.Alert.Days != 5 {
+		t.Errorf("notification = %+v", notification)
+	}
+}
+
+func TestEvaluateAbsenceAlertsRetriesFailedNotifications(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, hook := newAbsenceAlertService(orgID)
+	repo.settings.AbsenceAlertSpells = 1
+	repo.absences = []domain.Absence{
+		{EmployeeID: uuid.New(), StartDate: date(2025, time.June, 2), EndDate: date(2025, time.June, 2), Days: 1},
+	}
+	asOf := date(2025, time.June, 30)
+
+	hook.err = errors.New("webhook returned 503: unavailable")
+	evaluation, err := svc.evaluateAbsenceAlerts(repo.settings, asOf)
+	if err != nil {
+		t.Fatalf("a failed notification must not fail the evaluation: %v", err)
+	}
+	if evaluation.Raised != 1 || evaluation.NotifyFailed != 1 || repo.absenceAlerts[0].NotifiedAt != nil {
+		t.Fatalf("evaluation = %+v, want the alert raised and its notification pending", evaluation)
+	}
+
+	hook.err = nil
+	evaluation, err = svc.evaluateAbsenceAlerts(repo.settings, asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evaluation.Raised != 0 || evaluation.Notified != 1 || repo.absenceAlerts[0].NotifiedAt == nil {
+		t.Errorf("evaluation = %+v, want the pending notification delivered", evaluation)
+	}
+}
+
+func TestEvaluateAbsenceAlertsCountsMonitoredTypesOnly(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, _ := newAbsenceAlertService(orgID)
+	for _, lt := range repo.leaveTypes {
+		lt.AbsenceMonitored = false
+	}
+	repo.settings.AbsenceAlertSpells = 1
+	repo.absences = []domain.Absence{
+		{EmployeeID: uuid.New(), StartDate: date(2025, time.June, 2), EndDate: date(2025, time.June, 2), Days: 1},
+	}
+
+	evaluation, err := svc.evaluateAbsenceAlerts(repo.settings, date(2025, time.June, 30))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if evaluation.Raised != 0 || len(repo.absenceAlerts) != 0 {
+		t.Errorf("evaluation = %+v, want no alert without a monitored leave type", evaluation)
+	}
+}
+
+func TestAbsenceAlertActions(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, _ := newAbsenceAlertService(orgID)
+	alert := &domain.AbsenceAlert{OrganizationID: orgID, EmployeeID: uuid.New(), Status: domain.AbsenceAlertStatusOpen}
+	if _, err := repo.CreateAbsenceAlert(alert); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hr := uuid.New()
+
+	if _, err := svc.AcknowledgeAbsenceAlert(uuid.New(), alert.ID, hr, ""); httpStatus(err) != 404 {
+		t.Errorf("acknowledging another organization's alert: status %d, want 404", httpStatus(err))
+	}
+	acknowledged, err := svc.AcknowledgeAbsenceAlert(orgID, alert.ID, hr, "reviewing")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acknowledged.Status != domain.AbsenceAlertStatusAcknowledged || *acknowledged.AcknowledgedBy != hr || acknowledged.Comments != "reviewing" {
+		t.Errorf("acknowledged = %+v", acknowledged)
+	}
+	if _, err := svc.AcknowledgeAbsenceAlert(orgID, alert.ID, hr, ""); httpStatus(err) != 409 {
+		t.Errorf("acknowledging twice: status %d, want 409", httpStatus(err))
+	}
+
+	resolved, err := svc.ResolveAbsenceAlert(orgID, alert.ID, hr, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Status != domain.AbsenceAlertStatusResolved || *resolved.ResolvedBy != hr || resolved.Comments != "reviewing" {
+		t.Errorf("resolved = %+v", resolved)
+	}
+	if _, err := svc.ResolveAbsenceAlert(orgID, alert.ID, hr, ""); httpStatus(err) != 409 {
+		t.Errorf("resolving twice: status %d, want 409", httpStatus(err))
+	}
+
+	if _, _, err := svc.ListAbsenceAlerts(orgID, &domain.ListAbsenceAlertsParams{Status: "closed"}); httpStatus(err) != 400 {
+		t.Errorf("listing an unknown status: status %d, want 400", httpStatus(err))
+	}
+}
+
+func TestUpdateOrganizationSettingsAbsenceAlerts(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	svc := newTestService(repo)
+
+	days, spells, hook := 8.0, 3, "https://hr.example.com/hooks/absence"
+	settings, err := svc.UpdateOrganizationSettings(orgID, &domain.UpdateOrganizationSettingsRequest{
+		AbsenceAlertDays:       &days,
+		AbsenceAlertSpells:     &spells,
+		AbsenceAlertWebhookURL: &hook,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.AbsenceAlertDays != 8 || settings.AbsenceAlertSpells != 3 || settings.AbsenceAlertWebhookURL != hook {
+		t.Errorf("settings = %+v", settings)
+	}
+
+	for _, bad := range []string{"hr.example.com/hook", "ftp://hr.example.com/hook", "https://"} {
+		if _, err := svc.UpdateOrganizationSettings(orgID, &domain.UpdateOrganizationSettingsRequest{AbsenceAlertWebhookURL: &bad}); httpStatus(err) != 400 {
+			t.Errorf("webhook URL %q: status %d, want 400", bad, httpStatus(err))
+		}
+	}
+	negative := -1
+	if _, err := svc.UpdateOrganizationSettings(orgID, &domain.UpdateOrganizationSettingsRequest{AbsenceAlertSpells: &negative}); httpStatus(err) != 400 {
+		t.Errorf("negative spells: status %d, want 400", httpStatus(err))
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// applyAbsenceLimit checks a new request against the organization's limit
+// on concurrent absences in the employee's department, counting the
+// colleagues' pending and approved leave. Over the limit, the request is
+// refused with a conflict listing the dates and who is off, or in warn mode
+// flagged with HasConflicts and saved. Employees without a department are
+// not checked; when the organization service is down the check follows its
+// severity.
+func (s *leaveService) applyAbsenceLimit(orgID uuid.UUID, token string, employee *organization.EmployeeResponse, leaveRequest *domain.LeaveRequest) error {
+	if len(leaveRequest.Breakdown) == 0 {
+		return nil
+	}
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return err
+	}
+	if settings.MaxConcurrentAbsences == 0 {
+		return nil
+	}
+	if employee == nil && s.employees != nil {
+		// The employee check was skipped, so the department is unknown
+		return s.skipAbsenceLimit(leaveRequest, errEmployeeSkipped)
+	}
+	if employee == nil || employee.DepartmentID == "" {
+		return nil
+	}
+
+	members, err := s.employees.ListDepartmentEmployees(token, orgID.String(), employee.DepartmentID)
+	if organizationDown(err) {
+		return s.skipAbsenceLimit(leaveRequest, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	var colleagues []uuid.UUID
+	for _, m := range members {
+		id, err := uuid.Parse(m.ID)
+		if err != nil || id == leaveRequest.EmployeeID {
+			continue
+		}
+		colleagues = append(colleagues, id)
+	}
+
+	first, last := leaveRequest.Breakdown[0].Date, leaveRequest.Breakdown[len(leaveRequest.Breakdown)-1].Date
+	days, err := s.leaveRepo.ListEmployeeLeaveRequestDays(orgID, colleagues, first, last,
+		[]string{domain.LeaveStatusPending, domain.LeaveStatusApproved, domain.LeaveStatusCancellationRequested})
+	if err != nil {
+		return err
+	}
+
+	conflicts := domain.FindAbsenceConflicts(leaveRequest.Breakdown, days, settings.MaxConcurrentAbsences)
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if settings.ConcurrentAbsenceMode == domain.AbsenceLimitModeWarn {
+		leaveRequest.HasConflicts = true
+		leaveRequest.AbsenceConflicts = conflicts
+		return nil
+	}
+
+	appErr := apperrors.NewConflictError("the department would exceed its limit on concurrent absences")
+	appErr.Details = conflicts
+	return appErr
+}
+
+// skipAbsenceLimit applies the absence limit's severity when cause kept it
+// from running, noting the skip on the request when it degrades
+func (s *leaveService) skipAbsenceLimit(leaveRequest *domain.LeaveRequest, cause error) error {
+	skipped, err := s.skipValidation(domain.ValidationCheckAbsenceLimit, leaveRequest.EmployeeID, cause)
+	if err != nil {
+		return err
+	}
+	leaveRequest.SkippedValidations = append(leaveRequest.SkippedValidations, skipped)
+	return nil
+}
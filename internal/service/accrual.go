@@ -0,0 +1,108 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// AccrueLeave grants the period's accruals to the organization's balances
+// of leave types that accrue, an empty period meaning the current month in
+// the organization's time zone. Quarterly types only accrue for the last
+// month of a quarter. Balances are opened by the yearly reset or a first
+// request; each accrues in its own transaction, and a period already
+// accrued for an employee and type is not granted again, so reruns are
+// safe and pick up failed balances. Accruals the bank cap cuts short are
+// logged.
+func (s *leaveService) AccrueLeave(orgID uuid.UUID, period string) (*domain.AccrualSummary, error) {
+	today, err := s.Today(orgID)
+	if err != nil {
+		return nil, err
+	}
+	current := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	month := current
+	if period != "" {
+		if month, err = domain.ParseAccrualPeriod(period); err != nil {
+			return nil, apperrors.NewBadRequestError(err.Error())
+		}
+		if month.After(current) {
+			return nil, apperrors.NewBadRequestError("cannot accrue a period that has not started")
+		}
+	}
+	period = month.Format(domain.AccrualPeriodLayout)
+
+	leaveTypes, err := s.leaveRepo.ListAccrualLeaveTypes(orgID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.AccrualSummary{OrganizationID: orgID, Period: period}
+	for i := range leaveTypes {
+		leaveType := &leaveTypes[i]
+		if !leaveType.AccruesIn(month.Month()) {
+			continue
+		}
+		summary.LeaveTypes++
+
+		ids, err := s.leaveRepo.ListLeaveTypeBalanceIDs(leaveType.ID, month.Year())
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			run, err := s.leaveRepo.AccrueBalance(id, leaveType, period, settings.HoursPerDay)
+			switch {
+			case err != nil:
+				log.Printf("Error: cannot accrue %s for balance %s: %v", period, id, err)
+				summary.Failed++
+				continue
+			case run == nil:
+				summary.AlreadyAccrued++
+				continue
+			case run.Days > 0:
+				summary.Accrued++
+				summary.AccruedDays += run.Days
+			case !run.Capped:
+				summary.Capped++
+			}
+			if run.Capped {
+				log.Printf("Accrual %s for balance %s capped at %g banked: %g credited, %g forfeited",
+					period, id, *leaveType.MaxBalanceDays, run.Days, run.ForfeitedDays)
+				summary.BankCapped++
+				summary.ForfeitedDays += run.ForfeitedDays
+			}
+		}
+	}
+
+	log.Printf("Accrual %s of %s finished: %d leave types, %d balances accrued, %d capped, %d at bank cap, %d already accrued, %d failed",
+		period, orgID, summary.LeaveTypes, summary.Accrued, summary.Capped, summary.BankCapped, summary.AlreadyAccrued, summary.Failed)
+	return summary, nil
+}
+
+// AccrueAllOrganizations runs the current month's accruals of every
+// organization with an accruing leave type, for a scheduler to call. An
+// organization that fails is logged and left for the next run.
+func (s *leaveService) AccrueAllOrganizations() ([]domain.AccrualSummary, error) {
+	orgIDs, err := s.leaveRepo.ListAccrualOrganizationIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]domain.AccrualSummary, 0, len(orgIDs))
+	for _, orgID := range orgIDs {
+		summary, err := s.AccrueLeave(orgID, "")
+		if err != nil {
+			log.Printf("Error: cannot run accruals of %s: %v", orgID, err)
+			continue
+		}
+		summaries = append(summaries, *summary)
+	}
+	return summaries, nil
+}
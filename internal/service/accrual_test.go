@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestAccrueLeaveSummarizesBankCap(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	capDays := 30.0
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	leaveType.AccrualFrequency = domain.AccrualFrequencyMonthly
+	leaveType.AccrualRate = 2
+	leaveType.MaxBalanceDays = &capDays
+
+	// Last year's March has always started
+	year := time.Now().Year() - 1
+	period := fmt.Sprintf("%d-03", year)
+
+	repo.accrualRuns = []*domain.AccrualRun{
+		{Days: 2},
+		{Days: 0.5, Capped: true, ForfeitedDays: 1.5},
+		{Capped: true, ForfeitedDays: 2},
+		{},
+		nil,
+	}
+	for range repo.accrualRuns {
+		repo.balances = append(repo.balances, domain.LeaveBalance{
+			Base:        domain.Base{ID: uuid.New()},
+			LeaveTypeID: leaveType.ID,
+			Year:        year,
+		})
+	}
+
+	summary, err := newTestService(repo).AccrueLeave(orgID, period)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := domain.AccrualSummary{
+		OrganizationID: orgID,
+		Period:         period,
+		LeaveTypes:     1,
+		Accrued:        2,
+		AccruedDays:    2.5,
+		Capped:         1,
+		AlreadyAccrued: 1,
+		BankCapped:     2,
+		ForfeitedDays:  3.5,
+	}
+	if *summary != want {
+		t.Errorf("summary = %+v, want %+v", *summary, want)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestCheckAdvanceBooking(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		months  int
+		start   time.Time
+		wantErr string
+	}{
+		{name: "today", months: 15, start: today},
+		{name: "last bookable day", months: 15, start: time.Date(2027, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{name: "time of day is ignored", months: 15, start: time.Date(2027, 6, 10, 18, 0, 0, 0, time.UTC)},
+		{name: "one day too far", months: 15, start: time.Date(2027, 6, 11, 0, 0, 0, 0, time.UTC), wantErr: "can be booked from 2026-03-11"},
+		{name: "shorter window", months: 3, start: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), wantErr: "up to 2026-06-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.settings.MaxAdvanceBookingMonths = tt.months
+
+			err := newTestService(repo).checkAdvanceBooking(orgID, tt.start, today)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if httpStatus(err) != 400 || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want a 400 mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestFutureYear(t *testing.T) {
+	thisYear := time.Now().UTC().Year()
+	nextYear := time.Date(thisYear+1, time.February, 1, 0, 0, 0, 0, time.UTC)
+	for nextYear.Weekday() != time.Monday {
+		nextYear = nextYear.AddDate(0, 0, 1)
+	}
+
+	tests := []struct {
+		name            string
+		start           time.Time
+		wantProvisional bool
+		wantStatus      int
+	}{
+		{name: "this year", start: upcomingMonday(), wantProvisional: upcomingMonday().Year() > thisYear},
+		{name: "next year", start: nextYear, wantProvisional: true},
+		{name: "beyond the booking window", start: upcomingMonday().AddDate(0, domain.DefaultMaxAdvanceBookingMonths, 0), wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+
+			_, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  uuid.New(),
+				LeaveTypeID: leaveType.ID,
+				StartDate:   tt.start,
+				EndDate:     tt.start.AddDate(0, 0, 1),
+				Reason:      "Summer holiday",
+			})
+			if status := httpStatus(err); status != tt.wantStatus {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if repo.created != nil {
+					t.Error("a request beyond the window was saved")
+				}
+				return
+			}
+			if repo.createdProvisional != tt.wantProvisional {
+				t.Errorf("provisional = %v, want %v", repo.createdProvisional, tt.wantProvisional)
+			}
+		})
+	}
+}
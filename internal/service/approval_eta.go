@@ -0,0 +1,127 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// decisionHistoryWindow is how far back decisions inform the estimate
+	decisionHistoryWindow = 90 * 24 * time.Hour
+	// decisionMediansTTL is how long an organization's medians are reused
+	decisionMediansTTL = 10 * time.Minute
+)
+
+// decisionMedians are median decision times in business hours
+type decisionMedians struct {
+	byApprover   map[uuid.UUID]float64
+	organization float64
+	// decided is false when nobody in the organization decided anything
+	// in the window
+	decided   bool
+	expiresAt time.Time
+}
+
+type decisionMediansCache struct {
+	mu   sync.Mutex
+	orgs map[uuid.UUID]*decisionMedians
+}
+
+// estimateDecision sets EstimatedDecisionBy on a request awaiting a
+// decision: its submission time plus the assigned approver's median
+// decision time over the last 90 days, or the organization's median when
+// the approver has no decisions in that window. Requests are left without
+// an estimate when the organization has no decisions either, or when the
+// estimate cannot be computed; it never fails the caller.
+func (s *leaveService) estimateDecision(leaveRequest *domain.LeaveRequest) {
+	leaveRequest.EstimatedDecisionBy = nil
+	if leaveRequest.Status != domain.LeaveStatusPending {
+		return
+	}
+
+	medians, err := s.decisionMedians(leaveRequest.OrganizationID)
+	if err != nil {
+		log.Printf("Warning: cannot estimate decision time for %s: %v", leaveRequest.ID, err)
+		return
+	}
+
+	hours, ok := 0.0, false
+	if leaveRequest.AssignedApproverID != nil {
+		hours, ok = medians.byApprover[*leaveRequest.AssignedApproverID]
+	}
+	if !ok {
+		if !medians.decided {
+			return
+		}
+		hours = medians.organization
+	}
+
+	// Enough room for the median to run over weekends and holidays
+	from := domain.TruncateToDate(leaveRequest.CreatedAt)
+	holidays, err := s.holidaysAt(leaveRequest.OrganizationID, "", from, from.AddDate(0, 0, int(hours/8)*2+14))
+	if err != nil {
+		log.Printf("Warning: cannot estimate decision time for %s: %v", leaveRequest.ID, err)
+		return
+	}
+
+	estimate := domain.AddBusinessHours(leaveRequest.CreatedAt, hours, holidays)
+	leaveRequest.EstimatedDecisionBy = &estimate
+}
+
+// decisionMedians returns the organization's median decision times,
+// recomputing them at most every few minutes
+func (s *leaveService) decisionMedians(orgID uuid.UUID) (*decisionMedians, error) {
+	now := time.Now()
+	s.medians.mu.Lock()
+	cached, ok := s.medians.orgs[orgID]
+	s.medians.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	from := now.Add(-decisionHistoryWindow)
+	decisions, err := s.leaveRepo.ListApproverDecisions(orgID, from, now, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests decided in the window may have been submitted before it
+	holidayFrom := from
+	for _, d := range decisions {
+		if d.RequestedAt.Before(holidayFrom) {
+			holidayFrom = d.RequestedAt
+		}
+	}
+	holidays, err := s.holidaysAt(orgID, "", domain.TruncateToDate(holidayFrom), domain.TruncateToDate(now))
+	if err != nil {
+		return nil, err
+	}
+
+	var all []float64
+	hours := make(map[uuid.UUID][]float64)
+	for _, d := range decisions {
+		h := domain.BusinessHoursBetween(d.RequestedAt, d.DecidedAt, holidays)
+		hours[d.ApproverID] = append(hours[d.ApproverID], h)
+		all = append(all, h)
+	}
+
+	medians := &decisionMedians{
+		byApprover:   make(map[uuid.UUID]float64, len(hours)),
+		organization: percentile(all, 50),
+		decided:      len(all) > 0,
+		expiresAt:    now.Add(decisionMediansTTL),
+	}
+	for id, h := range hours {
+		medians.byApprover[id] = percentile(h, 50)
+	}
+
+	s.medians.mu.Lock()
+	s.medians.orgs[orgID] = medians
+	s.medians.mu.Unlock()
+
+	return medians, nil
+}
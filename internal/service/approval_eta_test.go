@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestEstimateDecision(t *testing.T) {
+	// 2026-03-02 is a Monday
+	monday := func(hour int) time.Time {
+		return time.Date(2026, 3, 2, hour, 0, 0, 0, time.UTC)
+	}
+	fast := uuid.New()
+	slow := uuid.New()
+	newcomer := uuid.New()
+	decisions := []domain.ApproverDecision{
+		{ApproverID: fast, RequestedAt: monday(9), DecidedAt: monday(11)},
+		{ApproverID: fast, RequestedAt: monday(9), DecidedAt: monday(13)},
+		{ApproverID: fast, RequestedAt: monday(9), DecidedAt: monday(15)},
+		{ApproverID: slow, RequestedAt: monday(9), DecidedAt: monday(17)},
+	}
+
+	tests := []struct {
+		name      string
+		decisions []domain.ApproverDecision
+		status    string
+		createdAt time.Time
+		approver  *uuid.UUID
+		want      *time.Time
+	}{
+		{name: "approver median", decisions: decisions, createdAt: monday(9), approver: &fast, want: ptrTime(monday(13))},
+		{name: "organization median for a new approver", decisions: decisions, createdAt: monday(9), approver: &newcomer, want: ptrTime(monday(14))},
+		{name: "organization median without an assignee", decisions: decisions, createdAt: monday(9), want: ptrTime(monday(14))},
+		{name: "over the weekend", decisions: decisions, createdAt: monday(15).AddDate(0, 0, 4), approver: &fast, want: ptrTime(monday(11).AddDate(0, 0, 7))},
+		{name: "no decisions at all", createdAt: monday(9), approver: &fast},
+		{name: "already decided", decisions: decisions, status: domain.LeaveStatusApproved, createdAt: monday(9), approver: &fast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.decisions = tt.decisions
+			status := tt.status
+			if status == "" {
+				status = domain.LeaveStatusPending
+			}
+			request := &domain.LeaveRequest{
+				Base:               domain.Base{ID: uuid.New(), CreatedAt: tt.createdAt},
+				OrganizationID:     uuid.New(),
+				Status:             status,
+				AssignedApproverID: tt.approver,
+			}
+
+			newTestService(repo).estimateDecision(request)
+
+			got := request.EstimatedDecisionBy
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecisionMediansAreCached(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	svc := newTestService(repo)
+
+	first, err := svc.decisionMedians(orgID)
+	if err != nil {
+		t.Fatalf("decision medians: %v", err)
+	}
+	if first.decided {
+		t.Fatal("an organization without decisions has medians")
+	}
+
+	// A new decision is not picked up until the cache expires
+	now := time.Now()
+	repo.decisions = []domain.ApproverDecision{{ApproverID: uuid.New(), RequestedAt: now.Add(-time.Hour), DecidedAt: now}}
+	if again, _ := svc.decisionMedians(orgID); again != first {
+		t.Error("medians were recomputed within the cache lifetime")
+	}
+
+	first.expiresAt = now.Add(-time.Second)
+	if refreshed, _ := svc.decisionMedians(orgID); refreshed == first || !refreshed.decided {
+		t.Error("expired medians were not recomputed")
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
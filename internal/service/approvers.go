@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApproverResolver decides who may approve requests of a leave type
+type ApproverResolver interface {
+	// Assign returns the approver a new request is routed to, or nil when
+	// any user the resolver authorizes may act on it
+	Assign(request *domain.LeaveRequest) (*uuid.UUID, error)
+	// CanApprove reports whether the user may approve or reject the request
+	CanApprove(request *domain.LeaveRequest, userID uuid.UUID, role string) bool
+}
+
+// DirectManager routes requests to the employee's manager. The service has
+// no reporting-line data, so any manager in the organization may act.
+type DirectManager struct{}
+
+func (DirectManager) Assign(request *domain.LeaveRequest) (*uuid.UUID, error) {
+	return nil, nil
+}
+
+func (DirectManager) CanApprove(request *domain.LeaveRequest, userID uuid.UUID, role string) bool {
+	return role == domain.RoleManager && request.EmployeeID != userID
+}
+
+// RoleBased lets any user holding Role approve
+type RoleBased struct {
+	Role string
+}
+
+func (r RoleBased) Assign(request *domain.LeaveRequest) (*uuid.UUID, error) {
+	return nil, nil
+}
+
+func (r RoleBased) CanApprove(request *domain.LeaveRequest, userID uuid.UUID, role string) bool {
+	return role == r.Role && request.EmployeeID != userID
+}
+
+// RoundRobinPool assigns each new request to the next member of an approver pool
+type RoundRobinPool struct {
+	PoolID uuid.UUID
+	repo   repository.LeaveRepository
+}
+
+func (r RoundRobinPool) Assign(request *domain.LeaveRequest) (*uuid.UUID, error) {
+	approverID, err := r.repo.NextPoolApprover(r.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	return &approverID, nil
+}
+
+func (r RoundRobinPool) CanApprove(request *domain.LeaveRequest, userID uuid.UUID, role string) bool {
+	return request.AssignedApproverID != nil && *request.AssignedApproverID == userID
+}
+
+// Quorum lets any candidate approve, the members of an approver pool and
+// the holders of a role, until the leave type's quorum is met. Candidates
+// who already approved a pending request can no longer act on it.
+type Quorum struct {
+	PoolID *uuid.UUID
+	Role   string
+	repo   repository.LeaveRepository
+}
+
+func (q Quorum) Assign(request *domain.LeaveRequest) (*uuid.UUID, error) {
+	return nil, nil
+}
+
+func (q Quorum) CanApprove(request *domain.LeaveRequest, userID uuid.UUID, role string) bool {
+	if request.EmployeeID == userID {
+		return false
+	}
+	if request.Status == domain.LeaveStatusPending {
+		for _, a := range request.Approvals {
+			if a.ApproverID == userID {
+				return false
+			}
+		}
+	}
+	if q.Role != "" && role == q.Role {
+		return true
+	}
+	if q.PoolID == nil {
+		return false
+	}
+	member, err := q.repo.IsApproverPoolMember(*q.PoolID, userID)
+	if err != nil {
+		log.Printf("Warning: cannot check approver pool membership of %s: %v", userID, err)
+		return false
+	}
+	return member
+}
+
+// approverResolver returns the resolver configured on the leave type
+func (s *leaveService) approverResolver(leaveType *domain.LeaveType) ApproverResolver {
+	switch leaveType.ApprovalStrategy {
+	case domain.ApprovalStrategyRoleBased:
+		return RoleBased{Role: leaveType.ApproverRole}
+	case domain.ApprovalStrategyRoundRobin:
+		if leaveType.ApproverPoolID != nil {
+			return RoundRobinPool{PoolID: *leaveType.ApproverPoolID, repo: s.leaveRepo}
+		}
+	case domain.ApprovalStrategyQuorum:
+		return Quorum{PoolID: leaveType.ApproverPoolID, Role: leaveType.ApproverRole, repo: s.leaveRepo}
+	}
+	return DirectManager{}
+}
+
+// canApprove lets admins act on any request and defers everyone else to
+// the leave type's resolver, for themselves or for the approvers whose
+// approvals are delegated to them today
+func (s *leaveService) canApprove(request *domain.LeaveRequest, userID uuid.UUID, role string) bool {
+	if s.canApproveDirectly(request, userID, role) {
+		return true
+	}
+	return s.canApproveFor(request, userID, s.delegatorsOf(request.OrganizationID, userID))
+}
+
+// canApproveFor reports whether the delegate may act on the request for
+// any of the delegators. Approvers are approver pool members, so only the
+// pool strategies honour delegations.
+func (s *leaveService) canApproveFor(request *domain.LeaveRequest, delegateID uuid.UUID, delegators []uuid.UUID) bool {
+	if request.EmployeeID == delegateID {
+		return false
+	}
+	for _, delegatorID := range delegators {
+		if s.canApproveDirectly(request, delegatorID, "") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *leaveService) canApproveDirectly(request *domain.LeaveRequest, userID uuid.UUID, role string) bool {
+	if role == domain.RoleAdmin {
+		return true
+	}
+	if request.LeaveType == nil {
+		return DirectManager{}.CanApprove(request, userID, role)
+	}
+	return s.approverResolver(request.LeaveType).CanApprove(request, userID, role)
+}
+
+// validateApprovalStrategy checks the strategy settings of a leave type
+// against the organization's approver pools
+func (s *leaveService) validateApprovalStrategy(leaveType *domain.LeaveType) error {
+	if leaveType.QuorumSize == 0 {
+		leaveType.QuorumSize = 1
+	}
+
+	switch leaveType.ApprovalStrategy {
+	case "":
+		leaveType.ApprovalStrategy = domain.ApprovalStrategyDirectManager
+	case domain.ApprovalStrategyDirectManager:
+	case domain.ApprovalStrategyRoleBased:
+		if strings.TrimSpace(leaveType.ApproverRole) == "" {
+			return apperrors.NewBadRequestError("approver_role is required for the role_based strategy")
+		}
+	case domain.ApprovalStrategyRoundRobin:
+		if leaveType.ApproverPoolID == nil {
+			return apperrors.NewBadRequestError("approver_pool_id is required for the round_robin strategy")
+		}
+		if _, err := s.GetApproverPool(leaveType.OrganizationID, *leaveType.ApproverPoolID); err != nil {
+			return err
+		}
+	case domain.ApprovalStrategyQuorum:
+		if leaveType.ApproverPoolID == nil && strings.TrimSpace(leaveType.ApproverRole) == "" {
+			return apperrors.NewBadRequestError("approver_pool_id or approver_role is required for the quorum strategy")
+		}
+		if leaveType.QuorumSize < 1 {
+			return apperrors.NewBadRequestError("quorum_size must be at least 1")
+		}
+		if leaveType.ApproverPoolID != nil {
+			pool, err := s.GetApproverPool(leaveType.OrganizationID, *leaveType.ApproverPoolID)
+			if err != nil {
+				return err
+			}
+			// Holders of the role may be any number, so only a pool alone bounds the quorum
+			if leaveType.ApproverRole == "" && leaveType.QuorumSize > len(pool.Members) {
+				return apperrors.NewBadRequestError(fmt.Sprintf(
+					"quorum_size %d exceeds the %d members of the approver pool", leaveType.QuorumSize, len(pool.Members)))
+			}
+		}
+	default:
+		return apperrors.NewBadRequestError("approval_strategy must be one of direct_manager, role_based, round_robin or quorum")
+	}
+	return nil
+}
+
+// ListPendingApprovals returns the pending requests the user may act on
+func (s *leaveService) ListPendingApprovals(orgID, userID uuid.UUID, role string) ([]domain.LeaveRequest, error) {
+	pending, err := s.leaveRepo.ListPendingLeaveRequests(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	delegators := s.delegatorsOf(orgID, userID)
+	inbox := make([]domain.LeaveRequest, 0, len(pending))
+	for i := range pending {
+		if s.canApproveDirectly(&pending[i], userID, role) || s.canApproveFor(&pending[i], userID, delegators) {
+			inbox = append(inbox, pending[i])
+		}
+	}
+	return inbox, nil
+}
+
+// CreateApproverPool creates an empty approver pool for the organization
+func (s *leaveService) CreateApproverPool(pool *domain.ApproverPool) error {
+	if strings.TrimSpace(pool.Name) == "" {
+		return apperrors.NewBadRequestError("name is required")
+	}
+	return s.leaveRepo.CreateApproverPool(pool)
+}
+
+// GetApproverPool retrieves an approver pool of the organization with its members
+func (s *leaveService) GetApproverPool(orgID, id uuid.UUID) (*domain.ApproverPool, error) {
+	pool, err := s.leaveRepo.GetApproverPool(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFoundError("approver pool not found")
+		}
+		return nil, err
+	}
+
+	if pool.OrganizationID != orgID {
+		return nil, apperrors.NewNotFoundError("approver pool not found")
+	}
+
+	return pool, nil
+}
+
+// ListApproverPools lists the organization's approver pools
+func (s *leaveService) ListApproverPools(orgID uuid.UUID) ([]domain.ApproverPool, error) {
+	return s.leaveRepo.ListApproverPools(orgID)
+}
+
+// AddApproverPoolMember adds a user to the end of a pool's rotation
+func (s *leaveService) AddApproverPoolMember(orgID, poolID, userID uuid.UUID) (*domain.ApproverPoolMember, error) {
+	pool, err := s.GetApproverPool(orgID, poolID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range pool.Members {
+		if m.UserID == userID {
+			return nil, apperrors.NewConflictError("user is already a member of this approver pool")
+		}
+	}
+
+	member := &domain.ApproverPoolMember{
+		PoolID: pool.ID,
+		UserID: userID,
+	}
+	if err := s.leaveRepo.AddApproverPoolMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveApproverPoolMember takes a user out of a pool's rotation. Requests
+// already assigned to them stay assigned.
+func (s *leaveService) RemoveApproverPoolMember(orgID, poolID, userID uuid.UUID) error {
+	pool, err := s.GetApproverPool(orgID, poolID)
+	if err != nil {
+		return err
+	}
+
+	err = s.leaveRepo.RemoveApproverPoolMember(pool.ID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewNotFoundError("user is not a member of this approver pool")
+	}
+	return err
+}
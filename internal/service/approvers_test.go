@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestApproverResolversCanApprove(t *testing.T) {
+	employee := uuid.New()
+	assigned := uuid.New()
+	member := uuid.New()
+	other := uuid.New()
+	poolID := uuid.New()
+
+	repo := newFakeRepository()
+	repo.poolMembers[poolID] = []uuid.UUID{member, assigned}
+
+	pending := &domain.LeaveRequest{EmployeeID: employee, Status: domain.LeaveStatusPending, AssignedApproverID: &assigned}
+	approvedByMember := &domain.LeaveRequest{
+		EmployeeID: employee,
+		Status:     domain.LeaveStatusPending,
+		Approvals:  []domain.LeaveRequestApproval{{ApproverID: member}},
+	}
+
+	tests := []struct {
+		name     string
+		resolver ApproverResolver
+		request  *domain.LeaveRequest
+		userID   uuid.UUID
+		role     string
+		want     bool
+	}{
+		{name: "direct manager: manager", resolver: DirectManager{}, request: pending, userID: other, role: domain.RoleManager, want: true},
+		{name: "direct manager: own request", resolver: DirectManager{}, request: pending, userID: employee, role: domain.RoleManager, want: false},
+		{name: "direct manager: hr", resolver: DirectManager{}, request: pending, userID: other, role: domain.RoleHR, want: false},
+		{name: "role based: role holder", resolver: RoleBased{Role: domain.RoleHR}, request: pending, userID: other, role: domain.RoleHR, want: true},
+		{name: "role based: own request", resolver: RoleBased{Role: domain.RoleHR}, request: pending, userID: employee, role: domain.RoleHR, want: false},
+		{name: "role based: manager", resolver: RoleBased{Role: domain.RoleHR}, request: pending, userID: other, role: domain.RoleManager, want: false},
+		{name: "round robin: assigned approver", resolver: RoundRobinPool{PoolID: poolID, repo: repo}, request: pending, userID: assigned, want: true},
+		{name: "round robin: other pool member", resolver: RoundRobinPool{PoolID: poolID, repo: repo}, request: pending, userID: member, role: domain.RoleManager, want: false},
+		{name: "round robin: unassigned", resolver: RoundRobinPool{PoolID: poolID, repo: repo}, request: approvedByMember, userID: assigned, want: false},
+		{name: "quorum: pool member", resolver: Quorum{PoolID: &poolID, repo: repo}, request: pending, userID: member, want: true},
+		{name: "quorum: role holder", resolver: Quorum{Role: domain.RoleHR, repo: repo}, request: pending, userID: other, role: domain.RoleHR, want: true},
+		{name: "quorum: outsider", resolver: Quorum{PoolID: &poolID, Role: domain.RoleHR, repo: repo}, request: pending, userID: other, role: domain.RoleManager, want: false},
+		{name: "quorum: already approved", resolver: Quorum{PoolID: &poolID, repo: repo}, request: approvedByMember, userID: member, want: false},
+		{name: "quorum: own request", resolver: Quorum{PoolID: &poolID, Role: domain.RoleHR, repo: repo}, request: pending, userID: employee, role: domain.RoleHR, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resolver.CanApprove(tt.request, tt.userID, tt.role); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundRobinPoolAssign(t *testing.T) {
+	poolID := uuid.New()
+	members := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	repo := newFakeRepository()
+	repo.poolMembers[poolID] = members
+	resolver := RoundRobinPool{PoolID: poolID, repo: repo}
+
+	for i := 0; i < 2*len(members); i++ {
+		got, err := resolver.Assign(&domain.LeaveRequest{})
+		if err != nil {
+			t.Fatalf("assignment %d: %v", i, err)
+		}
+		if want := members[i%len(members)]; got == nil || *got != want {
+			t.Errorf("assignment %d went to %v, want %s", i, got, want)
+		}
+	}
+
+	empty := RoundRobinPool{PoolID: uuid.New(), repo: repo}
+	if _, err := empty.Assign(&domain.LeaveRequest{}); !errors.Is(err, repository.ErrEmptyApproverPool) {
+		t.Errorf("empty pool: got %v, want ErrEmptyApproverPool", err)
+	}
+}
+
+func TestApproverResolverForLeaveType(t *testing.T) {
+	poolID := uuid.New()
+	svc := newTestService(newFakeRepository())
+
+	tests := []struct {
+		leaveType domain.LeaveType
+		want      string
+	}{
+		{leaveType: domain.LeaveType{}, want: "service.DirectManager"},
+		{leaveType: domain.LeaveType{ApprovalStrategy: domain.ApprovalStrategyDirectManager}, want: "service.DirectManager"},
+		{leaveType: domain.LeaveType{ApprovalStrategy: domain.ApprovalStrategyRoleBased, ApproverRole: domain.RoleHR}, want: "service.RoleBased"},
+		{leaveType: domain.LeaveType{ApprovalStrategy: domain.ApprovalStrategyRoundRobin, ApproverPoolID: &poolID}, want: "service.RoundRobinPool"},
+		{leaveType: domain.LeaveType{ApprovalStrategy: domain.ApprovalStrategyRoundRobin}, want: "service.DirectManager"},
+		{leaveType: domain.LeaveType{ApprovalStrategy: domain.ApprovalStrategyQuorum, ApproverPoolID: &poolID}, want: "service.Quorum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.leaveType.ApprovalStrategy, func(t *testing.T) {
+			if got := typeName(svc.approverResolver(&tt.leaveType)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApproveLeaveRequestAuthorization(t *testing.T) {
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{name: "approver role", role: domain.RoleHR, want: 0},
+		{name: "admin", role: domain.RoleAdmin, want: 0},
+		{name: "manager", role: domain.RoleManager, want: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			leaveType.RequiresApproval = true
+			leaveType.ApprovalStrategy = domain.ApprovalStrategyRoleBased
+			leaveType.ApproverRole = domain.RoleHR
+			request := repo.addPendingRequest(leaveType, uuid.New())
+
+			_, err := newTestService(repo).ApproveLeaveRequest(orgID, request.ID, uuid.New(), tt.role, "")
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if tt.want == 0 && repo.transitioned != domain.LeaveStatusApproved {
+				t.Errorf("request moved to %q, want approved", repo.transitioned)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateBalanceAdjustment records a manual change to a balance of the
+// organization. It stays pending for approval unless an admin asks for it
+// to be approved at once, in which case it is applied to the balance, or
+// scheduled when it takes effect on a later day. While a balance anomaly
+// alert holds the organization's adjustments, they all stay pending.
+// Additions that would bank more than the leave type's cap need an admin
+// to override it.
+func (s *leaveService) CreateBalanceAdjustment(orgID uuid.UUID, req *domain.CreateBalanceAdjustmentRequest) (*domain.LeaveBalanceAdjustment, error) {
+	if req.AutoApprove && req.CallerRole != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can approve their own balance adjustments")
+	}
+	if req.OverrideCap && req.CallerRole != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can override the balance cap")
+	}
+
+	balance, err := s.leaveRepo.GetLeaveBalanceByID(req.LeaveBalanceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && balance.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("leave balance not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if balance.LeaveType != nil && !balance.LeaveType.TracksBalance {
+		return nil, apperrors.NewBadRequestError(balance.LeaveType.Name + " does not track a balance")
+	}
+
+	adjustment := &domain.LeaveBalanceAdjustment{
+		LeaveBalanceID: balance.ID,
+		Adjustment:     req.Adjustment,
+		Reason:         req.Reason,
+		Comments:       req.Comments,
+		PerformedBy:    req.PerformedBy,
+		Status:         domain.AdjustmentStatusPending,
+	}
+	if banked := balance.TotalDays - balance.UsedDays + req.Adjustment; req.Adjustment > 0 && balance.LeaveType != nil &&
+		balance.LeaveType.MaxBalanceDays != nil && banked > *balance.LeaveType.MaxBalanceDays {
+		if !req.OverrideCap {
+			return nil, apperrors.NewUnprocessableError(fmt.Sprintf(
+				"adjustment would bank %g, above the %s cap of %g; an admin must set override_cap",
+				banked, balance.LeaveType.Name, *balance.LeaveType.MaxBalanceDays))
+		}
+		adjustment.CapOverridden = true
+	}
+	if req.EffectiveDate != nil {
+		effective := domain.TruncateToDate(*req.EffectiveDate)
+		if effective.Year() != balance.Year {
+			return nil, apperrors.NewBadRequestError(fmt.Sprintf("effective_date must fall in the balance's year %d", balance.Year))
+		}
+		adjustment.EffectiveDate = &effective
+	}
+
+	status, err := s.approvedAdjustmentStatus(orgID, adjustment)
+	if err != nil {
+		return nil, err
+	}
+	if req.AutoApprove && status == domain.AdjustmentStatusApproved {
+		s.recordBalanceMutation(orgID, req.Adjustment, domain.BalanceMutationAdjustment)
+	}
+	if req.AutoApprove && s.adjustmentsHeld(orgID) {
+		log.Printf("Balance adjustment to %s by %s left pending: balance anomaly alert active in organization %s",
+			balance.ID, req.PerformedBy, orgID)
+		adjustment.HeldForApproval = true
+	} else if req.AutoApprove {
+		now := time.Now()
+		adjustment.Status = status
+		adjustment.ApprovedBy = &req.PerformedBy
+		adjustment.ApprovedAt = &now
+	}
+
+	if err := s.leaveRepo.CreateBalanceAdjustment(adjustment); err != nil {
+		return nil, adjustmentBalanceError(err, balance)
+	}
+	return adjustment, nil
+}
+
+// ListOrgBalanceAdjustments lists the organization's adjustments for
+// review, by default the pending ones, oldest first
+func (s *leaveService) ListOrgBalanceAdjustments(orgID uuid.UUID, params *domain.ListBalanceAdjustmentsParams) ([]domain.LeaveBalanceAdjustment, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+	switch params.Status {
+	case "":
+		params.Status = domain.AdjustmentStatusPending
+	case "all":
+		params.Status = ""
+	case domain.AdjustmentStatusPending, domain.AdjustmentStatusApproved, domain.AdjustmentStatusScheduled,
+		domain.AdjustmentStatusRejected, domain.AdjustmentStatusReverted, domain.AdjustmentStatusCancelled,
+		domain.AdjustmentStatusCorrection:
+	default:
+		return nil, 0, apperrors.NewBadRequestError("status must be pending, approved, rejected, reverted, correction or all")
+	}
+	if params.From != nil && params.To != nil && params.To.Before(*params.From) {
+		return nil, 0, apperrors.NewBadRequestError("to must not be before from")
+	}
+
+	return s.leaveRepo.ListOrgBalanceAdjustments(orgID, params)
+}
+
+// ApproveBalanceAdjustment approves a pending adjustment of the
+// organization and applies it to the balance
+func (s *leaveService) ApproveBalanceAdjustment(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	return s.decideBalanceAdjustment(orgID, id, approverID, role, domain.AdjustmentStatusApproved, comments)
+}
+
+// RejectBalanceAdjustment rejects a pending adjustment of the organization,
+// leaving the balance unchanged
+func (s *leaveService) RejectBalanceAdjustment(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	return s.decideBalanceAdjustment(orgID, id, rejectorID, role, domain.AdjustmentStatusRejected, comments)
+}
+
+// decideBalanceAdjustment moves a pending adjustment to status. Only
+// admins decide adjustments they made themselves. Approving is how
+// adjustments held by a balance anomaly alert are released, so the hold
+// does not apply here; approved adjustments effective on a later day are
+// scheduled.
+func (s *leaveService) decideBalanceAdjustment(orgID, id, deciderID uuid.UUID, role, status, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	adjustment, err := s.getOrgBalanceAdjustment(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	verb := "approved"
+	if status == domain.AdjustmentStatusRejected {
+		verb = "rejected"
+	}
+	if adjustment.Status != domain.AdjustmentStatusPending {
+		return nil, apperrors.NewInvalidStatusError("only pending balance adjustments can be " + verb)
+	}
+	if adjustment.PerformedBy == deciderID && role != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can decide their own balance adjustments")
+	}
+	if status == domain.AdjustmentStatusApproved {
+		if status, err = s.approvedAdjustmentStatus(orgID, adjustment); err != nil {
+			return nil, err
+		}
+	}
+
+	balance := adjustment.LeaveBalance
+	now := time.Now()
+	adjustment.Status = status
+	adjustment.ApprovedBy = &deciderID
+	adjustment.ApprovedAt = &now
+	if comments != "" {
+		adjustment.Comments = comments
+	}
+	adjustment.LeaveBalance = nil
+
+	if err := s.leaveRepo.UpdateBalanceAdjustment(adjustment); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only pending balance adjustments can be " + verb)
+		}
+		return nil, adjustmentBalanceError(err, balance)
+	}
+
+	if status == domain.AdjustmentStatusApproved {
+		s.recordBalanceMutation(orgID, adjustment.Adjustment, domain.BalanceMutationAdjustment)
+	}
+	return adjustment, nil
+}
+
+// approvedAdjustmentStatus is the status an adjustment takes on approval:
+// scheduled when it takes effect after today, approved otherwise
+func (s *leaveService) approvedAdjustmentStatus(orgID uuid.UUID, adjustment *domain.LeaveBalanceAdjustment) (string, error) {
+	if adjustment.EffectiveDate == nil {
+		return domain.AdjustmentStatusApproved, nil
+	}
+	today, err := s.Today(orgID)
+	if err != nil {
+		return "", err
+	}
+	if adjustment.EffectiveAfter(today) {
+		return domain.AdjustmentStatusScheduled, nil
+	}
+	return domain.AdjustmentStatusApproved, nil
+}
+
+// CancelBalanceAdjustment calls off a scheduled adjustment of the
+// organization before it applies. The balance is left as it is.
+func (s *leaveService) CancelBalanceAdjustment(orgID, id, cancelledBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	adjustment, err := s.getOrgBalanceAdjustment(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !adjustment.CanCancel() {
+		return nil, apperrors.NewInvalidStatusError("only scheduled balance adjustments can be cancelled")
+	}
+
+	adjustment.Status = domain.AdjustmentStatusCancelled
+	if comments != "" {
+		adjustment.Comments = comments
+	}
+	adjustment.LeaveBalance = nil
+
+	if err := s.leaveRepo.UpdateBalanceAdjustment(adjustment); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only scheduled balance adjustments can be cancelled")
+		}
+		return nil, err
+	}
+
+	log.Printf("Scheduled balance adjustment %s cancelled by %s", adjustment.ID, cancelledBy)
+	return adjustment, nil
+}
+
+// ApplyDueBalanceAdjustments applies the organization's scheduled
+// adjustments effective on or before today in its time zone, each in its
+// own transaction. An adjustment moves from scheduled to approved as its
+// change reaches the balance, so it applies once however often this runs;
+// one that fails, e.g. a deduction the balance no longer covers, is logged
+// and stays scheduled for the next run.
+func (s *leaveService) ApplyDueBalanceAdjustments(orgID uuid.UUID) (*domain.ScheduledAdjustmentsApplication, error) {
+	today, err := s.Today(orgID)
+	if err != nil {
+		return nil, err
+	}
+	// Effective dates are stored as plain dates
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	ids, err := s.leaveRepo.ListDueBalanceAdjustmentIDs(orgID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ScheduledAdjustmentsApplication{
+		OrganizationID: orgID,
+		Date:           today,
+		Due:            len(ids),
+		Applied:        []uuid.UUID{},
+		Failed:         []uuid.UUID{},
+	}
+	for _, id := range ids {
+		adjustment, err := s.leaveRepo.GetBalanceAdjustment(id)
+		if err != nil {
+			log.Printf("Error: cannot load scheduled balance adjustment %s: %v", id, err)
+			result.Failed = append(result.Failed, id)
+			continue
+		}
+
+		adjustment.Status = domain.AdjustmentStatusApproved
+		adjustment.LeaveBalance = nil
+		err = s.leaveRepo.UpdateBalanceAdjustment(adjustment)
+		switch {
+		case errors.Is(err, repository.ErrStatusChanged):
+			// Cancelled or applied since it was listed
+			continue
+		case err != nil:
+			log.Printf("Error: cannot apply scheduled balance adjustment %s: %v", id, err)
+			result.Failed = append(result.Failed, id)
+			continue
+		}
+
+		s.recordBalanceMutation(orgID, adjustment.Adjustment, domain.BalanceMutationAdjustment)
+		result.Applied = append(result.Applied, id)
+	}
+
+	if result.Due > 0 {
+		log.Printf("Scheduled balance adjustments of %s applied: %d due, %d applied, %d failed",
+			orgID, result.Due, len(result.Applied), len(result.Failed))
+	}
+	return result, nil
+}
+
+// ApplyAllDueBalanceAdjustments applies the due scheduled adjustments of
+// every organization that has any, for a scheduler to call. An
+// organization that fails is logged and left for the next run.
+func (s *leaveService) ApplyAllDueBalanceAdjustments() ([]domain.ScheduledAdjustmentsApplication, error) {
+	orgIDs, err := s.leaveRepo.ListScheduledAdjustmentOrganizationIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.ScheduledAdjustmentsApplication, 0, len(orgIDs))
+	for _, orgID := range orgIDs {
+		result, err := s.ApplyDueBalanceAdjustments(orgID)
+		if err != nil {
+			log.Printf("Error: cannot apply scheduled balance adjustments of %s: %v", orgID, err)
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// RevertBalanceAdjustment takes back an approved adjustment of the
+// organization made in error, recording the reversal as a correction that
+// is returned. Taking back added days is checked like a deduction.
+// Transfers are reverted by transferring back instead, as both sides must
+// move together.
+func (s *leaveService) RevertBalanceAdjustment(orgID, id, revertedBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	adjustment, err := s.getOrgBalanceAdjustment(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !adjustment.CanRevert() {
+		return nil, apperrors.NewInvalidStatusError("only approved balance adjustments can be reverted")
+	}
+	if adjustment.TransferID != nil {
+		return nil, apperrors.NewBadRequestError("adjustments of a balance transfer cannot be reverted, transfer the days back instead")
+	}
+
+	correction, err := s.leaveRepo.RevertBalanceAdjustment(adjustment.ID, revertedBy, comments)
+	if err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only approved balance adjustments can be reverted")
+		}
+		return nil, adjustmentBalanceError(err, adjustment.LeaveBalance)
+	}
+
+	s.recordBalanceMutation(orgID, correction.Adjustment, domain.BalanceMutationAdjustment)
+	return correction, nil
+}
+
+// getOrgBalanceAdjustment loads an adjustment with its balance and hides
+// adjustments of other organizations behind the same not-found error as
+// missing ones
+func (s *leaveService) getOrgBalanceAdjustment(orgID, id uuid.UUID) (*domain.LeaveBalanceAdjustment, error) {
+	adjustment, err := s.leaveRepo.GetBalanceAdjustment(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && (adjustment.LeaveBalance == nil || adjustment.LeaveBalance.OrganizationID != orgID)) {
+		return nil, apperrors.NewNotFoundError("balance adjustment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// adjustmentBalanceError maps the repository's balance check failures on
+// applying an adjustment to API errors
+func adjustmentBalanceError(err error, balance *domain.LeaveBalance) error {
+	var insufficient *repository.InsufficientBalanceError
+	var capExceeded *repository.NegativeBalanceCapError
+	switch {
+	case errors.As(err, &insufficient):
+		return apperrors.NewInsufficientBalanceError(fmt.Sprintf(
+			"insufficient leave balance: cannot deduct %.2f, %.2f available excluding pending requests",
+			insufficient.Requested, insufficient.Available))
+	case errors.As(err, &capExceeded):
+		unit := ""
+		if balance.LeaveType != nil {
+			unit = balance.LeaveType.Unit
+		}
+		return negativeBalanceCapError(capExceeded, unit)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NewNotFoundError("leave balance not found")
+	}
+	return err
+}
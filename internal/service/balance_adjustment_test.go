@@ -0,0 +1,507 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestRevertBalanceAdjustment(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		transfer   bool
+		otherOrg   bool
+		revertErr  error
+		wantStatus int
+	}{
+		{name: "approved", status: domain.AdjustmentStatusApproved},
+		{name: "pending", status: domain.AdjustmentStatusPending, wantStatus: 409},
+		{name: "already reverted", status: domain.AdjustmentStatusReverted, wantStatus: 409},
+		{name: "correction", status: domain.AdjustmentStatusCorrection, wantStatus: 409},
+		{name: "side of a transfer", status: domain.AdjustmentStatusApproved, transfer: true, wantStatus: 400},
+		{name: "another organization's", status: domain.AdjustmentStatusApproved, otherOrg: true, wantStatus: 404},
+		{name: "reverted concurrently", status: domain.AdjustmentStatusApproved, revertErr: repository.ErrStatusChanged, wantStatus: 409},
+		{name: "added days already used", status: domain.AdjustmentStatusApproved, revertErr: &repository.InsufficientBalanceError{Available: 1, Requested: 3}, wantStatus: 422},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.revertErr = tt.revertErr
+
+			balanceOrg := orgID
+			if tt.otherOrg {
+				balanceOrg = uuid.New()
+			}
+			adjustment := &domain.LeaveBalanceAdjustment{
+				LeaveBalanceID: uuid.New(),
+				LeaveBalance:   &domain.LeaveBalance{OrganizationID: balanceOrg, LeaveType: &domain.LeaveType{Name: "Annual"}},
+				Adjustment:     3,
+				Status:         tt.status,
+			}
+			adjustment.ID = uuid.New()
+			if tt.transfer {
+				transferID := uuid.New()
+				adjustment.TransferID = &transferID
+			}
+			repo.adjustments[adjustment.ID] = adjustment
+
+			revertedBy := uuid.New()
+			correction, err := newTestService(repo).RevertBalanceAdjustment(orgID, adjustment.ID, revertedBy, "entered twice")
+			if status := httpStatus(err); status != tt.wantStatus {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if tt.revertErr == nil && repo.reverted != nil {
+					t.Error("a refused revert reached the repository")
+				}
+				return
+			}
+			if correction.Adjustment != -3 || correction.Status != domain.AdjustmentStatusCorrection {
+				t.Errorf("correction = %+v, want -3 as a correction", correction)
+			}
+		})
+	}
+}
+
+func TestCreateBalanceAdjustmentBankCap(t *testing.T) {
+	tests := []struct {
+		name         string
+		adjustment   float64
+		role         string
+		override     bool
+		wantStatus   int
+		wantOverride bool
+	}{
+		{name: "within the cap", adjustment: 2, role: domain.RoleManager},
+		{name: "up to the cap", adjustment: 4, role: domain.RoleManager},
+		{name: "past the cap", adjustment: 5, role: domain.RoleAdmin, wantStatus: 422},
+		{name: "admin override", adjustment: 5, role: domain.RoleAdmin, override: true, wantOverride: true},
+		{name: "manager override", adjustment: 5, role: domain.RoleManager, override: true, wantStatus: 403},
+		{name: "deduction at the cap", adjustment: -1, role: domain.RoleManager},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			capDays := 30.0
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			leaveType.MaxBalanceDays = &capDays
+			balance := domain.LeaveBalance{
+				Base:           domain.Base{ID: uuid.New()},
+				OrganizationID: orgID,
+				LeaveTypeID:    leaveType.ID,
+				LeaveType:      leaveType,
+				TotalDays:      36,
+				UsedDays:       10,
+			}
+			repo.balances = append(repo.balances, balance)
+
+			adjustment, err := newTestService(repo).CreateBalanceAdjustment(orgID, &domain.CreateBalanceAdjustmentRequest{
+				LeaveBalanceID: balance.ID,
+				Adjustment:     tt.adjustment,
+				Reason:         "Long service award",
+				OverrideCap:    tt.override,
+				PerformedBy:    uuid.New(),
+				CallerRole:     tt.role,
+			})
+			if status := httpStatus(err); status != tt.wantStatus {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if repo.createdAdjustment != nil {
+					t.Error("a rejected adjustment was saved")
+				}
+				return
+			}
+			if adjustment.CapOverridden != tt.wantOverride {
+				t.Errorf("cap overridden = %v, want %v", adjustment.CapOverridden, tt.wantOverride)
+			}
+		})
+	}
+}
+
+func TestBulkAdjustBalances(t *testing.T) {
+	orgID, employeeID, otherID := uuid.New(), uuid.New(), uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	annual := repo.addLeaveType(orgID, domain.RoundingNone)
+	casual := repo.addLeaveType(orgID, domain.RoundingNone)
+	casual.Name = "Casual"
+	for _, leaveType := range []*domain.LeaveType{annual, casual} {
+		repo.balances = append(repo.balances, domain.LeaveBalance{
+			Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, EmployeeID: employeeID,
+			LeaveTypeID: leaveType.ID, LeaveType: leaveType, Year: 2026, TotalDays: 20,
+		})
+	}
+	svc, directory := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{Email: "Ana@Example.com"})
+
+	rows := []domain.BulkAdjustmentRow{
+		{Line: 2, EmployeeID: employeeID.String(), LeaveType: "annual", Adjustment: "2", Reason: "Long service award"},
+		{Line: 3, Email: "ana@example.com", LeaveType: casual.ID.String(), Adjustment: "-0.5", Reason: "Correction"},
+		{Line: 4, EmployeeID: "E-104", LeaveType: "Annual", Adjustment: "1", Reason: "Bonus day"},
+		{Line: 5, Email: "bo@example.com", LeaveType: "Annual", Adjustment: "1", Reason: "Bonus day"},
+		{Line: 6, LeaveType: "Annual", Adjustment: "1", Reason: "Bonus day"},
+		{Line: 7, EmployeeID: employeeID.String(), LeaveType: "Sabbatical", Adjustment: "1", Reason: "Bonus day"},
+		{Line: 8, EmployeeID: employeeID.String(), LeaveType: "Annual", Adjustment: "two", Reason: "Bonus day"},
+		{Line: 9, EmployeeID: employeeID.String(), LeaveType: "Annual", Adjustment: "0", Reason: "Bonus day"},
+		{Line: 10, EmployeeID: employeeID.String(), LeaveType: "Annual", Adjustment: "1", Reason: "Gift"},
+		{Line: 11, EmployeeID: otherID.String(), LeaveType: "Annual", Adjustment: "1", Reason: "Bonus day"},
+	}
+	report, err := svc.BulkAdjustBalances(orgID, &domain.BulkAdjustmentRequest{
+		Year: 2026, AutoApprove: true, Rows: rows, PerformedBy: uuid.New(), CallerRole: domain.RoleAdmin,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantErrors := []string{"", "", "invalid employee_id", "no employee with email bo@example.com",
+		"employee_id or email is required", `unknown leave type "Sabbatical"`, "adjustment must be a number",
+		"adjustment must not be zero", "reason must be 5 to 500 characters", "no Annual balance for the year"}
+	if report.Rows != 10 || report.Succeeded != 2 || report.Failed != 8 || len(report.Results) != 10 {
+		t.Fatalf("report = %+v, want 2 of 10 rows created", report)
+	}
+	// A bad row fails alone, reported by its line
+	for i, result := range report.Results {
+		if result.Line != rows[i].Line || result.Error != wantErrors[i] {
+			t.Errorf("line %d: %s %q, want %q", result.Line, result.Status, result.Error, wantErrors[i])
+		}
+		if created := result.Status == domain.BulkAdjustmentRowCreated; created != (wantErrors[i] == "") ||
+			created && (result.AdjustmentID == nil || result.AdjustmentStatus != domain.AdjustmentStatusApproved) {
+			t.Errorf("line %d: %+v", result.Line, result)
+		}
+	}
+	if email := report.Results[1]; email.EmployeeID == nil || *email.EmployeeID != employeeID || *email.LeaveTypeID != casual.ID {
+		t.Errorf("email row resolved to %+v, want %s's casual balance", email, employeeID)
+	}
+	if directory.listed != 1 {
+		t.Errorf("listed employees %d times, want once", directory.listed)
+	}
+}
+
+func TestBulkAdjustBalancesLooksUpEmailsOnlyWhenNeeded(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	repo.addLeaveType(orgID, domain.RoundingNone)
+	svc, directory := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{})
+	directory.err = organization.ErrUnavailable
+
+	req := &domain.BulkAdjustmentRequest{Year: 2026, PerformedBy: uuid.New(), CallerRole: domain.RoleManager, Rows: []domain.BulkAdjustmentRow{
+		{Line: 2, EmployeeID: employeeID.String(), Email: "ana@example.com", LeaveType: "Annual", Adjustment: "1", Reason: "Bonus day"},
+	}}
+	if _, err := svc.BulkAdjustBalances(orgID, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if directory.listed != 0 {
+		t.Error("employees were listed for rows naming their employee by id")
+	}
+
+	req.Rows[0].EmployeeID = ""
+	if _, err := svc.BulkAdjustBalances(orgID, req); httpStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("got %d (%v), want 503 when emails cannot be looked up", httpStatus(err), err)
+	}
+}
+
+func TestBulkAdjustBalancesAuthorization(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	svc := newTestService(repo)
+	for name, req := range map[string]*domain.BulkAdjustmentRequest{
+		"auto approve": {AutoApprove: true, CallerRole: domain.RoleManager},
+		"override cap": {OverrideCap: true, CallerRole: domain.RoleHR},
+	} {
+		if _, err := svc.BulkAdjustBalances(orgID, req); httpStatus(err) != http.StatusForbidden {
+			t.Errorf("%s: got %d (%v), want 403", name, httpStatus(err), err)
+		}
+	}
+}
+
+func TestCreateBalanceAdjustmentEffectiveDate(t *testing.T) {
+	orgID := uuid.New()
+	today := domain.DefaultOrganizationSettings(orgID).Today()
+	tomorrow := today.AddDate(0, 0, 1)
+	tests := []struct {
+		name        string
+		effective   time.Time
+		year        int
+		autoApprove bool
+		wantStatus  string
+		wantError   int
+	}{
+		{name: "approved for a later day", effective: tomorrow, year: tomorrow.Year(), autoApprove: true, wantStatus: domain.AdjustmentStatusScheduled},
+		{name: "approved for today", effective: today, year: today.Year(), autoApprove: true, wantStatus: domain.AdjustmentStatusApproved},
+		{name: "awaiting approval", effective: tomorrow, year: tomorrow.Year(), wantStatus: domain.AdjustmentStatusPending},
+		{name: "outside the balance's year", effective: tomorrow, year: tomorrow.Year() + 1, autoApprove: true, wantError: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			balance := domain.LeaveBalance{
+				Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, LeaveTypeID: leaveType.ID, LeaveType: leaveType,
+				Year: tt.year, TotalDays: 20,
+			}
+			repo.balances = append(repo.balances, balance)
+
+			effective := tt.effective.Add(15 * time.Hour)
+			adjustment, err := newTestService(repo).CreateBalanceAdjustment(orgID, &domain.CreateBalanceAdjustmentRequest{
+				LeaveBalanceID: balance.ID,
+				Adjustment:     2,
+				Reason:         "Long service award",
+				AutoApprove:    tt.autoApprove,
+				EffectiveDate:  &effective,
+				PerformedBy:    uuid.New(),
+				CallerRole:     domain.RoleAdmin,
+			})
+			if tt.wantError != 0 {
+				if httpStatus(err) != tt.wantError {
+					t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if adjustment.Status != tt.wantStatus || !adjustment.EffectiveDate.Equal(tt.effective) {
+				t.Errorf("adjustment is %s effective %v, want %s effective %v", adjustment.Status, adjustment.EffectiveDate, tt.wantStatus, tt.effective)
+			}
+		})
+	}
+}
+
+// scheduledAdjustment adds an adjustment of a balance of orgID to repo
+func scheduledAdjustment(repo *fakeRepository, orgID uuid.UUID, status string, effective time.Time, delta float64) *domain.LeaveBalanceAdjustment {
+	adjustment := &domain.LeaveBalanceAdjustment{
+		Base:           domain.Base{ID: uuid.New()},
+		LeaveBalanceID: uuid.New(),
+		LeaveBalance:   &domain.LeaveBalance{OrganizationID: orgID, Year: effective.Year()},
+		Adjustment:     delta,
+		Reason:         "Long service award",
+		PerformedBy:    uuid.New(),
+		Status:         status,
+		EffectiveDate:  &effective,
+	}
+	repo.adjustments[adjustment.ID] = adjustment
+	return adjustment
+}
+
+func TestApproveBalanceAdjustmentSchedulesLaterEffect(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	adjustment := scheduledAdjustment(repo, orgID, domain.AdjustmentStatusPending, repo.settings.Today().AddDate(0, 0, 7), 2)
+
+	approved, err := newTestService(repo).ApproveBalanceAdjustment(orgID, adjustment.ID, uuid.New(), domain.RoleManager, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approved.Status != domain.AdjustmentStatusScheduled || approved.ApprovedBy == nil {
+		t.Errorf("approved adjustment is %s, want scheduled", approved.Status)
+	}
+	if len(repo.updatedAdjustments) != 1 || repo.updatedAdjustments[0].Status != domain.AdjustmentStatusScheduled {
+		t.Errorf("saved %+v, want the adjustment scheduled", repo.updatedAdjustments)
+	}
+}
+
+func TestCancelBalanceAdjustment(t *testing.T) {
+	orgID := uuid.New()
+	nextWeek := domain.DefaultOrganizationSettings(orgID).Today().AddDate(0, 0, 7)
+	tests := []struct {
+		name       string
+		status     string
+		updateErr  error
+		wantStatus int
+	}{
+		{name: "scheduled", status: domain.AdjustmentStatusScheduled},
+		{name: "already applied", status: domain.AdjustmentStatusApproved, wantStatus: http.StatusConflict},
+		{name: "pending", status: domain.AdjustmentStatusPending, wantStatus: http.StatusConflict},
+		{name: "applied meanwhile", status: domain.AdjustmentStatusScheduled, updateErr: repository.ErrStatusChanged, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			adjustment := scheduledAdjustment(repo, orgID, tt.status, nextWeek, 2)
+			repo.updateAdjustmentErr = map[uuid.UUID]error{adjustment.ID: tt.updateErr}
+
+			cancelled, err := newTestService(repo).CancelBalanceAdjustment(orgID, adjustment.ID, uuid.New(), "award withdrawn")
+			if httpStatus(err) != tt.wantStatus {
+				t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				return
+			}
+			if cancelled.Status != domain.AdjustmentStatusCancelled || cancelled.Comments != "award withdrawn" {
+				t.Errorf("cancelled adjustment = %+v", cancelled)
+			}
+		})
+	}
+}
+
+func TestApplyDueBalanceAdjustments(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	today := repo.settings.Today()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	overdue := scheduledAdjustment(repo, orgID, domain.AdjustmentStatusScheduled, today.AddDate(0, 0, -2), 2)
+	uncovered := scheduledAdjustment(repo, orgID, domain.AdjustmentStatusScheduled, today.AddDate(0, 0, -1), -30)
+	cancelled := scheduledAdjustment(repo, orgID, domain.AdjustmentStatusScheduled, today, 1)
+	scheduledAdjustment(repo, orgID, domain.AdjustmentStatusScheduled, today.AddDate(0, 0, 1), 1)
+	scheduledAdjustment(repo, orgID, domain.AdjustmentStatusCancelled, today, 1)
+	repo.updateAdjustmentErr = map[uuid.UUID]error{
+		uncovered.ID: &repository.InsufficientBalanceError{},
+		// Cancelled after it was listed
+		cancelled.ID: repository.ErrStatusChanged,
+	}
+
+	result, err := newTestService(repo).ApplyDueBalanceAdjustments(orgID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Due != 3 || !result.Date.Equal(today) {
+		t.Errorf("%d due on %v, want 3 due on %v", result.Due, result.Date, today)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != overdue.ID {
+		t.Errorf("applied %v, want only %s", result.Applied, overdue.ID)
+	}
+	// The deduction stays scheduled for the next run
+	if len(result.Failed) != 1 || result.Failed[0] != uncovered.ID {
+		t.Errorf("failed %v, want only %s", result.Failed, uncovered.ID)
+	}
+	if len(repo.updatedAdjustments) != 1 || repo.updatedAdjustments[0].Status != domain.AdjustmentStatusApproved {
+		t.Errorf("saved %+v, want the overdue adjustment approved", repo.updatedAdjustments)
+	}
+}
+
+func TestTransferLeaveBalanceValidation(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	annual := repo.addLeaveType(orgID, domain.RoundingNone)
+	casual := repo.addLeaveType(orgID, domain.RoundingNone)
+	casual.Name = "Casual"
+	hourly := repo.addLeaveType(orgID, domain.RoundingNone)
+	hourly.Name, hourly.Unit = "Volunteering", domain.LeaveUnitHours
+	unpaid := repo.addLeaveType(orgID, domain.RoundingNone)
+	unpaid.Name, unpaid.TracksBalance = "Unpaid", false
+	foreign := repo.addLeaveType(uuid.New(), domain.RoundingNone)
+
+	tests := []struct {
+		name         string
+		from, to     uuid.UUID
+		days         float64
+		allRemaining bool
+		wantStatus   int
+	}{
+		{name: "same type", from: annual.ID, to: annual.ID, days: 1, wantStatus: http.StatusBadRequest},
+		{name: "no amount", from: annual.ID, to: casual.ID, wantStatus: http.StatusBadRequest},
+		{name: "days and all remaining", from: annual.ID, to: casual.ID, days: 1, allRemaining: true, wantStatus: http.StatusBadRequest},
+		{name: "untracked target", from: annual.ID, to: unpaid.ID, days: 1, wantStatus: http.StatusBadRequest},
+		{name: "different units", from: annual.ID, to: hourly.ID, days: 1, wantStatus: http.StatusBadRequest},
+		{name: "another organization's type", from: annual.ID, to: foreign.ID, days: 1, wantStatus: http.StatusNotFound},
+		{name: "valid", from: annual.ID, to: casual.ID, days: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.transfers = nil
+			_, err := newTestService(repo).TransferLeaveBalance(orgID, &domain.BalanceTransferRequest{
+				EmployeeID:      uuid.New(),
+				FromLeaveTypeID: tt.from,
+				ToLeaveTypeID:   tt.to,
+				Days:            tt.days,
+				AllRemaining:    tt.allRemaining,
+				Reason:          "Converting unused leave",
+			})
+			if httpStatus(err) != tt.wantStatus {
+				t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+			}
+			if moved := len(repo.transfers) == 1; moved != (tt.wantStatus == 0) {
+				t.Errorf("transferred = %v, want %v", moved, tt.wantStatus == 0)
+			}
+		})
+	}
+}
+
+func TestTransferLeaveBalanceErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "insufficient", err: &repository.InsufficientBalanceError{Available: 2, Requested: 5}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "nothing to transfer", err: repository.ErrNothingToTransfer, wantStatus: http.StatusUnprocessableEntity},
+		{name: "no source balance", err: gorm.ErrRecordNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			from := repo.addLeaveType(orgID, domain.RoundingNone)
+			to := repo.addLeaveType(orgID, domain.RoundingNone)
+			repo.transferErrs = map[uuid.UUID]error{employeeID: tt.err}
+
+			_, err := newTestService(repo).TransferLeaveBalance(orgID, &domain.BalanceTransferRequest{
+				EmployeeID: employeeID, FromLeaveTypeID: from.ID, ToLeaveTypeID: to.ID, AllRemaining: true, Reason: "Converting unused leave",
+			})
+			if httpStatus(err) != tt.wantStatus {
+				t.Errorf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRunBalanceTransferJob(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	moved, empty, missing := uuid.New(), uuid.New(), uuid.New()
+	repo.transferErrs = map[uuid.UUID]error{
+		empty:   repository.ErrNothingToTransfer,
+		missing: gorm.ErrRecordNotFound,
+	}
+	job := domain.BalanceTransferJob{
+		Base:           domain.Base{ID: uuid.New()},
+		OrganizationID: orgID,
+		AllRemaining:   true,
+		Status:         domain.TransferJobStatusQueued,
+		Total:          3,
+		EmployeeIDs:    []uuid.UUID{moved, empty, missing},
+	}
+
+	newTestService(repo).runBalanceTransferJob(job, 20)
+
+	// Progress is saved after every employee
+	if len(repo.transferJobs) != 5 {
+		t.Fatalf("saved the job %d times, want when it starts, after each employee and when it ends", len(repo.transferJobs))
+	}
+	last := repo.transferJobs[len(repo.transferJobs)-1]
+	if last.Status != domain.TransferJobStatusCompleted || last.Succeeded != 1 || last.Skipped != 1 || last.Failed != 1 ||
+		last.StartedAt == nil || last.FinishedAt == nil {
+		t.Errorf("job finished as %+v, want completed with one of each outcome", last)
+	}
+	if len(repo.transfers) != 1 || repo.transfers[0].EmployeeID != moved {
+		t.Errorf("transfers = %+v, want only %s's", repo.transfers, moved)
+	}
+	if len(repo.transferFailures) != 1 || repo.transferFailures[0].EmployeeID != missing ||
+		repo.transferFailures[0].JobID != job.ID || !strings.Contains(repo.transferFailures[0].Error, "no balance of the source leave type") {
+		t.Errorf("failures = %+v, want %s's missing balance", repo.transferFailures, missing)
+	}
+}
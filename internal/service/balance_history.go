@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetBalanceHistory returns a page of the ledger of the employee's balance
+// of a leave type for a year, the current one unless set, with the total
+// number of entries. Drift between the ledger and the stored balance is
+// flagged on the ledger and logged.
+func (s *leaveService) GetBalanceHistory(orgID, employeeID, leaveTypeID uuid.UUID, year, page, pageSize int) (*domain.BalanceLedger, int64, error) {
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	balance, err := s.leaveRepo.GetLeaveBalance(employeeID, leaveTypeID, year)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && balance.OrganizationID != orgID) {
+		return nil, 0, apperrors.NewNotFoundError("leave balance not found")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, adjustments, leave, err := s.leaveRepo.SumBalanceLedger(balance)
+	if err != nil {
+		return nil, 0, err
+	}
+	opening := balance.TotalDays - adjustments
+
+	entries, err := s.leaveRepo.ListBalanceLedgerEntries(balance, opening, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entries == nil {
+		entries = []domain.BalanceLedgerEntry{}
+	}
+
+	ledger := &domain.BalanceLedger{
+		LeaveBalanceID: balance.ID,
+		EmployeeID:     balance.EmployeeID,
+		LeaveTypeID:    balance.LeaveTypeID,
+		Year:           balance.Year,
+		OpeningBalance: opening,
+		ClosingBalance: opening + adjustments + leave,
+		StoredBalance:  balance.TotalDays - balance.UsedDays,
+		Entries:        entries,
+	}
+	ledger.Reconciled = math.Abs(ledger.ClosingBalance-ledger.StoredBalance) < 0.005
+	if !ledger.Reconciled {
+		log.Printf("Warning: balance %s drifted from its ledger: stored %.2f, ledger %.2f",
+			balance.ID, ledger.StoredBalance, ledger.ClosingBalance)
+	}
+	return ledger, count, nil
+}
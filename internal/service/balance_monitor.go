@@ -0,0 +1,176 @@
+package service
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// balanceWriteWindow is how long balance writes are counted against an
+// organization's rate threshold
+const balanceWriteWindow = time.Minute
+
+// balanceMonitor watches balance writes for signs of a runaway bug: a single
+// change larger than the organization's threshold, or more writes in a
+// minute than it allows. It keeps its counts and alerts in memory, so each
+// instance watches the writes it serves and a restart clears its alerts.
+type balanceMonitor struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	rates  map[uuid.UUID]*balanceWriteCount
+	alerts map[uuid.UUID]*domain.BalanceAnomaly
+}
+
+type balanceWriteCount struct {
+	since  time.Time
+	writes int
+}
+
+func newBalanceMonitor() *balanceMonitor {
+	return &balanceMonitor{
+		now:    time.Now,
+		rates:  make(map[uuid.UUID]*balanceWriteCount),
+		alerts: make(map[uuid.UUID]*domain.BalanceAnomaly),
+	}
+}
+
+// observe counts one balance write of delta days for the organization and
+// returns the alert it raised, if any. An organization has at most one
+// active alert; writes while it is active are counted but raise nothing new.
+func (m *balanceMonitor) observe(settings *domain.OrganizationSettings, delta float64, source string) *domain.BalanceAnomaly {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	orgID := settings.OrganizationID
+	rate := m.rates[orgID]
+	if rate == nil || now.Sub(rate.since) >= balanceWriteWindow {
+		rate = &balanceWriteCount{since: now}
+		m.rates[orgID] = rate
+	}
+	rate.writes++
+
+	if m.alerts[orgID] != nil {
+		return nil
+	}
+
+	anomaly := &domain.BalanceAnomaly{
+		OrganizationID:  orgID,
+		Source:          source,
+		AdjustmentsHeld: settings.AnomalyHoldAdjustments,
+		DetectedAt:      now,
+	}
+	switch {
+	case settings.AnomalyMaxMutationDays > 0 && math.Abs(delta) > settings.AnomalyMaxMutationDays:
+		anomaly.Kind = domain.BalanceAnomalyLargeMutation
+		anomaly.Delta = delta
+		anomaly.Threshold = settings.AnomalyMaxMutationDays
+	case settings.AnomalyMaxWritesPerMinute > 0 && rate.writes > settings.AnomalyMaxWritesPerMinute:
+		anomaly.Kind = domain.BalanceAnomalyWriteRate
+		anomaly.Writes = rate.writes
+		anomaly.Threshold = float64(settings.AnomalyMaxWritesPerMinute)
+	default:
+		return nil
+	}
+
+	m.alerts[orgID] = anomaly
+	copied := *anomaly
+	return &copied
+}
+
+// active returns a copy of the organization's active alert, or nil
+func (m *balanceMonitor) active(orgID uuid.UUID) *domain.BalanceAnomaly {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	anomaly := m.alerts[orgID]
+	if anomaly == nil {
+		return nil
+	}
+	copied := *anomaly
+	return &copied
+}
+
+// clear removes the organization's active alert and returns it, or nil
+// when there was none. The write count starts over.
+func (m *balanceMonitor) clear(orgID uuid.UUID) *domain.BalanceAnomaly {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	anomaly := m.alerts[orgID]
+	delete(m.alerts, orgID)
+	delete(m.rates, orgID)
+	return anomaly
+}
+
+// recordBalanceMutation reports a balance write to the anomaly monitor and
+// logs the alert it raises. It never fails the caller; when the
+// organization's thresholds cannot be read the defaults apply.
+func (s *leaveService) recordBalanceMutation(orgID uuid.UUID, delta float64, source string) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		log.Printf("Warning: using default balance anomaly thresholds for %s: %v", orgID, err)
+		settings = domain.DefaultOrganizationSettings(orgID)
+	}
+
+	anomaly := s.balances.observe(settings, delta, source)
+	if anomaly == nil {
+		return
+	}
+	switch anomaly.Kind {
+	case domain.BalanceAnomalyLargeMutation:
+		log.Printf("Alert: balance anomaly in organization %s: %s changed a balance by %.2f, over the %.2f threshold (adjustments held: %t)",
+			orgID, source, anomaly.Delta, anomaly.Threshold, anomaly.AdjustmentsHeld)
+	case domain.BalanceAnomalyWriteRate:
+		log.Printf("Alert: balance anomaly in organization %s: %d balance writes within a minute, over the %.0f threshold, last from %s (adjustments held: %t)",
+			orgID, anomaly.Writes, anomaly.Threshold, source, anomaly.AdjustmentsHeld)
+	}
+}
+
+// adjustmentsHeld reports whether an active alert holds the organization's
+// balance adjustments for approval
+func (s *leaveService) adjustmentsHeld(orgID uuid.UUID) bool {
+	anomaly := s.balances.active(orgID)
+	return anomaly != nil && anomaly.AdjustmentsHeld
+}
+
+// balanceChangesHeldError refuses transfers, which are applied without
+// approval, while adjustments are held
+func balanceChangesHeldError() error {
+	return apperrors.NewConflictError("balance changes are held by an active balance anomaly alert until an admin clears it")
+}
+
+// recordTransfer reports both sides of a balance transfer
+func (s *leaveService) recordTransfer(orgID uuid.UUID, transfer *domain.BalanceTransfer) {
+	if transfer.Debit != nil {
+		s.recordBalanceMutation(orgID, transfer.Debit.Adjustment, domain.BalanceMutationTransfer)
+	}
+	if transfer.Credit != nil {
+		s.recordBalanceMutation(orgID, transfer.Credit.Adjustment, domain.BalanceMutationTransfer)
+	}
+}
+
+// GetBalanceAnomaly returns the organization's active balance anomaly alert
+func (s *leaveService) GetBalanceAnomaly(orgID uuid.UUID) (*domain.BalanceAnomaly, error) {
+	anomaly := s.balances.active(orgID)
+	if anomaly == nil {
+		return nil, apperrors.NewNotFoundError("no active balance anomaly alert")
+	}
+	return anomaly, nil
+}
+
+// ClearBalanceAnomaly dismisses the organization's active balance anomaly
+// alert, releasing held adjustments
+func (s *leaveService) ClearBalanceAnomaly(orgID, clearedBy uuid.UUID) (*domain.BalanceAnomaly, error) {
+	anomaly := s.balances.clear(orgID)
+	if anomaly == nil {
+		return nil, apperrors.NewNotFoundError("no active balance anomaly alert")
+	}
+	log.Printf("Balance anomaly alert in organization %s cleared by %s", orgID, clearedBy)
+	return anomaly, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// newClockedMonitor returns a monitor whose clock the test moves
+func newClockedMonitor(start time.Time) (*balanceMonitor, *time.Time) {
+	now := start
+	m := newBalanceMonitor()
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func anomalySettings(maxDays float64, maxWrites int) *domain.OrganizationSettings {
+	settings := domain.DefaultOrganizationSettings(uuid.New())
+	settings.AnomalyMaxMutationDays = maxDays
+	settings.AnomalyMaxWritesPerMinute = maxWrites
+	return settings
+}
+
+func TestBalanceMonitorLargeMutation(t *testing.T) {
+	tests := []struct {
+		name     string
+		delta    float64
+		wantKind string
+	}{
+		{name: "at the threshold", delta: 10},
+		{name: "credit over the threshold", delta: 10.5, wantKind: domain.BalanceAnomalyLargeMutation},
+		{name: "debit over the threshold", delta: -12, wantKind: domain.BalanceAnomalyLargeMutation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newClockedMonitor(time.Now())
+			settings := anomalySettings(10, 100)
+
+			anomaly := m.observe(settings, tt.delta, domain.BalanceMutationAdjustment)
+			if tt.wantKind == "" {
+				if anomaly != nil {
+					t.Fatalf("got alert %+v, want none", anomaly)
+				}
+				return
+			}
+			if anomaly == nil || anomaly.Kind != tt.wantKind || anomaly.Delta != tt.delta || anomaly.Threshold != 10 ||
+				anomaly.Source != domain.BalanceMutationAdjustment || !anomaly.AdjustmentsHeld {
+				t.Fatalf("got alert %+v", anomaly)
+			}
+			if m.active(settings.OrganizationID) == nil {
+				t.Error("the alert is not active")
+			}
+		})
+	}
+}
+
+func TestBalanceMonitorWriteRate(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	m, now := newClockedMonitor(start)
+	settings := anomalySettings(0, 3)
+
+	for i := 0; i < 3; i++ {
+		if anomaly := m.observe(settings, 1, domain.BalanceMutationLeaveRequest); anomaly != nil {
+			t.Fatalf("write %d raised %+v", i+1, anomaly)
+		}
+	}
+
+	// The window starts over after a minute
+	*now = start.Add(balanceWriteWindow)
+	for i := 0; i < 3; i++ {
+		if anomaly := m.observe(settings, 1, domain.BalanceMutationLeaveRequest); anomaly != nil {
+			t.Fatalf("write %d of the next minute raised %+v", i+1, anomaly)
+		}
+	}
+
+	anomaly := m.observe(settings, 1, domain.BalanceMutationTransfer)
+	if anomaly == nil || anomaly.Kind != domain.BalanceAnomalyWriteRate || anomaly.Writes != 4 || anomaly.Threshold != 3 {
+		t.Fatalf("fourth write in a minute: got %+v", anomaly)
+	}
+	if again := m.observe(settings, 100, domain.BalanceMutationTransfer); again != nil {
+		t.Errorf("a second alert was raised while the first is active: %+v", again)
+	}
+
+	if cleared := m.clear(settings.OrganizationID); cleared == nil || cleared.Kind != domain.BalanceAnomalyWriteRate {
+		t.Errorf("clear returned %+v", cleared)
+	}
+	if m.active(settings.OrganizationID) != nil {
+		t.Error("the alert is still active after clearing")
+	}
+	if anomaly := m.observe(settings, 1, domain.BalanceMutationTransfer); anomaly != nil {
+		t.Errorf("the write count did not start over after clearing: %+v", anomaly)
+	}
+}
+
+func TestBalanceMonitorKeepsOrganizationsApart(t *testing.T) {
+	m, _ := newClockedMonitor(time.Now())
+	noisy := anomalySettings(10, 100)
+	quiet := anomalySettings(10, 100)
+
+	m.observe(noisy, 50, domain.BalanceMutationAdjustment)
+	if m.active(quiet.OrganizationID) != nil {
+		t.Error("another organization's alert is active")
+	}
+	if anomaly := m.observe(quiet, 50, domain.BalanceMutationAdjustment); anomaly == nil {
+		t.Error("an alert elsewhere suppressed this organization's")
+	}
+}
+
+func TestBalanceAnomalyHoldsTransfers(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	svc := newTestService(repo)
+
+	if _, err := svc.GetBalanceAnomaly(orgID); httpStatus(err) != 404 {
+		t.Fatalf("without an alert: got %v, want a 404", err)
+	}
+
+	svc.recordBalanceMutation(orgID, domain.DefaultAnomalyMaxMutationDays+1, domain.BalanceMutationAdjustment)
+	if anomaly, err := svc.GetBalanceAnomaly(orgID); err != nil || anomaly.Kind != domain.BalanceAnomalyLargeMutation {
+		t.Fatalf("got %+v, %v, want a large mutation alert", anomaly, err)
+	}
+	if !svc.adjustmentsHeld(orgID) {
+		t.Error("adjustments are not held")
+	}
+	_, err := svc.TransferLeaveBalance(orgID, &domain.BalanceTransferRequest{EmployeeID: uuid.New()})
+	if status := httpStatus(err); status != 409 {
+		t.Errorf("transfer during the alert: got %v (%d), want a 409", err, status)
+	}
+
+	if _, err := svc.ClearBalanceAnomaly(orgID, uuid.New()); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if svc.adjustmentsHeld(orgID) {
+		t.Error("adjustments are still held after clearing")
+	}
+	if _, err := svc.ClearBalanceAnomaly(orgID, uuid.New()); httpStatus(err) != 404 {
+		t.Errorf("clearing twice: got %v, want a 404", err)
+	}
+}
+
+func TestBalanceAnomalyWithoutHold(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	repo.settings.AnomalyHoldAdjustments = false
+	svc := newTestService(repo)
+
+	svc.recordBalanceMutation(orgID, domain.DefaultAnomalyMaxMutationDays+1, domain.BalanceMutationAdjustment)
+	if _, err := svc.GetBalanceAnomaly(orgID); err != nil {
+		t.Fatalf("no alert was raised: %v", err)
+	}
+	if svc.adjustmentsHeld(orgID) {
+		t.Error("adjustments are held although the organization turned holding off")
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TransferLeaveBalance moves an employee's unused days from one leave type
+// to another in a single step
+func (s *leaveService) TransferLeaveBalance(orgID uuid.UUID, req *domain.BalanceTransferRequest) (*domain.BalanceTransfer, error) {
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	}
+	if s.adjustmentsHeld(orgID) {
+		return nil, balanceChangesHeldError()
+	}
+	entitlement, err := s.validateTransfer(orgID, req.FromLeaveTypeID, req.ToLeaveTypeID, req.Days, req.AllRemaining)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.leaveRepo.TransferLeaveBalance(orgID, req, entitlement)
+	if err != nil {
+		return nil, transferError(err)
+	}
+	s.recordTransfer(orgID, transfer)
+	return transfer, nil
+}
+
+// StartBulkBalanceTransfer queues the same transfer for several employees
+// and runs it in the background. The returned job is polled for progress.
+// A job interrupted by a restart stays running and is not resumed.
+func (s *leaveService) StartBulkBalanceTransfer(orgID uuid.UUID, req *domain.BulkBalanceTransferRequest) (*domain.BalanceTransferJob, error) {
+	if req.All == (len(req.EmployeeIDs) > 0) {
+		return nil, apperrors.NewBadRequestError("set either employee_ids or all")
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	}
+	if s.adjustmentsHeld(orgID) {
+		return nil, balanceChangesHeldError()
+	}
+	entitlement, err := s.validateTransfer(orgID, req.FromLeaveTypeID, req.ToLeaveTypeID, req.Days, req.AllRemaining)
+	if err != nil {
+		return nil, err
+	}
+
+	employeeIDs := req.EmployeeIDs
+	if req.All {
+		employeeIDs, err = s.leaveRepo.ListBalanceEmployeeIDs(req.FromLeaveTypeID, req.Year)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	job := &domain.BalanceTransferJob{
+		OrganizationID:  orgID,
+		FromLeaveTypeID: req.FromLeaveTypeID,
+		ToLeaveTypeID:   req.ToLeaveTypeID,
+		Year:            req.Year,
+		Days:            req.Days,
+		AllRemaining:    req.AllRemaining,
+		Reason:          req.Reason,
+		RequestedBy:     req.PerformedBy,
+		Status:          domain.TransferJobStatusQueued,
+		Total:           len(employeeIDs),
+		EmployeeIDs:     employeeIDs,
+	}
+	if err := s.leaveRepo.CreateBalanceTransferJob(job); err != nil {
+		return nil, err
+	}
+
+	go s.runBalanceTransferJob(*job, entitlement)
+	return job, nil
+}
+
+// GetBalanceTransferJob returns a bulk transfer job of the organization with its failures
+func (s *leaveService) GetBalanceTransferJob(orgID, id uuid.UUID) (*domain.BalanceTransferJob, error) {
+	job, err := s.leaveRepo.GetBalanceTransferJob(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && job.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("balance transfer job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// runBalanceTransferJob transfers each employee's balance in turn, saving
+// progress after every employee. Each transfer commits on its own, so a
+// failure only affects that employee.
+func (s *leaveService) runBalanceTransferJob(job domain.BalanceTransferJob, entitlement float64) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Error: balance transfer job %s stopped: %v", job.ID, r)
+			s.finishBalanceTransferJob(&job, domain.TransferJobStatusFailed)
+		}
+	}()
+
+	now := time.Now()
+	job.Status = domain.TransferJobStatusRunning
+	job.StartedAt = &now
+	s.saveBalanceTransferJob(&job)
+
+	for _, employeeID := range job.EmployeeIDs {
+		if s.adjustmentsHeld(job.OrganizationID) {
+			log.Printf("Alert: balance transfer job %s stopped by the balance anomaly alert after %d of %d employees",
+				job.ID, job.Succeeded+job.Skipped+job.Failed, job.Total)
+			s.finishBalanceTransferJob(&job, domain.TransferJobStatusFailed)
+			return
+		}
+
+		transfer, err := s.leaveRepo.TransferLeaveBalance(job.OrganizationID, &domain.BalanceTransferRequest{
+			EmployeeID:      employeeID,
+			FromLeaveTypeID: job.FromLeaveTypeID,
+			ToLeaveTypeID:   job.ToLeaveTypeID,
+			Year:            job.Year,
+			Days:            job.Days,
+			AllRemaining:    job.AllRemaining,
+			Reason:          job.Reason,
+			PerformedBy:     job.RequestedBy,
+		}, entitlement)
+
+		switch {
+		case err == nil:
+			job.Succeeded++
+			s.recordTransfer(job.OrganizationID, transfer)
+		case errors.Is(err, repository.ErrNothingToTransfer):
+			job.Skipped++
+		default:
+			job.Failed++
+			failure := &domain.BalanceTransferJobFailure{
+				JobID:      job.ID,
+				EmployeeID: employeeID,
+				Error:      transferError(err).Error(),
+			}
+			if err := s.leaveRepo.AddBalanceTransferJobFailure(failure); err != nil {
+				log.Printf("Warning: cannot record balance transfer failure for %s in job %s: %v", employeeID, job.ID, err)
+			}
+		}
+		s.saveBalanceTransferJob(&job)
+	}
+
+	s.finishBalanceTransferJob(&job, domain.TransferJobStatusCompleted)
+}
+
+func (s *leaveService) finishBalanceTransferJob(job *domain.BalanceTransferJob, status string) {
+	now := time.Now()
+	job.Status = status
+	job.FinishedAt = &now
+	s.saveBalanceTransferJob(job)
+}
+
+func (s *leaveService) saveBalanceTransferJob(job *domain.BalanceTransferJob) {
+	if err := s.leaveRepo.UpdateBalanceTransferJob(job); err != nil {
+		log.Printf("Warning: cannot save progress of balance transfer job %s: %v", job.ID, err)
+	}
+}
+
+// validateTransfer checks that both leave types belong to the organization,
+// track a balance in the same unit and differ, and that the amount is set
+// one way only. It returns the entitlement a new target balance opens with.
+func (s *leaveService) validateTransfer(orgID, fromID, toID uuid.UUID, days float64, allRemaining bool) (float64, error) {
+	if fromID == toID {
+		return 0, apperrors.NewBadRequestError("source and target leave types must differ")
+	}
+	if allRemaining == (days > 0) {
+		return 0, apperrors.NewBadRequestError("set either days or all_remaining")
+	}
+
+	from, err := s.GetLeaveType(orgID, fromID)
+	if err != nil {
+		return 0, err
+	}
+	to, err := s.GetLeaveType(orgID, toID)
+	if err != nil {
+		return 0, err
+	}
+	for _, leaveType := range []*domain.LeaveType{from, to} {
+		if !leaveType.TracksBalance {
+			return 0, apperrors.NewBadRequestError(leaveType.Name + " does not track a balance")
+		}
+	}
+	if from.Unit != to.Unit {
+		return 0, apperrors.NewBadRequestError(fmt.Sprintf(
+			"cannot transfer between %s tracked in %s and %s tracked in %s", from.Name, from.Unit, to.Name, to.Unit))
+	}
+
+	return s.entitlement(orgID, to)
+}
+
+// transferError maps repository errors from a transfer to API errors
+func transferError(err error) error {
+	var insufficient *repository.InsufficientBalanceError
+	switch {
+	case errors.As(err, &insufficient):
+		return apperrors.NewInsufficientBalanceError(fmt.Sprintf(
+			"insufficient leave balance: %.2f requested, %.2f available excluding pending requests",
+			insufficient.Requested, insufficient.Available))
+	case errors.Is(err, repository.ErrNothingToTransfer):
+		return apperrors.NewUnprocessableError(err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NewNotFoundError("employee has no balance of the source leave type for this year")
+	}
+	return err
+}
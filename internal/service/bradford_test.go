@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestBradfordReportMergesSpellsAcrossWeekends(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	sick := repo.addLeaveType(orgID, domain.RoundingNone)
+
+	// Monday 2025-06-02
+	day := func(offset int) time.Time { return time.Date(2025, time.June, 2+offset, 0, 0, 0, 0, time.UTC) }
+	weekendSplit, bridged, separate := uuid.New(), uuid.New(), uuid.New()
+	repo.holidays = []domain.Holiday{{Name: "Founders' day", Date: day(14)}}
+	repo.absences = []domain.Absence{
+		{EmployeeID: weekendSplit, StartDate: day(2), EndDate: day(4), Days: 3},
+		{EmployeeID: weekendSplit, StartDate: day(7), EndDate: day(7), Days: 1},
+		{EmployeeID: bridged, StartDate: day(10), EndDate: day(11), Days: 2},
+		{EmployeeID: bridged, StartDate: day(15), EndDate: day(15), Days: 1},
+		{EmployeeID: separate, StartDate: day(0), EndDate: day(0), Days: 1},
+		{EmployeeID: separate, StartDate: day(2), EndDate: day(2), Days: 1},
+	}
+
+	asOf := time.Date(2025, time.June, 30, 15, 0, 0, 0, time.UTC)
+	report, err := newTestService(repo).BradfordReport(orgID, &domain.BradfordParams{AsOf: asOf, LeaveTypeID: sick.ID, PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The window is the 52 weeks ending on the as-of date, both included
+	wantStart := time.Date(2024, time.July, 2, 0, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)
+	if !repo.absenceWindow[0].Equal(wantStart) || !repo.absenceWindow[1].Equal(wantEnd) {
+		t.Errorf("absences listed over %v, want %v to %v", repo.absenceWindow, wantStart, wantEnd)
+	}
+
+	if report.Total != 3 {
+		t.Errorf("total = %d, want 3", report.Total)
+	}
+	want := []domain.BradfordScore{
+		{EmployeeID: separate, Spells: 2, Days: 2, Score: 8, Band: "none"},
+		{EmployeeID: weekendSplit, Spells: 1, Days: 4, Score: 4, Band: "none"},
+	}
+	if len(report.Scores) != len(want) {
+		t.Fatalf("got %+v, want %+v", report.Scores, want)
+	}
+	for i := range want {
+		if report.Scores[i] != want[i] {
+			t.Errorf("score %d = %+v, want %+v", i, report.Scores[i], want[i])
+		}
+	}
+
+	var csv bytes.Buffer
+	if err := newTestService(repo).WriteBradfordCSV(&csv, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[3], "3,"+bridged.String()+",1,3.00,3.00,") {
+		t.Errorf("CSV does not list every employee, the bridged spell last:\n%s", csv.String())
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BulkAdjustBalances records the adjustments of an uploaded CSV against
+// the organization's balances of the requested year, attributed to the
+// uploader and approved at once when an admin asks for it, like single
+// adjustments. Every row is validated and applied on its own, so a large
+// file never holds one long transaction and a bad row only fails itself;
+// the report lists the outcome of each row by its line in the file.
+func (s *leaveService) BulkAdjustBalances(orgID uuid.UUID, req *domain.BulkAdjustmentRequest) (*domain.BulkAdjustmentReport, error) {
+	if req.AutoApprove && req.CallerRole != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can approve their own balance adjustments")
+	}
+	if req.OverrideCap && req.CallerRole != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can override the balance cap")
+	}
+
+	if req.Year == 0 {
+		today, err := s.Today(orgID)
+		if err != nil {
+			return nil, err
+		}
+		req.Year = today.Year()
+	}
+
+	leaveTypes, err := s.leaveRepo.ListLeaveTypes(orgID)
+	if err != nil {
+		return nil, err
+	}
+	typesByKey := make(map[string]*domain.LeaveType, 2*len(leaveTypes))
+	for i := range leaveTypes {
+		typesByKey[leaveTypes[i].ID.String()] = &leaveTypes[i]
+		typesByKey[strings.ToLower(leaveTypes[i].Name)] = &leaveTypes[i]
+	}
+
+	emails, err := s.employeeIDsByEmail(orgID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.BulkAdjustmentReport{
+		Year:    req.Year,
+		Rows:    len(req.Rows),
+		Results: make([]domain.BulkAdjustmentRowResult, 0, len(req.Rows)),
+	}
+	for _, row := range req.Rows {
+		result := s.bulkAdjustRow(orgID, req, row, typesByKey, emails)
+		if result.Status == domain.BulkAdjustmentRowCreated {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	log.Printf("Bulk adjustment of %s by %s finished: %d rows, %d created, %d failed",
+		orgID, req.PerformedBy, report.Rows, report.Succeeded, report.Failed)
+	return report, nil
+}
+
+// employeeIDsByEmail maps the lowercased emails of the organization's
+// employees to their ids when a row names its employee by email only. The
+// organization service is not called otherwise.
+func (s *leaveService) employeeIDsByEmail(orgID uuid.UUID, req *domain.BulkAdjustmentRequest) (map[string]uuid.UUID, error) {
+	needed := false
+	for _, row := range req.Rows {
+		if row.EmployeeID == "" && row.Email != "" {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+	if s.employees == nil {
+		return nil, apperrors.NewServiceUnavailableError("organization service not configured, cannot look employees up by email")
+	}
+
+	employees, err := s.employees.ListEmployees(req.AuthToken, orgID.String())
+	if errors.Is(err, organization.ErrUnavailable) || errors.Is(err, organization.ErrNotConfigured) {
+		return nil, apperrors.NewServiceUnavailableError("organization service unavailable, cannot look employees up by email")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uuid.UUID, len(employees))
+	for _, employee := range employees {
+		id, err := uuid.Parse(employee.ID)
+		if err != nil || employee.Email == "" {
+			continue
+		}
+		ids[strings.ToLower(employee.Email)] = id
+	}
+	return ids, nil
+}
+
+// bulkAdjustRow validates and applies one row of a bulk adjustment
+func (s *leaveService) bulkAdjustRow(orgID uuid.UUID, req *domain.BulkAdjustmentRequest, row domain.BulkAdjustmentRow,
+	typesByKey map[string]*domain.LeaveType, emails map[string]uuid.UUID) domain.BulkAdjustmentRowResult {
+	result := domain.BulkAdjustmentRowResult{Line: row.Line, Status: domain.BulkAdjustmentRowFailed}
+
+	var employeeID uuid.UUID
+	switch {
+	case row.EmployeeID != "":
+		id, err := uuid.Parse(row.EmployeeID)
+		if err != nil {
+			result.Error = "invalid employee_id"
+			return result
+		}
+		employeeID = id
+	case row.Email != "":
+		id, ok := emails[strings.ToLower(row.Email)]
+		if !ok {
+			result.Error = "no employee with email " + row.Email
+			return result
+		}
+		employeeID = id
+	default:
+		result.Error = "employee_id or email is required"
+		return result
+	}
+	result.EmployeeID = &employeeID
+
+	leaveType, ok := typesByKey[strings.ToLower(row.LeaveType)]
+	if !ok {
+		result.Error = "unknown leave type " + strconv.Quote(row.LeaveType)
+		return result
+	}
+	result.LeaveTypeID = &leaveType.ID
+
+	adjustment, err := strconv.ParseFloat(row.Adjustment, 64)
+	if err != nil {
+		result.Error = "adjustment must be a number"
+		return result
+	}
+	if adjustment == 0 {
+		result.Error = "adjustment must not be zero"
+		return result
+	}
+	if n := len([]rune(row.Reason)); n < 5 || n > 500 {
+		result.Error = "reason must be 5 to 500 characters"
+		return result
+	}
+
+	balance, err := s.leaveRepo.GetLeaveBalance(employeeID, leaveType.ID, req.Year)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && balance.OrganizationID != orgID) {
+		result.Error = "no " + leaveType.Name + " balance for the year"
+		return result
+	}
+	if err != nil {
+		log.Printf("Error: bulk adjustment cannot load the balance of line %d in %s: %v", row.Line, orgID, err)
+		result.Error = "cannot load the balance"
+		return result
+	}
+
+	created, err := s.CreateBalanceAdjustment(orgID, &domain.CreateBalanceAdjustmentRequest{
+		LeaveBalanceID: balance.ID,
+		Adjustment:     adjustment,
+		Reason:         row.Reason,
+		AutoApprove:    req.AutoApprove,
+		OverrideCap:    req.OverrideCap,
+		PerformedBy:    req.PerformedBy,
+		CallerRole:     req.CallerRole,
+	})
+	var appErr *apperrors.AppError
+	switch {
+	case errors.As(err, &appErr):
+		result.Error = appErr.Message
+		return result
+	case err != nil:
+		log.Printf("Error: bulk adjustment cannot apply line %d in %s: %v", row.Line, orgID, err)
+		result.Error = "cannot record the adjustment"
+		return result
+	}
+
+	result.Status = domain.BulkAdjustmentRowCreated
+	result.AdjustmentID = &created.ID
+	result.AdjustmentStatus = created.Status
+	return result
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+)
+
+func TestNormalizeChangesParams(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: domain.DefaultChangesLimit},
+		{limit: -3, want: domain.DefaultChangesLimit},
+		{limit: 1, want: 1},
+		{limit: 250, want: 250},
+		{limit: domain.MaxChangesLimit, want: domain.MaxChangesLimit},
+		{limit: domain.MaxChangesLimit + 1, want: domain.MaxChangesLimit},
+		{limit: 50000, want: domain.MaxChangesLimit},
+	}
+
+	for _, tt := range tests {
+		params := &domain.ChangesParams{Limit: tt.limit}
+		normalizeChangesParams(params)
+		if params.Limit != tt.want {
+			t.Errorf("limit %d: got %d, want %d", tt.limit, params.Limit, tt.want)
+		}
+	}
+}
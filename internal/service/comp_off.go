@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateCompOffRequest claims comp-off for a weekend or holiday the
+// employee worked. The organization must have a comp-off leave type, the
+// date must be a non-working day in the past per its calendar, and an
+// employee claims a date once unless the claim was rejected.
+func (s *leaveService) CreateCompOffRequest(orgID uuid.UUID, req *domain.CreateCompOffRequest) (*domain.CompOffRequest, error) {
+	if (req.Days > 0) == (req.Hours > 0) {
+		return nil, apperrors.NewBadRequestError("claim either days or hours")
+	}
+	if req.Days > 0 && math.Mod(req.Days*2, 1) != 0 {
+		return nil, apperrors.NewBadRequestError("days must be 0.5 or 1")
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.CompOffLeaveTypeID == nil {
+		return nil, apperrors.NewUnprocessableError("the organization has no comp-off leave type configured")
+	}
+
+	worked := domain.TruncateToDate(req.DateWorked)
+	if worked.After(settings.Today()) {
+		return nil, apperrors.NewBadRequestError("date_worked cannot be in the future")
+	}
+	holidays, err := s.employeeHolidays(orgID, req.EmployeeID, s.employeeLocation(req.AuthToken, orgID, req.EmployeeID), worked, worked)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsNonWorkingDay(worked, holidays) {
+		return nil, apperrors.NewUnprocessableError("comp-off can only be claimed for weekends and holidays, " +
+			worked.Format("2006-01-02") + " is a working day")
+	}
+
+	request := &domain.CompOffRequest{
+		OrganizationID: orgID,
+		EmployeeID:     req.EmployeeID,
+		DateWorked:     worked,
+		Days:           req.Days,
+		Hours:          req.Hours,
+		Reason:         req.Reason,
+		Status:         domain.CompOffStatusPending,
+		RequestedBy:    req.RequestedBy,
+	}
+	if err := s.leaveRepo.CreateCompOffRequest(request); err != nil {
+		if errors.Is(err, repository.ErrDuplicateCompOff) {
+			return nil, apperrors.NewConflictError("comp-off was already requested for " + worked.Format("2006-01-02"))
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// GetCompOffRequest returns a comp-off request of the organization
+func (s *leaveService) GetCompOffRequest(orgID, id uuid.UUID) (*domain.CompOffRequest, error) {
+	request, err := s.leaveRepo.GetCompOffRequest(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && request.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("comp-off request not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListCompOffRequests lists the organization's comp-off requests, oldest
+// first
+func (s *leaveService) ListCompOffRequests(orgID uuid.UUID, params *domain.ListCompOffRequestsParams) ([]domain.CompOffRequest, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+	switch params.Status {
+	case "", domain.CompOffStatusPending, domain.CompOffStatusApproved,
+		domain.CompOffStatusRejected, domain.CompOffStatusExpired:
+	default:
+		return nil, 0, apperrors.NewBadRequestError("invalid status")
+	}
+	return s.leaveRepo.ListCompOffRequests(orgID, params)
+}
+
+// ApproveCompOffRequest approves a pending claim and credits it to the
+// employee's balance of the comp-off leave type for the current year, in
+// that type's unit. With an expiry configured, the credit lapses that many
+// days after approval. Only admins decide claims for themselves.
+func (s *leaveService) ApproveCompOffRequest(orgID, id, approverID uuid.UUID, role, comments string) (*domain.CompOffRequest, error) {
+	request, err := s.decidableCompOffRequest(orgID, id, approverID, role, "approved")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.CompOffLeaveTypeID == nil {
+		return nil, apperrors.NewUnprocessableError("the organization has no comp-off leave type configured")
+	}
+	leaveType, err := s.GetLeaveType(orgID, *settings.CompOffLeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+	opening, err := s.entitlement(orgID, leaveType)
+	if err != nil {
+		return nil, err
+	}
+	credit := domain.CompOffCredit(leaveType, request.Days, request.Hours, settings.HoursPerDay)
+
+	now := time.Now()
+	today := settings.Today()
+	request.Status = domain.CompOffStatusApproved
+	request.DecidedBy = &approverID
+	request.DecidedAt = &now
+	if comments != "" {
+		request.Comments = comments
+	}
+	if settings.CompOffExpiryDays > 0 {
+		expiresOn := today.AddDate(0, 0, settings.CompOffExpiryDays)
+		request.ExpiresOn = &expiresOn
+	}
+
+	if err := s.leaveRepo.ApproveCompOffRequest(request, leaveType.ID, today.Year(), opening, credit); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only pending comp-off requests can be approved")
+		}
+		return nil, err
+	}
+
+	s.recordBalanceMutation(orgID, credit, domain.BalanceMutationAdjustment)
+	return request, nil
+}
+
+// RejectCompOffRequest rejects a pending claim; the date can be claimed
+// again
+func (s *leaveService) RejectCompOffRequest(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.CompOffRequest, error) {
+	request, err := s.decidableCompOffRequest(orgID, id, rejectorID, role, "rejected")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = domain.CompOffStatusRejected
+	request.DecidedBy = &rejectorID
+	request.DecidedAt = &now
+	if comments != "" {
+		request.Comments = comments
+	}
+
+	if err := s.leaveRepo.RejectCompOffRequest(request); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only pending comp-off requests can be rejected")
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// decidableCompOffRequest loads a pending comp-off request the caller may
+// decide
+func (s *leaveService) decidableCompOffRequest(orgID, id, deciderID uuid.UUID, role, verb string) (*domain.CompOffRequest, error) {
+	request, err := s.GetCompOffRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !request.CanDecide() {
+		return nil, apperrors.NewInvalidStatusError("only pending comp-off requests can be " + verb)
+	}
+	if request.EmployeeID == deciderID && role != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can decide their own comp-off requests")
+	}
+	return request, nil
+}
+
+// ExpireCompOff lapses the organization's approved comp-off whose last
+// usable day passed, forfeiting what is left of each credit with a system
+// adjustment; failures are logged and left for the next run.
+func (s *leaveService) ExpireCompOff(orgID uuid.UUID) (*domain.CompOffExpirySummary, error) {
+	today, err := s.Today(orgID)
+	if err != nil {
+		return nil, err
+	}
+	// Expiry dates are stored as plain dates
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	ids, err := s.leaveRepo.ListExpiredCompOffIDs(orgID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.CompOffExpirySummary{}
+	for _, id := range ids {
+		adjustment, err := s.leaveRepo.ExpireCompOff(id, today)
+		if err != nil {
+			log.Printf("Error: cannot expire comp-off %s: %v", id, err)
+			summary.Failed++
+			continue
+		}
+		summary.Expired++
+		if adjustment != nil {
+			summary.Forfeited++
+			summary.ForfeitedDays -= adjustment.Adjustment
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// newCompOffService returns a service whose organization credits comp-off
+// to a leave type with no allowance of its own, in unit
+func newCompOffService(orgID uuid.UUID, unit string) (*leaveService, *fakeRepository, *domain.LeaveType) {
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	leaveType.Name = "Comp-off"
+	leaveType.DefaultDays = 0
+	leaveType.Unit = unit
+	repo.settings.CompOffLeaveTypeID = &leaveType.ID
+	return newTestService(repo), repo, leaveType
+}
+
+// lastWeekday is the most recent day before today falling on weekday
+func lastWeekday(weekday time.Weekday) time.Time {
+	day := domain.TruncateToDate(time.Now()).AddDate(0, 0, -1)
+	for day.Weekday() != weekday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// claimCompOff files a pending comp-off claim for the last Saturday worked
+func claimCompOff(t *testing.T, svc *leaveService, orgID, employeeID uuid.UUID, days, hours float64) *domain.CompOffRequest {
+	t.Helper()
+	request, err := svc.CreateCompOffRequest(orgID, &domain.CreateCompOffRequest{
+		EmployeeID:  employeeID,
+		DateWorked:  lastWeekday(time.Saturday),
+		Days:        days,
+		Hours:       hours,
+		Reason:      "Weekend release",
+		RequestedBy: employeeID,
+	})
+	if err != nil {
+		t.Fatalf("claiming comp-off: %v", err)
+	}
+	return request
+}
+
+func TestCreateCompOffRequest(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, _ := newCompOffService(orgID, domain.LeaveUnitDays)
+	employeeID := uuid.New()
+
+	request := claimCompOff(t, svc, orgID, employeeID, 1, 0)
+	if request.Status != domain.CompOffStatusPending || repo.compOffs[request.ID] == nil {
+		t.Fatalf("claim was not saved pending: %+v", request)
+	}
+
+	tests := []struct {
+		name       string
+		dateWorked time.Time
+		days       float64
+		hours      float64
+		want       int
+	}{
+		{name: "working day", dateWorked: lastWeekday(time.Wednesday), days: 1, want: http.StatusUnprocessableEntity},
+		{name: "same day again", dateWorked: lastWeekday(time.Saturday), days: 1, want: http.StatusConflict},
+		{name: "future day", dateWorked: domain.TruncateToDate(time.Now()).AddDate(0, 0, 7), days: 1, want: http.StatusBadRequest},
+		{name: "days and hours", dateWorked: lastWeekday(time.Sunday), days: 1, hours: 4, want: http.StatusBadRequest},
+		{name: "quarter day", dateWorked: lastWeekday(time.Sunday), days: 0.25, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateCompOffRequest(orgID, &domain.CreateCompOffRequest{
+				EmployeeID:  employeeID,
+				DateWorked:  tt.dateWorked,
+				Days:        tt.days,
+				Hours:       tt.hours,
+				Reason:      "Weekend release",
+				RequestedBy: employeeID,
+			})
+			if got := httpStatus(err); got != tt.want {
+				t.Errorf("got %d (%v), want %d", got, err, tt.want)
+			}
+		})
+	}
+
+	// A rejected claim frees the date
+	if _, err := svc.RejectCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, "not agreed"); err != nil {
+		t.Fatalf("rejecting: %v", err)
+	}
+	claimCompOff(t, svc, orgID, employeeID, 1, 0)
+}
+
+func TestCreateCompOffRequestWithoutLeaveType(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, _ := newCompOffService(orgID, domain.LeaveUnitDays)
+	repo.settings.CompOffLeaveTypeID = nil
+
+	_, err := svc.CreateCompOffRequest(orgID, &domain.CreateCompOffRequest{
+		EmployeeID: uuid.New(),
+		DateWorked: lastWeekday(time.Saturday),
+		Days:       1,
+		Reason:     "Weekend release",
+	})
+	if got := httpStatus(err); got != http.StatusUnprocessableEntity {
+		t.Errorf("got %d (%v), want 422", got, err)
+	}
+}
+
+func TestApproveCompOffRequestCreditsBalance(t *testing.T) {
+	tests := []struct {
+		name       string
+		unit       string
+		days       float64
+		hours      float64
+		wantCredit float64
+	}{
+		{name: "days to days", unit: domain.LeaveUnitDays, days: 0.5, wantCredit: 0.5},
+		{name: "hours to days", unit: domain.LeaveUnitDays, hours: 4, wantCredit: 0.5},
+		{name: "days to hours", unit: domain.LeaveUnitHours, days: 1, wantCredit: domain.DefaultHoursPerDay},
+		{name: "hours to hours", unit: domain.LeaveUnitHours, hours: 3, wantCredit: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			svc, repo, leaveType := newCompOffService(orgID, tt.unit)
+			repo.settings.CompOffExpiryDays = 90
+			employeeID := uuid.New()
+			request := claimCompOff(t, svc, orgID, employeeID, tt.days, tt.hours)
+
+			approverID := uuid.New()
+			approved, err := svc.ApproveCompOffRequest(orgID, request.ID, approverID, domain.RoleManager, "thanks")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if approved.Status != domain.CompOffStatusApproved || approved.DecidedBy == nil || *approved.DecidedBy != approverID ||
+				approved.Comments != "thanks" {
+				t.Errorf("approved = %+v", approved)
+			}
+			if approved.Credited == nil || *approved.Credited != tt.wantCredit {
+				t.Errorf("credited %v, want %v", approved.Credited, tt.wantCredit)
+			}
+			wantExpiry := repo.settings.Today().AddDate(0, 0, 90)
+			if approved.ExpiresOn == nil || !approved.ExpiresOn.Equal(wantExpiry) {
+				t.Errorf("expires on %v, want %s", approved.ExpiresOn, wantExpiry.Format("2006-01-02"))
+			}
+
+			balances, _ := repo.ListLeaveBalances(orgID, employeeID, repo.settings.Today().Year())
+			if len(balances) != 1 || balances[0].LeaveTypeID != leaveType.ID || balances[0].TotalDays != tt.wantCredit {
+				t.Errorf("balances = %+v, want %v on the comp-off type", balances, tt.wantCredit)
+			}
+		})
+	}
+}
+
+func TestApproveCompOffRequestAddsToExistingBalance(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, leaveType := newCompOffService(orgID, domain.LeaveUnitDays)
+	employeeID := uuid.New()
+	repo.balances = []domain.LeaveBalance{{
+		Base:        domain.Base{ID: uuid.New()},
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveType.ID,
+		Year:        repo.settings.Today().Year(),
+		TotalDays:   1.5,
+		UsedDays:    1,
+	}}
+
+	request := claimCompOff(t, svc, orgID, employeeID, 1, 0)
+	approved, err := svc.ApproveCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.balances) != 1 || repo.balances[0].TotalDays != 2.5 {
+		t.Errorf("balances = %+v, want the existing one at 2.5", repo.balances)
+	}
+	if *approved.LeaveBalanceID != repo.balances[0].ID {
+		t.Errorf("credited balance %s, want %s", *approved.LeaveBalanceID, repo.balances[0].ID)
+	}
+	// Without an expiry the credit never lapses
+	if approved.ExpiresOn != nil {
+		t.Errorf("expires on %v, want never", approved.ExpiresOn)
+	}
+}
+
+func TestCompOffDecidedOnce(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, _ := newCompOffService(orgID, domain.LeaveUnitDays)
+	employeeID := uuid.New()
+	request := claimCompOff(t, svc, orgID, employeeID, 1, 0)
+
+	if _, err := svc.ApproveCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, ""); err != nil {
+		t.Fatalf("first approval: %v", err)
+	}
+	_, err := svc.ApproveCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, "")
+	if got := httpStatus(err); got != http.StatusConflict {
+		t.Errorf("second approval got %d (%v), want 409", got, err)
+	}
+	_, err = svc.RejectCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, "")
+	if got := httpStatus(err); got != http.StatusConflict {
+		t.Errorf("rejecting an approved claim got %d (%v), want 409", got, err)
+	}
+	if len(repo.balances) != 1 || repo.balances[0].TotalDays != 1 {
+		t.Errorf("balances = %+v, want a single credit of 1", repo.balances)
+	}
+}
+
+func TestCompOffDecidedConcurrently(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, _ := newCompOffService(orgID, domain.LeaveUnitDays)
+	request := claimCompOff(t, svc, orgID, uuid.New(), 1, 0)
+
+	// Another approver decides the claim between the check and the save
+	svc.leaveRepo = &racingCompOffRepository{fakeRepository: repo}
+	_, err := svc.ApproveCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, "")
+	if got := httpStatus(err); got != http.StatusConflict {
+		t.Errorf("got %d (%v), want 409", got, err)
+	}
+	if len(repo.balances) != 0 {
+		t.Errorf("balances = %+v, want none credited", repo.balances)
+	}
+}
+
+// racingCompOffRepository rejects a claim right after the service loaded
+// it, as a concurrent decision would
+type racingCompOffRepository struct {
+	*fakeRepository
+}
+
+func (r *racingCompOffRepository) GetCompOffRequest(id uuid.UUID) (*domain.CompOffRequest, error) {
+	request, err := r.fakeRepository.GetCompOffRequest(id)
+	if err == nil {
+		r.compOffs[id].Status = domain.CompOffStatusRejected
+	}
+	return request, err
+}
+
+func TestCompOffSelfDecision(t *testing.T) {
+	orgID := uuid.New()
+	svc, _, _ := newCompOffService(orgID, domain.LeaveUnitDays)
+	managerID := uuid.New()
+	request := claimCompOff(t, svc, orgID, managerID, 1, 0)
+
+	_, err := svc.ApproveCompOffRequest(orgID, request.ID, managerID, domain.RoleManager, "")
+	if got := httpStatus(err); got != http.StatusForbidden {
+		t.Errorf("manager approving their own claim got %d (%v), want 403", got, err)
+	}
+	if _, err := svc.ApproveCompOffRequest(orgID, request.ID, managerID, domain.RoleAdmin, ""); err != nil {
+		t.Errorf("admin approving their own claim: %v", err)
+	}
+}
+
+func TestExpireCompOffForfeitsUnusedDays(t *testing.T) {
+	orgID := uuid.New()
+	svc, repo, leaveType := newCompOffService(orgID, domain.LeaveUnitDays)
+	repo.settings.CompOffExpiryDays = 30
+	today := repo.settings.Today()
+
+	// Each employee earned a day; they used none, half or all of it
+	unused, halfUsed, allUsed, current := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	for _, employeeID := range []uuid.UUID{unused, halfUsed, allUsed, current} {
+		request := claimCompOff(t, svc, orgID, employeeID, 1, 0)
+		if _, err := svc.ApproveCompOffRequest(orgID, request.ID, uuid.New(), domain.RoleManager, ""); err != nil {
+			t.Fatalf("approving: %v", err)
+		}
+		if employeeID != current {
+			lapsed := today.AddDate(0, 0, -1)
+			repo.compOffs[request.ID].ExpiresOn = &lapsed
+		}
+	}
+	for i := range repo.balances {
+		switch repo.balances[i].EmployeeID {
+		case halfUsed:
+			repo.balances[i].UsedDays = 0.5
+		case allUsed:
+			repo.balances[i].UsedDays = 1
+		}
+	}
+
+	summary, err := svc.ExpireCompOff(orgID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := domain.CompOffExpirySummary{Expired: 3, Forfeited: 2, ForfeitedDays: 1.5}
+	if *summary != want {
+		t.Errorf("summary = %+v, want %+v", *summary, want)
+	}
+
+	wantTotals := map[uuid.UUID]float64{unused: 0, halfUsed: 0.5, allUsed: 1, current: 1}
+	for _, balance := range repo.balances {
+		if balance.LeaveTypeID != leaveType.ID {
+			continue
+		}
+		if balance.TotalDays != wantTotals[balance.EmployeeID] {
+			t.Errorf("balance of %s is %v, want %v", balance.EmployeeID, balance.TotalDays, wantTotals[balance.EmployeeID])
+		}
+	}
+	for _, request := range repo.compOffs {
+		wantStatus := domain.CompOffStatusExpired
+		if request.EmployeeID == current {
+			wantStatus = domain.CompOffStatusApproved
+		}
+		if request.Status != wantStatus {
+			t.Errorf("claim of %s is %s, want %s", request.EmployeeID, request.Status, wantStatus)
+		}
+	}
+
+	// A second run finds nothing left to expire
+	summary, err = svc.ExpireCompOff(orgID)
+	if err != nil || *summary != (domain.CompOffExpirySummary{}) {
+		t.Errorf("second run = %+v, %v", summary, err)
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// syncDelegation keeps the delegation of a request's approvals in step with
+// the request after a change of status. Approved leave of an approver,
+// meaning a member of any of the organization's approver pools, proposes a
+// delegation to another member; leave no longer approved withdraws it. The
+// request is saved by then, so failures are only logged.
+func (s *leaveService) syncDelegation(leaveRequest *domain.LeaveRequest) {
+	if err := s.syncDelegationOf(leaveRequest); err != nil {
+		log.Printf("Error: cannot update the delegation of leave request %s: %v", leaveRequest.ID, err)
+	}
+}
+
+func (s *leaveService) syncDelegationOf(leaveRequest *domain.LeaveRequest) error {
+	onLeave := leaveRequest.Status == domain.LeaveStatusApproved ||
+		leaveRequest.Status == domain.LeaveStatusCancellationRequested
+
+	delegation, err := s.leaveRepo.GetLeaveRequestDelegation(leaveRequest.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		delegation = nil
+	} else if err != nil {
+		return err
+	}
+
+	if !onLeave {
+		if delegation == nil || !delegation.IsActive() {
+			return nil
+		}
+		delegation.Status = domain.DelegationStatusWithdrawn
+		return s.leaveRepo.UpdateDelegation(delegation, (*domain.Delegation).IsActive)
+	}
+
+	if delegation != nil && delegation.IsActive() {
+		if delegation.StartDate.Equal(leaveRequest.StartDate) && delegation.EndDate.Equal(leaveRequest.EndDate) {
+			return nil
+		}
+		delegation.StartDate = leaveRequest.StartDate
+		delegation.EndDate = leaveRequest.EndDate
+		return s.leaveRepo.UpdateDelegation(delegation, (*domain.Delegation).IsActive)
+	}
+	// Declined delegations stay declined for the leave
+	if delegation != nil && !delegation.IsWithdrawn() {
+		return nil
+	}
+
+	delegate, approver, err := s.suggestDelegate(leaveRequest.OrganizationID, leaveRequest.EmployeeID)
+	if err != nil || !approver {
+		return err
+	}
+	settings, err := s.GetOrganizationSettings(leaveRequest.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	var confirmBy *time.Time
+	if settings.DelegationConfirmDays > 0 {
+		deadline := time.Now().AddDate(0, 0, settings.DelegationConfirmDays)
+		confirmBy = &deadline
+	}
+
+	if delegation != nil {
+		// Leave reopened and approved again after being cancelled proposes
+		// its withdrawn delegation anew
+		delegation.Status = domain.DelegationStatusProposed
+		delegation.DelegateID = delegate
+		delegation.StartDate = leaveRequest.StartDate
+		delegation.EndDate = leaveRequest.EndDate
+		delegation.ConfirmBy = confirmBy
+		delegation.EscalatedAt = nil
+		delegation.DecidedBy = nil
+		delegation.DecidedAt = nil
+		if err := s.leaveRepo.UpdateDelegation(delegation, (*domain.Delegation).IsWithdrawn); err != nil {
+			return err
+		}
+	} else {
+		delegation = &domain.Delegation{
+			OrganizationID: leaveRequest.OrganizationID,
+			LeaveRequestID: leaveRequest.ID,
+			DelegatorID:    leaveRequest.EmployeeID,
+			DelegateID:     delegate,
+			Status:         domain.DelegationStatusProposed,
+			StartDate:      leaveRequest.StartDate,
+			EndDate:        leaveRequest.EndDate,
+			ConfirmBy:      confirmBy,
+		}
+		created, err := s.leaveRepo.CreateDelegation(delegation)
+		if err != nil || !created {
+			return err
+		}
+	}
+
+	s.notifyDelegation(settings.DelegationWebhookURL, domain.DelegationProposedEvent, delegation)
+	return nil
+}
+
+// suggestDelegate reports whether the employee is an approver and returns
+// the first other member of their approver pools, nil when there is none
+func (s *leaveService) suggestDelegate(orgID, employeeID uuid.UUID) (*uuid.UUID, bool, error) {
+	pools, err := s.leaveRepo.ListApproverPools(orgID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	approver := false
+	var delegate *uuid.UUID
+	for _, pool := range pools {
+		member := false
+		for _, m := range pool.Members {
+			if m.UserID == employeeID {
+				member = true
+				break
+			}
+		}
+		if !member {
+			continue
+		}
+		approver = true
+		for _, m := range pool.Members {
+			if delegate == nil && m.UserID != employeeID {
+				userID := m.UserID
+				delegate = &userID
+				break
+			}
+		}
+	}
+	return delegate, approver, nil
+}
+
+// notifyDelegation posts a delegation event to the organization's webhook,
+// if it has one. Failing to is only logged.
+func (s *leaveService) notifyDelegation(webhookURL, event string, delegation *domain.Delegation) bool {
+	if webhookURL == "" {
+		return false
+	}
+	err := s.webhooks.Post(webhookURL, domain.DelegationNotification{
+		Event:      event,
+		Delegation: delegation,
+	})
+	if err != nil {
+		log.Printf("Error: cannot send %s for delegation %s in organization %s: %v",
+			event, delegation.ID, delegation.OrganizationID, err)
+		return false
+	}
+	now := time.Now()
+	if err := s.leaveRepo.MarkDelegationNotified(delegation.ID, now); err != nil {
+		log.Printf("Warning: cannot mark delegation %s notified: %v", delegation.ID, err)
+	}
+	delegation.NotifiedAt = &now
+	return true
+}
+
+// ListDelegations lists the organization's delegations by start date
+func (s *leaveService) ListDelegations(orgID uuid.UUID, params *domain.ListDelegationsParams) ([]domain.Delegation, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+	switch params.Status {
+	case "", domain.DelegationStatusProposed, domain.DelegationStatusEscalated, domain.DelegationStatusConfirmed,
+		domain.DelegationStatusDeclined, domain.DelegationStatusWithdrawn:
+	default:
+		return nil, 0, apperrors.NewBadRequestError("invalid status")
+	}
+	return s.leaveRepo.ListDelegations(orgID, params)
+}
+
+// ConfirmDelegation confirms a proposed or escalated delegation, to the
+// suggested delegate unless the request names another. From then on the
+// delegate may act on what the delegator could during the leave.
+func (s *leaveService) ConfirmDelegation(orgID, id, userID uuid.UUID, role string, req *domain.ConfirmDelegationRequest) (*domain.Delegation, error) {
+	delegation, err := s.getDelegation(orgID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !delegation.IsOpen() {
+		return nil, apperrors.NewInvalidStatusError("only proposed or escalated delegations can be confirmed")
+	}
+
+	delegateID := req.DelegateID
+	if delegateID == nil {
+		delegateID = delegation.DelegateID
+	}
+	if delegateID == nil {
+		return nil, apperrors.NewBadRequestError("delegate_id is required, no delegate was suggested")
+	}
+	if err := s.checkDelegate(orgID, req.AuthToken, delegation, *delegateID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delegation.Status = domain.DelegationStatusConfirmed
+	delegation.DelegateID = delegateID
+	delegation.DecidedBy = &userID
+	delegation.DecidedAt = &now
+
+	if err := s.leaveRepo.UpdateDelegation(delegation, (*domain.Delegation).IsOpen); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only proposed or escalated delegations can be confirmed")
+		}
+		return nil, err
+	}
+	return delegation, nil
+}
+
+// ChangeDelegate hands an open or confirmed delegation to another delegate.
+// A proposal stays to be confirmed.
+func (s *leaveService) ChangeDelegate(orgID, id, userID uuid.UUID, role string, req *domain.ChangeDelegateRequest) (*domain.Delegation, error) {
+	delegation, err := s.getDelegation(orgID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !delegation.IsActive() {
+		return nil, apperrors.NewInvalidStatusError("only proposed, escalated or confirmed delegations can change delegate")
+	}
+	if err := s.checkDelegate(orgID, req.AuthToken, delegation, req.DelegateID); err != nil {
+		return nil, err
+	}
+
+	delegation.DelegateID = &req.DelegateID
+	if delegation.Status == domain.DelegationStatusConfirmed {
+		now := time.Now()
+		delegation.DecidedBy = &userID
+		delegation.DecidedAt = &now
+	}
+
+	if err := s.leaveRepo.UpdateDelegation(delegation, (*domain.Delegation).IsActive); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only proposed, escalated or confirmed delegations can change delegate")
+		}
+		return nil, err
+	}
+	return delegation, nil
+}
+
+// DeclineDelegation records that the delegator's approvals wait for their
+// return instead of being delegated
+func (s *leaveService) DeclineDelegation(orgID, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+	delegation, err := s.getDelegation(orgID, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !delegation.IsOpen() {
+		return nil, apperrors.NewInvalidStatusError("only proposed or escalated delegations can be declined")
+	}
+
+	now := time.Now()
+	delegation.Status = domain.DelegationStatusDeclined
+	delegation.DecidedBy = &userID
+	delegation.DecidedAt = &now
+
+	if err := s.leaveRepo.UpdateDelegation(delegation, (*domain.Delegation).IsOpen); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, apperrors.NewInvalidStatusError("only proposed or escalated delegations can be declined")
+		}
+		return nil, err
+	}
+	return delegation, nil
+}
+
+// EscalateDelegations escalates the proposals of every organization that
+// were not confirmed by their deadline, notifying each organization's
+// webhook so the delegator's manager can pick a delegate. It is meant to be
+// run on a schedule.
+func (s *leaveService) EscalateDelegations() (*domain.DelegationEscalation, error) {
+	now := time.Now()
+	overdue, err := s.leaveRepo.ListOverdueDelegations(now)
+	if err != nil {
+		return nil, err
+	}
+
+	escalation := &domain.DelegationEscalation{}
+	webhooks := make(map[uuid.UUID]string)
+	for i := range overdue {
+		delegation := &overdue[i]
+		delegation.Status = domain.DelegationStatusEscalated
+		delegation.EscalatedAt = &now
+		err := s.leaveRepo.UpdateDelegation(delegation, func(current *domain.Delegation) bool {
+			return current.IsOverdue(now)
+		})
+		if errors.Is(err, repository.ErrStatusChanged) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Error: cannot escalate delegation %s: %v", delegation.ID, err)
+			continue
+		}
+		escalation.Escalated++
+		log.Printf("Delegation %s of approver %s in organization %s escalated, not confirmed by %s",
+			delegation.ID, delegation.DelegatorID, delegation.OrganizationID, delegation.ConfirmBy.Format(time.RFC3339))
+
+		webhookURL, ok := webhooks[delegation.OrganizationID]
+		if !ok {
+			settings, err := s.GetOrganizationSettings(delegation.OrganizationID)
+			if err != nil {
+				log.Printf("Error: cannot read the settings of organization %s: %v", delegation.OrganizationID, err)
+			} else {
+				webhookURL = settings.DelegationWebhookURL
+			}
+			webhooks[delegation.OrganizationID] = webhookURL
+		}
+		if webhookURL == "" {
+			continue
+		}
+		if s.notifyDelegation(webhookURL, domain.DelegationEscalatedEvent, delegation) {
+			escalation.Notified++
+		} else {
+			escalation.NotifyFailed++
+		}
+	}
+	return escalation, nil
+}
+
+// getDelegation returns a delegation of the organization the user may
+// decide: the delegator's own, or any for HR and admins
+func (s *leaveService) getDelegation(orgID, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+	delegation, err := s.leaveRepo.GetDelegation(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && delegation.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("delegation not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if delegation.DelegatorID != userID && role != domain.RoleAdmin && role != domain.RoleHR {
+		return nil, apperrors.NewForbiddenError("only the delegator, HR or admins can decide a delegation")
+	}
+	return delegation, nil
+}
+
+// checkDelegate verifies the delegate is another employee of the
+// organization
+func (s *leaveService) checkDelegate(orgID uuid.UUID, authToken string, delegation *domain.Delegation, delegateID uuid.UUID) error {
+	if delegateID == delegation.DelegatorID {
+		return apperrors.NewBadRequestError("approvals cannot be delegated to the delegator")
+	}
+	_, err := s.verifyEmployee(authToken, orgID, delegateID)
+	return err
+}
+
+// delegatorsOf returns the approvers whose confirmed delegations to the
+// user cover today. Delegations only widen who may approve, so failing to
+// read them is logged and none are used.
+func (s *leaveService) delegatorsOf(orgID, userID uuid.UUID) []uuid.UUID {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		log.Printf("Warning: cannot read the settings of organization %s: %v", orgID, err)
+		return nil
+	}
+	delegators, err := s.leaveRepo.ListDelegators(orgID, userID, settings.Today())
+	if err != nil {
+		log.Printf("Warning: cannot list the delegations to %s: %v", userID, err)
+		return nil
+	}
+	return delegators
+}
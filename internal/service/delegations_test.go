@@ -0,0 +1,376 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// newDelegationService returns a service whose approver pool holds the
+// approver and then the colleague, with HR approving leave of the returned
+// type and delegations notified through the returned webhook
+func newDelegationService(orgID, approver, colleague uuid.UUID) (*leaveService, *fakeRepository, *fakeWebhook, *domain.LeaveType) {
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	repo.settings.DelegationWebhookURL = "https://hr.example.com/hooks/delegations"
+	repo.poolMembers[uuid.New()] = []uuid.UUID{approver, colleague}
+
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	leaveType.RequiresApproval = true
+	leaveType.ApprovalStrategy = domain.ApprovalStrategyRoleBased
+	leaveType.ApproverRole = domain.RoleHR
+
+	hook := &fakeWebhook{}
+	svc := newTestService(repo)
+	svc.webhooks = hook
+	return svc, repo, hook, leaveType
+}
+
+func TestApproveLeaveRequestProposesDelegation(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	svc, repo, hook, leaveType := newDelegationService(orgID, approver, colleague)
+
+	request := repo.addPendingRequest(leaveType, approver)
+	if _, err := svc.ApproveLeaveRequest(orgID, request.ID, uuid.New(), domain.RoleHR, ""); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+
+	if len(repo.delegations) != 1 {
+		t.Fatalf("%d delegations, want 1", len(repo.delegations))
+	}
+	delegation := repo.delegations[0]
+	if delegation.Status != domain.DelegationStatusProposed || delegation.DelegatorID != approver ||
+		delegation.LeaveRequestID != request.ID {
+		t.Errorf("delegation %+v, want proposed for the approver's request", delegation)
+	}
+	if delegation.DelegateID == nil || *delegation.DelegateID != colleague {
+		t.Errorf("suggested delegate %v, want the other pool member %s", delegation.DelegateID, colleague)
+	}
+	if !delegation.StartDate.Equal(request.StartDate) || !delegation.EndDate.Equal(request.EndDate) {
+		t.Errorf("delegation runs %s to %s, want the leave's %s to %s",
+			delegation.StartDate, delegation.EndDate, request.StartDate, request.EndDate)
+	}
+	deadline := time.Now().AddDate(0, 0, domain.DefaultDelegationConfirmDays)
+	if delegation.ConfirmBy == nil || delegation.ConfirmBy.Sub(deadline).Abs() > time.Minute {
+		t.Errorf("confirm by %v, want about %s", delegation.ConfirmBy, deadline)
+	}
+
+	if len(hook.payloads) != 1 {
+		t.Fatalf("%d webhook posts, want 1", len(hook.payloads))
+	}
+	if n := hook.payloads[0].(domain.DelegationNotification); n.Event != domain.DelegationProposedEvent {
+		t.Errorf("event %q, want %q", n.Event, domain.DelegationProposedEvent)
+	}
+	if delegation.NotifiedAt == nil {
+		t.Error("delegation not marked notified")
+	}
+
+	// Employees outside the approver pools delegate nothing
+	other := repo.addPendingRequest(leaveType, uuid.New())
+	if _, err := svc.ApproveLeaveRequest(orgID, other.ID, uuid.New(), domain.RoleHR, ""); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if len(repo.delegations) != 1 {
+		t.Errorf("%d delegations, want none for a non-approver", len(repo.delegations)-1)
+	}
+}
+
+func TestCreateLeaveRequestProposesDelegationWhenAutoApproved(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	svc, repo, _, leaveType := newDelegationService(orgID, approver, colleague)
+	leaveType.RequiresApproval = false
+	monday := upcomingMonday()
+
+	request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  approver,
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(repo.delegations) != 1 || repo.delegations[0].LeaveRequestID != request.ID {
+		t.Fatalf("delegations %v, want one proposed for the auto-approved request", repo.delegations)
+	}
+}
+
+func TestSyncDelegationFollowsLeave(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	svc, repo, hook, leaveType := newDelegationService(orgID, approver, colleague)
+	repo.settings.DelegationConfirmDays = 0
+
+	request := repo.addPendingRequest(leaveType, approver)
+	request.Status = domain.LeaveStatusApproved
+	svc.syncDelegation(request)
+	svc.syncDelegation(request)
+	if len(repo.delegations) != 1 || len(hook.payloads) != 1 {
+		t.Fatalf("%d delegations and %d posts after syncing twice, want 1 of each", len(repo.delegations), len(hook.payloads))
+	}
+	if repo.delegations[0].ConfirmBy != nil {
+		t.Errorf("confirm by %v, want none when the organization does not escalate", repo.delegations[0].ConfirmBy)
+	}
+
+	// A cancellation awaiting approval keeps the delegation
+	request.Status = domain.LeaveStatusCancellationRequested
+	svc.syncDelegation(request)
+	if status := repo.delegations[0].Status; status != domain.DelegationStatusProposed {
+		t.Fatalf("status %q with a cancellation awaiting approval, want proposed", status)
+	}
+
+	request.Status = domain.LeaveStatusCancelled
+	svc.syncDelegation(request)
+	if status := repo.delegations[0].Status; status != domain.DelegationStatusWithdrawn {
+		t.Fatalf("status %q after cancellation, want withdrawn", status)
+	}
+
+	// Leave reopened and approved again is proposed anew
+	request.Status = domain.LeaveStatusApproved
+	svc.syncDelegation(request)
+	if len(repo.delegations) != 1 || repo.delegations[0].Status != domain.DelegationStatusProposed {
+		t.Fatalf("delegations %v after approving again, want the one proposed anew", repo.delegations)
+	}
+	if len(hook.payloads) != 2 {
+		t.Errorf("%d webhook posts, want the new proposal notified", len(hook.payloads))
+	}
+
+	// A declined delegation is not proposed again
+	repo.delegations[0].Status = domain.DelegationStatusDeclined
+	svc.syncDelegation(request)
+	if status := repo.delegations[0].Status; status != domain.DelegationStatusDeclined {
+		t.Errorf("status %q, want declined kept", status)
+	}
+}
+
+func TestCancelLeaveRequestWithdrawsDelegation(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	svc, repo, _, leaveType := newDelegationService(orgID, approver, colleague)
+
+	request := repo.addPendingRequest(leaveType, approver)
+	if _, err := svc.ApproveLeaveRequest(orgID, request.ID, uuid.New(), domain.RoleHR, ""); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if _, err := svc.CancelLeaveRequest(orgID, request.ID, uuid.New(), domain.RoleHR, ""); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if status := repo.delegations[0].Status; status != domain.DelegationStatusWithdrawn {
+		t.Errorf("status %q, want withdrawn", status)
+	}
+}
+
+func TestDecideDelegation(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	stranger := uuid.New()
+
+	tests := []struct {
+		name       string
+		status     string
+		userID     uuid.UUID
+		role       string
+		decide     func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error)
+		want       int
+		wantStatus string
+		wantTo     uuid.UUID
+	}{
+		{
+			name: "delegator confirms the suggestion", status: domain.DelegationStatusProposed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ConfirmDelegation(orgID, id, userID, role, &domain.ConfirmDelegationRequest{})
+			},
+			wantStatus: domain.DelegationStatusConfirmed, wantTo: colleague,
+		},
+		{
+			name: "hr confirms an escalation to another delegate", status: domain.DelegationStatusEscalated, userID: uuid.New(), role: domain.RoleHR,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ConfirmDelegation(orgID, id, userID, role, &domain.ConfirmDelegationRequest{DelegateID: &stranger})
+			},
+			wantStatus: domain.DelegationStatusConfirmed, wantTo: stranger,
+		},
+		{
+			name: "someone else cannot confirm", status: domain.DelegationStatusProposed, userID: colleague, role: domain.RoleManager,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ConfirmDelegation(orgID, id, userID, role, &domain.ConfirmDelegationRequest{})
+			},
+			want: 403,
+		},
+		{
+			name: "delegating to the delegator", status: domain.DelegationStatusProposed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ConfirmDelegation(orgID, id, userID, role, &domain.ConfirmDelegationRequest{DelegateID: &approver})
+			},
+			want: 400,
+		},
+		{
+			name: "confirming twice", status: domain.DelegationStatusConfirmed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ConfirmDelegation(orgID, id, userID, role, &domain.ConfirmDelegationRequest{})
+			},
+			want: 409,
+		},
+		{
+			name: "changing the delegate of a confirmed delegation", status: domain.DelegationStatusConfirmed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ChangeDelegate(orgID, id, userID, role, &domain.ChangeDelegateRequest{DelegateID: stranger})
+			},
+			wantStatus: domain.DelegationStatusConfirmed, wantTo: stranger,
+		},
+		{
+			name: "changing the delegate of a proposal", status: domain.DelegationStatusProposed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ChangeDelegate(orgID, id, userID, role, &domain.ChangeDelegateRequest{DelegateID: stranger})
+			},
+			wantStatus: domain.DelegationStatusProposed, wantTo: stranger,
+		},
+		{
+			name: "changing the delegate of a withdrawn delegation", status: domain.DelegationStatusWithdrawn, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.ChangeDelegate(orgID, id, userID, role, &domain.ChangeDelegateRequest{DelegateID: stranger})
+			},
+			want: 409,
+		},
+		{
+			name: "delegator declines", status: domain.DelegationStatusProposed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.DeclineDelegation(orgID, id, userID, role)
+			},
+			wantStatus: domain.DelegationStatusDeclined, wantTo: colleague,
+		},
+		{
+			name: "declining a confirmed delegation", status: domain.DelegationStatusConfirmed, userID: approver,
+			decide: func(svc *leaveService, id, userID uuid.UUID, role string) (*domain.Delegation, error) {
+				return svc.DeclineDelegation(orgID, id, userID, role)
+			},
+			want: 409,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _, leaveType := newDelegationService(orgID, approver, colleague)
+			request := repo.addPendingRequest(leaveType, approver)
+			request.Status = domain.LeaveStatusApproved
+			svc.syncDelegation(request)
+			repo.delegations[0].Status = tt.status
+
+			delegation, err := tt.decide(svc, repo.delegations[0].ID, tt.userID, tt.role)
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if tt.want != 0 {
+				if repo.delegations[0].Status != tt.status {
+					t.Errorf("status %q saved, want %q kept", repo.delegations[0].Status, tt.status)
+				}
+				return
+			}
+			saved := repo.delegations[0]
+			if saved.Status != tt.wantStatus || saved.DelegateID == nil || *saved.DelegateID != tt.wantTo {
+				t.Errorf("saved %s to %v, want %s to %s", saved.Status, saved.DelegateID, tt.wantStatus, tt.wantTo)
+			}
+			if tt.wantStatus != domain.DelegationStatusProposed && (delegation.DecidedBy == nil || *delegation.DecidedBy != tt.userID) {
+				t.Errorf("decided by %v, want %s", delegation.DecidedBy, tt.userID)
+			}
+		})
+	}
+
+	t.Run("other organization", func(t *testing.T) {
+		svc, repo, _, leaveType := newDelegationService(orgID, approver, colleague)
+		request := repo.addPendingRequest(leaveType, approver)
+		request.Status = domain.LeaveStatusApproved
+		svc.syncDelegation(request)
+
+		_, err := svc.DeclineDelegation(uuid.New(), repo.delegations[0].ID, approver, "")
+		if status := httpStatus(err); status != 404 {
+			t.Fatalf("got %v (%d), want 404", err, status)
+		}
+	})
+}
+
+func TestEscalateDelegations(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	svc, repo, hook, leaveType := newDelegationService(orgID, approver, colleague)
+
+	overdue := repo.addPendingRequest(leaveType, approver)
+	overdue.Status = domain.LeaveStatusApproved
+	svc.syncDelegation(overdue)
+	upcoming := repo.addPendingRequest(leaveType, colleague)
+	upcoming.Status = domain.LeaveStatusApproved
+	svc.syncDelegation(upcoming)
+	hook.payloads = nil
+
+	past := time.Now().Add(-time.Hour)
+	repo.delegations[0].ConfirmBy = &past
+
+	escalation, err := svc.EscalateDelegations()
+	if err != nil {
+		t.Fatalf("escalate: %v", err)
+	}
+	if escalation.Escalated != 1 || escalation.Notified != 1 {
+		t.Errorf("escalation %+v, want 1 escalated and notified", escalation)
+	}
+	if status := repo.delegations[0].Status; status != domain.DelegationStatusEscalated || repo.delegations[0].EscalatedAt == nil {
+		t.Errorf("overdue delegation %s, want escalated", status)
+	}
+	if status := repo.delegations[1].Status; status != domain.DelegationStatusProposed {
+		t.Errorf("delegation not due yet %s, want proposed", status)
+	}
+	if len(hook.payloads) != 1 || hook.payloads[0].(domain.DelegationNotification).Event != domain.DelegationEscalatedEvent {
+		t.Errorf("webhook posts %v, want one %s", hook.payloads, domain.DelegationEscalatedEvent)
+	}
+
+	// Reruns escalate nothing twice
+	escalation, err = svc.EscalateDelegations()
+	if err != nil {
+		t.Fatalf("escalate again: %v", err)
+	}
+	if escalation.Escalated != 0 {
+		t.Errorf("%d escalated on the rerun, want 0", escalation.Escalated)
+	}
+}
+
+func TestDelegateApprovesForDelegator(t *testing.T) {
+	orgID, approver, colleague := uuid.New(), uuid.New(), uuid.New()
+	delegate := uuid.New()
+	svc, repo, _, leaveType := newDelegationService(orgID, approver, colleague)
+
+	// The approver's own leave, confirmed to the delegate
+	leave := repo.addPendingRequest(leaveType, approver)
+	leave.StartDate = repo.settings.Today()
+	leave.Status = domain.LeaveStatusApproved
+	svc.syncDelegation(leave)
+	if _, err := svc.ConfirmDelegation(orgID, repo.delegations[0].ID, approver, "",
+		&domain.ConfirmDelegationRequest{DelegateID: &delegate}); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	// A request of a round-robin type assigned to the approver
+	poolType := repo.addLeaveType(orgID, domain.RoundingNone)
+	poolType.RequiresApproval = true
+	poolType.ApprovalStrategy = domain.ApprovalStrategyRoundRobin
+	poolID := uuid.New()
+	poolType.ApproverPoolID = &poolID
+	request := repo.addPendingRequest(poolType, uuid.New())
+	request.AssignedApproverID = &approver
+
+	inbox, err := svc.ListPendingApprovals(orgID, delegate, domain.RoleManager)
+	if err != nil {
+		t.Fatalf("inbox: %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].ID != request.ID {
+		t.Errorf("delegate's inbox %v, want the request assigned to the delegator", inbox)
+	}
+	if _, err := svc.ApproveLeaveRequest(orgID, request.ID, colleague, domain.RoleManager, ""); httpStatus(err) != 403 {
+		t.Errorf("got %v, want 403 for a pool member without the delegation", err)
+	}
+	if _, err := svc.ApproveLeaveRequest(orgID, request.ID, delegate, domain.RoleManager, ""); err != nil {
+		t.Fatalf("delegate approves: %v", err)
+	}
+
+	// Once the leave is cancelled the delegation no longer applies
+	repo.delegations[0].Status = domain.DelegationStatusWithdrawn
+	request.Status = domain.LeaveStatusPending
+	if _, err := svc.ApproveLeaveRequest(orgID, request.ID, delegate, domain.RoleManager, ""); httpStatus(err) != 403 {
+		t.Errorf("got %v, want 403 after the delegation was withdrawn", err)
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// departmentMappingTTL is how long an organization's departments and their
+// members are reused
+const departmentMappingTTL = 5 * time.Minute
+
+// departmentMapping places an organization's employees in departments
+type departmentMapping struct {
+	names      map[uuid.UUID]string
+	byEmployee map[uuid.UUID]uuid.UUID
+	expiresAt  time.Time
+}
+
+type departmentMappingCache struct {
+	mu   sync.Mutex
+	orgs map[uuid.UUID]*departmentMapping
+}
+
+// DepartmentAnalysisReport totals per department the requests starting in
+// [from, to) and their approved days, by leave type. Employees the
+// organization service does not place in a department are grouped as
+// unassigned; when it cannot be read, the report is still returned with
+// whatever it could map and a warning.
+func (s *leaveService) DepartmentAnalysisReport(orgID uuid.UUID, from, to time.Time, token string) (*domain.DepartmentAnalysisReport, error) {
+	if !to.After(from) {
+		return nil, apperrors.NewBadRequestError("end_date must be after start_date")
+	}
+	if to.Sub(from) > maxReportRange {
+		return nil, apperrors.NewBadRequestError("report range cannot exceed 366 days")
+	}
+
+	rows, err := s.leaveRepo.ListEmployeeLeaveByType(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	mapping, warning := s.departmentMapping(token, orgID)
+
+	report := &domain.DepartmentAnalysisReport{
+		StartDate:   from,
+		EndDate:     to,
+		Departments: []domain.DepartmentLeaveStats{},
+		Warning:     warning,
+	}
+	departments := make(map[uuid.UUID]*domain.DepartmentLeaveStats)
+	byType := make(map[uuid.UUID]map[string]int)
+	for _, row := range rows {
+		departmentID := mapping.byEmployee[row.EmployeeID]
+		stats, ok := departments[departmentID]
+		if !ok {
+			stats = &domain.DepartmentLeaveStats{DepartmentName: domain.UnassignedDepartment}
+			if departmentID != uuid.Nil {
+				id := departmentID
+				stats.DepartmentID = &id
+				stats.DepartmentName = mapping.names[departmentID]
+				if stats.DepartmentName == "" {
+					stats.DepartmentName = departmentID.String()
+				}
+			}
+			departments[departmentID] = stats
+			byType[departmentID] = make(map[string]int)
+		}
+
+		stats.TotalRequests += row.Count
+		if row.Unit != domain.LeaveUnitHours {
+			stats.TotalDaysTaken += row.TotalDays
+		}
+		i, ok := byType[departmentID][row.LeaveType]
+		if !ok {
+			byType[departmentID][row.LeaveType] = len(stats.LeaveByType)
+			stats.LeaveByType = append(stats.LeaveByType, row.LeaveByType)
+			continue
+		}
+		stats.LeaveByType[i].Count += row.Count
+		stats.LeaveByType[i].TotalDays += row.TotalDays
+	}
+
+	for _, stats := range departments {
+		report.Departments = append(report.Departments, *stats)
+	}
+	sort.Slice(report.Departments, func(i, j int) bool {
+		a, b := report.Departments[i], report.Departments[j]
+		if a.TotalDaysTaken != b.TotalDaysTaken {
+			return a.TotalDaysTaken > b.TotalDaysTaken
+		}
+		return a.DepartmentName < b.DepartmentName
+	})
+	return report, nil
+}
+
+// departmentMapping returns the organization's departments and members,
+// cached for a few minutes. What the organization service fails to return
+// is left out and explained by the warning; incomplete mappings are not
+// cached.
+func (s *leaveService) departmentMapping(token string, orgID uuid.UUID) (*departmentMapping, string) {
+	now := time.Now()
+	s.departments.mu.Lock()
+	cached, ok := s.departments.orgs[orgID]
+	s.departments.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached, ""
+	}
+
+	mapping := &departmentMapping{
+		names:      make(map[uuid.UUID]string),
+		byEmployee: make(map[uuid.UUID]uuid.UUID),
+		expiresAt:  now.Add(departmentMappingTTL),
+	}
+	if s.employees == nil {
+		return mapping, "employee directory not configured, all employees are unassigned"
+	}
+
+	employees, err := s.employees.ListEmployees(token, orgID.String())
+	if err != nil {
+		log.Printf("Warning: cannot list the employees of %s for the department report: %v", orgID, err)
+		return mapping, "organization service unavailable, all employees are unassigned"
+	}
+	for _, employee := range employees {
+		employeeID, err := uuid.Parse(employee.ID)
+		if err != nil {
+			continue
+		}
+		if departmentID, err := uuid.Parse(employee.DepartmentID); err == nil {
+			mapping.byEmployee[employeeID] = departmentID
+		}
+	}
+
+	departments, err := s.employees.ListDepartments(token, orgID.String())
+	if err != nil {
+		log.Printf("Warning: cannot list the departments of %s for the department report: %v", orgID, err)
+		return mapping, "organization service unavailable, departments are named by id"
+	}
+	for _, department := range departments {
+		if departmentID, err := uuid.Parse(department.ID); err == nil {
+			mapping.names[departmentID] = department.Name
+		}
+	}
+
+	s.departments.mu.Lock()
+	s.departments.orgs[orgID] = mapping
+	s.departments.mu.Unlock()
+	return mapping, ""
+}
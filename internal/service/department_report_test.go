@@ -0,0 +1,180 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// departmentLeave seeds two engineers, a salesperson, an employee of a
+// department the organization service does not name and one without a
+// department, with their leave of the period
+func departmentLeave(repo *fakeRepository, directory *fakeDirectory) (engineering, sales, unnamed uuid.UUID) {
+	engineering, sales, unnamed = uuid.New(), uuid.New(), uuid.New()
+	directory.departments = []organization.DepartmentResponse{
+		{ID: engineering.String(), Name: "Engineering"},
+		{ID: sales.String(), Name: "Sales"},
+	}
+
+	leave := func(employeeID uuid.UUID, leaveType, unit string, count int64, days float64) domain.EmployeeLeaveByType {
+		return domain.EmployeeLeaveByType{EmployeeID: employeeID, LeaveByType: domain.LeaveByType{
+			LeaveType: leaveType, IsPaid: true, Unit: unit, Count: count, TotalDays: days}}
+	}
+	ada, grace, linus, ken, dennis := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	directory.employees[ada] = &organization.EmployeeResponse{DepartmentID: engineering.String()}
+	directory.employees[grace] = &organization.EmployeeResponse{DepartmentID: engineering.String()}
+	directory.employees[linus] = &organization.EmployeeResponse{DepartmentID: sales.String()}
+	directory.employees[ken] = &organization.EmployeeResponse{DepartmentID: unnamed.String()}
+	directory.employees[dennis] = &organization.EmployeeResponse{}
+	repo.employeeLeave = []domain.EmployeeLeaveByType{
+		leave(ada, "Annual", domain.LeaveUnitDays, 2, 4),
+		leave(grace, "Annual", domain.LeaveUnitDays, 1, 3),
+		// Hourly leave counts as requests, not days
+		leave(grace, "Medical appointment", domain.LeaveUnitHours, 3, 6),
+		leave(linus, "Annual", domain.LeaveUnitDays, 1, 10),
+		leave(ken, "Sick", domain.LeaveUnitDays, 1, 1),
+		leave(dennis, "Sick", domain.LeaveUnitDays, 2, 2),
+	}
+	return engineering, sales, unnamed
+}
+
+func TestDepartmentAnalysisReport(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	svc, directory := newDirectoryService(repo, uuid.New(), &organization.EmployeeResponse{})
+	engineering, sales, unnamed := departmentLeave(repo, directory)
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	report, err := svc.DepartmentAnalysisReport(orgID, from, from.AddDate(0, 3, 0), "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Warning != "" {
+		t.Errorf("warning = %q", report.Warning)
+	}
+
+	// Most days taken first, ties by name
+	want := []struct {
+		id       *uuid.UUID
+		name     string
+		requests int64
+		days     float64
+		types    []domain.LeaveByType
+	}{
+		{id: &sales, name: "Sales", requests: 1, days: 10, types: []domain.LeaveByType{{LeaveType: "Annual", Count: 1, TotalDays: 10}}},
+		{id: &engineering, name: "Engineering", requests: 6, days: 7, types: []domain.LeaveByType{
+			{LeaveType: "Annual", Count: 3, TotalDays: 7}, {LeaveType: "Medical appointment", Count: 3, TotalDays: 6}}},
+		{name: domain.UnassignedDepartment, requests: 2, days: 2, types: []domain.LeaveByType{{LeaveType: "Sick", Count: 2, TotalDays: 2}}},
+		{id: &unnamed, name: unnamed.String(), requests: 1, days: 1, types: []domain.LeaveByType{{LeaveType: "Sick", Count: 1, TotalDays: 1}}},
+	}
+	if len(report.Departments) != len(want) {
+		t.Fatalf("departments = %+v", report.Departments)
+	}
+	for i, w := range want {
+		got := report.Departments[i]
+		if (got.DepartmentID == nil) != (w.id == nil) || (w.id != nil && *got.DepartmentID != *w.id) ||
+			got.DepartmentName != w.name || got.TotalRequests != w.requests || got.TotalDaysTaken != w.days {
+			t.Errorf("department %d = %+v, want %s with %d requests and %v days", i, got, w.name, w.requests, w.days)
+		}
+		if len(got.LeaveByType) != len(w.types) {
+			t.Errorf("%s leave by type = %+v", w.name, got.LeaveByType)
+			continue
+		}
+		for j, lt := range w.types {
+			if got.LeaveByType[j].LeaveType != lt.LeaveType || got.LeaveByType[j].Count != lt.Count || got.LeaveByType[j].TotalDays != lt.TotalDays {
+				t.Errorf("%s leave by type %d = %+v, want %+v", w.name, j, got.LeaveByType[j], lt)
+			}
+		}
+	}
+
+	// The mapping is reused for a few minutes
+	if _, err := svc.DepartmentAnalysisReport(orgID, from, from.AddDate(0, 3, 0), "token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if directory.listed != 1 {
+		t.Errorf("listed the employees %d times, want once", directory.listed)
+	}
+}
+
+func TestDepartmentAnalysisReportOrganizationServiceDown(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		down        func(directory *fakeDirectory)
+		wantWarning string
+		wantNames   []string
+	}{
+		{name: "employees", down: func(d *fakeDirectory) { d.err = organization.ErrUnavailable },
+			wantWarning: "organization service unavailable, all employees are unassigned",
+			wantNames:   []string{domain.UnassignedDepartment}},
+		{name: "departments", down: func(d *fakeDirectory) { d.departmentErr = organization.ErrUnavailable },
+			wantWarning: "organization service unavailable, departments are named by id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			svc, directory := newDirectoryService(repo, uuid.New(), &organization.EmployeeResponse{})
+			engineering, sales, unnamed := departmentLeave(repo, directory)
+			if tt.wantNames == nil {
+				tt.wantNames = []string{sales.String(), engineering.String(), domain.UnassignedDepartment, unnamed.String()}
+			}
+			tt.down(directory)
+
+			report, err := svc.DepartmentAnalysisReport(orgID, from, from.AddDate(0, 3, 0), "token")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Warning != tt.wantWarning {
+				t.Errorf("warning = %q, want %q", report.Warning, tt.wantWarning)
+			}
+			var names []string
+			for _, d := range report.Departments {
+				names = append(names, d.DepartmentName)
+			}
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("departments %v, want %v", names, tt.wantNames)
+			}
+			for i := range names {
+				if names[i] != tt.wantNames[i] {
+					t.Errorf("departments %v, want %v", names, tt.wantNames)
+					break
+				}
+			}
+
+			// An incomplete mapping is not cached, so the next report
+			// recovers once the service is back
+			directory.err, directory.departmentErr = nil, nil
+			if report, err = svc.DepartmentAnalysisReport(orgID, from, from.AddDate(0, 3, 0), "token"); err != nil || report.Warning != "" {
+				t.Errorf("after recovery: warning %q (%v)", report.Warning, err)
+			}
+			if directory.listed != 2 {
+				t.Errorf("listed the employees %d times, want twice", directory.listed)
+			}
+		})
+	}
+}
+
+func TestDepartmentAnalysisReportValidation(t *testing.T) {
+	svc := newTestService(newFakeRepository())
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for name, to := range map[string]time.Time{
+		"empty range":    from,
+		"too long range": from.AddDate(1, 1, 0),
+	} {
+		if _, err := svc.DepartmentAnalysisReport(uuid.New(), from, to, "token"); httpStatus(err) != http.StatusBadRequest {
+			t.Errorf("%s: got %v, want a 400", name, err)
+		}
+	}
+
+	// Without a directory everyone is unassigned
+	report, err := svc.DepartmentAnalysisReport(uuid.New(), from, from.AddDate(0, 1, 0), "token")
+	if err != nil || report.Warning != "employee directory not configured, all employees are unassigned" {
+		t.Errorf("report = %+v (%v)", report, err)
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// EmployeeDirectory confirms that employees belong to an organization,
+// lists the members of the organization and its departments and names the
+// organization
+type EmployeeDirectory interface {
+	GetOrganization(token string, orgID string) (*organization.OrganizationResponse, error)
+	GetEmployee(token string, orgID string, employeeID string) (*organization.EmployeeResponse, error)
+	ListDepartmentEmployees(token string, orgID string, departmentID string) ([]organization.EmployeeResponse, error)
+	ListEmployees(token string, orgID string) ([]organization.EmployeeResponse, error)
+	ListDepartments(token string, orgID string) ([]organization.DepartmentResponse, error)
+}
+
+// verifyEmployee rejects employees unknown to the organization and returns
+// the employee found. When the organization service is unreachable the
+// request is let through or refused per the employee check's severity.
+// Without a directory nothing is checked. The employee is nil whenever the
+// check was skipped.
+func (s *leaveService) verifyEmployee(token string, orgID, employeeID uuid.UUID) (*organization.EmployeeResponse, error) {
+	employee, _, err := s.checkEmployee(token, orgID, employeeID)
+	return employee, err
+}
+
+// checkEmployee is verifyEmployee also returning the skipped check when
+// the organization service was down and the check degraded
+func (s *leaveService) checkEmployee(token string, orgID, employeeID uuid.UUID) (*organization.EmployeeResponse, *domain.SkippedValidation, error) {
+	if s.employees == nil {
+		return nil, nil, nil
+	}
+
+	employee, err := s.employees.GetEmployee(token, orgID.String(), employeeID.String())
+	switch {
+	case err == nil:
+		return employee, nil, nil
+	case errors.Is(err, organization.ErrEmployeeNotFound):
+		return nil, nil, apperrors.NewUnprocessableError("employee not found in organization")
+	case organizationDown(err):
+		skipped, err := s.skipValidation(domain.ValidationCheckEmployee, employeeID, err)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, &skipped, nil
+	}
+	return nil, nil, err
+}
+
+// skipEmployeeChecks applies the severity of each check of leaveType that
+// reads the employee, once the employee check was skipped, and returns
+// them after employeeSkipped
+func (s *leaveService) skipEmployeeChecks(leaveType *domain.LeaveType, employeeID uuid.UUID, employeeSkipped *domain.SkippedValidation) ([]domain.SkippedValidation, error) {
+	var checks []string
+	if leaveType.Eligibility != nil {
+		checks = append(checks, domain.ValidationCheckEligibility)
+	}
+	if leaveType.MinTenureDays > 0 {
+		checks = append(checks, domain.ValidationCheckTenure)
+	}
+	if leaveType.BlockedDuringProbation {
+		checks = append(checks, domain.ValidationCheckProbation)
+	}
+
+	skipped := []domain.SkippedValidation{*employeeSkipped}
+	for _, check := range checks {
+		check, err := s.skipValidation(check, employeeID, errEmployeeSkipped)
+		if err != nil {
+			return nil, err
+		}
+		skipped = append(skipped, check)
+	}
+	return skipped, nil
+}
+
+// checkTenure refuses leave of a type with a minimum tenure that would
+// start before the employee is eligible. When the employee's start date is
+// unknown, including when the organization service could not be reached,
+// the check is skipped with a warning.
+func (s *leaveService) checkTenure(leaveType *domain.LeaveType, employeeID uuid.UUID, employee *organization.EmployeeResponse, start time.Time) error {
+	if leaveType.MinTenureDays == 0 {
+		return nil
+	}
+	if employee == nil || employee.StartDate == "" {
+		log.Printf("Warning: skipping tenure check for %s: employee start date unknown", employeeID)
+		return nil
+	}
+
+	startDate, err := employeeStartDate(employee)
+	if err != nil {
+		log.Printf("Warning: skipping tenure check for %s: invalid start date %q", employeeID, employee.StartDate)
+		return nil
+	}
+
+	eligible := leaveType.EligibleFrom(startDate)
+	if domain.TruncateToDate(start).Before(eligible) {
+		return apperrors.NewUnprocessableError(fmt.Sprintf(
+			"%s requires %d days of tenure; the employee becomes eligible on %s",
+			leaveType.Name, leaveType.MinTenureDays, eligible.Format("2006-01-02")))
+	}
+	return nil
+}
+
+// checkProbation refuses leave of a type blocked during probation when
+// any of its days, from start, falls within the employee's probation. A
+// request straddling the end of probation is refused whole. When override
+// is set the request is let through and true returned, to be recorded on
+// it. When the employee's start date is unknown the check is skipped with
+// a warning.
+func (s *leaveService) checkProbation(orgID uuid.UUID, leaveType *domain.LeaveType, employeeID uuid.UUID, employee *organization.EmployeeResponse, start, end time.Time, override bool) (bool, error) {
+	if !leaveType.BlockedDuringProbation {
+		return false, nil
+	}
+	if employee == nil || employee.StartDate == "" {
+		log.Printf("Warning: skipping probation check for %s: employee start date unknown", employeeID)
+		return false, nil
+	}
+	startDate, err := employeeStartDate(employee)
+	if err != nil {
+		log.Printf("Warning: skipping probation check for %s: invalid start date %q", employeeID, employee.StartDate)
+		return false, nil
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return false, err
+	}
+	probationEnd := settings.ProbationEnd(startDate)
+	if !domain.TruncateToDate(start).Before(probationEnd) {
+		return false, nil
+	}
+	if override {
+		log.Printf("Probation of %s overridden for %s leave starting %s", employeeID, leaveType.Name, start.Format("2006-01-02"))
+		return true, nil
+	}
+
+	msg := fmt.Sprintf("%s cannot be taken during probation; the employee's probation ends on %s, so the earliest allowed start date is %s",
+		leaveType.Name, probationEnd.AddDate(0, 0, -1).Format("2006-01-02"), probationEnd.Format("2006-01-02"))
+	if !domain.TruncateToDate(end).Before(probationEnd) {
+		msg += "; split the request to take the days from then"
+	}
+	appErr := apperrors.NewUnprocessableError(msg)
+	appErr.Details = domain.ProbationConflict{EarliestStartDate: probationEnd}
+	return false, appErr
+}
+
+// checkEligibility refuses leave of a type the employee does not meet the
+// eligibility rule of, naming the failed condition. When the employee could
+// not be looked up the check is skipped with a warning.
+func (s *leaveService) checkEligibility(leaveType *domain.LeaveType, employeeID uuid.UUID, employee *organization.EmployeeResponse) error {
+	if leaveType.Eligibility == nil {
+		return nil
+	}
+	if employee == nil {
+		log.Printf("Warning: skipping eligibility check for %s: employee unknown", employeeID)
+		return nil
+	}
+
+	failed := leaveType.Eligibility.FailedCondition(employeeAttributes(employee))
+	if failed == nil {
+		return nil
+	}
+	appErr := apperrors.NewForbiddenError(fmt.Sprintf(
+		"employee is not eligible for %s: requires %s", leaveType.Name, failed))
+	appErr.Details = failed
+	return appErr
+}
+
+// employeeStartDate parses the employee's start date, which the
+// organization service reports as YYYY-MM-DD or RFC 3339
+func employeeStartDate(employee *organization.EmployeeResponse) (time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", employee.StartDate)
+	if err != nil {
+		return time.Parse(time.RFC3339, employee.StartDate)
+	}
+	return startDate, nil
+}
+
+// employeeAttributes returns the employee's attributes keyed by the names
+// eligibility rules use
+func employeeAttributes(employee *organization.EmployeeResponse) map[string]string {
+	return map[string]string{
+		domain.EligibilityAttributeGender:         employee.Gender,
+		domain.EligibilityAttributeEmploymentType: employee.EmploymentType,
+		domain.EligibilityAttributeLocation:       employee.Location,
+		domain.EligibilityAttributeGrade:          employee.Grade,
+	}
+}
+
+// today returns the current date in the employee's time zone when the
+// organization service reports one, and otherwise in the organization's
+func (s *leaveService) today(orgID uuid.UUID, employee *organization.EmployeeResponse) (time.Time, error) {
+	if employee != nil && employee.Timezone != "" {
+		if loc, err := time.LoadLocation(employee.Timezone); err == nil {
+			return domain.TruncateToDate(time.Now().In(loc)), nil
+		}
+	}
+	return s.Today(orgID)
+}
+
+// locationOf returns the employee's location, which picks the holidays
+// they observe, or "" when unknown
+func locationOf(employee *organization.EmployeeResponse) string {
+	if employee == nil {
+		return ""
+	}
+	return employee.Location
+}
+
+// employeeLocation looks up the employee's location. When the lookup
+// fails the employee is left with the organization-wide holidays only.
+func (s *leaveService) employeeLocation(token string, orgID, employeeID uuid.UUID) string {
+	if s.employees == nil {
+		return ""
+	}
+	employee, err := s.employees.GetEmployee(token, orgID.String(), employeeID.String())
+	if err != nil {
+		log.Printf("Warning: cannot look up the location of %s, counting organization-wide holidays only: %v", employeeID, err)
+		return ""
+	}
+	return employee.Location
+}
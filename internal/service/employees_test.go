@@ -0,0 +1,553 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// fakeDirectory answers employee lookups from employees, or with err when
+// set, and counts the employee listings in listed; department members are
+// listed from the employees' DepartmentID and departments from
+// departments, both answered with departmentErr when set. The other
+// lookups are left unimplemented.
+type fakeDirectory struct {
+	EmployeeDirectory
+	employees     map[uuid.UUID]*organization.EmployeeResponse
+	departments   []organization.DepartmentResponse
+	err           error
+	listed        int
+	departmentErr error
+}
+
+func (d *fakeDirectory) GetEmployee(token string, orgID string, employeeID string) (*organization.EmployeeResponse, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	employee, ok := d.employees[uuid.MustParse(employeeID)]
+	if !ok {
+		return nil, organization.ErrEmployeeNotFound
+	}
+	return employee, nil
+}
+
+func (d *fakeDirectory) ListEmployees(token string, orgID string) ([]organization.EmployeeResponse, error) {
+	d.listed++
+	if d.err != nil {
+		return nil, d.err
+	}
+	var employees []organization.EmployeeResponse
+	for id, employee := range d.employees {
+		listed := *employee
+		listed.ID = id.String()
+		employees = append(employees, listed)
+	}
+	return employees, nil
+}
+
+func (d *fakeDirectory) ListDepartmentEmployees(token string, orgID string, departmentID string) ([]organization.EmployeeResponse, error) {
+	if d.departmentErr != nil {
+		return nil, d.departmentErr
+	}
+	var members []organization.EmployeeResponse
+	for id, employee := range d.employees {
+		if employee.DepartmentID == departmentID {
+			member := *employee
+			member.ID = id.String()
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (d *fakeDirectory) ListDepartments(token string, orgID string) ([]organization.DepartmentResponse, error) {
+	if d.departmentErr != nil {
+		return nil, d.departmentErr
+	}
+	return d.departments, nil
+}
+
+// newDirectoryService returns a service over repo looking employees up in
+// a directory holding employee
+func newDirectoryService(repo *fakeRepository, employeeID uuid.UUID, employee *organization.EmployeeResponse) (*leaveService, *fakeDirectory) {
+	directory := &fakeDirectory{employees: map[uuid.UUID]*organization.EmployeeResponse{employeeID: employee}}
+	return NewLeaveService(repo, directory, true, nil).(*leaveService), directory
+}
+
+// maternity is an eligibility rule on every attribute an employee has
+var maternity = &domain.EligibilityRule{Conditions: []domain.EligibilityCondition{
+	{Attribute: domain.EligibilityAttributeGender, Values: []string{"female"}},
+	{Attribute: domain.EligibilityAttributeEmploymentType, Values: []string{"full_time", "part_time"}},
+	{Attribute: domain.EligibilityAttributeLocation, Values: []string{"LK", "IN"}},
+	{Attribute: domain.EligibilityAttributeGrade, Values: []string{"G3", "G4"}},
+}}
+
+func TestCreateLeaveRequestEligibility(t *testing.T) {
+	eligible := organization.EmployeeResponse{Gender: "Female", EmploymentType: "full_time", Location: " lk ", Grade: "G4"}
+	tests := []struct {
+		name       string
+		employee   func(*organization.EmployeeResponse)
+		wantFailed string
+	}{
+		{name: "eligible", employee: func(*organization.EmployeeResponse) {}},
+		{name: "gender", employee: func(e *organization.EmployeeResponse) { e.Gender = "male" }, wantFailed: "gender in (female)"},
+		{name: "employment type", employee: func(e *organization.EmployeeResponse) { e.EmploymentType = "contractor" },
+			wantFailed: "employment_type in (full_time, part_time)"},
+		{name: "location", employee: func(e *organization.EmployeeResponse) { e.Location = "UK" }, wantFailed: "location in (LK, IN)"},
+		{name: "grade", employee: func(e *organization.EmployeeResponse) { e.Grade = "G1" }, wantFailed: "grade in (G3, G4)"},
+		{name: "grade unknown", employee: func(e *organization.EmployeeResponse) { e.Grade = "" }, wantFailed: "grade in (G3, G4)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			leaveType.Name = "Maternity"
+			leaveType.Eligibility = maternity
+			employee := eligible
+			tt.employee(&employee)
+			svc, _ := newDirectoryService(repo, employeeID, &employee)
+
+			monday := upcomingMonday()
+			_, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday,
+			})
+
+			if tt.wantFailed == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if httpStatus(err) != http.StatusForbidden {
+				t.Fatalf("got %d (%v), want 403", httpStatus(err), err)
+			}
+			appErr := err.(*apperrors.AppError)
+			if !strings.Contains(appErr.Message, "not eligible for Maternity: requires "+tt.wantFailed) {
+				t.Errorf("message %q does not name %s", appErr.Message, tt.wantFailed)
+			}
+			if failed, ok := appErr.Details.(*domain.EligibilityCondition); !ok || failed.String() != tt.wantFailed {
+				t.Errorf("details = %+v, want the condition %s", appErr.Details, tt.wantFailed)
+			}
+			if repo.created != nil {
+				t.Error("an ineligible request was saved")
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestEligibilitySkippedWhenEmployeeUnknown(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	leaveType.Eligibility = maternity
+	svc, directory := newDirectoryService(repo, uuid.New(), nil)
+	directory.err = organization.ErrUnavailable
+
+	monday := upcomingMonday()
+	if _, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  uuid.New(),
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestListLeaveTypesEligibleForEmployee(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	repo := newFakeRepository()
+	for _, name := range []string{"Annual", "Casual", "Maternity", "Paternity", "Sick"} {
+		leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+		leaveType.Name = name
+		switch name {
+		case "Maternity":
+			leaveType.Eligibility = maternity
+		case "Paternity":
+			leaveType.Eligibility = &domain.EligibilityRule{Conditions: []domain.EligibilityCondition{
+				{Attribute: domain.EligibilityAttributeGender, Values: []string{"male"}},
+			}}
+		}
+	}
+	svc, _ := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{
+		Gender: "male", EmploymentType: "full_time", Location: "LK", Grade: "G3",
+	})
+
+	// Pages count the eligible types only
+	var names []string
+	for page := 1; page <= 3; page++ {
+		leaveTypes, total, err := svc.ListLeaveTypes(orgID, &domain.ListLeaveTypesParams{Page: page, PageSize: 2, EmployeeID: &employeeID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if total != 4 {
+			t.Errorf("page %d: total = %d, want 4", page, total)
+		}
+		for _, leaveType := range leaveTypes {
+			names = append(names, leaveType.Name)
+		}
+	}
+	if got := strings.Join(names, ", "); got != "Annual, Casual, Paternity, Sick" {
+		t.Errorf("listed %s, want every type but Maternity", got)
+	}
+
+	// Without an employee every type is listed
+	_, total, err := svc.ListLeaveTypes(orgID, &domain.ListLeaveTypesParams{Page: 1, PageSize: 10})
+	if err != nil || total != 5 {
+		t.Errorf("listed %d types (%v), want 5", total, err)
+	}
+}
+
+func TestInitializeEmployeeBalances(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	year := time.Now().Year()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	annual := repo.addLeaveType(orgID, domain.RoundingNone)
+	casual := repo.addLeaveType(orgID, domain.RoundingNone)
+	casual.Name, casual.DefaultDays = "Casual", 7
+	hourly := repo.addLeaveType(orgID, domain.RoundingNone)
+	hourly.Name, hourly.DefaultDays, hourly.Unit = "Volunteering", 2, domain.LeaveUnitHours
+	accruing := repo.addLeaveType(orgID, domain.RoundingNone)
+	accruing.Name, accruing.AccrualFrequency, accruing.AccrualRate = "Sick", domain.AccrualFrequencyMonthly, 1
+	ineligible := repo.addLeaveType(orgID, domain.RoundingNone)
+	ineligible.Name, ineligible.Eligibility = "Maternity", maternity
+
+	// Hired on the 10th of April, with 9 of 12 months left
+	svc, _ := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{
+		Gender: "male", StartDate: fmt.Sprintf("%d-04-10", year),
+	})
+	result, err := svc.InitializeEmployeeBalances(orgID, employeeID, &domain.InitializeBalancesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Year != year || result.Proration.Remaining != 9 || result.Proration.Total != 12 {
+		t.Fatalf("prorated %d/%d of %d, want 9/12 of %d", result.Proration.Remaining, result.Proration.Total, result.Year, year)
+	}
+
+	// 7 days x 9/12 = 5.25 rounds to the nearest half day
+	want := map[uuid.UUID]float64{annual.ID: 15, casual.ID: 5.5, hourly.ID: 1.5 * domain.DefaultHoursPerDay, accruing.ID: 0}
+	if len(result.Balances) != len(want) {
+		t.Fatalf("opened %d balances, want %d", len(result.Balances), len(want))
+	}
+	for _, b := range result.Balances {
+		total, ok := want[b.LeaveTypeID]
+		if !ok {
+			t.Errorf("opened a %s balance the employee is not eligible for", b.LeaveType)
+			continue
+		}
+		if !b.Created || b.Balance.TotalDays != total || b.Balance.OpeningDays == nil || *b.Balance.OpeningDays != total {
+			t.Errorf("%s opened with %v (%s), want %v", b.LeaveType, b.Balance.TotalDays, b.Calculation, total)
+		}
+	}
+
+	// A second call returns the balances unchanged
+	again, err := svc.InitializeEmployeeBalances(orgID, employeeID, &domain.InitializeBalancesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, b := range again.Balances {
+		if b.Created {
+			t.Errorf("%s was opened twice", b.LeaveType)
+		}
+	}
+	if len(repo.balances) != len(want) {
+		t.Errorf("stored %d balances, want %d", len(repo.balances), len(want))
+	}
+}
+
+func TestInitializeEmployeeBalancesHireDate(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	year := time.Now().Year()
+	tests := []struct {
+		name       string
+		hireDate   *time.Time
+		employee   *organization.EmployeeResponse
+		wantTotal  float64
+		wantStatus int
+	}{
+		{name: "from the request", hireDate: ptrTime(time.Date(year, time.October, 20, 0, 0, 0, 0, time.UTC)),
+			employee: &organization.EmployeeResponse{StartDate: fmt.Sprintf("%d-01-01", year)}, wantTotal: 5},
+		{name: "from the directory in RFC 3339", employee: &organization.EmployeeResponse{StartDate: fmt.Sprintf("%d-07-01T00:00:00Z", year)}, wantTotal: 10},
+		{name: "invalid start date", employee: &organization.EmployeeResponse{StartDate: "soon"}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "unknown start date", employee: &organization.EmployeeResponse{}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.addLeaveType(orgID, domain.RoundingNone)
+			svc, _ := newDirectoryService(repo, employeeID, tt.employee)
+
+			result, err := svc.InitializeEmployeeBalances(orgID, employeeID, &domain.InitializeBalancesRequest{HireDate: tt.hireDate})
+			if tt.wantStatus != 0 {
+				if httpStatus(err) != tt.wantStatus {
+					t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Balances) != 1 || result.Balances[0].Balance.TotalDays != tt.wantTotal {
+				t.Errorf("balances = %+v, want one of %v days", result.Balances, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestAbsenceLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		department    string
+		departmentErr error
+		// severity overrides the absence limit's, which degrades
+		severity      string
+		wantStatus    int
+		wantConflicts int
+		wantSkipped   bool
+	}{
+		{name: "blocked", mode: domain.AbsenceLimitModeBlock, department: "eng", wantStatus: http.StatusConflict},
+		{name: "warned", mode: domain.AbsenceLimitModeWarn, department: "eng", wantConflicts: 1},
+		{name: "without a department", mode: domain.AbsenceLimitModeBlock},
+		{name: "directory down", mode: domain.AbsenceLimitModeBlock, department: "eng",
+			departmentErr: organization.ErrUnavailable, wantSkipped: true},
+		{name: "directory down, blocking", mode: domain.AbsenceLimitModeBlock, department: "eng",
+			departmentErr: organization.ErrUnavailable, severity: domain.ValidationSeverityBlock, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.settings.MaxConcurrentAbsences = 2
+			repo.settings.ConcurrentAbsenceMode = tt.mode
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			svc, directory := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{DepartmentID: tt.department})
+			if tt.severity != "" {
+				svc.validationSeverities[domain.ValidationCheckAbsenceLimit] = tt.severity
+			}
+			directory.departmentErr = tt.departmentErr
+
+			// Two colleagues are off on Tuesday, one on Wednesday; a third
+			// colleague's rejected leave and another department's leave
+			// do not count
+			monday := upcomingMonday()
+			colleagues := make([]uuid.UUID, 4)
+			for i := range colleagues {
+				colleagues[i] = uuid.New()
+				department := tt.department
+				if i == 3 {
+					department = "sales"
+				}
+				directory.employees[colleagues[i]] = &organization.EmployeeResponse{DepartmentID: department}
+			}
+			repo.addDayRequest(leaveType, colleagues[0], monday.AddDate(0, 0, 1), "", "", domain.LeaveStatusApproved)
+			repo.addDayRequest(leaveType, colleagues[0], monday.AddDate(0, 0, 2), "", "", domain.LeaveStatusApproved)
+			repo.addDayRequest(leaveType, colleagues[1], monday.AddDate(0, 0, 1), domain.HalfDayPM, domain.HalfDayPM, domain.LeaveStatusPending)
+			repo.addDayRequest(leaveType, colleagues[2], monday.AddDate(0, 0, 2), "", "", domain.LeaveStatusRejected)
+			repo.addDayRequest(leaveType, colleagues[3], monday.AddDate(0, 0, 2), "", "", domain.LeaveStatusApproved)
+
+			request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday.AddDate(0, 0, 4),
+			})
+			if httpStatus(err) != tt.wantStatus {
+				t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusConflict {
+				conflicts, ok := err.(*apperrors.AppError).Details.([]domain.AbsenceConflict)
+				if !ok || len(conflicts) != 1 || !conflicts[0].Date.Equal(monday.AddDate(0, 0, 1)) || len(conflicts[0].EmployeeIDs) != 2 {
+					t.Errorf("details = %+v, want Tuesday with both colleagues off", err.(*apperrors.AppError).Details)
+				}
+			}
+			if tt.wantStatus != 0 {
+				if repo.created != nil {
+					t.Error("a refused request was saved")
+				}
+				return
+			}
+			if request.HasConflicts != (tt.wantConflicts > 0) || len(request.AbsenceConflicts) != tt.wantConflicts {
+				t.Errorf("request flagged %v with %+v, want %d conflicts", request.HasConflicts, request.AbsenceConflicts, tt.wantConflicts)
+			}
+			if skipped := len(request.SkippedValidations) == 1; skipped != tt.wantSkipped {
+				t.Errorf("skipped %+v, want the absence limit skipped: %v", request.SkippedValidations, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestObservesLocationHolidays(t *testing.T) {
+	monday := upcomingMonday()
+	tests := []struct {
+		name     string
+		location string
+		wantDays float64
+	}{
+		{name: "in the north", location: "lk-n", wantDays: 3},
+		{name: "in the south", location: "LK-S", wantDays: 4},
+		{name: "without a location", wantDays: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			// Only the northern office is off on Wednesday; everyone is off
+			// on Friday
+			repo.holidays = []domain.Holiday{
+				{OrganizationID: orgID, Name: "Thai Pongal", Date: monday.AddDate(0, 0, 2), Location: domain.HolidayLocation("LK-N")},
+				{OrganizationID: orgID, Name: "Vesak", Date: monday.AddDate(0, 0, 4)},
+			}
+			svc, _ := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{Location: tt.location})
+
+			_, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday.AddDate(0, 0, 4),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.created.Days != tt.wantDays {
+				t.Errorf("days = %v, want %v", repo.created.Days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestProbation(t *testing.T) {
+	monday := upcomingMonday()
+	friday := monday.AddDate(0, 0, 4)
+	// started returns the start date of an employee whose three months'
+	// probation ends the day before probationEnd
+	started := func(probationEnd time.Time) string {
+		return probationEnd.AddDate(0, -3, 0).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		name            string
+		startDate       string
+		notBlocked      bool
+		override        bool
+		probationMonths *int
+		wantEarliest    time.Time
+		wantSplit       bool
+		wantOverridden  bool
+	}{
+		{name: "within probation", startDate: started(friday.AddDate(0, 1, 0)), wantEarliest: friday.AddDate(0, 1, 0)},
+		{name: "straddling its end", startDate: started(monday.AddDate(0, 0, 2)), wantEarliest: monday.AddDate(0, 0, 2), wantSplit: true},
+		{name: "starting the day it ends", startDate: started(monday)},
+		{name: "type not blocked", startDate: started(friday.AddDate(0, 1, 0)), notBlocked: true},
+		{name: "overridden", startDate: started(friday.AddDate(0, 1, 0)), override: true, wantOverridden: true},
+		{name: "start date unknown"},
+		{name: "start date invalid", startDate: "last spring"},
+		{name: "organization without probation", startDate: started(friday.AddDate(0, 1, 0)), probationMonths: new(int)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			if tt.probationMonths != nil {
+				repo.settings = domain.DefaultOrganizationSettings(orgID)
+				repo.settings.ProbationMonths = *tt.probationMonths
+			}
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			leaveType.Name = "Annual"
+			leaveType.BlockedDuringProbation = !tt.notBlocked
+			svc, _ := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{StartDate: tt.startDate})
+
+			request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:        employeeID,
+				LeaveTypeID:       leaveType.ID,
+				StartDate:         monday,
+				EndDate:           friday,
+				OverrideProbation: tt.override,
+			})
+
+			if tt.wantEarliest.IsZero() {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if request.ProbationOverridden != tt.wantOverridden {
+					t.Errorf("probation_overridden = %v, want %v", request.ProbationOverridden, tt.wantOverridden)
+				}
+				return
+			}
+			if httpStatus(err) != http.StatusUnprocessableEntity {
+				t.Fatalf("got %d (%v), want 422", httpStatus(err), err)
+			}
+			appErr := err.(*apperrors.AppError)
+			earliest := tt.wantEarliest.Format("2006-01-02")
+			if !strings.Contains(appErr.Message, "Annual cannot be taken during probation") ||
+				!strings.Contains(appErr.Message, "earliest allowed start date is "+earliest) {
+				t.Errorf("message %q does not name %s as the earliest start", appErr.Message, earliest)
+			}
+			if split := strings.Contains(appErr.Message, "split the request"); split != tt.wantSplit {
+				t.Errorf("message %q suggests splitting: %v, want %v", appErr.Message, split, tt.wantSplit)
+			}
+			if conflict, ok := appErr.Details.(domain.ProbationConflict); !ok || !conflict.EarliestStartDate.Equal(tt.wantEarliest) {
+				t.Errorf("details = %+v, want the earliest start %s", appErr.Details, earliest)
+			}
+			if repo.created != nil {
+				t.Error("a request within probation was saved")
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestProbation(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	monday := upcomingMonday()
+	employee := &organization.EmployeeResponse{StartDate: monday.AddDate(0, -1, 0).Format("2006-01-02")}
+	svc, _ := newDirectoryService(repo, employeeID, employee)
+
+	request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday,
+	})
+	if err != nil {
+		t.Fatalf("creating: %v", err)
+	}
+	request.Status = domain.LeaveStatusPending
+	// The type was blocked during probation after the request was made
+	leaveType.BlockedDuringProbation = true
+
+	edit := &domain.EditLeaveRequestRequest{LeaveTypeID: leaveType.ID, StartDate: monday, EndDate: monday.AddDate(0, 0, 1)}
+	if _, err := svc.EditLeaveRequest(orgID, request.ID, employeeID, edit); httpStatus(err) != http.StatusUnprocessableEntity {
+		t.Fatalf("got %d (%v), want 422", httpStatus(err), err)
+	}
+
+	edit.OverrideProbation = true
+	edited, err := svc.EditLeaveRequest(orgID, request.ID, employeeID, edit)
+	if err != nil {
+		t.Fatalf("editing: %v", err)
+	}
+	if !edited.ProbationOverridden {
+		t.Error("the override was not recorded on the request")
+	}
+}
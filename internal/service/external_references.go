@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// UpdateExternalReferences replaces the external references of a request
+func (s *leaveService) UpdateExternalReferences(orgID, id uuid.UUID, refs []domain.ExternalReference) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveRequest.ExternalReferences, err = domain.NormalizeExternalReferences(refs)
+	if err != nil {
+		return nil, apperrors.NewBadRequestError(err.Error())
+	}
+	if err := s.leaveRepo.UpdateExternalReferences(leaveRequest); err != nil {
+		return nil, err
+	}
+
+	if err := s.warnExternalReferenceDuplicates(leaveRequest); err != nil {
+		return nil, err
+	}
+	return leaveRequest, nil
+}
+
+// warnExternalReferenceDuplicates adds a warning for each external
+// reference already carried by another request of the organization. A
+// shared reference may be legitimate, e.g. one case covering several
+// absences, so it never blocks the write.
+func (s *leaveService) warnExternalReferenceDuplicates(leaveRequest *domain.LeaveRequest) error {
+	if len(leaveRequest.ExternalReferences) == 0 {
+		return nil
+	}
+	duplicates, err := s.leaveRepo.FindExternalReferenceDuplicates(leaveRequest.OrganizationID, leaveRequest.ID, leaveRequest.ExternalReferences)
+	if err != nil {
+		return err
+	}
+	for _, ref := range leaveRequest.ExternalReferences {
+		ids, ok := duplicates[ref]
+		if !ok {
+			continue
+		}
+		others := make([]string, len(ids))
+		for i, id := range ids {
+			others[i] = id.String()
+		}
+		leaveRequest.Warnings = append(leaveRequest.Warnings, fmt.Sprintf(
+			"%s reference %s is also on leave requests %s", ref.System, ref.Reference, strings.Join(others, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,1143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeRepository serves the repository methods the service tests need from
+// memory. Methods it does not override panic through the nil embedded
+// interface, so a test touching one fails loudly.
+type fakeRepository struct {
+	repository.LeaveRepository
+
+	settings   *domain.OrganizationSettings
+	holidays   []domain.Holiday
+	decisions  []domain.ApproverDecision
+	overdue    map[uuid.UUID]int
+	leaveTypes map[uuid.UUID]*domain.LeaveType
+	requests   map[uuid.UUID]*domain.LeaveRequest
+
+	// created is the last request saved through CreateLeaveRequest, with
+	// the entitlement its balance would open with and whether that balance
+	// would be provisional
+	created            *domain.LeaveRequest
+	createdEntitlement float64
+	createdProvisional bool
+	createErr          error
+
+	// updated is the last request saved through UpdatePendingLeaveRequest
+	updated            *domain.LeaveRequest
+	updatedEntitlement float64
+	updateErr          error
+
+	// transitioned is the status of the last TransitionLeaveRequest
+	transitioned string
+
+	// adjustments back GetBalanceAdjustment; reverted is the last
+	// adjustment reverted, answered with revertErr when set
+	adjustments map[uuid.UUID]*domain.LeaveBalanceAdjustment
+	reverted    *domain.LeaveBalanceAdjustment
+	revertErr   error
+
+	// createdAdjustment is the last adjustment saved through
+	// CreateBalanceAdjustment
+	createdAdjustment *domain.LeaveBalanceAdjustment
+
+	// updatedAdjustments are the adjustments saved through
+	// UpdateBalanceAdjustment, which answers with updateAdjustmentErr for
+	// the adjustments it holds instead
+	updatedAdjustments  []domain.LeaveBalanceAdjustment
+	updateAdjustmentErr map[uuid.UUID]error
+
+	// absences back ListAbsences, which records the window it was asked for
+	// and keeps the absences overlapping it
+	absences      []domain.Absence
+	absenceWindow [2]time.Time
+
+	// absenceAlerts back the absence alert methods, in creation order
+	absenceAlerts []*domain.AbsenceAlert
+
+	// accrualRuns answer AccrueBalance for the balances of the same index
+	// in balances; a nil run means the period was already accrued
+	accrualRuns []*domain.AccrualRun
+
+	// purged is the last request deleted through PurgeLeaveRequest
+	purged   *domain.LeaveRequest
+	purgeErr error
+
+	// poolMembers are the approver pools' members in rotation order, and
+	// poolNext the index NextPoolApprover hands out next
+	poolMembers map[uuid.UUID][]uuid.UUID
+	poolNext    map[uuid.UUID]int
+
+	// changedRequests and changedBalances feed the change feeds in
+	// (updated_at, id) order; holidays feed the holiday one
+	changedRequests []domain.LeaveRequest
+	changedBalances []domain.LeaveBalance
+
+	// balances, forfeited and taken back the leave history summary
+	balances  []domain.LeaveBalance
+	forfeited map[uuid.UUID]float64
+	taken     []domain.LeaveTakenByYear
+
+	// snapshots back the statement's opening balances, snapshotParams is
+	// the last filter they were listed with and resetRuns are the run ids
+	// InitializeYearlyBalance was called with
+	snapshots      []domain.BalanceSnapshot
+	snapshotParams *domain.ListBalanceSnapshotsParams
+	resetRuns      []uuid.UUID
+
+	// claims back the employees' optional holiday claims
+	claims []domain.OptionalHolidayClaim
+
+	// compOffs back the comp-off requests; approving one credits its
+	// balance in balances, opening it when there is none
+	compOffs map[uuid.UUID]*domain.CompOffRequest
+
+	// jobs are queued jobs ClaimJob hands out once each, in order;
+	// finishedJobs are the jobs saved through FinishJob, and jobLeaseErr
+	// answers UpdateJobProgress when set
+	jobsMu       sync.Mutex
+	jobs         []*domain.Job
+	finishedJobs []domain.Job
+	jobLeaseErr  error
+
+	// expected are the figures the balances' source records add up to;
+	// CorrectLeaveBalance answers with corrections, or correctErr when set,
+	// and records the opening it fell back to in correctedOpening
+	expected         map[uuid.UUID]domain.BalanceFigures
+	corrections      map[uuid.UUID]*domain.BalanceDiscrepancy
+	correctErr       error
+	correctedOpening map[uuid.UUID]float64
+
+	// transferErrs answer TransferLeaveBalance per employee, transfers are
+	// the transfers made, and transferJobs and transferFailures what bulk
+	// transfer jobs saved
+	transferErrs     map[uuid.UUID]error
+	transfers        []domain.BalanceTransferRequest
+	transferJobs     []domain.BalanceTransferJob
+	transferFailures []domain.BalanceTransferJobFailure
+
+	// employeeLeave backs ListEmployeeLeaveByType
+	employeeLeave []domain.EmployeeLeaveByType
+
+	// recalculated are the requests saved through RecalculateLeaveRequestDays
+	// with the history recorded; recalculateErrs answer it per request
+	recalculated    []recalculatedRequest
+	recalculateErrs map[uuid.UUID]error
+
+	// history are the entries saved through CreateLeaveRequestHistory
+	history []domain.LeaveRequestHistory
+
+	// delegations back the delegation methods, in creation order
+	delegations []*domain.Delegation
+}
+
+// recalculatedRequest is a request's new day count as saved, with the count
+// it replaced
+type recalculatedRequest struct {
+	request      domain.LeaveRequest
+	previousDays float64
+	history      *domain.LeaveRequestHistory
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		leaveTypes:  make(map[uuid.UUID]*domain.LeaveType),
+		requests:    make(map[uuid.UUID]*domain.LeaveRequest),
+		adjustments: make(map[uuid.UUID]*domain.LeaveBalanceAdjustment),
+		poolMembers: make(map[uuid.UUID][]uuid.UUID),
+		poolNext:    make(map[uuid.UUID]int),
+		compOffs:    make(map[uuid.UUID]*domain.CompOffRequest),
+	}
+}
+
+// httpStatus is the status an error would be served with, 500 for errors
+// that are not application errors and 0 for none
+func httpStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.HTTPStatus
+	}
+	return 500
+}
+
+// newTestService returns a service over repo without an employee directory
+// or holiday provider
+func newTestService(repo *fakeRepository) *leaveService {
+	return NewLeaveService(repo, nil, false, nil).(*leaveService)
+}
+
+func (f *fakeRepository) GetOrganizationSettings(orgID uuid.UUID) (*domain.OrganizationSettings, error) {
+	if f.settings == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return f.settings, nil
+}
+
+func (f *fakeRepository) SaveOrganizationSettings(settings *domain.OrganizationSettings) error {
+	f.settings = settings
+	return nil
+}
+
+func (f *fakeRepository) ListHolidays(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.Holiday, error) {
+	var holidays []domain.Holiday
+	for _, h := range f.holidays {
+		if !h.Date.Before(startDate) && !h.Date.After(endDate) {
+			holidays = append(holidays, h)
+		}
+	}
+	return holidays, nil
+}
+
+func (f *fakeRepository) ListEmployeeLeaveByType(orgID uuid.UUID, startDate, endDate time.Time) ([]domain.EmployeeLeaveByType, error) {
+	return f.employeeLeave, nil
+}
+
+func (f *fakeRepository) ListApproverDecisions(orgID uuid.UUID, from, to time.Time, excludeBackdated bool) ([]domain.ApproverDecision, error) {
+	return f.decisions, nil
+}
+
+func (f *fakeRepository) CountOverduePendingByApprover(orgID uuid.UUID, now time.Time) (map[uuid.UUID]int, error) {
+	return f.overdue, nil
+}
+
+func (f *fakeRepository) GetLeaveType(id uuid.UUID) (*domain.LeaveType, error) {
+	leaveType, ok := f.leaveTypes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return leaveType, nil
+}
+
+func (f *fakeRepository) GetLeaveRequest(id uuid.UUID) (*domain.LeaveRequest, error) {
+	request, ok := f.requests[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return request, nil
+}
+
+func (f *fakeRepository) CreateLeaveRequest(request *domain.LeaveRequest, leaveType *domain.LeaveType, entitlement float64, provisional bool, history *domain.LeaveRequestHistory) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	f.created = request
+	f.createdEntitlement = entitlement
+	f.createdProvisional = provisional
+	f.requests[request.ID] = request
+	return nil
+}
+
+func (f *fakeRepository) UpdatePendingLeaveRequest(request *domain.LeaveRequest, entitlement float64, provisional bool, history *domain.LeaveRequestHistory) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	f.updated = request
+	f.updatedEntitlement = entitlement
+	return nil
+}
+
+func (f *fakeRepository) TransitionLeaveRequest(request *domain.LeaveRequest, fromStatus string, history *domain.LeaveRequestHistory) error {
+	f.transitioned = request.Status
+	return nil
+}
+
+func (f *fakeRepository) PurgeLeaveRequest(request *domain.LeaveRequest) error {
+	if f.purgeErr != nil {
+		return f.purgeErr
+	}
+	f.purged = request
+	delete(f.requests, request.ID)
+	return nil
+}
+
+func (f *fakeRepository) GetBalanceAdjustment(id uuid.UUID) (*domain.LeaveBalanceAdjustment, error) {
+	adjustment, ok := f.adjustments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return adjustment, nil
+}
+
+func (f *fakeRepository) UpdateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error {
+	if err := f.updateAdjustmentErr[adjustment.ID]; err != nil {
+		return err
+	}
+	f.updatedAdjustments = append(f.updatedAdjustments, *adjustment)
+	return nil
+}
+
+// ListDueBalanceAdjustmentIDs returns the scheduled adjustments in
+// adjustments effective on or before today, earliest first
+func (f *fakeRepository) ListDueBalanceAdjustmentIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error) {
+	var due []*domain.LeaveBalanceAdjustment
+	for _, adjustment := range f.adjustments {
+		if adjustment.Status == domain.AdjustmentStatusScheduled && !adjustment.EffectiveDate.After(today) {
+			due = append(due, adjustment)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].EffectiveDate.Before(*due[j].EffectiveDate) })
+	ids := make([]uuid.UUID, len(due))
+	for i, adjustment := range due {
+		ids[i] = adjustment.ID
+	}
+	return ids, nil
+}
+
+func (f *fakeRepository) RevertBalanceAdjustment(id, revertedBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error) {
+	if f.revertErr != nil {
+		return nil, f.revertErr
+	}
+	adjustment := f.adjustments[id]
+	f.reverted = adjustment
+	return &domain.LeaveBalanceAdjustment{
+		LeaveBalanceID: adjustment.LeaveBalanceID,
+		Adjustment:     -adjustment.Adjustment,
+		PerformedBy:    domain.SystemUserID,
+		ApprovedBy:     &revertedBy,
+		Status:         domain.AdjustmentStatusCorrection,
+	}, nil
+}
+
+func (f *fakeRepository) NextPoolApprover(poolID uuid.UUID) (uuid.UUID, error) {
+	members := f.poolMembers[poolID]
+	if len(members) == 0 {
+		return uuid.Nil, repository.ErrEmptyApproverPool
+	}
+	next := members[f.poolNext[poolID]%len(members)]
+	f.poolNext[poolID]++
+	return next, nil
+}
+
+func (f *fakeRepository) IsApproverPoolMember(poolID, userID uuid.UUID) (bool, error) {
+	for _, member := range f.poolMembers[poolID] {
+		if member == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepository) ListApproverPools(orgID uuid.UUID) ([]domain.ApproverPool, error) {
+	var pools []domain.ApproverPool
+	for poolID, members := range f.poolMembers {
+		pool := domain.ApproverPool{Base: domain.Base{ID: poolID}, OrganizationID: orgID}
+		for _, userID := range members {
+			pool.Members = append(pool.Members, domain.ApproverPoolMember{PoolID: poolID, UserID: userID})
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// typeName is the package-qualified name of v's type
+func typeName(v interface{}) string {
+	return fmt.Sprintf("%T", v)
+}
+
+// afterWatermark reports whether (at, id) comes after the params' watermark
+// in change-feed order
+func afterWatermark(at time.Time, id uuid.UUID, params *domain.ChangesParams) bool {
+	if !at.Equal(params.Since) {
+		return at.After(params.Since)
+	}
+	return id.String() > params.SinceID.String()
+}
+
+func (f *fakeRepository) ListLeaveRequestChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveRequest, error) {
+	var changes []domain.LeaveRequest
+	for _, r := range f.changedRequests {
+		if afterWatermark(r.UpdatedAt, r.ID, params) && len(changes) < params.Limit &&
+			(params.EmployeeID == nil || r.EmployeeID == *params.EmployeeID) {
+			changes = append(changes, r)
+		}
+	}
+	return changes, nil
+}
+
+func (f *fakeRepository) ListLeaveBalanceChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalance, error) {
+	var changes []domain.LeaveBalance
+	for _, b := range f.changedBalances {
+		if afterWatermark(b.UpdatedAt, b.ID, params) && len(changes) < params.Limit &&
+			(params.EmployeeID == nil || b.EmployeeID == *params.EmployeeID) {
+			changes = append(changes, b)
+		}
+	}
+	return changes, nil
+}
+
+func (f *fakeRepository) ListHolidayChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.Holiday, error) {
+	var changes []domain.Holiday
+	for _, h := range f.holidays {
+		if afterWatermark(h.UpdatedAt, h.ID, params) && len(changes) < params.Limit {
+			changes = append(changes, h)
+		}
+	}
+	return changes, nil
+}
+
+func (f *fakeRepository) ListPendingLeaveRequests(orgID uuid.UUID) ([]domain.LeaveRequest, error) {
+	var pending []domain.LeaveRequest
+	for _, r := range f.requests {
+		if r.Status == domain.LeaveStatusPending {
+			pending = append(pending, *r)
+		}
+	}
+	return pending, nil
+}
+
+func (f *fakeRepository) ListLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error) {
+	var leaveTypes []domain.LeaveType
+	for _, leaveType := range f.leaveTypes {
+		if leaveType.OrganizationID == orgID {
+			leaveTypes = append(leaveTypes, *leaveType)
+		}
+	}
+	return leaveTypes, nil
+}
+
+// ListLeaveTypesWithOptions pages through the organization's leave types
+// by name; only paging is applied
+func (f *fakeRepository) ListLeaveTypesWithOptions(orgID uuid.UUID, params *domain.ListLeaveTypesParams) ([]domain.LeaveType, int64, error) {
+	leaveTypes, _ := f.ListLeaveTypes(orgID)
+	sort.Slice(leaveTypes, func(i, j int) bool { return leaveTypes[i].Name < leaveTypes[j].Name })
+	total := int64(len(leaveTypes))
+	if params.Page > 0 && params.PageSize > 0 {
+		start := (params.Page - 1) * params.PageSize
+		if start > len(leaveTypes) {
+			start = len(leaveTypes)
+		}
+		leaveTypes = leaveTypes[start:]
+		if len(leaveTypes) > params.PageSize {
+			leaveTypes = leaveTypes[:params.PageSize]
+		}
+	}
+	return leaveTypes, total, nil
+}
+
+func (f *fakeRepository) ListEmployeeBalanceYears(orgID, employeeID uuid.UUID, fromYear, toYear int) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	for _, b := range f.balances {
+		if b.EmployeeID == employeeID && b.Year >= fromYear && b.Year <= toYear {
+			balances = append(balances, b)
+		}
+	}
+	return balances, nil
+}
+
+func (f *fakeRepository) SumForfeitedCarryOver(balanceIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	forfeited := make(map[uuid.UUID]float64)
+	for _, id := range balanceIDs {
+		if days, ok := f.forfeited[id]; ok {
+			forfeited[id] = days
+		}
+	}
+	return forfeited, nil
+}
+
+func (f *fakeRepository) SumLeaveTakenByYear(orgID, employeeID uuid.UUID, fromYear, toYear int) ([]domain.LeaveTakenByYear, error) {
+	var taken []domain.LeaveTakenByYear
+	for _, t := range f.taken {
+		if t.Year >= fromYear && t.Year <= toYear {
+			taken = append(taken, t)
+		}
+	}
+	return taken, nil
+}
+
+func (f *fakeRepository) ListLeaveBalances(orgID, employeeID uuid.UUID, year int) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	for _, b := range f.balances {
+		if b.EmployeeID == employeeID && b.Year == year {
+			balances = append(balances, b)
+		}
+	}
+	return balances, nil
+}
+
+// CreateMissingLeaveBalances adds the balances not yet in balances and
+// replaces every one with the stored balance
+func (f *fakeRepository) CreateMissingLeaveBalances(balances []*domain.LeaveBalance) ([]bool, error) {
+	created := make([]bool, len(balances))
+	for i, balance := range balances {
+		stored := false
+		for _, b := range f.balances {
+			if b.EmployeeID == balance.EmployeeID && b.LeaveTypeID == balance.LeaveTypeID && b.Year == balance.Year {
+				*balance = b
+				stored = true
+				break
+			}
+		}
+		if !stored {
+			balance.ID = uuid.New()
+			f.balances = append(f.balances, *balance)
+			created[i] = true
+		}
+	}
+	return created, nil
+}
+
+func (f *fakeRepository) TransferLeaveBalance(orgID uuid.UUID, req *domain.BalanceTransferRequest, toEntitlement float64) (*domain.BalanceTransfer, error) {
+	if err := f.transferErrs[req.EmployeeID]; err != nil {
+		return nil, err
+	}
+	f.transfers = append(f.transfers, *req)
+	return &domain.BalanceTransfer{
+		TransferID: uuid.New(),
+		Debit:      &domain.LeaveBalanceAdjustment{Adjustment: -req.Days},
+		Credit:     &domain.LeaveBalanceAdjustment{Adjustment: req.Days},
+	}, nil
+}
+
+func (f *fakeRepository) UpdateBalanceTransferJob(job *domain.BalanceTransferJob) error {
+	f.transferJobs = append(f.transferJobs, *job)
+	return nil
+}
+
+func (f *fakeRepository) AddBalanceTransferJobFailure(failure *domain.BalanceTransferJobFailure) error {
+	f.transferFailures = append(f.transferFailures, *failure)
+	return nil
+}
+
+// ListEmployeeLeaveRequestDays lists the breakdown days in [from, to] of
+// the employees' requests in requests with one of the statuses
+func (f *fakeRepository) ListEmployeeLeaveRequestDays(orgID uuid.UUID, employeeIDs []uuid.UUID, from, to time.Time, statuses []string) ([]domain.LeaveRequestDay, error) {
+	var days []domain.LeaveRequestDay
+	for _, request := range f.requests {
+		if !containsID(employeeIDs, request.EmployeeID) || !containsStatus(statuses, request.Status) {
+			continue
+		}
+		breakdown := request.Breakdown
+		if breakdown == nil {
+			breakdown = domain.LeaveDayBreakdown(request.StartDate, request.EndDate, request.StartHalf, request.EndHalf, nil)
+		}
+		for _, d := range breakdown {
+			if !d.Date.Before(from) && !d.Date.After(to) {
+				d.LeaveRequest = request
+				days = append(days, d)
+			}
+		}
+	}
+	return days, nil
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, candidate := range statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeRepository) ListRecalculationBalances(orgID uuid.UUID, employeeID *uuid.UUID, year int) ([]domain.LeaveBalance, error) {
+	var balances []domain.LeaveBalance
+	for _, b := range f.balances {
+		if b.Year == year && (employeeID == nil || b.EmployeeID == *employeeID) {
+			b.LeaveType = f.leaveTypes[b.LeaveTypeID]
+			balances = append(balances, b)
+		}
+	}
+	return balances, nil
+}
+
+func (f *fakeRepository) ExpectedBalanceFigures(balance *domain.LeaveBalance, fallbackOpening float64) (domain.BalanceFigures, error) {
+	return f.expected[balance.ID], nil
+}
+
+func (f *fakeRepository) CorrectLeaveBalance(balanceID uuid.UUID, fallbackOpening float64) (*domain.BalanceDiscrepancy, error) {
+	if f.correctedOpening == nil {
+		f.correctedOpening = make(map[uuid.UUID]float64)
+	}
+	f.correctedOpening[balanceID] = fallbackOpening
+	if f.correctErr != nil {
+		return nil, f.correctErr
+	}
+	return f.corrections[balanceID], nil
+}
+
+func (f *fakeRepository) SumScheduledAdjustments(balanceIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	return map[uuid.UUID]float64{}, nil
+}
+
+func (f *fakeRepository) ListEmployeeLeaveRequestsStarting(orgID, employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) ListBalancesAdjustments(balanceIDs []uuid.UUID) ([]domain.LeaveBalanceAdjustment, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) ListOpeningBalanceSnapshots(orgID, employeeID uuid.UUID, year int) ([]domain.BalanceSnapshot, error) {
+	var snapshots []domain.BalanceSnapshot
+	for _, snapshot := range f.snapshots {
+		if snapshot.EmployeeID == employeeID && snapshot.Year == year {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+func (f *fakeRepository) ListBalanceSnapshots(orgID uuid.UUID, params *domain.ListBalanceSnapshotsParams) ([]domain.BalanceSnapshot, int64, error) {
+	f.snapshotParams = params
+	return f.snapshots, int64(len(f.snapshots)), nil
+}
+
+func (f *fakeRepository) ListEntitlementLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error) {
+	return f.ListLeaveTypes(orgID)
+}
+
+func (f *fakeRepository) InitializeYearlyBalance(orgID, employeeID, runID uuid.UUID, leaveTypes []domain.LeaveType, year int, hoursPerDay float64) (domain.YearlyResetCounts, error) {
+	f.resetRuns = append(f.resetRuns, runID)
+	return domain.YearlyResetCounts{Created: len(leaveTypes)}, nil
+}
+
+func (f *fakeRepository) GetLeaveBalanceByID(id uuid.UUID) (*domain.LeaveBalance, error) {
+	for i := range f.balances {
+		if f.balances[i].ID == id {
+			balance := f.balances[i]
+			return &balance, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) GetLeaveBalance(employeeID, leaveTypeID uuid.UUID, year int) (*domain.LeaveBalance, error) {
+	for i := range f.balances {
+		if b := f.balances[i]; b.EmployeeID == employeeID && b.LeaveTypeID == leaveTypeID && b.Year == year {
+			return &b, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) CreateBalanceAdjustment(adjustment *domain.LeaveBalanceAdjustment) error {
+	adjustment.ID = uuid.New()
+	f.createdAdjustment = adjustment
+	return nil
+}
+
+func (f *fakeRepository) ListAccrualLeaveTypes(orgID uuid.UUID) ([]domain.LeaveType, error) {
+	return f.ListLeaveTypes(orgID)
+}
+
+func (f *fakeRepository) ListLeaveTypeBalanceIDs(leaveTypeID uuid.UUID, year int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, b := range f.balances {
+		if b.LeaveTypeID == leaveTypeID && b.Year == year {
+			ids = append(ids, b.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeRepository) AccrueBalance(balanceID uuid.UUID, leaveType *domain.LeaveType, period string, hoursPerDay float64) (*domain.AccrualRun, error) {
+	for i, b := range f.balances {
+		if b.ID == balanceID {
+			return f.accrualRuns[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) ListAbsences(orgID uuid.UUID, leaveTypeIDs []uuid.UUID, startDate, endDate time.Time) ([]domain.Absence, error) {
+	f.absenceWindow = [2]time.Time{startDate, endDate}
+	if len(leaveTypeIDs) == 0 {
+		return nil, nil
+	}
+	var absences []domain.Absence
+	for _, a := range f.absences {
+		if !a.EndDate.Before(startDate) && !a.StartDate.After(endDate) {
+			absences = append(absences, a)
+		}
+	}
+	return absences, nil
+}
+
+func (f *fakeRepository) ListAbsenceMonitoredLeaveTypeIDs(orgID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, lt := range f.leaveTypes {
+		if lt.OrganizationID == orgID && lt.AbsenceMonitored {
+			ids = append(ids, lt.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeRepository) ListUnclearedAbsenceAlerts(orgID uuid.UUID) ([]domain.AbsenceAlert, error) {
+	var alerts []domain.AbsenceAlert
+	for _, a := range f.absenceAlerts {
+		if a.OrganizationID == orgID && a.ClearedAt == nil {
+			alerts = append(alerts, *a)
+		}
+	}
+	return alerts, nil
+}
+
+func (f *fakeRepository) CreateAbsenceAlert(alert *domain.AbsenceAlert) (bool, error) {
+	for _, a := range f.absenceAlerts {
+		if a.OrganizationID == alert.OrganizationID && a.EmployeeID == alert.EmployeeID && a.ClearedAt == nil {
+			return false, nil
+		}
+	}
+	alert.ID = uuid.New()
+	stored := *alert
+	f.absenceAlerts = append(f.absenceAlerts, &stored)
+	return true, nil
+}
+
+func (f *fakeRepository) ClearAbsenceAlert(id uuid.UUID, at time.Time) error {
+	for _, a := range f.absenceAlerts {
+		if a.ID == id && a.ClearedAt == nil {
+			a.ClearedAt = &at
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) MarkAbsenceAlertNotified(id uuid.UUID, at time.Time) error {
+	for _, a := range f.absenceAlerts {
+		if a.ID == id {
+			a.NotifiedAt = &at
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) GetAbsenceAlert(id uuid.UUID) (*domain.AbsenceAlert, error) {
+	for _, a := range f.absenceAlerts {
+		if a.ID == id {
+			alert := *a
+			return &alert, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) AcknowledgeAbsenceAlert(alert *domain.AbsenceAlert) error {
+	return f.saveAbsenceAlert(alert, (*domain.AbsenceAlert).CanAcknowledge)
+}
+
+func (f *fakeRepository) ResolveAbsenceAlert(alert *domain.AbsenceAlert) error {
+	return f.saveAbsenceAlert(alert, (*domain.AbsenceAlert).CanResolve)
+}
+
+func (f *fakeRepository) saveAbsenceAlert(alert *domain.AbsenceAlert, allowed func(*domain.AbsenceAlert) bool) error {
+	for i, a := range f.absenceAlerts {
+		if a.ID == alert.ID {
+			if !allowed(a) {
+				return repository.ErrStatusChanged
+			}
+			saved := *alert
+			f.absenceAlerts[i] = &saved
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) GetOverlappingRequests(employeeID uuid.UUID, startDate, endDate time.Time) ([]domain.LeaveRequest, error) {
+	var overlapping []domain.LeaveRequest
+	for _, r := range f.requests {
+		active := false
+		for _, status := range domain.ActiveLeaveStatuses {
+			active = active || r.Status == status
+		}
+		if r.EmployeeID == employeeID && active && !r.StartDate.After(endDate) && !r.EndDate.Before(startDate) {
+			overlapping = append(overlapping, *r)
+		}
+	}
+	return overlapping, nil
+}
+
+func (f *fakeRepository) ListLeaveRequestHistory(leaveRequestID uuid.UUID) ([]domain.LeaveRequestHistory, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) ReopenLeaveRequest(request *domain.LeaveRequest, fromStatus string, leaveType *domain.LeaveType, history *domain.LeaveRequestHistory) error {
+	f.transitioned = request.Status
+	return nil
+}
+
+func (f *fakeRepository) ListOptionalHolidayClaims(orgID uuid.UUID, employeeID *uuid.UUID, from, to time.Time) ([]domain.OptionalHolidayClaim, error) {
+	var claims []domain.OptionalHolidayClaim
+	for _, c := range f.claims {
+		if employeeID == nil || c.EmployeeID == *employeeID {
+			claims = append(claims, c)
+		}
+	}
+	return claims, nil
+}
+
+func (f *fakeRepository) GetHoliday(id uuid.UUID) (*domain.Holiday, error) {
+	for _, h := range f.holidays {
+		if h.ID == id {
+			return &h, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// CreateOptionalHolidayClaim adds the claim to claims, refusing it like
+// the repository does
+func (f *fakeRepository) CreateOptionalHolidayClaim(claim *domain.OptionalHolidayClaim, year, quota int) error {
+	claimed := 0
+	for _, c := range f.claims {
+		if c.EmployeeID != claim.EmployeeID {
+			continue
+		}
+		if c.HolidayID == claim.HolidayID {
+			return repository.ErrDuplicateOptionalHolidayClaim
+		}
+		if holiday, err := f.GetHoliday(c.HolidayID); err == nil && holiday.Date.Year() == year {
+			claimed++
+		}
+	}
+	if quota > 0 && claimed >= quota {
+		return repository.ErrOptionalHolidayQuotaReached
+	}
+	claim.ID = uuid.New()
+	f.claims = append(f.claims, *claim)
+	return nil
+}
+
+func (f *fakeRepository) GetOptionalHolidayClaim(employeeID, holidayID uuid.UUID) (*domain.OptionalHolidayClaim, error) {
+	for _, c := range f.claims {
+		if c.EmployeeID == employeeID && c.HolidayID == holidayID {
+			c.Holiday, _ = f.GetHoliday(holidayID)
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) DeleteOptionalHolidayClaim(id uuid.UUID) error {
+	for i, c := range f.claims {
+		if c.ID == id {
+			f.claims = append(f.claims[:i], f.claims[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) ListHolidaysOnDate(orgID uuid.UUID, date time.Time) ([]domain.Holiday, error) {
+	var holidays []domain.Holiday
+	for _, h := range f.holidays {
+		if h.OrganizationID == orgID && h.Date.Equal(date) {
+			holidays = append(holidays, h)
+		}
+	}
+	return holidays, nil
+}
+
+func (f *fakeRepository) CreateHoliday(holiday *domain.Holiday) error {
+	holiday.ID = uuid.New()
+	f.holidays = append(f.holidays, *holiday)
+	return nil
+}
+
+func (f *fakeRepository) UpdateHoliday(holiday *domain.Holiday) error {
+	for i := range f.holidays {
+		if f.holidays[i].ID == holiday.ID {
+			f.holidays[i] = *holiday
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) DeleteHoliday(id uuid.UUID) error {
+	for i := range f.holidays {
+		if f.holidays[i].ID == id {
+			f.holidays = append(f.holidays[:i], f.holidays[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) ListLeaveRequestsCovering(orgID uuid.UUID, dates []time.Time, endingFrom time.Time) ([]domain.LeaveRequest, error) {
+	var covering []domain.LeaveRequest
+	for _, r := range f.requests {
+		if r.OrganizationID != orgID || !containsStatus(domain.ActiveLeaveStatuses, r.Status) || r.EndDate.Before(endingFrom) {
+			continue
+		}
+		for _, date := range dates {
+			if !r.StartDate.After(date) && !r.EndDate.Before(date) {
+				covering = append(covering, *r)
+				break
+			}
+		}
+	}
+	sort.Slice(covering, func(i, j int) bool { return covering[i].StartDate.Before(covering[j].StartDate) })
+	return covering, nil
+}
+
+func (f *fakeRepository) RecalculateLeaveRequestDays(request *domain.LeaveRequest, previousDays float64, history *domain.LeaveRequestHistory) error {
+	if err := f.recalculateErrs[request.ID]; err != nil {
+		return err
+	}
+	f.recalculated = append(f.recalculated, recalculatedRequest{request: *request, previousDays: previousDays, history: history})
+	return nil
+}
+
+func (f *fakeRepository) CreateCompOffRequest(request *domain.CompOffRequest) error {
+	for _, r := range f.compOffs {
+		if r.EmployeeID == request.EmployeeID && r.DateWorked.Equal(request.DateWorked) &&
+			r.Status != domain.CompOffStatusRejected {
+			return repository.ErrDuplicateCompOff
+		}
+	}
+	request.ID = uuid.New()
+	saved := *request
+	f.compOffs[request.ID] = &saved
+	return nil
+}
+
+func (f *fakeRepository) GetCompOffRequest(id uuid.UUID) (*domain.CompOffRequest, error) {
+	request, ok := f.compOffs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	loaded := *request
+	return &loaded, nil
+}
+
+func (f *fakeRepository) RejectCompOffRequest(request *domain.CompOffRequest) error {
+	if !f.compOffs[request.ID].CanDecide() {
+		return repository.ErrStatusChanged
+	}
+	saved := *request
+	f.compOffs[request.ID] = &saved
+	return nil
+}
+
+func (f *fakeRepository) ApproveCompOffRequest(request *domain.CompOffRequest, leaveTypeID uuid.UUID, year int, opening, credit float64) error {
+	if !f.compOffs[request.ID].CanDecide() {
+		return repository.ErrStatusChanged
+	}
+	var balance *domain.LeaveBalance
+	for i := range f.balances {
+		b := &f.balances[i]
+		if b.EmployeeID == request.EmployeeID && b.LeaveTypeID == leaveTypeID && b.Year == year {
+			balance = b
+		}
+	}
+	if balance == nil {
+		f.balances = append(f.balances, domain.LeaveBalance{
+			Base:           domain.Base{ID: uuid.New()},
+			OrganizationID: request.OrganizationID,
+			EmployeeID:     request.EmployeeID,
+			LeaveTypeID:    leaveTypeID,
+			Year:           year,
+			TotalDays:      opening,
+		})
+		balance = &f.balances[len(f.balances)-1]
+	}
+	balance.TotalDays += credit
+
+	request.LeaveBalanceID = &balance.ID
+	request.Credited = &credit
+	saved := *request
+	f.compOffs[request.ID] = &saved
+	return nil
+}
+
+func (f *fakeRepository) ListExpiredCompOffIDs(orgID uuid.UUID, today time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for id, r := range f.compOffs {
+		if r.OrganizationID == orgID && r.Status == domain.CompOffStatusApproved &&
+			r.ExpiresOn != nil && r.ExpiresOn.Before(today) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ExpireCompOff forfeits what is left of the credit like the repository
+// does, up to the days still unused on the balance
+func (f *fakeRepository) ExpireCompOff(id uuid.UUID, today time.Time) (*domain.LeaveBalanceAdjustment, error) {
+	request := f.compOffs[id]
+	request.Status = domain.CompOffStatusExpired
+	for i := range f.balances {
+		balance := &f.balances[i]
+		if balance.ID != *request.LeaveBalanceID {
+			continue
+		}
+		if forfeited := math.Min(*request.Credited, balance.Remaining()); forfeited > 0 {
+			balance.TotalDays -= forfeited
+			return &domain.LeaveBalanceAdjustment{LeaveBalanceID: balance.ID, Adjustment: -forfeited}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) ClaimJob(lease time.Duration) (*domain.Job, error) {
+	f.jobsMu.Lock()
+	defer f.jobsMu.Unlock()
+	for _, job := range f.jobs {
+		if job.Status == domain.JobStatusQueued {
+			job.Status = domain.JobStatusRunning
+			job.Attempts++
+			claimed := *job
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) UpdateJobProgress(job *domain.Job, lease time.Duration) error {
+	return f.jobLeaseErr
+}
+
+func (f *fakeRepository) FinishJob(job *domain.Job) error {
+	f.jobsMu.Lock()
+	defer f.jobsMu.Unlock()
+	f.finishedJobs = append(f.finishedJobs, *job)
+	return nil
+}
+
+// ListMonthlyLeave totals the approved leave falling in [startDate, endDate)
+// per month from the requests' breakdowns, as the repository does
+func (f *fakeRepository) ListMonthlyLeave(orgID uuid.UUID, leaveTypeID *uuid.UUID, startDate, endDate time.Time) ([]domain.MonthlyStats, error) {
+	byMonth := make(map[time.Time]*domain.MonthlyStats)
+	var months []time.Time
+	for _, r := range f.requests {
+		if r.OrganizationID != orgID || (leaveTypeID != nil && r.LeaveTypeID != *leaveTypeID) ||
+			(r.Status != domain.LeaveStatusApproved && r.Status != domain.LeaveStatusCancellationRequested) {
+			continue
+		}
+		counted := make(map[time.Time]bool)
+		for _, day := range r.Breakdown {
+			if day.Date.Before(startDate) || !day.Date.Before(endDate) {
+				continue
+			}
+			month := time.Date(day.Date.Year(), day.Date.Month(), 1, 0, 0, 0, 0, time.UTC)
+			m, ok := byMonth[month]
+			if !ok {
+				m = &domain.MonthlyStats{Month: month}
+				byMonth[month] = m
+				months = append(months, month)
+			}
+			if !counted[month] {
+				counted[month] = true
+				m.Count++
+			}
+			m.TotalDays += day.Fraction * r.Days / r.RawDays
+		}
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+	stats := make([]domain.MonthlyStats, 0, len(months))
+	for _, month := range months {
+		stats = append(stats, *byMonth[month])
+	}
+	return stats, nil
+}
+
+func (f *fakeRepository) CreateLeaveRequestHistory(history *domain.LeaveRequestHistory) error {
+	f.history = append(f.history, *history)
+	return nil
+}
+
+func (f *fakeRepository) CreateDelegation(delegation *domain.Delegation) (bool, error) {
+	for _, d := range f.delegations {
+		if d.LeaveRequestID == delegation.LeaveRequestID {
+			return false, nil
+		}
+	}
+	delegation.ID = uuid.New()
+	stored := *delegation
+	f.delegations = append(f.delegations, &stored)
+	return true, nil
+}
+
+func (f *fakeRepository) GetDelegation(id uuid.UUID) (*domain.Delegation, error) {
+	for _, d := range f.delegations {
+		if d.ID == id {
+			delegation := *d
+			return &delegation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) GetLeaveRequestDelegation(leaveRequestID uuid.UUID) (*domain.Delegation, error) {
+	for _, d := range f.delegations {
+		if d.LeaveRequestID == leaveRequestID {
+			delegation := *d
+			return &delegation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) UpdateDelegation(delegation *domain.Delegation, allowed func(*domain.Delegation) bool) error {
+	for i, d := range f.delegations {
+		if d.ID == delegation.ID {
+			if !allowed(d) {
+				return repository.ErrStatusChanged
+			}
+			saved := *delegation
+			f.delegations[i] = &saved
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepository) MarkDelegationNotified(id uuid.UUID, at time.Time) error {
+	for _, d := range f.delegations {
+		if d.ID == id {
+			d.NotifiedAt = &at
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) ListOverdueDelegations(now time.Time) ([]domain.Delegation, error) {
+	var overdue []domain.Delegation
+	for _, d := range f.delegations {
+		if d.IsOverdue(now) {
+			overdue = append(overdue, *d)
+		}
+	}
+	return overdue, nil
+}
+
+func (f *fakeRepository) ListDelegators(orgID, delegateID uuid.UUID, day time.Time) ([]uuid.UUID, error) {
+	var delegators []uuid.UUID
+	for _, d := range f.delegations {
+		if d.OrganizationID == orgID && d.DelegateID != nil && *d.DelegateID == delegateID &&
+			d.Status == domain.DelegationStatusConfirmed && !d.StartDate.After(day) && !d.EndDate.Before(day) {
+			delegators = append(delegators, d.DelegatorID)
+		}
+	}
+	return delegators, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// ImportHolidays adds the uploaded holidays to the organization calendar,
+// each validated like a single create and created on its own, so a bad row
+// only fails itself. Of two rows on the same date for the same employees
+// the later one fails. A dry run validates every row against the calendar
+// and creates nothing.
+func (s *leaveService) ImportHolidays(orgID uuid.UUID, rows []domain.HolidayImportRow, dryRun bool) (*domain.HolidayImportReport, error) {
+	report := &domain.HolidayImportReport{
+		DryRun:  dryRun,
+		Rows:    len(rows),
+		Results: make([]domain.HolidayImportResult, 0, len(rows)),
+	}
+
+	var seen []importedHoliday
+	for _, row := range rows {
+		result := s.importHolidayRow(orgID, row, dryRun, &seen)
+		switch result.Status {
+		case domain.HolidayImportCreated:
+			report.Created++
+		case domain.HolidayImportValid:
+			report.Valid++
+		default:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	log.Printf("Holiday import of %s finished: %d rows, %d created, %d valid, %d failed (dry run: %t)",
+		orgID, report.Rows, report.Created, report.Valid, report.Failed, dryRun)
+	return report, nil
+}
+
+// importedHoliday is the holiday of an earlier row of an import
+type importedHoliday struct {
+	line    int
+	holiday *domain.Holiday
+}
+
+// importHolidayRow validates and, unless dryRun, creates one holiday. seen
+// holds the holidays of earlier rows that passed validation.
+func (s *leaveService) importHolidayRow(orgID uuid.UUID, row domain.HolidayImportRow, dryRun bool, seen *[]importedHoliday) domain.HolidayImportResult {
+	result := domain.HolidayImportResult{Line: row.Line, Name: row.Name, Location: row.Location, Status: domain.HolidayImportFailed}
+
+	date, err := row.ParseDate()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Date = &date
+
+	holiday := &domain.Holiday{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(row.Name),
+		Date:           date,
+		Type:           strings.ToLower(strings.TrimSpace(row.Type)),
+		Location:       domain.HolidayLocation(row.Location),
+	}
+	if err := validateHoliday(holiday); err != nil {
+		result.Error = importErrorMessage(err)
+		return result
+	}
+
+	for _, earlier := range *seen {
+		if earlier.holiday.Date.Equal(date) && earlier.holiday.Overlaps(holiday) {
+			result.Error = fmt.Sprintf("line %d is already a holiday on %s", earlier.line, date.Format("2006-01-02"))
+			return result
+		}
+	}
+	*seen = append(*seen, importedHoliday{line: row.Line, holiday: holiday})
+
+	if dryRun {
+		err = s.checkHolidayDateFree(holiday)
+	} else {
+		err = s.CreateHoliday(holiday, nil)
+	}
+	if err != nil {
+		if msg := importErrorMessage(err); msg != "" {
+			result.Error = msg
+		} else {
+			log.Printf("Error: holiday import cannot save line %d in %s: %v", row.Line, orgID, err)
+			result.Error = "cannot save the holiday"
+		}
+		return result
+	}
+
+	if dryRun {
+		result.Status = domain.HolidayImportValid
+		return result
+	}
+	result.Status = domain.HolidayImportCreated
+	result.HolidayID = &holiday.ID
+	return result
+}
+
+// importErrorMessage returns the message of an application error, or ""
+// for errors not meant for the caller
+func importErrorMessage(err error) string {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return ""
+}
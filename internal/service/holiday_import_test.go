@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// holidayImportRows are an upload with one row of each outcome. May Day is
+// already in the calendar.
+var holidayImportRows = []domain.HolidayImportRow{
+	{Line: 2, Name: " Vesak ", Date: "2026-05-12", Type: "Public"},
+	{Line: 3, Name: "Vesak Day 2", Date: "12/05/2026", Type: "public"},
+	{Line: 4, Name: "Retreat", Date: "2026-06-01", Type: "regional"},
+	{Line: 5, Name: "Vesak (north)", Date: "2026-05-12", Type: "public", Location: "LK-N"},
+	{Line: 6, Name: "Labour Day", Date: "2026-05-01", Type: "public"},
+	// Another location on the same date does not clash
+	{Line: 7, Name: "Thai Pongal", Date: "2026-01-14", Type: "public", Location: "LK-N"},
+	{Line: 8, Name: "Duruthu Poya", Date: "2026-01-14", Type: "public", Location: "LK-S"},
+}
+
+var holidayImportOutcomes = []struct {
+	line    int
+	failure string
+}{
+	{line: 2},
+	{line: 3, failure: "date must be in YYYY-MM-DD format"},
+	{line: 4, failure: "holiday type must be public, company or optional"},
+	{line: 5, failure: "line 2 is already a holiday on 2026-05-12"},
+	{line: 6, failure: "May Day already falls on 2026-05-01 for the whole organization, employees observe one holiday per date"},
+	{line: 7},
+	{line: 8},
+}
+
+func TestImportHolidays(t *testing.T) {
+	orgID := uuid.New()
+	for _, dryRun := range []bool{false, true} {
+		repo := newFakeRepository()
+		repo.holidays = []domain.Holiday{{Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, Name: "May Day", Date: date(2026, time.May, 1), Type: domain.HolidayTypePublic}}
+
+		report, err := newTestService(repo).ImportHolidays(orgID, holidayImportRows, dryRun)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		okStatus := domain.HolidayImportCreated
+		if dryRun {
+			okStatus = domain.HolidayImportValid
+		}
+		if len(report.Results) != len(holidayImportOutcomes) {
+			t.Fatalf("dry run %t: results = %+v", dryRun, report.Results)
+		}
+		for i, want := range holidayImportOutcomes {
+			result := report.Results[i]
+			wantStatus := okStatus
+			if want.failure != "" {
+				wantStatus = domain.HolidayImportFailed
+			}
+			if result.Line != want.line || result.Status != wantStatus || result.Error != want.failure {
+				t.Errorf("dry run %t: line %d = %s %q, want %s %q", dryRun, result.Line, result.Status, result.Error, wantStatus, want.failure)
+			}
+			if (result.HolidayID != nil) != (wantStatus == domain.HolidayImportCreated) {
+				t.Errorf("dry run %t: line %d has holiday id %v", dryRun, result.Line, result.HolidayID)
+			}
+		}
+
+		wantCreated, wantValid := 3, 0
+		if dryRun {
+			wantCreated, wantValid = 0, 3
+		}
+		if report.DryRun != dryRun || report.Rows != 7 || report.Created != wantCreated || report.Valid != wantValid || report.Failed != 4 {
+			t.Errorf("report = %+v", report)
+		}
+		if len(repo.holidays) != 1+wantCreated {
+			t.Fatalf("dry run %t: calendar holds %d holidays, want %d", dryRun, len(repo.holidays), 1+wantCreated)
+		}
+		if !dryRun {
+			vesak := repo.holidays[1]
+			if vesak.Name != "Vesak" || vesak.Type != domain.HolidayTypePublic || vesak.Location != nil ||
+				!vesak.Date.Equal(date(2026, time.May, 12)) {
+				t.Errorf("saved %+v, want the row trimmed and lower-cased", vesak)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// recalculateHolidayLeave counts again the days of the organization's
+// pending and approved requests, not yet ended, that include the date of
+// the holiday before or after the change, before being nil for a new
+// holiday and after for a removed one. Unless previewing, requests whose
+// count changes are saved with their balances charged the difference and a
+// history entry; a request that fails keeps its old days and is listed
+// with the error.
+func (s *leaveService) recalculateHolidayLeave(orgID uuid.UUID, before, after *domain.Holiday, change *domain.HolidayChange) (*domain.HolidayRecalculation, error) {
+	result := &domain.HolidayRecalculation{
+		Preview:       change.PreviewImpact,
+		LeaveRequests: []domain.RecalculatedLeaveRequest{},
+	}
+
+	var dates []time.Time
+	for _, h := range []*domain.Holiday{before, after} {
+		if h == nil {
+			continue
+		}
+		date := domain.TruncateToDate(h.Date)
+		if len(dates) == 0 || !dates[0].Equal(date) {
+			dates = append(dates, date)
+		}
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.leaveRepo.ListLeaveRequestsCovering(orgID, dates, settings.Today())
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make(map[uuid.UUID]string)
+	for i := range requests {
+		request := &requests[i]
+		location, ok := locations[request.EmployeeID]
+		if !ok {
+			location = s.employeeLocation(change.AuthToken, orgID, request.EmployeeID)
+			locations[request.EmployeeID] = location
+		}
+		if request.LeaveType == nil ||
+			(before == nil || !before.AppliesTo(location)) && (after == nil || !after.AppliesTo(location)) {
+			continue
+		}
+
+		holidays, err := s.changedEmployeeHolidays(request, location, before, after)
+		if err != nil {
+			return nil, err
+		}
+		breakdown, rawDays, days := recountLeaveRequest(settings, request, holidays)
+		if math.Abs(days-request.Days) < 0.005 && math.Abs(rawDays-request.RawDays) < 0.005 {
+			continue
+		}
+
+		recalculated := domain.RecalculatedLeaveRequest{
+			LeaveRequestID: request.ID,
+			EmployeeID:     request.EmployeeID,
+			LeaveTypeID:    request.LeaveTypeID,
+			Status:         request.Status,
+			StartDate:      request.StartDate,
+			EndDate:        request.EndDate,
+			OldDays:        request.Days,
+			NewDays:        days,
+			Delta:          days - request.Days,
+		}
+		result.Requests++
+
+		if !change.PreviewImpact {
+			previousDays := request.Days
+			request.Days = days
+			request.RawDays = rawDays
+			request.SetBreakdown(breakdown)
+			history := &domain.LeaveRequestHistory{
+				LeaveRequestID: request.ID,
+				Action:         domain.LeaveActionRecalculate,
+				Status:         request.Status,
+				Comments:       domain.HolidayRecalculationComment,
+				PerformedBy:    change.PerformedBy,
+			}
+			if err := s.leaveRepo.RecalculateLeaveRequestDays(request, previousDays, history); err != nil {
+				log.Printf("Error: cannot recalculate leave request %s after a holiday change: %v", request.ID, err)
+				recalculated.Error = recalculationErrorMessage(err)
+				result.Failed++
+			}
+		}
+		result.LeaveRequests = append(result.LeaveRequests, recalculated)
+	}
+	return result, nil
+}
+
+// applyHolidayRecalculation recalculates the leave a saved holiday change
+// affects. The change stands either way, so a failure is logged rather than
+// returned.
+func (s *leaveService) applyHolidayRecalculation(orgID uuid.UUID, before, after *domain.Holiday, change *domain.HolidayChange) *domain.HolidayRecalculation {
+	result, err := s.recalculateHolidayLeave(orgID, before, after, change)
+	if err != nil {
+		log.Printf("Error: cannot recalculate leave after a holiday change in %s: %v", orgID, err)
+		return nil
+	}
+	if result.Requests > 0 {
+		log.Printf("Holiday change in %s recalculated %d leave requests, %d failed", orgID, result.Requests, result.Failed)
+	}
+	return result
+}
+
+// changedEmployeeHolidays lists the holidays the employee observes over a
+// request as they are once the holiday changes from before to after,
+// whether or not the change is saved yet
+func (s *leaveService) changedEmployeeHolidays(request *domain.LeaveRequest, location string, before, after *domain.Holiday) ([]domain.Holiday, error) {
+	start, end := domain.TruncateToDate(request.StartDate), domain.TruncateToDate(request.EndDate)
+	current, err := s.employeeHolidays(request.OrganizationID, request.EmployeeID, location, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := make([]domain.Holiday, 0, len(current)+1)
+	for _, h := range current {
+		if (before != nil && h.ID == before.ID) || (after != nil && after.ID != uuid.Nil && h.ID == after.ID) {
+			continue
+		}
+		holidays = append(holidays, h)
+	}
+
+	if after == nil || !after.AppliesTo(location) {
+		return holidays, nil
+	}
+	date := domain.TruncateToDate(after.Date)
+	if date.Before(start) || date.After(end) {
+		return holidays, nil
+	}
+	if after.Type == domain.HolidayTypeOptional {
+		// A new optional holiday has no claims yet
+		if after.ID == uuid.Nil {
+			return holidays, nil
+		}
+		_, err := s.leaveRepo.GetOptionalHolidayClaim(request.EmployeeID, after.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return holidays, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(holidays, *after), nil
+}
+
+// recountLeaveRequest counts a request's days around holidays as when it
+// was made, without the limits that only apply to new requests. Hourly
+// leave keeps its hours unless its day is no longer a working day.
+func recountLeaveRequest(settings *domain.OrganizationSettings, request *domain.LeaveRequest, holidays []domain.Holiday) ([]domain.LeaveRequestDay, float64, float64) {
+	if request.LeaveType.IsHourly() {
+		day := domain.TruncateToDate(request.StartDate)
+		breakdown := domain.LeaveDayBreakdown(day, day, "", "", holidays)
+		if len(breakdown) == 0 {
+			return nil, 0, 0
+		}
+		breakdown[0].Fraction = request.Hours / settings.HoursPerDay
+		return breakdown, request.Hours, request.Hours
+	}
+	return countLeaveDays(settings, request.LeaveType, request.StartDate, request.EndDate, request.StartHalf, request.EndHalf, holidays)
+}
+
+// recalculationErrorMessage explains why a request kept its old days
+func recalculationErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrStatusChanged):
+		return "the leave request changed while it was recalculated"
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "leave balance not found for this leave request"
+	default:
+		return "the leave request could not be recalculated"
+	}
+}
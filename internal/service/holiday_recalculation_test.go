@@ -0,0 +1,233 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// addSpanRequest registers a request of leaveType for employeeID from start
+// to end counted as days
+func (f *fakeRepository) addSpanRequest(leaveType *domain.LeaveType, employeeID uuid.UUID, start, end time.Time, status string, days float64) *domain.LeaveRequest {
+	request := f.addDayRequest(leaveType, employeeID, start, "", "", status)
+	request.EndDate = end
+	request.Days, request.RawDays = days, days
+	return request
+}
+
+// recalculatedDays maps the requests a recalculation lists to their old
+// and new days
+func recalculatedDays(t *testing.T, result *domain.HolidayRecalculation) map[uuid.UUID][2]float64 {
+	t.Helper()
+	if result == nil {
+		t.Fatal("no recalculation was returned")
+	}
+	days := make(map[uuid.UUID][2]float64, len(result.LeaveRequests))
+	for _, r := range result.LeaveRequests {
+		if r.Delta != r.NewDays-r.OldDays {
+			t.Errorf("request %s: delta %v from %v to %v days", r.LeaveRequestID, r.Delta, r.OldDays, r.NewDays)
+		}
+		days[r.LeaveRequestID] = [2]float64{r.OldDays, r.NewDays}
+	}
+	return days
+}
+
+func TestCreateHolidayRecalculatesLeave(t *testing.T) {
+	for _, preview := range []bool{false, true} {
+		orgID, employeeID, adminID := uuid.New(), uuid.New(), uuid.New()
+		monday := upcomingMonday()
+		repo := newFakeRepository()
+		leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+		week := repo.addSpanRequest(leaveType, employeeID, monday, monday.AddDate(0, 0, 4), domain.LeaveStatusApproved, 5)
+		midweek := repo.addSpanRequest(leaveType, uuid.New(), monday.AddDate(0, 0, 1), monday.AddDate(0, 0, 2), domain.LeaveStatusPending, 2)
+		// Neither a rejected request nor one the holiday falls outside is recounted
+		repo.addSpanRequest(leaveType, uuid.New(), monday, monday.AddDate(0, 0, 4), domain.LeaveStatusRejected, 5)
+		repo.addSpanRequest(leaveType, employeeID, monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 8), domain.LeaveStatusApproved, 2)
+
+		holiday := &domain.Holiday{OrganizationID: orgID, Name: "Poya", Date: monday.AddDate(0, 0, 2), Type: domain.HolidayTypePublic}
+		err := newTestService(repo).CreateHoliday(holiday, &domain.HolidayChange{PerformedBy: adminID, PreviewImpact: preview})
+		if err != nil {
+			t.Fatalf("preview %t: unexpected error: %v", preview, err)
+		}
+
+		result := holiday.Recalculation
+		days := recalculatedDays(t, result)
+		if result.Preview != preview || result.Requests != 2 || result.Failed != 0 || len(days) != 2 ||
+			days[week.ID] != [2]float64{5, 4} || days[midweek.ID] != [2]float64{2, 1} {
+			t.Errorf("preview %t: recalculation = %+v", preview, result)
+		}
+
+		if preview {
+			if len(repo.holidays) != 0 || len(repo.recalculated) != 0 {
+				t.Errorf("a preview saved %d holidays and %d requests", len(repo.holidays), len(repo.recalculated))
+			}
+			continue
+		}
+		if len(repo.holidays) != 1 || len(repo.recalculated) != 2 {
+			t.Fatalf("saved %d holidays and %d requests, want 1 and 2", len(repo.holidays), len(repo.recalculated))
+		}
+		for _, saved := range repo.recalculated {
+			history := saved.history
+			if history.LeaveRequestID != saved.request.ID || history.Action != domain.LeaveActionRecalculate ||
+				history.Comments != domain.HolidayRecalculationComment || history.PerformedBy != adminID ||
+				history.Status != saved.request.Status {
+				t.Errorf("history = %+v", history)
+			}
+			if saved.request.Days != saved.previousDays-1 || saved.request.RawDays != saved.request.Days ||
+				len(saved.request.Breakdown) == 0 {
+				t.Errorf("request %s saved with %v days over %v", saved.request.ID, saved.request.Days, saved.previousDays)
+			}
+		}
+	}
+}
+
+func TestUpdateHolidayRecalculatesLeave(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(monday time.Time) *domain.UpdateHolidayRequest
+		claimed bool
+		// wantDays are the new days of this and next week's requests, 0
+		// when left alone
+		wantDays [2]float64
+	}{
+		{name: "moved to next week", update: func(monday time.Time) *domain.UpdateHolidayRequest {
+			next := monday.AddDate(0, 0, 9)
+			return &domain.UpdateHolidayRequest{Date: &next}
+		}, wantDays: [2]float64{5, 4}},
+		{name: "renamed", update: func(time.Time) *domain.UpdateHolidayRequest {
+			name := "Vesak Poya"
+			return &domain.UpdateHolidayRequest{Name: &name}
+		}},
+		{name: "made optional", update: func(time.Time) *domain.UpdateHolidayRequest {
+			optional := domain.HolidayTypeOptional
+			return &domain.UpdateHolidayRequest{Type: &optional}
+		}, wantDays: [2]float64{5, 0}},
+		{name: "made optional and claimed", claimed: true, update: func(time.Time) *domain.UpdateHolidayRequest {
+			optional := domain.HolidayTypeOptional
+			return &domain.UpdateHolidayRequest{Type: &optional}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			monday := upcomingMonday()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			poya := domain.Holiday{Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, Name: "Poya",
+				Date: monday.AddDate(0, 0, 2), Type: domain.HolidayTypePublic}
+			repo.holidays = []domain.Holiday{poya}
+			if tt.claimed {
+				repo.claims = []domain.OptionalHolidayClaim{{OrganizationID: orgID, EmployeeID: employeeID, HolidayID: poya.ID}}
+			}
+			requests := [2]*domain.LeaveRequest{
+				repo.addSpanRequest(leaveType, employeeID, monday, monday.AddDate(0, 0, 4), domain.LeaveStatusApproved, 4),
+				repo.addSpanRequest(leaveType, employeeID, monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 11), domain.LeaveStatusApproved, 5),
+			}
+
+			holiday, err := newTestService(repo).UpdateHoliday(orgID, poya.ID, tt.update(monday), &domain.HolidayChange{PerformedBy: uuid.New()})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.holidays[0].Name != holiday.Name || !repo.holidays[0].Date.Equal(holiday.Date) {
+				t.Errorf("saved %+v, want %+v", repo.holidays[0], holiday)
+			}
+
+			saved := make(map[uuid.UUID]float64)
+			for _, r := range repo.recalculated {
+				saved[r.request.ID] = r.request.Days
+			}
+			for i, request := range requests {
+				if saved[request.ID] != tt.wantDays[i] {
+					t.Errorf("request %d saved with %v days, want %v", i, saved[request.ID], tt.wantDays[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteHolidayRecalculatesLeave(t *testing.T) {
+	orgID := uuid.New()
+	monday := upcomingMonday()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	poya := domain.Holiday{Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, Name: "Poya",
+		Date: monday.AddDate(0, 0, 2), Type: domain.HolidayTypePublic}
+	repo.holidays = []domain.Holiday{poya}
+	request := repo.addSpanRequest(leaveType, uuid.New(), monday, monday.AddDate(0, 0, 4), domain.LeaveStatusPending, 4)
+	svc := newTestService(repo)
+
+	preview, err := svc.DeleteHoliday(orgID, poya.ID, &domain.HolidayChange{PreviewImpact: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if days := recalculatedDays(t, preview); days[request.ID] != [2]float64{4, 5} || len(repo.holidays) != 1 || len(repo.recalculated) != 0 {
+		t.Errorf("preview = %+v, holidays left %d, requests saved %d", preview, len(repo.holidays), len(repo.recalculated))
+	}
+
+	result, err := svc.DeleteHoliday(orgID, poya.ID, &domain.HolidayChange{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if days := recalculatedDays(t, result); days[request.ID] != [2]float64{4, 5} || len(repo.holidays) != 0 || len(repo.recalculated) != 1 {
+		t.Errorf("recalculation = %+v, holidays left %d, requests saved %d", result, len(repo.holidays), len(repo.recalculated))
+	}
+
+	if _, err := svc.DeleteHoliday(orgID, poya.ID, nil); httpStatus(err) != http.StatusNotFound {
+		t.Errorf("deleting it again: got %v, want a 404", err)
+	}
+}
+
+func TestHolidayRecalculationOnlyAtItsLocation(t *testing.T) {
+	orgID, north, south := uuid.New(), uuid.New(), uuid.New()
+	monday := upcomingMonday()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	northern := repo.addSpanRequest(leaveType, north, monday, monday.AddDate(0, 0, 4), domain.LeaveStatusApproved, 5)
+	repo.addSpanRequest(leaveType, south, monday, monday.AddDate(0, 0, 4), domain.LeaveStatusApproved, 5)
+	svc, directory := newDirectoryService(repo, north, &organization.EmployeeResponse{Location: "LK-N"})
+	directory.employees[south] = &organization.EmployeeResponse{Location: "LK-S"}
+
+	holiday := &domain.Holiday{OrganizationID: orgID, Name: "Thai Pongal", Date: monday.AddDate(0, 0, 2),
+		Type: domain.HolidayTypePublic, Location: domain.HolidayLocation("lk-n")}
+	if err := svc.CreateHoliday(holiday, &domain.HolidayChange{AuthToken: "token"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.recalculated) != 1 || repo.recalculated[0].request.ID != northern.ID {
+		t.Errorf("recalculated %+v, want the northern request only", repo.recalculated)
+	}
+}
+
+func TestHolidayRecalculationKeepsFailures(t *testing.T) {
+	orgID := uuid.New()
+	monday := upcomingMonday()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	changed := repo.addSpanRequest(leaveType, uuid.New(), monday, monday.AddDate(0, 0, 4), domain.LeaveStatusApproved, 5)
+	repo.addSpanRequest(leaveType, uuid.New(), monday, monday.AddDate(0, 0, 4), domain.LeaveStatusApproved, 5)
+	repo.recalculateErrs = map[uuid.UUID]error{changed.ID: repository.ErrStatusChanged}
+
+	holiday := &domain.Holiday{OrganizationID: orgID, Name: "Poya", Date: monday.AddDate(0, 0, 2), Type: domain.HolidayTypePublic}
+	if err := newTestService(repo).CreateHoliday(holiday, &domain.HolidayChange{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := holiday.Recalculation
+	if result.Requests != 2 || result.Failed != 1 || len(repo.recalculated) != 1 || len(repo.holidays) != 1 {
+		t.Fatalf("recalculation = %+v, saved %d requests", result, len(repo.recalculated))
+	}
+	for _, r := range result.LeaveRequests {
+		wantError := ""
+		if r.LeaveRequestID == changed.ID {
+			wantError = "the leave request changed while it was recalculated"
+		}
+		if r.Error != wantError {
+			t.Errorf("request %s error = %q, want %q", r.LeaveRequestID, r.Error, wantError)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// jobPollInterval is how long an idle worker waits before looking for
+	// a job again
+	jobPollInterval = 5 * time.Second
+	// jobProgressInterval throttles how often a running job saves its
+	// progress, which also renews its lease
+	jobProgressInterval = 2 * time.Second
+)
+
+// jobProgress reports that processed of total items are done. It fails
+// with repository.ErrJobLeaseLost once another worker has taken the job
+// over, and the job should stop.
+type jobProgress func(processed, total int) error
+
+// jobRunner runs a claimed job and returns its result
+type jobRunner func(job *domain.Job, progress jobProgress) (interface{}, error)
+
+// jobRunner returns the runner of a job type, nil for unknown types
+func (s *leaveService) jobRunner(jobType string) jobRunner {
+	switch jobType {
+	case domain.JobTypeYearlyReset:
+		return s.runYearlyReset
+	case domain.JobTypeBalanceRecalculation:
+		return s.runBalanceRecalculation
+	}
+	return nil
+}
+
+// enqueueJob queues a job of the organization for the workers. A singleton
+// type already queued or running for the organization is a conflict.
+func (s *leaveService) enqueueJob(orgID, requestedBy uuid.UUID, jobType string, payload interface{}, total int) (*domain.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &domain.Job{
+		OrganizationID: orgID,
+		Type:           jobType,
+		Singleton:      domain.IsSingletonJobType(jobType),
+		Payload:        data,
+		Status:         domain.JobStatusQueued,
+		Total:          total,
+		RequestedBy:    requestedBy,
+	}
+
+	err = s.leaveRepo.CreateJob(job)
+	var active *repository.ActiveJobError
+	if errors.As(err, &active) {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("a %s job is already %s: %s",
+			jobType, active.Job.Status, active.Job.ID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob returns a background job of the organization
+func (s *leaveService) GetJob(orgID, id uuid.UUID) (*domain.Job, error) {
+	job, err := s.leaveRepo.GetJob(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && job.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// RunJobWorkers starts workers goroutines draining the job queue until ctx
+// is done. While paused reports true, as in read-only mode, no new jobs are
+// claimed.
+func (s *leaveService) RunJobWorkers(ctx context.Context, workers int, paused func() bool) {
+	for i := 0; i < workers; i++ {
+		go s.jobWorker(ctx, paused)
+	}
+}
+
+func (s *leaveService) jobWorker(ctx context.Context, paused func() bool) {
+	for {
+		claimed := false
+		if !paused() {
+			job, err := s.leaveRepo.ClaimJob(domain.JobLeaseDuration)
+			if err != nil {
+				log.Printf("Error: cannot claim a job: %v", err)
+			} else if job != nil {
+				s.runJob(job)
+				claimed = true
+			}
+		}
+		if claimed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// runJob runs a claimed job and records its outcome. A job that panics is
+// failed; one taken over by another worker is left to it.
+func (s *leaveService) runJob(job *domain.Job) {
+	started := time.Now()
+	result, err := s.runJobRecovering(job)
+	if errors.Is(err, repository.ErrJobLeaseLost) {
+		log.Printf("Warning: job %s was taken over by another worker after its lease expired", job.ID)
+		return
+	}
+
+	job.Status = domain.JobStatusCompleted
+	job.Error = ""
+	job.Result = nil
+	if err == nil {
+		if job.Result, err = json.Marshal(result); err != nil {
+			err = fmt.Errorf("cannot encode result: %w", err)
+		}
+	}
+	if err != nil {
+		log.Printf("Error: %s job %s of %s failed: %v", job.Type, job.ID, job.OrganizationID, err)
+		job.Status = domain.JobStatusFailed
+		job.Error = err.Error()
+	}
+
+	if err := s.leaveRepo.FinishJob(job); err != nil {
+		log.Printf("Warning: cannot save the outcome of job %s: %v", job.ID, err)
+		return
+	}
+	s.jobStats.record(job.Type, job.Status, time.Since(started))
+}
+
+func (s *leaveService) runJobRecovering(job *domain.Job) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job stopped: %v", r)
+		}
+	}()
+
+	runner := s.jobRunner(job.Type)
+	if runner == nil {
+		return nil, fmt.Errorf("unknown job type %q", job.Type)
+	}
+
+	lastSaved := time.Time{}
+	return runner(job, func(processed, total int) error {
+		job.Processed = processed
+		job.Total = total
+		if time.Since(lastSaved) < jobProgressInterval && processed < total {
+			return nil
+		}
+		lastSaved = time.Now()
+		return s.leaveRepo.UpdateJobProgress(job, domain.JobLeaseDuration)
+	})
+}
+
+// JobMetrics reports the queue depth and how long the jobs this process
+// ran took
+func (s *leaveService) JobMetrics() (*domain.JobMetrics, error) {
+	counts, err := s.leaveRepo.CountJobsByStatus()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.JobMetrics{
+		Queued:  counts[domain.JobStatusQueued],
+		Running: counts[domain.JobStatusRunning],
+		Types:   s.jobStats.snapshot(),
+	}, nil
+}
+
+// jobStats tallies the jobs this process finished, per type
+type jobStats struct {
+	mu    sync.Mutex
+	types map[string]domain.JobTypeMetrics
+}
+
+func newJobStats() *jobStats {
+	return &jobStats{types: make(map[string]domain.JobTypeMetrics)}
+}
+
+func (j *jobStats) record(jobType, status string, took time.Duration) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	m := j.types[jobType]
+	if status == domain.JobStatusCompleted {
+		m.Completed++
+	} else {
+		m.Failed++
+	}
+	m.TotalSeconds += took.Seconds()
+	if took.Seconds() > m.MaxSeconds {
+		m.MaxSeconds = took.Seconds()
+	}
+	j.types[jobType] = m
+}
+
+func (j *jobStats) snapshot() map[string]domain.JobTypeMetrics {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	types := make(map[string]domain.JobTypeMetrics, len(j.types))
+	for jobType, m := range j.types {
+		types[jobType] = m
+	}
+	return types
+}
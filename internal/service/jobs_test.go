@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// yearlyResetJob is a queued yearly reset of employees in orgID
+func yearlyResetJob(t *testing.T, orgID uuid.UUID, employees int) *domain.Job {
+	t.Helper()
+	payload := domain.YearlyResetJobPayload{Year: 2027}
+	for i := 0; i < employees; i++ {
+		payload.EmployeeIDs = append(payload.EmployeeIDs, uuid.New())
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &domain.Job{
+		Base:           domain.Base{ID: uuid.New()},
+		OrganizationID: orgID,
+		Type:           domain.JobTypeYearlyReset,
+		Payload:        data,
+		Status:         domain.JobStatusQueued,
+	}
+}
+
+func TestJobWorkersRunEachJobOnce(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	for i := 0; i < 5; i++ {
+		repo.jobs = append(repo.jobs, yearlyResetJob(t, orgID, 0))
+	}
+	svc := newTestService(repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.RunJobWorkers(ctx, 3, func() bool { return false })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		repo.jobsMu.Lock()
+		finished := len(repo.finishedJobs)
+		repo.jobsMu.Unlock()
+		if finished >= len(repo.jobs) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d jobs finished", finished, len(repo.jobs))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	repo.jobsMu.Lock()
+	defer repo.jobsMu.Unlock()
+	runs := make(map[uuid.UUID]int)
+	for _, job := range repo.finishedJobs {
+		runs[job.ID]++
+		if job.Status != domain.JobStatusCompleted || job.Attempts != 1 || len(job.Result) == 0 {
+			t.Errorf("job %s finished %s on attempt %d with %s", job.ID, job.Status, job.Attempts, job.Result)
+		}
+	}
+	for _, job := range repo.jobs {
+		if runs[job.ID] != 1 {
+			t.Errorf("job %s ran %d times, want once", job.ID, runs[job.ID])
+		}
+	}
+	if got := svc.jobStats.snapshot()[domain.JobTypeYearlyReset].Completed; got != len(repo.jobs) {
+		t.Errorf("stats count %d completed jobs, want %d", got, len(repo.jobs))
+	}
+}
+
+func TestJobWorkersPaused(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	repo.jobs = []*domain.Job{yearlyResetJob(t, orgID, 0)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	newTestService(repo).RunJobWorkers(ctx, 2, func() bool { return true })
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	repo.jobsMu.Lock()
+	defer repo.jobsMu.Unlock()
+	if repo.jobs[0].Status != domain.JobStatusQueued {
+		t.Errorf("a paused worker claimed the job, now %s", repo.jobs[0].Status)
+	}
+}
+
+func TestRunJobTakenOver(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	repo.addLeaveType(orgID, domain.RoundingNone)
+	// Another worker claimed the job after this one's lease expired
+	repo.jobLeaseErr = repository.ErrJobLeaseLost
+
+	job := yearlyResetJob(t, orgID, 3)
+	job.Status = domain.JobStatusRunning
+	job.Attempts = 1
+	svc := newTestService(repo)
+	svc.runJob(job)
+
+	if len(repo.resetRuns) != 1 {
+		t.Errorf("reset %d employees, want the job to stop after the first", len(repo.resetRuns))
+	}
+	if len(repo.finishedJobs) != 0 {
+		t.Errorf("the job's outcome was saved over its new worker's: %+v", repo.finishedJobs)
+	}
+	if stats := svc.jobStats.snapshot(); len(stats) != 0 {
+		t.Errorf("stats = %+v, want the job left uncounted", stats)
+	}
+}
+
+func TestRunJobFailures(t *testing.T) {
+	orgID := uuid.New()
+	tests := []struct {
+		name      string
+		jobType   string
+		payload   string
+		wantError string
+	}{
+		{name: "unknown type", jobType: "archive", payload: `{}`, wantError: `unknown job type "archive"`},
+		{name: "bad payload", jobType: domain.JobTypeYearlyReset, payload: `{"year":"next"}`, wantError: "cannot unmarshal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			svc := newTestService(repo)
+
+			svc.runJob(&domain.Job{
+				Base:           domain.Base{ID: uuid.New()},
+				OrganizationID: orgID,
+				Type:           tt.jobType,
+				Payload:        json.RawMessage(tt.payload),
+				Status:         domain.JobStatusRunning,
+				Attempts:       1,
+			})
+
+			if len(repo.finishedJobs) != 1 {
+				t.Fatalf("finished %d jobs, want 1", len(repo.finishedJobs))
+			}
+			job := repo.finishedJobs[0]
+			if job.Status != domain.JobStatusFailed || !strings.Contains(job.Error, tt.wantError) || job.Result != nil {
+				t.Errorf("job finished %s with %q and result %s, want failed with %q", job.Status, job.Error, job.Result, tt.wantError)
+			}
+			if got := svc.jobStats.snapshot()[tt.jobType].Failed; got != 1 {
+				t.Errorf("stats count %d failed jobs, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// GetLeaveHistorySummary reports the employee's entitlement and leave taken
+// for each of the last domain.LeaveHistoryYears leave years. A year's
+// figures come from the employee's balance of each leave type when there is
+// one; otherwise they are reconstructed from the approved requests and the
+// type's default days, and flagged. Years before the employee's first
+// balance or request are left out. Year totals cover the paid types counted
+// in days.
+func (s *leaveService) GetLeaveHistorySummary(orgID, employeeID uuid.UUID) (*domain.LeaveHistorySummary, error) {
+	today, err := s.Today(orgID)
+	if err != nil {
+		return nil, err
+	}
+	toYear := today.Year()
+	fromYear := toYear - domain.LeaveHistoryYears + 1
+
+	balances, err := s.leaveRepo.ListEmployeeBalanceYears(orgID, employeeID, fromYear, toYear)
+	if err != nil {
+		return nil, err
+	}
+	balanceIDs := make([]uuid.UUID, len(balances))
+	for i := range balances {
+		balanceIDs[i] = balances[i].ID
+	}
+	forfeited, err := s.leaveRepo.SumForfeitedCarryOver(balanceIDs)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := s.leaveRepo.SumLeaveTakenByYear(orgID, employeeID, fromYear, toYear)
+	if err != nil {
+		return nil, err
+	}
+	leaveTypes, err := s.leaveRepo.ListLeaveTypes(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	paid := make(map[uuid.UUID]bool, len(leaveTypes))
+	for _, leaveType := range leaveTypes {
+		paid[leaveType.ID] = leaveType.IsPaid
+	}
+
+	type yearType struct {
+		year        int
+		leaveTypeID uuid.UUID
+	}
+	fromBalance := make(map[yearType]bool, len(balances))
+	takenByYearType := make(map[yearType]float64, len(taken))
+	firstYear := toYear + 1
+	for _, b := range balances {
+		fromBalance[yearType{b.Year, b.LeaveTypeID}] = true
+		if b.Year < firstYear {
+			firstYear = b.Year
+		}
+	}
+	for _, t := range taken {
+		takenByYearType[yearType{t.Year, t.LeaveTypeID}] += t.Days
+		if t.Year < firstYear {
+			firstYear = t.Year
+		}
+	}
+
+	summary := &domain.LeaveHistorySummary{EmployeeID: employeeID, Years: []domain.LeaveHistoryYear{}}
+	for year := toYear; year >= firstYear; year-- {
+		historyYear := domain.LeaveHistoryYear{Year: year, LeaveTypes: []domain.LeaveHistoryTypeYear{}}
+
+		for i := range balances {
+			b := &balances[i]
+			if b.Year != year || b.LeaveType == nil {
+				continue
+			}
+			historyYear.LeaveTypes = append(historyYear.LeaveTypes, domain.LeaveHistoryTypeYear{
+				LeaveTypeID:         b.LeaveTypeID,
+				LeaveType:           b.LeaveType.Name,
+				Unit:                b.LeaveType.Unit,
+				LeaveHistoryFigures: domain.BalanceHistoryFigures(b, forfeited[b.ID]),
+			})
+		}
+
+		// Paid types are reconstructed even when nothing was taken, as not
+		// taking leave is what the summary should show; unpaid ones only
+		// when leave was taken
+		for i := range leaveTypes {
+			leaveType := &leaveTypes[i]
+			key := yearType{year, leaveType.ID}
+			days, wasTaken := takenByYearType[key]
+			if fromBalance[key] || !leaveType.TracksBalance || (!leaveType.IsPaid && !wasTaken) {
+				continue
+			}
+			historyYear.LeaveTypes = append(historyYear.LeaveTypes, domain.LeaveHistoryTypeYear{
+				LeaveTypeID:         leaveType.ID,
+				LeaveType:           leaveType.Name,
+				Unit:                leaveType.Unit,
+				LeaveHistoryFigures: domain.ReconstructedHistoryFigures(leaveType, days),
+				Reconstructed:       true,
+			})
+		}
+
+		sort.Slice(historyYear.LeaveTypes, func(i, j int) bool {
+			return historyYear.LeaveTypes[i].LeaveType < historyYear.LeaveTypes[j].LeaveType
+		})
+		for _, typeYear := range historyYear.LeaveTypes {
+			if !paid[typeYear.LeaveTypeID] || typeYear.Unit == domain.LeaveUnitHours {
+				continue
+			}
+			historyYear.Add(typeYear.LeaveHistoryFigures)
+			historyYear.Reconstructed = historyYear.Reconstructed || typeYear.Reconstructed
+		}
+		historyYear.SetUtilization()
+
+		summary.Years = append(summary.Years, historyYear)
+	}
+	return summary, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestGetLeaveHistorySummary(t *testing.T) {
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	thisYear := time.Now().UTC().Year()
+
+	repo := newFakeRepository()
+	annual := repo.addLeaveType(orgID, domain.RoundingNone)
+	annual.IsPaid = true
+	unpaid := repo.addLeaveType(orgID, domain.RoundingNone)
+	unpaid.Name = "Unpaid"
+	unpaid.DefaultDays = 10
+	toil := repo.addLeaveType(orgID, domain.RoundingNone)
+	toil.Name = "Time off in lieu"
+	toil.IsPaid = true
+	toil.Unit = domain.LeaveUnitHours
+
+	current := domain.LeaveBalance{
+		Base:            domain.Base{ID: uuid.New()},
+		EmployeeID:      employeeID,
+		LeaveTypeID:     annual.ID,
+		LeaveType:       annual,
+		Year:            thisYear,
+		TotalDays:       23,
+		CarriedOverDays: 5,
+		UsedDays:        10,
+	}
+	hours := domain.LeaveBalance{
+		Base:        domain.Base{ID: uuid.New()},
+		EmployeeID:  employeeID,
+		LeaveTypeID: toil.ID,
+		LeaveType:   toil,
+		Year:        thisYear,
+		TotalDays:   16,
+		UsedDays:    8,
+	}
+	repo.balances = []domain.LeaveBalance{current, hours}
+	repo.forfeited = map[uuid.UUID]float64{current.ID: 2}
+	repo.taken = []domain.LeaveTakenByYear{
+		{Year: thisYear - 1, LeaveTypeID: annual.ID, Days: 12},
+		{Year: thisYear - 1, LeaveTypeID: unpaid.ID, Days: 3},
+	}
+
+	summary, err := newTestService(repo).GetLeaveHistorySummary(orgID, employeeID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summary.Years) != 2 {
+		t.Fatalf("got %d years, want this year and last, as nothing came before", len(summary.Years))
+	}
+
+	now := summary.Years[0]
+	if now.Year != thisYear || now.Reconstructed {
+		t.Errorf("this year: got year %d reconstructed %v", now.Year, now.Reconstructed)
+	}
+	wantNow := domain.LeaveHistoryFigures{Entitled: 20, Taken: 10, CarriedOver: 5, Forfeited: 2, Utilization: 40}
+	if now.LeaveHistoryFigures != wantNow {
+		t.Errorf("this year's totals: got %+v, want %+v without the hourly type", now.LeaveHistoryFigures, wantNow)
+	}
+	if len(now.LeaveTypes) != 2 || now.LeaveTypes[0].LeaveType != "Annual" || now.LeaveTypes[1].Unit != domain.LeaveUnitHours {
+		t.Errorf("this year's types: %+v", now.LeaveTypes)
+	}
+
+	last := summary.Years[1]
+	if last.Year != thisYear-1 || !last.Reconstructed {
+		t.Errorf("last year: got year %d reconstructed %v", last.Year, last.Reconstructed)
+	}
+	wantLast := domain.LeaveHistoryFigures{Entitled: 20, Taken: 12, Utilization: 60}
+	if last.LeaveHistoryFigures != wantLast {
+		t.Errorf("last year's totals: got %+v, want %+v without unpaid leave", last.LeaveHistoryFigures, wantLast)
+	}
+	names := make([]string, len(last.LeaveTypes))
+	for i, typeYear := range last.LeaveTypes {
+		names[i] = typeYear.LeaveType
+		if !typeYear.Reconstructed {
+			t.Errorf("last year's %s is not flagged reconstructed", typeYear.LeaveType)
+		}
+	}
+	// The hourly type is paid, so it is reconstructed with nothing taken;
+	// unpaid leave only shows because some was taken
+	if want := []string{"Annual", "Time off in lieu", "Unpaid"}; len(names) != len(want) ||
+		names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Errorf("last year's types: got %v, want %v", names, want)
+	}
+}
+
+func TestGetLeaveHistorySummaryWithoutHistory(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.addLeaveType(orgID, domain.RoundingNone).IsPaid = true
+
+	summary, err := newTestService(repo).GetLeaveHistorySummary(orgID, uuid.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Years == nil || len(summary.Years) != 0 {
+		t.Errorf("got years %+v, want an empty list", summary.Years)
+	}
+}
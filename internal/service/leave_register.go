@@ -0,0 +1,111 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/xlsx"
+	"github.com/google/uuid"
+)
+
+// LeaveRegister totals the organization's requests starting in the year
+// per leave type, refusing registers longer than params.MaxRows before
+// anything is written
+func (s *leaveService) LeaveRegister(orgID uuid.UUID, params *domain.LeaveRegisterParams) (*domain.LeaveRegister, error) {
+	if params.Year < 1900 || params.Year > 9999 {
+		return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+	}
+	if params.LeaveTypeID != nil {
+		if _, err := s.GetLeaveType(orgID, *params.LeaveTypeID); err != nil {
+			return nil, err
+		}
+	}
+
+	register := &domain.LeaveRegister{
+		OrganizationID: orgID,
+		Year:           params.Year,
+		StartDate:      time.Date(params.Year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:        time.Date(params.Year+1, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	types, err := s.leaveRepo.SummarizeLeaveRegister(orgID, params.LeaveTypeID, register.StartDate, register.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	register.Types = types
+	for _, t := range types {
+		register.Requests += t.Requests
+	}
+
+	if params.MaxRows > 0 && register.Requests > int64(params.MaxRows) {
+		guidance := "export one leave type at a time with leave_type_id"
+		if params.LeaveTypeID != nil {
+			guidance = "ask an operator to raise LEAVE_REGISTER_MAX_ROWS"
+		}
+		return nil, apperrors.NewUnprocessableError(fmt.Sprintf(
+			"the %d leave register lists %d requests, more than the %d an export may hold; %s",
+			params.Year, register.Requests, params.MaxRows, guidance))
+	}
+	return register, nil
+}
+
+// WriteLeaveRegister streams the register as an XLSX workbook: a summary
+// sheet of the per-type totals, then a sheet per leave type listing its
+// requests, read a page at a time
+func (s *leaveService) WriteLeaveRegister(w io.Writer, register *domain.LeaveRegister) error {
+	book := xlsx.NewWriter(w)
+
+	if _, err := book.AddSheet("Summary"); err != nil {
+		return err
+	}
+	if err := book.WriteHeader("Leave type", "Unit", "Requests", "Approved", "Pending", "Rejected", "Cancelled", "Approved days"); err != nil {
+		return err
+	}
+	for _, t := range register.Types {
+		if err := book.WriteRow(t.LeaveType, t.Unit, t.Requests, t.Approved, t.Pending, t.Rejected, t.Cancelled, t.ApprovedDays); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range register.Types {
+		if _, err := book.AddSheet(t.LeaveType); err != nil {
+			return err
+		}
+		days := "Days"
+		if t.Unit == domain.LeaveUnitHours {
+			days = "Hours"
+		}
+		if err := book.WriteHeader("Request ID", "Employee ID", "Start date", "End date", days, "Status",
+			"Approver", "Approval date", "Requested at"); err != nil {
+			return err
+		}
+
+		var after *domain.LeaveRequest
+		for {
+			page, err := s.leaveRepo.ListLeaveRegisterPage(register.OrganizationID, t.LeaveTypeID,
+				register.StartDate, register.EndDate, after, domain.LeaveRegisterPageSize)
+			if err != nil {
+				return err
+			}
+			for i := range page {
+				r := &page[i]
+				var approver interface{}
+				if r.ApprovedBy != nil {
+					approver = r.ApprovedBy.String()
+				}
+				if err := book.WriteRow(r.ID.String(), r.EmployeeID.String(), r.StartDate, r.EndDate, r.Days, r.Status,
+					approver, r.ApprovedAt, r.CreatedAt); err != nil {
+					return err
+				}
+			}
+			if len(page) < domain.LeaveRegisterPageSize {
+				break
+			}
+			after = &page[len(page)-1]
+		}
+	}
+
+	return book.Close()
+}
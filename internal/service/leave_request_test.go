@@ -0,0 +1,596 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// upcomingMonday is the first Monday at least two weeks from today in UTC,
+// clear of any notice period the tests use
+func upcomingMonday() time.Time {
+	day := domain.TruncateToDate(time.Now().UTC()).AddDate(0, 0, 14)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// addLeaveType registers a leave type of orgID that needs no approval
+func (f *fakeRepository) addLeaveType(orgID uuid.UUID, rounding string) *domain.LeaveType {
+	leaveType := &domain.LeaveType{
+		ID:                uuid.New(),
+		OrganizationID:    orgID,
+		Name:              "Annual",
+		DefaultDays:       20,
+		MaxDaysPerRequest: 30,
+		Rounding:          rounding,
+		Unit:              domain.LeaveUnitDays,
+		TracksBalance:     true,
+	}
+	f.leaveTypes[leaveType.ID] = leaveType
+	return leaveType
+}
+
+func TestCreateLeaveRequestPersistsRequest(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	monday := upcomingMonday()
+
+	request, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  uuid.New(),
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday.AddDate(0, 0, 4),
+		Reason:      "Family holiday",
+		Comment:     "Back on the 1st",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved := repo.created
+	if saved == nil {
+		t.Fatal("request was not saved")
+	}
+	if saved != request {
+		t.Error("returned request is not the saved one")
+	}
+	if saved.OrganizationID != orgID {
+		t.Errorf("organization_id = %s, want %s", saved.OrganizationID, orgID)
+	}
+	if saved.Days != 5 || saved.RawDays != 5 {
+		t.Errorf("days = %v raw_days = %v, want 5 and 5", saved.Days, saved.RawDays)
+	}
+	if saved.Comments != "Back on the 1st" {
+		t.Errorf("comments = %q", saved.Comments)
+	}
+	if repo.createdEntitlement != 20 {
+		t.Errorf("balance opened with %v days, want the leave type's 20", repo.createdEntitlement)
+	}
+}
+
+func TestCreateLeaveRequestTotalDays(t *testing.T) {
+	monday := upcomingMonday()
+
+	tests := []struct {
+		name      string
+		totalDays float64
+		wantErr   bool
+	}{
+		{name: "omitted", totalDays: 0},
+		{name: "matching", totalDays: 5},
+		{name: "matching up to float error", totalDays: math.Nextafter(5, 6)},
+		{name: "a hundredth short", totalDays: 4.99, wantErr: true},
+		{name: "more than the working days", totalDays: 7, wantErr: true},
+		{name: "fewer than the working days", totalDays: 4.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+
+			_, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  uuid.New(),
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday.AddDate(0, 0, 4),
+				TotalDays:   tt.totalDays,
+				Reason:      "Family holiday",
+			})
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if status := httpStatus(err); status != 400 {
+				t.Fatalf("got %v (%d), want a 400", err, status)
+			}
+			if repo.created != nil {
+				t.Error("a mismatched request was saved")
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestRounding(t *testing.T) {
+	monday := upcomingMonday()
+
+	tests := []struct {
+		name     string
+		rounding string
+		wantDays float64
+	}{
+		{name: "none", rounding: domain.RoundingNone, wantDays: 2.5},
+		{name: "half up", rounding: domain.RoundingHalfUp, wantDays: 2.5},
+		{name: "full up", rounding: domain.RoundingFullUp, wantDays: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, tt.rounding)
+
+			// Monday afternoon to Wednesday is two and a half days
+			request, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  uuid.New(),
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday.AddDate(0, 0, 2),
+				StartHalf:   domain.HalfDayPM,
+				Reason:      "Moving house",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if request.RawDays != 2.5 || request.Days != tt.wantDays {
+				t.Errorf("raw_days = %v days = %v, want 2.5 and %v", request.RawDays, request.Days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestRoundsFromRawDays(t *testing.T) {
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingFullUp)
+	svc := newTestService(repo)
+	monday := upcomingMonday()
+
+	request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday.AddDate(0, 0, 2),
+		StartHalf:   domain.HalfDayPM,
+		Reason:      "Moving house",
+	})
+	if err != nil {
+		t.Fatalf("creating: %v", err)
+	}
+	request.Status = domain.LeaveStatusPending
+
+	// Shrinking to Monday afternoon and Tuesday is 1.5 raw days; rounding
+	// the stored 3 instead would keep it at 3
+	edited, err := svc.EditLeaveRequest(orgID, request.ID, employeeID, &domain.EditLeaveRequestRequest{
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday.AddDate(0, 0, 1),
+		StartHalf:   domain.HalfDayPM,
+		Reason:      "Moving house",
+	})
+	if err != nil {
+		t.Fatalf("editing: %v", err)
+	}
+	if edited.RawDays != 1.5 || edited.Days != 2 {
+		t.Errorf("raw_days = %v days = %v, want 1.5 and 2", edited.RawDays, edited.Days)
+	}
+}
+
+// addPendingRequest registers a pending request of leaveType for
+// employeeID over the upcoming week
+func (f *fakeRepository) addPendingRequest(leaveType *domain.LeaveType, employeeID uuid.UUID) *domain.LeaveRequest {
+	monday := upcomingMonday()
+	request := &domain.LeaveRequest{
+		OrganizationID: leaveType.OrganizationID,
+		EmployeeID:     employeeID,
+		LeaveTypeID:    leaveType.ID,
+		LeaveType:      leaveType,
+		StartDate:      monday,
+		EndDate:        monday.AddDate(0, 0, 4),
+		Days:           5,
+		RawDays:        5,
+		Status:         domain.LeaveStatusPending,
+		Reason:         "Family holiday",
+	}
+	request.ID = uuid.New()
+	f.requests[request.ID] = request
+	return request
+}
+
+// leaveRequestActors are the callers the authorization tests act as
+var leaveRequestActors = []struct {
+	name     string
+	owner    bool
+	role     string
+	strategy string
+	want     int
+}{
+	{name: "requesting employee", owner: true, role: "employee", want: 0},
+	{name: "another employee", role: "employee", want: 403},
+	{name: "manager under direct_manager", role: domain.RoleManager, want: 0},
+	{name: "admin", role: domain.RoleAdmin, want: 0},
+	{name: "holder of the approver role", role: domain.RoleHR, strategy: domain.ApprovalStrategyRoleBased, want: 0},
+	{name: "manager under role_based", role: domain.RoleManager, strategy: domain.ApprovalStrategyRoleBased, want: 403},
+	{name: "no role", role: "", want: 403},
+}
+
+func TestEditLeaveRequestAuthorization(t *testing.T) {
+	for _, tt := range leaveRequestActors {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			if tt.strategy != "" {
+				leaveType.ApprovalStrategy = tt.strategy
+				leaveType.ApproverRole = domain.RoleHR
+			}
+			request := repo.addPendingRequest(leaveType, employeeID)
+
+			editorID := uuid.New()
+			if tt.owner {
+				editorID = employeeID
+			}
+			_, err := newTestService(repo).EditLeaveRequest(orgID, request.ID, editorID, &domain.EditLeaveRequestRequest{
+				LeaveTypeID: leaveType.ID,
+				StartDate:   request.StartDate,
+				EndDate:     request.StartDate.AddDate(0, 0, 1),
+				Reason:      "Shorter trip",
+				CallerRole:  tt.role,
+			})
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if tt.want == 403 && repo.updated != nil {
+				t.Error("a forbidden edit was saved")
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestAppliesCreateRules(t *testing.T) {
+	monday := upcomingMonday()
+
+	tests := []struct {
+		name  string
+		setup func(leaveType *domain.LeaveType)
+		edit  domain.EditLeaveRequestRequest
+		want  int
+	}{
+		{
+			name:  "notice period",
+			setup: func(leaveType *domain.LeaveType) { leaveType.MinDaysNotice = 60 },
+			edit:  domain.EditLeaveRequestRequest{StartDate: monday, EndDate: monday},
+			want:  400,
+		},
+		{
+			name:  "notice period bypassed",
+			setup: func(leaveType *domain.LeaveType) { leaveType.MinDaysNotice = 60 },
+			edit:  domain.EditLeaveRequestRequest{StartDate: monday, EndDate: monday, BypassNotice: true},
+			want:  0,
+		},
+		{
+			name: "backdating by an employee",
+			edit: domain.EditLeaveRequestRequest{StartDate: monday.AddDate(0, 0, -35), EndDate: monday.AddDate(0, 0, -35)},
+			want: 400,
+		},
+		{
+			name: "longer than the maximum per request",
+			setup: func(leaveType *domain.LeaveType) {
+				leaveType.MaxDaysPerRequest = 2
+			},
+			edit: domain.EditLeaveRequestRequest{StartDate: monday, EndDate: monday.AddDate(0, 0, 4)},
+			want: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			request := repo.addPendingRequest(leaveType, employeeID)
+			if tt.setup != nil {
+				tt.setup(leaveType)
+			}
+
+			edit := tt.edit
+			edit.LeaveTypeID = leaveType.ID
+			edit.Reason = "Changed plans"
+			edit.CallerRole = "employee"
+			_, err := newTestService(repo).EditLeaveRequest(orgID, request.ID, employeeID, &edit)
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestOpensBalanceWithEntitlement(t *testing.T) {
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	request := repo.addPendingRequest(leaveType, employeeID)
+
+	_, err := newTestService(repo).EditLeaveRequest(orgID, request.ID, employeeID, &domain.EditLeaveRequestRequest{
+		LeaveTypeID: leaveType.ID,
+		StartDate:   request.StartDate,
+		EndDate:     request.EndDate,
+		Reason:      "Same dates",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.updatedEntitlement != 20 {
+		t.Errorf("balance would open with %v days, want the leave type's 20", repo.updatedEntitlement)
+	}
+}
+
+func TestCancelLeaveRequestAuthorization(t *testing.T) {
+	for _, tt := range leaveRequestActors {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			if tt.strategy != "" {
+				leaveType.ApprovalStrategy = tt.strategy
+				leaveType.ApproverRole = domain.RoleHR
+			}
+			request := repo.addPendingRequest(leaveType, employeeID)
+
+			cancelledBy := uuid.New()
+			if tt.owner {
+				cancelledBy = employeeID
+			}
+			_, err := newTestService(repo).CancelLeaveRequest(orgID, request.ID, cancelledBy, tt.role, "")
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if tt.want == 0 && repo.transitioned != domain.LeaveStatusCancelled {
+				t.Errorf("request moved to %q, want cancelled", repo.transitioned)
+			}
+			if tt.want == 403 && repo.transitioned != "" {
+				t.Error("a forbidden cancellation was saved")
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestCountsWorkingDays(t *testing.T) {
+	friday := upcomingMonday().AddDate(0, 0, 4)
+
+	tests := []struct {
+		name     string
+		end      time.Time
+		maxDays  int
+		holiday  bool
+		wantDays float64
+		wantErr  bool
+	}{
+		{name: "over a weekend", end: friday.AddDate(0, 0, 3), maxDays: 2, wantDays: 2},
+		{name: "over a weekend and a holiday", end: friday.AddDate(0, 0, 4), maxDays: 2, holiday: true, wantDays: 2},
+		{name: "more working days than allowed", end: friday.AddDate(0, 0, 4), maxDays: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			leaveType.MaxDaysPerRequest = tt.maxDays
+			if tt.holiday {
+				repo.holidays = []domain.Holiday{{Name: "Founders day", Date: friday.AddDate(0, 0, 3)}}
+			}
+
+			_, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  uuid.New(),
+				LeaveTypeID: leaveType.ID,
+				StartDate:   friday,
+				EndDate:     tt.end,
+				Reason:      "Long weekend",
+			})
+			if tt.wantErr {
+				if status := httpStatus(err); status != 400 {
+					t.Fatalf("got %v (%d), want a 400", err, status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.created.Days != tt.wantDays {
+				t.Errorf("days = %v, want %v", repo.created.Days, tt.wantDays)
+			}
+		})
+	}
+}
+
+// halfDayOverlaps pairs the halves of an employee's existing request on a
+// day with those of another request for the same day. A morning off ends
+// at midday (end_half am); an afternoon off starts at midday (start_half pm).
+var halfDayOverlaps = []struct {
+	name               string
+	existingStartHalf  string
+	existingEndHalf    string
+	requestedStartHalf string
+	requestedEndHalf   string
+	want               int
+}{
+	{name: "morning then afternoon", existingEndHalf: domain.HalfDayAM, requestedStartHalf: domain.HalfDayPM, want: 0},
+	{name: "afternoon then morning", existingStartHalf: domain.HalfDayPM, requestedEndHalf: domain.HalfDayAM, want: 0},
+	{name: "morning twice", existingEndHalf: domain.HalfDayAM, requestedEndHalf: domain.HalfDayAM, want: 422},
+	{name: "afternoon twice", existingStartHalf: domain.HalfDayPM, requestedStartHalf: domain.HalfDayPM, want: 422},
+	{name: "full day against a morning", existingEndHalf: domain.HalfDayAM, want: 422},
+	{name: "full day against an afternoon", existingStartHalf: domain.HalfDayPM, want: 422},
+	{name: "morning against a full day", requestedEndHalf: domain.HalfDayAM, want: 422},
+	{name: "full day twice", want: 422},
+}
+
+// addDayRequest registers a request of leaveType for employeeID on day
+// with the given halves and status
+func (f *fakeRepository) addDayRequest(leaveType *domain.LeaveType, employeeID uuid.UUID, day time.Time, startHalf, endHalf, status string) *domain.LeaveRequest {
+	request := &domain.LeaveRequest{
+		OrganizationID: leaveType.OrganizationID,
+		EmployeeID:     employeeID,
+		LeaveTypeID:    leaveType.ID,
+		LeaveType:      leaveType,
+		StartDate:      day,
+		EndDate:        day,
+		StartHalf:      startHalf,
+		EndHalf:        endHalf,
+		Status:         status,
+		Reason:         "Appointment",
+	}
+	request.ID = uuid.New()
+	request.UpdatedAt = time.Now()
+	f.requests[request.ID] = request
+	return request
+}
+
+func TestCreateLeaveRequestOverlap(t *testing.T) {
+	for _, tt := range halfDayOverlaps {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			wednesday := upcomingMonday().AddDate(0, 0, 2)
+			repo.addDayRequest(leaveType, employeeID, wednesday, tt.existingStartHalf, tt.existingEndHalf, domain.LeaveStatusApproved)
+
+			_, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   wednesday,
+				EndDate:     wednesday,
+				StartHalf:   tt.requestedStartHalf,
+				EndHalf:     tt.requestedEndHalf,
+				Reason:      "Appointment",
+			})
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if tt.want != 0 && repo.created != nil {
+				t.Error("an overlapping request was saved")
+			}
+		})
+	}
+}
+
+func TestCreateLeaveRequestOverlapIgnoresInactiveRequests(t *testing.T) {
+	for _, status := range []string{domain.LeaveStatusRejected, domain.LeaveStatusCancelled} {
+		t.Run(status, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			monday := upcomingMonday()
+			repo.addDayRequest(leaveType, employeeID, monday, "", "", status)
+
+			_, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday,
+				Reason:      "Appointment",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestOverlap(t *testing.T) {
+	for _, tt := range halfDayOverlaps {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			monday := upcomingMonday()
+			friday := monday.AddDate(0, 0, 4)
+			repo.addDayRequest(leaveType, employeeID, friday, tt.existingStartHalf, tt.existingEndHalf, domain.LeaveStatusPending)
+			// Monday to Thursday, moved onto Friday
+			request := repo.addPendingRequest(leaveType, employeeID)
+			request.EndDate = monday.AddDate(0, 0, 3)
+
+			_, err := newTestService(repo).EditLeaveRequest(orgID, request.ID, employeeID, &domain.EditLeaveRequestRequest{
+				LeaveTypeID: leaveType.ID,
+				StartDate:   friday,
+				EndDate:     friday,
+				StartHalf:   tt.requestedStartHalf,
+				EndHalf:     tt.requestedEndHalf,
+				Reason:      "Appointment",
+			})
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if tt.want != 0 && repo.updated != nil {
+				t.Error("an overlapping edit was saved")
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestMayOverlapItself(t *testing.T) {
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	request := repo.addPendingRequest(leaveType, employeeID)
+
+	_, err := newTestService(repo).EditLeaveRequest(orgID, request.ID, employeeID, &domain.EditLeaveRequestRequest{
+		LeaveTypeID: leaveType.ID,
+		StartDate:   request.StartDate.AddDate(0, 0, 1),
+		EndDate:     request.EndDate,
+		Reason:      "Shorter trip",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReopenLeaveRequestOverlap(t *testing.T) {
+	for _, tt := range halfDayOverlaps {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			monday := upcomingMonday()
+			repo.addDayRequest(leaveType, employeeID, monday, tt.existingStartHalf, tt.existingEndHalf, domain.LeaveStatusApproved)
+			rejected := repo.addDayRequest(leaveType, employeeID, monday, tt.requestedStartHalf, tt.requestedEndHalf, domain.LeaveStatusRejected)
+
+			_, err := newTestService(repo).ReopenLeaveRequest(orgID, rejected.ID, uuid.New(), "Rejected by mistake")
+			if status := httpStatus(err); status != tt.want {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if reopened := repo.transitioned == domain.LeaveStatusPending; reopened != (tt.want == 0) {
+				t.Errorf("reopened = %t", reopened)
+			}
+		})
+	}
+}
@@ -1,11 +1,24 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
 	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/Axontik/comin-leave-management-service/pkg/holidays"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/Axontik/comin-leave-management-service/pkg/webhook"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type LeaveService interface {
@@ -16,15 +29,152 @@ type LeaveService interface {
 	DeleteLeaveType(orgID, id uuid.UUID) error
 	ListLeaveTypes(orgID uuid.UUID, params *domain.ListLeaveTypesParams) ([]domain.LeaveType, int64, error)
 	CreateLeaveRequest(orgID uuid.UUID, req *domain.CreateLeaveRequestRequest) (*domain.LeaveRequest, error)
+	GetLeaveRequest(orgID, id uuid.UUID, includeDeleted bool) (*domain.LeaveRequestDetail, error)
+	ExportLeaveRequestAuditBundle(orgID, id, userID uuid.UUID) (*domain.LeaveRequestAuditBundle, error)
+	ListLeaveRequests(orgID uuid.UUID, params *domain.ListLeaveRequestsParams) ([]domain.LeaveRequest, int64, error)
+	ListEmployeeLeaveRequests(orgID, employeeID uuid.UUID, params *domain.ListLeaveRequestsParams) ([]domain.LeaveRequest, int64, error)
+	GetLeaveCalendar(orgID uuid.UUID, from, to time.Time, location string) (*domain.LeaveCalendar, error)
+	ListLeaveRequestChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveRequest, error)
+	EditLeaveRequest(orgID, id, editorID uuid.UUID, req *domain.EditLeaveRequestRequest) (*domain.LeaveRequest, error)
+	ApproveLeaveRequest(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error)
+	RejectLeaveRequest(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.LeaveRequest, error)
+	ListPendingApprovals(orgID, userID uuid.UUID, role string) ([]domain.LeaveRequest, error)
+	CancelLeaveRequest(orgID, id, cancelledBy uuid.UUID, role, comments string) (*domain.LeaveRequest, error)
+	ApproveCancellation(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error)
+	DenyCancellation(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error)
+	DeleteLeaveRequest(orgID, id, deletedBy uuid.UUID) error
+	WithdrawLeaveRequest(orgID, id, employeeID uuid.UUID, role string, undo bool) (*domain.LeaveRequest, error)
+	RestoreLeaveRequest(orgID, id, restoredBy uuid.UUID) (*domain.LeaveRequest, error)
+	PurgeOrganization(orgID uuid.UUID) error
+	ReopenLeaveRequest(orgID, id, reopenedBy uuid.UUID, comments string) (*domain.LeaveRequest, error)
+	UpdateExternalReferences(orgID, id uuid.UUID, refs []domain.ExternalReference) (*domain.LeaveRequest, error)
+
+	// Leave Balance methods
+	CreateLeaveBalance(balance *domain.LeaveBalance) error
+	ListOrgLeaveBalances(orgID uuid.UUID, params *domain.ListLeaveBalancesParams) ([]domain.LeaveBalance, int64, error)
+	ListEmployeeBalances(orgID, employeeID uuid.UUID, year int, includeHistory bool) ([]domain.LeaveBalanceResponse, error)
+	CreateBalanceAdjustment(orgID uuid.UUID, req *domain.CreateBalanceAdjustmentRequest) (*domain.LeaveBalanceAdjustment, error)
+	ListOrgBalanceAdjustments(orgID uuid.UUID, params *domain.ListBalanceAdjustmentsParams) ([]domain.LeaveBalanceAdjustment, int64, error)
+	ApproveBalanceAdjustment(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveBalanceAdjustment, error)
+	RejectBalanceAdjustment(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.LeaveBalanceAdjustment, error)
+	RevertBalanceAdjustment(orgID, id, revertedBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error)
+	BulkAdjustBalances(orgID uuid.UUID, req *domain.BulkAdjustmentRequest) (*domain.BulkAdjustmentReport, error)
+	CancelBalanceAdjustment(orgID, id, cancelledBy uuid.UUID, comments string) (*domain.LeaveBalanceAdjustment, error)
+	ApplyDueBalanceAdjustments(orgID uuid.UUID) (*domain.ScheduledAdjustmentsApplication, error)
+	ApplyAllDueBalanceAdjustments() ([]domain.ScheduledAdjustmentsApplication, error)
+	CreateCompOffRequest(orgID uuid.UUID, req *domain.CreateCompOffRequest) (*domain.CompOffRequest, error)
+	GetCompOffRequest(orgID, id uuid.UUID) (*domain.CompOffRequest, error)
+	ListCompOffRequests(orgID uuid.UUID, params *domain.ListCompOffRequestsParams) ([]domain.CompOffRequest, int64, error)
+	ApproveCompOffRequest(orgID, id, approverID uuid.UUID, role, comments string) (*domain.CompOffRequest, error)
+	RejectCompOffRequest(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.CompOffRequest, error)
+	ExpireCompOff(orgID uuid.UUID) (*domain.CompOffExpirySummary, error)
+	GetBalanceHistory(orgID, employeeID, leaveTypeID uuid.UUID, year, page, pageSize int) (*domain.BalanceLedger, int64, error)
+	GetLeaveHistorySummary(orgID, employeeID uuid.UUID) (*domain.LeaveHistorySummary, error)
+	ListBalanceAdjustmentChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalanceAdjustment, error)
+	TransferLeaveBalance(orgID uuid.UUID, req *domain.BalanceTransferRequest) (*domain.BalanceTransfer, error)
+	StartBulkBalanceTransfer(orgID uuid.UUID, req *domain.BulkBalanceTransferRequest) (*domain.BalanceTransferJob, error)
+	GetBalanceTransferJob(orgID, id uuid.UUID) (*domain.BalanceTransferJob, error)
+	StartYearlyReset(orgID uuid.UUID, req *domain.YearlyResetRequest) (*domain.Job, error)
+	ExpireCarryOver(orgID uuid.UUID) (*domain.CarryOverExpirySummary, error)
+	ListBalanceSnapshots(orgID uuid.UUID, params *domain.ListBalanceSnapshotsParams) ([]domain.BalanceSnapshot, int64, error)
+	AccrueLeave(orgID uuid.UUID, period string) (*domain.AccrualSummary, error)
+	AccrueAllOrganizations() ([]domain.AccrualSummary, error)
+	InitializeEmployeeBalances(orgID, employeeID uuid.UUID, req *domain.InitializeBalancesRequest) (*domain.BalanceInitialization, error)
+	RecalculateBalances(orgID uuid.UUID, params domain.RecalculateBalancesParams) (*domain.BalanceRecalculation, error)
+	StartBalanceRecalculation(orgID, requestedBy uuid.UUID, params domain.RecalculateBalancesParams) (*domain.Job, error)
+
+	// Background jobs
+	GetJob(orgID, id uuid.UUID) (*domain.Job, error)
+	RunJobWorkers(ctx context.Context, workers int, paused func() bool)
+	JobMetrics() (*domain.JobMetrics, error)
+	GetBalanceAnomaly(orgID uuid.UUID) (*domain.BalanceAnomaly, error)
+	ClearBalanceAnomaly(orgID, clearedBy uuid.UUID) (*domain.BalanceAnomaly, error)
+	EvaluateAbsenceAlerts(orgID uuid.UUID) (*domain.AbsenceAlertEvaluation, error)
+	EvaluateAllAbsenceAlerts() ([]domain.AbsenceAlertEvaluation, error)
+	ListAbsenceAlerts(orgID uuid.UUID, params *domain.ListAbsenceAlertsParams) ([]domain.AbsenceAlert, int64, error)
+	AcknowledgeAbsenceAlert(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error)
+	ResolveAbsenceAlert(orgID, id, userID uuid.UUID, comments string) (*domain.AbsenceAlert, error)
+	ListDelegations(orgID uuid.UUID, params *domain.ListDelegationsParams) ([]domain.Delegation, int64, error)
+	ConfirmDelegation(orgID, id, userID uuid.UUID, role string, req *domain.ConfirmDelegationRequest) (*domain.Delegation, error)
+	ChangeDelegate(orgID, id, userID uuid.UUID, role string, req *domain.ChangeDelegateRequest) (*domain.Delegation, error)
+	DeclineDelegation(orgID, id, userID uuid.UUID, role string) (*domain.Delegation, error)
+	EscalateDelegations() (*domain.DelegationEscalation, error)
+
+	// Holiday methods
+	CreateHoliday(holiday *domain.Holiday, change *domain.HolidayChange) error
+	GetHoliday(orgID, id uuid.UUID) (*domain.Holiday, error)
+	ListHolidays(orgID uuid.UUID, params *domain.ListHolidaysParams) ([]domain.Holiday, error)
+	UpdateHoliday(orgID, id uuid.UUID, req *domain.UpdateHolidayRequest, change *domain.HolidayChange) (*domain.Holiday, error)
+	DeleteHoliday(orgID, id uuid.UUID, change *domain.HolidayChange) (*domain.HolidayRecalculation, error)
+	GetHolidayCalendar(orgID uuid.UUID, year int, location string) (*domain.HolidayCalendar, error)
+	ImportHolidays(orgID uuid.UUID, rows []domain.HolidayImportRow, dryRun bool) (*domain.HolidayImportReport, error)
+	ImportPublicHolidays(orgID uuid.UUID, country string, year int, location string, preview bool) (*domain.PublicHolidayImport, error)
+	ClaimOptionalHoliday(orgID, employeeID, holidayID, claimedBy uuid.UUID, callerRole, token string) (*domain.OptionalHolidayClaim, error)
+	UnclaimOptionalHoliday(orgID, employeeID, holidayID uuid.UUID, callerRole string) error
+	ListOptionalHolidayClaims(orgID, employeeID uuid.UUID, year int, token string) (*domain.OptionalHolidayClaims, error)
+
+	// Organization settings methods
+	GetOrganizationSettings(orgID uuid.UUID) (*domain.OrganizationSettings, error)
+	UpdateOrganizationSettings(orgID uuid.UUID, req *domain.UpdateOrganizationSettingsRequest) (*domain.OrganizationSettings, error)
+
+	// Approver pool methods
+	CreateApproverPool(pool *domain.ApproverPool) error
+	GetApproverPool(orgID, id uuid.UUID) (*domain.ApproverPool, error)
+	ListApproverPools(orgID uuid.UUID) ([]domain.ApproverPool, error)
+	AddApproverPoolMember(orgID, poolID, userID uuid.UUID) (*domain.ApproverPoolMember, error)
+	RemoveApproverPoolMember(orgID, poolID, userID uuid.UUID) error
+
+	// Report methods
+	LeaveSummary(orgID uuid.UUID, from, to time.Time) (*domain.LeaveStats, error)
+	ApproverPerformanceReport(orgID uuid.UUID, from, to time.Time, excludeBackdated bool) (*domain.ApproverPerformanceReport, error)
+	HolidayImpactReport(orgID uuid.UUID, from, to time.Time) (*domain.HolidayImpactReport, error)
+	DepartmentAnalysisReport(orgID uuid.UUID, from, to time.Time, token string) (*domain.DepartmentAnalysisReport, error)
+	MonthlyTrendsReport(orgID uuid.UUID, months int, leaveTypeID *uuid.UUID) (*domain.MonthlyTrendsReport, error)
+	LeaveRegister(orgID uuid.UUID, params *domain.LeaveRegisterParams) (*domain.LeaveRegister, error)
+	WriteLeaveRegister(w io.Writer, register *domain.LeaveRegister) error
+	LeaveStatement(orgID, employeeID uuid.UUID, year int, token string) (*domain.LeaveStatement, error)
+	BradfordReport(orgID uuid.UUID, params *domain.BradfordParams) (*domain.BradfordReport, error)
+	WriteBradfordCSV(w io.Writer, report *domain.BradfordReport) error
+
+	// Sync methods
+	SyncEmployee(orgID, employeeID uuid.UUID, role, token string) (*domain.SyncResponse, error)
+
+	// Working day methods
+	AddWorkingDays(orgID uuid.UUID, params *domain.AddWorkingDaysParams) (*domain.WorkingDaysResult, error)
+	GetCalendarDefinition(orgID uuid.UUID, year int, location string) (*domain.CalendarDefinition, error)
+	Today(orgID uuid.UUID) (time.Time, error)
 }
 
+// maxCalendarRange bounds calendar queries to roughly one year
+const maxCalendarRange = 366 * 24 * time.Hour
+
 type leaveService struct {
-	leaveRepo repository.LeaveRepository
+	leaveRepo            repository.LeaveRepository
+	employees            EmployeeDirectory
+	validationSeverities map[string]string
+	holidayProvider      holidays.Provider
+	medians              decisionMediansCache
+	departments          departmentMappingCache
+	balances             *balanceMonitor
+	jobStats             *jobStats
+	webhooks             webhook.Poster
 }
 
-func NewLeaveService(leaveRepo repository.LeaveRepository) LeaveService {
+// NewLeaveService builds the service. employees may be nil to skip employee
+// verification; employeeCheckFailOpen lets requests through when the
+// directory is unreachable, as the advisory checks always are. holidayProvider may be nil to disable public
+// holiday imports.
+func NewLeaveService(leaveRepo repository.LeaveRepository, employees EmployeeDirectory, employeeCheckFailOpen bool, holidayProvider holidays.Provider) LeaveService {
 	return &leaveService{
-		leaveRepo: leaveRepo,
+		leaveRepo:            leaveRepo,
+		employees:            employees,
+		validationSeverities: validationSeverities(employeeCheckFailOpen),
+		holidayProvider:      holidayProvider,
+		medians:              decisionMediansCache{orgs: make(map[uuid.UUID]*decisionMedians)},
+		departments:          departmentMappingCache{orgs: make(map[uuid.UUID]*departmentMapping)},
+		balances:             newBalanceMonitor(),
+		jobStats:             newJobStats(),
+		webhooks:             webhook.NewClient(),
 	}
 }
 
@@ -34,6 +184,9 @@ func (s *leaveService) CreateLeaveType(leaveType *domain.LeaveType) error {
 	if err := validateLeaveType(leaveType); err != nil {
 		return err
 	}
+	if err := s.validateApprovalStrategy(leaveType); err != nil {
+		return err
+	}
 
 	// Check for duplicate name in the organization
 	existingTypes, _, err := s.ListLeaveTypes(leaveType.OrganizationID, &domain.ListLeaveTypesParams{
@@ -54,12 +207,15 @@ func (s *leaveService) CreateLeaveType(leaveType *domain.LeaveType) error {
 func (s *leaveService) GetLeaveType(orgID, id uuid.UUID) (*domain.LeaveType, error) {
 	leaveType, err := s.leaveRepo.GetLeaveType(id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFoundError("leave type not found")
+		}
 		return nil, err
 	}
 
 	// Verify organization ownership
 	if leaveType.OrganizationID != orgID {
-		return nil, errors.New("leave type not found in organization")
+		return nil, apperrors.NewNotFoundError("leave type not found in organization")
 	}
 
 	return leaveType, nil
@@ -71,6 +227,9 @@ func (s *leaveService) UpdateLeaveType(leaveType *domain.LeaveType) error {
 	if err := validateLeaveType(leaveType); err != nil {
 		return err
 	}
+	if err := s.validateApprovalStrategy(leaveType); err != nil {
+		return err
+	}
 
 	// Check if leave type exists
 	existing, err := s.GetLeaveType(leaveType.OrganizationID, leaveType.ID)
@@ -78,6 +237,22 @@ func (s *leaveService) UpdateLeaveType(leaveType *domain.LeaveType) error {
 		return err
 	}
 
+	// Balances already hold amounts in the current unit
+	if existing.Unit != leaveType.Unit {
+		return errors.New("unit cannot be changed once a leave type exists")
+	}
+
+	// Active requests were booked, or not, under the current setting
+	if existing.TracksBalance != leaveType.TracksBalance {
+		hasActiveRequests, err := s.leaveRepo.HasActiveLeaveRequests(leaveType.ID)
+		if err != nil {
+			return err
+		}
+		if hasActiveRequests {
+			return errors.New("tracks_balance cannot be changed while the leave type has active leave requests")
+		}
+	}
+
 	// Check for name uniqueness if name is being changed
 	if existing.Name != leaveType.Name {
 		existingTypes, _, err := s.ListLeaveTypes(leaveType.OrganizationID, &domain.ListLeaveTypesParams{
@@ -124,13 +299,67 @@ func (s *leaveService) ListLeaveTypes(orgID uuid.UUID, params *domain.ListLeaveT
 		if params.PageSize < 1 || params.PageSize > 100 {
 			params.PageSize = 10
 		}
+		if params.EmployeeID != nil {
+			return s.listEligibleLeaveTypes(orgID, params)
+		}
 	}
 
 	return s.leaveRepo.ListLeaveTypesWithOptions(orgID, params)
 }
 
+// listEligibleLeaveTypes lists the leave types the employee is eligible
+// for. Eligibility is evaluated here rather than in the query, so the
+// matching types are loaded in full and paginated after filtering. When
+// the employee could not be looked up no type is filtered out.
+func (s *leaveService) listEligibleLeaveTypes(orgID uuid.UUID, params *domain.ListLeaveTypesParams) ([]domain.LeaveType, int64, error) {
+	employee, err := s.verifyEmployee(params.AuthToken, orgID, *params.EmployeeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	all := *params
+	all.Page, all.PageSize = 0, 0
+	leaveTypes, _, err := s.leaveRepo.ListLeaveTypesWithOptions(orgID, &all)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	eligible := leaveTypes
+	if employee != nil {
+		attributes := employeeAttributes(employee)
+		eligible = leaveTypes[:0]
+		for _, leaveType := range leaveTypes {
+			if leaveType.Eligibility.FailedCondition(attributes) == nil {
+				eligible = append(eligible, leaveType)
+			}
+		}
+	}
+
+	total := int64(len(eligible))
+	start := (params.Page - 1) * params.PageSize
+	if start > len(eligible) {
+		start = len(eligible)
+	}
+	end := start + params.PageSize
+	if end > len(eligible) {
+		end = len(eligible)
+	}
+	return eligible[start:end], total, nil
+}
+
 // Helper functions
 
+// normalizeChangesParams defaults a missing limit and clamps one over the
+// maximum, so a client asking for too much gets a full page, not a small one
+func normalizeChangesParams(params *domain.ChangesParams) {
+	switch {
+	case params.Limit < 1:
+		params.Limit = domain.DefaultChangesLimit
+	case params.Limit > domain.MaxChangesLimit:
+		params.Limit = domain.MaxChangesLimit
+	}
+}
+
 func validateLeaveType(leaveType *domain.LeaveType) error {
 	if leaveType.Name == "" {
 		return errors.New("name is required")
@@ -144,19 +373,65 @@ func validateLeaveType(leaveType *domain.LeaveType) error {
 	if leaveType.MinDaysNotice < 0 {
 		return errors.New("minimum days notice cannot be negative")
 	}
+	if !domain.IsValidRounding(leaveType.Rounding) {
+		return errors.New("rounding must be one of none, half_up or full_up")
+	}
+	if leaveType.Rounding == "" {
+		leaveType.Rounding = domain.RoundingNone
+	}
+	if !domain.IsValidLeaveUnit(leaveType.Unit) {
+		return errors.New("unit must be days or hours")
+	}
+	if leaveType.Unit == "" {
+		leaveType.Unit = domain.LeaveUnitDays
+	}
+	if leaveType.MinTenureDays < 0 {
+		return errors.New("minimum tenure days cannot be negative")
+	}
+	if leaveType.MaxCarryOverDays < 0 {
+		return errors.New("max carry over days cannot be negative")
+	}
+	if leaveType.CarryOverExpiryMonths < 0 || leaveType.CarryOverExpiryMonths > 12 {
+		return errors.New("carry over expiry months must be between 0 and 12")
+	}
+	if !domain.IsValidAccrualFrequency(leaveType.AccrualFrequency) {
+		return errors.New("accrual frequency must be none, monthly or quarterly")
+	}
+	if leaveType.AccrualFrequency == "" {
+		leaveType.AccrualFrequency = domain.AccrualFrequencyNone
+	}
+	if leaveType.AccrualRate < 0 {
+		return errors.New("accrual rate cannot be negative")
+	}
+	if leaveType.Accrues() && leaveType.AccrualRate == 0 {
+		return errors.New("accrual rate is required for accruing leave types")
+	}
+	if leaveType.MaxBalanceDays != nil && *leaveType.MaxBalanceDays < 0 {
+		return errors.New("max balance days cannot be negative")
+	}
+	if leaveType.MaxNegativeDays != nil {
+		if !leaveType.AllowNegativeBalance {
+			return errors.New("max negative days requires allow negative balance")
+		}
+		if *leaveType.MaxNegativeDays < 0 {
+			return errors.New("max negative days cannot be negative")
+		}
+	}
+	if leaveType.Eligibility != nil {
+		if err := leaveType.Eligibility.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *leaveService) CreateLeaveRequest(orgID uuid.UUID, req *domain.CreateLeaveRequestRequest) (*domain.LeaveRequest, error) {
 	// Validate request
 	if req.EmployeeID == uuid.Nil {
-		return nil, errors.New("employee ID is required")
+		return nil, apperrors.NewBadRequestError("employee ID is required")
 	}
 	if req.LeaveTypeID == uuid.Nil {
-		return nil, errors.New("leave type ID is required")
-	}
-	if req.StartDate.After(req.EndDate) {
-		return nil, errors.New("start date cannot be after end date")
+		return nil, apperrors.NewBadRequestError("leave type ID is required")
 	}
 
 	// Get leave type
@@ -165,29 +440,1764 @@ func (s *leaveService) CreateLeaveRequest(orgID uuid.UUID, req *domain.CreateLea
 		return nil, err
 	}
 
-	// Calculate total days
-	totalDays := int(req.EndDate.Sub(req.StartDate).Milliseconds() / 86400000)
-	if totalDays > leaveType.MaxDaysPerRequest {
-		return nil, errors.New("total days exceed maximum allowed")
+	planned, err := s.planLeave(orgID, leaveType, &leavePeriod{
+		EmployeeID:   req.EmployeeID,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		StartHalf:    req.StartHalf,
+		EndHalf:      req.EndHalf,
+		Hours:        req.Hours,
+		StartTime:    req.StartTime,
+		BypassNotice: req.BypassNotice,
+		AuthToken:    req.AuthToken,
+		CallerRole:   req.CallerRole,
+
+		OverrideProbation: req.OverrideProbation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	employee, today, backdated, days := planned.employee, planned.today, planned.backdated, planned.days
+	req.EndDate = planned.endDate
+	// Fractional days don't add up exactly in floating point, so only a
+	// difference of a hundredth or more is a mismatch
+	if req.TotalDays != 0 && math.Abs(req.TotalDays-days) >= 0.005 {
+		return nil, apperrors.NewBadRequestError(fmt.Sprintf(
+			"total_days %.2f does not match the %.2f working days between start and end date", req.TotalDays, days))
+	}
+
+	externalRefs, err := domain.NormalizeExternalReferences(req.ExternalReferences)
+	if err != nil {
+		return nil, apperrors.NewBadRequestError(err.Error())
 	}
 
 	// Create leave request
 	leaveRequest := &domain.LeaveRequest{
-		EmployeeID:  req.EmployeeID,
-		LeaveTypeID: req.LeaveTypeID,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Status:      domain.LeaveStatusPending,
-		Reason:      req.Reason,
+		OrganizationID: orgID,
+		EmployeeID:     req.EmployeeID,
+		LeaveTypeID:    req.LeaveTypeID,
+		StartDate:      req.StartDate,
+		EndDate:        req.EndDate,
+		StartHalf:      req.StartHalf,
+		EndHalf:        req.EndHalf,
+		Hours:          req.Hours,
+		StartTime:      req.StartTime,
+		Days:           days,
+		RawDays:        planned.rawDays,
+		Status:         domain.LeaveStatusPending,
+		Reason:         req.Reason,
+		Comments:       req.Comment,
+		Backdated:      backdated,
+
+		ProbationOverridden: planned.probationOverridden,
+		SkippedValidations:  planned.skipped,
 	}
+	leaveRequest.SetBreakdown(planned.breakdown)
+	leaveRequest.ExternalReferences = externalRefs
 
-	// Save leave request
-	if err := s.leaveRepo.CreateLeaveRequest(leaveRequest); err != nil {
+	if err := s.applyAbsenceLimit(orgID, req.AuthToken, employee, leaveRequest); err != nil {
+		return nil, err
+	}
+
+	// Leave types without approval are approved on creation by the system;
+	// everything else, including any backdated request, is routed to an
+	// approver before it is saved
+	var history *domain.LeaveRequestHistory
+	if !leaveType.RequiresApproval && !backdated {
+		now := time.Now()
+		leaveRequest.Status = domain.LeaveStatusApproved
+		leaveRequest.ApprovedBy = &domain.SystemUserID
+		leaveRequest.ApprovedAt = &now
+		history = &domain.LeaveRequestHistory{
+			Action:      domain.LeaveActionAutoApprove,
+			Status:      domain.LeaveStatusApproved,
+			PerformedBy: domain.SystemUserID,
+		}
+	} else {
+		leaveRequest.AssignedApproverID, err = s.approverResolver(leaveType).Assign(leaveRequest)
+		if errors.Is(err, repository.ErrEmptyApproverPool) {
+			return nil, apperrors.NewConflictError("the approver pool for this leave type has no members")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entitlement, err := s.entitlement(orgID, leaveType)
+	if err != nil {
 		return nil, err
 	}
 
+	// A balance opened for a year ahead is provisional until the yearly
+	// reset adopts it
+	provisional := req.StartDate.Year() > today.Year()
+
+	// Save leave request
+	if err := s.leaveRepo.CreateLeaveRequest(leaveRequest, leaveType, entitlement, provisional, history); err != nil {
+		return nil, requestBalanceError(err, leaveType.Unit)
+	}
+	s.recordSkippedValidations(leaveRequest)
+	if leaveRequest.Status == domain.LeaveStatusApproved {
+		s.syncDelegation(leaveRequest)
+	}
+
+	if err := s.warnExternalReferenceDuplicates(leaveRequest); err != nil {
+		return nil, err
+	}
+	s.estimateDecision(leaveRequest)
 	return leaveRequest, nil
 }
 
+// CreateLeaveBalance opens a balance for an employee and leave type
+func (s *leaveService) CreateLeaveBalance(balance *domain.LeaveBalance) error {
+	if balance.EmployeeID == uuid.Nil {
+		return errors.New("employee ID is required")
+	}
+	if balance.TotalDays < 0 {
+		return errors.New("total days cannot be negative")
+	}
+
+	// Verify the leave type belongs to the same organization
+	leaveType, err := s.GetLeaveType(balance.OrganizationID, balance.LeaveTypeID)
+	if err != nil {
+		return err
+	}
+	if !leaveType.TracksBalance {
+		return apperrors.NewBadRequestError(leaveType.Name + " does not track a balance")
+	}
+	if balance.OpeningDays == nil {
+		opening := balance.TotalDays
+		balance.OpeningDays = &opening
+	}
+
+	return s.leaveRepo.CreateLeaveBalance(balance)
+}
+
+// CreateHoliday adds a holiday to the organization calendar. Employees
+// observe at most one holiday per date, so an organization-wide holiday
+// cannot share its date with another, nor a located one with an
+// organization-wide one or another of its location. With a change, the
+// leave requests the holiday falls in are recalculated, or only listed
+// when previewing.
+func (s *leaveService) CreateHoliday(holiday *domain.Holiday, change *domain.HolidayChange) error {
+	if err := validateHoliday(holiday); err != nil {
+		return err
+	}
+	holiday.Date = domain.TruncateToDate(holiday.Date)
+	holiday.Location = domain.HolidayLocation(holiday.LocationName())
+	if err := s.checkHolidayDateFree(holiday); err != nil {
+		return err
+	}
+
+	if change != nil && change.PreviewImpact {
+		var err error
+		holiday.Recalculation, err = s.recalculateHolidayLeave(holiday.OrganizationID, nil, holiday, change)
+		return err
+	}
+	if err := s.leaveRepo.CreateHoliday(holiday); err != nil {
+		return err
+	}
+	if change != nil {
+		holiday.Recalculation = s.applyHolidayRecalculation(holiday.OrganizationID, nil, holiday, change)
+	}
+	return nil
+}
+
+// GetHoliday returns a holiday of the organization
+func (s *leaveService) GetHoliday(orgID, id uuid.UUID) (*domain.Holiday, error) {
+	holiday, err := s.leaveRepo.GetHoliday(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && holiday.OrganizationID != orgID) {
+		return nil, apperrors.NewNotFoundError("holiday not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// ListHolidays lists the organization's holidays by date, within the year
+// or the from and to dates when set, and only those observed at the
+// location when set
+func (s *leaveService) ListHolidays(orgID uuid.UUID, params *domain.ListHolidaysParams) ([]domain.Holiday, error) {
+	var from, to time.Time
+	if params.Year != 0 {
+		if params.Year < 1900 || params.Year > 9999 {
+			return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+		}
+		from = time.Date(params.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to = time.Date(params.Year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	if params.From != nil && params.From.After(from) {
+		from = *params.From
+	}
+	if params.To != nil && (to.IsZero() || params.To.Before(to)) {
+		to = *params.To
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, apperrors.NewBadRequestError("from date cannot be after to date")
+	}
+
+	holidays, err := s.leaveRepo.ListHolidays(orgID, from, to)
+	if err != nil || params.Location == "" {
+		return holidays, err
+	}
+	return domain.HolidaysAt(holidays, params.Location), nil
+}
+
+// GetHolidayCalendar returns the organization's holidays of a year by
+// month, only those observed at location when set
+func (s *leaveService) GetHolidayCalendar(orgID uuid.UUID, year int, location string) (*domain.HolidayCalendar, error) {
+	if year < 1900 || year > 9999 {
+		return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+	}
+	holidays, err := s.ListHolidays(orgID, &domain.ListHolidaysParams{Year: year, Location: location})
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildHolidayCalendar(year, location, holidays), nil
+}
+
+// UpdateHoliday changes a holiday of the organization. A new date,
+// location or type changes which days leave around it takes, so with a
+// change those leave requests are recalculated, or only listed when
+// previewing, in which case the holiday is returned unsaved.
+func (s *leaveService) UpdateHoliday(orgID, id uuid.UUID, req *domain.UpdateHolidayRequest, change *domain.HolidayChange) (*domain.Holiday, error) {
+	holiday, err := s.GetHoliday(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *holiday
+	if req.Name != nil {
+		holiday.Name = *req.Name
+	}
+	if req.Type != nil {
+		holiday.Type = *req.Type
+	}
+	if req.Date != nil {
+		holiday.Date = domain.TruncateToDate(*req.Date)
+	}
+	if req.Location != nil {
+		holiday.Location = domain.HolidayLocation(*req.Location)
+	}
+	if err := validateHoliday(holiday); err != nil {
+		return nil, err
+	}
+
+	moved := !holiday.Date.Equal(domain.TruncateToDate(before.Date)) ||
+		!strings.EqualFold(holiday.LocationName(), before.LocationName())
+	if moved {
+		if err := s.checkHolidayDateFree(holiday); err != nil {
+			return nil, err
+		}
+	}
+	recount := change != nil && (moved || holiday.Type != before.Type)
+
+	if change != nil && change.PreviewImpact {
+		holiday.Recalculation = &domain.HolidayRecalculation{Preview: true, LeaveRequests: []domain.RecalculatedLeaveRequest{}}
+		if recount {
+			if holiday.Recalculation, err = s.recalculateHolidayLeave(orgID, &before, holiday, change); err != nil {
+				return nil, err
+			}
+		}
+		return holiday, nil
+	}
+
+	if err := s.leaveRepo.UpdateHoliday(holiday); err != nil {
+		return nil, err
+	}
+	if recount {
+		holiday.Recalculation = s.applyHolidayRecalculation(orgID, &before, holiday, change)
+	}
+	return holiday, nil
+}
+
+// DeleteHoliday removes a holiday of the organization. With a change, the
+// leave requests it falls in are recalculated, or only listed when
+// previewing, in which case the holiday is kept.
+func (s *leaveService) DeleteHoliday(orgID, id uuid.UUID, change *domain.HolidayChange) (*domain.HolidayRecalculation, error) {
+	holiday, err := s.GetHoliday(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if change != nil && change.PreviewImpact {
+		return s.recalculateHolidayLeave(orgID, holiday, nil, change)
+	}
+
+	if err := s.leaveRepo.DeleteHoliday(holiday.ID); err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, nil
+	}
+	return s.applyHolidayRecalculation(orgID, holiday, nil, change), nil
+}
+
+func validateHoliday(holiday *domain.Holiday) error {
+	if holiday.Name == "" {
+		return apperrors.NewBadRequestError("name is required")
+	}
+	if len([]rune(holiday.Name)) > 100 {
+		return apperrors.NewBadRequestError("name must be at most 100 characters")
+	}
+	if holiday.Date.IsZero() {
+		return apperrors.NewBadRequestError("date is required")
+	}
+	if len([]rune(holiday.LocationName())) > 100 {
+		return apperrors.NewBadRequestError("location must be at most 100 characters")
+	}
+
+	switch holiday.Type {
+	case domain.HolidayTypePublic, domain.HolidayTypeCompany, domain.HolidayTypeOptional:
+	default:
+		return apperrors.NewBadRequestError("holiday type must be public, company or optional")
+	}
+	return nil
+}
+
+// checkHolidayDateFree refuses a second holiday on the same date for the
+// same employees
+func (s *leaveService) checkHolidayDateFree(holiday *domain.Holiday) error {
+	existing, err := s.leaveRepo.ListHolidaysOnDate(holiday.OrganizationID, holiday.Date)
+	if err != nil {
+		return err
+	}
+	for i := range existing {
+		other := &existing[i]
+		if other.ID == holiday.ID || !other.Overlaps(holiday) {
+			continue
+		}
+		if strings.EqualFold(other.Name, holiday.Name) && other.LocationName() == holiday.LocationName() {
+			return apperrors.NewConflictError(fmt.Sprintf("%s is already a holiday on %s",
+				other.Name, holiday.Date.Format("2006-01-02")))
+		}
+		where := "for the whole organization"
+		if other.Location != nil {
+			where = "in " + *other.Location
+		}
+		return apperrors.NewConflictError(fmt.Sprintf("%s already falls on %s %s, employees observe one holiday per date",
+			other.Name, holiday.Date.Format("2006-01-02"), where))
+	}
+	return nil
+}
+
+// GetLeaveRequest retrieves a leave request of the organization with its history
+func (s *leaveService) GetLeaveRequest(orgID, id uuid.UUID, includeDeleted bool) (*domain.LeaveRequestDetail, error) {
+	var leaveRequest *domain.LeaveRequest
+	var err error
+	if includeDeleted {
+		leaveRequest, err = s.getOrgLeaveRequestIncludingDeleted(orgID, id)
+	} else {
+		leaveRequest, err = s.getOrgLeaveRequest(orgID, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.leaveRepo.ListLeaveRequestHistory(leaveRequest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.estimateDecision(leaveRequest)
+	return &domain.LeaveRequestDetail{
+		LeaveRequest: leaveRequest,
+		History:      history,
+	}, nil
+}
+
+// ExportLeaveRequestAuditBundle gathers a request of the organization, deleted
+// or not, and its full history into one document. The export is recorded
+// in the request's history first, so the bundle cannot be produced without
+// a trace and lists its own generation.
+func (s *leaveService) ExportLeaveRequestAuditBundle(orgID, id, userID uuid.UUID) (*domain.LeaveRequestAuditBundle, error) {
+	leaveRequest, err := s.getOrgLeaveRequestIncludingDeleted(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.leaveRepo.CreateLeaveRequestHistory(&domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionAuditExport,
+		Status:         leaveRequest.Status,
+		PerformedBy:    userID,
+	}); err != nil {
+		return nil, err
+	}
+
+	history, err := s.leaveRepo.ListLeaveRequestHistory(leaveRequest.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+
+	return &domain.LeaveRequestAuditBundle{
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: userID,
+		Request:     leaveRequest,
+		History:     history,
+		NotRecorded: domain.AuditBundleNotRecorded,
+	}, nil
+}
+
+// ListLeaveRequests lists leave requests with filtering and pagination
+func (s *leaveService) ListLeaveRequests(orgID uuid.UUID, params *domain.ListLeaveRequestsParams) ([]domain.LeaveRequest, int64, error) {
+	// Validate pagination parameters
+	if params != nil {
+		if params.Page < 1 {
+			params.Page = 1
+		}
+		if params.PageSize < 1 || params.PageSize > 100 {
+			params.PageSize = 10
+		}
+		if params.From != nil && params.To != nil && params.From.After(*params.To) {
+			return nil, 0, apperrors.NewBadRequestError("from date cannot be after to date")
+		}
+	}
+
+	return s.leaveRepo.ListLeaveRequestsWithOptions(orgID, params)
+}
+
+// ListEmployeeLeaveRequests lists one employee's leave requests
+func (s *leaveService) ListEmployeeLeaveRequests(orgID, employeeID uuid.UUID, params *domain.ListLeaveRequestsParams) ([]domain.LeaveRequest, int64, error) {
+	params.EmployeeID = &employeeID
+	return s.ListLeaveRequests(orgID, params)
+}
+
+// GetLeaveCalendar returns who is on approved or pending leave, claimed
+// optional holidays included, on each day of the range, alongside the
+// holidays in it: those observed at location when
+// set, every location's otherwise
+func (s *leaveService) GetLeaveCalendar(orgID uuid.UUID, from, to time.Time, location string) (*domain.LeaveCalendar, error) {
+	if from.After(to) {
+		return nil, apperrors.NewBadRequestError("from date cannot be after to date")
+	}
+	if to.Sub(from) > maxCalendarRange {
+		return nil, apperrors.NewBadRequestError("calendar range cannot exceed 366 days")
+	}
+
+	days, err := s.leaveRepo.ListLeaveRequestDays(orgID, from, to, domain.ActiveLeaveStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := s.leaveRepo.ListHolidays(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if location != "" {
+		holidays = domain.HolidaysAt(holidays, location)
+	}
+
+	claims, err := s.leaveRepo.ListOptionalHolidayClaims(orgID, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.BuildLeaveCalendar(from, to, days, holidays, claims), nil
+}
+
+// ListLeaveRequestChanges returns leave requests changed after the watermark
+func (s *leaveService) ListLeaveRequestChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveRequest, error) {
+	normalizeChangesParams(params)
+	return s.leaveRepo.ListLeaveRequestChanges(orgID, params)
+}
 
+// ListOrgLeaveBalances lists the organization's balances for a year,
+// the current one unless set, with pagination
+func (s *leaveService) ListOrgLeaveBalances(orgID uuid.UUID, params *domain.ListLeaveBalancesParams) ([]domain.LeaveBalance, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+	if params.Year == 0 {
+		params.Year = time.Now().Year()
+	}
+
+	return s.leaveRepo.ListOrgLeaveBalances(orgID, params)
+}
+
+// balanceHistoryLimit is how many of a balance's latest adjustments are
+// embedded when its history is requested
+const balanceHistoryLimit = 10
+
+// ListEmployeeBalances returns the employee's balances for a year, the
+// current one unless set, optionally with each balance's latest adjustments.
+// An employee without balances gets an empty list.
+func (s *leaveService) ListEmployeeBalances(orgID, employeeID uuid.UUID, year int, includeHistory bool) ([]domain.LeaveBalanceResponse, error) {
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	balances, err := s.leaveRepo.ListLeaveBalances(orgID, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]domain.LeaveBalanceResponse, len(balances))
+	index := make(map[uuid.UUID]int, len(balances))
+	for i, b := range balances {
+		responses[i] = domain.LeaveBalanceResponse{
+			ID:                   b.ID,
+			LeaveTypeID:          b.LeaveTypeID,
+			Year:                 b.Year,
+			TotalDays:            b.TotalDays,
+			UsedDays:             b.UsedDays,
+			PendingDays:          b.PendingDays,
+			RemainingDays:        b.Remaining(),
+			CarriedOverDays:      b.CarriedOverRemaining(),
+			CarriedOverExpiresOn: b.CarriedOverExpiresOn,
+			Provisional:          b.Provisional,
+		}
+		if b.LeaveType != nil {
+			responses[i].LeaveType = b.LeaveType.Name
+			responses[i].Unit = b.LeaveType.Unit
+			responses[i].MaxBalanceDays = b.LeaveType.MaxBalanceDays
+			responses[i].AtCap = b.LeaveType.AtBalanceCap(b.TotalDays - b.UsedDays)
+			responses[i].ForfeitedAccrualPerMonth = b.LeaveType.ForfeitedAccrualPerMonth(b.TotalDays - b.UsedDays)
+		}
+		index[b.ID] = i
+	}
 
+	ids := make([]uuid.UUID, len(balances))
+	for i, b := range balances {
+		ids[i] = b.ID
+	}
+	scheduled, err := s.leaveRepo.SumScheduledAdjustments(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range responses {
+		responses[i].ScheduledDays = scheduled[responses[i].ID]
+		responses[i].ProjectedRemainingDays = responses[i].RemainingDays + responses[i].ScheduledDays
+	}
+
+	if !includeHistory || len(balances) == 0 {
+		return responses, nil
+	}
+
+	adjustments, err := s.leaveRepo.ListRecentBalanceAdjustments(ids, balanceHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range adjustments {
+		i := index[a.LeaveBalanceID]
+		responses[i].History = append(responses[i].History, a)
+	}
+	return responses, nil
+}
+
+// ListBalanceAdjustmentChanges returns balance adjustments changed after the watermark
+func (s *leaveService) ListBalanceAdjustmentChanges(orgID uuid.UUID, params *domain.ChangesParams) ([]domain.LeaveBalanceAdjustment, error) {
+	normalizeChangesParams(params)
+	return s.leaveRepo.ListBalanceAdjustmentChanges(orgID, params)
+}
+
+// EditLeaveRequest changes the dates, leave type or reason of a pending
+// request, rebooking its pending days on the balance
+func (s *leaveService) EditLeaveRequest(orgID, id, editorID uuid.UUID, req *domain.EditLeaveRequestRequest) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Employees edit their own requests; whoever may decide a request
+	// may correct it
+	if editorID != leaveRequest.EmployeeID && !s.canApprove(leaveRequest, editorID, req.CallerRole) {
+		return nil, apperrors.NewForbiddenError("not allowed to edit this leave request")
+	}
+
+	if !leaveRequest.CanEdit() {
+		return nil, apperrors.NewInvalidStatusError("only pending leave requests can be edited")
+	}
+
+	leaveType, err := s.GetLeaveType(orgID, req.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Balances of the two units cannot be rebooked against each other
+	if leaveRequest.LeaveType != nil && leaveRequest.LeaveType.IsHourly() != leaveType.IsHourly() {
+		return nil, apperrors.NewBadRequestError("a request cannot move between leave types tracked in days and in hours")
+	}
+
+	// Edits obey the same rules as new requests, counted from the raw
+	// days so repeated edits never compound the rounding
+	planned, err := s.planLeave(orgID, leaveType, &leavePeriod{
+		RequestID:    leaveRequest.ID,
+		EmployeeID:   leaveRequest.EmployeeID,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		StartHalf:    req.StartHalf,
+		EndHalf:      req.EndHalf,
+		Hours:        req.Hours,
+		StartTime:    req.StartTime,
+		BypassNotice: req.BypassNotice,
+		AuthToken:    req.AuthToken,
+		CallerRole:   req.CallerRole,
+
+		OverrideProbation: req.OverrideProbation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Moving to another leave type reroutes the request to that type's approvers
+	if leaveType.ID != leaveRequest.LeaveTypeID {
+		leaveRequest.AssignedApproverID, err = s.approverResolver(leaveType).Assign(leaveRequest)
+		if errors.Is(err, repository.ErrEmptyApproverPool) {
+			return nil, apperrors.NewConflictError("the approver pool for this leave type has no members")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	leaveRequest.LeaveTypeID = leaveType.ID
+	leaveRequest.LeaveType = leaveType
+	leaveRequest.StartDate = req.StartDate
+	leaveRequest.EndDate = planned.endDate
+	leaveRequest.StartHalf = req.StartHalf
+	leaveRequest.EndHalf = req.EndHalf
+	leaveRequest.Hours = req.Hours
+	leaveRequest.StartTime = req.StartTime
+	leaveRequest.Days = planned.days
+	leaveRequest.RawDays = planned.rawDays
+	leaveRequest.Backdated = planned.backdated
+	leaveRequest.ProbationOverridden = planned.probationOverridden
+	leaveRequest.SetBreakdown(planned.breakdown)
+	leaveRequest.Reason = req.Reason
+
+	leaveRequest.HasConflicts = false
+	leaveRequest.AbsenceConflicts = nil
+	leaveRequest.SkippedValidations = planned.skipped
+	if err := s.applyAbsenceLimit(orgID, req.AuthToken, planned.employee, leaveRequest); err != nil {
+		return nil, err
+	}
+
+	entitlement, err := s.entitlement(orgID, leaveType)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionUpdate,
+		Status:         leaveRequest.Status,
+		PerformedBy:    editorID,
+	}
+
+	provisional := leaveRequest.StartDate.Year() > planned.today.Year()
+	err = s.leaveRepo.UpdatePendingLeaveRequest(leaveRequest, entitlement, provisional, history)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return nil, apperrors.NewInvalidStatusError("only pending leave requests can be edited")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NewNotFoundError("leave balance not found for this leave request")
+	}
+	if err != nil {
+		return nil, requestBalanceError(err, leaveType.Unit)
+	}
+	s.recordSkippedValidations(leaveRequest)
+
+	return leaveRequest, nil
+}
+
+// requestBalanceError maps the repository's balance check failures on
+// booking a request's days to API errors, leaving other errors as they are
+func requestBalanceError(err error, unit string) error {
+	var insufficient *repository.InsufficientBalanceError
+	if errors.As(err, &insufficient) {
+		return apperrors.NewInsufficientBalanceError(fmt.Sprintf(
+			"insufficient leave balance: %.2f %s requested, %.2f available, short by %.2f",
+			insufficient.Requested, unit, insufficient.Available, insufficient.Requested-insufficient.Available))
+	}
+	var capExceeded *repository.NegativeBalanceCapError
+	if errors.As(err, &capExceeded) {
+		return negativeBalanceCapError(capExceeded, unit)
+	}
+	return err
+}
+
+// ApproveLeaveRequest approves a pending request, moving its days from
+// pending to used on the employee's balance
+func (s *leaveService) ApproveLeaveRequest(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canApprove(leaveRequest, approverID, role) {
+		return nil, apperrors.NewForbiddenError("you are not an approver for this leave request")
+	}
+
+	if !leaveRequest.CanApprove() {
+		return nil, apperrors.NewInvalidStatusError("only pending leave requests can be approved")
+	}
+
+	if leaveType := leaveRequest.LeaveType; leaveType != nil &&
+		leaveType.ApprovalStrategy == domain.ApprovalStrategyQuorum && leaveType.QuorumSize > 1 {
+		return s.recordQuorumApproval(leaveRequest, approverID, comments)
+	}
+
+	now := time.Now()
+	leaveRequest.Status = domain.LeaveStatusApproved
+	leaveRequest.ApprovedBy = &approverID
+	leaveRequest.ApprovedAt = &now
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionApprove,
+		Status:         domain.LeaveStatusApproved,
+		Comments:       comments,
+		PerformedBy:    approverID,
+	}
+
+	if err := s.transitionLeaveRequest(leaveRequest, domain.LeaveStatusPending, history); err != nil {
+		return nil, err
+	}
+
+	return leaveRequest, nil
+}
+
+// recordQuorumApproval counts the approver toward the leave type's quorum,
+// approving the request once it is met
+func (s *leaveService) recordQuorumApproval(leaveRequest *domain.LeaveRequest, approverID uuid.UUID, comments string) (*domain.LeaveRequest, error) {
+	approval := &domain.LeaveRequestApproval{
+		LeaveRequestID: leaveRequest.ID,
+		ApproverID:     approverID,
+	}
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionApprove,
+		Status:         domain.LeaveStatusApproved,
+		Comments:       comments,
+		PerformedBy:    approverID,
+	}
+
+	_, err := s.leaveRepo.RecordQuorumApproval(leaveRequest, approval, leaveRequest.LeaveType.QuorumSize, history)
+	if errors.Is(err, repository.ErrAlreadyApproved) {
+		return nil, apperrors.NewConflictError("you have already approved this leave request")
+	}
+	if err != nil {
+		return nil, s.transitionError(err)
+	}
+	// Only the approval meeting the quorum changes the balance
+	s.recordUsedDaysChange(leaveRequest, domain.LeaveStatusPending)
+	if leaveRequest.Status == domain.LeaveStatusApproved {
+		s.syncDelegation(leaveRequest)
+	}
+
+	leaveRequest.Approvals = append(leaveRequest.Approvals, *approval)
+	return leaveRequest, nil
+}
+
+// RejectLeaveRequest rejects a pending request and releases its pending days
+func (s *leaveService) RejectLeaveRequest(orgID, id, rejectorID uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	if strings.TrimSpace(comments) == "" {
+		return nil, apperrors.NewBadRequestError("a comment explaining the rejection is required")
+	}
+
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canApprove(leaveRequest, rejectorID, role) {
+		return nil, apperrors.NewForbiddenError("you are not an approver for this leave request")
+	}
+
+	if leaveRequest.Status != domain.LeaveStatusPending {
+		return nil, apperrors.NewInvalidStatusError("only pending leave requests can be rejected")
+	}
+
+	leaveRequest.Status = domain.LeaveStatusRejected
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionReject,
+		Status:         domain.LeaveStatusRejected,
+		Comments:       comments,
+		PerformedBy:    rejectorID,
+	}
+
+	if err := s.transitionLeaveRequest(leaveRequest, domain.LeaveStatusPending, history); err != nil {
+		return nil, err
+	}
+
+	return leaveRequest, nil
+}
+
+// CancelLeaveRequest cancels a pending request, or an approved one that has
+// not started yet, returning its days to the balance. Approved leave inside
+// the organization's free cancellation window only moves to
+// cancellation_requested unless the caller is one of its approvers.
+func (s *leaveService) CancelLeaveRequest(orgID, id, cancelledBy uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Employees cancel their own requests; otherwise only whoever may
+	// decide the request, admins included
+	if cancelledBy != leaveRequest.EmployeeID && !s.canApprove(leaveRequest, cancelledBy, role) {
+		return nil, apperrors.NewForbiddenError("not allowed to cancel this leave request")
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	today := settings.Today()
+
+	if !leaveRequest.CanCancel(today) {
+		if leaveRequest.Status == domain.LeaveStatusCancellationRequested {
+			return nil, apperrors.NewInvalidStatusError("a cancellation for this leave is already awaiting approval")
+		}
+		if leaveRequest.Status == domain.LeaveStatusApproved {
+			return nil, apperrors.NewInvalidStatusError("approved leave that has already started cannot be cancelled")
+		}
+		return nil, apperrors.NewInvalidStatusError("only pending or upcoming approved leave requests can be cancelled")
+	}
+
+	fromStatus := leaveRequest.Status
+	toStatus := domain.LeaveStatusCancelled
+	action := domain.LeaveActionCancel
+
+	if fromStatus == domain.LeaveStatusApproved && !s.canApprove(leaveRequest, cancelledBy, role) {
+		if leaveRequest.InsideCancellationWindow(today, settings.FreeCancellationDays) {
+			toStatus = domain.LeaveStatusCancellationRequested
+			action = domain.LeaveActionRequestCancellation
+		}
+	}
+
+	leaveRequest.Status = toStatus
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         action,
+		Status:         toStatus,
+		Comments:       comments,
+		PerformedBy:    cancelledBy,
+	}
+
+	if err := s.transitionLeaveRequest(leaveRequest, fromStatus, history); err != nil {
+		return nil, err
+	}
+
+	return leaveRequest, nil
+}
+
+// ApproveCancellation finalizes a requested cancellation and returns the
+// leave's days to the balance
+func (s *leaveService) ApproveCancellation(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	return s.decideCancellation(orgID, id, approverID, role, comments, true)
+}
+
+// DenyCancellation keeps the leave approved
+func (s *leaveService) DenyCancellation(orgID, id, approverID uuid.UUID, role, comments string) (*domain.LeaveRequest, error) {
+	return s.decideCancellation(orgID, id, approverID, role, comments, false)
+}
+
+func (s *leaveService) decideCancellation(orgID, id, approverID uuid.UUID, role, comments string, approve bool) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canApprove(leaveRequest, approverID, role) {
+		return nil, apperrors.NewForbiddenError("you are not an approver for this leave request")
+	}
+
+	if !leaveRequest.CanDecideCancellation() {
+		return nil, apperrors.NewInvalidStatusError("this leave request has no cancellation awaiting approval")
+	}
+
+	toStatus := domain.LeaveStatusApproved
+	action := domain.LeaveActionDenyCancellation
+	if approve {
+		toStatus = domain.LeaveStatusCancelled
+		action = domain.LeaveActionApproveCancellation
+	}
+	leaveRequest.Status = toStatus
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         action,
+		Status:         toStatus,
+		Comments:       comments,
+		PerformedBy:    approverID,
+	}
+
+	if err := s.transitionLeaveRequest(leaveRequest, domain.LeaveStatusCancellationRequested, history); err != nil {
+		return nil, err
+	}
+
+	return leaveRequest, nil
+}
+
+// GetOrganizationSettings returns the organization's settings, or the
+// defaults when none were saved
+func (s *leaveService) GetOrganizationSettings(orgID uuid.UUID) (*domain.OrganizationSettings, error) {
+	settings, err := s.leaveRepo.GetOrganizationSettings(orgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultOrganizationSettings(orgID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateOrganizationSettings applies the given fields over the current settings
+func (s *leaveService) UpdateOrganizationSettings(orgID uuid.UUID, req *domain.UpdateOrganizationSettingsRequest) (*domain.OrganizationSettings, error) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FreeCancellationDays != nil {
+		if *req.FreeCancellationDays < 0 {
+			return nil, apperrors.NewBadRequestError("free_cancellation_days cannot be negative")
+		}
+		settings.FreeCancellationDays = *req.FreeCancellationDays
+	}
+	if req.BackdateWindowDays != nil {
+		if *req.BackdateWindowDays < 0 {
+			return nil, apperrors.NewBadRequestError("backdate_window_days cannot be negative")
+		}
+		settings.BackdateWindowDays = *req.BackdateWindowDays
+	}
+	if req.HoursPerDay != nil {
+		if *req.HoursPerDay <= 0 || *req.HoursPerDay > 24 {
+			return nil, apperrors.NewBadRequestError("hours_per_day must be more than 0 and at most 24")
+		}
+		settings.HoursPerDay = *req.HoursPerDay
+	}
+	if req.SandwichPolicy != nil {
+		settings.SandwichPolicy = *req.SandwichPolicy
+	}
+	if req.MaxConcurrentAbsences != nil {
+		if *req.MaxConcurrentAbsences < 0 {
+			return nil, apperrors.NewBadRequestError("max_concurrent_absences cannot be negative")
+		}
+		settings.MaxConcurrentAbsences = *req.MaxConcurrentAbsences
+	}
+	if req.ConcurrentAbsenceMode != nil {
+		switch *req.ConcurrentAbsenceMode {
+		case domain.AbsenceLimitModeBlock, domain.AbsenceLimitModeWarn:
+			settings.ConcurrentAbsenceMode = *req.ConcurrentAbsenceMode
+		default:
+			return nil, apperrors.NewBadRequestError("concurrent_absence_mode must be block or warn")
+		}
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, apperrors.NewBadRequestError("timezone must be an IANA time zone such as Europe/Berlin")
+		}
+		settings.Timezone = *req.Timezone
+	}
+	if req.AnomalyMaxMutationDays != nil {
+		if *req.AnomalyMaxMutationDays <= 0 {
+			return nil, apperrors.NewBadRequestError("anomaly_max_mutation_days must be more than 0")
+		}
+		settings.AnomalyMaxMutationDays = *req.AnomalyMaxMutationDays
+	}
+	if req.AnomalyMaxWritesPerMinute != nil {
+		if *req.AnomalyMaxWritesPerMinute < 1 {
+			return nil, apperrors.NewBadRequestError("anomaly_max_writes_per_minute must be at least 1")
+		}
+		settings.AnomalyMaxWritesPerMinute = *req.AnomalyMaxWritesPerMinute
+	}
+	if req.AnomalyHoldAdjustments != nil {
+		settings.AnomalyHoldAdjustments = *req.AnomalyHoldAdjustments
+	}
+	if req.MaxAdvanceBookingMonths != nil {
+		if *req.MaxAdvanceBookingMonths < 1 || *req.MaxAdvanceBookingMonths > 60 {
+			return nil, apperrors.NewBadRequestError("max_advance_booking_months must be between 1 and 60")
+		}
+		settings.MaxAdvanceBookingMonths = *req.MaxAdvanceBookingMonths
+	}
+	if req.ProrationBasis != nil {
+		switch *req.ProrationBasis {
+		case domain.ProrationBasisMonths, domain.ProrationBasisDays:
+			settings.ProrationBasis = *req.ProrationBasis
+		default:
+			return nil, apperrors.NewBadRequestError("proration_basis must be months or days")
+		}
+	}
+	if req.ProrationRounding != nil {
+		switch *req.ProrationRounding {
+		case domain.ProrationRoundingNearestHalf, domain.ProrationRoundingUp:
+			settings.ProrationRounding = *req.ProrationRounding
+		default:
+			return nil, apperrors.NewBadRequestError("proration_rounding must be nearest_half or up")
+		}
+	}
+	if req.UndoWindowMinutes != nil {
+		if *req.UndoWindowMinutes < 0 || *req.UndoWindowMinutes > 1440 {
+			return nil, apperrors.NewBadRequestError("undo_window_minutes must be between 0 and 1440")
+		}
+		settings.UndoWindowMinutes = *req.UndoWindowMinutes
+	}
+	if req.CompOffLeaveTypeID != nil {
+		if *req.CompOffLeaveTypeID == uuid.Nil {
+			settings.CompOffLeaveTypeID = nil
+		} else {
+			leaveType, err := s.GetLeaveType(orgID, *req.CompOffLeaveTypeID)
+			if err != nil {
+				return nil, err
+			}
+			if !leaveType.TracksBalance {
+				return nil, apperrors.NewBadRequestError(leaveType.Name + " does not track a balance and cannot hold comp-off")
+			}
+			settings.CompOffLeaveTypeID = &leaveType.ID
+		}
+	}
+	if req.CompOffExpiryDays != nil {
+		if *req.CompOffExpiryDays < 0 {
+			return nil, apperrors.NewBadRequestError("comp_off_expiry_days cannot be negative")
+		}
+		settings.CompOffExpiryDays = *req.CompOffExpiryDays
+	}
+	if req.OptionalHolidayQuota != nil {
+		if *req.OptionalHolidayQuota < 0 || *req.OptionalHolidayQuota > 366 {
+			return nil, apperrors.NewBadRequestError("optional_holiday_quota must be between 0 and 366")
+		}
+		settings.OptionalHolidayQuota = *req.OptionalHolidayQuota
+	}
+	if req.BradfordBands != nil {
+		if err := req.BradfordBands.Validate(); err != nil {
+			return nil, apperrors.NewBadRequestError("bradford_bands: " + err.Error())
+		}
+		settings.BradfordBands = req.BradfordBands
+	}
+	if req.AbsenceAlertDays != nil {
+		if *req.AbsenceAlertDays < 0 || *req.AbsenceAlertDays > 366 {
+			return nil, apperrors.NewBadRequestError("absence_alert_days must be between 0 and 366")
+		}
+		settings.AbsenceAlertDays = *req.AbsenceAlertDays
+	}
+	if req.AbsenceAlertSpells != nil {
+		if *req.AbsenceAlertSpells < 0 || *req.AbsenceAlertSpells > 366 {
+			return nil, apperrors.NewBadRequestError("absence_alert_spells must be between 0 and 366")
+		}
+		settings.AbsenceAlertSpells = *req.AbsenceAlertSpells
+	}
+	if req.AbsenceAlertWebhookURL != nil {
+		if *req.AbsenceAlertWebhookURL != "" {
+			u, err := url.Parse(*req.AbsenceAlertWebhookURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, apperrors.NewBadRequestError("absence_alert_webhook_url must be an http or https URL")
+			}
+		}
+		settings.AbsenceAlertWebhookURL = *req.AbsenceAlertWebhookURL
+	}
+	if req.ProbationMonths != nil {
+		if *req.ProbationMonths < 0 || *req.ProbationMonths > 24 {
+			return nil, apperrors.NewBadRequestError("probation_months must be between 0 and 24")
+		}
+		settings.ProbationMonths = *req.ProbationMonths
+	}
+	if req.DelegationConfirmDays != nil {
+		if *req.DelegationConfirmDays < 0 || *req.DelegationConfirmDays > 30 {
+			return nil, apperrors.NewBadRequestError("delegation_confirm_days must be between 0 and 30")
+		}
+		settings.DelegationConfirmDays = *req.DelegationConfirmDays
+	}
+	if req.DelegationWebhookURL != nil {
+		if *req.DelegationWebhookURL != "" {
+			u, err := url.Parse(*req.DelegationWebhookURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, apperrors.NewBadRequestError("delegation_webhook_url must be an http or https URL")
+			}
+		}
+		settings.DelegationWebhookURL = *req.DelegationWebhookURL
+	}
+
+	if err := s.leaveRepo.SaveOrganizationSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// DeleteLeaveRequest soft-deletes a rejected or cancelled request. Requests
+// that still hold days on a balance must be cancelled or rejected first.
+func (s *leaveService) DeleteLeaveRequest(orgID, id, deletedBy uuid.UUID) error {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if !leaveRequest.CanDelete() {
+		return apperrors.NewInvalidStatusError("only rejected or cancelled leave requests can be deleted; " +
+			leaveRequest.Status + " requests still count against the leave balance, cancel or reject them first")
+	}
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionDelete,
+		Status:         leaveRequest.Status,
+		PerformedBy:    deletedBy,
+	}
+
+	err = s.leaveRepo.SoftDeleteLeaveRequest(leaveRequest, history)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return apperrors.NewConflictError("leave request was modified by another request, please retry")
+	}
+	return err
+}
+
+// WithdrawLeaveRequest lets an employee take back their own request. With
+// undo, a request still pending, not voted on by any approver and
+// submitted within the organization's undo window is deleted outright,
+// releasing its pending days and leaving no history, and nil is returned.
+// Otherwise the request is cancelled as usual. Devices that synced an
+// undone request only drop it on their next full resync, as no tombstone
+// is kept.
+func (s *leaveService) WithdrawLeaveRequest(orgID, id, employeeID uuid.UUID, role string, undo bool) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if leaveRequest.EmployeeID != employeeID {
+		return nil, apperrors.NewNotFoundError("leave request not found")
+	}
+
+	if undo {
+		undone, err := s.undoLeaveRequest(leaveRequest)
+		if err != nil || undone {
+			return nil, err
+		}
+	}
+	return s.CancelLeaveRequest(orgID, id, employeeID, role, "")
+}
+
+// undoLeaveRequest deletes a request that can still be undone and reports
+// whether it did
+func (s *leaveService) undoLeaveRequest(leaveRequest *domain.LeaveRequest) (bool, error) {
+	settings, err := s.GetOrganizationSettings(leaveRequest.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	window := time.Duration(settings.UndoWindowMinutes) * time.Minute
+	if leaveRequest.Status != domain.LeaveStatusPending || len(leaveRequest.Approvals) > 0 ||
+		time.Since(leaveRequest.CreatedAt) > window {
+		return false, nil
+	}
+
+	err = s.leaveRepo.PurgeLeaveRequest(leaveRequest)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("Leave request %s undone by employee %s", leaveRequest.ID, leaveRequest.EmployeeID)
+	return true, nil
+}
+
+// PurgeOrganization deletes all of the organization's leave data without
+// a trace, for tearing down seeded environments. It is not served over
+// HTTP.
+func (s *leaveService) PurgeOrganization(orgID uuid.UUID) error {
+	if err := s.leaveRepo.PurgeOrganization(orgID); err != nil {
+		return err
+	}
+	log.Printf("Leave data of organization %s purged", orgID)
+	return nil
+}
+
+// RestoreLeaveRequest brings back a soft-deleted request
+func (s *leaveService) RestoreLeaveRequest(orgID, id, restoredBy uuid.UUID) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequestIncludingDeleted(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !leaveRequest.DeletedAt.Valid {
+		return nil, apperrors.NewInvalidStatusError("leave request is not deleted")
+	}
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionRestore,
+		Status:         leaveRequest.Status,
+		PerformedBy:    restoredBy,
+	}
+
+	err = s.leaveRepo.RestoreLeaveRequest(leaveRequest, history)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return nil, apperrors.NewInvalidStatusError("leave request is not deleted")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return leaveRequest, nil
+}
+
+// ReopenLeaveRequest puts a rejected or cancelled request back to pending so
+// a mistaken decision can be undone without the employee refiling. The
+// request must have been decided within the reopen window and must still
+// fit the balance and not overlap other active leave.
+func (s *leaveService) ReopenLeaveRequest(orgID, id, reopenedBy uuid.UUID, comments string) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.getOrgLeaveRequest(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !leaveRequest.CanReopen() {
+		return nil, apperrors.NewInvalidStatusError("only rejected or cancelled leave requests can be reopened")
+	}
+
+	decidedAt, err := s.decidedAt(leaveRequest)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(decidedAt) > domain.ReopenWindowDays*24*time.Hour {
+		return nil, apperrors.NewInvalidStatusError(fmt.Sprintf(
+			"leave requests can only be reopened within %d days of being %s", domain.ReopenWindowDays, leaveRequest.Status))
+	}
+
+	leaveType, err := s.GetLeaveType(orgID, leaveRequest.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOverlap(leaveRequest); err != nil {
+		return nil, err
+	}
+
+	// Route to the leave type's current approvers, who may have changed
+	// since the request was first submitted
+	leaveRequest.AssignedApproverID, err = s.approverResolver(leaveType).Assign(leaveRequest)
+	if errors.Is(err, repository.ErrEmptyApproverPool) {
+		return nil, apperrors.NewConflictError("the approver pool for this leave type has no members")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fromStatus := leaveRequest.Status
+	leaveRequest.Status = domain.LeaveStatusPending
+	leaveRequest.ApprovedBy = nil
+	leaveRequest.ApprovedAt = nil
+
+	history := &domain.LeaveRequestHistory{
+		LeaveRequestID: leaveRequest.ID,
+		Action:         domain.LeaveActionReopen,
+		Status:         domain.LeaveStatusPending,
+		Comments:       comments,
+		PerformedBy:    reopenedBy,
+	}
+
+	err = s.leaveRepo.ReopenLeaveRequest(leaveRequest, fromStatus, leaveType, history)
+	var insufficient *repository.InsufficientBalanceError
+	if errors.As(err, &insufficient) {
+		return nil, apperrors.NewInsufficientBalanceError(fmt.Sprintf(
+			"cannot reopen: insufficient leave balance: %.2f %s requested, %.2f available, short by %.2f",
+			insufficient.Requested, leaveType.Unit, insufficient.Available, insufficient.Requested-insufficient.Available))
+	}
+	if err != nil {
+		return nil, s.transitionError(err)
+	}
+
+	return leaveRequest, nil
+}
+
+// decidedAt returns when the request entered its current status, falling
+// back to its last update for requests without history
+func (s *leaveService) decidedAt(leaveRequest *domain.LeaveRequest) (time.Time, error) {
+	history, err := s.leaveRepo.ListLeaveRequestHistory(leaveRequest.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// History is newest first; deletes, restores and audit exports keep
+	// the status
+	for _, h := range history {
+		if h.Status == leaveRequest.Status && h.Action != domain.LeaveActionDelete &&
+			h.Action != domain.LeaveActionRestore && h.Action != domain.LeaveActionAuditExport {
+			return h.CreatedAt, nil
+		}
+	}
+	return leaveRequest.UpdatedAt, nil
+}
+
+// getOrgLeaveRequest loads a request and hides requests of other organizations
+// behind the same not-found error as missing ones
+func (s *leaveService) getOrgLeaveRequest(orgID, id uuid.UUID) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.leaveRepo.GetLeaveRequest(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFoundError("leave request not found")
+		}
+		return nil, err
+	}
+
+	if leaveRequest.OrganizationID != orgID {
+		return nil, apperrors.NewNotFoundError("leave request not found")
+	}
+
+	return leaveRequest, nil
+}
+
+func (s *leaveService) getOrgLeaveRequestIncludingDeleted(orgID, id uuid.UUID) (*domain.LeaveRequest, error) {
+	leaveRequest, err := s.leaveRepo.GetLeaveRequestIncludingDeleted(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewNotFoundError("leave request not found")
+		}
+		return nil, err
+	}
+
+	if leaveRequest.OrganizationID != orgID {
+		return nil, apperrors.NewNotFoundError("leave request not found")
+	}
+
+	return leaveRequest, nil
+}
+
+func (s *leaveService) transitionLeaveRequest(leaveRequest *domain.LeaveRequest, fromStatus string, history *domain.LeaveRequestHistory) error {
+	if err := s.leaveRepo.TransitionLeaveRequest(leaveRequest, fromStatus, history); err != nil {
+		return s.transitionError(err)
+	}
+	s.recordUsedDaysChange(leaveRequest, fromStatus)
+	s.syncDelegation(leaveRequest)
+	return nil
+}
+
+// recordUsedDaysChange reports a transition that took days from or gave
+// days back to a balance to the anomaly monitor
+func (s *leaveService) recordUsedDaysChange(leaveRequest *domain.LeaveRequest, fromStatus string) {
+	if leaveRequest.LeaveType != nil && !leaveRequest.LeaveType.TracksBalance {
+		return
+	}
+	switch {
+	case fromStatus == domain.LeaveStatusPending && leaveRequest.Status == domain.LeaveStatusApproved:
+		s.recordBalanceMutation(leaveRequest.OrganizationID, -leaveRequest.Days, domain.BalanceMutationLeaveRequest)
+	case (fromStatus == domain.LeaveStatusApproved || fromStatus == domain.LeaveStatusCancellationRequested) &&
+		leaveRequest.Status == domain.LeaveStatusCancelled:
+		s.recordBalanceMutation(leaveRequest.OrganizationID, leaveRequest.Days, domain.BalanceMutationLeaveRequest)
+	}
+}
+
+// transitionError maps repository errors from status transitions to API errors
+func (s *leaveService) transitionError(err error) error {
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return apperrors.NewConflictError("leave request was modified by another request, please retry")
+	}
+	var capExceeded *repository.NegativeBalanceCapError
+	if errors.As(err, &capExceeded) {
+		return negativeBalanceCapError(capExceeded, "")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewNotFoundError("leave balance not found for this leave request")
+	}
+	return err
+}
+
+// negativeBalanceCapError describes a booking that would overdraw a balance
+// past its leave type's cap. unit is left out of the message when empty.
+func negativeBalanceCapError(e *repository.NegativeBalanceCapError, unit string) *apperrors.AppError {
+	if unit != "" {
+		unit = " " + unit
+	}
+	return apperrors.NewNegativeBalanceCapError(fmt.Sprintf(
+		"the balance would go to %.2f%s, beyond the negative balance limit of %.2f%s for this leave type",
+		e.Remaining, unit, e.Cap, unit))
+}
+
+// Today returns the current date in the organization's time zone
+func (s *leaveService) Today(orgID uuid.UUID) (time.Time, error) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return settings.Today(), nil
+}
+
+// leavePeriod is the period of a new or edited request and who asks for it
+type leavePeriod struct {
+	EmployeeID   uuid.UUID
+	StartDate    time.Time
+	EndDate      time.Time
+	StartHalf    string
+	EndHalf      string
+	Hours        float64
+	StartTime    string
+	BypassNotice bool
+	// OverrideProbation lets leave of a type blocked during probation
+	// through
+	OverrideProbation bool
+	AuthToken         string
+	CallerRole        string
+	// RequestID is the request being edited, which its new period may
+	// overlap
+	RequestID uuid.UUID
+}
+
+// plannedLeave is a period that passed planLeave with its counted days.
+// endDate is the period's end, which hourly leave defaults to its start.
+type plannedLeave struct {
+	employee  *organization.EmployeeResponse
+	today     time.Time
+	backdated bool
+	endDate   time.Time
+	breakdown []domain.LeaveRequestDay
+	rawDays   float64
+	days      float64
+	// probationOverridden is set when the period falls within probation
+	// and OverrideProbation let it through
+	probationOverridden bool
+	// skipped are the checks the organization service being down skipped
+	skipped []domain.SkippedValidation
+}
+
+// planLeave applies the rules both new and edited requests obey: a period
+// valid for the leave type, no overlap with the employee's other active
+// leave, the employee's eligibility, tenure and probation, backdating, the advance
+// booking window and the notice period. It counts the days charged to the
+// balance, rounded per the leave type.
+func (s *leaveService) planLeave(orgID uuid.UUID, leaveType *domain.LeaveType, period *leavePeriod) (*plannedLeave, error) {
+	if leaveType.IsHourly() && period.EndDate.IsZero() {
+		period.EndDate = period.StartDate
+	}
+	if err := validateLeavePeriod(leaveType, period.StartDate, period.EndDate, period.StartHalf, period.EndHalf, period.Hours, period.StartTime); err != nil {
+		return nil, err
+	}
+	if err := s.checkOverlap(&domain.LeaveRequest{
+		Base:       domain.Base{ID: period.RequestID},
+		EmployeeID: period.EmployeeID,
+		StartDate:  period.StartDate,
+		EndDate:    period.EndDate,
+		StartHalf:  period.StartHalf,
+		EndHalf:    period.EndHalf,
+	}); err != nil {
+		return nil, err
+	}
+
+	employee, employeeSkipped, err := s.checkEmployee(period.AuthToken, orgID, period.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	var skipped []domain.SkippedValidation
+	if employeeSkipped != nil {
+		if skipped, err = s.skipEmployeeChecks(leaveType, period.EmployeeID, employeeSkipped); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkEligibility(leaveType, period.EmployeeID, employee); err != nil {
+		return nil, err
+	}
+	if err := s.checkTenure(leaveType, period.EmployeeID, employee, period.StartDate); err != nil {
+		return nil, err
+	}
+	probationOverridden, err := s.checkProbation(orgID, leaveType, period.EmployeeID, employee,
+		period.StartDate, period.EndDate, period.OverrideProbation)
+	if err != nil {
+		return nil, err
+	}
+
+	today, err := s.today(orgID, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	backdated, err := s.checkBackdating(orgID, leaveType, period.StartDate, period.CallerRole, today)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAdvanceBooking(orgID, period.StartDate, today); err != nil {
+		return nil, err
+	}
+
+	// Enforce the leave type's notice period in calendar days; it has no
+	// meaning for leave that already started
+	if !period.BypassNotice && !backdated {
+		earliest := leaveType.EarliestStartDate(today)
+		if domain.TruncateToDate(period.StartDate).Before(earliest) {
+			return nil, apperrors.NewBadRequestError(fmt.Sprintf(
+				"%s requires %d days notice; the earliest allowed start date is %s",
+				leaveType.Name, leaveType.MinDaysNotice, earliest.Format("2006-01-02")))
+		}
+	}
+
+	// Days charged to the balance, rounded per the leave type; the maximum
+	// is checked against the same number
+	breakdown, rawDays, days, err := s.leaveDays(orgID, period.EmployeeID, locationOf(employee), leaveType,
+		period.StartDate, period.EndDate, period.StartHalf, period.EndHalf, period.Hours)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plannedLeave{
+		employee:  employee,
+		today:     today,
+		backdated: backdated,
+		endDate:   period.EndDate,
+		breakdown: breakdown,
+		rawDays:   rawDays,
+		days:      days,
+
+		probationOverridden: probationOverridden,
+		skipped:             skipped,
+	}, nil
+}
+
+// checkOverlap refuses a request taking off the same half of any day as
+// another of the employee's active requests. A morning off and an
+// afternoon off on the same day do not overlap.
+func (s *leaveService) checkOverlap(request *domain.LeaveRequest) error {
+	overlapping, err := s.leaveRepo.GetOverlappingRequests(request.EmployeeID, request.StartDate, request.EndDate)
+	if err != nil {
+		return err
+	}
+	for _, other := range overlapping {
+		if other.ID != request.ID && request.Overlaps(&other) {
+			return apperrors.NewUnprocessableError(fmt.Sprintf(
+				"overlaps %s leave request %s from %s to %s", other.Status, other.ID,
+				other.StartDate.Format("2006-01-02"), other.EndDate.Format("2006-01-02")))
+		}
+	}
+	return nil
+}
+
+// checkBackdating reports whether the request starts before today and
+// whether that is allowed: always for leave types that allow backdating,
+// and within the organization's backdate window for managers and admins
+func (s *leaveService) checkBackdating(orgID uuid.UUID, leaveType *domain.LeaveType, startDate time.Time, callerRole string, today time.Time) (bool, error) {
+	start := domain.TruncateToDate(startDate)
+	if !start.Before(today) {
+		return false, nil
+	}
+	if leaveType.AllowBackdated {
+		return true, nil
+	}
+
+	if callerRole != domain.RoleAdmin && callerRole != domain.RoleManager {
+		return false, apperrors.NewBadRequestError(fmt.Sprintf(
+			"%s cannot start in the past; the earliest allowed start date is %s",
+			leaveType.Name, today.Format("2006-01-02")))
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return false, err
+	}
+	earliest := today.AddDate(0, 0, -settings.BackdateWindowDays)
+	if start.Before(earliest) {
+		return false, apperrors.NewBadRequestError(fmt.Sprintf(
+			"requests can be backdated at most %d days; the earliest allowed start date is %s",
+			settings.BackdateWindowDays, earliest.Format("2006-01-02")))
+	}
+	return true, nil
+}
+
+// checkAdvanceBooking refuses leave starting further ahead of today than
+// the organization's advance booking window, telling the employee from
+// which date the request can be filed
+func (s *leaveService) checkAdvanceBooking(orgID uuid.UUID, start, today time.Time) error {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return err
+	}
+	start = domain.TruncateToDate(start)
+	latest := today.AddDate(0, settings.MaxAdvanceBookingMonths, 0)
+	if !start.After(latest) {
+		return nil
+	}
+	return apperrors.NewBadRequestError(fmt.Sprintf(
+		"leave can be booked at most %d months ahead, up to %s; leave starting %s can be booked from %s",
+		settings.MaxAdvanceBookingMonths, latest.Format("2006-01-02"), start.Format("2006-01-02"),
+		start.AddDate(0, -settings.MaxAdvanceBookingMonths, 0).Format("2006-01-02")))
+}
+
+// leaveDays returns the employee's working days between start and end,
+// skipping weekends and the holidays they observe at their location, their
+// raw total less any half days, and the total after the leave type's
+// rounding. The rounded count must fit the leave type's maximum. Hourly
+// types are counted by leaveHours.
+func (s *leaveService) leaveDays(orgID, employeeID uuid.UUID, location string, leaveType *domain.LeaveType, start, end time.Time, startHalf, endHalf string, hours float64) ([]domain.LeaveRequestDay, float64, float64, error) {
+	if leaveType.IsHourly() {
+		return s.leaveHours(orgID, employeeID, location, start, hours)
+	}
+	if end.Sub(start) > domain.MaxLeaveSpanDays*24*time.Hour {
+		return nil, 0, 0, apperrors.NewBadRequestError(domain.ErrLeaveSpanTooLong.Error())
+	}
+
+	holidays, err := s.employeeHolidays(orgID, employeeID, location, domain.TruncateToDate(start), domain.TruncateToDate(end))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	breakdown, rawDays, days := countLeaveDays(settings, leaveType, start, end, startHalf, endHalf, holidays)
+	if days > float64(leaveType.MaxDaysPerRequest) {
+		return nil, 0, 0, apperrors.NewBadRequestError(fmt.Sprintf(
+			"request covers %.2f working days, more than the %d allowed for %s",
+			days, leaveType.MaxDaysPerRequest, leaveType.Name))
+	}
+	return breakdown, rawDays, days, nil
+}
+
+// countLeaveDays breaks leave of a daily type down into its working days
+// around holidays, sandwiching the days between them under the
+// organization's policy, and totals them raw and after the leave type's
+// rounding
+func countLeaveDays(settings *domain.OrganizationSettings, leaveType *domain.LeaveType, start, end time.Time, startHalf, endHalf string, holidays []domain.Holiday) ([]domain.LeaveRequestDay, float64, float64) {
+	breakdown := domain.LeaveDayBreakdown(start, end, startHalf, endHalf, holidays)
+	if settings.SandwichPolicy {
+		breakdown = domain.SandwichDays(breakdown)
+	}
+	var rawDays float64
+	for _, day := range breakdown {
+		rawDays += day.Fraction
+	}
+	return breakdown, rawDays, domain.RoundDays(rawDays, leaveType.Rounding)
+}
+
+// holidaysAt lists the holidays between from and to observed at location,
+// organization-wide ones included
+func (s *leaveService) holidaysAt(orgID uuid.UUID, location string, from, to time.Time) ([]domain.Holiday, error) {
+	holidays, err := s.leaveRepo.ListHolidays(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return domain.HolidaysAt(holidays, location), nil
+}
+
+// leaveHours returns the single working day of hourly leave on date, its
+// fraction being the share of the organization's working day it takes, and
+// the hours as both the raw and the charged amount
+func (s *leaveService) leaveHours(orgID, employeeID uuid.UUID, location string, date time.Time, hours float64) ([]domain.LeaveRequestDay, float64, float64, error) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if hours > settings.HoursPerDay {
+		return nil, 0, 0, apperrors.NewBadRequestError(fmt.Sprintf(
+			"hourly leave cannot exceed the %.2f hour working day", settings.HoursPerDay))
+	}
+
+	day := domain.TruncateToDate(date)
+	holidays, err := s.employeeHolidays(orgID, employeeID, location, day, day)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	breakdown := domain.LeaveDayBreakdown(day, day, "", "", holidays)
+	if len(breakdown) == 0 {
+		return nil, 0, 0, apperrors.NewBadRequestError(fmt.Sprintf("%s is not a working day", day.Format("2006-01-02")))
+	}
+	breakdown[0].Fraction = hours / settings.HoursPerDay
+	return breakdown, hours, hours, nil
+}
+
+// entitlement is the balance a first request of the leave type opens with
+func (s *leaveService) entitlement(orgID uuid.UUID, leaveType *domain.LeaveType) (float64, error) {
+	if !leaveType.IsHourly() {
+		return leaveType.Entitlement(0), nil
+	}
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return 0, err
+	}
+	return leaveType.Entitlement(settings.HoursPerDay), nil
+}
+
+// validateLeavePeriod checks the dates of a request and, depending on the
+// leave type's unit, its half-day markers or its hours
+func validateLeavePeriod(leaveType *domain.LeaveType, start, end time.Time, startHalf, endHalf string, hours float64, startTime string) error {
+	if end.IsZero() {
+		return apperrors.NewBadRequestError("end date is required")
+	}
+	if start.After(end) {
+		return apperrors.NewBadRequestError("start date cannot be after end date")
+	}
+	if leaveType.IsHourly() {
+		if err := domain.ValidateHourlyLeave(start, end, startHalf, endHalf, hours, startTime); err != nil {
+			return apperrors.NewBadRequestError(err.Error())
+		}
+		return nil
+	}
+	if hours != 0 || startTime != "" {
+		return apperrors.NewBadRequestError(domain.ErrHoursNotAllowed.Error())
+	}
+	if err := domain.ValidateHalfDays(start, end, startHalf, endHalf); err != nil {
+		return apperrors.NewBadRequestError(err.Error())
+	}
+	return nil
+}
+
+// AddWorkingDays computes the end and return-to-work dates for a run of
+// working days, skipping weekends and the holidays observed at the
+// location, the organization-wide ones only when it is empty. For an
+// employee their claimed optional holidays are skipped too, as when their
+// request is counted, and the days it would be charged include sandwiched
+// weekends and holidays under the organization's sandwich policy.
+func (s *leaveService) AddWorkingDays(orgID uuid.UUID, params *domain.AddWorkingDaysParams) (*domain.WorkingDaysResult, error) {
+	start := domain.TruncateToDate(params.Start)
+	to := start.AddDate(0, 0, domain.MaxLeaveSpanDays+7)
+
+	var holidays []domain.Holiday
+	var err error
+	if params.EmployeeID != nil {
+		location := params.Location
+		if location == "" {
+			location = s.employeeLocation(params.AuthToken, orgID, *params.EmployeeID)
+		}
+		holidays, err = s.employeeHolidays(orgID, *params.EmployeeID, location, start, to)
+	} else {
+		holidays, err = s.holidaysAt(orgID, params.Location, start, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := domain.AddWorkingDays(start, params.Days, holidays)
+	if errors.Is(err, domain.ErrInvalidDayFraction) || errors.Is(err, domain.ErrLeaveSpanTooLong) {
+		return nil, apperrors.NewBadRequestError(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	endHalf := ""
+	if result.EndsHalfDay {
+		endHalf = domain.HalfDayAM
+	}
+	_, result.ChargedDays, _ = countLeaveDays(settings, &domain.LeaveType{Rounding: domain.RoundingNone},
+		result.StartDate, result.EndDate, "", endHalf, holidays)
+	return result, nil
+}
+
+// GetCalendarDefinition returns the organization's working weekdays and
+// holidays for a leave year, as observed at location, the
+// organization-wide holidays only when it is empty
+func (s *leaveService) GetCalendarDefinition(orgID uuid.UUID, year int, location string) (*domain.CalendarDefinition, error) {
+	if year < 1900 || year > 9999 {
+		return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+	}
+
+	holidays, err := s.holidaysAt(orgID, location,
+		time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewCalendarDefinition(orgID, year, location, holidays), nil
+}
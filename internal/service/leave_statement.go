@@ -0,0 +1,132 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// LeaveStatement gathers an employee's leave position for the year: their
+// balances with the opening the yearly reset snapshotted, the requests
+// starting in the year and every adjustment to the year's balances, headed
+// by the organization's name
+func (s *leaveService) LeaveStatement(orgID, employeeID uuid.UUID, year int, token string) (*domain.LeaveStatement, error) {
+	if year < 1900 || year > 9999 {
+		return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+	}
+
+	employee, err := s.verifyEmployee(token, orgID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &domain.LeaveStatement{
+		OrganizationID:   orgID,
+		OrganizationName: s.organizationName(token, orgID),
+		EmployeeID:       employeeID,
+		Year:             year,
+		GeneratedAt:      time.Now().UTC(),
+		Balances:         []domain.LeaveStatementBalance{},
+		Requests:         []domain.LeaveStatementRequest{},
+		Adjustments:      []domain.LeaveStatementAdjustment{},
+	}
+	if employee != nil {
+		statement.EmployeeEmail = employee.Email
+	}
+
+	balances, err := s.ListEmployeeBalances(orgID, employeeID, year, false)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.leaveRepo.ListOpeningBalanceSnapshots(orgID, employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+	openings := make(map[uuid.UUID]*domain.LeaveStatementOpening, len(snapshots))
+	for _, snapshot := range snapshots {
+		openings[snapshot.LeaveTypeID] = &domain.LeaveStatementOpening{
+			RunID:           snapshot.RunID,
+			SnapshotAt:      snapshot.CreatedAt,
+			TotalDays:       snapshot.TotalDays,
+			UsedDays:        snapshot.UsedDays,
+			PendingDays:     snapshot.PendingDays,
+			RemainingDays:   snapshot.RemainingDays,
+			CarriedOverDays: snapshot.CarriedOverDays,
+		}
+	}
+	ids := make([]uuid.UUID, len(balances))
+	index := make(map[uuid.UUID]int, len(balances))
+	for i, b := range balances {
+		ids[i] = b.ID
+		index[b.ID] = i
+		statement.Balances = append(statement.Balances, domain.LeaveStatementBalance{
+			LeaveTypeID:   b.LeaveTypeID,
+			LeaveType:     b.LeaveType,
+			Unit:          b.Unit,
+			Opening:       openings[b.LeaveTypeID],
+			TotalDays:     b.TotalDays,
+			UsedDays:      b.UsedDays,
+			PendingDays:   b.PendingDays,
+			RemainingDays: b.RemainingDays,
+		})
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	requests, err := s.leaveRepo.ListEmployeeLeaveRequestsStarting(orgID, employeeID, start, start.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range requests {
+		entry := domain.LeaveStatementRequest{
+			ID:        r.ID,
+			StartDate: r.StartDate,
+			EndDate:   r.EndDate,
+			Days:      r.Days,
+			Status:    r.Status,
+		}
+		if r.LeaveType != nil {
+			entry.LeaveType = r.LeaveType.Name
+			entry.Unit = r.LeaveType.Unit
+		}
+		statement.Requests = append(statement.Requests, entry)
+	}
+
+	adjustments, err := s.leaveRepo.ListBalancesAdjustments(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range adjustments {
+		balance := balances[index[a.LeaveBalanceID]]
+		statement.Adjustments = append(statement.Adjustments, domain.LeaveStatementAdjustment{
+			ID:         a.ID,
+			LeaveType:  balance.LeaveType,
+			Unit:       balance.Unit,
+			Date:       a.CreatedAt,
+			Adjustment: a.Adjustment,
+			Reason:     a.Reason,
+			Status:     a.Status,
+		})
+	}
+
+	return statement, nil
+}
+
+// organizationName looks up the organization's name, falling back to its
+// id when the organization service cannot tell
+func (s *leaveService) organizationName(token string, orgID uuid.UUID) string {
+	if s.employees == nil {
+		return orgID.String()
+	}
+	org, err := s.employees.GetOrganization(token, orgID.String())
+	if err != nil {
+		log.Printf("Warning: cannot look up the name of organization %s, using its id: %v", orgID, err)
+		return orgID.String()
+	}
+	if org.Name == "" {
+		return orgID.String()
+	}
+	return org.Name
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// employeeHolidays lists the holidays between from and to the employee
+// observes: those of their location, less the optional holidays they did
+// not claim
+func (s *leaveService) employeeHolidays(orgID, employeeID uuid.UUID, location string, from, to time.Time) ([]domain.Holiday, error) {
+	holidays, err := s.holidaysAt(orgID, location, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	optional := false
+	for _, h := range holidays {
+		if h.Type == domain.HolidayTypeOptional {
+			optional = true
+			break
+		}
+	}
+	if !optional {
+		return holidays, nil
+	}
+
+	claims, err := s.leaveRepo.ListOptionalHolidayClaims(orgID, &employeeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	claimed := make(map[uuid.UUID]bool, len(claims))
+	for _, claim := range claims {
+		claimed[claim.HolidayID] = true
+	}
+	return domain.ClaimedOptionalHolidays(holidays, claimed), nil
+}
+
+// ClaimOptionalHoliday elects an optional holiday for the employee, who
+// then gets it off without it being charged to any balance. Claims count
+// against the organization's yearly quota, cannot overlap the employee's
+// active leave, and only admins may claim a holiday that has passed.
+func (s *leaveService) ClaimOptionalHoliday(orgID, employeeID, holidayID, claimedBy uuid.UUID, callerRole, token string) (*domain.OptionalHolidayClaim, error) {
+	holiday, err := s.GetHoliday(orgID, holidayID)
+	if err != nil {
+		return nil, err
+	}
+	if holiday.Type != domain.HolidayTypeOptional {
+		return nil, apperrors.NewUnprocessableError(fmt.Sprintf(
+			"%s is a %s holiday, only optional holidays are claimed", holiday.Name, holiday.Type))
+	}
+	if holiday.Location != nil && !holiday.AppliesTo(s.employeeLocation(token, orgID, employeeID)) {
+		return nil, apperrors.NewUnprocessableError(fmt.Sprintf(
+			"%s is only observed in %s", holiday.Name, *holiday.Location))
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	date := domain.TruncateToDate(holiday.Date)
+	if date.Before(settings.Today()) && callerRole != domain.RoleAdmin {
+		return nil, apperrors.NewForbiddenError("only admins can claim optional holidays that have passed")
+	}
+
+	overlapping, err := s.leaveRepo.GetOverlappingRequests(employeeID, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(overlapping) > 0 {
+		other := overlapping[0]
+		return nil, apperrors.NewConflictError(fmt.Sprintf(
+			"%s leave request %s already covers %s", other.Status, other.ID, date.Format("2006-01-02")))
+	}
+
+	claim := &domain.OptionalHolidayClaim{
+		OrganizationID: orgID,
+		EmployeeID:     employeeID,
+		HolidayID:      holiday.ID,
+		ClaimedBy:      claimedBy,
+	}
+	err = s.leaveRepo.CreateOptionalHolidayClaim(claim, date.Year(), settings.OptionalHolidayQuota)
+	switch {
+	case errors.Is(err, repository.ErrOptionalHolidayQuotaReached):
+		return nil, apperrors.NewUnprocessableError(fmt.Sprintf(
+			"the employee already claimed the %d optional holidays allowed in %d", settings.OptionalHolidayQuota, date.Year()))
+	case errors.Is(err, repository.ErrDuplicateOptionalHolidayClaim):
+		return nil, apperrors.NewConflictError(holiday.Name + " is already claimed")
+	case err != nil:
+		return nil, err
+	}
+	claim.Holiday = holiday
+	return claim, nil
+}
+
+// UnclaimOptionalHoliday withdraws the employee's claim of an optional
+// holiday. Only admins may withdraw it on or after the holiday.
+func (s *leaveService) UnclaimOptionalHoliday(orgID, employeeID, holidayID uuid.UUID, callerRole string) error {
+	claim, err := s.leaveRepo.GetOptionalHolidayClaim(employeeID, holidayID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && claim.OrganizationID != orgID) {
+		return apperrors.NewNotFoundError("optional holiday claim not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	today, err := s.Today(orgID)
+	if err != nil {
+		return err
+	}
+	if claim.Holiday != nil && !domain.TruncateToDate(claim.Holiday.Date).After(today) && callerRole != domain.RoleAdmin {
+		return apperrors.NewForbiddenError("optional holidays can only be unclaimed before their date")
+	}
+	return s.leaveRepo.DeleteOptionalHolidayClaim(claim.ID)
+}
+
+// ListOptionalHolidayClaims returns the employee's optional holiday claims
+// of a year, the current one unless set, against the quota, with the
+// optional holidays still open to them
+func (s *leaveService) ListOptionalHolidayClaims(orgID, employeeID uuid.UUID, year int, token string) (*domain.OptionalHolidayClaims, error) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if year == 0 {
+		year = settings.Today().Year()
+	}
+	if year < 1900 || year > 9999 {
+		return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	claims, err := s.leaveRepo.ListOptionalHolidayClaims(orgID, &employeeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.holidaysAt(orgID, s.employeeLocation(token, orgID, employeeID), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[uuid.UUID]bool, len(claims))
+	for _, claim := range claims {
+		claimed[claim.HolidayID] = true
+	}
+	result := &domain.OptionalHolidayClaims{
+		EmployeeID: employeeID,
+		Year:       year,
+		Quota:      settings.OptionalHolidayQuota,
+		Claimed:    len(claims),
+		Claims:     claims,
+		Available:  []domain.Holiday{},
+	}
+	if result.Claims == nil {
+		result.Claims = []domain.OptionalHolidayClaim{}
+	}
+	if result.Quota > 0 {
+		remaining := result.Quota - result.Claimed
+		if remaining < 0 {
+			remaining = 0
+		}
+		result.Remaining = &remaining
+	}
+	for _, h := range holidays {
+		if h.Type == domain.HolidayTypeOptional && !claimed[h.ID] {
+			result.Available = append(result.Available, h)
+		}
+	}
+	return result, nil
+}
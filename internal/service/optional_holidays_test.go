@@ -0,0 +1,200 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// optionalHolidays seeds next year's calendar of orgID with three optional
+// holidays, one of them only observed in the north, a public holiday and an
+// optional holiday that has passed, in that order
+func optionalHolidays(repo *fakeRepository, orgID uuid.UUID) []domain.Holiday {
+	year := time.Now().Year() + 1
+	seeded := []domain.Holiday{
+		{Name: "Deepavali", Date: date(year, time.January, 12), Type: domain.HolidayTypeOptional},
+		{Name: "Good Friday", Date: date(year, time.February, 9), Type: domain.HolidayTypeOptional},
+		{Name: "Thai Pongal", Date: date(year, time.March, 9), Type: domain.HolidayTypeOptional, Location: domain.HolidayLocation("LK-N")},
+		{Name: "Vesak", Date: date(year, time.April, 13), Type: domain.HolidayTypePublic},
+		{Name: "Ramazan", Date: date(year-2, time.April, 10), Type: domain.HolidayTypeOptional},
+	}
+	for i := range seeded {
+		seeded[i].ID = uuid.New()
+		seeded[i].OrganizationID = orgID
+	}
+	repo.holidays = seeded
+	return seeded
+}
+
+func TestClaimOptionalHoliday(t *testing.T) {
+	tests := []struct {
+		name       string
+		holiday    int
+		role       string
+		claimed    []int
+		onLeave    bool
+		wantStatus int
+	}{
+		{name: "claimed", holiday: 0},
+		{name: "within the quota", holiday: 0, claimed: []int{1}},
+		{name: "past the quota", holiday: 0, claimed: []int{1, 2}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "claimed before", holiday: 0, claimed: []int{0}, wantStatus: http.StatusConflict},
+		{name: "not optional", holiday: 3, wantStatus: http.StatusUnprocessableEntity},
+		{name: "observed elsewhere", holiday: 2, wantStatus: http.StatusUnprocessableEntity},
+		{name: "passed", holiday: 4, wantStatus: http.StatusForbidden},
+		{name: "passed, by an admin", holiday: 4, role: domain.RoleAdmin},
+		{name: "on leave that day", holiday: 0, onLeave: true, wantStatus: http.StatusConflict},
+		{name: "unknown", holiday: -1, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			holidays := optionalHolidays(repo, orgID)
+			for _, i := range tt.claimed {
+				repo.claims = append(repo.claims, domain.OptionalHolidayClaim{
+					Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, EmployeeID: employeeID, HolidayID: holidays[i].ID,
+				})
+			}
+			if tt.onLeave {
+				leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+				repo.addDayRequest(leaveType, employeeID, holidays[tt.holiday].Date, "", "", domain.LeaveStatusPending)
+			}
+			svc, _ := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{Location: "LK-S"})
+
+			holidayID := uuid.New()
+			if tt.holiday >= 0 {
+				holidayID = holidays[tt.holiday].ID
+			}
+			claim, err := svc.ClaimOptionalHoliday(orgID, employeeID, holidayID, employeeID, tt.role, "token")
+			if httpStatus(err) != tt.wantStatus {
+				t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if len(repo.claims) != len(tt.claimed) {
+					t.Errorf("a refused claim was saved: %+v", repo.claims)
+				}
+				return
+			}
+			if claim.EmployeeID != employeeID || claim.HolidayID != holidayID || claim.ClaimedBy != employeeID ||
+				claim.Holiday == nil || claim.Holiday.ID != holidayID {
+				t.Errorf("claim = %+v", claim)
+			}
+			if len(repo.claims) != len(tt.claimed)+1 {
+				t.Errorf("saved %d claims, want %d", len(repo.claims), len(tt.claimed)+1)
+			}
+		})
+	}
+}
+
+func TestUnclaimOptionalHoliday(t *testing.T) {
+	tests := []struct {
+		name       string
+		holiday    int
+		role       string
+		otherOrg   bool
+		wantStatus int
+	}{
+		{name: "before the holiday", holiday: 0},
+		{name: "after the holiday", holiday: 4, wantStatus: http.StatusForbidden},
+		{name: "after the holiday, by an admin", holiday: 4, role: domain.RoleAdmin},
+		{name: "another organization's", holiday: 0, otherOrg: true, wantStatus: http.StatusNotFound},
+		{name: "not claimed", holiday: 1, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			holidays := optionalHolidays(repo, orgID)
+			claimOrg := orgID
+			if tt.otherOrg {
+				claimOrg = uuid.New()
+			}
+			for _, i := range []int{0, 4} {
+				repo.claims = append(repo.claims, domain.OptionalHolidayClaim{
+					Base: domain.Base{ID: uuid.New()}, OrganizationID: claimOrg, EmployeeID: employeeID, HolidayID: holidays[i].ID,
+				})
+			}
+
+			err := newTestService(repo).UnclaimOptionalHoliday(orgID, employeeID, holidays[tt.holiday].ID, tt.role)
+			if httpStatus(err) != tt.wantStatus {
+				t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+			}
+			wantKept := 2
+			if tt.wantStatus == 0 {
+				wantKept = 1
+			}
+			if len(repo.claims) != wantKept {
+				t.Fatalf("%d claims left, want %d", len(repo.claims), wantKept)
+			}
+			if tt.wantStatus == 0 && repo.claims[0].HolidayID == holidays[tt.holiday].ID {
+				t.Error("the withdrawn claim was kept")
+			}
+		})
+	}
+}
+
+func TestListOptionalHolidayClaims(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	holidays := optionalHolidays(repo, orgID)
+	repo.claims = []domain.OptionalHolidayClaim{{OrganizationID: orgID, EmployeeID: employeeID, HolidayID: holidays[1].ID}}
+	svc, _ := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{Location: "LK-S"})
+
+	year := holidays[0].Date.Year()
+	result, err := svc.ListOptionalHolidayClaims(orgID, employeeID, year, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Year != year || result.Quota != domain.DefaultOptionalHolidayQuota || result.Claimed != 1 ||
+		result.Remaining == nil || *result.Remaining != 1 || len(result.Claims) != 1 {
+		t.Errorf("claims = %+v", result)
+	}
+	// The public holiday, the claimed one and the northern one are not open
+	if len(result.Available) != 1 || result.Available[0].ID != holidays[0].ID {
+		t.Errorf("available = %+v, want %s only", result.Available, holidays[0].Name)
+	}
+
+	repo.settings.OptionalHolidayQuota = 0
+	if result, err = svc.ListOptionalHolidayClaims(orgID, employeeID, year, "token"); err != nil || result.Remaining != nil {
+		t.Errorf("without a quota remaining = %v (%v), want unset", result.Remaining, err)
+	}
+	if _, err := svc.ListOptionalHolidayClaims(orgID, employeeID, 10000, "token"); httpStatus(err) != http.StatusBadRequest {
+		t.Errorf("year 10000: got %v, want a 400", err)
+	}
+}
+
+func TestCreateLeaveRequestCountsUnclaimedOptionalHolidays(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	monday := upcomingMonday()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	repo.holidays = []domain.Holiday{
+		{Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, Name: "Deepavali", Date: monday.AddDate(0, 0, 1), Type: domain.HolidayTypeOptional},
+		{Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, Name: "Good Friday", Date: monday.AddDate(0, 0, 4), Type: domain.HolidayTypeOptional},
+	}
+	// Only Friday was claimed, so Tuesday is a working day
+	repo.claims = []domain.OptionalHolidayClaim{{OrganizationID: orgID, EmployeeID: employeeID, HolidayID: repo.holidays[1].ID}}
+
+	_, err := newTestService(repo).CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday.AddDate(0, 0, 4),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.created.Days != 4 {
+		t.Errorf("days = %v, want the unclaimed holiday charged", repo.created.Days)
+	}
+}
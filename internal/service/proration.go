@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// InitializeEmployeeBalances opens a new hire's balances of the current
+// leave year, or of the hire year when they join later, for every paid
+// leave type that tracks a balance and that they are eligible for. Each
+// type's DefaultDays are pro-rated by what is left of the year from the
+// hire date and rounded per the organization's settings; accruing types
+// open empty. Balances that already exist are returned unchanged, so
+// calling it again creates nothing.
+func (s *leaveService) InitializeEmployeeBalances(orgID, employeeID uuid.UUID, req *domain.InitializeBalancesRequest) (*domain.BalanceInitialization, error) {
+	employee, err := s.verifyEmployee(req.AuthToken, orgID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var hireDate time.Time
+	switch {
+	case req.HireDate != nil:
+		hireDate = domain.TruncateToDate(*req.HireDate)
+	case employee != nil && employee.StartDate != "":
+		if hireDate, err = employeeStartDate(employee); err != nil {
+			return nil, apperrors.NewUnprocessableError(fmt.Sprintf(
+				"the employee's start date %q is invalid, pass hire_date instead", employee.StartDate))
+		}
+	default:
+		return nil, apperrors.NewBadRequestError("hire_date is required when the employee's start date cannot be looked up")
+	}
+
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	year := settings.Today().Year()
+	if hireDate.Year() > year {
+		year = hireDate.Year()
+	}
+
+	leaveTypes, err := s.leaveRepo.ListEntitlementLeaveTypes(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.BalanceInitialization{
+		EmployeeID: employeeID,
+		HireDate:   hireDate,
+		Year:       year,
+		Rounding:   settings.ProrationRounding,
+		Proration:  domain.ProrateYear(hireDate, year, settings.ProrationBasis),
+		Balances:   []domain.ProratedBalance{},
+	}
+
+	var balances []*domain.LeaveBalance
+	for i := range leaveTypes {
+		leaveType := &leaveTypes[i]
+		if employee != nil && leaveType.Eligibility.FailedCondition(employeeAttributes(employee)) != nil {
+			continue
+		}
+
+		prorated := domain.ProratedBalance{
+			LeaveTypeID: leaveType.ID,
+			LeaveType:   leaveType.Name,
+			Unit:        leaveType.Unit,
+		}
+		total := 0.0
+		if leaveType.Accrues() {
+			prorated.Calculation = fmt.Sprintf("accrues %.2f %s %s from the hire date on",
+				leaveType.AccrualRate, leaveType.Unit, leaveType.AccrualFrequency)
+		} else {
+			prorated.DefaultDays = float64(leaveType.DefaultDays)
+			prorated.ProratedDays = prorated.DefaultDays * result.Proration.Fraction
+			prorated.RoundedDays = domain.RoundProratedDays(prorated.ProratedDays, settings.ProrationRounding)
+			prorated.Describe(result.Proration, settings.ProrationRounding)
+			total = prorated.RoundedDays
+			if leaveType.IsHourly() {
+				total *= settings.HoursPerDay
+			}
+		}
+
+		result.Balances = append(result.Balances, prorated)
+		balances = append(balances, &domain.LeaveBalance{
+			OrganizationID: orgID,
+			EmployeeID:     employeeID,
+			LeaveTypeID:    leaveType.ID,
+			Year:           year,
+			TotalDays:      total,
+			OpeningDays:    &total,
+		})
+	}
+
+	created, err := s.leaveRepo.CreateMissingLeaveBalances(balances)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Balances {
+		result.Balances[i].Created = created[i]
+		result.Balances[i].Balance = balances[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/holidays"
+	"github.com/google/uuid"
+)
+
+// ImportPublicHolidays adds a country's public holidays of the year, as
+// listed by the holiday provider, to the organization calendar as public
+// holidays, organization-wide or for one location. Dates that already have
+// a holiday for the same employees are skipped, whatever its name, as are
+// the provider's regional holidays, whose regions need not match the
+// organization's locations. A preview returns what would be created and
+// saves nothing.
+func (s *leaveService) ImportPublicHolidays(orgID uuid.UUID, country string, year int, location string, preview bool) (*domain.PublicHolidayImport, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 {
+		return nil, apperrors.NewBadRequestError("country must be a two-letter ISO 3166-1 code")
+	}
+	if year < 1900 || year > 9999 {
+		return nil, apperrors.NewBadRequestError("year must be between 1900 and 9999")
+	}
+	if s.holidayProvider == nil {
+		return nil, apperrors.NewServiceUnavailableError("holiday provider not configured")
+	}
+
+	fetched, err := s.holidayProvider.PublicHolidays(country, year)
+	var providerErr *holidays.ProviderError
+	switch {
+	case errors.Is(err, holidays.ErrNotConfigured):
+		return nil, apperrors.NewServiceUnavailableError("holiday provider not configured")
+	case errors.As(err, &providerErr):
+		log.Printf("Warning: holiday provider failed for %s %d: %v", country, year, err)
+		return nil, apperrors.NewBadGatewayError(providerErr.Error())
+	case err != nil:
+		return nil, apperrors.NewBadGatewayError("holiday provider failed: " + err.Error())
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	existing, err := s.leaveRepo.ListHolidays(orgID, from, from.AddDate(1, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	target := &domain.Holiday{Location: domain.HolidayLocation(location)}
+	taken := make(map[string]string, len(existing)+len(fetched))
+	for i := range existing {
+		if existing[i].Overlaps(target) {
+			taken[existing[i].Date.Format("2006-01-02")] = existing[i].Name
+		}
+	}
+
+	result := &domain.PublicHolidayImport{
+		Country:  country,
+		Year:     year,
+		Location: target.LocationName(),
+		Preview:  preview,
+		Created:  []domain.Holiday{},
+		Skipped:  []domain.SkippedHoliday{},
+	}
+	for _, public := range fetched {
+		name := strings.TrimSpace(public.Name)
+		if name == "" {
+			name = strings.TrimSpace(public.LocalName)
+		}
+		if runes := []rune(name); len(runes) > 100 {
+			name = string(runes[:100])
+		}
+
+		date, err := time.Parse("2006-01-02", public.Date)
+		if err != nil {
+			log.Printf("Warning: holiday provider listed %q with invalid date %q", name, public.Date)
+			continue
+		}
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, domain.SkippedHoliday{Name: name, Date: date, Reason: reason})
+		}
+
+		key := public.Date
+		if other, ok := taken[key]; ok {
+			if strings.EqualFold(other, name) {
+				skip("already in the calendar")
+			} else {
+				skip(fmt.Sprintf("%s already falls on this date", other))
+			}
+			continue
+		}
+		if !public.Global {
+			skip("observed only in some regions")
+			continue
+		}
+
+		holiday := domain.Holiday{
+			OrganizationID: orgID,
+			Name:           name,
+			Date:           date,
+			Type:           domain.HolidayTypePublic,
+			Location:       target.Location,
+		}
+		if preview {
+			err = validateHoliday(&holiday)
+		} else {
+			err = s.CreateHoliday(&holiday, nil)
+		}
+		if err != nil {
+			if msg := importErrorMessage(err); msg != "" {
+				skip(msg)
+				continue
+			}
+			return nil, err
+		}
+		taken[key] = name
+		result.Created = append(result.Created, holiday)
+	}
+
+	if !preview {
+		log.Printf("Imported %d public holidays of %s %d into %s, %d skipped",
+			len(result.Created), country, year, orgID, len(result.Skipped))
+	}
+	return result, nil
+}
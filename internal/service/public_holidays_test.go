@@ -0,0 +1,126 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/pkg/holidays"
+	"github.com/google/uuid"
+)
+
+// holidayProvider serves Sri Lanka's 2026 holidays the way Nager.Date
+// lists them: two national ones and a regional one
+func holidayProvider(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/PublicHolidays/2026/LK" {
+			http.Error(w, `{"title":"Country not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[
+			{"date":"2026-02-04","localName":"Independence Day","name":"Independence Day","global":true},
+			{"date":"2026-04-14","localName":"Puthandu","name":"Tamil New Year","global":false},
+			{"date":"2026-05-01","localName":"May Day","name":"Labour Day","global":true}
+		]`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestImportPublicHolidays(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	// May Day is already in the calendar under another name
+	repo.holidays = []domain.Holiday{{OrganizationID: orgID, Name: "Workers' Day", Date: date(2026, time.May, 1), Type: domain.HolidayTypeCompany}}
+	svc := NewLeaveService(repo, nil, false, holidays.NewClient(holidayProvider(t).URL))
+
+	result, err := svc.ImportPublicHolidays(orgID, " lk ", 2026, "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Country != "LK" || result.Preview {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Created) != 1 || result.Created[0].Name != "Independence Day" ||
+		!result.Created[0].Date.Equal(date(2026, time.February, 4)) || result.Created[0].Type != domain.HolidayTypePublic {
+		t.Fatalf("created %+v, want Independence Day only", result.Created)
+	}
+
+	reasons := map[string]string{}
+	for _, skipped := range result.Skipped {
+		reasons[skipped.Name] = skipped.Reason
+	}
+	if reasons["Tamil New Year"] != "observed only in some regions" {
+		t.Errorf("regional holiday skipped with %q", reasons["Tamil New Year"])
+	}
+	if reasons["Labour Day"] != "Workers' Day already falls on this date" {
+		t.Errorf("conflicting holiday skipped with %q", reasons["Labour Day"])
+	}
+	if len(repo.holidays) != 2 {
+		t.Errorf("calendar has %d holidays, want the existing one and the import", len(repo.holidays))
+	}
+
+	// Importing again finds everything in place
+	again, err := svc.ImportPublicHolidays(orgID, "LK", 2026, "", false)
+	if err != nil {
+		t.Fatalf("reimporting: %v", err)
+	}
+	if len(again.Created) != 0 || again.Skipped[0].Reason != "already in the calendar" {
+		t.Errorf("reimport = %+v", again)
+	}
+}
+
+func TestImportPublicHolidaysPreviewSavesNothing(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	svc := NewLeaveService(repo, nil, false, holidays.NewClient(holidayProvider(t).URL))
+
+	result, err := svc.ImportPublicHolidays(orgID, "LK", 2026, "Colombo", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Preview || result.Location != "Colombo" || len(result.Created) != 2 {
+		t.Errorf("preview = %+v, want the two national holidays for Colombo", result)
+	}
+	if len(repo.holidays) != 0 {
+		t.Errorf("a preview saved %d holidays", len(repo.holidays))
+	}
+}
+
+func TestImportPublicHolidaysFailures(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	tests := []struct {
+		name     string
+		provider holidays.Provider
+		country  string
+		year     int
+		want     int
+	}{
+		{name: "provider failing", provider: holidays.NewClient(down.URL), country: "LK", year: 2026, want: 502},
+		{name: "unknown country", provider: holidays.NewClient(holidayProvider(t).URL), country: "XX", year: 2026, want: 502},
+		{name: "provider not configured", provider: holidays.NewClient(""), country: "LK", year: 2026, want: 503},
+		{name: "no provider", country: "LK", year: 2026, want: 503},
+		{name: "invalid country", provider: holidays.NewClient(down.URL), country: "LKA", year: 2026, want: 400},
+		{name: "invalid year", provider: holidays.NewClient(down.URL), country: "LK", year: 20260, want: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			_, err := NewLeaveService(repo, nil, false, tt.provider).ImportPublicHolidays(uuid.New(), tt.country, tt.year, "", false)
+			if status := httpStatus(err); status != tt.want {
+				t.Errorf("got %v (%d), want %d", err, status, tt.want)
+			}
+			if len(repo.holidays) != 0 {
+				t.Error("holidays were saved")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// RecalculateBalances compares the organization's balances of a year with
+// what their source records add up to and reports every discrepancy. With
+// Apply, each one is fixed in its own transaction and documented by a
+// correction adjustment by the system user; a balance that cannot be fixed
+// is reported with its error and left for a rerun. Balances opened before
+// opening entitlements were recorded are taken to have opened with their
+// leave type's current entitlement.
+func (s *leaveService) RecalculateBalances(orgID uuid.UUID, params domain.RecalculateBalancesParams) (*domain.BalanceRecalculation, error) {
+	return s.recalculateBalances(orgID, params, nil)
+}
+
+// StartBalanceRecalculation queues a job recalculating the organization's
+// balances and applying the fixes, for the year resolved now. An
+// organization runs one recalculation at a time.
+func (s *leaveService) StartBalanceRecalculation(orgID, requestedBy uuid.UUID, params domain.RecalculateBalancesParams) (*domain.Job, error) {
+	if params.Year == 0 {
+		today, err := s.Today(orgID)
+		if err != nil {
+			return nil, err
+		}
+		params.Year = today.Year()
+	} else if params.Year < 1900 || params.Year > 9999 {
+		return nil, apperrors.NewBadRequestError("invalid year")
+	}
+	params.Apply = true
+	return s.enqueueJob(orgID, requestedBy, domain.JobTypeBalanceRecalculation, params, 0)
+}
+
+// runBalanceRecalculation runs a balance recalculation job
+func (s *leaveService) runBalanceRecalculation(job *domain.Job, progress jobProgress) (interface{}, error) {
+	var params domain.RecalculateBalancesParams
+	if err := json.Unmarshal(job.Payload, &params); err != nil {
+		return nil, err
+	}
+	return s.recalculateBalances(job.OrganizationID, params, progress)
+}
+
+// recalculateBalances does the work of RecalculateBalances, reporting each
+// balance checked to progress when set
+func (s *leaveService) recalculateBalances(orgID uuid.UUID, params domain.RecalculateBalancesParams, progress jobProgress) (*domain.BalanceRecalculation, error) {
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Year == 0 {
+		params.Year = settings.Today().Year()
+	} else if params.Year < 1900 || params.Year > 9999 {
+		return nil, apperrors.NewBadRequestError("invalid year")
+	}
+
+	balances, err := s.leaveRepo.ListRecalculationBalances(orgID, params.EmployeeID, params.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.BalanceRecalculation{
+		Year:          params.Year,
+		Applied:       params.Apply,
+		Checked:       len(balances),
+		Discrepancies: []domain.BalanceDiscrepancy{},
+	}
+	for i := range balances {
+		if progress != nil {
+			if err := progress(i, len(balances)); err != nil {
+				return nil, err
+			}
+		}
+		balance := &balances[i]
+		fallbackOpening := balance.LeaveType.Entitlement(settings.HoursPerDay)
+
+		expected, err := s.leaveRepo.ExpectedBalanceFigures(balance, fallbackOpening)
+		if err != nil {
+			return nil, err
+		}
+		stored := balance.StoredFigures()
+		if stored.Matches(expected) {
+			continue
+		}
+
+		discrepancy := domain.BalanceDiscrepancy{
+			LeaveBalanceID: balance.ID,
+			EmployeeID:     balance.EmployeeID,
+			LeaveTypeID:    balance.LeaveTypeID,
+			Year:           balance.Year,
+			Stored:         stored,
+			Expected:       expected,
+			Changes:        stored.Describe(expected),
+		}
+		if params.Apply {
+			// The balance may have moved since it was read, so the fix is
+			// recalculated under its lock
+			corrected, err := s.leaveRepo.CorrectLeaveBalance(balance.ID, fallbackOpening)
+			switch {
+			case err != nil:
+				log.Printf("Error: cannot correct balance %s: %v", balance.ID, err)
+				discrepancy.Error = err.Error()
+			case corrected == nil:
+				continue
+			default:
+				discrepancy = *corrected
+			}
+		}
+		result.Discrepancies = append(result.Discrepancies, discrepancy)
+	}
+	if progress != nil {
+		if err := progress(len(balances), len(balances)); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.Apply {
+		log.Printf("Recalculated %d balances of %s for %d: %d discrepancies",
+			result.Checked, orgID, params.Year, len(result.Discrepancies))
+	}
+	return result, nil
+}
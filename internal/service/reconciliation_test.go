@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// driftedBalances seeds three balances of 2026: one that adds up, one whose
+// used days drifted and one that drifted but was fixed since it was read
+func driftedBalances(repo *fakeRepository, orgID uuid.UUID) (leaveType *domain.LeaveType, right, drifted, fixed uuid.UUID) {
+	leaveType = repo.addLeaveType(orgID, domain.RoundingNone)
+	repo.expected = make(map[uuid.UUID]domain.BalanceFigures)
+	repo.corrections = make(map[uuid.UUID]*domain.BalanceDiscrepancy)
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for _, id := range ids {
+		repo.balances = append(repo.balances, domain.LeaveBalance{
+			Base: domain.Base{ID: id}, OrganizationID: orgID, EmployeeID: uuid.New(), LeaveTypeID: leaveType.ID,
+			Year: 2026, TotalDays: 20, UsedDays: 3,
+		})
+		repo.expected[id] = domain.BalanceFigures{TotalDays: 20, UsedDays: 5}
+	}
+	repo.expected[ids[0]] = domain.BalanceFigures{TotalDays: 20, UsedDays: 3}
+	repo.corrections[ids[1]] = &domain.BalanceDiscrepancy{LeaveBalanceID: ids[1], Changes: "used 3.00 -> 5.00", Applied: true}
+	return leaveType, ids[0], ids[1], ids[2]
+}
+
+func TestRecalculateBalancesReportsOnly(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	_, _, drifted, fixed := driftedBalances(repo, orgID)
+
+	result, err := newTestService(repo).RecalculateBalances(orgID, domain.RecalculateBalancesParams{Year: 2026})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Applied || result.Checked != 3 || len(result.Discrepancies) != 2 {
+		t.Fatalf("result = %+v, want 2 of 3 balances reported", result)
+	}
+	for i, id := range []uuid.UUID{drifted, fixed} {
+		d := result.Discrepancies[i]
+		if d.LeaveBalanceID != id || d.Applied || d.Changes != "used 3.00 -> 5.00" {
+			t.Errorf("discrepancy = %+v, want %s reported as used 3.00 -> 5.00", d, id)
+		}
+	}
+	if len(repo.correctedOpening) != 0 {
+		t.Error("a balance was corrected without apply")
+	}
+}
+
+func TestRecalculateBalancesApplies(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	leaveType, right, drifted, fixed := driftedBalances(repo, orgID)
+
+	result, err := newTestService(repo).RecalculateBalances(orgID, domain.RecalculateBalancesParams{Year: 2026, Apply: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The balance fixed in the meantime is no longer a discrepancy
+	if !result.Applied || len(result.Discrepancies) != 1 || result.Discrepancies[0].LeaveBalanceID != drifted ||
+		!result.Discrepancies[0].Applied {
+		t.Fatalf("result = %+v, want only %s corrected", result, drifted)
+	}
+	if _, ok := repo.correctedOpening[right]; ok {
+		t.Error("a balance that adds up was corrected")
+	}
+	// Balances without a recorded opening fall back to the entitlement
+	for _, id := range []uuid.UUID{drifted, fixed} {
+		if opening, ok := repo.correctedOpening[id]; !ok || opening != float64(leaveType.DefaultDays) {
+			t.Errorf("balance %s corrected with opening %v (%v), want %d", id, opening, ok, leaveType.DefaultDays)
+		}
+	}
+}
+
+func TestRecalculateBalancesKeepsFailures(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	driftedBalances(repo, orgID)
+	repo.correctErr = errors.New("deadlock detected")
+
+	result, err := newTestService(repo).RecalculateBalances(orgID, domain.RecalculateBalancesParams{Year: 2026, Apply: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Discrepancies) != 2 {
+		t.Fatalf("reported %d discrepancies, want both failed ones", len(result.Discrepancies))
+	}
+	for _, d := range result.Discrepancies {
+		if d.Applied || d.Error != "deadlock detected" {
+			t.Errorf("discrepancy = %+v, want it reported unapplied with its error", d)
+		}
+	}
+}
+
+func TestRecalculateBalancesValidation(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	if _, err := newTestService(repo).RecalculateBalances(orgID, domain.RecalculateBalancesParams{Year: 10000}); httpStatus(err) != http.StatusBadRequest {
+		t.Errorf("got %d (%v), want 400", httpStatus(err), err)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/google/uuid"
+)
+
+// maxReportRange bounds report periods
+const maxReportRange = 366 * 24 * time.Hour
+
+// LeaveSummary counts the requests starting in [from, to) and totals their
+// approved days, split into paid and unpaid leave
+func (s *leaveService) LeaveSummary(orgID uuid.UUID, from, to time.Time) (*domain.LeaveStats, error) {
+	if !to.After(from) {
+		return nil, apperrors.NewBadRequestError("end_date must be after start_date")
+	}
+	if to.Sub(from) > maxReportRange {
+		return nil, apperrors.NewBadRequestError("report range cannot exceed 366 days")
+	}
+	return s.leaveRepo.GetLeaveStats(orgID, from, to)
+}
+
+// HolidayImpactReport breaks down the holidays within [from, to) per
+// location: how many each location observes and the working days they
+// take out of the period
+func (s *leaveService) HolidayImpactReport(orgID uuid.UUID, from, to time.Time) (*domain.HolidayImpactReport, error) {
+	if !to.After(from) {
+		return nil, apperrors.NewBadRequestError("end_date must be after start_date")
+	}
+	if to.Sub(from) > maxReportRange {
+		return nil, apperrors.NewBadRequestError("report range cannot exceed 366 days")
+	}
+
+	last := domain.TruncateToDate(to).AddDate(0, 0, -1)
+	holidays, err := s.leaveRepo.ListHolidays(orgID, domain.TruncateToDate(from), last)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.HolidayImpactReport{
+		StartDate: from,
+		EndDate:   to,
+		Locations: domain.BuildHolidayImpact(from, last, holidays),
+	}, nil
+}
+
+// MonthlyTrendsReport totals the approved leave of the last months
+// calendar months, the current one included, optionally of one leave type
+func (s *leaveService) MonthlyTrendsReport(orgID uuid.UUID, months int, leaveTypeID *uuid.UUID) (*domain.MonthlyTrendsReport, error) {
+	if months < 1 || months > domain.MaxTrendMonths {
+		return nil, apperrors.NewBadRequestError(fmt.Sprintf("months must be between 1 and %d", domain.MaxTrendMonths))
+	}
+	if leaveTypeID != nil {
+		if _, err := s.GetLeaveType(orgID, *leaveTypeID); err != nil {
+			return nil, err
+		}
+	}
+
+	today, err := s.Today(orgID)
+	if err != nil {
+		return nil, err
+	}
+	first := time.Date(today.Year(), today.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+	stats, err := s.leaveRepo.ListMonthlyLeave(orgID, leaveTypeID, first, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.MonthlyTrendsReport{Months: months, LeaveTypeID: leaveTypeID}
+	report.MonthlyStats, report.Trend = domain.BuildMonthlyTrends(first, months, stats)
+	return report, nil
+}
+
+// ApproverPerformanceReport aggregates approve and reject decisions made in
+// [from, to) per approver. Decision time runs from request creation to the
+// decision, counted in business hours. Backdated requests can be left out
+// since they are typically decided after the leave has started.
+func (s *leaveService) ApproverPerformanceReport(orgID uuid.UUID, from, to time.Time, excludeBackdated bool) (*domain.ApproverPerformanceReport, error) {
+	if !to.After(from) {
+		return nil, apperrors.NewBadRequestError("end_date must be after start_date")
+	}
+	if to.Sub(from) > maxReportRange {
+		return nil, apperrors.NewBadRequestError("report range cannot exceed 366 days")
+	}
+
+	decisions, err := s.leaveRepo.ListApproverDecisions(orgID, from, to, excludeBackdated)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	overdue, err := s.leaveRepo.CountOverduePendingByApprover(orgID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests decided in the period may have been submitted before it.
+	// Approvers are not tied to a location, so only organization-wide
+	// holidays pause the clock.
+	holidayFrom := from
+	for _, d := range decisions {
+		if d.RequestedAt.Before(holidayFrom) {
+			holidayFrom = d.RequestedAt
+		}
+	}
+	holidays, err := s.holidaysAt(orgID, "", domain.TruncateToDate(holidayFrom), domain.TruncateToDate(to))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[uuid.UUID]*domain.ApproverPerformance)
+	hours := make(map[uuid.UUID][]float64)
+	entry := func(id uuid.UUID) *domain.ApproverPerformance {
+		if stats[id] == nil {
+			stats[id] = &domain.ApproverPerformance{ApproverID: id}
+		}
+		return stats[id]
+	}
+
+	for _, d := range decisions {
+		p := entry(d.ApproverID)
+		p.Decided++
+		if d.Action == domain.LeaveActionApprove {
+			p.Approved++
+		} else {
+			p.Rejected++
+		}
+		if d.DecidedAt.After(d.StartDate) {
+			p.Overdue++
+		}
+		hours[d.ApproverID] = append(hours[d.ApproverID], domain.BusinessHoursBetween(d.RequestedAt, d.DecidedAt, holidays))
+	}
+	for id, n := range overdue {
+		entry(id).Overdue += n
+	}
+
+	report := &domain.ApproverPerformanceReport{
+		StartDate: from,
+		EndDate:   to,
+		Approvers: make([]domain.ApproverPerformance, 0, len(stats)),
+	}
+	for id, p := range stats {
+		if p.Decided > 0 {
+			p.ApprovalRate = float64(p.Approved) / float64(p.Decided)
+		}
+		p.MedianDecisionHours = percentile(hours[id], 50)
+		p.P90DecisionHours = percentile(hours[id], 90)
+		report.Approvers = append(report.Approvers, *p)
+	}
+	sort.Slice(report.Approvers, func(i, j int) bool {
+		a, b := report.Approvers[i], report.Approvers[j]
+		if a.Decided != b.Decided {
+			return a.Decided > b.Decided
+		}
+		return a.ApproverID.String() < b.ApproverID.String()
+	})
+
+	return report, nil
+}
+
+// percentile returns the p-th percentile of values using linear
+// interpolation between closest ranks, or 0 for no values
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
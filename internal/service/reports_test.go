@@ -0,0 +1,242 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "no values", values: nil, p: 50, want: 0},
+		{name: "one value", values: []float64{7}, p: 90, want: 7},
+		{name: "odd median", values: []float64{4, 1, 2}, p: 50, want: 2},
+		{name: "even median interpolates", values: []float64{1, 2, 3, 4}, p: 50, want: 2.5},
+		{name: "p90 interpolates", values: []float64{1, 2, 4}, p: 90, want: 3.6},
+		{name: "p100 is the maximum", values: []float64{3, 9, 1}, p: 100, want: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.values, tt.p); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApproverPerformanceReport(t *testing.T) {
+	// 2026-03-02 is a Monday
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	busy := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	quiet := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	idle := uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+
+	repo := newFakeRepository()
+	repo.decisions = []domain.ApproverDecision{
+		{ApproverID: busy, Action: domain.LeaveActionApprove, RequestedAt: at(2, 9), DecidedAt: at(2, 10), StartDate: at(20, 0)},
+		{ApproverID: busy, Action: domain.LeaveActionReject, RequestedAt: at(2, 9), DecidedAt: at(2, 11), StartDate: at(20, 0)},
+		// Decided after the leave started
+		{ApproverID: busy, Action: domain.LeaveActionApprove, RequestedAt: at(2, 9), DecidedAt: at(2, 13), StartDate: at(2, 0)},
+		// Friday afternoon to Monday morning is two business hours
+		{ApproverID: quiet, Action: domain.LeaveActionApprove, RequestedAt: at(6, 16), DecidedAt: at(9, 10), StartDate: at(20, 0)},
+	}
+	repo.overdue = map[uuid.UUID]int{quiet: 2, idle: 1}
+
+	report, err := newTestService(repo).ApproverPerformanceReport(uuid.New(), at(1, 0), at(31, 0), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.ApproverPerformance{
+		{ApproverID: busy, Decided: 3, Approved: 2, Rejected: 1, ApprovalRate: 2.0 / 3, MedianDecisionHours: 2, P90DecisionHours: 3.6, Overdue: 1},
+		{ApproverID: quiet, Decided: 1, Approved: 1, ApprovalRate: 1, MedianDecisionHours: 2, P90DecisionHours: 2, Overdue: 2},
+		{ApproverID: idle, Overdue: 1},
+	}
+	if len(report.Approvers) != len(want) {
+		t.Fatalf("got %d approvers, want %d: %+v", len(report.Approvers), len(want), report.Approvers)
+	}
+	for i, w := range want {
+		got := report.Approvers[i]
+		if got.ApproverID != w.ApproverID || got.Decided != w.Decided || got.Approved != w.Approved ||
+			got.Rejected != w.Rejected || got.Overdue != w.Overdue ||
+			math.Abs(got.ApprovalRate-w.ApprovalRate) > 1e-9 ||
+			math.Abs(got.MedianDecisionHours-w.MedianDecisionHours) > 1e-9 ||
+			math.Abs(got.P90DecisionHours-w.P90DecisionHours) > 1e-9 {
+			t.Errorf("approver %d: got %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestApproverPerformanceReportRange(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		to   time.Time
+	}{
+		{name: "end equals start", to: from},
+		{name: "end before start", to: from.AddDate(0, 0, -1)},
+		{name: "longer than a year", to: from.AddDate(1, 0, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTestService(newFakeRepository()).ApproverPerformanceReport(uuid.New(), from, tt.to, false); err == nil {
+				t.Error("expected a bad request error")
+			}
+		})
+	}
+}
+
+func TestLeaveSummaryRange(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		to   time.Time
+	}{
+		{name: "empty", to: from},
+		{name: "reversed", to: from.AddDate(0, 0, -1)},
+		{name: "longer than a year", to: from.AddDate(1, 0, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestService(newFakeRepository()).LeaveSummary(uuid.New(), from, tt.to)
+			if status := httpStatus(err); status != 400 {
+				t.Errorf("got %v (%d), want a 400", err, status)
+			}
+		})
+	}
+}
+
+// addApprovedLeave files approved leave of leaveType over [start, end],
+// charging its working days
+func (f *fakeRepository) addApprovedLeave(leaveType *domain.LeaveType, start, end time.Time) *domain.LeaveRequest {
+	request := &domain.LeaveRequest{
+		Base:           domain.Base{ID: uuid.New()},
+		OrganizationID: leaveType.OrganizationID,
+		EmployeeID:     uuid.New(),
+		LeaveTypeID:    leaveType.ID,
+		StartDate:      start,
+		EndDate:        end,
+		Status:         domain.LeaveStatusApproved,
+		Breakdown:      domain.LeaveDayBreakdown(start, end, "", "", nil),
+	}
+	request.RawDays = domain.CalculateLeaveDays(start, end, "", "", nil)
+	request.Days = request.RawDays
+	f.requests[request.ID] = request
+	return request
+}
+
+// monthStart is the first day of the month months after today's
+func monthStart(today time.Time, months int) time.Time {
+	return time.Date(today.Year(), today.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMonthlyTrendsReport(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	annual := repo.addLeaveType(orgID, domain.RoundingNone)
+	sick := repo.addLeaveType(orgID, domain.RoundingNone)
+	today := repo.settings.Today()
+
+	// Four months back to now: leave spanning the turn of the first two,
+	// nothing in the third and a day of leave in the current month
+	second := monthStart(today, -2)
+	spanning := repo.addApprovedLeave(annual, second.AddDate(0, 0, -7), second.AddDate(0, 0, 6))
+	var before, after float64
+	for _, day := range spanning.Breakdown {
+		if day.Date.Before(second) {
+			before += day.Fraction
+		} else {
+			after += day.Fraction
+		}
+	}
+	current := monthStart(today, 0)
+	for current.Weekday() == time.Saturday || current.Weekday() == time.Sunday {
+		current = current.AddDate(0, 0, 1)
+	}
+	repo.addApprovedLeave(sick, current, current)
+	// Neither leave outside the window nor pending leave counts
+	repo.addApprovedLeave(annual, monthStart(today, -5), monthStart(today, -5).AddDate(0, 0, 3))
+	repo.addDayRequest(annual, uuid.New(), monthStart(today, -1).AddDate(0, 0, 14), "", "", domain.LeaveStatusPending)
+
+	report, err := newTestService(repo).MonthlyTrendsReport(orgID, 4, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.MonthlyStats{
+		{Month: monthStart(today, -3), Count: 1, TotalDays: before},
+		{Month: second, Count: 1, TotalDays: after},
+		{Month: monthStart(today, -1)},
+		{Month: monthStart(today, 0), Count: 1, TotalDays: 1},
+	}
+	if len(report.MonthlyStats) != len(want) {
+		t.Fatalf("got %d months, want %d: %+v", len(report.MonthlyStats), len(want), report.MonthlyStats)
+	}
+	for i, m := range report.MonthlyStats {
+		if !m.Month.Equal(want[i].Month) || m.Count != want[i].Count || math.Abs(m.TotalDays-want[i].TotalDays) >= 0.005 {
+			t.Errorf("month %d = %+v, want %+v", i, m, want[i])
+		}
+	}
+	if before+after != spanning.Days {
+		t.Errorf("the spanning leave split into %v and %v, want %v in all", before, after, spanning.Days)
+	}
+
+	// A week on either side of the turn holds five working days each
+	trends := []string{domain.TrendStable, domain.TrendStable, domain.TrendDecreasing, domain.TrendIncreasing}
+	for i, point := range report.Trend {
+		if point.Trend != trends[i] || point.Value != report.MonthlyStats[i].TotalDays {
+			t.Errorf("trend %d = %+v, want %s", i, point, trends[i])
+		}
+	}
+
+	// One leave type keeps only its months
+	report, err = newTestService(repo).MonthlyTrendsReport(orgID, 4, &sick.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, m := range report.MonthlyStats {
+		if (i == 3) != (m.TotalDays == 1) {
+			t.Errorf("sick leave month %d = %+v", i, m)
+		}
+	}
+}
+
+func TestMonthlyTrendsReportValidation(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = domain.DefaultOrganizationSettings(orgID)
+	unknown := uuid.New()
+
+	for _, tt := range []struct {
+		name        string
+		months      int
+		leaveTypeID *uuid.UUID
+		want        int
+	}{
+		{name: "no months", months: 0, want: 400},
+		{name: "too many months", months: domain.MaxTrendMonths + 1, want: 400},
+		{name: "unknown leave type", months: 12, leaveTypeID: &unknown, want: 404},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestService(repo).MonthlyTrendsReport(orgID, tt.months, tt.leaveTypeID)
+			if got := httpStatus(err); got != tt.want {
+				t.Errorf("got %d (%v), want %d", got, err, tt.want)
+			}
+		})
+	}
+}
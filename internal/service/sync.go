@@ -0,0 +1,93 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+// SyncEmployee returns everything that changed for the employee since the
+// given token, plus the token to send next time. A missing, malformed or
+// expired token yields a full resync.
+func (s *leaveService) SyncEmployee(orgID, employeeID uuid.UUID, role, token string) (*domain.SyncResponse, error) {
+	now := time.Now()
+	resp := &domain.SyncResponse{
+		LeaveRequests: []domain.SyncLeaveRequest{},
+		Balances:      []domain.LeaveBalance{},
+		Holidays:      []domain.Holiday{},
+		Tombstones:    []domain.SyncTombstone{},
+	}
+
+	next := domain.NewSyncToken(now)
+	if current, err := domain.DecodeSyncToken(token, now); err == nil {
+		next.LeaveRequests = current.LeaveRequests
+		next.Balances = current.Balances
+		next.Holidays = current.Holidays
+	} else {
+		resp.FullResync = true
+	}
+
+	changes := func(wm domain.SyncWatermark, employee *uuid.UUID) *domain.ChangesParams {
+		return &domain.ChangesParams{
+			Since:      wm.Since,
+			SinceID:    wm.SinceID,
+			Limit:      domain.SyncPageSize,
+			EmployeeID: employee,
+		}
+	}
+
+	requests, err := s.leaveRepo.ListLeaveRequestChanges(orgID, changes(next.LeaveRequests, &employeeID))
+	if err != nil {
+		return nil, err
+	}
+	for i := range requests {
+		r := &requests[i]
+		if r.DeletedAt.Valid {
+			// A fresh client never saw the row, so there is nothing to drop
+			if !resp.FullResync {
+				resp.Tombstones = append(resp.Tombstones, domain.SyncTombstone{
+					Entity:    domain.SyncEntityLeaveRequest,
+					ID:        r.ID,
+					DeletedAt: r.DeletedAt.Time,
+				})
+			}
+		} else {
+			resp.LeaveRequests = append(resp.LeaveRequests, domain.NewSyncLeaveRequest(r))
+		}
+		next.LeaveRequests = domain.SyncWatermark{Since: r.UpdatedAt, SinceID: r.ID}
+	}
+
+	balances, err := s.leaveRepo.ListLeaveBalanceChanges(orgID, changes(next.Balances, &employeeID))
+	if err != nil {
+		return nil, err
+	}
+	resp.Balances = append(resp.Balances, balances...)
+	if n := len(balances); n > 0 {
+		next.Balances = domain.SyncWatermark{Since: balances[n-1].UpdatedAt, SinceID: balances[n-1].ID}
+	}
+
+	holidays, err := s.leaveRepo.ListHolidayChanges(orgID, changes(next.Holidays, nil))
+	if err != nil {
+		return nil, err
+	}
+	resp.Holidays = append(resp.Holidays, holidays...)
+	if n := len(holidays); n > 0 {
+		next.Holidays = domain.SyncWatermark{Since: holidays[n-1].UpdatedAt, SinceID: holidays[n-1].ID}
+	}
+
+	resp.HasMore = len(requests) == domain.SyncPageSize ||
+		len(balances) == domain.SyncPageSize ||
+		len(holidays) == domain.SyncPageSize
+
+	approvals, err := s.ListPendingApprovals(orgID, employeeID, role)
+	if err != nil {
+		return nil, err
+	}
+	for i := range approvals {
+		resp.PendingApprovals = append(resp.PendingApprovals, domain.NewSyncLeaveRequest(&approvals[i]))
+	}
+
+	resp.Token = next.Encode()
+	return resp, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// changedRequest is a request of employeeID last updated at
+func changedRequest(employeeID uuid.UUID, at time.Time, status string) domain.LeaveRequest {
+	return domain.LeaveRequest{
+		Base:       domain.Base{ID: uuid.New(), UpdatedAt: at},
+		EmployeeID: employeeID,
+		Status:     status,
+	}
+}
+
+func TestSyncEmployeeFullThenDelta(t *testing.T) {
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	base := time.Now().Add(-time.Hour)
+
+	repo := newFakeRepository()
+	repo.changedRequests = []domain.LeaveRequest{
+		changedRequest(employeeID, base, domain.LeaveStatusApproved),
+		changedRequest(uuid.New(), base.Add(time.Minute), domain.LeaveStatusPending),
+	}
+	deleted := changedRequest(employeeID, base.Add(2*time.Minute), domain.LeaveStatusPending)
+	deleted.DeletedAt = gorm.DeletedAt{Time: deleted.UpdatedAt, Valid: true}
+	repo.changedRequests = append(repo.changedRequests, deleted)
+	repo.changedBalances = []domain.LeaveBalance{{Base: domain.Base{ID: uuid.New(), UpdatedAt: base}, EmployeeID: employeeID}}
+	repo.holidays = []domain.Holiday{{Base: domain.Base{ID: uuid.New(), UpdatedAt: base}}}
+	svc := newTestService(repo)
+
+	full, err := svc.SyncEmployee(orgID, employeeID, "employee", "")
+	if err != nil {
+		t.Fatalf("full sync: %v", err)
+	}
+	if !full.FullResync || full.HasMore {
+		t.Errorf("full sync: full_resync %v has_more %v, want true and false", full.FullResync, full.HasMore)
+	}
+	if len(full.LeaveRequests) != 1 || full.LeaveRequests[0].ID != repo.changedRequests[0].ID {
+		t.Errorf("full sync: got requests %+v, want only the employee's live one", full.LeaveRequests)
+	}
+	if len(full.Tombstones) != 0 {
+		t.Errorf("full sync: got tombstones %+v, want none", full.Tombstones)
+	}
+	if len(full.Balances) != 1 || len(full.Holidays) != 1 {
+		t.Errorf("full sync: got %d balances and %d holidays, want 1 each", len(full.Balances), len(full.Holidays))
+	}
+	if full.PendingApprovals != nil {
+		t.Errorf("full sync: got pending approvals %+v for an employee", full.PendingApprovals)
+	}
+
+	// Nothing changed, so the token brings back nothing
+	idle, err := svc.SyncEmployee(orgID, employeeID, "employee", full.Token)
+	if err != nil {
+		t.Fatalf("idle sync: %v", err)
+	}
+	if idle.FullResync || len(idle.LeaveRequests) != 0 || len(idle.Balances) != 0 || len(idle.Holidays) != 0 {
+		t.Errorf("idle sync: got %+v, want an empty delta", idle)
+	}
+
+	// A later deletion comes back as a tombstone
+	removed := repo.changedRequests[0]
+	removed.UpdatedAt = base.Add(3 * time.Minute)
+	removed.DeletedAt = gorm.DeletedAt{Time: removed.UpdatedAt, Valid: true}
+	repo.changedRequests = append(repo.changedRequests, removed)
+
+	delta, err := svc.SyncEmployee(orgID, employeeID, "employee", idle.Token)
+	if err != nil {
+		t.Fatalf("delta sync: %v", err)
+	}
+	if delta.FullResync || len(delta.LeaveRequests) != 0 {
+		t.Errorf("delta sync: got full_resync %v and requests %+v", delta.FullResync, delta.LeaveRequests)
+	}
+	if len(delta.Tombstones) != 1 || delta.Tombstones[0].ID != removed.ID ||
+		delta.Tombstones[0].Entity != domain.SyncEntityLeaveRequest {
+		t.Errorf("delta sync: got tombstones %+v, want one for %s", delta.Tombstones, removed.ID)
+	}
+}
+
+func TestSyncEmployeeInvalidTokenResyncs(t *testing.T) {
+	repo := newFakeRepository()
+	resp, err := newTestService(repo).SyncEmployee(uuid.New(), uuid.New(), "employee", "garbage")
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !resp.FullResync || resp.Token == "" {
+		t.Errorf("got full_resync %v token %q, want a full resync with a new token", resp.FullResync, resp.Token)
+	}
+}
+
+func TestSyncEmployeePages(t *testing.T) {
+	employeeID := uuid.New()
+	base := time.Now().Add(-time.Hour)
+	repo := newFakeRepository()
+	for i := 0; i <= domain.SyncPageSize; i++ {
+		repo.changedRequests = append(repo.changedRequests,
+			changedRequest(employeeID, base.Add(time.Duration(i)*time.Second), domain.LeaveStatusApproved))
+	}
+	svc := newTestService(repo)
+
+	first, err := svc.SyncEmployee(uuid.New(), employeeID, "employee", "")
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if !first.HasMore || len(first.LeaveRequests) != domain.SyncPageSize {
+		t.Fatalf("first page: got has_more %v with %d requests, want true with %d",
+			first.HasMore, len(first.LeaveRequests), domain.SyncPageSize)
+	}
+
+	second, err := svc.SyncEmployee(uuid.New(), employeeID, "employee", first.Token)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if second.HasMore || len(second.LeaveRequests) != 1 ||
+		second.LeaveRequests[0].ID != repo.changedRequests[domain.SyncPageSize].ID {
+		t.Errorf("second page: got has_more %v with %d requests, want the last one only",
+			second.HasMore, len(second.LeaveRequests))
+	}
+}
+
+func TestSyncEmployeePendingApprovals(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	request := repo.addPendingRequest(leaveType, uuid.New())
+
+	resp, err := newTestService(repo).SyncEmployee(orgID, uuid.New(), domain.RoleManager, "")
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(resp.PendingApprovals) != 1 || resp.PendingApprovals[0].ID != request.ID {
+		t.Errorf("got pending approvals %+v, want %s", resp.PendingApprovals, request.ID)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// validationSeverities is what each check of a leave request does when the
+// organization service cannot be reached, including while its client's
+// circuit is open. The employee check is authorization-critical and blocks
+// unless employeeCheckFailOpen; the other checks are advisory and degrade.
+func validationSeverities(employeeCheckFailOpen bool) map[string]string {
+	employee := domain.ValidationSeverityBlock
+	if employeeCheckFailOpen {
+		employee = domain.ValidationSeverityDegrade
+	}
+	return map[string]string{
+		domain.ValidationCheckEmployee:     employee,
+		domain.ValidationCheckEligibility:  domain.ValidationSeverityDegrade,
+		domain.ValidationCheckTenure:       domain.ValidationSeverityDegrade,
+		domain.ValidationCheckProbation:    domain.ValidationSeverityDegrade,
+		domain.ValidationCheckAbsenceLimit: domain.ValidationSeverityDegrade,
+	}
+}
+
+// validationUnavailable is how the 503 of each blocking check ends
+var validationUnavailable = map[string]string{
+	domain.ValidationCheckEmployee:     "cannot verify employee",
+	domain.ValidationCheckEligibility:  "cannot check eligibility",
+	domain.ValidationCheckTenure:       "cannot check tenure",
+	domain.ValidationCheckProbation:    "cannot check probation",
+	domain.ValidationCheckAbsenceLimit: "cannot check concurrent absences",
+}
+
+// errEmployeeSkipped is why the checks reading the employee are skipped
+// along with the employee check
+var errEmployeeSkipped = errors.New("employee could not be looked up")
+
+// organizationDown reports whether err means the organization service could
+// not be reached or is not configured
+func organizationDown(err error) bool {
+	return errors.Is(err, organization.ErrUnavailable) || errors.Is(err, organization.ErrNotConfigured)
+}
+
+// skipValidation applies the severity of check, which could not run because
+// of cause: the organization service being down or, for the checks reading
+// the employee, errEmployeeSkipped. A blocking check refuses the
+// request with a 503; a degrading one is returned to be recorded on it.
+func (s *leaveService) skipValidation(check string, employeeID uuid.UUID, cause error) (domain.SkippedValidation, error) {
+	if s.validationSeverities[check] != domain.ValidationSeverityDegrade {
+		return domain.SkippedValidation{}, apperrors.NewServiceUnavailableError(
+			"organization service unavailable, " + validationUnavailable[check])
+	}
+	log.Printf("Warning: skipping %s check for %s: %v", check, employeeID, cause)
+
+	reason := cause.Error()
+	switch {
+	case errors.Is(cause, organization.ErrNotConfigured):
+		reason = "organization service not configured"
+	case errors.Is(cause, organization.ErrUnavailable):
+		reason = "organization service unavailable"
+	}
+	return domain.SkippedValidation{Check: check, Reason: reason}, nil
+}
+
+// recordSkippedValidations warns the caller of each check the saved request
+// went through without and notes it in the request's history. The request
+// is saved by then, so failing to write the history is only logged.
+func (s *leaveService) recordSkippedValidations(leaveRequest *domain.LeaveRequest) {
+	for _, skipped := range leaveRequest.SkippedValidations {
+		leaveRequest.Warnings = append(leaveRequest.Warnings, skipped.String())
+		if err := s.leaveRepo.CreateLeaveRequestHistory(&domain.LeaveRequestHistory{
+			LeaveRequestID: leaveRequest.ID,
+			Action:         domain.LeaveActionValidationSkipped,
+			Status:         leaveRequest.Status,
+			Comments:       skipped.String(),
+			PerformedBy:    domain.SystemUserID,
+		}); err != nil {
+			log.Printf("Warning: cannot record skipped %s check on leave request %s: %v", skipped.Check, leaveRequest.ID, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// errCircuitOpen is how the organization client fails calls while its
+// circuit is open
+var errCircuitOpen = fmt.Errorf("%w: circuit open", organization.ErrUnavailable)
+
+func TestCreateLeaveRequestOrganizationDown(t *testing.T) {
+	every := []string{domain.ValidationCheckEmployee, domain.ValidationCheckEligibility, domain.ValidationCheckTenure,
+		domain.ValidationCheckProbation, domain.ValidationCheckAbsenceLimit}
+	tests := []struct {
+		name        string
+		failOpen    bool
+		err         error
+		block       string
+		wantStatus  int
+		wantMessage string
+		wantReason  string
+	}{
+		{name: "employee check blocks", err: organization.ErrUnavailable,
+			wantStatus: http.StatusServiceUnavailable, wantMessage: "cannot verify employee"},
+		{name: "advisory checks degrade", failOpen: true, err: organization.ErrUnavailable,
+			wantReason: "organization service unavailable"},
+		{name: "circuit open", failOpen: true, err: errCircuitOpen, wantReason: "organization service unavailable"},
+		{name: "not configured", failOpen: true, err: organization.ErrNotConfigured,
+			wantReason: "organization service not configured"},
+		{name: "advisory check set to block", failOpen: true, err: organization.ErrUnavailable, block: domain.ValidationCheckTenure,
+			wantStatus: http.StatusServiceUnavailable, wantMessage: "cannot check tenure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, employeeID := uuid.New(), uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.settings.MaxConcurrentAbsences = 1
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			leaveType.Eligibility = maternity
+			leaveType.MinTenureDays = 90
+			leaveType.BlockedDuringProbation = true
+			svc, directory := newDirectoryService(repo, employeeID, nil)
+			svc.validationSeverities = validationSeverities(tt.failOpen)
+			if tt.block != "" {
+				svc.validationSeverities[tt.block] = domain.ValidationSeverityBlock
+			}
+			directory.err, directory.departmentErr = tt.err, tt.err
+
+			monday := upcomingMonday()
+			request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   monday,
+				EndDate:     monday,
+			})
+
+			if tt.wantStatus != 0 {
+				if httpStatus(err) != tt.wantStatus {
+					t.Fatalf("got %d (%v), want %d", httpStatus(err), err, tt.wantStatus)
+				}
+				if msg := err.(*apperrors.AppError).Message; !strings.Contains(msg, tt.wantMessage) {
+					t.Errorf("message %q does not say it %s", msg, tt.wantMessage)
+				}
+				if repo.created != nil || len(repo.history) != 0 {
+					t.Error("a refused request was saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// The other checks read the employee, so they are skipped with it
+			if len(request.SkippedValidations) != len(every) {
+				t.Fatalf("skipped %+v, want %v", request.SkippedValidations, every)
+			}
+			for i, skipped := range request.SkippedValidations {
+				wantReason := tt.wantReason
+				if i > 0 {
+					wantReason = "employee could not be looked up"
+				}
+				if skipped.Check != every[i] || skipped.Reason != wantReason {
+					t.Errorf("skipped %+v, want the %s check for %q", skipped, every[i], wantReason)
+				}
+			}
+			if len(request.Warnings) != len(every) || request.Warnings[0] != "employee check skipped: "+tt.wantReason {
+				t.Errorf("warnings = %q, want one per skipped check", request.Warnings)
+			}
+			if len(repo.history) != len(every) {
+				t.Fatalf("recorded %d history entries, want one per skipped check", len(repo.history))
+			}
+			for i, entry := range repo.history {
+				if entry.LeaveRequestID != request.ID || entry.Action != domain.LeaveActionValidationSkipped ||
+					entry.Status != request.Status || entry.Comments != request.Warnings[i] || entry.PerformedBy != domain.SystemUserID {
+					t.Errorf("history entry %+v does not record %q on the request", entry, request.Warnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEditLeaveRequestOrganizationDown(t *testing.T) {
+	orgID, employeeID := uuid.New(), uuid.New()
+	repo := newFakeRepository()
+	leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+	svc, directory := newDirectoryService(repo, employeeID, &organization.EmployeeResponse{})
+	monday := upcomingMonday()
+
+	request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+		EmployeeID:  employeeID,
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday,
+	})
+	if err != nil {
+		t.Fatalf("creating: %v", err)
+	}
+	if len(request.SkippedValidations) != 0 || len(repo.history) != 0 {
+		t.Fatalf("skipped %+v with the organization service up", request.SkippedValidations)
+	}
+	request.Status = domain.LeaveStatusPending
+
+	directory.err = organization.ErrUnavailable
+	edited, err := svc.EditLeaveRequest(orgID, request.ID, employeeID, &domain.EditLeaveRequestRequest{
+		LeaveTypeID: leaveType.ID,
+		StartDate:   monday,
+		EndDate:     monday.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("editing: %v", err)
+	}
+	want := domain.SkippedValidation{Check: domain.ValidationCheckEmployee, Reason: "organization service unavailable"}
+	if len(edited.SkippedValidations) != 1 || edited.SkippedValidations[0] != want {
+		t.Errorf("skipped %+v, want %+v", edited.SkippedValidations, want)
+	}
+	if len(repo.history) != 1 || repo.history[0].Action != domain.LeaveActionValidationSkipped {
+		t.Errorf("history = %+v, want the skipped employee check", repo.history)
+	}
+}
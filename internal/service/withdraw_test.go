@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/Axontik/comin-leave-management-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestWithdrawLeaveRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		undo           bool
+		window         int
+		age            time.Duration
+		voted          bool
+		purgeErr       error
+		stranger       bool
+		wantStatus     int
+		wantPurged     bool
+		wantTransition string
+	}{
+		{name: "undo within the window", undo: true, window: 15, age: time.Minute, wantPurged: true},
+		{name: "undo at the window's end", undo: true, window: 15, age: 14 * time.Minute, wantPurged: true},
+		{name: "undo after the window cancels", undo: true, window: 15, age: 16 * time.Minute, wantTransition: domain.LeaveStatusCancelled},
+		{name: "undo turned off cancels", undo: true, window: 0, age: time.Second, wantTransition: domain.LeaveStatusCancelled},
+		{name: "undo after a vote cancels", undo: true, window: 15, age: time.Minute, voted: true, wantTransition: domain.LeaveStatusCancelled},
+		{name: "undo racing a decision cancels", undo: true, window: 15, age: time.Minute, purgeErr: repository.ErrStatusChanged, wantTransition: domain.LeaveStatusCancelled},
+		{name: "without undo cancels", window: 15, age: time.Minute, wantTransition: domain.LeaveStatusCancelled},
+		{name: "someone else's request", undo: true, window: 15, age: time.Minute, stranger: true, wantStatus: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.settings.UndoWindowMinutes = tt.window
+			repo.purgeErr = tt.purgeErr
+			request := repo.addPendingRequest(repo.addLeaveType(orgID, domain.RoundingNone), employeeID)
+			request.CreatedAt = time.Now().Add(-tt.age)
+			if tt.voted {
+				request.Approvals = []domain.LeaveRequestApproval{{ApproverID: uuid.New()}}
+			}
+
+			caller := employeeID
+			if tt.stranger {
+				caller = uuid.New()
+			}
+			result, err := newTestService(repo).WithdrawLeaveRequest(orgID, request.ID, caller, "employee", tt.undo)
+			if status := httpStatus(err); status != tt.wantStatus {
+				t.Fatalf("got %v (%d), want %d", err, status, tt.wantStatus)
+			}
+			if (repo.purged != nil) != tt.wantPurged {
+				t.Errorf("purged = %v, want %v", repo.purged != nil, tt.wantPurged)
+			}
+			if tt.wantPurged && result != nil {
+				t.Errorf("an undone request returned %+v, want nil", result)
+			}
+			if repo.transitioned != tt.wantTransition {
+				t.Errorf("request moved to %q, want %q", repo.transitioned, tt.wantTransition)
+			}
+		})
+	}
+}
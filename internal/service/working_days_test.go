@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestAddWorkingDaysCountsLikeRequests(t *testing.T) {
+	monday := upcomingMonday()
+	// A public holiday on the first Wednesday, and an optional one on the
+	// second Tuesday that only the claiming employee has off
+	public := domain.Holiday{Name: "Founders' Day", Date: monday.AddDate(0, 0, 2), Type: domain.HolidayTypePublic}
+	optional := domain.Holiday{Name: "Diwali", Date: monday.AddDate(0, 0, 8), Type: domain.HolidayTypeOptional}
+	public.ID, optional.ID = uuid.New(), uuid.New()
+
+	tests := []struct {
+		name     string
+		sandwich bool
+		claimed  bool
+		start    int
+		days     float64
+		wantEnd  int
+		wantHalf bool
+	}{
+		{name: "over the holiday", start: 1, days: 3, wantEnd: 4},
+		{name: "across the weekend", start: 3, days: 3, wantEnd: 7},
+		{name: "across the weekend, sandwiched", sandwich: true, start: 3, days: 3, wantEnd: 7},
+		{name: "ending on a half day, sandwiched", sandwich: true, start: 3, days: 2.5, wantEnd: 7, wantHalf: true},
+		{name: "unclaimed optional holiday is worked", start: 7, days: 2, wantEnd: 8},
+		{name: "claimed optional holiday is skipped", claimed: true, start: 7, days: 2, wantEnd: 9},
+		{name: "claimed optional holiday, sandwiched", sandwich: true, claimed: true, start: 3, days: 5, wantEnd: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			employeeID := uuid.New()
+			repo := newFakeRepository()
+			repo.settings = domain.DefaultOrganizationSettings(orgID)
+			repo.settings.SandwichPolicy = tt.sandwich
+			repo.holidays = []domain.Holiday{public, optional}
+			if tt.claimed {
+				repo.claims = []domain.OptionalHolidayClaim{{EmployeeID: employeeID, HolidayID: optional.ID}}
+			}
+			leaveType := repo.addLeaveType(orgID, domain.RoundingNone)
+			svc := newTestService(repo)
+
+			start := monday.AddDate(0, 0, tt.start)
+			result, err := svc.AddWorkingDays(orgID, &domain.AddWorkingDaysParams{Start: start, Days: tt.days, EmployeeID: &employeeID})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := monday.AddDate(0, 0, tt.wantEnd); !result.EndDate.Equal(want) || result.EndsHalfDay != tt.wantHalf {
+				t.Fatalf("ends %s (half day %t), want %s (half day %t)",
+					result.EndDate.Format("2006-01-02"), result.EndsHalfDay, want.Format("2006-01-02"), tt.wantHalf)
+			}
+
+			// The suggested period, filed as a request, is charged the
+			// days the suggestion reported
+			endHalf := ""
+			if result.EndsHalfDay {
+				endHalf = domain.HalfDayAM
+			}
+			request, err := svc.CreateLeaveRequest(orgID, &domain.CreateLeaveRequestRequest{
+				EmployeeID:  employeeID,
+				LeaveTypeID: leaveType.ID,
+				StartDate:   result.StartDate,
+				EndDate:     result.EndDate,
+				EndHalf:     endHalf,
+				Reason:      "Trip",
+			})
+			if err != nil {
+				t.Fatalf("filing the suggestion: %v", err)
+			}
+			if request.RawDays != result.ChargedDays {
+				t.Errorf("charged_days = %v, the request is charged %v", result.ChargedDays, request.RawDays)
+			}
+			if !tt.sandwich && result.ChargedDays != tt.days {
+				t.Errorf("charged_days = %v without the sandwich policy, want %v", result.ChargedDays, tt.days)
+			}
+			if tt.sandwich && tt.wantEnd-tt.start > 4 && result.ChargedDays <= tt.days {
+				t.Errorf("charged_days = %v, want the sandwiched weekend on top of %v", result.ChargedDays, tt.days)
+			}
+		})
+	}
+}
+
+func TestAddWorkingDaysWithoutEmployee(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	monday := upcomingMonday()
+	repo.holidays = []domain.Holiday{{Name: "Founders' Day", Date: monday.AddDate(0, 0, 1), Type: domain.HolidayTypePublic}}
+
+	result, err := newTestService(repo).AddWorkingDays(orgID, &domain.AddWorkingDaysParams{Start: monday, Days: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := monday.AddDate(0, 0, 2); !result.EndDate.Equal(want) || result.ChargedDays != 2 {
+		t.Errorf("ends %s charged %v, want %s and 2", result.EndDate.Format("2006-01-02"), result.ChargedDays, want.Format("2006-01-02"))
+	}
+
+	for _, days := range []float64{0, 1.25} {
+		if _, err := newTestService(repo).AddWorkingDays(orgID, &domain.AddWorkingDaysParams{Start: monday, Days: days}); httpStatus(err) != 400 {
+			t.Errorf("%v days: got %v, want a 400", days, err)
+		}
+	}
+}
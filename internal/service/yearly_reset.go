@@ -0,0 +1,149 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	apperrors "github.com/Axontik/comin-leave-management-service/internal/errors"
+	"github.com/Axontik/comin-leave-management-service/pkg/organization"
+	"github.com/google/uuid"
+)
+
+// yearlyResetProgressEvery is how often, in employees, a running reset logs
+// its progress
+const yearlyResetProgressEvery = 100
+
+// StartYearlyReset queues a job opening the organization's balances of the
+// requested year for every active employee and paid leave type that tracks
+// a balance, carrying over unused days within each type's limit. The
+// employees are listed now, with the caller's credentials; an organization
+// runs one reset at a time.
+func (s *leaveService) StartYearlyReset(orgID uuid.UUID, req *domain.YearlyResetRequest) (*domain.Job, error) {
+	if s.employees == nil {
+		return nil, apperrors.NewServiceUnavailableError("organization service not configured, cannot list employees")
+	}
+
+	employees, err := s.employees.ListEmployees(req.AuthToken, orgID.String())
+	if errors.Is(err, organization.ErrUnavailable) || errors.Is(err, organization.ErrNotConfigured) {
+		return nil, apperrors.NewServiceUnavailableError("organization service unavailable, cannot list employees")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload := domain.YearlyResetJobPayload{Year: req.Year, EmployeeIDs: []uuid.UUID{}}
+	for _, employee := range employees {
+		if employee.Status != "" && employee.Status != "active" {
+			continue
+		}
+		employeeID, err := uuid.Parse(employee.ID)
+		if err != nil {
+			log.Printf("Warning: yearly reset skipping employee with invalid id %q in %s", employee.ID, orgID)
+			continue
+		}
+		payload.EmployeeIDs = append(payload.EmployeeIDs, employeeID)
+	}
+
+	return s.enqueueJob(orgID, req.RequestedBy, domain.JobTypeYearlyReset, payload, len(payload.EmployeeIDs))
+}
+
+// runYearlyReset runs a yearly reset job. Employees are processed one
+// transaction each; those that fail are listed in the summary and can be
+// retried by running the reset again.
+func (s *leaveService) runYearlyReset(job *domain.Job, progress jobProgress) (interface{}, error) {
+	var payload domain.YearlyResetJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, err
+	}
+	orgID := job.OrganizationID
+
+	leaveTypes, err := s.leaveRepo.ListEntitlementLeaveTypes(orgID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.GetOrganizationSettings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.YearlyResetSummary{
+		Year:       payload.Year,
+		LeaveTypes: len(leaveTypes),
+		Errors:     []domain.YearlyResetError{},
+	}
+	if len(leaveTypes) == 0 {
+		return summary, nil
+	}
+
+	for i, employeeID := range payload.EmployeeIDs {
+		summary.Employees++
+		counts, err := s.leaveRepo.InitializeYearlyBalance(orgID, employeeID, job.ID, leaveTypes, payload.Year, settings.HoursPerDay)
+		if err != nil {
+			summary.Errors = append(summary.Errors, domain.YearlyResetError{EmployeeID: employeeID, Error: err.Error()})
+		} else {
+			summary.Add(counts)
+		}
+
+		if summary.Employees%yearlyResetProgressEvery == 0 {
+			log.Printf("Yearly reset %d of %s: %d employees done, %d balances created, %d failed",
+				payload.Year, orgID, summary.Employees, summary.Created, len(summary.Errors))
+		}
+		if err := progress(i+1, len(payload.EmployeeIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Yearly reset %d of %s finished: %d employees, %d balances created, %d adopted, %d carried over, %d skipped, %d failed",
+		payload.Year, orgID, summary.Employees, summary.Created, summary.Adopted, summary.CarriedOver, summary.Skipped, len(summary.Errors))
+	return summary, nil
+}
+
+// ListBalanceSnapshots lists the organization's balance snapshots, those
+// of one reset run and one employee when given
+func (s *leaveService) ListBalanceSnapshots(orgID uuid.UUID, params *domain.ListBalanceSnapshotsParams) ([]domain.BalanceSnapshot, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 10
+	}
+	return s.leaveRepo.ListBalanceSnapshots(orgID, params)
+}
+
+// ExpireCarryOver forfeits the unused carried-over days of the
+// organization's balances whose carry-over expired before today, in the
+// organization's time zone. Each balance is expired in its own transaction
+// with a system adjustment documenting the forfeiture; failures are logged
+// and left for the next run.
+func (s *leaveService) ExpireCarryOver(orgID uuid.UUID) (*domain.CarryOverExpirySummary, error) {
+	today, err := s.Today(orgID)
+	if err != nil {
+		return nil, err
+	}
+	// Expiry dates are stored as plain dates
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	ids, err := s.leaveRepo.ListExpiredCarryOverBalanceIDs(orgID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.CarryOverExpirySummary{}
+	for _, id := range ids {
+		adjustment, err := s.leaveRepo.ExpireCarryOver(id, today)
+		if err != nil {
+			log.Printf("Error: cannot expire carried-over days of balance %s: %v", id, err)
+			summary.Failed++
+			continue
+		}
+		summary.Expired++
+		if adjustment != nil {
+			summary.Forfeited++
+			summary.ForfeitedDays -= adjustment.Adjustment
+		}
+	}
+	return summary, nil
+}
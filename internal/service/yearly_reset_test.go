@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Axontik/comin-leave-management-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestRunYearlyResetSnapshotsUnderTheJob(t *testing.T) {
+	orgID := uuid.New()
+	repo := newFakeRepository()
+	repo.settings = &domain.OrganizationSettings{OrganizationID: orgID, HoursPerDay: 8}
+	repo.addLeaveType(orgID, domain.RoundingNone)
+
+	payload, err := json.Marshal(domain.YearlyResetJobPayload{Year: 2027, EmployeeIDs: []uuid.UUID{uuid.New(), uuid.New()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &domain.Job{Base: domain.Base{ID: uuid.New()}, OrganizationID: orgID, Payload: payload}
+
+	if _, err := newTestService(repo).runYearlyReset(job, func(int, int) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.resetRuns) != 2 {
+		t.Fatalf("got %d employees reset, want 2", len(repo.resetRuns))
+	}
+	for _, runID := range repo.resetRuns {
+		if runID != job.ID {
+			t.Errorf("balances snapshotted under run %s, want the job's id %s", runID, job.ID)
+		}
+	}
+}
+
+func TestLeaveStatementOpensWithSnapshot(t *testing.T) {
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	repo := newFakeRepository()
+	annual := repo.addLeaveType(orgID, domain.RoundingNone)
+	sick := repo.addLeaveType(orgID, domain.RoundingNone)
+	sick.Name = "Sick"
+
+	for _, leaveType := range []*domain.LeaveType{annual, sick} {
+		repo.balances = append(repo.balances, domain.LeaveBalance{
+			Base:        domain.Base{ID: uuid.New()},
+			EmployeeID:  employeeID,
+			LeaveTypeID: leaveType.ID,
+			LeaveType:   leaveType,
+			Year:        2027,
+			TotalDays:   25,
+			UsedDays:    6,
+		})
+	}
+	runID := uuid.New()
+	snapshotAt := time.Date(2027, time.January, 1, 2, 0, 0, 0, time.UTC)
+	repo.snapshots = []domain.BalanceSnapshot{
+		{
+			Base:            domain.Base{ID: uuid.New(), CreatedAt: snapshotAt},
+			RunID:           runID,
+			EmployeeID:      employeeID,
+			LeaveTypeID:     annual.ID,
+			Year:            2027,
+			TotalDays:       23,
+			PendingDays:     2,
+			RemainingDays:   21,
+			CarriedOverDays: 3,
+		},
+		// Another employee's snapshot stays off the statement
+		{RunID: runID, EmployeeID: uuid.New(), LeaveTypeID: sick.ID, Year: 2027, TotalDays: 10},
+	}
+
+	statement, err := newTestService(repo).LeaveStatement(orgID, employeeID, 2027, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statement.Balances) != 2 {
+		t.Fatalf("got %d balances, want 2", len(statement.Balances))
+	}
+	for _, b := range statement.Balances {
+		switch b.LeaveTypeID {
+		case annual.ID:
+			want := domain.LeaveStatementOpening{RunID: runID, SnapshotAt: snapshotAt, TotalDays: 23,
+				PendingDays: 2, RemainingDays: 21, CarriedOverDays: 3}
+			if b.Opening == nil || *b.Opening != want {
+				t.Errorf("annual opening = %+v, want %+v", b.Opening, want)
+			}
+			if b.TotalDays != 25 || b.UsedDays != 6 {
+				t.Errorf("current annual balance = %v total, %v used, want 25 and 6", b.TotalDays, b.UsedDays)
+			}
+		case sick.ID:
+			if b.Opening != nil {
+				t.Errorf("sick balance without a snapshot opens with %+v", b.Opening)
+			}
+		}
+	}
+}
+
+func TestListBalanceSnapshotsDefaultsPaging(t *testing.T) {
+	repo := newFakeRepository()
+
+	params := &domain.ListBalanceSnapshotsParams{Page: 0, PageSize: 500}
+	if _, _, err := newTestService(repo).ListBalanceSnapshots(uuid.New(), params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.snapshotParams.Page != 1 || repo.snapshotParams.PageSize != 10 {
+		t.Errorf("listed page %d of size %d, want page 1 of size 10", repo.snapshotParams.Page, repo.snapshotParams.PageSize)
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leave_balance_adjustments_updated;
+DROP INDEX IF EXISTS idx_leave_requests_org_updated;
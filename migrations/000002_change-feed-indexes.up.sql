@@ -0,0 +1,3 @@
+-- Indexes backing the incremental change feeds (updated_at, id watermark)
+CREATE INDEX idx_leave_requests_org_updated ON leave_requests(organization_id, updated_at, id);
+CREATE INDEX idx_leave_balance_adjustments_updated ON leave_balance_adjustments(updated_at, id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leave_request_history_request;
+ALTER TABLE leave_request_history DROP COLUMN IF EXISTS updated_at;
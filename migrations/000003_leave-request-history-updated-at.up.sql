@@ -0,0 +1,3 @@
+-- History rows share the Base model, which writes updated_at
+ALTER TABLE leave_request_history ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX idx_leave_request_history_request ON leave_request_history(leave_request_id);
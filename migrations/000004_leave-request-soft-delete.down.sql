@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leave_requests_deleted_at;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS deleted_at;
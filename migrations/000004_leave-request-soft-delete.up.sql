@@ -0,0 +1,2 @@
+ALTER TABLE leave_requests ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_leave_requests_deleted_at ON leave_requests(deleted_at);
@@ -0,0 +1,4 @@
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS raw_days;
+
+ALTER TABLE leave_types DROP CONSTRAINT IF EXISTS leave_types_rounding_check;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS rounding;
@@ -0,0 +1,6 @@
+ALTER TABLE leave_types ADD COLUMN rounding VARCHAR(10) NOT NULL DEFAULT 'none';
+ALTER TABLE leave_types ADD CONSTRAINT leave_types_rounding_check CHECK (rounding IN ('none', 'half_up', 'full_up'));
+
+ALTER TABLE leave_requests ADD COLUMN raw_days DECIMAL(5,2);
+UPDATE leave_requests SET raw_days = days;
+ALTER TABLE leave_requests ALTER COLUMN raw_days SET NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE leave_types DROP COLUMN IF EXISTS allow_negative_balance;
@@ -0,0 +1 @@
+ALTER TABLE leave_types ADD COLUMN allow_negative_balance BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_leave_requests_assigned_approver;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS assigned_approver_id;
+
+ALTER TABLE leave_types DROP COLUMN IF EXISTS approver_pool_id;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS approver_role;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS approval_strategy;
+
+DROP TABLE IF EXISTS approver_pool_members;
+DROP TABLE IF EXISTS approver_pools;
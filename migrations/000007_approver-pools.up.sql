@@ -0,0 +1,25 @@
+CREATE TABLE approver_pools (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    last_assigned_user_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(organization_id, name)
+);
+
+CREATE TABLE approver_pool_members (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    pool_id UUID NOT NULL REFERENCES approver_pools(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(pool_id, user_id)
+);
+
+ALTER TABLE leave_types ADD COLUMN approval_strategy VARCHAR(20) NOT NULL DEFAULT 'direct_manager';
+ALTER TABLE leave_types ADD COLUMN approver_role VARCHAR(50);
+ALTER TABLE leave_types ADD COLUMN approver_pool_id UUID REFERENCES approver_pools(id);
+
+ALTER TABLE leave_requests ADD COLUMN assigned_approver_id UUID;
+CREATE INDEX idx_leave_requests_assigned_approver ON leave_requests(assigned_approver_id) WHERE status = 'pending';
@@ -0,0 +1,6 @@
+UPDATE leave_requests SET status = 'approved' WHERE status = 'cancellation_requested';
+UPDATE leave_request_history SET status = 'approved' WHERE status = 'cancellation_requested';
+ALTER TABLE leave_request_history ALTER COLUMN status TYPE VARCHAR(20);
+ALTER TABLE leave_requests ALTER COLUMN status TYPE VARCHAR(20);
+
+DROP TABLE IF EXISTS organization_settings;
@@ -0,0 +1,10 @@
+CREATE TABLE organization_settings (
+    organization_id UUID PRIMARY KEY,
+    free_cancellation_days INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Room for the cancellation_requested status
+ALTER TABLE leave_requests ALTER COLUMN status TYPE VARCHAR(30);
+ALTER TABLE leave_request_history ALTER COLUMN status TYPE VARCHAR(30);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_holidays_org_updated;
+DROP INDEX IF EXISTS idx_leave_balances_employee_updated;
+DROP INDEX IF EXISTS idx_leave_requests_employee_updated;
@@ -0,0 +1,4 @@
+-- Indexes backing the per-employee sync feeds (updated_at, id watermark)
+CREATE INDEX idx_leave_requests_employee_updated ON leave_requests(organization_id, employee_id, updated_at, id);
+CREATE INDEX idx_leave_balances_employee_updated ON leave_balances(organization_id, employee_id, updated_at, id);
+CREATE INDEX idx_holidays_org_updated ON holidays(organization_id, updated_at, id);
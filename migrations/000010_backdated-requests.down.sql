@@ -0,0 +1,3 @@
+ALTER TABLE organization_settings DROP COLUMN IF EXISTS backdate_window_days;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS backdated;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS allow_backdated;
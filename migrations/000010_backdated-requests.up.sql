@@ -0,0 +1,3 @@
+ALTER TABLE leave_types ADD COLUMN allow_backdated BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE leave_requests ADD COLUMN backdated BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE organization_settings ADD COLUMN backdate_window_days INTEGER NOT NULL DEFAULT 90;
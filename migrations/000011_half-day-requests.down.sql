@@ -0,0 +1,2 @@
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS end_half;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS start_half;
@@ -0,0 +1,2 @@
+ALTER TABLE leave_requests ADD COLUMN start_half VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE leave_requests ADD COLUMN end_half VARCHAR(2) NOT NULL DEFAULT '';
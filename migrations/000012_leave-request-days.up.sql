@@ -0,0 +1,24 @@
+-- Working days covered by each leave request, half days as 0.5
+CREATE TABLE leave_request_days (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    leave_request_id UUID NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    fraction DECIMAL(3,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(leave_request_id, date)
+);
+
+CREATE INDEX idx_leave_request_days_date ON leave_request_days(date);
+
+-- Backfill existing requests, skipping weekends and the organization's holidays
+INSERT INTO leave_request_days (leave_request_id, date, fraction)
+SELECT r.id, d::date,
+    CASE WHEN (d::date = r.start_date AND r.start_half = 'pm') OR (d::date = r.end_date AND r.end_half = 'am')
+        THEN 0.5 ELSE 1 END
+FROM leave_requests r
+CROSS JOIN LATERAL generate_series(r.start_date, r.end_date, INTERVAL '1 day') AS d
+WHERE EXTRACT(ISODOW FROM d) < 6
+  AND NOT EXISTS (
+    SELECT 1 FROM holidays h WHERE h.organization_id = r.organization_id AND h.date = d::date
+  );
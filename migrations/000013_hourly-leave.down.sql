@@ -0,0 +1,6 @@
+ALTER TABLE organization_settings DROP COLUMN IF EXISTS hours_per_day;
+
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS start_time;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS hours;
+
+ALTER TABLE leave_types DROP COLUMN IF EXISTS unit;
@@ -0,0 +1,6 @@
+ALTER TABLE leave_types ADD COLUMN unit VARCHAR(5) NOT NULL DEFAULT 'days';
+
+ALTER TABLE leave_requests ADD COLUMN hours DECIMAL(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE leave_requests ADD COLUMN start_time VARCHAR(5) NOT NULL DEFAULT '';
+
+ALTER TABLE organization_settings ADD COLUMN hours_per_day DECIMAL(4,2) NOT NULL DEFAULT 8;
@@ -0,0 +1 @@
+ALTER TABLE leave_types DROP COLUMN IF EXISTS tracks_balance;
@@ -0,0 +1 @@
+ALTER TABLE leave_types ADD COLUMN tracks_balance BOOLEAN NOT NULL DEFAULT true;
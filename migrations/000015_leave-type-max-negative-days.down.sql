@@ -0,0 +1 @@
+ALTER TABLE leave_types DROP COLUMN IF EXISTS max_negative_days;
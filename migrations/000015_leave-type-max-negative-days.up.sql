@@ -0,0 +1 @@
+ALTER TABLE leave_types ADD COLUMN max_negative_days DECIMAL(5,2);
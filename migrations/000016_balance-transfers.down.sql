@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS balance_transfer_job_failures;
+DROP TABLE IF EXISTS balance_transfer_jobs;
+DROP INDEX IF EXISTS idx_leave_balance_adjustments_transfer;
+ALTER TABLE leave_balance_adjustments
+    DROP COLUMN IF EXISTS counterpart_id,
+    DROP COLUMN IF EXISTS transfer_id;
@@ -0,0 +1,37 @@
+ALTER TABLE leave_balance_adjustments
+    ADD COLUMN transfer_id UUID,
+    ADD COLUMN counterpart_id UUID REFERENCES leave_balance_adjustments(id) DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX idx_leave_balance_adjustments_transfer ON leave_balance_adjustments(transfer_id) WHERE transfer_id IS NOT NULL;
+
+CREATE TABLE balance_transfer_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    from_leave_type_id UUID NOT NULL REFERENCES leave_types(id),
+    to_leave_type_id UUID NOT NULL REFERENCES leave_types(id),
+    year INTEGER NOT NULL,
+    days DECIMAL(5,2) NOT NULL,
+    all_remaining BOOLEAN NOT NULL,
+    reason TEXT NOT NULL,
+    requested_by UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    total INTEGER NOT NULL,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE balance_transfer_job_failures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES balance_transfer_jobs(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL,
+    error TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_balance_transfer_job_failures_job ON balance_transfer_job_failures(job_id);
@@ -0,0 +1,3 @@
+ALTER TABLE leave_request_days DROP COLUMN IF EXISTS sandwiched;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS sandwiched_days;
+ALTER TABLE organization_settings DROP COLUMN IF EXISTS sandwich_policy;
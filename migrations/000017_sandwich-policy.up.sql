@@ -0,0 +1,3 @@
+ALTER TABLE organization_settings ADD COLUMN sandwich_policy BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE leave_requests ADD COLUMN sandwiched_days DECIMAL(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE leave_request_days ADD COLUMN sandwiched BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS has_conflicts;
+ALTER TABLE organization_settings DROP CONSTRAINT IF EXISTS organization_settings_concurrent_absence_mode_check;
+ALTER TABLE organization_settings
+    DROP COLUMN IF EXISTS concurrent_absence_mode,
+    DROP COLUMN IF EXISTS max_concurrent_absences;
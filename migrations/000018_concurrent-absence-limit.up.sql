@@ -0,0 +1,7 @@
+ALTER TABLE organization_settings
+    ADD COLUMN max_concurrent_absences INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN concurrent_absence_mode VARCHAR(10) NOT NULL DEFAULT 'block';
+ALTER TABLE organization_settings ADD CONSTRAINT organization_settings_concurrent_absence_mode_check
+    CHECK (concurrent_absence_mode IN ('block', 'warn'));
+
+ALTER TABLE leave_requests ADD COLUMN has_conflicts BOOLEAN NOT NULL DEFAULT false;
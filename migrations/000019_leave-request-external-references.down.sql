@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leave_requests_external_references;
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS external_references;
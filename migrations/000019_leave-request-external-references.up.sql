@@ -0,0 +1,3 @@
+ALTER TABLE leave_requests ADD COLUMN external_references JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX idx_leave_requests_external_references ON leave_requests USING GIN (external_references jsonb_path_ops);
@@ -0,0 +1 @@
+ALTER TABLE leave_types ADD COLUMN min_tenure_days INTEGER NOT NULL DEFAULT 0;
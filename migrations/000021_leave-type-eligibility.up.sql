@@ -0,0 +1 @@
+ALTER TABLE leave_types ADD COLUMN eligibility JSONB;
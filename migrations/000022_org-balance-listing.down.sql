@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_leave_balances_org_year;
@@ -0,0 +1 @@
+CREATE INDEX idx_leave_balances_org_year ON leave_balances(organization_id, year, employee_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS leave_request_approvals;
+
+ALTER TABLE leave_types DROP COLUMN IF EXISTS quorum_size;
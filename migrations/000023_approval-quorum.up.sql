@@ -0,0 +1,10 @@
+ALTER TABLE leave_types ADD COLUMN quorum_size INTEGER NOT NULL DEFAULT 1 CHECK (quorum_size >= 1);
+
+CREATE TABLE leave_request_approvals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    leave_request_id UUID NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+    approver_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(leave_request_id, approver_id)
+);
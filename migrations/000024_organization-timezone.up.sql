@@ -0,0 +1 @@
+ALTER TABLE organization_settings ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,4 @@
+ALTER TABLE organization_settings
+    DROP COLUMN IF EXISTS anomaly_hold_adjustments,
+    DROP COLUMN IF EXISTS anomaly_max_writes_per_minute,
+    DROP COLUMN IF EXISTS anomaly_max_mutation_days;
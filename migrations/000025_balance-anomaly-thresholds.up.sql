@@ -0,0 +1,4 @@
+ALTER TABLE organization_settings
+    ADD COLUMN anomaly_max_mutation_days DECIMAL(6,2) NOT NULL DEFAULT 60,
+    ADD COLUMN anomaly_max_writes_per_minute INTEGER NOT NULL DEFAULT 500,
+    ADD COLUMN anomaly_hold_adjustments BOOLEAN NOT NULL DEFAULT TRUE;
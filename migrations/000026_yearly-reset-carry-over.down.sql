@@ -0,0 +1,5 @@
+ALTER TABLE leave_balances
+    DROP COLUMN IF EXISTS closed_at,
+    DROP COLUMN IF EXISTS carried_over_days;
+
+ALTER TABLE leave_types DROP COLUMN IF EXISTS max_carry_over_days;
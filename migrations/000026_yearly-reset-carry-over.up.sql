@@ -0,0 +1,5 @@
+ALTER TABLE leave_types ADD COLUMN max_carry_over_days DECIMAL(5,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE leave_balances
+    ADD COLUMN carried_over_days DECIMAL(5,2) NOT NULL DEFAULT 0,
+    ADD COLUMN closed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_leave_balances_carry_over_expiry;
+
+ALTER TABLE leave_balances
+    DROP COLUMN IF EXISTS carried_over_expired_at,
+    DROP COLUMN IF EXISTS carried_over_expires_on;
+
+ALTER TABLE leave_types DROP COLUMN IF EXISTS carry_over_expiry_months;
@@ -0,0 +1,8 @@
+ALTER TABLE leave_types ADD COLUMN carry_over_expiry_months INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE leave_balances
+    ADD COLUMN carried_over_expires_on DATE,
+    ADD COLUMN carried_over_expired_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_leave_balances_carry_over_expiry ON leave_balances(organization_id, carried_over_expires_on)
+    WHERE carried_over_expired_at IS NULL;
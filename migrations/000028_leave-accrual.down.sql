@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS accrual_runs;
+
+ALTER TABLE leave_types
+    DROP COLUMN IF EXISTS accrual_rate,
+    DROP COLUMN IF EXISTS accrual_frequency;
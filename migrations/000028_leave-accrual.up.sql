@@ -0,0 +1,20 @@
+ALTER TABLE leave_types
+    ADD COLUMN accrual_frequency VARCHAR(10) NOT NULL DEFAULT 'none'
+        CHECK (accrual_frequency IN ('none', 'monthly', 'quarterly')),
+    ADD COLUMN accrual_rate DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (accrual_rate >= 0);
+
+CREATE TABLE accrual_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    employee_id UUID NOT NULL,
+    leave_type_id UUID NOT NULL REFERENCES leave_types(id),
+    leave_balance_id UUID NOT NULL REFERENCES leave_balances(id),
+    period VARCHAR(7) NOT NULL,
+    days DECIMAL(5,2) NOT NULL,
+    adjustment_id UUID REFERENCES leave_balance_adjustments(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(employee_id, leave_type_id, period)
+);
+
+CREATE INDEX idx_accrual_runs_balance ON accrual_runs(leave_balance_id);
@@ -0,0 +1,3 @@
+ALTER TABLE leave_balances DROP COLUMN IF EXISTS provisional;
+
+ALTER TABLE organization_settings DROP COLUMN IF EXISTS max_advance_booking_months;
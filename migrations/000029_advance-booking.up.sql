@@ -0,0 +1,4 @@
+ALTER TABLE organization_settings
+    ADD COLUMN max_advance_booking_months INTEGER NOT NULL DEFAULT 15 CHECK (max_advance_booking_months >= 1);
+
+ALTER TABLE leave_balances ADD COLUMN provisional BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+ALTER TABLE organization_settings
+    DROP COLUMN IF EXISTS proration_rounding,
+    DROP COLUMN IF EXISTS proration_basis;
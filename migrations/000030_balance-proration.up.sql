@@ -0,0 +1,5 @@
+ALTER TABLE organization_settings
+    ADD COLUMN proration_basis VARCHAR(10) NOT NULL DEFAULT 'months'
+        CHECK (proration_basis IN ('months', 'days')),
+    ADD COLUMN proration_rounding VARCHAR(15) NOT NULL DEFAULT 'nearest_half'
+        CHECK (proration_rounding IN ('nearest_half', 'up'));
@@ -0,0 +1,2 @@
+ALTER TABLE organization_settings
+    ADD COLUMN undo_window_minutes INTEGER NOT NULL DEFAULT 15 CHECK (undo_window_minutes >= 0);
@@ -0,0 +1 @@
+ALTER TABLE leave_balances DROP COLUMN IF EXISTS opening_days;
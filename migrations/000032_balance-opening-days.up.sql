@@ -0,0 +1 @@
+ALTER TABLE leave_balances ADD COLUMN opening_days DECIMAL(5,2);
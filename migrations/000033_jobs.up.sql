@@ -0,0 +1,25 @@
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    singleton BOOLEAN NOT NULL DEFAULT FALSE,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    processed INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    result JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    requested_by UUID NOT NULL,
+    lease_expires_at TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_claimable ON jobs(created_at) WHERE status IN ('queued', 'running');
+
+-- Backstops the advisory lock taken when queueing singleton jobs
+CREATE UNIQUE INDEX idx_jobs_active_singleton ON jobs(organization_id, type)
+    WHERE singleton AND status IN ('queued', 'running');
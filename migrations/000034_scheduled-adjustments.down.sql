@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_leave_balance_adjustments_scheduled;
+ALTER TABLE leave_balance_adjustments
+    DROP COLUMN IF EXISTS applied_at,
+    DROP COLUMN IF EXISTS effective_date;
@@ -0,0 +1,5 @@
+ALTER TABLE leave_balance_adjustments
+    ADD COLUMN effective_date DATE,
+    ADD COLUMN applied_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_leave_balance_adjustments_scheduled ON leave_balance_adjustments(effective_date) WHERE status = 'scheduled';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS comp_off_requests;
+ALTER TABLE organization_settings
+    DROP COLUMN IF EXISTS comp_off_expiry_days,
+    DROP COLUMN IF EXISTS comp_off_leave_type_id;
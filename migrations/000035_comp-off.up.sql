@@ -0,0 +1,30 @@
+ALTER TABLE organization_settings
+    ADD COLUMN comp_off_leave_type_id UUID REFERENCES leave_types(id) ON DELETE SET NULL,
+    ADD COLUMN comp_off_expiry_days INTEGER NOT NULL DEFAULT 0 CHECK (comp_off_expiry_days >= 0);
+
+CREATE TABLE comp_off_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    employee_id UUID NOT NULL,
+    date_worked DATE NOT NULL,
+    days DECIMAL(5,2) NOT NULL DEFAULT 0,
+    hours DECIMAL(5,2) NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by UUID NOT NULL,
+    decided_by UUID,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    comments TEXT,
+    leave_balance_id UUID REFERENCES leave_balances(id),
+    adjustment_id UUID REFERENCES leave_balance_adjustments(id),
+    credited DECIMAL(5,2),
+    expires_on DATE,
+    expired_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_comp_off_requests_org ON comp_off_requests(organization_id, status);
+CREATE UNIQUE INDEX idx_comp_off_requests_employee_date ON comp_off_requests(employee_id, date_worked)
+    WHERE status IN ('pending', 'approved', 'expired');
+CREATE INDEX idx_comp_off_requests_expiry ON comp_off_requests(expires_on) WHERE status = 'approved' AND expired_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE leave_balance_adjustments
+    DROP COLUMN IF EXISTS balance_after,
+    DROP COLUMN IF EXISTS balance_before;
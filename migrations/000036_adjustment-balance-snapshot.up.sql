@@ -0,0 +1,3 @@
+ALTER TABLE leave_balance_adjustments
+    ADD COLUMN balance_before DECIMAL(5,2),
+    ADD COLUMN balance_after DECIMAL(5,2);
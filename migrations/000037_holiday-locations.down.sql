@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_holidays_org_date_location;
+
+-- Only organization-wide holidays fit the one holiday per date rule
+DELETE FROM holidays WHERE location IS NOT NULL;
+ALTER TABLE holidays ADD CONSTRAINT holidays_organization_id_date_key UNIQUE(organization_id, date);
+
+ALTER TABLE holidays DROP COLUMN IF EXISTS location;
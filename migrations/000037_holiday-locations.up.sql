@@ -0,0 +1,6 @@
+-- Holidays can be limited to one office or region; NULL keeps them
+-- organization-wide. Each location has one holiday per date.
+ALTER TABLE holidays ADD COLUMN location VARCHAR(100);
+
+ALTER TABLE holidays DROP CONSTRAINT IF EXISTS holidays_organization_id_date_key;
+CREATE UNIQUE INDEX idx_holidays_org_date_location ON holidays(organization_id, date, COALESCE(LOWER(location), ''));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS optional_holiday_claims;
+ALTER TABLE organization_settings DROP COLUMN IF EXISTS optional_holiday_quota;
@@ -0,0 +1,17 @@
+ALTER TABLE organization_settings
+    ADD COLUMN optional_holiday_quota INTEGER NOT NULL DEFAULT 2 CHECK (optional_holiday_quota >= 0);
+
+-- Optional holidays an employee elected to take off; the date is the
+-- holiday's, so moving the holiday moves the claim with it
+CREATE TABLE optional_holiday_claims (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    employee_id UUID NOT NULL,
+    holiday_id UUID NOT NULL REFERENCES holidays(id) ON DELETE CASCADE,
+    claimed_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_optional_holiday_claims_employee_holiday ON optional_holiday_claims(employee_id, holiday_id);
+CREATE INDEX idx_optional_holiday_claims_org ON optional_holiday_claims(organization_id, holiday_id);
@@ -0,0 +1,10 @@
+-- Bands the Bradford report classifies employees' scores into, lowest
+-- first; each applies from its min_score up to the next band's
+ALTER TABLE organization_settings
+    ADD COLUMN bradford_bands JSONB NOT NULL DEFAULT '[
+        {"name": "none", "min_score": 0},
+        {"name": "low", "min_score": 51},
+        {"name": "medium", "min_score": 125},
+        {"name": "high", "min_score": 400},
+        {"name": "critical", "min_score": 650}
+    ]';
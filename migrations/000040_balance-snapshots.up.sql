@@ -0,0 +1,22 @@
+-- Balances as each yearly reset run left them, the authoritative opening
+-- balance of the year. Rows are only ever inserted; run_id is the id of
+-- the yearly reset job, so a retried run does not snapshot twice.
+CREATE TABLE balance_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    run_id UUID NOT NULL,
+    employee_id UUID NOT NULL,
+    leave_type_id UUID NOT NULL REFERENCES leave_types(id),
+    leave_balance_id UUID NOT NULL,
+    year INTEGER NOT NULL,
+    total_days DECIMAL(5,2) NOT NULL,
+    used_days DECIMAL(5,2) NOT NULL,
+    pending_days DECIMAL(5,2) NOT NULL,
+    remaining_days DECIMAL(5,2) NOT NULL,
+    carried_over_days DECIMAL(5,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_balance_snapshots_run_employee_type ON balance_snapshots(run_id, employee_id, leave_type_id);
+CREATE INDEX idx_balance_snapshots_org_employee ON balance_snapshots(organization_id, employee_id, year);
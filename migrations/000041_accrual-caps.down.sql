@@ -0,0 +1,3 @@
+ALTER TABLE leave_balance_adjustments DROP COLUMN IF EXISTS cap_overridden;
+ALTER TABLE accrual_runs DROP COLUMN IF EXISTS forfeited_days, DROP COLUMN IF EXISTS capped;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS max_balance_days;
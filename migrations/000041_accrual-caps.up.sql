@@ -0,0 +1,13 @@
+-- The most a balance may bank, its total less the days used, in the
+-- type's unit; NULL leaves it uncapped
+ALTER TABLE leave_types
+    ADD COLUMN max_balance_days DECIMAL(5,2) CHECK (max_balance_days >= 0);
+
+-- Accruals the bank cap cut short, and what they forfeited
+ALTER TABLE accrual_runs
+    ADD COLUMN capped BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN forfeited_days DECIMAL(5,2) NOT NULL DEFAULT 0;
+
+-- Manual adjustments an admin let past the bank cap
+ALTER TABLE leave_balance_adjustments
+    ADD COLUMN cap_overridden BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS absence_alerts;
+ALTER TABLE organization_settings
+    DROP COLUMN IF EXISTS absence_alert_webhook_url,
+    DROP COLUMN IF EXISTS absence_alert_spells,
+    DROP COLUMN IF EXISTS absence_alert_days;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS absence_monitored;
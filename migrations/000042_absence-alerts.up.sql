@@ -0,0 +1,39 @@
+-- Leave types whose leave, e.g. sickness, counts towards the rolling
+-- absence thresholds
+ALTER TABLE leave_types
+    ADD COLUMN absence_monitored BOOLEAN NOT NULL DEFAULT false;
+
+-- Days or spells in a rolling 12 months that raise an absence alert, 0
+-- for off, and where HR is notified of new alerts
+ALTER TABLE organization_settings
+    ADD COLUMN absence_alert_days DECIMAL(6,2) NOT NULL DEFAULT 0 CHECK (absence_alert_days >= 0),
+    ADD COLUMN absence_alert_spells INTEGER NOT NULL DEFAULT 0 CHECK (absence_alert_spells >= 0),
+    ADD COLUMN absence_alert_webhook_url TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE absence_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    employee_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    window_start DATE NOT NULL,
+    window_end DATE NOT NULL,
+    days DECIMAL(6,2) NOT NULL,
+    spells INTEGER NOT NULL,
+    threshold_days DECIMAL(6,2) NOT NULL,
+    threshold_spells INTEGER NOT NULL,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    cleared_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_by UUID,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    resolved_by UUID,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    comments TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_absence_alerts_org ON absence_alerts(organization_id, status);
+-- An employee has at most one alert until they fall back under the
+-- thresholds, so a threshold that stays crossed is not alerted again
+CREATE UNIQUE INDEX idx_absence_alerts_uncleared ON absence_alerts(organization_id, employee_id)
+    WHERE cleared_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE leave_requests DROP COLUMN IF EXISTS probation_overridden;
+ALTER TABLE organization_settings DROP COLUMN IF EXISTS probation_months;
+ALTER TABLE leave_types DROP COLUMN IF EXISTS blocked_during_probation;
@@ -0,0 +1,11 @@
+-- Leave types such as vacation that cannot be taken during probation
+ALTER TABLE leave_types
+    ADD COLUMN blocked_during_probation BOOLEAN NOT NULL DEFAULT false;
+
+-- How many months new employees are on probation, 0 for none
+ALTER TABLE organization_settings
+    ADD COLUMN probation_months INTEGER NOT NULL DEFAULT 3 CHECK (probation_months >= 0);
+
+-- Requests HR or an admin let through during probation
+ALTER TABLE leave_requests
+    ADD COLUMN probation_overridden BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS delegations;
+ALTER TABLE organization_settings
+    DROP COLUMN IF EXISTS delegation_webhook_url,
+    DROP COLUMN IF EXISTS delegation_confirm_days;
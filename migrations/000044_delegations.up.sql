@@ -0,0 +1,33 @@
+-- How long approvers have to confirm the delegation proposed for their own
+-- leave before it is escalated, 0 for never, and where proposals and
+-- escalations are sent
+ALTER TABLE organization_settings
+    ADD COLUMN delegation_confirm_days INTEGER NOT NULL DEFAULT 2 CHECK (delegation_confirm_days >= 0),
+    ADD COLUMN delegation_webhook_url TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL,
+    leave_request_id UUID NOT NULL REFERENCES leave_requests(id) ON DELETE CASCADE,
+    delegator_id UUID NOT NULL,
+    delegate_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'proposed',
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    confirm_by TIMESTAMP WITH TIME ZONE,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    escalated_at TIMESTAMP WITH TIME ZONE,
+    decided_by UUID,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A leave request has at most one delegation, which follows it through
+-- edits and cancellation
+CREATE UNIQUE INDEX idx_delegations_leave_request ON delegations(leave_request_id);
+CREATE INDEX idx_delegations_org ON delegations(organization_id, status);
+CREATE INDEX idx_delegations_delegate ON delegations(delegate_id, start_date, end_date)
+    WHERE status = 'confirmed';
+CREATE INDEX idx_delegations_confirm_by ON delegations(confirm_by)
+    WHERE status = 'proposed';
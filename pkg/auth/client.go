@@ -3,6 +3,7 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -11,6 +12,13 @@ import (
 	"time"
 )
 
+var (
+	// ErrNotConfigured is returned when the client was built without a base URL
+	ErrNotConfigured = errors.New("auth service not configured")
+	// ErrUnavailable is returned when the auth service cannot be reached or fails with 5xx
+	ErrUnavailable = errors.New("auth service unavailable")
+)
+
 type AuthClient struct {
 	baseURL    string
 	httpClient *http.Client
@@ -37,6 +45,10 @@ func NewAuthClient(baseURL string) *AuthClient {
 }
 
 func (c *AuthClient) ValidateToken(token string) (*UserResponse, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
 	log.Printf("Validating token: %s", token)
 
 	token = strings.TrimPrefix(token, "Bearer ")
@@ -53,13 +65,17 @@ func (c *AuthClient) ValidateToken(token string) (*UserResponse, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		log.Printf("Error making request: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	body, _ := ioutil.ReadAll(resp.Body)
 	log.Printf("Response status: %d, body: %s", resp.StatusCode, string(body))
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		var errResp ErrorResponse
 		if err := json.Unmarshal(body, &errResp); err != nil {
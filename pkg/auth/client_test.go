@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/contract"
+)
+
+// TestAuthClientContract replays every interaction of the auth service
+// contract against the client
+func TestAuthClientContract(t *testing.T) {
+	c, err := contract.Load("../../contracts/auth-service.json")
+	if err != nil {
+		t.Fatalf("loading contract: %v", err)
+	}
+
+	calls := map[string]contract.Call{
+		"auth.ValidateToken": func(baseURL string, vars map[string]string) (interface{}, error) {
+			return NewAuthClient(baseURL).ValidateToken(vars["token"])
+		},
+	}
+
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		t.Run(interaction.Description, func(t *testing.T) {
+			call, ok := calls[interaction.Call]
+			if !ok {
+				t.Fatalf("no test call for %q", interaction.Call)
+			}
+			if err := contract.Replay(c, interaction, call); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestValidateTokenNotConfigured(t *testing.T) {
+	if _, err := NewAuthClient("").ValidateToken("Bearer token"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("got %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestValidateTokenUnreachable(t *testing.T) {
+	_, err := NewAuthClient("http://127.0.0.1:1").ValidateToken("Bearer token")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("got %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,23 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// currentUserKey is where the validated user is kept on the gin context
+const currentUserKey = "auth_user"
+
+// SetCurrentUser stores the user a request's token was validated as, for
+// the handlers of the request
+func SetCurrentUser(c *gin.Context, user *UserResponse) {
+	c.Set(currentUserKey, user)
+}
+
+// CurrentUser returns the user stored by SetCurrentUser, or false on
+// routes that do not validate a token
+func CurrentUser(c *gin.Context) (*UserResponse, bool) {
+	value, ok := c.Get(currentUserKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*UserResponse)
+	return user, ok && user != nil
+}
@@ -0,0 +1,114 @@
+package holidays
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the public Nager.Date API
+const DefaultBaseURL = "https://date.nager.at"
+
+// ErrNotConfigured is returned when the client was built without a base URL
+var ErrNotConfigured = errors.New("holiday provider not configured")
+
+// ProviderError is a failed call to the holiday provider, with the
+// provider's status and message when it answered
+type ProviderError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode == 0 {
+		return "holiday provider unreachable: " + e.Message
+	}
+	return fmt.Sprintf("holiday provider returned %d: %s", e.StatusCode, e.Message)
+}
+
+// PublicHoliday is a public holiday of a country as the provider lists it
+type PublicHoliday struct {
+	Date      string `json:"date"`
+	LocalName string `json:"localName"`
+	Name      string `json:"name"`
+	// Global is false for holidays observed only in some regions
+	Global bool `json:"global"`
+}
+
+// Provider lists the public holidays of a country, by ISO 3166-1 alpha-2
+// code, in a year
+type Provider interface {
+	PublicHolidays(country string, year int) ([]PublicHoliday, error)
+}
+
+// Client calls a Nager.Date compatible holiday API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+// PublicHolidays fetches the country's public holidays of the year. A
+// country the provider does not know comes back as a 404 ProviderError.
+func (c *Client) PublicHolidays(country string, year int) ([]PublicHoliday, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
+	endpoint := fmt.Sprintf("%s/api/v3/PublicHolidays/%d/%s", c.baseURL, year, url.PathEscape(country))
+	resp, err := c.httpClient.Get(endpoint)
+	if err != nil {
+		return nil, &ProviderError{Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return []PublicHoliday{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ProviderError{StatusCode: resp.StatusCode, Message: providerMessage(resp)}
+	}
+
+	var holidays []PublicHoliday
+	if err := json.NewDecoder(resp.Body).Decode(&holidays); err != nil {
+		return nil, &ProviderError{StatusCode: resp.StatusCode, Message: "invalid response: " + err.Error()}
+	}
+	return holidays, nil
+}
+
+// providerMessage reads the error of a failed response: the title or
+// message of a JSON problem body, else the start of the body, else the
+// status text
+func providerMessage(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	var problem struct {
+		Title   string `json:"title"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &problem) == nil {
+		if problem.Title != "" {
+			return problem.Title
+		}
+		if problem.Message != "" {
+			return problem.Message
+		}
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		return text
+	}
+	return http.StatusText(resp.StatusCode)
+}
@@ -0,0 +1,101 @@
+package holidays
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPublicHolidays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/PublicHolidays/2026/LK" {
+			t.Errorf("requested %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"date":"2026-02-04","localName":"නිදහස් දිනය","name":"Independence Day","countryCode":"LK","global":true},
+			{"date":"2026-04-14","localName":"Puthandu","name":"Tamil New Year","global":false,"counties":["LK-4"]}
+		]`))
+	}))
+	defer server.Close()
+
+	holidays, err := NewClient(server.URL+"/").PublicHolidays("LK", 2026)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []PublicHoliday{
+		{Date: "2026-02-04", LocalName: "නිදහස් දිනය", Name: "Independence Day", Global: true},
+		{Date: "2026-04-14", LocalName: "Puthandu", Name: "Tamil New Year"},
+	}
+	if len(holidays) != len(want) {
+		t.Fatalf("got %d holidays, want %d", len(holidays), len(want))
+	}
+	for i := range want {
+		if holidays[i] != want[i] {
+			t.Errorf("holiday %d = %+v, want %+v", i, holidays[i], want[i])
+		}
+	}
+}
+
+func TestClientPublicHolidaysFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "unknown country", status: http.StatusNotFound, body: `{"title":"Country not found"}`, wantStatus: 404, wantMessage: "Country not found"},
+		{name: "message body", status: http.StatusBadRequest, body: `{"message":"year out of range"}`, wantStatus: 400, wantMessage: "year out of range"},
+		{name: "text body", status: http.StatusServiceUnavailable, body: "down for maintenance\n", wantStatus: 503, wantMessage: "down for maintenance"},
+		{name: "empty body", status: http.StatusBadGateway, wantStatus: 502, wantMessage: "Bad Gateway"},
+		{name: "invalid json", status: http.StatusOK, body: `{"date":`, wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewClient(server.URL).PublicHolidays("LK", 2026)
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("got %v, want a ProviderError", err)
+			}
+			if providerErr.StatusCode != tt.wantStatus || (tt.wantMessage != "" && providerErr.Message != tt.wantMessage) {
+				t.Errorf("got %d %q, want %d %q", providerErr.StatusCode, providerErr.Message, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestClientPublicHolidaysNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	holidays, err := NewClient(server.URL).PublicHolidays("LK", 2026)
+	if err != nil || holidays == nil || len(holidays) != 0 {
+		t.Errorf("got %v, %v, want no holidays", holidays, err)
+	}
+}
+
+func TestClientPublicHolidaysUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	_, err := NewClient(server.URL).PublicHolidays("LK", 2026)
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != 0 {
+		t.Errorf("got %v, want an unreachable ProviderError", err)
+	}
+
+	if _, err := NewClient("").PublicHolidays("LK", 2026); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("got %v, want ErrNotConfigured", err)
+	}
+}
@@ -0,0 +1,53 @@
+package organization
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold is how many consecutive failed calls open the circuit
+	breakerThreshold = 5
+	// breakerCooldown is how long an open circuit fails calls without
+	// trying the organization service
+	breakerCooldown = 30 * time.Second
+)
+
+// breaker stops calls to the organization service after it failed
+// threshold times in a row, so callers fail fast instead of each waiting
+// out the timeout. Once the cooldown passes calls are tried again; the
+// first success closes the circuit and a failure reopens it.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// isOpen reports whether calls are being failed without being made
+func (b *breaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold && b.now().Before(b.openUntil)
+}
+
+// record counts the outcome of a call
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,81 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls, failing atomic.Int32
+	failing.Store(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewOrganizationClient(server.URL)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	client.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < breakerThreshold; i++ {
+		if client.CircuitOpen() {
+			t.Fatalf("circuit open after %d failures", i)
+		}
+		if _, err := client.ListDepartments("token", "org"); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("got %v, want ErrUnavailable", err)
+		}
+	}
+
+	// Open, calls fail without reaching the service
+	if !client.CircuitOpen() {
+		t.Fatal("circuit closed after the threshold of failures")
+	}
+	if _, err := client.ListEmployees("token", "org"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("got %v, want ErrUnavailable", err)
+	}
+	if got := calls.Load(); got != breakerThreshold {
+		t.Errorf("service called %d times, want %d", got, breakerThreshold)
+	}
+
+	// After the cooldown a failed trial reopens it for another cooldown
+	now = now.Add(breakerCooldown)
+	if _, err := client.ListEmployees("token", "org"); !errors.Is(err, ErrUnavailable) || calls.Load() != breakerThreshold+1 {
+		t.Fatalf("trial call got %v after %d calls", err, calls.Load())
+	}
+	if !client.CircuitOpen() {
+		t.Fatal("circuit closed after a failed trial")
+	}
+
+	// and a successful one closes it
+	now = now.Add(breakerCooldown)
+	failing.Store(0)
+	if _, err := client.ListEmployees("token", "org"); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if client.CircuitOpen() {
+		t.Error("circuit open after a successful trial")
+	}
+}
+
+func TestBreakerCountsConsecutiveFailures(t *testing.T) {
+	b := newBreaker(2, time.Minute)
+	b.record(false)
+	b.record(true)
+	b.record(false)
+	if b.isOpen() {
+		t.Error("circuit opened on failures a success separated")
+	}
+	b.record(false)
+	if !b.isOpen() {
+		t.Error("circuit closed after two failures in a row")
+	}
+}
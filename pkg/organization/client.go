@@ -2,18 +2,41 @@ package organization
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Axontik/comin-leave-management-service/pkg/auth"
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	// ErrNotConfigured is returned when the client was built without a base URL
+	ErrNotConfigured = errors.New("organization service not configured")
+	// ErrUnavailable is returned when the organization service cannot be reached or fails with 5xx
+	ErrUnavailable = errors.New("organization service unavailable")
+	// ErrEmployeeNotFound is returned when the employee does not exist in the organization
+	ErrEmployeeNotFound = errors.New("employee not found in organization")
+)
+
+// employeeCacheTTL is how long a successful employee lookup is reused
+const employeeCacheTTL = 5 * time.Minute
+
 type OrganizationClient struct {
 	baseURL    string
 	httpClient *http.Client
+	breaker    *breaker
+
+	mu        sync.Mutex
+	employees map[string]cachedEmployee
+}
+
+type cachedEmployee struct {
+	employee  *EmployeeResponse
+	expiresAt time.Time
 }
 
 type OrganizationResponse struct {
@@ -22,16 +45,66 @@ type OrganizationResponse struct {
 	Status string `json:"status"`
 }
 
+type EmployeeResponse struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	DepartmentID   string `json:"department_id"`
+	Email          string `json:"email"`
+	// StartDate is the employee's first day, as YYYY-MM-DD or RFC 3339
+	StartDate string `json:"start_date"`
+	Status    string `json:"status"`
+	// Attributes leave type eligibility rules are evaluated against
+	Gender         string `json:"gender"`
+	EmploymentType string `json:"employment_type"`
+	Location       string `json:"location"`
+	Grade          string `json:"grade"`
+	// Timezone is the employee's IANA zone when it differs from the
+	// organization's; empty otherwise
+	Timezone string `json:"timezone"`
+}
+
+type DepartmentResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 func NewOrganizationClient(baseURL string) *OrganizationClient {
 	return &OrganizationClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: time.Second * 10,
 		},
+		breaker:   newBreaker(breakerThreshold, breakerCooldown),
+		employees: make(map[string]cachedEmployee),
 	}
 }
 
+// CircuitOpen reports whether calls to the organization service are failing
+// fast with ErrUnavailable after repeated failures
+func (c *OrganizationClient) CircuitOpen() bool {
+	return c.breaker.isOpen()
+}
+
+// do sends req through the circuit breaker. Transport errors and 5xx
+// responses count as failures; when the circuit is open the request is not
+// sent. Failures to reach the service are returned as ErrUnavailable.
+func (c *OrganizationClient) do(req *http.Request) (*http.Response, error) {
+	if c.breaker.isOpen() {
+		return nil, fmt.Errorf("%w: circuit open", ErrUnavailable)
+	}
+	resp, err := c.httpClient.Do(req)
+	c.breaker.record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return resp, nil
+}
+
 func (c *OrganizationClient) GetOrganization(token string, orgID string) (*OrganizationResponse, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/organizations/%s", c.baseURL, orgID), nil)
 
 	if err != nil {
@@ -40,12 +113,15 @@ func (c *OrganizationClient) GetOrganization(token string, orgID string) (*Organ
 
 	req.Header.Set("Authorization", fmt.Sprintf("%s", token))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to get organization")
 	}
@@ -58,6 +134,162 @@ func (c *OrganizationClient) GetOrganization(token string, orgID string) (*Organ
 	return &org, nil
 }
 
+// GetEmployee looks up an employee of the organization. Found employees are
+// cached for a few minutes; misses and failures are not.
+func (c *OrganizationClient) GetEmployee(token string, orgID string, employeeID string) (*EmployeeResponse, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
+	key := orgID + "/" + employeeID
+	c.mu.Lock()
+	cached, ok := c.employees[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.employee, nil
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/organizations/%s/employees/%s", c.baseURL, orgID, employeeID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", token)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrEmployeeNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get employee: status %d", resp.StatusCode)
+	}
+
+	var employee EmployeeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&employee); err != nil {
+		return nil, err
+	}
+	if employee.OrganizationID != orgID {
+		return nil, ErrEmployeeNotFound
+	}
+
+	c.mu.Lock()
+	c.employees[key] = cachedEmployee{employee: &employee, expiresAt: time.Now().Add(employeeCacheTTL)}
+	c.mu.Unlock()
+
+	return &employee, nil
+}
+
+// ListDepartmentEmployees returns the employees of a department in the
+// organization. Results are not cached since membership changes are
+// expected to show up immediately.
+func (c *OrganizationClient) ListDepartmentEmployees(token string, orgID string, departmentID string) ([]EmployeeResponse, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/organizations/%s/departments/%s/employees", c.baseURL, orgID, departmentID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", token)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list department employees: status %d", resp.StatusCode)
+	}
+
+	var employees []EmployeeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&employees); err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// ListDepartments returns the departments of the organization. Results are
+// not cached.
+func (c *OrganizationClient) ListDepartments(token string, orgID string) ([]DepartmentResponse, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/organizations/%s/departments", c.baseURL, orgID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", token)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list departments: status %d", resp.StatusCode)
+	}
+
+	var departments []DepartmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&departments); err != nil {
+		return nil, err
+	}
+	return departments, nil
+}
+
+// ListEmployees returns every employee of the organization. Results are not
+// cached.
+func (c *OrganizationClient) ListEmployees(token string, orgID string) ([]EmployeeResponse, error) {
+	if c.baseURL == "" {
+		return nil, ErrNotConfigured
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/organizations/%s/employees", c.baseURL, orgID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", token)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list employees: status %d", resp.StatusCode)
+	}
+
+	var employees []EmployeeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&employees); err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
 // Middleware to validate requests
 func ValidateOrganizationAccess(authClient *auth.AuthClient, orgClient *OrganizationClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -69,6 +301,10 @@ func ValidateOrganizationAccess(authClient *auth.AuthClient, orgClient *Organiza
 
 		user, err := authClient.ValidateToken(token)
 		if err != nil {
+			if status, msg, ok := dependencyError("auth", err, auth.ErrNotConfigured, auth.ErrUnavailable); ok {
+				c.AbortWithStatusJSON(status, gin.H{"error": msg})
+				return
+			}
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
@@ -76,11 +312,16 @@ func ValidateOrganizationAccess(authClient *auth.AuthClient, orgClient *Organiza
 		// Check if organization exists and is active
 		org, err := orgClient.GetOrganization(string(token), string(user.OrganizationID))
 		log.Printf("Organization Client: %+v", org)
+		if status, msg, ok := dependencyError("organization", err, ErrNotConfigured, ErrUnavailable); ok {
+			c.AbortWithStatusJSON(status, gin.H{"error": msg})
+			return
+		}
 		if err != nil || org.Status != "active" {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid organization access"})
 			return
 		}
 
+		auth.SetCurrentUser(c, user)
 		c.Set("user_id", user.ID)
 		c.Set("organization_id", user.OrganizationID)
 		c.Set("email", user.Email)
@@ -89,3 +330,15 @@ func ValidateOrganizationAccess(authClient *auth.AuthClient, orgClient *Organiza
 		c.Next()
 	}
 }
+
+// dependencyError maps a missing or unreachable upstream service to a 503 so
+// callers can tell an outage apart from a rejected credential
+func dependencyError(name string, err, notConfigured, unavailable error) (int, string, bool) {
+	switch {
+	case errors.Is(err, notConfigured):
+		return http.StatusServiceUnavailable, name + " dependency not configured", true
+	case errors.Is(err, unavailable):
+		return http.StatusServiceUnavailable, name + " dependency unreachable", true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,93 @@
+package organization
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/internal/contract"
+)
+
+// TestOrganizationClientContract replays every interaction of the
+// organization service contract against the client. Each call gets a new
+// client so the employee cache never answers for the server.
+func TestOrganizationClientContract(t *testing.T) {
+	c, err := contract.Load("../../contracts/organization-service.json")
+	if err != nil {
+		t.Fatalf("loading contract: %v", err)
+	}
+
+	calls := map[string]contract.Call{
+		"organization.GetOrganization": func(baseURL string, vars map[string]string) (interface{}, error) {
+			return NewOrganizationClient(baseURL).GetOrganization(vars["authorization"], vars["organization_id"])
+		},
+		"organization.GetEmployee": func(baseURL string, vars map[string]string) (interface{}, error) {
+			return NewOrganizationClient(baseURL).GetEmployee(vars["authorization"], vars["organization_id"], vars["employee_id"])
+		},
+		"organization.ListDepartmentEmployees": func(baseURL string, vars map[string]string) (interface{}, error) {
+			return NewOrganizationClient(baseURL).ListDepartmentEmployees(vars["authorization"], vars["organization_id"], vars["department_id"])
+		},
+		"organization.ListEmployees": func(baseURL string, vars map[string]string) (interface{}, error) {
+			return NewOrganizationClient(baseURL).ListEmployees(vars["authorization"], vars["organization_id"])
+		},
+		"organization.ListDepartments": func(baseURL string, vars map[string]string) (interface{}, error) {
+			return NewOrganizationClient(baseURL).ListDepartments(vars["authorization"], vars["organization_id"])
+		},
+	}
+
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		t.Run(interaction.Description, func(t *testing.T) {
+			call, ok := calls[interaction.Call]
+			if !ok {
+				t.Fatalf("no test call for %q", interaction.Call)
+			}
+			if err := contract.Replay(c, interaction, call); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestGetEmployeeMissingIsNotFound checks the 404 interaction surfaces as
+// ErrEmployeeNotFound, which callers map to their own not found errors
+func TestGetEmployeeMissingIsNotFound(t *testing.T) {
+	c, err := contract.Load("../../contracts/organization-service.json")
+	if err != nil {
+		t.Fatalf("loading contract: %v", err)
+	}
+
+	var found bool
+	for i := range c.Interactions {
+		interaction := &c.Interactions[i]
+		if interaction.Call != "organization.GetEmployee" || interaction.Response.Status != 404 {
+			continue
+		}
+		found = true
+		err := contract.Replay(c, interaction, func(baseURL string, vars map[string]string) (interface{}, error) {
+			_, err := NewOrganizationClient(baseURL).GetEmployee(vars["authorization"], vars["organization_id"], vars["employee_id"])
+			if !errors.Is(err, ErrEmployeeNotFound) {
+				t.Errorf("got %v, want ErrEmployeeNotFound", err)
+			}
+			return nil, err
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if !found {
+		t.Fatal("contract has no employee not found interaction")
+	}
+}
+
+func TestClientNotConfigured(t *testing.T) {
+	client := NewOrganizationClient("")
+	if _, err := client.GetOrganization("token", "org"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("GetOrganization: got %v, want ErrNotConfigured", err)
+	}
+	if _, err := client.GetEmployee("token", "org", "employee"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("GetEmployee: got %v, want ErrNotConfigured", err)
+	}
+	if _, err := client.ListDepartments("token", "org"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("ListDepartments: got %v, want ErrNotConfigured", err)
+	}
+}
@@ -0,0 +1,156 @@
+package organization
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Axontik/comin-leave-management-service/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const testOrgID = "0b8f2f1e-8e0a-4a5e-9a7c-5d0c1c3f6a22"
+
+// stubService answers every request with status and body
+func stubService(t *testing.T, status int, body interface{}) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// closedServiceURL is the address of a server that is no longer listening
+func closedServiceURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestValidateOrganizationAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := map[string]string{"id": "7d1c6a9e-3f55-4c55-9b3e-2f0f4a2f8b11", "organization_id": testOrgID, "role": "manager"}
+	activeOrg := map[string]string{"id": testOrgID, "name": "Example Ltd", "status": "active"}
+
+	tests := []struct {
+		name       string
+		header     string
+		authURL    func(t *testing.T) string
+		orgURL     func(t *testing.T) string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "missing header",
+			authURL:    func(t *testing.T) string { return stubService(t, http.StatusOK, user) },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusOK, activeOrg) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "auth not configured",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return "" },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusOK, activeOrg) },
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "auth dependency not configured",
+		},
+		{
+			name:       "auth unreachable",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return closedServiceURL() },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusOK, activeOrg) },
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "auth dependency unreachable",
+		},
+		{
+			name:       "auth failing",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return stubService(t, http.StatusBadGateway, nil) },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusOK, activeOrg) },
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "auth dependency unreachable",
+		},
+		{
+			name:   "token rejected",
+			header: "Bearer token",
+			authURL: func(t *testing.T) string {
+				return stubService(t, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			},
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusOK, activeOrg) },
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "invalid token",
+		},
+		{
+			name:       "organization not configured",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return stubService(t, http.StatusOK, user) },
+			orgURL:     func(t *testing.T) string { return "" },
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "organization dependency not configured",
+		},
+		{
+			name:       "organization failing",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return stubService(t, http.StatusOK, user) },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusServiceUnavailable, nil) },
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "organization dependency unreachable",
+		},
+		{
+			name:    "organization inactive",
+			header:  "Bearer token",
+			authURL: func(t *testing.T) string { return stubService(t, http.StatusOK, user) },
+			orgURL: func(t *testing.T) string {
+				return stubService(t, http.StatusOK, map[string]string{"id": testOrgID, "status": "suspended"})
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "organization not found",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return stubService(t, http.StatusOK, user) },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusNotFound, nil) },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "allowed",
+			header:     "Bearer token",
+			authURL:    func(t *testing.T) string { return stubService(t, http.StatusOK, user) },
+			orgURL:     func(t *testing.T) string { return stubService(t, http.StatusOK, activeOrg) },
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ValidateOrganizationAccess(auth.NewAuthClient(tt.authURL(t)), NewOrganizationClient(tt.orgURL(t))))
+			router.GET("/", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"role": c.GetString("role"), "organization_id": c.GetString("organization_id")})
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var body map[string]string
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if tt.wantError != "" && body["error"] != tt.wantError {
+				t.Errorf("got error %q, want %q", body["error"], tt.wantError)
+			}
+			if tt.wantStatus == http.StatusOK && (body["role"] != "manager" || body["organization_id"] != testOrgID) {
+				t.Errorf("user not set on the context: %v", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// render writes the document and checks the structure every PDF reader
+// relies on: the header, each xref offset pointing at its object, and the
+// trailer pointing at the xref
+func render(t *testing.T, d *Document) string {
+	t.Helper()
+	var buf bytes.Buffer
+	n, err := d.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("writing: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo reported %d bytes, wrote %d", n, buf.Len())
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "%PDF-1.4\n") {
+		t.Errorf("missing header: %q", out[:20])
+	}
+	if !strings.HasSuffix(out, "%%EOF\n") {
+		t.Errorf("missing end marker: %q", out[len(out)-20:])
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindStringSubmatch(out)
+	if startxref == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(startxref[1])
+	if !strings.HasPrefix(out[xref:], "xref\n") {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllStringSubmatch(out[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := strconv.Itoa(i+1) + " 0 obj\n"; !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, out[offset:offset+10], want)
+		}
+	}
+	if !strings.Contains(out, "trailer\n<< /Size "+strconv.Itoa(len(entries)+1)+" /Root 1 0 R /Info 5 0 R >>") {
+		t.Errorf("trailer does not match the %d objects", len(entries))
+	}
+	return out
+}
+
+func TestDocumentWriteTo(t *testing.T) {
+	d := New("Statement (draft)")
+	d.AddPage()
+	d.Text(48, 60, 12, true, "Leave statement")
+	d.Line(48, 64, 200, 64)
+	d.AddPage()
+	d.Text(48, 60, 9, false, "Page two")
+
+	out := render(t, d)
+	if !strings.Contains(out, "/Count 2 >>") || !strings.Contains(out, "/Kids [6 0 R 8 0 R]") {
+		t.Error("page tree does not list both pages")
+	}
+	if !strings.Contains(out, `/Title (Statement \(draft\))`) {
+		t.Error("title was not escaped in the metadata")
+	}
+	// The y position is measured from the top, PDF's from the bottom
+	if !strings.Contains(out, "BT /F2 12 Tf 48 781.89 Td (Leave statement) Tj ET") {
+		t.Error("bold text not placed from the top of the page")
+	}
+	if !strings.Contains(out, "0.5 w 48 777.89 m 200 777.89 l S") {
+		t.Error("line not drawn")
+	}
+}
+
+func TestDocumentWithoutPages(t *testing.T) {
+	out := render(t, New("Empty"))
+	if !strings.Contains(out, "/Count 1 >>") {
+		t.Error("a document without pages was not given one")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := map[string]string{
+		`f(x) = a\b`:     `f\(x\) = a\\b`,
+		"tab\tnewline\n": "tab newline ",
+		"café":           `caf\351`,
+		"Q1 – Q2…":       `Q1 \226 Q2\205`,
+		"日本":             "??",
+	}
+	for in, want := range tests {
+		if got := escape(in); got != want {
+			t.Errorf("escape(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	d := New("")
+	d.Text(0, 0, 9, false, `(unbalanced \`)
+	if out := render(t, d); !strings.Contains(out, `(\(unbalanced \\) Tj`) {
+		t.Error("text was not escaped in the content stream")
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := Fit("Annual leave", 10, 100); got != "Annual leave" {
+		t.Errorf("text that fits was changed to %q", got)
+	}
+	if got := Fit("Annual leave carried over", 10, 50); got != "Annual le…" {
+		t.Errorf("Fit = %q, want %q", got, "Annual le…")
+	}
+	if got := Fit("Annual", 10, 2); got != "" {
+		t.Errorf("Fit into no room = %q", got)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := map[float64]string{12: "12", 595.28: "595.28", 0.5: "0.5", -0.001: "0", 841.891: "841.89"}
+	for v, want := range tests {
+		if got := number(v); got != want {
+			t.Errorf("number(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Poster delivers a JSON payload to a webhook URL
+type Poster interface {
+	Post(url string, payload interface{}) error
+}
+
+// DeliveryError is a webhook call that failed, with the receiver's status
+// when it answered
+type DeliveryError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *DeliveryError) Error() string {
+	if e.StatusCode == 0 {
+		return "webhook unreachable: " + e.Message
+	}
+	return fmt.Sprintf("webhook returned %d: %s", e.StatusCode, e.Message)
+}
+
+// Client posts JSON payloads over HTTP
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+// Post sends payload as a JSON body, treating any 2xx answer as delivered
+func (c *Client) Post(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return &DeliveryError{Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		message := strings.TrimSpace(string(text))
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return &DeliveryError{StatusCode: resp.StatusCode, Message: message}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPost(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if err := NewClient().Post(server.URL, map[string]string{"event": "absence_alert.raised"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["event"] != "absence_alert.raised" {
+		t.Errorf("received %v", got)
+	}
+}
+
+func TestClientPostFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "receiver down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewClient().Post(server.URL, struct{}{})
+	var delivery *DeliveryError
+	if !errors.As(err, &delivery) || delivery.StatusCode != http.StatusServiceUnavailable || delivery.Message != "receiver down for maintenance" {
+		t.Errorf("err = %v, want a 503 DeliveryError with the receiver's message", err)
+	}
+
+	server.Close()
+	if err := NewClient().Post(server.URL, struct{}{}); !errors.As(err, &delivery) || delivery.StatusCode != 0 {
+		t.Errorf("err = %v, want an unreachable DeliveryError", err)
+	}
+}
@@ -0,0 +1,172 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openWorkbook reads the parts of a written workbook by name
+func openWorkbook(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("workbook is not a zip: %v", err)
+	}
+	parts := make(map[string]string, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+		// Every part must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v\n%s", f.Name, err, content)
+			}
+		}
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+func TestWriterWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	if _, err := w.AddSheet("Leave requests"); err != nil {
+		t.Fatalf("adding sheet: %v", err)
+	}
+	if err := w.WriteHeader("Employee", "Days", "Start"); err != nil {
+		t.Fatalf("writing header: %v", err)
+	}
+	start := time.Date(2026, time.March, 2, 15, 4, 0, 0, time.UTC)
+	if err := w.WriteRow(`Ana "AJ" <ops> & co`, 2.5, start, nil, true, 3, int64(7), (*time.Time)(nil)); err != nil {
+		t.Fatalf("writing row: %v", err)
+	}
+	if _, err := w.AddSheet("Balances"); err != nil {
+		t.Fatalf("adding second sheet: %v", err)
+	}
+	if err := w.WriteRow("bell\x07 tab\t"); err != nil {
+		t.Fatalf("writing row: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+
+	parts := openWorkbook(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	workbook := parts["xl/workbook.xml"]
+	if !strings.Contains(workbook, `<sheet name="Leave requests" sheetId="1" r:id="rId1"/>`) ||
+		!strings.Contains(workbook, `<sheet name="Balances" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("workbook does not list both sheets: %s", workbook)
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		// Header cells are bold
+		`<c r="A1" t="inlineStr" s="2"><is><t xml:space="preserve">Employee</t></is></c>`,
+		// Text is escaped
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">Ana &quot;AJ&quot; &lt;ops&gt; &amp; co</t></is></c>`,
+		// Numbers are numeric cells, without a type
+		`<c r="B2"><v>2.5</v></c>`,
+		`<c r="F2"><v>3</v></c>`,
+		`<c r="G2"><v>7</v></c>`,
+		// Dates are serials in the date style, without the time of day
+		`<c r="C2" s="1"><v>46083</v></c>`,
+		`<c r="E2" t="b"><v>1</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet lacks %s:\n%s", want, sheet)
+		}
+	}
+	// Nil cells are left out rather than written empty
+	if strings.Contains(sheet, `r="D2"`) || strings.Contains(sheet, `r="H2"`) {
+		t.Errorf("nil cells were written: %s", sheet)
+	}
+
+	if second := parts["xl/worksheets/sheet2.xml"]; !strings.Contains(second, ">bell tab\t<") {
+		t.Errorf("control characters were not dropped: %s", second)
+	}
+}
+
+func TestWriterEmptyWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Close(); err != nil {
+		t.Fatalf("closing: %v", err)
+	}
+	parts := openWorkbook(t, buf.Bytes())
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Sheet1"`) {
+		t.Errorf("empty workbook has no sheet: %s", parts["xl/workbook.xml"])
+	}
+}
+
+func TestWriterRowBeforeSheet(t *testing.T) {
+	if err := NewWriter(io.Discard).WriteRow("orphan"); !errors.Is(err, ErrNoSheet) {
+		t.Errorf("got %v, want ErrNoSheet", err)
+	}
+}
+
+func TestWriterUniqueSheetNames(t *testing.T) {
+	w := NewWriter(io.Discard)
+	long := strings.Repeat("x", 40)
+	want := []struct{ name, got string }{
+		{name: "Summary", got: "Summary"},
+		{name: "summary", got: "summary (2)"},
+		{name: "Summary", got: "Summary (3)"},
+		{name: long, got: strings.Repeat("x", 31)},
+		{name: long, got: strings.Repeat("x", 27) + " (2)"},
+	}
+	for _, tt := range want {
+		got, err := w.AddSheet(tt.name)
+		if err != nil {
+			t.Fatalf("adding %q: %v", tt.name, err)
+		}
+		if got != tt.got {
+			t.Errorf("AddSheet(%q) = %q, want %q", tt.name, got, tt.got)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	tests := map[string]string{
+		"Q1/Q2 [draft]":                      "Q1-Q2 -draft-",
+		"  'quoted'  ":                       "quoted",
+		"":                                   "Sheet",
+		"Ünïcødé":                            "Ünïcødé",
+		"A name well over thirty-one runes!": "A name well over thirty-one run",
+	}
+	for in, want := range tests {
+		if got := SheetName(in); got != want {
+			t.Errorf("SheetName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}